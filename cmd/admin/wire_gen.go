@@ -11,6 +11,7 @@ import (
 	game2 "github.com/b7777777v/fish_server/internal/app/game"
 	"github.com/b7777777v/fish_server/internal/biz"
 	"github.com/b7777777v/fish_server/internal/biz/account"
+	admin2 "github.com/b7777777v/fish_server/internal/biz/admin"
 	"github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/biz/lobby"
 	"github.com/b7777777v/fish_server/internal/biz/player"
@@ -42,12 +43,18 @@ func initApp(config *conf.Config) (*admin.AdminApp, func(), error) {
 	jwt := config.JWT
 	client := data.ProvideRedisClient(dataData)
 	tokenCache := redis.NewTokenCache(client, v)
-	tokenHelper := token.ProvideTokenHelper(jwt, tokenCache)
+	sessionStore := data.NewSessionStore(client)
+	tokenHelper := token.ProvideTokenHelper(jwt, tokenCache, sessionStore)
 	playerUsecase := player.NewPlayerUsecase(playerRepo, tokenHelper, v)
 	walletRepo := data.NewWalletRepo(dataData, v)
-	walletUsecase := wallet.NewWalletUsecase(walletRepo, v)
+	dbManager := data.ProvideDBManager(dataData)
+	sanctionRepo := data.NewSanctionRepo(dbManager)
+	sessionEvents := data.NewSessionEvents(client)
+	sessionUsecase := account.NewSessionUsecase(sessionStore, sessionEvents, tokenHelper, v)
+	sanctionUsecase := account.NewSanctionUsecase(sanctionRepo, sessionUsecase, v)
+	walletUsecase := biz.ProvideWalletUsecase(walletRepo, sanctionUsecase, v)
 	gameRepo := data.NewGameRepo(dataData, v)
-	gamePlayerRepo := data.NewGamePlayerRepo(dataData, v)
+	gamePlayerRepo := data.NewGamePlayerRepo(playerRepo, dataData, v)
 	gameRecordRepo := data.NewGameRecordRepo(dataData, v)
 	roomConfig := game.NewDefaultRoomConfig()
 	fishSpawner := game.NewFishSpawner(v, roomConfig)
@@ -62,25 +69,65 @@ func initApp(config *conf.Config) (*admin.AdminApp, func(), error) {
 	rtpController := game.NewRTPController(inventoryManager, v)
 	roomManager := game.NewRoomManager(v, fishSpawner, mathModel, inventoryManager, rtpController)
 	gameUsecase := game.NewGameUsecase(gameRepo, gamePlayerRepo, gameRecordRepo, walletUsecase, roomManager, fishSpawner, mathModel, inventoryManager, rtpController, v)
-	dbManager := data.ProvideDBManager(dataData)
 	accountRepo := data.NewAccountRepo(dbManager)
-	oAuthService := account.NewOAuthService()
+	loginAttemptStore := data.NewLoginAttemptStore(client)
+	loginHistoryRepo := data.NewLoginHistoryRepo(dbManager)
+	loginThrottlePolicy := biz.ProvideLoginThrottlePolicy(config)
+	loginSecurityUsecase := account.NewLoginSecurityUsecase(loginAttemptStore, loginHistoryRepo, loginThrottlePolicy, v)
+	oAuthStateStore := data.NewOAuthStateStore(client)
+	oAuthService, err := biz.ProvideOAuthService(config, oAuthStateStore, v)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
 	walletCreator := biz.ProvideWalletCreator(walletUsecase)
-	accountUsecase := account.NewAccountUsecase(accountRepo, tokenHelper, oAuthService, walletCreator)
-	hub := game2.NewHub(gameUsecase, playerUsecase, v)
-	webSocketHandler := game2.NewWebSocketHandler(hub, tokenHelper, accountUsecase, v)
+	accountUsecase := account.NewAccountUsecase(accountRepo, sessionUsecase, sanctionUsecase, loginSecurityUsecase, oAuthService, walletCreator)
+	messageRateLimiter := game2.NewMessageRateLimiter(config, v)
+	matchmaker := game2.NewMatchmaker(gameUsecase, config, v)
+	hub := game2.NewHub(gameUsecase, playerUsecase, messageRateLimiter, matchmaker, v)
+	guestProgressStore := data.NewGuestProgressStore(client)
+	walletCreditor := biz.ProvideWalletCreditor(walletUsecase)
+	guestRecordMigrator := biz.ProvideGuestRecordMigrator(gameRecordRepo)
+	guestUpgradePolicy := biz.ProvideGuestUpgradePolicy(config)
+	guestUsecase := account.NewGuestUsecase(accountUsecase, accountRepo, guestProgressStore, sessionUsecase, oAuthService, walletCreator, walletCreditor, guestRecordMigrator, guestUpgradePolicy, v)
+	webSocketHandler := game2.NewWebSocketHandler(hub, tokenHelper, accountUsecase, guestUsecase, config, v)
 	messageHandler := game2.NewMessageHandler(gameUsecase, hub, v)
-	gameApp := game2.NewGameApp(gameUsecase, accountUsecase, config, v, hub, webSocketHandler, messageHandler)
+	nodeRegistry := data.NewNodeRegistry(client)
+	roomCache := data.NewRoomCache(client)
+	nodeAgent := game2.NewNodeAgent(nodeRegistry, roomCache, hub, gameUsecase, config, v)
+	roomCheckpointRepo := data.NewRoomCheckpointRepo(client)
+	roomCheckpointer := game2.NewRoomCheckpointer(roomCheckpointRepo, nodeRegistry, hub, gameUsecase, nodeAgent, config, v)
+	roomHistoryRepo := data.NewRoomHistoryRepo(client)
+	roomLifecycleMonitor := game2.NewRoomLifecycleMonitor(roomHistoryRepo, gameUsecase, nodeAgent, v)
+	seatKeeper := game2.NewSeatKeeper(hub, gameUsecase, config, v)
+	roomConfigRepo := data.NewRoomConfigRepo(dbManager, client, v)
+	roomConfigBus := data.NewRoomConfigBus(client)
+	roomConfigWatcher := game2.NewRoomConfigWatcher(roomConfigRepo, roomConfigBus, gameUsecase, config, v)
+	roomEventRepo := data.NewRoomEventRepo(dbManager)
+	roomEventBoard := data.NewRoomEventBoard(client)
+	roomEventScheduler := game2.NewRoomEventScheduler(roomEventRepo, roomEventBoard, hub, gameUsecase, config, v)
+	sessionWatcher := game2.NewSessionWatcher(sessionUsecase, hub, v)
+	gameApp := game2.NewGameApp(gameUsecase, accountUsecase, config, v, hub, webSocketHandler, messageHandler, nodeAgent, matchmaker, roomCheckpointer, roomLifecycleMonitor, seatKeeper, roomConfigWatcher, roomEventScheduler, sessionWatcher, sanctionUsecase)
 	formationConfigRepo := data.NewFormationConfigRepo(dbManager, client, v)
 	formationConfigService := game.NewFormationConfigService(formationConfigRepo, v)
-	accountHandler := admin.NewAccountHandler(accountUsecase, tokenHelper)
+	roomConfigUsecase := game.NewRoomConfigUsecase(roomConfigRepo, roomConfigBus, v)
+	roomEventUsecase := game.NewRoomEventUsecase(roomEventRepo, v)
+	adminRepo := data.NewAdminRepo(dbManager)
+	adminUsecase := admin2.NewAdminUsecase(adminRepo, tokenHelper, v)
+	auditRepo := data.NewAuditRepo(dbManager)
+	auditUsecase := admin2.NewAuditUsecase(auditRepo, v)
+	exchangeRepo := data.NewExchangeRepo(dataData, v)
+	exchangeUsecase := wallet.NewExchangeUsecase(walletRepo, exchangeRepo, v)
+	adminAuth := admin.NewAdminAuth(adminUsecase, auditUsecase, tokenHelper, config, v)
+	accountHandler := admin.NewAccountHandler(accountUsecase, sessionUsecase, guestUsecase, loginSecurityUsecase, tokenHelper)
 	lobbyRepo := data.NewLobbyRepo(dbManager)
-	roomCache := data.NewRoomCache(client)
-	lobbyWalletRepo := data.NewLobbyWalletRepo(dataData, v)
-	lobbyPlayerRepo := data.NewLobbyPlayerRepo(dataData, v)
-	lobbyUsecase := lobby.NewLobbyUsecase(lobbyRepo, roomCache, lobbyWalletRepo, lobbyPlayerRepo)
-	lobbyHandler := admin.NewLobbyHandler(lobbyUsecase, tokenHelper)
-	adminService := admin.NewAdminService(playerUsecase, walletUsecase, gameApp, formationConfigService, tokenHelper, config, v, accountHandler, lobbyHandler)
+	lobbyWalletRepo := data.NewLobbyWalletRepo(playerRepo, v)
+	lobbyPlayerRepo := data.NewLobbyPlayerRepo(playerRepo, v)
+	lobbyUsecase := lobby.NewLobbyUsecase(lobbyRepo, roomCache, nodeRegistry, lobbyWalletRepo, lobbyPlayerRepo, roomEventBoard)
+	lobbyHandler := admin.NewLobbyHandler(lobbyUsecase, adminAuth)
+	walletHandler := admin.NewWalletHandler(walletUsecase, exchangeUsecase)
+	adminService := admin.NewAdminService(playerUsecase, walletUsecase, gameApp, formationConfigService, roomHistoryRepo, roomConfigUsecase, roomEventUsecase, adminUsecase, auditUsecase, accountUsecase, sanctionUsecase, loginSecurityUsecase, exchangeUsecase, adminAuth, tokenHelper, config, v, accountHandler, lobbyHandler, walletHandler)
 	adminServer := admin.NewServer(server, adminService, v)
 	adminApp := admin.NewAdminApp(adminServer, v)
 	return adminApp, func() {
@@ -54,9 +54,19 @@ func main() {
 		}
 	}()
 	// 等待中斷訊號以進行優雅關閉
+	// SIGTERM（滾動部署）先進入下線模式，等待玩家離開並結算；SIGINT 立即關閉
+	// 管理後台發起的下線完成後同樣退出
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case sig := <-quit:
+		if sig == syscall.SIGTERM {
+			log.Info("Received SIGTERM, draining server...")
+			app.Drain(0)
+		}
+	case <-app.Drained():
+		log.Info("Drain completed")
+	}
 	log.Info("Shutting down server...")
 	if err := app.Stop(); err != nil {
 		log.Errorf("Error stopping game app: %v", err)
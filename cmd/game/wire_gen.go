@@ -12,7 +12,6 @@ import (
 	"github.com/b7777777v/fish_server/internal/biz/account"
 	game2 "github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/biz/player"
-	"github.com/b7777777v/fish_server/internal/biz/wallet"
 	"github.com/b7777777v/fish_server/internal/conf"
 	"github.com/b7777777v/fish_server/internal/data"
 	"github.com/b7777777v/fish_server/internal/data/redis"
@@ -35,10 +34,21 @@ func initApp(config *conf.Config) (*game.GameApp, func(), error) {
 		return nil, nil, err
 	}
 	gameRepo := data.NewGameRepo(dataData, v)
-	playerRepo := data.NewGamePlayerRepo(dataData, v)
+	playerRepo := data.NewPlayerRepo(dataData, v)
+	gamePlayerRepo := data.NewGamePlayerRepo(playerRepo, dataData, v)
 	gameRecordRepo := data.NewGameRecordRepo(dataData, v)
 	walletRepo := data.NewWalletRepo(dataData, v)
-	walletUsecase := wallet.NewWalletUsecase(walletRepo, v)
+	dbManager := data.ProvideDBManager(dataData)
+	sanctionRepo := data.NewSanctionRepo(dbManager)
+	client := data.ProvideRedisClient(dataData)
+	sessionStore := data.NewSessionStore(client)
+	sessionEvents := data.NewSessionEvents(client)
+	jwt := config.JWT
+	tokenCache := redis.NewTokenCache(client, v)
+	tokenHelper := token.ProvideTokenHelper(jwt, tokenCache, sessionStore)
+	sessionUsecase := account.NewSessionUsecase(sessionStore, sessionEvents, tokenHelper, v)
+	sanctionUsecase := account.NewSanctionUsecase(sanctionRepo, sessionUsecase, v)
+	walletUsecase := biz.ProvideWalletUsecase(walletRepo, sanctionUsecase, v)
 	roomConfig := game2.NewDefaultRoomConfig()
 	fishSpawner := game2.NewFishSpawner(v, roomConfig)
 	mathModel := game2.NewMathModel(v)
@@ -51,22 +61,48 @@ func initApp(config *conf.Config) (*game.GameApp, func(), error) {
 	}
 	rtpController := game2.NewRTPController(inventoryManager, v)
 	roomManager := game2.NewRoomManager(v, fishSpawner, mathModel, inventoryManager, rtpController)
-	gameUsecase := game2.NewGameUsecase(gameRepo, playerRepo, gameRecordRepo, walletUsecase, roomManager, fishSpawner, mathModel, inventoryManager, rtpController, v)
-	dbManager := data.ProvideDBManager(dataData)
+	gameUsecase := game2.NewGameUsecase(gameRepo, gamePlayerRepo, gameRecordRepo, walletUsecase, roomManager, fishSpawner, mathModel, inventoryManager, rtpController, v)
 	accountRepo := data.NewAccountRepo(dbManager)
-	jwt := config.JWT
-	client := data.ProvideRedisClient(dataData)
-	tokenCache := redis.NewTokenCache(client, v)
-	tokenHelper := token.ProvideTokenHelper(jwt, tokenCache)
-	oAuthService := account.NewOAuthService()
+	loginAttemptStore := data.NewLoginAttemptStore(client)
+	loginHistoryRepo := data.NewLoginHistoryRepo(dbManager)
+	loginThrottlePolicy := biz.ProvideLoginThrottlePolicy(config)
+	loginSecurityUsecase := account.NewLoginSecurityUsecase(loginAttemptStore, loginHistoryRepo, loginThrottlePolicy, v)
+	oAuthStateStore := data.NewOAuthStateStore(client)
+	oAuthService, err := biz.ProvideOAuthService(config, oAuthStateStore, v)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
 	walletCreator := biz.ProvideWalletCreator(walletUsecase)
-	accountUsecase := account.NewAccountUsecase(accountRepo, tokenHelper, oAuthService, walletCreator)
-	playerPlayerRepo := data.NewPlayerRepo(dataData, v)
-	playerUsecase := player.NewPlayerUsecase(playerPlayerRepo, tokenHelper, v)
-	hub := game.NewHub(gameUsecase, playerUsecase, v)
-	webSocketHandler := game.NewWebSocketHandler(hub, tokenHelper, accountUsecase, v)
+	accountUsecase := account.NewAccountUsecase(accountRepo, sessionUsecase, sanctionUsecase, loginSecurityUsecase, oAuthService, walletCreator)
+	playerUsecase := player.NewPlayerUsecase(playerRepo, tokenHelper, v)
+	messageRateLimiter := game.NewMessageRateLimiter(config, v)
+	matchmaker := game.NewMatchmaker(gameUsecase, config, v)
+	hub := game.NewHub(gameUsecase, playerUsecase, messageRateLimiter, matchmaker, v)
+	guestProgressStore := data.NewGuestProgressStore(client)
+	walletCreditor := biz.ProvideWalletCreditor(walletUsecase)
+	guestRecordMigrator := biz.ProvideGuestRecordMigrator(gameRecordRepo)
+	guestUpgradePolicy := biz.ProvideGuestUpgradePolicy(config)
+	guestUsecase := account.NewGuestUsecase(accountUsecase, accountRepo, guestProgressStore, sessionUsecase, oAuthService, walletCreator, walletCreditor, guestRecordMigrator, guestUpgradePolicy, v)
+	webSocketHandler := game.NewWebSocketHandler(hub, tokenHelper, accountUsecase, guestUsecase, config, v)
 	messageHandler := game.NewMessageHandler(gameUsecase, hub, v)
-	gameApp := game.NewGameApp(gameUsecase, accountUsecase, config, v, hub, webSocketHandler, messageHandler)
+	nodeRegistry := data.NewNodeRegistry(client)
+	roomCache := data.NewRoomCache(client)
+	nodeAgent := game.NewNodeAgent(nodeRegistry, roomCache, hub, gameUsecase, config, v)
+	roomCheckpointRepo := data.NewRoomCheckpointRepo(client)
+	roomCheckpointer := game.NewRoomCheckpointer(roomCheckpointRepo, nodeRegistry, hub, gameUsecase, nodeAgent, config, v)
+	roomHistoryRepo := data.NewRoomHistoryRepo(client)
+	roomLifecycleMonitor := game.NewRoomLifecycleMonitor(roomHistoryRepo, gameUsecase, nodeAgent, v)
+	seatKeeper := game.NewSeatKeeper(hub, gameUsecase, config, v)
+	roomConfigRepo := data.NewRoomConfigRepo(dbManager, client, v)
+	roomConfigBus := data.NewRoomConfigBus(client)
+	roomConfigWatcher := game.NewRoomConfigWatcher(roomConfigRepo, roomConfigBus, gameUsecase, config, v)
+	roomEventRepo := data.NewRoomEventRepo(dbManager)
+	roomEventBoard := data.NewRoomEventBoard(client)
+	roomEventScheduler := game.NewRoomEventScheduler(roomEventRepo, roomEventBoard, hub, gameUsecase, config, v)
+	sessionWatcher := game.NewSessionWatcher(sessionUsecase, hub, v)
+	gameApp := game.NewGameApp(gameUsecase, accountUsecase, config, v, hub, webSocketHandler, messageHandler, nodeAgent, matchmaker, roomCheckpointer, roomLifecycleMonitor, seatKeeper, roomConfigWatcher, roomEventScheduler, sessionWatcher, sanctionUsecase)
 	return gameApp, func() {
 		cleanup2()
 		cleanup()
@@ -1,7 +1,10 @@
 package admin
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/b7777777v/fish_server/internal/biz/account"
@@ -12,13 +15,19 @@ import (
 // AccountHandler 處理帳號相關的 HTTP 請求
 type AccountHandler struct {
 	accountUsecase account.AccountUsecase
+	sessions       *account.SessionUsecase
+	guests         *account.GuestUsecase
+	security       *account.LoginSecurityUsecase
 	tokenHelper    *token.TokenHelper
 }
 
 // NewAccountHandler 建立新的 AccountHandler
-func NewAccountHandler(accountUsecase account.AccountUsecase, tokenHelper *token.TokenHelper) *AccountHandler {
+func NewAccountHandler(accountUsecase account.AccountUsecase, sessions *account.SessionUsecase, guests *account.GuestUsecase, security *account.LoginSecurityUsecase, tokenHelper *token.TokenHelper) *AccountHandler {
 	return &AccountHandler{
 		accountUsecase: accountUsecase,
+		sessions:       sessions,
+		guests:         guests,
+		security:       security,
 		tokenHelper:    tokenHelper,
 	}
 }
@@ -33,7 +42,15 @@ func RegisterAccountRoutes(r *gin.Engine, handler *AccountHandler) {
 		auth.POST("/register", handler.handleRegister)
 		auth.POST("/login", handler.handleLogin)
 		auth.POST("/guest-login", handler.handleGuestLogin)
+		auth.GET("/oauth/providers", handler.handleOAuthProviders)
+		auth.GET("/oauth/:provider/authorize", handler.handleOAuthAuthorize)
 		auth.POST("/oauth/callback", handler.handleOAuthCallback)
+		auth.POST("/refresh", handler.handleRefresh)
+		auth.POST("/logout", handler.authMiddleware(), handler.handleLogout)
+
+		// 遊客升級為正式帳號（需要遊客令牌）
+		auth.POST("/upgrade", handler.authMiddleware(), handler.handleUpgradeGuest)
+		auth.POST("/upgrade/oauth", handler.authMiddleware(), handler.handleUpgradeGuestOAuth)
 	}
 
 	// 使用者路由（需要認證）
@@ -42,6 +59,21 @@ func RegisterAccountRoutes(r *gin.Engine, handler *AccountHandler) {
 	{
 		user.GET("/profile", handler.handleGetProfile)
 		user.PUT("/profile", handler.handleUpdateProfile)
+
+		// 登入設備管理
+		user.GET("/sessions", handler.handleListSessions)
+		user.DELETE("/sessions", handler.handleLogoutAll)
+		user.DELETE("/sessions/:id", handler.handleRevokeSession)
+
+		// 登入記錄和已知設備
+		user.GET("/login-history", handler.handleLoginHistory)
+		user.GET("/devices", handler.handleListDevices)
+
+		// 第三方身份綁定
+		user.GET("/oauth", handler.handleListOAuthIdentities)
+		user.GET("/oauth/:provider/authorize", handler.handleOAuthLinkAuthorize)
+		user.POST("/oauth/link", handler.handleLinkOAuth)
+		user.DELETE("/oauth/:provider", handler.handleUnlinkOAuth)
 	}
 }
 
@@ -68,16 +100,36 @@ func (h *AccountHandler) authMiddleware() gin.HandlerFunc {
 
 		// 驗證 token
 		claims, err := h.tokenHelper.ParseToken(tokenString)
+		if errors.Is(err, token.ErrSessionRevoked) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session revoked"})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			c.Abort()
 			return
 		}
 
-		// 將 user_id、is_guest 和 nickname 存入 context
+		// 管理員令牌的 UserID 是 admin_users.id，不能當作玩家使用
+		if claims.Admin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin tokens cannot access player APIs"})
+			c.Abort()
+			return
+		}
+
+		// 客服觀戰令牌只能用於觀戰連接
+		if claims.SpectateRoom != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "spectate tokens cannot access player APIs"})
+			c.Abort()
+			return
+		}
+
+		// 將 user_id、is_guest、nickname 和 session_id 存入 context
 		c.Set("user_id", claims.UserID)
 		c.Set("is_guest", claims.IsGuest)
 		c.Set("nickname", claims.Nickname)
+		c.Set("session_id", claims.SessionID)
 		c.Next()
 	}
 }
@@ -93,12 +145,25 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	RoomID   string `json:"room_id,omitempty"` // 可選：要進入的房間，用於路由到房間所在的 Game Server
 }
 
-// OAuthCallbackRequest OAuth 回調請求
+// OAuthCallbackRequest OAuth 回調請求，state 為授權時返回的值
 type OAuthCallbackRequest struct {
 	Provider string `json:"provider" binding:"required"`
 	Code     string `json:"code" binding:"required"`
+	State    string `json:"state" binding:"required"`
+}
+
+// RefreshRequest 刷新令牌請求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UpgradeGuestRequest 遊客升級為用戶名密碼帳號的請求
+type UpgradeGuestRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
 }
 
 // UpdateProfileRequest 更新資料請求
@@ -129,17 +194,38 @@ func (h *AccountHandler) handleRegister(c *gin.Context) {
 		return
 	}
 
-	// 生成 JWT token
-	token, err := h.tokenHelper.GenerateTokenWithClaims(user.ID, false)
+	// 註冊後直接登入
+	tokens, err := h.sessions.StartSession(c.Request.Context(), user.ID, false, "", clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  user,
-	})
+	resp := tokenResponse(tokens)
+	resp["user"] = user
+	c.JSON(http.StatusOK, resp)
+}
+
+// clientInfo 發起請求的客戶端，保存在會話中供玩家識別設備，X-Device-ID 用於新設備檢測
+func clientInfo(c *gin.Context) account.ClientInfo {
+	return account.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+		DeviceID:  c.GetHeader("X-Device-ID"),
+	}
+}
+
+// tokenResponse 登入和刷新的響應，token 與 access_token 相同，保留給舊客戶端
+func tokenResponse(tokens *account.SessionTokens) gin.H {
+	return gin.H{
+		"token":              tokens.AccessToken,
+		"access_token":       tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_in": tokens.RefreshExpiresIn,
+		"session_id":         tokens.SessionID,
+		"new_device":         tokens.NewDevice,
+	}
 }
 
 // handleLogin 處理使用者登入
@@ -151,29 +237,46 @@ func (h *AccountHandler) handleLogin(c *gin.Context) {
 	}
 
 	// 呼叫 AccountUsecase.Login
-	token, err := h.accountUsecase.Login(c.Request.Context(), req.Username, req.Password)
+	tokens, err := h.accountUsecase.Login(c.Request.Context(), req.Username, req.Password, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondLoginError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-	})
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
+
+// respondLoginError 密碼登入失敗的響應：限流返回 429，封禁返回 403，其餘返回 401
+func respondLoginError(c *gin.Context, err error) {
+	var throttled *account.LoginThrottledError
+	if errors.As(err, &throttled) {
+		retryAfter := int64(math.Ceil(throttled.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       err.Error(),
+			"scope":       throttled.Scope,
+			"retry_after": retryAfter,
+			"message":     "登入失敗次數過多，請稍後再試",
+		})
+		return
+	}
+	if errors.Is(err, account.ErrAccountBanned) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 }
 
 // handleGuestLogin 處理遊客登入
 func (h *AccountHandler) handleGuestLogin(c *gin.Context) {
 	// 呼叫 AccountUsecase.GuestLogin
-	token, err := h.accountUsecase.GuestLogin(c.Request.Context())
+	tokens, err := h.accountUsecase.GuestLogin(c.Request.Context(), clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-	})
+	c.JSON(http.StatusOK, tokenResponse(tokens))
 }
 
 // handleOAuthCallback 處理 OAuth 回調
@@ -185,14 +288,155 @@ func (h *AccountHandler) handleOAuthCallback(c *gin.Context) {
 	}
 
 	// 呼叫 AccountUsecase.OAuthLogin
-	token, err := h.accountUsecase.OAuthLogin(c.Request.Context(), req.Provider, req.Code)
+	tokens, err := h.accountUsecase.OAuthLogin(c.Request.Context(), req.Provider, req.Code, req.State, clientInfo(c))
 	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
+
+// handleRefresh 使用刷新令牌換取新的訪問令牌，刷新令牌同時輪換
+func (h *AccountHandler) handleRefresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	tokens, err := h.sessions.Refresh(c.Request.Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		h.respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
+
+// respondSessionError 把會話錯誤映射為 HTTP 狀態碼
+func (h *AccountHandler) respondSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, account.ErrRefreshTokenReused):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token reused", "message": "會話已被撤銷，請重新登入"})
+	case errors.Is(err, account.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+	case errors.Is(err, account.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+	case errors.Is(err, account.ErrGuestSessionsListing):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "session operation failed"})
+	}
+}
+
+// handleLogout 登出當前會話
+func (h *AccountHandler) handleLogout(c *gin.Context) {
+	sessionID := c.GetString("session_id")
+	if sessionID == "" {
+		// 舊版令牌沒有會話，無法單獨撤銷
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is not bound to a session"})
+		return
+	}
+
+	if err := h.sessions.Logout(c.Request.Context(), c.GetInt64("user_id"), sessionID); err != nil {
+		h.respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// handleListSessions 列出玩家所有登入設備
+func (h *AccountHandler) handleListSessions(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		h.respondSessionError(c, account.ErrGuestSessionsListing)
+		return
+	}
+
+	sessions, err := h.sessions.ListSessions(c.Request.Context(), c.GetInt64("user_id"), c.GetString("session_id"))
+	if err != nil {
+		h.respondSessionError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// handleRevokeSession 登出指定設備
+func (h *AccountHandler) handleRevokeSession(c *gin.Context) {
+	// 遊客只能通過 /auth/logout 登出自己的會話
+	if c.GetBool("is_guest") {
+		h.respondSessionError(c, account.ErrGuestSessionsListing)
+		return
+	}
+
+	if err := h.sessions.Logout(c.Request.Context(), c.GetInt64("user_id"), c.Param("id")); err != nil {
+		h.respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// handleLogoutAll 登出玩家所有設備（包括當前設備）
+func (h *AccountHandler) handleLogoutAll(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		h.respondSessionError(c, account.ErrGuestSessionsListing)
+		return
+	}
+
+	count, err := h.sessions.LogoutAll(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		h.respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "all sessions revoked",
+		"count":   count,
+	})
+}
+
+// handleLoginHistory 玩家最近的登入記錄，包括失敗和被拒絕的嘗試
+func (h *AccountHandler) handleLoginHistory(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "guests have no login history"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	records, err := h.security.History(c.Request.Context(), c.GetInt64("user_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get login history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"logins": records,
+		"count":  len(records),
+	})
+}
+
+// handleListDevices 玩家登入成功過的設備
+func (h *AccountHandler) handleListDevices(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "guests have no devices"})
+		return
+	}
+
+	devices, err := h.security.Devices(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list devices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"devices": devices,
+		"count":   len(devices),
 	})
 }
 
@@ -215,6 +459,11 @@ func (h *AccountHandler) handleGetProfile(c *gin.Context) {
 			"nickname": nickname,
 			"is_guest": true,
 		}
+		// 附帶遊客進度，供玩家決定是否升級
+		if progress, err := h.guests.LoadProgress(c.Request.Context(), c.GetString("nickname")); err == nil {
+			guestUser["balance"] = progress.Balance
+			guestUser["upgrade_carry_over"] = h.guests.CarryOver(progress)
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"user": guestUser,
 		})
@@ -259,3 +508,86 @@ func (h *AccountHandler) handleUpdateProfile(c *gin.Context) {
 		"message": "profile updated successfully",
 	})
 }
+
+// guestIdentity 從遊客令牌中取得發起升級的遊客
+func guestIdentity(c *gin.Context) (account.GuestIdentity, bool) {
+	if !c.GetBool("is_guest") {
+		return account.GuestIdentity{}, false
+	}
+	return account.GuestIdentity{
+		SessionID: c.GetString("session_id"),
+		Nickname:  c.GetString("nickname"),
+	}, true
+}
+
+// respondUpgradeResult 升級成功後返回新帳號的令牌和轉入結果
+func respondUpgradeResult(c *gin.Context, result *account.GuestUpgradeResult) {
+	resp := tokenResponse(result.Tokens)
+	resp["user"] = result.User
+	resp["guest_balance"] = result.GuestBalance
+	resp["carried_over"] = result.CarriedOver
+	resp["migrated_rounds"] = result.MigratedRounds
+	c.JSON(http.StatusOK, resp)
+}
+
+// respondUpgradeError 把升級錯誤映射為 HTTP 狀態碼
+func (h *AccountHandler) respondUpgradeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, account.ErrNotGuest):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "message": "只有遊客會話可以升級"})
+	case errors.Is(err, account.ErrGuestInRoom):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "message": "請先離開房間再升級"})
+	case errors.Is(err, account.ErrGuestAlreadyUpgraded):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, account.ErrOAuthIdentityTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "message": "該第三方帳號已綁定其他帳號，請直接登入"})
+	case errors.Is(err, account.ErrOAuthProviderNotFound), errors.Is(err, account.ErrOAuthStateInvalid):
+		respondOAuthError(c, err)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "message": "升級失敗"})
+	}
+}
+
+// handleUpgradeGuest 把當前遊客升級為用戶名密碼帳號，遊客會話被撤銷並返回新帳號的令牌
+func (h *AccountHandler) handleUpgradeGuest(c *gin.Context) {
+	guest, ok := guestIdentity(c)
+	if !ok {
+		h.respondUpgradeError(c, account.ErrNotGuest)
+		return
+	}
+
+	var req UpgradeGuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.guests.UpgradeWithPassword(c.Request.Context(), guest, req.Username, req.Password, clientInfo(c))
+	if err != nil {
+		h.respondUpgradeError(c, err)
+		return
+	}
+	respondUpgradeResult(c, result)
+}
+
+// handleUpgradeGuestOAuth 把當前遊客升級為 OAuth 帳號
+func (h *AccountHandler) handleUpgradeGuestOAuth(c *gin.Context) {
+	guest, ok := guestIdentity(c)
+	if !ok {
+		h.respondUpgradeError(c, account.ErrNotGuest)
+		return
+	}
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.guests.UpgradeWithOAuth(c.Request.Context(), guest, req.Provider, req.Code, req.State, clientInfo(c))
+	if err != nil {
+		h.respondUpgradeError(c, err)
+		return
+	}
+	respondUpgradeResult(c, result)
+}
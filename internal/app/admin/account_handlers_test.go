@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/b7777777v/fish_server/internal/pkg/token"
+)
+
+// stubAccountUsecase 只支持密碼登入的帳號用例
+type stubAccountUsecase struct {
+	account.AccountUsecase
+	tokens *account.SessionTokens
+	err    error
+	calls  int
+}
+
+func (uc *stubAccountUsecase) Login(ctx context.Context, username, password string, client account.ClientInfo) (*account.SessionTokens, error) {
+	uc.calls++
+	return uc.tokens, uc.err
+}
+
+func newLoginTestRouter(uc account.AccountUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	s := &AdminService{
+		config:         &conf.Config{},
+		logger:         logger.New(io.Discard, "info", "console"),
+		accountHandler: &AccountHandler{accountUsecase: uc},
+	}
+	r := gin.New()
+	r.POST("/admin/login", s.Login)
+	return r
+}
+
+func postLogin(r *gin.Engine) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/login", strings.NewReader(`{"username":"alice","password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminServiceLogin_Throttled(t *testing.T) {
+	uc := &stubAccountUsecase{err: &account.LoginThrottledError{Scope: account.ThrottleScopeUsername, RetryAfter: 30 * time.Second}}
+	w := postLogin(newLoginTestRouter(uc))
+
+	assert.Equal(t, 1, uc.calls)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+}
+
+func TestAdminServiceLogin_ReturnsSessionTokens(t *testing.T) {
+	uc := &stubAccountUsecase{tokens: &account.SessionTokens{
+		AccessToken:      "access",
+		RefreshToken:     "refresh",
+		ExpiresIn:        900,
+		RefreshExpiresIn: 86400,
+		SessionID:        "sess-1",
+	}}
+	w := postLogin(newLoginTestRouter(uc))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp LoginResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "access", resp.Token)
+	assert.Equal(t, "refresh", resp.RefreshToken)
+	assert.Equal(t, "sess-1", resp.SessionID)
+	assert.Equal(t, int64(900), resp.ExpiresIn)
+	assert.NotEmpty(t, resp.GameServerURL)
+}
+
+func TestAdminServiceLogin_BannedAccount(t *testing.T) {
+	uc := &stubAccountUsecase{err: fmt.Errorf("%w permanently: cheating", account.ErrAccountBanned)}
+	w := postLogin(newLoginTestRouter(uc))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "cheating")
+}
+
+func TestAuthMiddleware_RejectsSpectateAndAdminTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokenHelper := token.NewTokenHelper(&conf.JWT{Secret: "test-secret", Issuer: "test", Expire: 3600})
+	h := &AccountHandler{tokenHelper: tokenHelper}
+	r := gin.New()
+	r.GET("/api/v1/user/profile", h.authMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt64("user_id")})
+	})
+
+	spectate, err := tokenHelper.GenerateSpectateToken(7, "room-1", time.Minute)
+	require.NoError(t, err)
+	claims, err := tokenHelper.ParseToken(spectate)
+	require.NoError(t, err)
+	assert.Zero(t, claims.UserID)
+	assert.Equal(t, "admin:7", claims.Subject)
+
+	admin, err := tokenHelper.GenerateAdminToken(7, time.Minute)
+	require.NoError(t, err)
+	player, err := tokenHelper.GenerateSessionToken(7, false, "", "sess-1")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		token string
+		code  int
+	}{
+		"spectate": {spectate, http.StatusForbidden},
+		"admin":    {admin, http.StatusForbidden},
+		"player":   {player, http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/user/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.code, w.Code, name)
+	}
+}
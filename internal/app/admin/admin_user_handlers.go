@@ -0,0 +1,379 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminLoginRequest 管理員登入請求，已啟用兩步驗證的管理員還需要提供驗證碼或恢復碼
+type AdminLoginRequest struct {
+	Username     string `json:"username" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+	OTPCode      string `json:"otp_code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// CreateAdminUserRequest 創建管理員請求
+type CreateAdminUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// UpdateAdminUserRequest 修改管理員請求，未提供的字段保持不變
+type UpdateAdminUserRequest struct {
+	Role     *string `json:"role"`
+	Active   *bool   `json:"active"`
+	Password *string `json:"password"`
+}
+
+// RoleRequest 創建或修改角色請求，IP 白名單只在創建時設置，之後通過 allowed-ips 接口修改
+type RoleRequest struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Permissions []adminbiz.Permission `json:"permissions"`
+	AllowedIPs  []string              `json:"allowed_ips"`
+}
+
+// RoleAllowedIPsRequest 修改角色 IP 白名單請求，空列表表示不限制
+type RoleAllowedIPsRequest struct {
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+// AdminUserListResponse 管理員列表響應
+type AdminUserListResponse struct {
+	Admins []*adminbiz.AdminUser `json:"admins"`
+	Count  int                   `json:"count"`
+}
+
+// RoleListResponse 角色列表響應
+type RoleListResponse struct {
+	Roles []*adminbiz.Role `json:"roles"`
+	Count int              `json:"count"`
+}
+
+// registerAdminUserRoutes 註冊管理員帳號和角色管理路由
+func (s *AdminService) registerAdminUserRoutes(admin *gin.RouterGroup) {
+	// 創建管理員、修改管理員和角色權限可以提權，和影響登入方式的修改一樣需要兩步驗證
+	stepUp := s.adminAuth.RequireStepUp()
+	manage := admin.Group("")
+	manage.Use(s.adminAuth.Require(adminbiz.PermAdminManage))
+	{
+		manage.GET("/permissions", s.ListPermissions)
+
+		manage.GET("/admin-users", s.ListAdminUsers)
+		manage.POST("/admin-users", stepUp, s.CreateAdminUser)
+		manage.GET("/admin-users/:id", s.GetAdminUser)
+		manage.PUT("/admin-users/:id", stepUp, s.UpdateAdminUser)
+
+		manage.GET("/roles", s.ListRoles)
+		manage.POST("/roles", s.CreateRole)
+		manage.GET("/roles/:name", s.GetRole)
+		manage.PUT("/roles/:name", stepUp, s.UpdateRole)
+		manage.DELETE("/roles/:name", s.DeleteRole)
+
+		// 影響其他管理員登入方式的修改需要兩步驗證
+		manage.POST("/admin-users/:id/otp/reset", stepUp, s.ResetAdminOTP)
+		manage.PUT("/roles/:name/allowed-ips", stepUp, s.SetRoleAllowedIPs)
+	}
+}
+
+// AdminLogin 管理員帳號密碼登入，簽發管理員令牌
+func (s *AdminService) AdminLogin(c *gin.Context) {
+	var req AdminLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := s.admins.Login(c.Request.Context(), adminbiz.LoginRequest{
+		Username:     req.Username,
+		Password:     req.Password,
+		OTPCode:      req.OTPCode,
+		RecoveryCode: req.RecoveryCode,
+		ClientIP:     c.ClientIP(),
+	}, s.adminAuth.tokenTTL())
+	if err != nil {
+		var status int
+		var response ErrorResponse
+		switch {
+		case errors.Is(err, adminbiz.ErrInvalidCredentials):
+			status, response = http.StatusUnauthorized, ErrorResponse{
+				Error:   "Invalid credentials",
+				Message: "Invalid username or password",
+			}
+		case errors.Is(err, adminbiz.ErrOTPRequired):
+			status, response = http.StatusUnauthorized, ErrorResponse{
+				Error:   "Two-factor code required",
+				Code:    "otp_required",
+				Message: "Provide otp_code or recovery_code",
+			}
+		case errors.Is(err, adminbiz.ErrInvalidOTP):
+			status, response = http.StatusUnauthorized, ErrorResponse{
+				Error:   "Invalid two-factor code",
+				Code:    "otp_invalid",
+				Message: err.Error(),
+			}
+		case errors.Is(err, adminbiz.ErrOTPLocked):
+			status, response = http.StatusTooManyRequests, ErrorResponse{
+				Error:   "Two-factor verification locked",
+				Code:    "otp_locked",
+				Message: err.Error(),
+			}
+		case errors.Is(err, adminbiz.ErrAdminDisabled):
+			status, response = http.StatusForbidden, ErrorResponse{
+				Error:   "Admin disabled",
+				Message: err.Error(),
+			}
+		case errors.Is(err, adminbiz.ErrIPNotAllowed):
+			status, response = http.StatusForbidden, ErrorResponse{
+				Error:   "IP address not allowed",
+				Code:    "ip_not_allowed",
+				Message: err.Error(),
+			}
+		default:
+			s.logger.Errorf("Failed to login admin %s: %v", req.Username, err)
+			status, response = http.StatusInternalServerError, ErrorResponse{
+				Error:   "Failed to login",
+				Message: err.Error(),
+			}
+		}
+		if auditErr := s.adminAuth.recordLoginAudit(c, req.Username, nil, status); auditErr != nil {
+			s.logger.Errorf("Failed to record failed login of admin %s: %v", req.Username, auditErr)
+		}
+		c.JSON(status, response)
+		return
+	}
+
+	// 登入記錄寫入失敗時不簽發令牌
+	if err := s.adminAuth.recordLoginAudit(c, req.Username, result, http.StatusOK); err != nil {
+		s.logger.Errorf("Failed to record login of admin %d: %v", result.Admin.ID, err)
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "Audit log unavailable",
+			Message: "Login rejected because it could not be audited",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCurrentAdmin 當前管理員及其角色權限
+func (s *AdminService) GetCurrentAdmin(c *gin.Context) {
+	c.JSON(http.StatusOK, currentPrincipal(c))
+}
+
+// ListPermissions 所有可分配給角色的權限
+func (s *AdminService) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": adminbiz.AllPermissions(),
+	})
+}
+
+// ListAdminUsers 查詢所有管理員
+func (s *AdminService) ListAdminUsers(c *gin.Context) {
+	admins, err := s.admins.ListAdmins(c.Request.Context())
+	if err != nil {
+		s.respondAdminError(c, "Failed to list admins", err)
+		return
+	}
+	c.JSON(http.StatusOK, AdminUserListResponse{Admins: admins, Count: len(admins)})
+}
+
+// GetAdminUser 查詢管理員
+func (s *AdminService) GetAdminUser(c *gin.Context) {
+	id, ok := adminUserID(c)
+	if !ok {
+		return
+	}
+
+	admin, err := s.admins.GetAdmin(c.Request.Context(), id)
+	if err != nil {
+		s.respondAdminError(c, "Failed to get admin", err)
+		return
+	}
+	c.JSON(http.StatusOK, admin)
+}
+
+// CreateAdminUser 創建管理員帳號
+func (s *AdminService) CreateAdminUser(c *gin.Context) {
+	var req CreateAdminUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	admin, err := s.admins.CreateAdmin(c.Request.Context(), currentPrincipal(c), req.Username, req.Password, req.Role)
+	if err != nil {
+		s.respondAdminError(c, "Failed to create admin", err)
+		return
+	}
+	c.JSON(http.StatusCreated, admin)
+}
+
+// UpdateAdminUser 修改管理員的角色、啟用狀態或重設密碼
+func (s *AdminService) UpdateAdminUser(c *gin.Context) {
+	id, ok := adminUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateAdminUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	admin, err := s.admins.UpdateAdmin(c.Request.Context(), currentPrincipal(c), id, adminbiz.AdminUpdate{
+		Role:     req.Role,
+		Active:   req.Active,
+		Password: req.Password,
+	})
+	if err != nil {
+		s.respondAdminError(c, "Failed to update admin", err)
+		return
+	}
+	c.JSON(http.StatusOK, admin)
+}
+
+// ListRoles 查詢所有角色
+func (s *AdminService) ListRoles(c *gin.Context) {
+	roles, err := s.admins.ListRoles(c.Request.Context())
+	if err != nil {
+		s.respondAdminError(c, "Failed to list roles", err)
+		return
+	}
+	c.JSON(http.StatusOK, RoleListResponse{Roles: roles, Count: len(roles)})
+}
+
+// GetRole 查詢角色
+func (s *AdminService) GetRole(c *gin.Context) {
+	role, err := s.admins.GetRole(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		s.respondAdminError(c, "Failed to get role", err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// CreateRole 創建自定義角色
+func (s *AdminService) CreateRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	role := &adminbiz.Role{Name: req.Name, Description: req.Description, Permissions: req.Permissions, AllowedIPs: req.AllowedIPs}
+	if err := s.admins.CreateRole(c.Request.Context(), currentPrincipal(c), role); err != nil {
+		s.respondAdminError(c, "Failed to create role", err)
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole 修改角色的描述和權限，使用該角色的管理員在下一次請求時生效
+func (s *AdminService) UpdateRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	role := &adminbiz.Role{Name: c.Param("name"), Description: req.Description, Permissions: req.Permissions}
+	if err := s.admins.UpdateRole(c.Request.Context(), currentPrincipal(c), role); err != nil {
+		s.respondAdminError(c, "Failed to update role", err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole 刪除自定義角色
+func (s *AdminService) DeleteRole(c *gin.Context) {
+	name := c.Param("name")
+	if err := s.admins.DeleteRole(c.Request.Context(), currentPrincipal(c), name); err != nil {
+		s.respondAdminError(c, "Failed to delete role", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role deleted",
+		"name":    name,
+	})
+}
+
+// SetRoleAllowedIPs 修改角色的 IP 白名單，包括 super_admin，立即對該角色的所有管理員生效
+func (s *AdminService) SetRoleAllowedIPs(c *gin.Context) {
+	var req RoleAllowedIPsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	name := c.Param("name")
+	before, _ := s.admins.GetRole(c.Request.Context(), name)
+	role, err := s.admins.SetRoleAllowedIPs(c.Request.Context(), currentPrincipal(c), name, req.AllowedIPs, c.ClientIP())
+	if err != nil {
+		s.respondAdminError(c, "Failed to set allowed IPs", err)
+		return
+	}
+	recordAuditChange(c, "role:"+name, before, role)
+	c.JSON(http.StatusOK, role)
+}
+
+// adminUserID 解析路徑中的管理員 ID，失敗時已寫入 400 響應
+func adminUserID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid admin ID",
+			Message: "admin ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondAdminError 將管理員帳號和角色錯誤轉換為 HTTP 響應
+func (s *AdminService) respondAdminError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, adminbiz.ErrInvalidAdmin), errors.Is(err, adminbiz.ErrInvalidRole):
+		status = http.StatusBadRequest
+	case errors.Is(err, adminbiz.ErrAdminNotFound), errors.Is(err, adminbiz.ErrRoleNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, adminbiz.ErrAdminExists), errors.Is(err, adminbiz.ErrRoleExists),
+		errors.Is(err, adminbiz.ErrRoleInUse), errors.Is(err, adminbiz.ErrLastSuperAdmin),
+		errors.Is(err, adminbiz.ErrSelfLockout):
+		status = http.StatusConflict
+	case errors.Is(err, adminbiz.ErrRoleImmutable), errors.Is(err, adminbiz.ErrSelfModification):
+		status = http.StatusForbidden
+	default:
+		s.logger.Errorf("%s: %v", message, err)
+	}
+
+	c.JSON(status, ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
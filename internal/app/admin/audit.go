@@ -0,0 +1,191 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// requestIDHeader 請求 ID 頭，客戶端未提供時由伺服器生成並在響應中返回
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength 客戶端提供的請求 ID 最大長度，超過時重新生成
+	maxRequestIDLength = 64
+	// auditAnnotationKey gin context 中保存處理器補充的審計信息的 key
+	auditAnnotationKey = "admin_audit"
+	// auditPendingKey gin context 中標記已寫入意圖記錄的 key
+	auditPendingKey = "admin_audit_pending"
+	// auditWriteTimeout 寫入審計記錄的超時
+	auditWriteTimeout = 5 * time.Second
+)
+
+// auditAnnotation 處理器補充的操作對象和修改前後數據
+type auditAnnotation struct {
+	target string
+	before interface{}
+	after  interface{}
+}
+
+// recordAuditChange 記錄本次請求的操作對象和修改前後的數據，由 Audit 中間件寫入審計日誌
+func recordAuditChange(c *gin.Context, target string, before, after interface{}) {
+	c.Set(auditAnnotationKey, &auditAnnotation{target: target, before: before, after: after})
+}
+
+// auditedMethod 需要審計的請求方法
+func auditedMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// newRequestID 生成隨機請求 ID
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// paramsTarget 處理器沒有指定操作對象時使用路由參數，例如 id=12
+func paramsTarget(c *gin.Context) string {
+	parts := make([]string, 0, len(c.Params))
+	for _, p := range c.Params {
+		parts = append(parts, p.Key+"="+p.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// encodeAuditValue 把修改前後的數據編碼為 JSON，nil（包括 nil 指針）保持為空
+func encodeAuditValue(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Audit 為請求分配請求 ID，並在已認證管理員的修改類請求完成後追加結果記錄
+// 應放在 Authenticate 之前，使認證失敗的響應也帶有請求 ID；意圖記錄由 Authenticate 在處理器執行前寫入，
+// 因此被 Require 拒絕的請求同樣會記錄
+func (a *AdminAuth) Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+
+		c.Next()
+
+		principal := currentPrincipal(c)
+		if a.audit == nil || principal == nil || !c.GetBool(auditPendingKey) {
+			return
+		}
+
+		entry := a.newAuditEntry(c, principal.Admin.ID, principal.Admin.Username, principal.Role.Name, c.Writer.Status())
+		if v, ok := c.Get(auditAnnotationKey); ok {
+			annotation := v.(*auditAnnotation)
+			if annotation.target != "" {
+				entry.Target = annotation.target
+			}
+			var err error
+			if entry.Before, err = encodeAuditValue(annotation.before); err != nil {
+				a.logger.Errorf("Failed to encode audit before value for %s: %v", entry.Route, err)
+			}
+			if entry.After, err = encodeAuditValue(annotation.after); err != nil {
+				a.logger.Errorf("Failed to encode audit after value for %s: %v", entry.Route, err)
+			}
+		}
+
+		// 響應已經發出，客戶端斷開也要寫完審計記錄；意圖記錄已經保存，這裡失敗只能告警
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), auditWriteTimeout)
+		defer cancel()
+		if err := a.audit.Record(ctx, entry); err != nil {
+			a.logger.Errorf("Failed to record audit result for %s %s by admin %d (request %s): %v",
+				entry.Method, entry.Path, entry.ActorID, entry.RequestID, err)
+		}
+	}
+}
+
+// AuditAccess 把讀取類請求（例如導出審計日誌）也當作需要審計的操作，必須在 Authenticate 之後使用
+func (a *AdminAuth) AuditAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := currentPrincipal(c)
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		if !a.beginAudit(c, principal) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// beginAudit 在處理器執行前追加意圖記錄，寫入失敗時返回 503 並中止請求，保證沒有審計記錄的操作不會執行
+func (a *AdminAuth) beginAudit(c *gin.Context, principal *adminbiz.Principal) bool {
+	if a.audit == nil || c.GetBool(auditPendingKey) {
+		return true
+	}
+	entry := a.newAuditEntry(c, principal.Admin.ID, principal.Admin.Username, principal.Role.Name, adminbiz.AuditStatusPending)
+	if err := a.audit.Record(c.Request.Context(), entry); err != nil {
+		a.logger.Errorf("Failed to record audit intent for %s %s by admin %d (request %s): %v",
+			entry.Method, entry.Path, entry.ActorID, entry.RequestID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "audit log unavailable, request rejected"})
+		c.Abort()
+		return false
+	}
+	c.Set(auditPendingKey, true)
+	return true
+}
+
+// recordLoginAudit 記錄管理員登入結果，登入前沒有已認證的管理員，操作者使用登入的帳號
+// 登入成功時必須在返回令牌前寫入成功，否則不簽發令牌
+func (a *AdminAuth) recordLoginAudit(c *gin.Context, username string, result *adminbiz.LoginResult, status int) error {
+	if a.audit == nil {
+		return nil
+	}
+	entry := a.newAuditEntry(c, 0, username, "", status)
+	if result != nil {
+		entry.ActorID, entry.ActorName = result.Admin.ID, result.Admin.Username
+		if result.Role != nil {
+			entry.Role = result.Role.Name
+		}
+		entry.Target = "admin:" + strconv.FormatInt(result.Admin.ID, 10)
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), auditWriteTimeout)
+	defer cancel()
+	return a.audit.Record(ctx, entry)
+}
+
+// newAuditEntry 用請求信息填充審計記錄，操作對象默認使用路由參數
+func (a *AdminAuth) newAuditEntry(c *gin.Context, actorID int64, actorName, role string, status int) *adminbiz.AuditEntry {
+	return &adminbiz.AuditEntry{
+		ActorID:   actorID,
+		ActorName: actorName,
+		Role:      role,
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		Path:      c.Request.URL.Path,
+		Target:    paramsTarget(c),
+		Status:    status,
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+	}
+}
@@ -0,0 +1,180 @@
+package admin
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogListResponse 審計日誌列表響應
+type AuditLogListResponse struct {
+	Entries []*adminbiz.AuditEntry `json:"entries"`
+	Count   int                    `json:"count"`
+	Offset  int                    `json:"offset"`
+}
+
+// auditCSVHeader 審計日誌 CSV 導出的列
+var auditCSVHeader = []string{
+	"seq", "created_at", "actor_id", "actor_name", "role", "method", "route", "path", "target",
+	"status", "ip", "request_id", "before", "after", "diff", "prev_hash", "hash",
+}
+
+// registerAuditRoutes 註冊審計日誌查詢路由
+func (s *AdminService) registerAuditRoutes(admin *gin.RouterGroup) {
+	audit := admin.Group("/audit-logs")
+	audit.Use(s.adminAuth.Require(adminbiz.PermAuditRead))
+	{
+		audit.GET("", s.ListAuditLogs)
+		audit.GET("/export", s.adminAuth.AuditAccess(), s.ExportAuditLogs)
+		audit.GET("/verify", s.VerifyAuditLogs)
+	}
+}
+
+// parseAuditFilter 解析查詢參數：actor_id、method、route、target、from、to（RFC3339）、limit、offset
+func parseAuditFilter(c *gin.Context) (adminbiz.AuditFilter, error) {
+	filter := adminbiz.AuditFilter{
+		Method: c.Query("method"),
+		Route:  c.Query("route"),
+		Target: c.Query("target"),
+	}
+
+	var err error
+	if v := c.Query("actor_id"); v != "" {
+		if filter.ActorID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return filter, fmt.Errorf("%w: invalid actor_id", adminbiz.ErrInvalidAuditFilter)
+		}
+	}
+	if v := c.Query("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("%w: from must be RFC3339", adminbiz.ErrInvalidAuditFilter)
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("%w: to must be RFC3339", adminbiz.ErrInvalidAuditFilter)
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("%w: invalid limit", adminbiz.ErrInvalidAuditFilter)
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("%w: invalid offset", adminbiz.ErrInvalidAuditFilter)
+		}
+	}
+	return filter, nil
+}
+
+// respondAuditError 把審計日誌錯誤映射為 HTTP 狀態碼
+func (s *AdminService) respondAuditError(c *gin.Context, err error) {
+	if errors.Is(err, adminbiz.ErrInvalidAuditFilter) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid filter", Message: err.Error()})
+		return
+	}
+	s.logger.Errorf("Audit log request failed: %v", err)
+	c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "Failed to read audit log",
+		Message: "Unable to read the audit log",
+	})
+}
+
+// ListAuditLogs 按條件查詢審計日誌，最新的在前
+func (s *AdminService) ListAuditLogs(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		s.respondAuditError(c, err)
+		return
+	}
+
+	entries, err := s.audit.Query(c.Request.Context(), filter)
+	if err != nil {
+		s.respondAuditError(c, err)
+		return
+	}
+	if entries == nil {
+		entries = []*adminbiz.AuditEntry{}
+	}
+	c.JSON(http.StatusOK, AuditLogListResponse{
+		Entries: entries,
+		Count:   len(entries),
+		Offset:  filter.Offset,
+	})
+}
+
+// ExportAuditLogs 按條件把審計日誌導出為 CSV，最新的在前，最多 MaxAuditExportRows 條
+func (s *AdminService) ExportAuditLogs(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		s.respondAuditError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	rows := 0
+	defer func() {
+		recordAuditChange(c, "audit_log", nil, gin.H{"query": c.Request.URL.RawQuery, "rows": rows})
+	}()
+
+	w := csv.NewWriter(c.Writer)
+	err = w.Write(auditCSVHeader)
+	if err == nil {
+		err = s.audit.Export(c.Request.Context(), filter, func(e *adminbiz.AuditEntry) error {
+			rows++
+			return w.Write([]string{
+				strconv.FormatInt(e.Seq, 10),
+				e.CreatedAt.UTC().Format(time.RFC3339Nano),
+				strconv.FormatInt(e.ActorID, 10),
+				e.ActorName,
+				e.Role,
+				e.Method,
+				e.Route,
+				e.Path,
+				e.Target,
+				strconv.Itoa(e.Status),
+				e.IP,
+				e.RequestID,
+				string(e.Before),
+				string(e.After),
+				string(e.Diff),
+				e.PrevHash,
+				e.Hash,
+			})
+		})
+	}
+	if err != nil {
+		// 還沒有寫出任何數據時返回 JSON 錯誤，否則只能截斷導出
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			s.respondAuditError(c, err)
+			return
+		}
+		s.logger.Errorf("Audit log export interrupted: %v", err)
+		return
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		s.logger.Errorf("Failed to write audit log export: %v", err)
+	}
+}
+
+// VerifyAuditLogs 校驗審計日誌的序號和雜湊鏈，valid 為 false 時 broken_seq 為第一個斷點
+func (s *AdminService) VerifyAuditLogs(c *gin.Context) {
+	result, err := s.audit.Verify(c.Request.Context())
+	if err != nil {
+		s.respondAuditError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
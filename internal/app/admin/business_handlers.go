@@ -1,22 +1,21 @@
 package admin
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	gamebiz "github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/b7777777v/fish_server/internal/biz/player"
 	"github.com/gin-gonic/gin"
 )
 
-// PlayerResponse 玩家信息響應
-type PlayerResponse struct {
-	ID           uint   `json:"id"`
-	Username     string `json:"username"`
-	Email        string `json:"email"`
-	Status       int    `json:"status"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
-	WalletsCount int    `json:"wallets_count,omitempty"`
-}
+// spectateTokenTTL 客服觀戰令牌有效期（只需覆蓋建立連接的時間）
+const spectateTokenTTL = 10 * time.Minute
 
 // WalletResponse 錢包信息響應
 type WalletResponse struct {
@@ -61,12 +60,19 @@ type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// LoginResponse 玩家登入響應，令牌綁定登入會話，過期後用 refresh_token 到 /api/v1/auth/refresh 輪換
 type LoginResponse struct {
-	Token         string `json:"token"`
-	GameServerURL string `json:"game_server_url"`
+	Token            string `json:"token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`         // 訪問令牌有效期（秒）
+	RefreshExpiresIn int64  `json:"refresh_expires_in"` // 刷新令牌閒置有效期（秒）
+	SessionID        string `json:"session_id"`
+	GameServerURL    string `json:"game_server_url"`
+	GameServerID     string `json:"game_server_id,omitempty"`
 }
 
-// Login handles player login.
+// Login 玩家登入並返回路由到的 Game Server
+// 與 /api/v1/auth/login 使用同一個登入流程（失敗限流、封禁檢查和登入會話）
 func (s *AdminService) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -77,55 +83,167 @@ func (s *AdminService) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := s.playerUC.Login(c.Request.Context(), req.Username, req.Password)
+	tokens, err := s.accountHandler.accountUsecase.Login(c.Request.Context(), req.Username, req.Password, clientInfo(c))
 	if err != nil {
-		s.logger.Errorf("Failed to login player: %v", err)
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "Invalid credentials",
-			Message: "Invalid username or password",
-		})
+		respondLoginError(c, err)
 		return
 	}
 
+	gameServerURL, gameServerID := s.routeGameServer(c.Request.Context(), req.RoomID)
+
+	response := LoginResponse{
+		Token:            tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		ExpiresIn:        tokens.ExpiresIn,
+		RefreshExpiresIn: tokens.RefreshExpiresIn,
+		SessionID:        tokens.SessionID,
+		GameServerURL:    gameServerURL,
+		GameServerID:     gameServerID,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// routeGameServer 通過大廳路由選擇 Game Server
+// 指定的房間不在任何在線節點時改為選擇負載最低的節點；集群中沒有節點時回退到本機配置的地址
+func (s *AdminService) routeGameServer(ctx context.Context, roomID string) (string, string) {
+	if s.lobbyHandler != nil {
+		node, err := s.lobbyHandler.lobbyUsecase.RouteGameServer(ctx, roomID)
+		if err != nil && roomID != "" {
+			s.logger.Warnf("Failed to route room %s, falling back to least loaded node: %v", roomID, err)
+			node, err = s.lobbyHandler.lobbyUsecase.RouteGameServer(ctx, "")
+		}
+		if err == nil {
+			return node.URL, node.NodeID
+		}
+		s.logger.Warnf("No game server routed, using local address: %v", err)
+	}
+
 	gameServerURL := "ws://localhost:9090/ws"
 	if s.config.Server != nil && s.config.Server.Game != nil {
 		gameServerURL = "ws://localhost:" + strconv.Itoa(s.config.Server.Game.Port) + "/ws"
 	}
+	return gameServerURL, ""
+}
 
-	response := LoginResponse{
-		Token:         token,
-		GameServerURL: gameServerURL,
+// SpectateRoomResponse 客服觀戰響應
+type SpectateRoomResponse struct {
+	RoomID        string `json:"room_id"`
+	Token         string `json:"token"`
+	GameServerURL string `json:"game_server_url"`
+	GameServerID  string `json:"game_server_id,omitempty"`
+	ExpiresIn     int64  `json:"expires_in"` // 令牌有效期（秒）
+}
+
+// RoomHistoryResponse 房間狀態轉換歷史響應
+type RoomHistoryResponse struct {
+	RoomID  string                   `json:"room_id"`
+	Status  string                   `json:"status"` // 最近一次轉換後的狀態
+	History []gamebiz.RoomTransition `json:"history"`
+	Count   int                      `json:"count"`
+}
+
+// SpectateRoom 簽發客服觀戰令牌：連接房間所在的 Game Server 後發送 WATCH_ROOM 即可觀戰
+// 客服觀戰不受房間觀戰人數上限限制，但不能入座或進行遊戲操作
+func (s *AdminService) SpectateRoom(c *gin.Context) {
+	roomID := c.Param("id")
+	ctx := c.Request.Context()
+
+	var gameServerURL, gameServerID string
+	var err error
+	if s.lobbyHandler != nil {
+		var node *lobby.GameNode
+		node, err = s.lobbyHandler.lobbyUsecase.RouteGameServer(ctx, roomID)
+		if err == nil {
+			gameServerURL, gameServerID = node.URL, node.NodeID
+		}
+	}
+	if errors.Is(err, lobby.ErrRoomNotRouted) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Room not found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if gameServerURL == "" {
+		// 未啟用集群時回退到本機地址
+		gameServerURL, gameServerID = s.routeGameServer(ctx, "")
 	}
 
-	c.JSON(http.StatusOK, response)
+	adminID := c.GetInt64("admin_id")
+	token, err := s.tokenHelper.GenerateSpectateToken(adminID, roomID, spectateTokenTTL)
+	if err != nil {
+		s.logger.Errorf("Failed to generate spectate token: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to generate token",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	s.logger.Infof("Admin %d issued spectate token for room %s", adminID, roomID)
+	c.JSON(http.StatusOK, SpectateRoomResponse{
+		RoomID:        roomID,
+		Token:         token,
+		GameServerURL: gameServerURL,
+		GameServerID:  gameServerID,
+		ExpiresIn:     int64(spectateTokenTTL.Seconds()),
+	})
 }
 
-// GetPlayer 獲取玩家信息
-func (s *AdminService) GetPlayer(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+// GetRoomHistory 查詢房間的狀態轉換歷史（房間關閉後保留 24 小時）
+func (s *AdminService) GetRoomHistory(c *gin.Context) {
+	roomID := c.Param("id")
+	history, err := s.roomHistory.RoomHistory(c.Request.Context(), roomID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid player ID",
-			Message: "Player ID must be a valid number",
+		s.logger.Errorf("Failed to get history of room %s: %v", roomID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get room history",
+			Message: err.Error(),
+		})
+		return
+	}
+	if len(history) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Room not found",
+			Message: "no lifecycle history for room " + roomID,
 		})
 		return
 	}
 
-	// 這裡需要在 PlayerUsecase 中添加 GetPlayer 方法
-	// 暫時返回模擬數據
-	s.logger.Infof("Getting player info for ID: %d", id)
+	c.JSON(http.StatusOK, RoomHistoryResponse{
+		RoomID:  roomID,
+		Status:  string(history[len(history)-1].To),
+		History: history,
+		Count:   len(history),
+	})
+}
 
-	response := PlayerResponse{
-		ID:        uint(id),
-		Username:  "player_" + idStr, // 模擬數據
-		Email:     "player" + idStr + "@example.com",
-		Status:    1,
-		CreatedAt: "2024-01-01T00:00:00Z",
-		UpdatedAt: "2024-01-01T00:00:00Z",
+// GetPlayer 獲取玩家資料（帳號、等級和主錢包餘額）
+func (s *AdminService) GetPlayer(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	profile, err := s.playerUC.GetProfile(c.Request.Context(), userID)
+	if errors.Is(err, player.ErrPlayerNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Player not found",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to get player %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to get player",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
 }
 
 // GetPlayerWallets 獲取玩家的錢包列表
@@ -275,24 +393,153 @@ func (s *AdminService) CreatePlayer(c *gin.Context) {
 	c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Not Implemented"})
 }
 
+// UpdatePlayerRequest 更新玩家資料請求，空字段保持不變
+type UpdatePlayerRequest struct {
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+}
+
 // UpdatePlayer 更新玩家信息
 func (s *AdminService) UpdatePlayer(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Not Implemented"})
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePlayerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	before := s.playerAuditSnapshot(c.Request.Context(), userID)
+	err := s.accounts.UpdateUser(c.Request.Context(), userID, req.Nickname, req.AvatarURL)
+	recordAuditChange(c, playerAuditTarget(userID), before, s.playerAuditSnapshot(c.Request.Context(), userID))
+	if err != nil {
+		s.respondSanctionError(c, "Failed to update player", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Player updated successfully",
+		"player_id": userID,
+	})
 }
 
-// DeletePlayer 刪除玩家
+// DeletePlayer 刪除玩家：停用帳號（保留數據），撤銷所有會話並踢出遊戲連接
 func (s *AdminService) DeletePlayer(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Not Implemented"})
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	before := s.playerAuditSnapshot(c.Request.Context(), userID)
+	if err := s.accounts.DisableUser(c.Request.Context(), userID); err != nil {
+		s.respondSanctionError(c, "Failed to delete player", err)
+		return
+	}
+	recordAuditChange(c, playerAuditTarget(userID), before, nil)
+
+	s.logger.Infof("Player %d has been disabled by %s", userID, adminActor(c))
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Player deleted successfully",
+		"player_id": userID,
+	})
 }
 
-// BanPlayer 封禁玩家
+// BanPlayerRequest 封禁玩家請求
+type BanPlayerRequest struct {
+	Reason          string `json:"reason" binding:"required"`
+	DurationSeconds int64  `json:"duration_seconds"` // 0 為永久封禁
+}
+
+// BanPlayer 封禁玩家，已連接的遊戲客戶端會立即被斷開
 func (s *AdminService) BanPlayer(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Not Implemented"})
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	var req BanPlayerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	s.issueSanction(c, userID, account.SanctionRequest{
+		Type:     account.SanctionBan,
+		Reason:   req.Reason,
+		Duration: time.Duration(req.DurationSeconds) * time.Second,
+	})
+}
+
+// UnbanPlayerRequest 解封玩家請求
+type UnbanPlayerRequest struct {
+	Reason string `json:"reason"`
 }
 
-// UnbanPlayer 解封玩家
+// UnbanPlayer 解封玩家（解除所有生效中的封禁）
 func (s *AdminService) UnbanPlayer(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Not Implemented"})
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UnbanPlayerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	before := s.sanctionAuditSnapshot(c.Request.Context(), userID)
+	lifted, err := s.sanctions.LiftType(c.Request.Context(), userID, account.SanctionBan, adminID(c), req.Reason)
+	recordAuditChange(c, playerAuditTarget(userID), before, s.sanctionAuditSnapshot(c.Request.Context(), userID))
+	if err != nil {
+		s.respondSanctionError(c, "Failed to unban player", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Player unbanned successfully",
+		"player_id": userID,
+		"lifted":    lifted,
+	})
+}
+
+// walletAuditState 審計日誌中記錄的錢包狀態
+type walletAuditState struct {
+	Balance  float64 `json:"balance"`
+	Currency string  `json:"currency"`
+	Status   int     `json:"status"`
+}
+
+// walletAuditTarget 錢包在審計日誌中的操作對象
+func walletAuditTarget(id uint) string {
+	return "wallet:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// walletAuditSnapshot 讀取錢包當前狀態用於審計，讀取失敗時返回 nil
+func (s *AdminService) walletAuditSnapshot(ctx context.Context, id uint) interface{} {
+	wallet, err := s.walletUC.GetWallet(ctx, id)
+	if err != nil || wallet == nil {
+		return nil
+	}
+	return &walletAuditState{
+		Balance:  wallet.Balance,
+		Currency: wallet.Currency,
+		Status:   int(wallet.Status),
+	}
 }
 
 // FreezeWallet 凍結錢包
@@ -307,7 +554,9 @@ func (s *AdminService) FreezeWallet(c *gin.Context) {
 		return
 	}
 
+	before := s.walletAuditSnapshot(c.Request.Context(), uint(id))
 	err = s.walletUC.FreezeWallet(c.Request.Context(), uint(id))
+	recordAuditChange(c, walletAuditTarget(uint(id)), before, s.walletAuditSnapshot(c.Request.Context(), uint(id)))
 	if err != nil {
 		s.logger.Errorf("Failed to freeze wallet %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -336,7 +585,9 @@ func (s *AdminService) UnfreezeWallet(c *gin.Context) {
 		return
 	}
 
+	before := s.walletAuditSnapshot(c.Request.Context(), uint(id))
 	err = s.walletUC.UnfreezeWallet(c.Request.Context(), uint(id))
+	recordAuditChange(c, walletAuditTarget(uint(id)), before, s.walletAuditSnapshot(c.Request.Context(), uint(id)))
 	if err != nil {
 		s.logger.Errorf("Failed to unfreeze wallet %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -386,7 +637,9 @@ func (s *AdminService) DepositToWallet(c *gin.Context) {
 	}
 	req.Metadata["admin_operation"] = true
 
+	before := s.walletAuditSnapshot(c.Request.Context(), uint(id))
 	err = s.walletUC.Deposit(c.Request.Context(), uint(id), req.Amount, req.Type, req.ReferenceID, req.Description, req.Metadata)
+	recordAuditChange(c, walletAuditTarget(uint(id)), before, s.walletAuditSnapshot(c.Request.Context(), uint(id)))
 	if err != nil {
 		s.logger.Errorf("Failed to deposit to wallet %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -437,7 +690,16 @@ func (s *AdminService) WithdrawFromWallet(c *gin.Context) {
 	}
 	req.Metadata["admin_operation"] = true
 
+	before := s.walletAuditSnapshot(c.Request.Context(), uint(id))
 	err = s.walletUC.Withdraw(c.Request.Context(), uint(id), req.Amount, req.Type, req.ReferenceID, req.Description, req.Metadata)
+	recordAuditChange(c, walletAuditTarget(uint(id)), before, s.walletAuditSnapshot(c.Request.Context(), uint(id)))
+	if errors.Is(err, account.ErrWithdrawBlocked) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Withdrawal blocked",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		s.logger.Errorf("Failed to withdraw from wallet %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
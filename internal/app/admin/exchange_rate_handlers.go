@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/gin-gonic/gin"
+)
+
+// SetExchangeRateRequest 設置兌換比例請求
+type SetExchangeRateRequest struct {
+	Rate    float64 `json:"rate" binding:"required"`
+	Enabled *bool   `json:"enabled"` // 不傳時為啟用
+}
+
+// CreatePlayerWalletRequest 為玩家開立幣種錢包請求
+type CreatePlayerWalletRequest struct {
+	Currency string `json:"currency" binding:"required"`
+}
+
+// ListCurrencies 支持的幣種
+func (s *AdminService) ListCurrencies(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"currencies": wallet.Currencies()})
+}
+
+// ListExchangeRates 所有兌換比例（包括停用的）
+func (s *AdminService) ListExchangeRates(c *gin.Context) {
+	rates, err := s.exchange.ListRates(c.Request.Context())
+	if err != nil {
+		s.respondExchangeError(c, "Failed to list exchange rates", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rates": rates, "count": len(rates)})
+}
+
+// SetExchangeRate 新增或修改一個方向的兌換比例
+func (s *AdminService) SetExchangeRate(c *gin.Context) {
+	var req SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rate := &wallet.ExchangeRate{
+		FromCurrency: wallet.NormalizeCurrency(c.Param("from")),
+		ToCurrency:   wallet.NormalizeCurrency(c.Param("to")),
+		Rate:         req.Rate,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+		UpdatedBy:    adminActor(c),
+	}
+	ctx := c.Request.Context()
+	before := s.exchangeRateAuditSnapshot(ctx, rate.FromCurrency, rate.ToCurrency)
+	err := s.exchange.SetRate(ctx, rate)
+	recordAuditChange(c, exchangeRateAuditTarget(rate.FromCurrency, rate.ToCurrency), before, s.exchangeRateAuditSnapshot(ctx, rate.FromCurrency, rate.ToCurrency))
+	if err != nil {
+		s.respondExchangeError(c, "Failed to set exchange rate", err)
+		return
+	}
+	c.JSON(http.StatusOK, rate)
+}
+
+// DeleteExchangeRate 刪除一個方向的兌換比例，之後該方向不能兌換
+func (s *AdminService) DeleteExchangeRate(c *gin.Context) {
+	from := wallet.NormalizeCurrency(c.Param("from"))
+	to := wallet.NormalizeCurrency(c.Param("to"))
+	ctx := c.Request.Context()
+
+	before := s.exchangeRateAuditSnapshot(ctx, from, to)
+	err := s.exchange.DeleteRate(ctx, from, to)
+	recordAuditChange(c, exchangeRateAuditTarget(from, to), before, nil)
+	if err != nil {
+		s.respondExchangeError(c, "Failed to delete exchange rate", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exchange rate deleted",
+		"from":    from,
+		"to":      to,
+	})
+}
+
+// CreatePlayerWallet 為玩家開立指定幣種的錢包，已存在時返回現有錢包
+func (s *AdminService) CreatePlayerWallet(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePlayerWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := s.accounts.GetUserByID(c.Request.Context(), userID); err != nil {
+		s.respondSanctionError(c, "Failed to create wallet", err)
+		return
+	}
+	w, err := s.walletUC.CreateWallet(c.Request.Context(), uint(userID), req.Currency)
+	if err != nil {
+		s.respondExchangeError(c, "Failed to create wallet", err)
+		return
+	}
+	recordAuditChange(c, walletAuditTarget(w.ID), nil, s.walletAuditSnapshot(c.Request.Context(), w.ID))
+	c.JSON(http.StatusOK, WalletResponse{
+		ID:        w.ID,
+		UserID:    w.UserID,
+		Balance:   w.Balance,
+		Currency:  w.Currency,
+		Status:    int(w.Status),
+		CreatedAt: w.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: w.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	})
+}
+
+// exchangeRateAuditTarget 兌換比例的審計對象
+func exchangeRateAuditTarget(from, to string) string {
+	return "exchange_rate:" + from + "-" + to
+}
+
+// exchangeRateAuditSnapshot 讀取兌換比例用於審計，不存在或讀取失敗時返回 nil
+func (s *AdminService) exchangeRateAuditSnapshot(ctx context.Context, from, to string) interface{} {
+	rates, err := s.exchange.ListRates(ctx)
+	if err != nil {
+		return nil
+	}
+	for _, rate := range rates {
+		if rate.FromCurrency == from && rate.ToCurrency == to {
+			return rate
+		}
+	}
+	return nil
+}
+
+// respondExchangeError 將幣種和兌換錯誤轉換為 HTTP 響應
+func (s *AdminService) respondExchangeError(c *gin.Context, message string, err error) {
+	status := exchangeErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		s.logger.Errorf("%s: %v", message, err)
+	}
+	c.JSON(status, ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
@@ -1,10 +1,12 @@
 package admin
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"time"
 
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
 	"github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/gin-gonic/gin"
 )
@@ -24,19 +26,39 @@ func NewFishTideHandler(repo game.FishTideRepo, manager game.FishTideManager) *F
 }
 
 // RegisterFishTideRoutes 註冊魚潮相關的路由
-func RegisterFishTideRoutes(r *gin.Engine, handler *FishTideHandler, lobbyHandler *LobbyHandler) {
+func RegisterFishTideRoutes(r *gin.Engine, handler *FishTideHandler, adminAuth *AdminAuth) {
+	require := adminAuth.Require
 	admin := r.Group("/api/v1/admin")
-	admin.Use(lobbyHandler.adminAuthMiddleware()) // 應用管理員認證中間件
+	admin.Use(adminAuth.Audit(), adminAuth.Authenticate())
 	{
-		admin.GET("/fish-tides", handler.handleGetFishTides)
-		admin.POST("/fish-tides", handler.handleCreateFishTide)
-		admin.PUT("/fish-tides/:id", handler.handleUpdateFishTide)
-		admin.DELETE("/fish-tides/:id", handler.handleDeleteFishTide)
-		admin.POST("/fish-tides/:id/start", handler.handleStartFishTide)
-		admin.POST("/fish-tides/:id/stop", handler.handleStopFishTide)
+		admin.GET("/fish-tides", require(adminbiz.PermFishTideRead), handler.handleGetFishTides)
+		admin.POST("/fish-tides", require(adminbiz.PermFishTideWrite), handler.handleCreateFishTide)
+		admin.PUT("/fish-tides/:id", require(adminbiz.PermFishTideWrite), handler.handleUpdateFishTide)
+		admin.DELETE("/fish-tides/:id", require(adminbiz.PermFishTideWrite), handler.handleDeleteFishTide)
+		admin.POST("/fish-tides/:id/start", require(adminbiz.PermFishTideWrite), handler.handleStartFishTide)
+		admin.POST("/fish-tides/:id/stop", require(adminbiz.PermFishTideWrite), handler.handleStopFishTide)
 	}
 }
 
+// fishTideAuditTarget 魚潮配置在審計日誌中的操作對象
+func fishTideAuditTarget(id int64) string {
+	return "fish_tide:" + strconv.FormatInt(id, 10)
+}
+
+// roomAuditTarget 房間在審計日誌中的操作對象
+func roomAuditTarget(roomID string) string {
+	return "room:" + roomID
+}
+
+// activeTideSnapshot 房間當前進行中的魚潮，用於審計；沒有魚潮時返回 nil
+func (h *FishTideHandler) activeTideSnapshot(ctx context.Context, roomID string) interface{} {
+	tide, err := h.manager.GetActiveTide(ctx, roomID)
+	if err != nil || tide == nil {
+		return nil
+	}
+	return tide
+}
+
 // CreateFishTideRequest 建立魚潮請求
 type CreateFishTideRequest struct {
 	Name            string  `json:"name" binding:"required"`
@@ -104,6 +126,7 @@ func (h *FishTideHandler) handleCreateFishTide(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recordAuditChange(c, fishTideAuditTarget(tide.ID), nil, tide)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "fish tide created successfully",
@@ -133,6 +156,7 @@ func (h *FishTideHandler) handleUpdateFishTide(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	before := *tide
 
 	// 更新字段（僅更新提供的字段）
 	if req.Name != "" {
@@ -164,6 +188,7 @@ func (h *FishTideHandler) handleUpdateFishTide(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recordAuditChange(c, fishTideAuditTarget(id), &before, tide)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "fish tide updated successfully",
@@ -181,10 +206,12 @@ func (h *FishTideHandler) handleDeleteFishTide(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.repo.GetTideByID(c.Request.Context(), id)
 	if err := h.repo.DeleteTide(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	recordAuditChange(c, fishTideAuditTarget(id), before, nil)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "fish tide deleted successfully",
@@ -207,10 +234,12 @@ func (h *FishTideHandler) handleStartFishTide(c *gin.Context) {
 		return
 	}
 
+	before := h.activeTideSnapshot(c.Request.Context(), req.RoomID)
 	if err := h.manager.StartTide(c.Request.Context(), req.RoomID, id); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	recordAuditChange(c, roomAuditTarget(req.RoomID), before, h.activeTideSnapshot(c.Request.Context(), req.RoomID))
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "fish tide started successfully",
@@ -227,10 +256,12 @@ func (h *FishTideHandler) handleStopFishTide(c *gin.Context) {
 		return
 	}
 
+	before := h.activeTideSnapshot(c.Request.Context(), req.RoomID)
 	if err := h.manager.StopTide(c.Request.Context(), req.RoomID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	recordAuditChange(c, roomAuditTarget(req.RoomID), before, h.activeTideSnapshot(c.Request.Context(), req.RoomID))
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "fish tide stopped successfully",
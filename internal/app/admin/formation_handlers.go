@@ -9,6 +9,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// formationAuditTarget 陣型配置修改在審計日誌中的操作對象
+const formationAuditTarget = "formation_config"
+
 // FormationConfigResponse 陣型配置響應
 type FormationConfigResponse struct {
 	Enabled                  bool                               `json:"enabled"`
@@ -92,6 +95,7 @@ func (s *AdminService) UpdateFormationConfig(c *gin.Context) {
 
 	// Get current config
 	currentConfig := s.gameApp.GetGameUsecase().GetFormationConfig()
+	before := formatFormationConfigResponse(currentConfig)
 
 	// Apply updates (only update non-nil fields)
 	if req.Enabled != nil {
@@ -166,6 +170,7 @@ func (s *AdminService) UpdateFormationConfig(c *gin.Context) {
 
 	// 2. 熱更新：應用配置到 Spawner
 	s.gameApp.GetGameUsecase().UpdateFormationConfig(currentConfig)
+	recordAuditChange(c, formationAuditTarget, before, formatFormationConfigResponse(currentConfig))
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -202,6 +207,8 @@ func (s *AdminService) SetFormationDifficulty(c *gin.Context) {
 		return
 	}
 
+	before := formatFormationConfigResponse(s.gameApp.GetGameUsecase().GetFormationConfig())
+
 	// 1. 設置難度並熱更新
 	if err := s.gameApp.GetGameUsecase().SetFormationDifficulty(req.Difficulty); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -214,6 +221,7 @@ func (s *AdminService) SetFormationDifficulty(c *gin.Context) {
 
 	// 2. 獲取更新後的配置
 	newConfig := s.gameApp.GetGameUsecase().GetFormationConfig()
+	recordAuditChange(c, formationAuditTarget, before, formatFormationConfigResponse(newConfig))
 
 	// 3. 保存配置到 DB + Redis
 	ctx := c.Request.Context()
@@ -259,12 +267,15 @@ func (s *AdminService) SetFormationSpawnRate(c *gin.Context) {
 		return
 	}
 
+	before := formatFormationConfigResponse(s.gameApp.GetGameUsecase().GetFormationConfig())
+
 	// 1. 更新生成率並熱更新
 	s.gameApp.GetGameUsecase().SetFormationSpawnRate(req.MinInterval, req.MaxInterval, req.BaseChance)
 
 	// 2. 保存配置到 DB + Redis
 	ctx := c.Request.Context()
 	updatedConfig := s.gameApp.GetGameUsecase().GetFormationConfig()
+	recordAuditChange(c, formationAuditTarget, before, formatFormationConfigResponse(updatedConfig))
 	if err := s.formationConfigSvc.SaveConfig(ctx, &updatedConfig); err != nil {
 		s.logger.Errorf("Failed to persist config after spawn rate change: %v", err)
 	}
@@ -296,7 +307,9 @@ func (s *AdminService) EnableFormationSpawn(c *gin.Context) {
 		return
 	}
 
+	before := formatFormationConfigResponse(s.gameApp.GetGameUsecase().GetFormationConfig())
 	s.gameApp.GetGameUsecase().EnableFormationSpawn(enabled)
+	recordAuditChange(c, formationAuditTarget, before, formatFormationConfigResponse(s.gameApp.GetGameUsecase().GetFormationConfig()))
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -336,6 +349,7 @@ func (s *AdminService) TriggerSpecialFormationEvent(c *gin.Context) {
 
 	duration := time.Duration(req.Duration) * time.Second
 	s.gameApp.GetGameUsecase().TriggerSpecialFormationEvent(req.Multiplier, duration)
+	recordAuditChange(c, formationAuditTarget, nil, req)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
 	"github.com/gin-gonic/gin"
 )
 
@@ -64,6 +65,7 @@ func (s *AdminService) RegisterRoutes(r *gin.Engine) {
 	// 註冊 Account 和 Lobby 模組的路由
 	RegisterAccountRoutes(r, s.accountHandler)
 	RegisterLobbyRoutes(r, s.lobbyHandler, s.accountHandler)
+	RegisterWalletRoutes(r, s.walletHandler, s.accountHandler)
 
 	// 管理後台 API 組（公開端點）
 	adminPublic := r.Group("/admin")
@@ -71,57 +73,103 @@ func (s *AdminService) RegisterRoutes(r *gin.Engine) {
 		// 登錄端點（公開，用於獲取 token）
 		adminPublic.POST("/login", s.Login)
 
+		// 管理員登入（簽發管理員令牌）
+		adminPublic.POST("/auth/login", s.adminAuth.Audit(), s.AdminLogin)
+
 		// 健康檢查（公開，用於監控）
 		adminPublic.GET("/health", s.HealthCheck)
 		adminPublic.GET("/health/live", s.LivenessCheck)
 		adminPublic.GET("/health/ready", s.ReadinessCheck)
 	}
 
-	// 管理後台 API 組（需要認證）
+	// 管理後台 API 組（需要管理員令牌，每條路由按角色權限授權）
+	require := s.adminAuth.Require
+	stepUp := s.adminAuth.RequireStepUp()
 	admin := r.Group("/admin")
-	admin.Use(s.lobbyHandler.adminAuthMiddleware()) // 🔒 應用管理員認證中間件
+	admin.Use(s.adminAuth.Audit(), s.adminAuth.Authenticate()) // 🔒 應用審計和管理員認證中間件
 	{
-		// 伺服器狀態（需要認證）
-		admin.GET("/status", s.ServerStatus)
-		admin.GET("/metrics", s.Metrics)
+		// 當前管理員信息（所有管理員）
+		admin.GET("/auth/me", s.GetCurrentAdmin)
+
+		// 兩步驗證綁定和敏感操作驗證（所有管理員）
+		s.registerOTPRoutes(admin)
 
-		// 環境信息（需要認證）
-		admin.GET("/env", s.GetEnvironmentInfo)
+		// 伺服器狀態
+		admin.GET("/status", require(adminbiz.PermSystemRead), s.ServerStatus)
+		admin.GET("/metrics", require(adminbiz.PermSystemRead), s.Metrics)
 
-		// 玩家管理（需要管理員權限）
+		// 環境信息
+		admin.GET("/env", require(adminbiz.PermSystemRead), s.GetEnvironmentInfo)
+
+		// 玩家管理
 		players := admin.Group("/players")
 		{
-			players.GET("/:id", s.GetPlayer)
-			players.POST("/", s.CreatePlayer)
-			players.PUT("/:id", s.UpdatePlayer)
-			players.DELETE("/:id", s.DeletePlayer)
-			players.POST("/:id/ban", s.BanPlayer)
-			players.POST("/:id/unban", s.UnbanPlayer)
-			players.GET("/:id/wallets", s.GetPlayerWallets)
+			players.GET("/:id", require(adminbiz.PermPlayerRead), s.GetPlayer)
+			players.POST("/", require(adminbiz.PermPlayerWrite), s.CreatePlayer)
+			players.PUT("/:id", require(adminbiz.PermPlayerWrite), s.UpdatePlayer)
+			players.DELETE("/:id", require(adminbiz.PermPlayerWrite), s.DeletePlayer)
+			players.POST("/:id/ban", require(adminbiz.PermPlayerBan), s.BanPlayer)
+			players.POST("/:id/unban", require(adminbiz.PermPlayerBan), s.UnbanPlayer)
+			players.GET("/:id/wallets", require(adminbiz.PermPlayerRead, adminbiz.PermWalletRead), s.GetPlayerWallets)
+			players.POST("/:id/wallets", require(adminbiz.PermWalletAdjust), s.CreatePlayerWallet)
+			players.GET("/:id/sanctions", require(adminbiz.PermPlayerRead), s.ListPlayerSanctions)
+			players.POST("/:id/sanctions", require(adminbiz.PermPlayerBan), s.IssuePlayerSanction)
+			players.POST("/:id/sanctions/:sanction_id/lift", require(adminbiz.PermPlayerBan), s.LiftPlayerSanction)
+			players.GET("/:id/login-history", require(adminbiz.PermPlayerRead), s.GetPlayerLoginHistory)
+			players.GET("/:id/devices", require(adminbiz.PermPlayerRead), s.GetPlayerDevices)
+			players.POST("/:id/unlock-login", require(adminbiz.PermPlayerBan), s.UnlockPlayerLogin)
 		}
 
-		// 錢包管理（需要管理員權限）
+		// 錢包管理
 		wallets := admin.Group("/wallets")
 		{
-			wallets.GET("/:id", s.GetWallet)
-			wallets.GET("/:id/transactions", s.GetWalletTransactions)
-			wallets.POST("/:id/freeze", s.FreezeWallet)
-			wallets.POST("/:id/unfreeze", s.UnfreezeWallet)
-			wallets.POST("/:id/deposit", s.DepositToWallet)
-			wallets.POST("/:id/withdraw", s.WithdrawFromWallet)
+			wallets.GET("/:id", require(adminbiz.PermWalletRead), s.GetWallet)
+			wallets.GET("/:id/transactions", require(adminbiz.PermWalletRead), s.GetWalletTransactions)
+			wallets.POST("/:id/freeze", require(adminbiz.PermWalletFreeze), s.FreezeWallet)
+			wallets.POST("/:id/unfreeze", require(adminbiz.PermWalletFreeze), s.UnfreezeWallet)
+			wallets.POST("/:id/deposit", require(adminbiz.PermWalletAdjust), stepUp, s.DepositToWallet)
+			wallets.POST("/:id/withdraw", require(adminbiz.PermWalletAdjust), stepUp, s.WithdrawFromWallet)
 		}
 
-		// 陣型配置管理（需要管理員權限）
+		// 幣種和兌換比例
+		admin.GET("/currencies", require(adminbiz.PermWalletRead), s.ListCurrencies)
+		exchangeRates := admin.Group("/exchange-rates")
+		{
+			exchangeRates.GET("", require(adminbiz.PermWalletRead), s.ListExchangeRates)
+			exchangeRates.PUT("/:from/:to", require(adminbiz.PermWalletAdjust), stepUp, s.SetExchangeRate)
+			exchangeRates.DELETE("/:from/:to", require(adminbiz.PermWalletAdjust), stepUp, s.DeleteExchangeRate)
+		}
+
+		// 房間管理
+		rooms := admin.Group("/rooms")
+		{
+			rooms.POST("/:id/spectate", require(adminbiz.PermRoomSpectate), s.SpectateRoom)
+			rooms.GET("/:id/history", require(adminbiz.PermRoomRead), s.GetRoomHistory)
+		}
+
+		// 房間配置管理
+		s.registerRoomConfigRoutes(admin)
+
+		// 定時房間活動排程管理
+		s.registerRoomEventRoutes(admin)
+
+		// 陣型配置管理
 		formations := admin.Group("/formations")
 		{
-			formations.GET("/config", s.GetFormationConfig)
-			formations.PUT("/config", s.UpdateFormationConfig)
-			formations.POST("/difficulty", s.SetFormationDifficulty)
-			formations.POST("/spawn-rate", s.SetFormationSpawnRate)
-			formations.POST("/enable", s.EnableFormationSpawn)
-			formations.POST("/trigger-event", s.TriggerSpecialFormationEvent)
-			formations.GET("/stats", s.GetFormationStats)
+			formations.GET("/config", require(adminbiz.PermFormationRead), s.GetFormationConfig)
+			formations.PUT("/config", require(adminbiz.PermFormationWrite), s.UpdateFormationConfig)
+			formations.POST("/difficulty", require(adminbiz.PermFormationWrite), s.SetFormationDifficulty)
+			formations.POST("/spawn-rate", require(adminbiz.PermFormationWrite), s.SetFormationSpawnRate)
+			formations.POST("/enable", require(adminbiz.PermFormationWrite), s.EnableFormationSpawn)
+			formations.POST("/trigger-event", require(adminbiz.PermFormationWrite), stepUp, s.TriggerSpecialFormationEvent)
+			formations.GET("/stats", require(adminbiz.PermFormationRead), s.GetFormationStats)
 		}
+
+		// 管理員帳號和角色管理
+		s.registerAdminUserRoutes(admin)
+
+		// 審計日誌查詢、導出和校驗
+		s.registerAuditRoutes(admin)
 	}
 
 	// 根據環境條件性註冊 pprof 路由
@@ -1,84 +1,26 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
 	"github.com/b7777777v/fish_server/internal/biz/lobby"
-	"github.com/b7777777v/fish_server/internal/pkg/token"
 	"github.com/gin-gonic/gin"
 )
 
 // LobbyHandler 處理大廳相關的 HTTP 請求
 type LobbyHandler struct {
 	lobbyUsecase lobby.LobbyUsecase
-	tokenHelper  *token.TokenHelper
+	adminAuth    *AdminAuth
 }
 
 // NewLobbyHandler 建立新的 LobbyHandler
-func NewLobbyHandler(lobbyUsecase lobby.LobbyUsecase, tokenHelper *token.TokenHelper) *LobbyHandler {
+func NewLobbyHandler(lobbyUsecase lobby.LobbyUsecase, adminAuth *AdminAuth) *LobbyHandler {
 	return &LobbyHandler{
 		lobbyUsecase: lobbyUsecase,
-		tokenHelper:  tokenHelper,
-	}
-}
-
-// adminAuthMiddleware 管理員認證中間件
-// 驗證 JWT token 並檢查是否為管理員用戶
-func (h *LobbyHandler) adminAuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 從 Authorization header 獲取 token
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
-			c.Abort()
-			return
-		}
-
-		// 解析 Bearer token
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			c.Abort()
-			return
-		}
-
-		tokenString := parts[1]
-
-		// 驗證 token
-		claims, err := h.tokenHelper.ParseToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
-			return
-		}
-
-		// 檢查是否為遊客（遊客不能是管理員）
-		if claims.IsGuest {
-			c.JSON(http.StatusForbidden, gin.H{"error": "guests are not allowed to access admin APIs"})
-			c.Abort()
-			return
-		}
-
-		// 檢查管理員權限
-		// TODO: 可以根據業務需求實現更複雜的權限檢查：
-		// 1. 從數據庫查詢用戶角色
-		// 2. 使用 RBAC (Role-Based Access Control)
-		// 3. 檢查環境變數中配置的管理員 ID 列表
-		//
-		// 當前簡單實現：UserID <= 10 的用戶被視為管理員
-		// 生產環境應該使用更安全的權限系統
-		if claims.UserID > 10 {
-			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions - admin access required"})
-			c.Abort()
-			return
-		}
-
-		// 將 user_id 存入 context
-		c.Set("user_id", claims.UserID)
-		c.Set("is_admin", true)
-		c.Next()
+		adminAuth:    adminAuth,
 	}
 }
 
@@ -90,19 +32,25 @@ func RegisterLobbyRoutes(r *gin.Engine, handler *LobbyHandler, accountHandler *A
 	lobby := api.Group("/lobby")
 	{
 		lobby.GET("/rooms", handler.handleGetRoomList)
+		lobby.GET("/rooms/:id/server", handler.handleGetRoomServer)
+		lobby.GET("/invites/:code/server", handler.handleGetInviteServer)
 		lobby.GET("/announcements", handler.handleGetAnnouncements)
+		lobby.GET("/events", handler.handleGetRoomEvents)
 
 		// 玩家狀態需要認證
 		lobby.GET("/player-status", accountHandler.authMiddleware(), handler.handleGetPlayerStatus)
 	}
 
-	// 管理員路由（需要管理員權限）
+	// 管理員路由（需要管理員令牌和對應權限）
+	require := handler.adminAuth.Require
 	admin := api.Group("/admin")
-	admin.Use(handler.adminAuthMiddleware()) // 應用管理員認證中間件
+	admin.Use(handler.adminAuth.Audit(), handler.adminAuth.Authenticate())
 	{
-		admin.POST("/announcements", handler.handleCreateAnnouncement)
-		admin.PUT("/announcements/:id", handler.handleUpdateAnnouncement)
-		admin.DELETE("/announcements/:id", handler.handleDeleteAnnouncement)
+		admin.POST("/announcements", require(adminbiz.PermAnnouncementWrite), handler.handleCreateAnnouncement)
+		admin.PUT("/announcements/:id", require(adminbiz.PermAnnouncementWrite), handler.handleUpdateAnnouncement)
+		admin.DELETE("/announcements/:id", require(adminbiz.PermAnnouncementWrite), handler.handleDeleteAnnouncement)
+		admin.GET("/cluster/nodes", require(adminbiz.PermSystemRead), handler.handleGetGameNodes)
+		admin.POST("/cluster/nodes/:id/drain", require(adminbiz.PermClusterManage), handler.handleDrainGameNode)
 	}
 }
 
@@ -133,6 +81,82 @@ func (h *LobbyHandler) handleGetRoomList(c *gin.Context) {
 	})
 }
 
+// handleGetRoomServer 獲取房間所在的 Game Server
+func (h *LobbyHandler) handleGetRoomServer(c *gin.Context) {
+	node, err := h.lobbyUsecase.RouteGameServer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, lobby.ErrRoomNotRouted):
+			status = http.StatusNotFound
+		case errors.Is(err, lobby.ErrNoGameServer):
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"room_id":         c.Param("id"),
+		"game_server_id":  node.NodeID,
+		"game_server_url": node.URL,
+	})
+}
+
+// handleGetInviteServer 按邀請碼獲取私人房間所在的 Game Server
+func (h *LobbyHandler) handleGetInviteServer(c *gin.Context) {
+	room, node, err := h.lobbyUsecase.RouteInviteCode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, lobby.ErrInviteNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, lobby.ErrNoGameServer):
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"room_id":         room.RoomID,
+		"game_server_id":  node.NodeID,
+		"game_server_url": node.URL,
+	})
+}
+
+// handleGetGameNodes 獲取在線的 Game Server 節點（管理員功能）
+func (h *LobbyHandler) handleGetGameNodes(c *gin.Context) {
+	nodes, err := h.lobbyUsecase.GetGameNodes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"nodes": nodes,
+		"count": len(nodes),
+	})
+}
+
+// handleDrainGameNode 請求 Game Server 節點進入下線模式（管理員功能）
+func (h *LobbyHandler) handleDrainGameNode(c *gin.Context) {
+	nodeID := c.Param("id")
+	if err := h.lobbyUsecase.DrainGameServer(c.Request.Context(), nodeID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, lobby.ErrGameServerNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Drain requested",
+		"node_id": nodeID,
+	})
+}
+
 // handleGetPlayerStatus 獲取玩家狀態
 func (h *LobbyHandler) handleGetPlayerStatus(c *gin.Context) {
 	// 從 context 中獲取 user_id（由認證中間件設置）
@@ -173,6 +197,19 @@ func (h *LobbyHandler) handleGetAnnouncements(c *gin.Context) {
 	})
 }
 
+// handleGetRoomEvents 獲取正在進行和即將開始的定時活動
+func (h *LobbyHandler) handleGetRoomEvents(c *gin.Context) {
+	events, err := h.lobbyUsecase.GetRoomEvents(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+	})
+}
+
 // handleCreateAnnouncement 建立公告（管理員功能）
 func (h *LobbyHandler) handleCreateAnnouncement(c *gin.Context) {
 	var req CreateAnnouncementRequest
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPlayerLoginHistory 查詢玩家最近的登入記錄，包括失敗和被拒絕的嘗試
+func (s *AdminService) GetPlayerLoginHistory(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	records, err := s.security.History(c.Request.Context(), userID, limit)
+	if err != nil {
+		s.respondSanctionError(c, "Failed to get login history", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"player_id": userID,
+		"logins":    records,
+		"count":     len(records),
+	})
+}
+
+// GetPlayerDevices 查詢玩家登入成功過的設備
+func (s *AdminService) GetPlayerDevices(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	devices, err := s.security.Devices(c.Request.Context(), userID)
+	if err != nil {
+		s.respondSanctionError(c, "Failed to list devices", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"player_id": userID,
+		"devices":   devices,
+		"count":     len(devices),
+	})
+}
+
+// UnlockPlayerLogin 清除玩家帳號名的登入失敗次數和臨時鎖定，來源 IP 的限流不受影響
+func (s *AdminService) UnlockPlayerLogin(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.accounts.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		s.respondSanctionError(c, "Failed to unlock login", err)
+		return
+	}
+	if user.Username == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to unlock login",
+			Message: "player has no password login",
+		})
+		return
+	}
+
+	if err := s.security.Unlock(c.Request.Context(), user.Username); err != nil {
+		s.respondSanctionError(c, "Failed to unlock login", err)
+		return
+	}
+	recordAuditChange(c, playerAuditTarget(userID), nil, gin.H{"login_unlocked": true})
+	s.logger.Infof("Login of player %d (%s) unlocked by %s", userID, user.Username, adminActor(c))
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Login unlocked",
+		"player_id": userID,
+	})
+}
@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/gin-gonic/gin"
+)
+
+// ========================================
+// 第三方登入和身份綁定
+// ========================================
+
+// respondOAuthError 把 OAuth 錯誤映射為 HTTP 狀態碼
+func respondOAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, account.ErrOAuthProviderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, account.ErrOAuthStateInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "message": "授權已過期或無效，請重新發起授權"})
+	case errors.Is(err, account.ErrOAuthLinkGuest), errors.Is(err, account.ErrAccountBanned):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, account.ErrOAuthIdentityTaken),
+		errors.Is(err, account.ErrOAuthAlreadyLinked),
+		errors.Is(err, account.ErrLastLoginMethod):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, account.ErrOAuthIdentityNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// handleOAuthProviders 已配置的第三方平台
+func (h *AccountHandler) handleOAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.accountUsecase.OAuthProviders()})
+}
+
+// handleOAuthAuthorize 生成登入用的授權地址，客戶端跳轉後第三方帶著 code 和 state 回調
+func (h *AccountHandler) handleOAuthAuthorize(c *gin.Context) {
+	authorization, err := h.accountUsecase.AuthorizeOAuth(c.Request.Context(), c.Param("provider"),
+		account.OAuthIntent{Purpose: account.OAuthPurposeLogin})
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, authorization)
+}
+
+// handleListOAuthIdentities 當前帳號綁定的第三方身份
+func (h *AccountHandler) handleListOAuthIdentities(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		respondOAuthError(c, account.ErrOAuthLinkGuest)
+		return
+	}
+
+	identities, err := h.accountUsecase.ListOAuthIdentities(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+		"providers":  h.accountUsecase.OAuthProviders(),
+	})
+}
+
+// handleOAuthLinkAuthorize 生成綁定用的授權地址，state 與當前帳號關聯
+func (h *AccountHandler) handleOAuthLinkAuthorize(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		respondOAuthError(c, account.ErrOAuthLinkGuest)
+		return
+	}
+
+	authorization, err := h.accountUsecase.AuthorizeOAuth(c.Request.Context(), c.Param("provider"),
+		account.OAuthIntent{Purpose: account.OAuthPurposeLink, UserID: c.GetInt64("user_id")})
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, authorization)
+}
+
+// handleLinkOAuth 用綁定授權的回調結果綁定第三方身份
+func (h *AccountHandler) handleLinkOAuth(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		respondOAuthError(c, account.ErrOAuthLinkGuest)
+		return
+	}
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	identity, err := h.accountUsecase.LinkOAuth(c.Request.Context(), c.GetInt64("user_id"), req.Provider, req.Code, req.State)
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"identity": identity})
+}
+
+// handleUnlinkOAuth 解綁第三方身份
+func (h *AccountHandler) handleUnlinkOAuth(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		respondOAuthError(c, account.ErrOAuthLinkGuest)
+		return
+	}
+
+	if err := h.accountUsecase.UnlinkOAuth(c.Request.Context(), c.GetInt64("user_id"), c.Param("provider")); err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "oauth identity unlinked"})
+}
@@ -0,0 +1,182 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/gin-gonic/gin"
+)
+
+// OTPCodeRequest 提交驗證器驗證碼的請求
+type OTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// SecondFactorRequest 提交驗證碼或恢復碼之一的請求
+type SecondFactorRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// RecoveryCodesResponse 恢復碼響應，明文只返回這一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// registerOTPRoutes 註冊當前管理員的兩步驗證路由，所有管理員都可以使用
+func (s *AdminService) registerOTPRoutes(admin *gin.RouterGroup) {
+	otp := admin.Group("/auth/otp")
+	{
+		otp.GET("", s.GetOTPStatus)
+		otp.POST("/enroll", s.EnrollOTP)
+		otp.POST("/confirm", s.ConfirmOTP)
+		otp.POST("/recovery-codes", s.RegenerateRecoveryCodes)
+		otp.POST("/disable", s.DisableOTP)
+	}
+	admin.POST("/auth/step-up", s.StepUp)
+}
+
+// GetOTPStatus 當前管理員的兩步驗證狀態和剩餘恢復碼數量
+func (s *AdminService) GetOTPStatus(c *gin.Context) {
+	status, err := s.admins.GetOTPStatus(c.Request.Context(), adminID(c))
+	if err != nil {
+		s.respondOTPError(c, "Failed to get two-factor status", err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// EnrollOTP 生成兩步驗證密鑰，返回密鑰和 otpauth:// 地址，確認前不生效
+func (s *AdminService) EnrollOTP(c *gin.Context) {
+	enrollment, err := s.admins.EnrollOTP(c.Request.Context(), currentPrincipal(c))
+	if err != nil {
+		s.respondOTPError(c, "Failed to enroll two-factor authentication", err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmOTP 用驗證碼確認密鑰並啟用兩步驗證，返回恢復碼
+func (s *AdminService) ConfirmOTP(c *gin.Context) {
+	var req OTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	codes, err := s.admins.ConfirmOTP(c.Request.Context(), currentPrincipal(c), req.Code)
+	if err != nil {
+		s.respondOTPError(c, "Failed to confirm two-factor authentication", err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes 驗證驗證碼後生成新的恢復碼，舊的恢復碼失效
+func (s *AdminService) RegenerateRecoveryCodes(c *gin.Context) {
+	var req OTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	codes, err := s.admins.RegenerateRecoveryCodes(c.Request.Context(), currentPrincipal(c), req.Code)
+	if err != nil {
+		s.respondOTPError(c, "Failed to regenerate recovery codes", err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableOTP 驗證驗證碼或恢復碼後關閉兩步驗證
+func (s *AdminService) DisableOTP(c *gin.Context) {
+	var req SecondFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := s.admins.DisableOTP(c.Request.Context(), currentPrincipal(c), req.Code, req.RecoveryCode); err != nil {
+		s.respondOTPError(c, "Failed to disable two-factor authentication", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// StepUp 驗證驗證碼或恢復碼，簽發可以執行敏感操作的新令牌
+func (s *AdminService) StepUp(c *gin.Context) {
+	var req SecondFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := s.admins.StepUp(c.Request.Context(), currentPrincipal(c), req.Code, req.RecoveryCode, s.adminAuth.tokenTTL())
+	if err != nil {
+		s.respondOTPError(c, "Failed to verify two-factor code", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":              result.Token,
+		"expires_in":         result.ExpiresIn,
+		"step_up_at":         result.StepUpAt,
+		"step_up_expires_in": int64(s.adminAuth.stepUpWindow().Seconds()),
+	})
+}
+
+// ResetAdminOTP 清除其他管理員的兩步驗證，對方需要重新綁定
+func (s *AdminService) ResetAdminOTP(c *gin.Context) {
+	id, ok := adminUserID(c)
+	if !ok {
+		return
+	}
+
+	admin, err := s.admins.ResetOTP(c.Request.Context(), currentPrincipal(c), id)
+	if err != nil {
+		s.respondAdminError(c, "Failed to reset two-factor authentication", err)
+		return
+	}
+	recordAuditChange(c, "admin:"+c.Param("id"), gin.H{"otp_enabled": true}, gin.H{"otp_enabled": false})
+	c.JSON(http.StatusOK, admin)
+}
+
+// respondOTPError 將兩步驗證錯誤轉換為 HTTP 響應
+func (s *AdminService) respondOTPError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	code := ""
+	switch {
+	case errors.Is(err, adminbiz.ErrOTPRequired):
+		status, code = http.StatusBadRequest, "otp_required"
+	case errors.Is(err, adminbiz.ErrInvalidOTP):
+		status, code = http.StatusUnauthorized, "otp_invalid"
+	case errors.Is(err, adminbiz.ErrOTPLocked):
+		status, code = http.StatusTooManyRequests, "otp_locked"
+	case errors.Is(err, adminbiz.ErrOTPNotEnrolled):
+		status, code = http.StatusConflict, "otp_not_enrolled"
+	case errors.Is(err, adminbiz.ErrOTPAlreadyEnabled):
+		status, code = http.StatusConflict, "otp_already_enabled"
+	case errors.Is(err, adminbiz.ErrAdminNotFound):
+		status = http.StatusNotFound
+	default:
+		s.logger.Errorf("%s: %v", message, err)
+	}
+
+	c.JSON(status, ErrorResponse{
+		Error:   message,
+		Code:    code,
+		Message: err.Error(),
+	})
+}
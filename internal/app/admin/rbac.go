@@ -0,0 +1,202 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/b7777777v/fish_server/internal/pkg/token"
+	"github.com/gin-gonic/gin"
+)
+
+// principalKey gin context 中保存已認證管理員的 key
+const principalKey = "admin_principal"
+
+// AdminAuth 管理後台認證、權限檢查和操作審計
+// 管理員使用 /admin/auth/login 簽發的管理員令牌，玩家令牌不能訪問管理後台
+type AdminAuth struct {
+	admins      *adminbiz.AdminUsecase
+	audit       *adminbiz.AuditUsecase
+	tokenHelper *token.TokenHelper
+	config      *conf.AdminAuth
+	logger      logger.Logger
+}
+
+// NewAdminAuth 建立新的 AdminAuth
+func NewAdminAuth(admins *adminbiz.AdminUsecase, audit *adminbiz.AuditUsecase, tokenHelper *token.TokenHelper, config *conf.Config, logger logger.Logger) *AdminAuth {
+	return &AdminAuth{
+		admins:      admins,
+		audit:       audit,
+		tokenHelper: tokenHelper,
+		config:      config.AdminAuth,
+		logger:      logger.With("module", "app/admin/auth"),
+	}
+}
+
+// ensureBootstrapAdmin 沒有任何管理員時按配置創建初始超級管理員，密碼支持 ${ENV} 形式的環境變量
+func (a *AdminAuth) ensureBootstrapAdmin() {
+	if a.config == nil {
+		return
+	}
+	password := os.ExpandEnv(a.config.BootstrapPassword)
+	if a.config.BootstrapUsername == "" || password == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := a.admins.EnsureBootstrapAdmin(ctx, a.config.BootstrapUsername, password); err != nil {
+		a.logger.Errorf("Failed to create bootstrap admin: %v", err)
+	}
+}
+
+// tokenTTL 管理員令牌有效期
+func (a *AdminAuth) tokenTTL() time.Duration {
+	if a.config == nil || a.config.TokenExpire <= 0 {
+		return 8 * time.Hour
+	}
+	return time.Duration(a.config.TokenExpire) * time.Second
+}
+
+// stepUpWindow 兩步驗證後可以執行敏感操作的時間
+func (a *AdminAuth) stepUpWindow() time.Duration {
+	if a.config == nil || a.config.StepUpWindow <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(a.config.StepUpWindow) * time.Second
+}
+
+// Authenticate 驗證管理員令牌並加載管理員當前的角色權限，請求 IP 必須在角色的 IP 白名單內
+func (a *AdminAuth) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 從 Authorization header 獲取 token
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
+			c.Abort()
+			return
+		}
+
+		// 解析 Bearer token
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+			c.Abort()
+			return
+		}
+
+		claims, err := a.tokenHelper.ParseToken(parts[1])
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.Abort()
+			return
+		}
+
+		// 玩家和遊客令牌不能訪問管理後台
+		if !claims.Admin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin token required"})
+			c.Abort()
+			return
+		}
+
+		principal, err := a.admins.Authenticate(c.Request.Context(), claims.UserID, c.ClientIP())
+		if err != nil {
+			switch {
+			case errors.Is(err, adminbiz.ErrAdminNotFound), errors.Is(err, adminbiz.ErrAdminDisabled):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "admin account is disabled or removed"})
+			case errors.Is(err, adminbiz.ErrIPNotAllowed):
+				a.logger.Warnf("Admin %d rejected from %s by the role allow-list", claims.UserID, c.ClientIP())
+				c.JSON(http.StatusForbidden, gin.H{"error": "ip address not allowed", "ip": c.ClientIP()})
+			default:
+				a.logger.Errorf("Failed to authenticate admin %d: %v", claims.UserID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to authenticate admin"})
+			}
+			c.Abort()
+			return
+		}
+
+		if claims.StepUpAt > 0 {
+			stepUpAt := time.Unix(claims.StepUpAt, 0)
+			principal.StepUpAt = &stepUpAt
+		}
+
+		c.Set(principalKey, principal)
+		c.Set("admin_id", principal.Admin.ID)
+
+		// 修改類請求在任何處理器執行前寫入審計意圖記錄
+		if auditedMethod(c.Request.Method) && !a.beginAudit(c, principal) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireStepUp 要求管理員令牌在最近 step_up_window 內通過過兩步驗證，用於充值、扣款、修改 RTP 等敏感操作
+// 未啟用兩步驗證的管理員需要先完成綁定；令牌過期的驗證通過 /admin/auth/step-up 重新驗證
+func (a *AdminAuth) RequireStepUp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := currentPrincipal(c)
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		if !principal.Admin.OTPEnabled {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "two-factor authentication required",
+				"code":  "otp_enrollment_required",
+			})
+			c.Abort()
+			return
+		}
+		if !principal.SteppedUp(time.Now(), a.stepUpWindow()) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "step-up verification required",
+				"code":  "step_up_required",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Require 要求已認證的管理員擁有所有列出的權限，必須在 Authenticate 之後使用
+func (a *AdminAuth) Require(permissions ...adminbiz.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := currentPrincipal(c)
+		if principal == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		for _, p := range permissions {
+			if !principal.Can(p) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "insufficient permissions",
+					"permission": p,
+					"role":       principal.Role.Name,
+				})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// currentPrincipal 當前請求已認證的管理員，未經過 Authenticate 時為 nil
+func currentPrincipal(c *gin.Context) *adminbiz.Principal {
+	if v, ok := c.Get(principalKey); ok {
+		if principal, ok := v.(*adminbiz.Principal); ok {
+			return principal
+		}
+	}
+	return nil
+}
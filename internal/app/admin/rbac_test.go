@@ -0,0 +1,296 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/b7777777v/fish_server/internal/pkg/token"
+)
+
+// stubAdminRepo 只支持認證所需查詢的管理員存儲
+type stubAdminRepo struct {
+	adminbiz.AdminRepo
+	admins map[int64]*adminbiz.AdminUser
+	roles  map[string]*adminbiz.Role
+}
+
+func (r *stubAdminRepo) GetAdmin(ctx context.Context, id int64) (*adminbiz.AdminUser, error) {
+	admin, ok := r.admins[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", adminbiz.ErrAdminNotFound, id)
+	}
+	return admin, nil
+}
+
+func (r *stubAdminRepo) GetRole(ctx context.Context, name string) (*adminbiz.Role, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", adminbiz.ErrRoleNotFound, name)
+	}
+	return role, nil
+}
+
+// stubAuditRepo 記憶體中的審計日誌，err 不為空時寫入失敗
+type stubAuditRepo struct {
+	adminbiz.AuditRepo
+	entries []*adminbiz.AuditEntry
+	err     error
+}
+
+func (r *stubAuditRepo) AppendAudit(ctx context.Context, entry *adminbiz.AuditEntry) error {
+	if r.err != nil {
+		return r.err
+	}
+	var prev *adminbiz.AuditEntry
+	if len(r.entries) > 0 {
+		prev = r.entries[len(r.entries)-1]
+	}
+	if err := entry.Chain(prev); err != nil {
+		return err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// setupTestAdminAuth 管理員 1 為已綁定兩步驗證的財務，管理員 2 為運營，管理員 3 已停用，
+// 管理員 4 屬於只允許 10.0.0.0/8 訪問的 office 角色
+func setupTestAdminAuth() (*AdminAuth, *token.TokenHelper, *gin.Engine) {
+	auth, tokenHelper, r, _ := setupTestAdminAuthWithAudit()
+	return auth, tokenHelper, r
+}
+
+// setupTestAdminAuthWithAudit 同 setupTestAdminAuth，並返回記錄到的審計日誌
+func setupTestAdminAuthWithAudit() (*AdminAuth, *token.TokenHelper, *gin.Engine, *stubAuditRepo) {
+	repo := &stubAdminRepo{
+		admins: map[int64]*adminbiz.AdminUser{
+			1: {ID: 1, Username: "finance", Role: adminbiz.RoleFinance, Active: true, OTPEnabled: true},
+			2: {ID: 2, Username: "operator", Role: adminbiz.RoleOperator, Active: true},
+			3: {ID: 3, Username: "former", Role: adminbiz.RoleSuperAdmin, Active: false},
+			4: {ID: 4, Username: "office", Role: "office", Active: true},
+		},
+		roles: map[string]*adminbiz.Role{
+			"office": {Name: "office", Permissions: []adminbiz.Permission{adminbiz.PermWalletRead}, AllowedIPs: []string{"10.0.0.0/8"}},
+		},
+	}
+	for _, role := range adminbiz.BuiltinRoles() {
+		repo.roles[role.Name] = role
+	}
+
+	log := logger.New(io.Discard, "info", "console")
+	tokenHelper := token.NewTokenHelper(&conf.JWT{Secret: "test-secret", Issuer: "test", Expire: 3600})
+	admins := adminbiz.NewAdminUsecase(repo, tokenHelper, log)
+	auditRepo := &stubAuditRepo{}
+	audit := adminbiz.NewAuditUsecase(auditRepo, log)
+	auth := NewAdminAuth(admins, audit, tokenHelper, &conf.Config{AdminAuth: &conf.AdminAuth{}}, log)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"admin_id": c.GetInt64("admin_id")}) }
+	deposit := func(c *gin.Context) {
+		recordAuditChange(c, "wallet:"+c.Param("id"), gin.H{"balance": 100}, gin.H{"balance": 150})
+		ok(c)
+	}
+	admin := r.Group("/admin", auth.Audit(), auth.Authenticate())
+	{
+		admin.GET("/wallets/:id", auth.Require(adminbiz.PermWalletRead), ok)
+		admin.POST("/wallets/:id/deposit", auth.Require(adminbiz.PermWalletAdjust), deposit)
+		admin.POST("/wallets/:id/withdraw", auth.Require(adminbiz.PermWalletAdjust), auth.RequireStepUp(), ok)
+		admin.PUT("/formations/config", auth.Require(adminbiz.PermFormationWrite), ok)
+		admin.GET("/audit-logs/export", auth.AuditAccess(), ok)
+	}
+	return auth, tokenHelper, r, auditRepo
+}
+
+func serveWithToken(r *gin.Engine, method, path, tokenString string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if tokenString != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAuthRoutePermissions(t *testing.T) {
+	_, tokenHelper, r := setupTestAdminAuth()
+
+	financeToken, err := tokenHelper.GenerateAdminToken(1, time.Hour)
+	require.NoError(t, err)
+	operatorToken, err := tokenHelper.GenerateAdminToken(2, time.Hour)
+	require.NoError(t, err)
+
+	// 財務可以充值但不能修改陣型
+	w := serveWithToken(r, "POST", "/admin/wallets/1/deposit", financeToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveWithToken(r, "PUT", "/admin/formations/config", financeToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, string(adminbiz.PermFormationWrite), response["permission"])
+	assert.Equal(t, adminbiz.RoleFinance, response["role"])
+
+	// 運營可以修改陣型但不能充值
+	w = serveWithToken(r, "PUT", "/admin/formations/config", operatorToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveWithToken(r, "POST", "/admin/wallets/1/deposit", operatorToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAdminAuthRejectsNonAdminTokens(t *testing.T) {
+	_, tokenHelper, r := setupTestAdminAuth()
+
+	w := serveWithToken(r, "POST", "/admin/wallets/1/deposit", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 玩家令牌即使 UserID 與管理員相同也不能訪問管理後台
+	playerToken, err := tokenHelper.GenerateTokenWithClaims(1, false)
+	require.NoError(t, err)
+	w = serveWithToken(r, "POST", "/admin/wallets/1/deposit", playerToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 停用或不存在的管理員令牌失效
+	disabledToken, err := tokenHelper.GenerateAdminToken(3, time.Hour)
+	require.NoError(t, err)
+	w = serveWithToken(r, "POST", "/admin/wallets/1/deposit", disabledToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	removedToken, err := tokenHelper.GenerateAdminToken(99, time.Hour)
+	require.NoError(t, err)
+	w = serveWithToken(r, "POST", "/admin/wallets/1/deposit", removedToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAuditRecordsMutations(t *testing.T) {
+	_, tokenHelper, r, auditRepo := setupTestAdminAuthWithAudit()
+
+	financeToken, err := tokenHelper.GenerateAdminToken(1, time.Hour)
+	require.NoError(t, err)
+
+	// 讀取請求不記錄，但仍分配請求 ID
+	w := serveWithToken(r, "GET", "/admin/wallets/7", financeToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get(requestIDHeader))
+	assert.Empty(t, auditRepo.entries)
+
+	// 修改請求記錄操作者、路由、目標和修改前後差異，沿用客戶端提供的請求 ID
+	req, _ := http.NewRequest("POST", "/admin/wallets/7/deposit", nil)
+	req.Header.Set("Authorization", "Bearer "+financeToken)
+	req.Header.Set(requestIDHeader, "req-42")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-42", w.Header().Get(requestIDHeader))
+
+	// 處理前先寫入意圖記錄，處理後寫入帶響應狀態和修改前後數據的結果記錄
+	require.Len(t, auditRepo.entries, 2)
+	intent, entry := auditRepo.entries[0], auditRepo.entries[1]
+	assert.Equal(t, adminbiz.AuditStatusPending, intent.Status)
+	assert.Equal(t, "id=7", intent.Target)
+	assert.Equal(t, "req-42", intent.RequestID)
+	assert.Empty(t, intent.Diff)
+	assert.Equal(t, int64(1), entry.ActorID)
+	assert.Equal(t, "finance", entry.ActorName)
+	assert.Equal(t, adminbiz.RoleFinance, entry.Role)
+	assert.Equal(t, "/admin/wallets/:id/deposit", entry.Route)
+	assert.Equal(t, "/admin/wallets/7/deposit", entry.Path)
+	assert.Equal(t, "wallet:7", entry.Target)
+	assert.Equal(t, http.StatusOK, entry.Status)
+	assert.Equal(t, "req-42", entry.RequestID)
+	assert.Equal(t, intent.Hash, entry.PrevHash)
+	assert.JSONEq(t, `{"balance": {"before": 100, "after": 150}}`, string(entry.Diff))
+
+	// 被拒絕的修改同樣記錄，沒有補充信息時使用路由參數作為目標
+	w = serveWithToken(r, "PUT", "/admin/formations/config", financeToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	require.Len(t, auditRepo.entries, 4)
+	assert.Equal(t, adminbiz.AuditStatusPending, auditRepo.entries[2].Status)
+	assert.Equal(t, http.StatusForbidden, auditRepo.entries[3].Status)
+	assert.Equal(t, entry.Hash, auditRepo.entries[2].PrevHash)
+
+	// 未認證的請求沒有操作者，不記錄
+	w = serveWithToken(r, "POST", "/admin/wallets/7/deposit", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotEmpty(t, w.Header().Get(requestIDHeader))
+	assert.Len(t, auditRepo.entries, 4)
+
+	// 導出審計日誌雖然是讀取請求也要記錄
+	w = serveWithToken(r, "GET", "/admin/audit-logs/export", financeToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, auditRepo.entries, 6)
+	assert.Equal(t, "/admin/audit-logs/export", auditRepo.entries[5].Route)
+}
+
+func TestAdminAuditFailsClosed(t *testing.T) {
+	_, tokenHelper, r, auditRepo := setupTestAdminAuthWithAudit()
+	auditRepo.err = fmt.Errorf("database unavailable")
+
+	financeToken, err := tokenHelper.GenerateAdminToken(1, time.Hour)
+	require.NoError(t, err)
+
+	// 意圖記錄寫入失敗時處理器不會執行
+	w := serveWithToken(r, "POST", "/admin/wallets/7/deposit", financeToken)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotContains(t, w.Body.String(), "admin_id")
+	w = serveWithToken(r, "GET", "/admin/audit-logs/export", financeToken)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// 讀取請求不受影響
+	w = serveWithToken(r, "GET", "/admin/wallets/7", financeToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminAuthRequireStepUp(t *testing.T) {
+	_, tokenHelper, r := setupTestAdminAuth()
+
+	// 登入時沒有通過兩步驗證的令牌不能扣款
+	plainToken, err := tokenHelper.GenerateAdminToken(1, time.Hour)
+	require.NoError(t, err)
+	w := serveWithToken(r, "POST", "/admin/wallets/1/withdraw", plainToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "step_up_required")
+
+	steppedUp, err := tokenHelper.GenerateAdminStepUpToken(1, time.Hour, time.Now())
+	require.NoError(t, err)
+	w = serveWithToken(r, "POST", "/admin/wallets/1/withdraw", steppedUp)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 超過 step_up_window（默認 5 分鐘）需要重新驗證
+	stale, err := tokenHelper.GenerateAdminStepUpToken(1, time.Hour, time.Now().Add(-10*time.Minute))
+	require.NoError(t, err)
+	w = serveWithToken(r, "POST", "/admin/wallets/1/withdraw", stale)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "step_up_required")
+}
+
+func TestAdminAuthRoleAllowedIPs(t *testing.T) {
+	_, tokenHelper, r := setupTestAdminAuth()
+
+	officeToken, err := tokenHelper.GenerateAdminToken(4, time.Hour)
+	require.NoError(t, err)
+
+	// httptest 請求的來源地址為 192.0.2.1，不在 office 角色的白名單內
+	w := serveWithToken(r, "GET", "/admin/wallets/1", officeToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "ip address not allowed")
+
+	req, _ := http.NewRequest("GET", "/admin/wallets/1", nil)
+	req.RemoteAddr = "10.1.2.3:40000"
+	req.Header.Set("Authorization", "Bearer "+officeToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
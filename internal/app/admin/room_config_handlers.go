@@ -0,0 +1,209 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	gamebiz "github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/gin-gonic/gin"
+)
+
+// roomConfigHistoryLimit 配置歷史默認返回的版本數
+const roomConfigHistoryLimit = 20
+
+// RoomConfigRequest 創建或修改房間配置請求
+type RoomConfigRequest struct {
+	RoomType        string             `json:"room_type"` // 創建時必填，修改時以路徑為準
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	Config          gamebiz.RoomConfig `json:"config"`
+	ExpectedVersion int64              `json:"expected_version"` // 修改時的當前版本，0 表示不檢查
+}
+
+// RoomConfigListResponse 房間配置列表響應
+type RoomConfigListResponse struct {
+	Configs []*gamebiz.RoomConfigVersion `json:"configs"`
+	Count   int                          `json:"count"`
+}
+
+// registerRoomConfigRoutes 註冊房間配置管理路由，修改配置（包括目標 RTP）需要兩步驗證
+func (s *AdminService) registerRoomConfigRoutes(admin *gin.RouterGroup) {
+	require := s.adminAuth.Require
+	stepUp := s.adminAuth.RequireStepUp()
+	roomConfigs := admin.Group("/room-configs")
+	{
+		roomConfigs.GET("", require(adminbiz.PermRoomRead), s.ListRoomConfigs)
+		roomConfigs.POST("", require(adminbiz.PermRoomConfigure), stepUp, s.CreateRoomConfig)
+		roomConfigs.GET("/:type", require(adminbiz.PermRoomRead), s.GetRoomConfig)
+		roomConfigs.PUT("/:type", require(adminbiz.PermRoomConfigure), stepUp, s.UpdateRoomConfig)
+		roomConfigs.DELETE("/:type", require(adminbiz.PermRoomConfigure), stepUp, s.DeleteRoomConfig)
+		roomConfigs.GET("/:type/versions", require(adminbiz.PermRoomRead), s.GetRoomConfigVersions)
+	}
+}
+
+// ListRoomConfigs 查詢所有房間類型的當前配置
+func (s *AdminService) ListRoomConfigs(c *gin.Context) {
+	configs, err := s.roomConfigs.List(c.Request.Context())
+	if err != nil {
+		s.respondRoomConfigError(c, "Failed to list room configs", err)
+		return
+	}
+	c.JSON(http.StatusOK, RoomConfigListResponse{Configs: configs, Count: len(configs)})
+}
+
+// GetRoomConfig 查詢房間類型的當前配置
+func (s *AdminService) GetRoomConfig(c *gin.Context) {
+	config, err := s.roomConfigs.Get(c.Request.Context(), gamebiz.RoomType(c.Param("type")))
+	if err != nil {
+		s.respondRoomConfigError(c, "Failed to get room config", err)
+		return
+	}
+	c.JSON(http.StatusOK, config)
+}
+
+// GetRoomConfigVersions 查詢房間類型最近的配置版本
+func (s *AdminService) GetRoomConfigVersions(c *gin.Context) {
+	limit := roomConfigHistoryLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid limit",
+				Message: "limit must be a positive integer",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	versions, err := s.roomConfigs.History(c.Request.Context(), gamebiz.RoomType(c.Param("type")), limit)
+	if err != nil {
+		s.respondRoomConfigError(c, "Failed to get room config versions", err)
+		return
+	}
+	c.JSON(http.StatusOK, RoomConfigListResponse{Configs: versions, Count: len(versions)})
+}
+
+// CreateRoomConfig 為房間類型創建配置
+func (s *AdminService) CreateRoomConfig(c *gin.Context) {
+	var req RoomConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	version := s.roomConfigVersion(c, gamebiz.RoomType(req.RoomType), &req)
+	before := s.roomConfigAuditSnapshot(c.Request.Context(), version.RoomType)
+	if err := s.roomConfigs.Create(c.Request.Context(), version); err != nil {
+		s.respondRoomConfigError(c, "Failed to create room config", err)
+		return
+	}
+	recordAuditChange(c, roomConfigAuditTarget(version.RoomType), before, version)
+	c.JSON(http.StatusCreated, version)
+}
+
+// UpdateRoomConfig 保存房間類型的新配置版本，運行中的房間在下一個遊戲循環週期套用
+func (s *AdminService) UpdateRoomConfig(c *gin.Context) {
+	var req RoomConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	version := s.roomConfigVersion(c, gamebiz.RoomType(c.Param("type")), &req)
+	before := s.roomConfigAuditSnapshot(c.Request.Context(), version.RoomType)
+	if err := s.roomConfigs.Update(c.Request.Context(), version, req.ExpectedVersion); err != nil {
+		s.respondRoomConfigError(c, "Failed to update room config", err)
+		return
+	}
+	recordAuditChange(c, roomConfigAuditTarget(version.RoomType), before, version)
+	c.JSON(http.StatusOK, version)
+}
+
+// DeleteRoomConfig 刪除房間類型的配置，房間回到內置默認配置
+func (s *AdminService) DeleteRoomConfig(c *gin.Context) {
+	var expectedVersion int64
+	if raw := c.Query("expected_version"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid expected_version",
+				Message: err.Error(),
+			})
+			return
+		}
+		expectedVersion = parsed
+	}
+
+	roomType := gamebiz.RoomType(c.Param("type"))
+	before := s.roomConfigAuditSnapshot(c.Request.Context(), roomType)
+	deleted, err := s.roomConfigs.Delete(c.Request.Context(), roomType, expectedVersion, adminActor(c))
+	if err != nil {
+		s.respondRoomConfigError(c, "Failed to delete room config", err)
+		return
+	}
+	recordAuditChange(c, roomConfigAuditTarget(roomType), before, s.roomConfigAuditSnapshot(c.Request.Context(), roomType))
+	c.JSON(http.StatusOK, deleted)
+}
+
+// roomConfigVersion 將請求轉換為配置版本
+func (s *AdminService) roomConfigVersion(c *gin.Context, roomType gamebiz.RoomType, req *RoomConfigRequest) *gamebiz.RoomConfigVersion {
+	return &gamebiz.RoomConfigVersion{
+		RoomType:    roomType,
+		Name:        req.Name,
+		Description: req.Description,
+		Config:      req.Config,
+		UpdatedBy:   adminActor(c),
+	}
+}
+
+// roomConfigAuditTarget 房間配置在審計日誌中的操作對象
+func roomConfigAuditTarget(roomType gamebiz.RoomType) string {
+	return "room_config:" + string(roomType)
+}
+
+// roomConfigAuditSnapshot 讀取房間類型當前生效的配置用於審計，讀取失敗時返回 nil
+func (s *AdminService) roomConfigAuditSnapshot(ctx context.Context, roomType gamebiz.RoomType) interface{} {
+	config, err := s.roomConfigs.Get(ctx, roomType)
+	if err != nil || config == nil {
+		return nil
+	}
+	return config
+}
+
+// adminActor 記錄在配置版本中的操作者
+func adminActor(c *gin.Context) string {
+	if principal := currentPrincipal(c); principal != nil {
+		return "admin:" + strconv.FormatInt(principal.Admin.ID, 10)
+	}
+	return "admin"
+}
+
+// respondRoomConfigError 將房間配置錯誤轉換為 HTTP 響應
+func (s *AdminService) respondRoomConfigError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gamebiz.ErrInvalidRoomConfig), errors.Is(err, gamebiz.ErrUnknownRoomType):
+		status = http.StatusBadRequest
+	case errors.Is(err, gamebiz.ErrRoomConfigNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, gamebiz.ErrRoomConfigExists), errors.Is(err, gamebiz.ErrRoomConfigConflict):
+		status = http.StatusConflict
+	default:
+		s.logger.Errorf("%s: %v", message, err)
+	}
+
+	c.JSON(status, ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,211 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
+	gamebiz "github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// upcomingRoomEventHours 即將開始的活動默認查詢的小時數
+	upcomingRoomEventHours = 24
+	// maxUpcomingRoomEventHours 即將開始的活動最多查詢的小時數
+	maxUpcomingRoomEventHours = 24 * 31
+)
+
+// RoomEventScheduleListResponse 活動排程列表響應
+type RoomEventScheduleListResponse struct {
+	Schedules []*gamebiz.RoomEventSchedule `json:"schedules"`
+	Count     int                          `json:"count"`
+}
+
+// UpcomingRoomEventsResponse 即將開始的活動響應
+type UpcomingRoomEventsResponse struct {
+	Events []*gamebiz.RoomEvent `json:"events"`
+	From   time.Time            `json:"from"`
+	To     time.Time            `json:"to"`
+	Count  int                  `json:"count"`
+}
+
+// registerRoomEventRoutes 註冊定時房間活動排程管理路由
+func (s *AdminService) registerRoomEventRoutes(admin *gin.RouterGroup) {
+	require := s.adminAuth.Require
+	stepUp := s.adminAuth.RequireStepUp()
+	roomEvents := admin.Group("/room-events")
+	{
+		roomEvents.GET("", require(adminbiz.PermRoomRead), s.ListRoomEventSchedules)
+		roomEvents.POST("", require(adminbiz.PermRoomConfigure), stepUp, s.CreateRoomEventSchedule)
+		roomEvents.GET("/upcoming", require(adminbiz.PermRoomRead), s.GetUpcomingRoomEvents)
+		roomEvents.GET("/:id", require(adminbiz.PermRoomRead), s.GetRoomEventSchedule)
+		roomEvents.PUT("/:id", require(adminbiz.PermRoomConfigure), stepUp, s.UpdateRoomEventSchedule)
+		roomEvents.DELETE("/:id", require(adminbiz.PermRoomConfigure), stepUp, s.DeleteRoomEventSchedule)
+	}
+}
+
+// ListRoomEventSchedules 查詢所有活動排程
+func (s *AdminService) ListRoomEventSchedules(c *gin.Context) {
+	schedules, err := s.roomEvents.List(c.Request.Context())
+	if err != nil {
+		s.respondRoomEventError(c, "Failed to list room event schedules", err)
+		return
+	}
+	c.JSON(http.StatusOK, RoomEventScheduleListResponse{Schedules: schedules, Count: len(schedules)})
+}
+
+// GetRoomEventSchedule 查詢活動排程
+func (s *AdminService) GetRoomEventSchedule(c *gin.Context) {
+	id, ok := roomEventScheduleID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := s.roomEvents.Get(c.Request.Context(), id)
+	if err != nil {
+		s.respondRoomEventError(c, "Failed to get room event schedule", err)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CreateRoomEventSchedule 創建活動排程，遊戲節點在下一次重新加載排程時生效
+func (s *AdminService) CreateRoomEventSchedule(c *gin.Context) {
+	var schedule gamebiz.RoomEventSchedule
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	schedule.ID = 0
+	schedule.UpdatedBy = adminActor(c)
+	if err := s.roomEvents.Create(c.Request.Context(), &schedule); err != nil {
+		s.respondRoomEventError(c, "Failed to create room event schedule", err)
+		return
+	}
+	recordAuditChange(c, roomEventAuditTarget(schedule.ID), nil, &schedule)
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateRoomEventSchedule 修改活動排程，正在進行的場次按新排程調整或結束
+func (s *AdminService) UpdateRoomEventSchedule(c *gin.Context) {
+	id, ok := roomEventScheduleID(c)
+	if !ok {
+		return
+	}
+
+	var schedule gamebiz.RoomEventSchedule
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	schedule.ID = id
+	schedule.UpdatedBy = adminActor(c)
+	before := s.roomEventAuditSnapshot(c.Request.Context(), id)
+	if err := s.roomEvents.Update(c.Request.Context(), &schedule); err != nil {
+		s.respondRoomEventError(c, "Failed to update room event schedule", err)
+		return
+	}
+	recordAuditChange(c, roomEventAuditTarget(id), before, &schedule)
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteRoomEventSchedule 刪除活動排程
+func (s *AdminService) DeleteRoomEventSchedule(c *gin.Context) {
+	id, ok := roomEventScheduleID(c)
+	if !ok {
+		return
+	}
+
+	before := s.roomEventAuditSnapshot(c.Request.Context(), id)
+	if err := s.roomEvents.Delete(c.Request.Context(), id, adminActor(c)); err != nil {
+		s.respondRoomEventError(c, "Failed to delete room event schedule", err)
+		return
+	}
+	recordAuditChange(c, roomEventAuditTarget(id), before, nil)
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Room event schedule deleted",
+		"schedule_id": id,
+	})
+}
+
+// GetUpcomingRoomEvents 查詢未來 hours 小時內進行的活動場次
+func (s *AdminService) GetUpcomingRoomEvents(c *gin.Context) {
+	hours := upcomingRoomEventHours
+	if raw := c.Query("hours"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxUpcomingRoomEventHours {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid hours",
+				Message: "hours must be between 1 and " + strconv.Itoa(maxUpcomingRoomEventHours),
+			})
+			return
+		}
+		hours = parsed
+	}
+
+	from := time.Now()
+	to := from.Add(time.Duration(hours) * time.Hour)
+	events, err := s.roomEvents.Upcoming(c.Request.Context(), from, to)
+	if err != nil {
+		s.respondRoomEventError(c, "Failed to list upcoming room events", err)
+		return
+	}
+	c.JSON(http.StatusOK, UpcomingRoomEventsResponse{Events: events, From: from, To: to, Count: len(events)})
+}
+
+// roomEventScheduleID 解析路徑中的排程 ID，無效時返回 400
+func roomEventScheduleID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid schedule ID",
+			Message: "schedule ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// roomEventAuditTarget 活動排程在審計日誌中的操作對象
+func roomEventAuditTarget(id int64) string {
+	return "room_event:" + strconv.FormatInt(id, 10)
+}
+
+// roomEventAuditSnapshot 讀取活動排程用於審計，不存在或讀取失敗時返回 nil
+func (s *AdminService) roomEventAuditSnapshot(ctx context.Context, id int64) interface{} {
+	schedule, err := s.roomEvents.Get(ctx, id)
+	if err != nil || schedule == nil {
+		return nil
+	}
+	return schedule
+}
+
+// respondRoomEventError 將活動排程錯誤轉換為 HTTP 響應
+func (s *AdminService) respondRoomEventError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, gamebiz.ErrInvalidRoomEvent):
+		status = http.StatusBadRequest
+	case errors.Is(err, gamebiz.ErrRoomEventNotFound):
+		status = http.StatusNotFound
+	default:
+		s.logger.Errorf("%s: %v", message, err)
+	}
+
+	c.JSON(status, ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,198 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/gin-gonic/gin"
+)
+
+// IssueSanctionRequest 發出處罰請求
+type IssueSanctionRequest struct {
+	Type            string   `json:"type" binding:"required"` // ban / mute / room_restriction / withdraw_block
+	Reason          string   `json:"reason" binding:"required"`
+	RoomTypes       []string `json:"room_types"`       // 僅 room_restriction
+	DurationSeconds int64    `json:"duration_seconds"` // 0 為永久
+}
+
+// LiftSanctionRequest 解除處罰請求
+type LiftSanctionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PlayerSanctionsResponse 玩家處罰記錄響應
+type PlayerSanctionsResponse struct {
+	Status    *account.SanctionStatus `json:"status"`
+	Sanctions []*account.Sanction     `json:"sanctions"`
+	Count     int                     `json:"count"`
+}
+
+// ListPlayerSanctions 查詢玩家的處罰記錄和當前生效的處罰
+func (s *AdminService) ListPlayerSanctions(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	sanctions, err := s.sanctions.List(c.Request.Context(), userID)
+	if err != nil {
+		s.respondSanctionError(c, "Failed to list sanctions", err)
+		return
+	}
+	status, err := s.sanctions.Status(c.Request.Context(), userID)
+	if err != nil {
+		s.respondSanctionError(c, "Failed to get sanction status", err)
+		return
+	}
+	c.JSON(http.StatusOK, PlayerSanctionsResponse{Status: status, Sanctions: sanctions, Count: len(sanctions)})
+}
+
+// IssuePlayerSanction 對玩家發出處罰
+func (s *AdminService) IssuePlayerSanction(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+
+	var req IssueSanctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	s.issueSanction(c, userID, account.SanctionRequest{
+		Type:      account.SanctionType(req.Type),
+		Reason:    req.Reason,
+		RoomTypes: req.RoomTypes,
+		Duration:  time.Duration(req.DurationSeconds) * time.Second,
+	})
+}
+
+// LiftPlayerSanction 提前解除玩家的一條處罰
+func (s *AdminService) LiftPlayerSanction(c *gin.Context) {
+	userID, ok := playerUserID(c)
+	if !ok {
+		return
+	}
+	sanctionID, err := strconv.ParseInt(c.Param("sanction_id"), 10, 64)
+	if err != nil || sanctionID <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid sanction ID",
+			Message: "sanction ID must be a positive integer",
+		})
+		return
+	}
+
+	var req LiftSanctionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	before := s.sanctionAuditSnapshot(c.Request.Context(), userID)
+	err = s.sanctions.Lift(c.Request.Context(), userID, sanctionID, adminID(c), req.Reason)
+	recordAuditChange(c, playerAuditTarget(userID), before, s.sanctionAuditSnapshot(c.Request.Context(), userID))
+	if err != nil {
+		s.respondSanctionError(c, "Failed to lift sanction", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Sanction lifted",
+		"player_id":   userID,
+		"sanction_id": sanctionID,
+	})
+}
+
+// issueSanction 確認玩家存在後發出處罰並記錄審計
+func (s *AdminService) issueSanction(c *gin.Context, userID int64, req account.SanctionRequest) {
+	ctx := c.Request.Context()
+	if _, err := s.accounts.GetUserByID(ctx, userID); err != nil {
+		s.respondSanctionError(c, "Failed to issue sanction", err)
+		return
+	}
+
+	before := s.sanctionAuditSnapshot(ctx, userID)
+	sanction, err := s.sanctions.Issue(ctx, userID, adminID(c), req)
+	recordAuditChange(c, playerAuditTarget(userID), before, s.sanctionAuditSnapshot(ctx, userID))
+	if err != nil {
+		s.respondSanctionError(c, "Failed to issue sanction", err)
+		return
+	}
+
+	s.logger.Infof("Sanction %s issued to player %d by %s", sanction.Type, userID, adminActor(c))
+	c.JSON(http.StatusCreated, sanction)
+}
+
+// playerUserID 解析路徑中的玩家帳號 ID（users.id），無效時返回 400
+func playerUserID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid player ID",
+			Message: "Player ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// adminID 當前請求的管理員 ID，未認證時為 0
+func adminID(c *gin.Context) int64 {
+	if principal := currentPrincipal(c); principal != nil {
+		return principal.Admin.ID
+	}
+	return 0
+}
+
+// playerAuditTarget 玩家在審計日誌中的操作對象
+func playerAuditTarget(userID int64) string {
+	return "player:" + strconv.FormatInt(userID, 10)
+}
+
+// playerAuditSnapshot 讀取玩家資料用於審計，讀取失敗時返回 nil
+func (s *AdminService) playerAuditSnapshot(ctx context.Context, userID int64) interface{} {
+	user, err := s.accounts.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// sanctionAuditSnapshot 讀取玩家當前生效的處罰用於審計，讀取失敗時返回 nil
+func (s *AdminService) sanctionAuditSnapshot(ctx context.Context, userID int64) interface{} {
+	status, err := s.sanctions.Status(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	return status
+}
+
+// respondSanctionError 將玩家管理和處罰錯誤轉換為 HTTP 響應
+func (s *AdminService) respondSanctionError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, account.ErrInvalidSanction):
+		status = http.StatusBadRequest
+	case errors.Is(err, account.ErrSanctionNotFound), errors.Is(err, account.ErrUserNotFound):
+		status = http.StatusNotFound
+	default:
+		s.logger.Errorf("%s: %v", message, err)
+	}
+
+	c.JSON(status, ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
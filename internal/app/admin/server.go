@@ -40,10 +40,16 @@ func (s *Server) Start() error {
 	
 	// 創建 Gin 引擎
 	s.engine = gin.New()
+	s.setupTrustedProxies()
 	
 	// 添加中間件
 	s.setupMiddleware()
 	
+	// 沒有任何管理員帳號時按配置創建初始超級管理員
+	if s.service.adminAuth != nil {
+		s.service.adminAuth.ensureBootstrapAdmin()
+	}
+
 	// 註冊路由
 	s.setupRoutes()
 	
@@ -62,6 +68,19 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// setupTrustedProxies 只信任配置的反向代理提供的 X-Forwarded-For，
+// 否則任何客戶端都可以偽造來源 IP 繞過角色的 IP 白名單
+func (s *Server) setupTrustedProxies() {
+	var proxies []string
+	if auth := s.service.config.AdminAuth; auth != nil {
+		proxies = auth.TrustedProxies
+	}
+	if err := s.engine.SetTrustedProxies(proxies); err != nil {
+		s.logger.Errorf("Invalid admin_auth.trusted_proxies %v, trusting no proxies: %v", proxies, err)
+		_ = s.engine.SetTrustedProxies(nil)
+	}
+}
+
 // Stop 停止管理後台服務器
 func (s *Server) Stop(ctx context.Context) error {
 	s.logger.Info("Stopping admin server...")
@@ -2,6 +2,8 @@ package admin
 
 import (
 	"github.com/b7777777v/fish_server/internal/app/game"
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	adminbiz "github.com/b7777777v/fish_server/internal/biz/admin"
 	gamebiz "github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/biz/player"
 	"github.com/b7777777v/fish_server/internal/biz/wallet"
@@ -16,6 +18,16 @@ type AdminService struct {
 	walletUC           *wallet.WalletUsecase
 	gameApp            *game.GameApp
 	formationConfigSvc *gamebiz.FormationConfigService // 陣型配置服務
+	roomHistory        gamebiz.RoomHistoryRepo         // 房間狀態轉換歷史
+	roomConfigs        *gamebiz.RoomConfigUsecase      // 房間配置版本管理
+	roomEvents         *gamebiz.RoomEventUsecase       // 定時房間活動排程管理
+	admins             *adminbiz.AdminUsecase          // 管理員帳號和角色管理
+	audit              *adminbiz.AuditUsecase          // 管理操作審計日誌
+	accounts           account.AccountUsecase          // 玩家帳號管理
+	sanctions          *account.SanctionUsecase        // 玩家處罰
+	security           *account.LoginSecurityUsecase   // 玩家登入記錄和登入限流
+	exchange           *wallet.ExchangeUsecase         // 幣種兌換比例
+	adminAuth          *AdminAuth                      // 管理員認證和權限檢查
 	tokenHelper        *token.TokenHelper
 	config             *conf.Config
 	logger             logger.Logger
@@ -23,6 +35,7 @@ type AdminService struct {
 	// New handlers
 	accountHandler *AccountHandler
 	lobbyHandler   *LobbyHandler
+	walletHandler  *WalletHandler
 }
 
 // NewAdminService 創建一個新的 AdminService 實例
@@ -31,22 +44,44 @@ func NewAdminService(
 	walletUC *wallet.WalletUsecase,
 	gameApp *game.GameApp,
 	formationConfigSvc *gamebiz.FormationConfigService, // 修正：使用正確的套件別名
+	roomHistory gamebiz.RoomHistoryRepo,
+	roomConfigs *gamebiz.RoomConfigUsecase,
+	roomEvents *gamebiz.RoomEventUsecase,
+	admins *adminbiz.AdminUsecase,
+	audit *adminbiz.AuditUsecase,
+	accounts account.AccountUsecase,
+	sanctions *account.SanctionUsecase,
+	security *account.LoginSecurityUsecase,
+	exchange *wallet.ExchangeUsecase,
+	adminAuth *AdminAuth,
 	tokenHelper *token.TokenHelper,
 	config *conf.Config,
 	logger logger.Logger,
 	accountHandler *AccountHandler,
 	lobbyHandler *LobbyHandler,
+	walletHandler *WalletHandler,
 ) *AdminService {
 	return &AdminService{
 		playerUC:           playerUC,
 		walletUC:           walletUC,
 		gameApp:            gameApp,
 		formationConfigSvc: formationConfigSvc, // 保存服務引用
+		roomHistory:        roomHistory,
+		roomConfigs:        roomConfigs,
+		roomEvents:         roomEvents,
+		admins:             admins,
+		audit:              audit,
+		accounts:           accounts,
+		sanctions:          sanctions,
+		security:           security,
+		exchange:           exchange,
+		adminAuth:          adminAuth,
 		tokenHelper:        tokenHelper,
 		config:             config,
 		logger:             logger.With("module", "app/admin"),
 		accountHandler:     accountHandler,
 		lobbyHandler:       lobbyHandler,
+		walletHandler:      walletHandler,
 	}
 }
 
@@ -0,0 +1,132 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/gin-gonic/gin"
+)
+
+// WalletHandler 玩家錢包和幣種兌換的 HTTP 處理器
+type WalletHandler struct {
+	wallets  *wallet.WalletUsecase
+	exchange *wallet.ExchangeUsecase
+}
+
+// NewWalletHandler 建立新的 WalletHandler
+func NewWalletHandler(wallets *wallet.WalletUsecase, exchange *wallet.ExchangeUsecase) *WalletHandler {
+	return &WalletHandler{
+		wallets:  wallets,
+		exchange: exchange,
+	}
+}
+
+// RegisterWalletRoutes 註冊玩家錢包路由，除幣種目錄外都需要玩家令牌
+func RegisterWalletRoutes(r *gin.Engine, handler *WalletHandler, accountHandler *AccountHandler) {
+	wallets := r.Group("/api/v1/wallet")
+	{
+		wallets.GET("/currencies", handler.handleListCurrencies)
+		wallets.GET("/exchange-rates", handler.handleListExchangeRates)
+		wallets.GET("", accountHandler.authMiddleware(), handler.handleListWallets)
+		wallets.POST("/exchange", accountHandler.authMiddleware(), handler.handleExchange)
+	}
+}
+
+// ExchangeRequest 幣種兌換請求，amount 為來源幣種數量
+type ExchangeRequest struct {
+	From   string  `json:"from" binding:"required"`
+	To     string  `json:"to" binding:"required"`
+	Amount float64 `json:"amount" binding:"required"`
+}
+
+// handleListCurrencies 支持的幣種
+func (h *WalletHandler) handleListCurrencies(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"currencies": wallet.Currencies()})
+}
+
+// handleListExchangeRates 當前可用的兌換方向和比例（不包括停用的）
+func (h *WalletHandler) handleListExchangeRates(c *gin.Context) {
+	rates, err := h.exchange.ListRates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list exchange rates"})
+		return
+	}
+	enabled := make([]*wallet.ExchangeRate, 0, len(rates))
+	for _, rate := range rates {
+		if rate.Enabled {
+			enabled = append(enabled, &wallet.ExchangeRate{
+				FromCurrency: rate.FromCurrency,
+				ToCurrency:   rate.ToCurrency,
+				Rate:         rate.Rate,
+				Enabled:      true,
+				UpdatedAt:    rate.UpdatedAt,
+			})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"rates": enabled, "count": len(enabled)})
+}
+
+// handleListWallets 玩家所有幣種的錢包
+func (h *WalletHandler) handleListWallets(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "guests have no wallets"})
+		return
+	}
+
+	list, err := h.wallets.GetWalletsByUserID(c.Request.Context(), uint(c.GetInt64("user_id")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list wallets"})
+		return
+	}
+	wallets := make([]WalletResponse, 0, len(list))
+	for _, w := range list {
+		wallets = append(wallets, WalletResponse{
+			ID:        w.ID,
+			UserID:    w.UserID,
+			Balance:   w.Balance,
+			Currency:  w.Currency,
+			Status:    int(w.Status),
+			CreatedAt: w.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt: w.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"wallets": wallets, "count": len(wallets)})
+}
+
+// handleExchange 按後台配置的比例兌換幣種
+func (h *WalletHandler) handleExchange(c *gin.Context) {
+	if c.GetBool("is_guest") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "guests cannot exchange currencies"})
+		return
+	}
+
+	var req ExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.exchange.Exchange(c.Request.Context(), uint(c.GetInt64("user_id")), req.From, req.To, req.Amount)
+	if err != nil {
+		c.JSON(exchangeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// exchangeErrorStatus 將兌換錯誤轉換為 HTTP 狀態碼
+func exchangeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, wallet.ErrUnknownCurrency), errors.Is(err, wallet.ErrInvalidAmount),
+		errors.Is(err, wallet.ErrInvalidExchangeRate):
+		return http.StatusBadRequest
+	case errors.Is(err, wallet.ErrExchangeNotAllowed), errors.Is(err, wallet.ErrWalletFrozen):
+		return http.StatusForbidden
+	case errors.Is(err, wallet.ErrWalletNotFound), errors.Is(err, wallet.ErrExchangeRateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, wallet.ErrInsufficientBalance):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
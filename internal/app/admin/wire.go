@@ -9,8 +9,10 @@ var ProviderSet = wire.NewSet(
 	NewAdminService,
 	NewServer,
 	NewAdminApp,
+	NewAdminAuth,
 
 	// Handlers
 	NewAccountHandler,
 	NewLobbyHandler,
+	NewWalletHandler,
 )
\ No newline at end of file
@@ -6,13 +6,13 @@ import (
     "fmt"
     "net/http"
     "net/http/pprof"
+    "sync"
     "time"
 
     "github.com/b7777777v/fish_server/internal/biz/account"
     "github.com/b7777777v/fish_server/internal/biz/game"
     "github.com/b7777777v/fish_server/internal/conf"
     "github.com/b7777777v/fish_server/internal/pkg/logger"
-    "strings"
 )
 
 // =======================================
@@ -33,6 +33,30 @@ type GameApp struct {
 	// 消息處理器
 	messageHandler *MessageHandler
 
+	// 集群節點代理
+	nodeAgent *NodeAgent
+
+	// 快速加入匹配器（負責預建房間和擴縮容）
+	matchmaker *Matchmaker
+
+	// 房間狀態檢查點
+	checkpointer *RoomCheckpointer
+
+	// 房間生命週期鉤子
+	lifecycle *RoomLifecycleMonitor
+
+	// 座位保留、換座和 AFK 回收
+	seatKeeper *SeatKeeper
+
+	// 房間配置熱更新
+	roomConfigs *RoomConfigWatcher
+
+	// 定時房間活動
+	roomEvents *RoomEventScheduler
+
+	// 會話撤銷時關閉連接
+	sessionWatcher *SessionWatcher
+
 	// 遊戲用例
 	gameUsecase *game.GameUsecase
 
@@ -45,6 +69,10 @@ type GameApp struct {
 	// 日誌記錄器
 	logger logger.Logger
 
+	// 下線模式
+	drainOnce sync.Once
+	drained   chan struct{}
+
 	// 上下文和取消函數
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -59,6 +87,15 @@ func NewGameApp(
 	hub *Hub,
 	wsHandler *WebSocketHandler,
 	messageHandler *MessageHandler,
+	nodeAgent *NodeAgent,
+	matchmaker *Matchmaker,
+	checkpointer *RoomCheckpointer,
+	lifecycle *RoomLifecycleMonitor,
+	seatKeeper *SeatKeeper,
+	roomConfigs *RoomConfigWatcher,
+	roomEvents *RoomEventScheduler,
+	sessionWatcher *SessionWatcher,
+	sanctions *account.SanctionUsecase,
 ) *GameApp {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -66,10 +103,19 @@ func NewGameApp(
 		hub:            hub,
 		wsHandler:      wsHandler,
 		messageHandler: messageHandler,
+		nodeAgent:      nodeAgent,
+		matchmaker:     matchmaker,
+		checkpointer:   checkpointer,
+		lifecycle:      lifecycle,
+		seatKeeper:     seatKeeper,
+		roomConfigs:    roomConfigs,
+		roomEvents:     roomEvents,
+		sessionWatcher: sessionWatcher,
 		gameUsecase:    gameUsecase,
 		accountUsecase: accountUsecase,
 		config:         config, // Changed: Store full config
 		logger:         logger.With("component", "game_app"),
+		drained:        make(chan struct{}),
 		ctx:            ctx,
 		cancel:         cancel,
 	}
@@ -77,6 +123,15 @@ func NewGameApp(
 	// 設置 HTTP 服務器
 	app.setupHTTPServer()
 
+	// 斷線的玩家由座位管理保留座位
+	app.hub.seatKeeper = seatKeeper
+
+	// 連接和加入房間時檢查玩家處罰
+	app.hub.sanctions = sanctions
+
+	// 管理後台請求下線時進入下線模式
+	app.nodeAgent.OnDrainRequested(func() { app.Drain(0) })
+
 	// 異步加載魚類數據到緩存
 	go func() {
 		if err := app.gameUsecase.LoadAndCacheFishTypes(context.Background()); err != nil {
@@ -84,72 +139,6 @@ func NewGameApp(
 		}
 	}()
 
-	// 根據配置創建預建房間
-	go func() {
-		ctx := context.Background()
-		if app.config == nil || app.config.Game == nil || len(app.config.Game.PrebuiltRooms) == 0 {
-			app.logger.Info("No prebuilt rooms configured")
-			return
-		}
-
-		app.logger.Info("Starting prebuilt room creation...")
-		totalCreated := 0
-		totalFailed := 0
-
-		for _, pr := range app.config.Game.PrebuiltRooms {
-			var rt game.RoomType
-			switch strings.ToLower(pr.Type) {
-			case "novice":
-				rt = game.RoomTypeNovice
-			case "intermediate":
-				rt = game.RoomTypeIntermediate
-			case "advanced":
-				rt = game.RoomTypeAdvanced
-			case "vip":
-				rt = game.RoomTypeVIP
-			default:
-				app.logger.Warnf("Unknown prebuilt room type: %s, skipping", pr.Type)
-				continue
-			}
-
-			count := pr.Count
-			if count <= 0 {
-				count = 1
-			}
-
-			// 檢查內存中的房間數量（實際可用的房間）
-			allRooms, _ := app.gameUsecase.GetRoomList(ctx, rt)
-			existingCount := len(allRooms)
-			missing := count - existingCount
-
-			if missing <= 0 {
-				app.logger.Infof("Room type %s: %d rooms already exist in memory (target: %d), no need to create", pr.Type, existingCount, count)
-				continue
-			}
-
-			app.logger.Infof("Creating %d %s rooms (existing in memory: %d, target: %d)...", missing, pr.Type, existingCount, count)
-
-			// 創建缺少的房間
-			for i := 0; i < missing; i++ {
-				room, err := app.gameUsecase.CreateRoom(ctx, rt, pr.MaxPlayers)
-				if err != nil {
-					app.logger.Errorf("Failed to create prebuilt room: type=%s, index=%d, err=%v", pr.Type, i+1, err)
-					totalFailed++
-				} else {
-					app.logger.Infof("Successfully created room: id=%s, type=%s, max_players=%d", room.ID, pr.Type, pr.MaxPlayers)
-					totalCreated++
-				}
-			}
-		}
-
-		// 總結日誌
-		if totalCreated > 0 || totalFailed > 0 {
-			app.logger.Infof("Prebuilt room creation completed: %d created, %d failed", totalCreated, totalFailed)
-		} else {
-			app.logger.Info("No new prebuilt rooms needed")
-		}
-	}()
-
 	return app
 }
 
@@ -253,8 +242,17 @@ func (app *GameApp) respondError(w http.ResponseWriter, statusCode int, message
 }
 
 
-// handleHealth 健康檢查處理器
+// handleHealth 健康檢查處理器（下線模式中返回 503，負載均衡不再分配新連接）
 func (app *GameApp) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if app.hub.Draining() {
+		app.respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+			"status":    "draining",
+			"service":   "game",
+			"timestamp": time.Now().Unix(),
+		})
+		return
+	}
+
 	app.respondJSON(w, http.StatusOK, map[string]interface{}{
 		"status":    "healthy",
 		"service":   "game",
@@ -266,9 +264,15 @@ func (app *GameApp) handleHealth(w http.ResponseWriter, r *http.Request) {
 func (app *GameApp) handleStatus(w http.ResponseWriter, r *http.Request) {
 	stats := app.hub.GetStats()
 
+	status := "running"
+	if app.hub.Draining() {
+		status = "draining"
+	}
+
 	app.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"status":             "running",
+		"status":             status,
 		"service":            "game",
+		"node_id":            app.nodeAgent.NodeID(),
 		"timestamp":          time.Now().Unix(),
 		"active_connections": stats.ActiveConnections,
 		"active_rooms":       stats.ActiveRooms,
@@ -276,6 +280,14 @@ func (app *GameApp) handleStatus(w http.ResponseWriter, r *http.Request) {
 		"total_messages":     stats.TotalMessages,
 		"start_time":         stats.StartTime.Unix(),
 		"last_activity":      stats.LastActivity.Unix(),
+		"bytes_sent":         stats.BytesSent,
+		"frames_sent":        stats.FramesSent,
+		"messages_sent":      stats.MessagesSent,
+		"batches_sent":       stats.BatchesSent,
+		"clients":            stats.Clients,
+		"rate_limit":         stats.RateLimit,
+		"room_lifecycle":     app.lifecycle.Stats(),
+		"room_configs":       app.gameUsecase.RoomConfigVersions(),
 	})
 }
 
@@ -320,8 +332,9 @@ func (app *GameApp) handleGuestLogin(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	// 調用 AccountUsecase 創建遊客用戶並生成 token
-	token, err := app.accountUsecase.GuestLogin(ctx)
+	// 調用 AccountUsecase 創建遊客會話並生成 token
+	client := account.ClientInfo{UserAgent: r.UserAgent(), IP: clientIP(r)}
+	tokens, err := app.accountUsecase.GuestLogin(ctx, client)
 	if err != nil {
 		app.logger.Errorf("Failed to create guest user: %v", err)
 		app.respondError(w, http.StatusInternalServerError, "Failed to create guest account")
@@ -330,9 +343,13 @@ func (app *GameApp) handleGuestLogin(w http.ResponseWriter, r *http.Request) {
 
 	// 返回 token 給客戶端
 	app.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"token":   token,
-		"message": "Guest login successful",
+		"success":            true,
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_in": tokens.RefreshExpiresIn,
+		"session_id":         tokens.SessionID,
+		"message":            "Guest login successful",
 	})
 }
 
@@ -344,6 +361,38 @@ func (app *GameApp) Run() error {
 	// 啟動 Hub
 	go app.hub.Run()
 
+	// 在恢復和創建房間之前註冊生命週期鉤子，記錄所有房間的狀態轉換
+	app.lifecycle.Start()
+
+	// 在恢復和創建房間之前加載房間配置，之後訂閱管理後台的配置變更
+	loadCtx, cancelLoad := context.WithTimeout(context.Background(), roomConfigLoadTimeout)
+	app.roomConfigs.Load(loadCtx)
+	cancelLoad()
+	app.roomConfigs.Start()
+
+	// 開始舉行定時活動
+	app.roomEvents.Start()
+
+	// 玩家登出設備後關閉對應的連接
+	app.sessionWatcher.Start()
+
+	// 恢復上次運行時的房間，預建房間按恢復後的房間數補足
+	restoreCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if restored := app.checkpointer.Restore(restoreCtx); restored > 0 {
+		app.logger.Infof("Restored %d rooms from checkpoints", restored)
+	}
+	cancel()
+	app.checkpointer.Start()
+
+	// 廣播座位事件，檢查過期的座位保留和 AFK 玩家
+	app.seatKeeper.Start()
+
+	// 按配置創建預建房間並開始擴縮容
+	app.matchmaker.Start()
+
+	// 註冊到集群，開始心跳和房間上報
+	app.nodeAgent.Start()
+
 	// 啟動 HTTP 服務器
 	if err := app.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		app.logger.Errorf("Failed to start game server: %v", err)
@@ -357,6 +406,19 @@ func (app *GameApp) Run() error {
 func (app *GameApp) Stop() error {
 	app.logger.Info("Stopping Game App")
 
+	// 先從集群移除，大廳不再路由新連接到本節點
+	app.nodeAgent.Stop()
+
+	// 停止房間擴縮容
+	app.matchmaker.Stop()
+	app.seatKeeper.Stop()
+	app.roomConfigs.Stop()
+	app.roomEvents.Stop()
+	app.sessionWatcher.Stop()
+
+	// 在斷開連接之前保存最後一次檢查點，重啟後玩家可以回到原座位
+	app.checkpointer.Stop()
+
 	// 停止 Hub
 	app.hub.Stop()
 
@@ -386,6 +448,9 @@ func (app *GameApp) GetStats() map[string]interface{} {
 		"total_messages":     hubStats.TotalMessages,
 		"start_time":         hubStats.StartTime,
 		"last_activity":      hubStats.LastActivity,
+		"bytes_sent":         hubStats.BytesSent,
+		"frames_sent":        hubStats.FramesSent,
+		"rate_limit":         hubStats.RateLimit,
 	}
 }
 
@@ -61,7 +61,7 @@ func TestChannelBuffers(t *testing.T) {
 	gameUsecase := game.NewGameUsecase(gameRepo, playerRepo, gameRecordRepo, walletUC, roomManager, spawner, mathModel, inventoryManager, rtpController, log)
 
 	t.Run("Hub channels have buffers", func(t *testing.T) {
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 
 		// 測試通道容量
 		assert.Greater(t, cap(hub.register), 0, "register channel should be buffered")
@@ -81,7 +81,7 @@ func TestChannelBuffers(t *testing.T) {
 	})
 
 	t.Run("RoomManager channels have buffers", func(t *testing.T) {
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 		roomManager := NewRoomManager("test_room", gameUsecase, hub, log)
 
 		// 測試通道容量
@@ -138,7 +138,7 @@ func TestNoChannelBlocking(t *testing.T) {
 	gameUsecase := game.NewGameUsecase(gameRepo, playerRepo, gameRecordRepo2, walletUC, roomManager, spawner, mathModel, inventoryManager, rtpController, log)
 
 	t.Run("Hub can handle burst of messages without blocking", func(t *testing.T) {
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 		go hub.Run()
 		defer hub.Stop()
 
@@ -171,7 +171,7 @@ func TestNoChannelBlocking(t *testing.T) {
 	})
 
 	t.Run("Multiple game actions don't block when sent concurrently", func(t *testing.T) {
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 		go hub.Run()
 		defer hub.Stop()
 
@@ -242,7 +242,7 @@ func TestNoChannelBlocking(t *testing.T) {
 	})
 
 	t.Run("RoomManager can receive actions without blocking", func(t *testing.T) {
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 		go hub.Run()
 		defer hub.Stop()
 
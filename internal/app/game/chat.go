@@ -0,0 +1,73 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+)
+
+// ========================================
+// 房間聊天
+// ========================================
+//
+// 入座的玩家可以在所在房間發言，伺服器以 CHAT_MESSAGE 廣播給房間內所有人（包括發言者和觀戰者）。
+// 正式帳號發言前檢查禁言處罰，被禁言時返回 CHAT_MUTED；觀戰者和客服觀戰連接不能發言。
+// 發言與其他消息共用連接的消息限流。
+
+// maxChatTextLength 單條發言的最大字符數
+const maxChatTextLength = 200
+
+// handleChat 處理房間發言
+func (mh *MessageHandler) handleChat(client *Client, message *pb.GameMessage) {
+	chatData := message.GetChat()
+	if chatData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid chat data")
+		return
+	}
+	if client.RoomID == "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if mh.rejectSpectator(client) {
+		return
+	}
+
+	text := strings.TrimSpace(chatData.Text)
+	if text == "" || utf8.RuneCountInString(text) > maxChatTextLength {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Chat text must be 1-200 characters")
+		return
+	}
+	if err := mh.checkMuted(context.Background(), client); err != nil {
+		message := "Failed to check chat permission"
+		if errors.Is(err, account.ErrChatMuted) {
+			message = err.Error()
+		}
+		mh.sendBizErrorResponse(client, err, message)
+		return
+	}
+
+	mh.broadcastToRoom(client.RoomID, &pb.GameMessage{
+		Type: pb.MessageType_CHAT_MESSAGE,
+		Data: &pb.GameMessage_ChatMessage{
+			ChatMessage: &pb.ChatMessage{
+				PlayerId:  client.PlayerID,
+				Nickname:  client.ID,
+				Text:      text,
+				Timestamp: time.Now().UnixMilli(),
+			},
+		},
+	}, nil)
+}
+
+// checkMuted 正式帳號被禁言時返回 account.ErrChatMuted；遊客沒有處罰
+func (mh *MessageHandler) checkMuted(ctx context.Context, client *Client) error {
+	if mh.hub == nil || mh.hub.sanctions == nil || client.accountID <= 0 {
+		return nil
+	}
+	return mh.hub.sanctions.CheckChat(ctx, client.accountID)
+}
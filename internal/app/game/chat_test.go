@@ -0,0 +1,120 @@
+package game
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// stubSanctionRepo 只支持查詢生效處罰的處罰存儲
+type stubSanctionRepo struct {
+	account.SanctionRepo
+	active map[int64][]*account.Sanction
+}
+
+func (r *stubSanctionRepo) ListActiveSanctions(ctx context.Context, userID int64, now time.Time) ([]*account.Sanction, error) {
+	return r.active[userID], nil
+}
+
+func newChatTestHandler(repo account.SanctionRepo) *MessageHandler {
+	log := logger.New(os.Stdout, "error", "console")
+	hub := &Hub{
+		broadcast: make(chan *BroadcastMessage, 4),
+		sanctions: account.NewSanctionUsecase(repo, nil, log),
+		logger:    log,
+	}
+	return NewMessageHandler(nil, hub, log)
+}
+
+func newChatTestClient(accountID int64) *Client {
+	return &Client{
+		ID:        "alice",
+		PlayerID:  accountID,
+		RoomID:    "room-1",
+		accountID: accountID,
+		send:      make(chan []byte, 4),
+		logger:    logger.New(os.Stdout, "error", "console"),
+	}
+}
+
+func chatMessage(text string) *pb.GameMessage {
+	return &pb.GameMessage{
+		Type: pb.MessageType_CHAT,
+		Data: &pb.GameMessage_Chat{Chat: &pb.ChatRequest{Text: text}},
+	}
+}
+
+func receiveError(t *testing.T, client *Client) *pb.ErrorMessage {
+	t.Helper()
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+	require.Equal(t, pb.MessageType_ERROR, msg.Type)
+	return msg.GetError()
+}
+
+func TestHandleChat_BroadcastsToRoom(t *testing.T) {
+	mh := newChatTestHandler(&stubSanctionRepo{})
+	client := newChatTestClient(7)
+
+	mh.HandleMessage(client, chatMessage("  hello  "))
+
+	broadcast := <-mh.hub.broadcast
+	assert.Equal(t, "room-1", broadcast.RoomID)
+	assert.Nil(t, broadcast.Exclude)
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(broadcast.Message, &msg))
+	require.Equal(t, pb.MessageType_CHAT_MESSAGE, msg.Type)
+	assert.Equal(t, int64(7), msg.GetChatMessage().PlayerId)
+	assert.Equal(t, "alice", msg.GetChatMessage().Nickname)
+	assert.Equal(t, "hello", msg.GetChatMessage().Text)
+}
+
+func TestHandleChat_RejectsMutedPlayer(t *testing.T) {
+	repo := &stubSanctionRepo{active: map[int64][]*account.Sanction{
+		7: {{UserID: 7, Type: account.SanctionMute, Reason: "spam"}},
+	}}
+	mh := newChatTestHandler(repo)
+
+	muted := newChatTestClient(7)
+	mh.HandleMessage(muted, chatMessage("hello"))
+	errMsg := receiveError(t, muted)
+	assert.Equal(t, pb.ErrorCode_CHAT_MUTED, errMsg.ErrorCode)
+	assert.Contains(t, errMsg.Message, "spam")
+	assert.Empty(t, mh.hub.broadcast)
+
+	// 禁言只影響被處罰的帳號
+	other := newChatTestClient(8)
+	mh.HandleMessage(other, chatMessage("hello"))
+	assert.Len(t, mh.hub.broadcast, 1)
+}
+
+func TestHandleChat_Validation(t *testing.T) {
+	mh := newChatTestHandler(&stubSanctionRepo{})
+
+	client := newChatTestClient(7)
+	mh.HandleMessage(client, chatMessage("   "))
+	assert.Equal(t, pb.ErrorCode_INVALID_ARGUMENT, receiveError(t, client).ErrorCode)
+	mh.HandleMessage(client, chatMessage(strings.Repeat("魚", maxChatTextLength+1)))
+	assert.Equal(t, pb.ErrorCode_INVALID_ARGUMENT, receiveError(t, client).ErrorCode)
+
+	spectator := newChatTestClient(8)
+	spectator.Spectating = true
+	mh.HandleMessage(spectator, chatMessage("hello"))
+	assert.Equal(t, pb.ErrorCode_SPECTATOR_ACTION_FORBIDDEN, receiveError(t, spectator).ErrorCode)
+
+	outside := newChatTestClient(9)
+	outside.RoomID = ""
+	mh.HandleMessage(outside, chatMessage("hello"))
+	assert.Equal(t, pb.ErrorCode_NOT_IN_ROOM, receiveError(t, outside).ErrorCode)
+
+	assert.Empty(t, mh.hub.broadcast)
+}
@@ -0,0 +1,287 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// RoomCheckpointer - 房間狀態檢查點
+// ========================================
+//
+// 節點定期把完整的房間狀態寫入 Redis。啟動時先恢復本節點的檢查點（節點崩潰後重啟）；
+// 配置為備用節點時，還會接管心跳已過期節點的檢查點。恢復的玩家使用原來的會話令牌重新連接後
+// 回到原座位；恢復的座位保留 resume_timeout，過期後由 SeatKeeper 按正常流程結算離開。
+
+// checkpointClaimTTL 恢復聲明的有效期，恢復節點在此期間內崩潰時其他節點可以重新接管
+const checkpointClaimTTL = time.Minute
+
+// RoomCheckpointer 定期保存房間檢查點並在啟動時恢復房間
+type RoomCheckpointer struct {
+	repo        game.RoomCheckpointRepo
+	registry    lobby.NodeRegistry
+	gameUsecase *game.GameUsecase
+	hub         *Hub
+
+	nodeID        string
+	enabled       bool
+	takeover      bool
+	interval      time.Duration
+	ttl           time.Duration
+	resumeTimeout time.Duration
+
+	logger logger.Logger
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewRoomCheckpointer 創建房間檢查點管理器
+func NewRoomCheckpointer(repo game.RoomCheckpointRepo, registry lobby.NodeRegistry, hub *Hub, gameUsecase *game.GameUsecase, nodeAgent *NodeAgent, config *conf.Config, logger logger.Logger) *RoomCheckpointer {
+	checkpoint := &conf.Checkpoint{Enable: true, Interval: 5, TTL: 600, ResumeTimeout: 120}
+	if config != nil && config.Cluster != nil && config.Cluster.Checkpoint != nil {
+		checkpoint = config.Cluster.Checkpoint
+	}
+
+	return &RoomCheckpointer{
+		repo:          repo,
+		registry:      registry,
+		gameUsecase:   gameUsecase,
+		hub:           hub,
+		nodeID:        nodeAgent.NodeID(),
+		enabled:       checkpoint.Enable,
+		takeover:      checkpoint.Takeover,
+		interval:      time.Duration(checkpoint.Interval) * time.Second,
+		ttl:           time.Duration(checkpoint.TTL) * time.Second,
+		resumeTimeout: time.Duration(checkpoint.ResumeTimeout) * time.Second,
+		logger:        logger.With("component", "room_checkpointer", "node_id", nodeAgent.NodeID()),
+	}
+}
+
+// Restore 恢復本節點上次運行時保存的房間，需在創建預建房間之前調用，返回恢復的房間數
+func (c *RoomCheckpointer) Restore(ctx context.Context) int {
+	if !c.enabled {
+		return 0
+	}
+	restored, err := c.restoreFrom(ctx, c.nodeID)
+	if err != nil {
+		c.logger.Errorf("Failed to restore rooms from checkpoint: %v", err)
+	}
+	return restored
+}
+
+// Start 開始定期保存檢查點
+func (c *RoomCheckpointer) Start() {
+	if !c.enabled {
+		c.logger.Info("Room checkpoints disabled")
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	c.stopped = make(chan struct{})
+
+	c.logger.Infof("Saving room checkpoints: interval=%v, ttl=%v, resume_timeout=%v, takeover=%v",
+		c.interval, c.ttl, c.resumeTimeout, c.takeover)
+	go c.run(c.stop, c.stopped)
+}
+
+// Stop 停止定期保存並寫入最後一次檢查點
+func (c *RoomCheckpointer) Stop() {
+	c.mu.Lock()
+	stop, stopped := c.stop, c.stopped
+	c.stop, c.stopped = nil, nil
+	c.mu.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-stopped
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := c.checkpoint(ctx); err != nil {
+		c.logger.Errorf("Failed to save final checkpoint: %v", err)
+	}
+}
+
+// Clear 停止保存並刪除本節點的檢查點，下線模式結算完所有玩家後調用
+func (c *RoomCheckpointer) Clear() {
+	c.Stop()
+	if !c.enabled {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := c.repo.DeleteCheckpoints(ctx, c.nodeID); err != nil {
+		c.logger.Errorf("Failed to delete checkpoints: %v", err)
+		return
+	}
+	c.logger.Info("Room checkpoints cleared")
+}
+
+// run 檢查點循環
+func (c *RoomCheckpointer) run(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+		if err := c.checkpoint(ctx); err != nil {
+			c.logger.Errorf("Failed to save room checkpoint: %v", err)
+		}
+		if c.takeover {
+			c.takeOver(ctx)
+		}
+		cancel()
+	}
+}
+
+// checkpoint 保存本節點所有房間的檢查點
+func (c *RoomCheckpointer) checkpoint(ctx context.Context) error {
+	checkpoints := c.gameUsecase.SnapshotRooms(c.nodeID)
+	if err := c.repo.SaveCheckpoints(ctx, c.nodeID, checkpoints, c.ttl); err != nil {
+		return err
+	}
+	c.logger.Debugf("Saved checkpoints of %d rooms", len(checkpoints))
+	return nil
+}
+
+// restoreFrom 聲明並恢復指定節點的檢查點，恢復後立即以本節點保存，再刪除原節點的檢查點
+func (c *RoomCheckpointer) restoreFrom(ctx context.Context, nodeID string) (int, error) {
+	claimed, err := c.repo.ClaimCheckpoints(ctx, nodeID, c.nodeID, checkpointClaimTTL)
+	if err != nil {
+		return 0, err
+	}
+	if !claimed {
+		c.logger.Infof("Checkpoints of node %s are being restored by another node", nodeID)
+		return 0, nil
+	}
+
+	checkpoints, err := c.repo.LoadCheckpoints(ctx, nodeID)
+	if err != nil {
+		return 0, err
+	}
+
+	reserveUntil := time.Now().Add(c.resumeTimeout)
+	restored := 0
+	for _, cp := range checkpoints {
+		if _, err := c.gameUsecase.RestoreRoom(ctx, cp, reserveUntil); err != nil {
+			c.logger.Warnf("Failed to restore room %s: %v", cp.Room.ID, err)
+			continue
+		}
+		restored++
+	}
+
+	if err := c.checkpoint(ctx); err != nil {
+		return restored, err
+	}
+	// 接管其他節點時，恢復的房間已保存為本節點的檢查點，刪除原節點的檢查點；
+	// 恢復本節點時檢查點已被覆蓋，恢復聲明在過期後自動釋放
+	if nodeID != c.nodeID {
+		if err := c.repo.DeleteCheckpoints(ctx, nodeID); err != nil {
+			return restored, err
+		}
+	}
+
+	if len(checkpoints) > 0 {
+		c.logger.Infof("Restored %d/%d rooms from checkpoints of node %s", restored, len(checkpoints), nodeID)
+	}
+	return restored, nil
+}
+
+// takeOver 接管心跳已過期節點的檢查點
+func (c *RoomCheckpointer) takeOver(ctx context.Context) {
+	nodeIDs, err := c.repo.CheckpointNodes(ctx)
+	if err != nil {
+		c.logger.Errorf("Failed to list checkpoint nodes: %v", err)
+		return
+	}
+	if len(nodeIDs) == 0 {
+		return
+	}
+
+	nodes, err := c.registry.ListNodes(ctx)
+	if err != nil {
+		c.logger.Errorf("Failed to list live nodes: %v", err)
+		return
+	}
+	live := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		live[node.NodeID] = true
+	}
+
+	for _, nodeID := range nodeIDs {
+		if nodeID == c.nodeID || live[nodeID] {
+			continue
+		}
+		c.logger.Warnf("Node %s is offline, taking over its rooms", nodeID)
+		if _, err := c.restoreFrom(ctx, nodeID); err != nil {
+			c.logger.Errorf("Failed to take over rooms of node %s: %v", nodeID, err)
+		}
+	}
+}
+
+// ========================================
+// 恢復會話
+// ========================================
+
+// resumeSession 玩家有保留的離線座位（節點恢復或斷線保留）時，把新連接放回原房間
+func (h *Hub) resumeSession(client *Client) bool {
+	if h.gameUsecase == nil || client.PlayerID == 0 || client.supportRoomID != "" {
+		return false
+	}
+
+	ctx := context.Background()
+	player, err := h.gameUsecase.ResumeSession(ctx, client.PlayerID)
+	if err != nil {
+		return false
+	}
+
+	roomID := player.RoomID
+	// 遊客的餘額保存在房間中的玩家對象上，使用恢復後的對象
+	if client.IsGuest {
+		client.GuestPlayer = player
+		client.guest.startRound(roomID, player.Balance)
+	}
+
+	h.mu.RLock()
+	firstClient := len(h.rooms[roomID]) == 0
+	h.mu.RUnlock()
+
+	client.RoomID = roomID
+	h.joinRoom <- &JoinRoomMessage{Client: client, RoomID: roomID}
+
+	mh := NewMessageHandler(h.gameUsecase, h, h.logger)
+	mh.sendJoinRoomResponse(client, roomID, "")
+	// 恢復的房間沒有推送狀態，由第一個重新連接的玩家啟動（只有一名玩家時 sendJoinRoomResponse 已啟動）
+	if firstClient {
+		if room, err := h.gameUsecase.GetRoom(ctx, roomID); err == nil && len(room.Players) > 1 {
+			mh.StartRoomStateUpdates(roomID)
+		}
+	}
+
+	h.logger.Infof("Player %d resumed session in room %s", client.PlayerID, roomID)
+	return true
+}
@@ -0,0 +1,305 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryCheckpointRepo 內存檢查點存儲，檢查點以 JSON 保存以模擬 Redis
+type memoryCheckpointRepo struct {
+	mu     sync.Mutex
+	nodes  map[string]map[string][]byte
+	claims map[string]string
+}
+
+func newMemoryCheckpointRepo() *memoryCheckpointRepo {
+	return &memoryCheckpointRepo{nodes: make(map[string]map[string][]byte), claims: make(map[string]string)}
+}
+
+func (r *memoryCheckpointRepo) SaveCheckpoints(ctx context.Context, nodeID string, checkpoints []*game.RoomCheckpoint, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(checkpoints) == 0 {
+		delete(r.nodes, nodeID)
+		return nil
+	}
+	rooms := make(map[string][]byte, len(checkpoints))
+	for _, cp := range checkpoints {
+		data, err := json.Marshal(cp)
+		if err != nil {
+			return err
+		}
+		rooms[cp.Room.ID] = data
+	}
+	r.nodes[nodeID] = rooms
+	return nil
+}
+
+func (r *memoryCheckpointRepo) LoadCheckpoints(ctx context.Context, nodeID string) ([]*game.RoomCheckpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var checkpoints []*game.RoomCheckpoint
+	for _, data := range r.nodes[nodeID] {
+		var cp game.RoomCheckpoint
+		if err := json.Unmarshal(data, &cp); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, &cp)
+	}
+	return checkpoints, nil
+}
+
+func (r *memoryCheckpointRepo) DeleteCheckpoints(ctx context.Context, nodeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.nodes, nodeID)
+	delete(r.claims, nodeID)
+	return nil
+}
+
+func (r *memoryCheckpointRepo) CheckpointNodes(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var nodeIDs []string
+	for nodeID := range r.nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	return nodeIDs, nil
+}
+
+func (r *memoryCheckpointRepo) ClaimCheckpoints(ctx context.Context, nodeID, claimer string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if owner, ok := r.claims[nodeID]; ok {
+		return owner == claimer, nil
+	}
+	r.claims[nodeID] = claimer
+	return true, nil
+}
+
+// newTestCheckpointer 創建指定節點 ID 的檢查點管理器
+func newTestCheckpointer(repo game.RoomCheckpointRepo, registry lobby.NodeRegistry, gu *game.GameUsecase, nodeID string) *RoomCheckpointer {
+	log := logger.New(os.Stdout, "error", "console")
+	config := &conf.Config{Cluster: &conf.Cluster{
+		NodeID:     nodeID,
+		Checkpoint: &conf.Checkpoint{Enable: true, Interval: 5, TTL: 600, ResumeTimeout: 60},
+	}}
+	hub := NewHub(gu, nil, nil, nil, log)
+	agent := NewNodeAgent(registry, nil, hub, gu, config, log)
+	return NewRoomCheckpointer(repo, registry, hub, gu, agent, config, log)
+}
+
+func TestRoomCheckpoint_RestoreFullState(t *testing.T) {
+	guA, rmA := newTestRoomUsecase(t)
+	guB, _ := newTestRoomUsecase(t)
+	closeAllRooms(t, guA)
+	closeAllRooms(t, guB)
+	ctx := context.Background()
+
+	room, err := guA.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guA.JoinRoomWithPlayer(ctx, room.ID, &game.Player{ID: 7, Nickname: "guest", Balance: 12345}))
+	formation, err := rmA.SpawnSpecialFormationInRoom(room.ID, game.FormationTypeLine, "straight_left_right", []int32{1, 1, 1})
+	require.NoError(t, err)
+
+	checkpoints := guA.SnapshotRooms("node-a")
+	require.Len(t, checkpoints, 1)
+	data, err := json.Marshal(checkpoints[0])
+	require.NoError(t, err)
+	var cp game.RoomCheckpoint
+	require.NoError(t, json.Unmarshal(data, &cp))
+
+	// 模擬節點 A 崩潰，由節點 B 恢復
+	original, err := guA.GetRoom(ctx, room.ID)
+	require.NoError(t, err)
+	seat := original.Players[7].SeatID
+	fishCount := len(cp.Room.Fishes)
+	require.NoError(t, guA.CloseRoom(ctx, room.ID))
+
+	restored, err := guB.RestoreRoom(ctx, &cp, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, room.ID, restored.ID)
+	assert.Equal(t, int64(7), restored.Seats[seat])
+	assert.Len(t, restored.Fishes, fishCount)
+	assert.Equal(t, game.PlayerStatusOffline, restored.Players[7].Status)
+	assert.Equal(t, int64(12345), restored.Players[7].Balance)
+
+	formations, err := guB.GetFormationsInRoom(ctx, room.ID)
+	require.NoError(t, err)
+	require.Len(t, formations, 1)
+	assert.Equal(t, formation.ID, formations[0].ID)
+	for _, fish := range formations[0].Fishes {
+		assert.Same(t, restored.Fishes[fish.ID], fish, "formation fishes are linked to the room fishes")
+	}
+
+	_, err = guB.RestoreRoom(ctx, &cp, time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, game.ErrRoomExists)
+
+	// 玩家重新連接後回到原座位
+	assert.Equal(t, map[string][]int64{room.ID: {7}}, guB.OfflinePlayers())
+	player, err := guB.ResumeSession(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, game.PlayerStatusPlaying, player.Status)
+	assert.Equal(t, seat, player.SeatID)
+	assert.Empty(t, guB.OfflinePlayers())
+	_, err = guB.ResumeSession(ctx, 7)
+	assert.ErrorIs(t, err, game.ErrPlayerNotInRoom)
+}
+
+func TestRoomCheckpoint_PrivateRoomSettings(t *testing.T) {
+	guA := newMatchmakerTestUsecase(t)
+	guB := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guA)
+	closeAllRooms(t, guB)
+	ctx := context.Background()
+
+	room, err := newTestMatchmaker(guA, 0, 0, 0.75).CreatePrivateRoom(ctx, game.RoomTypeNovice, 1, "secret")
+	require.NoError(t, err)
+	private, _ := guA.PrivateRoomInfo(room.ID)
+	require.NoError(t, guestJoiner(guA, 1)(room.ID))
+	_, err = guA.AuthorizeInvite(ctx, private.InviteCode, "secret", 2)
+	require.NoError(t, err)
+
+	repo := newMemoryCheckpointRepo()
+	require.NoError(t, repo.SaveCheckpoints(ctx, "node-a", guA.SnapshotRooms("node-a"), time.Minute))
+	require.NoError(t, guA.CloseRoom(ctx, room.ID))
+
+	checkpointer := newTestCheckpointer(repo, &stubNodeRegistry{}, guB, "node-b")
+	checkpointer.takeOver(ctx)
+
+	restored, ok := guB.PrivateRoomInfo(room.ID)
+	require.True(t, ok)
+	assert.Equal(t, private, restored)
+	// 已驗證邀請碼的玩家不需要重新輸入密碼
+	assert.NoError(t, guestJoiner(guB, 2)(room.ID))
+}
+
+func TestRoomCheckpointer_RestartAndTakeOver(t *testing.T) {
+	guA := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guA)
+	ctx := context.Background()
+	repo := newMemoryCheckpointRepo()
+
+	room, err := guA.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(guA, 1)(room.ID))
+	require.NoError(t, newTestCheckpointer(repo, &stubNodeRegistry{}, guA, "node-a").checkpoint(ctx))
+
+	// 存活節點的檢查點不會被接管
+	guB := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guB)
+	registry := &stubNodeRegistry{nodes: []*lobby.GameNode{{NodeID: "node-a"}}}
+	standby := newTestCheckpointer(repo, registry, guB, "node-b")
+	standby.takeOver(ctx)
+	_, err = guB.GetRoom(ctx, room.ID)
+	assert.ErrorIs(t, err, game.ErrRoomNotFound)
+
+	// 節點 A 下線後由備用節點接管，原節點的檢查點被刪除
+	registry.nodes = nil
+	standby.takeOver(ctx)
+	_, err = guB.GetRoom(ctx, room.ID)
+	require.NoError(t, err)
+	nodeIDs, _ := repo.CheckpointNodes(ctx)
+	assert.Equal(t, []string{"node-b"}, nodeIDs)
+
+	// 節點 A 重啟時檢查點已被接管，不會重複恢復
+	guA2 := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guA2)
+	assert.Zero(t, newTestCheckpointer(repo, registry, guA2, "node-a").Restore(ctx))
+
+	// 節點 B 重啟後恢復自己的房間
+	guB2 := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guB2)
+	require.NoError(t, guB.CloseRoom(ctx, room.ID))
+	assert.Equal(t, 1, newTestCheckpointer(repo, registry, guB2, "node-b").Restore(ctx))
+	_, err = guB2.GetRoom(ctx, room.ID)
+	assert.NoError(t, err)
+}
+
+func TestRoomCheckpointer_ExpireOfflinePlayers(t *testing.T) {
+	guA := newMatchmakerTestUsecase(t)
+	guB := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guA)
+	closeAllRooms(t, guB)
+	ctx := context.Background()
+	repo := newMemoryCheckpointRepo()
+
+	room, err := guA.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(guA, 1)(room.ID))
+	require.NoError(t, guestJoiner(guA, 2)(room.ID))
+	require.NoError(t, repo.SaveCheckpoints(ctx, "node-a", guA.SnapshotRooms("node-a"), time.Minute))
+
+	checkpointer := newTestCheckpointer(repo, &stubNodeRegistry{}, guB, "node-a")
+	require.Equal(t, 1, checkpointer.Restore(ctx))
+	_, err = guB.ResumeSession(ctx, 1)
+	require.NoError(t, err)
+
+	// 恢復的離線玩家保留座位 resume_timeout，過期後由座位管理結算離開
+	keeper := NewSeatKeeper(checkpointer.hub, guB, nil, logger.New(os.Stdout, "error", "console"))
+	expired, afk := keeper.sweep(ctx, time.Now())
+	assert.Zero(t, expired)
+	assert.Zero(t, afk)
+	expired, _ = keeper.sweep(ctx, time.Now().Add(time.Minute))
+	assert.Equal(t, 1, expired)
+
+	restored, err := guB.GetRoom(ctx, room.ID)
+	require.NoError(t, err)
+	assert.Contains(t, restored.Players, int64(1))
+	assert.NotContains(t, restored.Players, int64(2))
+}
+
+func TestHub_ResumeSession(t *testing.T) {
+	guA := newMatchmakerTestUsecase(t)
+	guB := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guA)
+	closeAllRooms(t, guB)
+	ctx := context.Background()
+	log := logger.New(os.Stdout, "error", "console")
+
+	room, err := guA.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guA.JoinRoomWithPlayer(ctx, room.ID, &game.Player{ID: -5, Nickname: "guest", Balance: 54321}))
+	for _, cp := range guA.SnapshotRooms("node-a") {
+		_, err := guB.RestoreRoom(ctx, cp, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+	}
+
+	hub := NewHub(guB, nil, nil, nil, log)
+
+	// 沒有離線座位的玩家不受影響
+	other := NewClient(nil, hub, log)
+	other.PlayerID = 6
+	assert.False(t, hub.resumeSession(other))
+	assert.Empty(t, other.RoomID)
+
+	// 遊客使用同一令牌重新連接，回到原房間並保留餘額
+	client := NewClient(nil, hub, log)
+	client.PlayerID = -5
+	client.IsGuest = true
+	client.GuestPlayer = &game.Player{ID: -5, Nickname: "guest", Balance: 100000}
+	require.True(t, hub.resumeSession(client))
+	assert.Equal(t, room.ID, client.RoomID)
+	assert.Equal(t, int64(54321), client.GuestPlayer.Balance)
+
+	join := <-hub.joinRoom
+	assert.Equal(t, room.ID, join.RoomID)
+
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+	require.Equal(t, pb.MessageType_JOIN_ROOM_RESPONSE, msg.Type)
+	assert.Equal(t, room.ID, msg.GetJoinRoomResponse().RoomId)
+}
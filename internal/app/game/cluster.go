@@ -0,0 +1,263 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// NodeAgent - 集群節點註冊與房間上報
+// ========================================
+
+// NodeAgent 在 Redis 中註冊本節點，定期發送心跳並上報房間列表，供大廳路由
+type NodeAgent struct {
+	registry    lobby.NodeRegistry
+	roomCache   lobby.RoomCache
+	hub         *Hub
+	gameUsecase *game.GameUsecase
+
+	nodeID    string
+	url       string
+	startedAt time.Time
+	interval  time.Duration
+	ttl       time.Duration
+
+	logger logger.Logger
+
+	// 下線模式：心跳中標記節點，onDrain 在收到管理後台的下線請求時調用
+	draining atomic.Bool
+	onDrain  func()
+
+	// 房間狀態轉換後請求立即上報，多次請求合併為一次
+	report chan struct{}
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewNodeAgent 創建集群節點代理
+func NewNodeAgent(registry lobby.NodeRegistry, roomCache lobby.RoomCache, hub *Hub, gameUsecase *game.GameUsecase, config *conf.Config, logger logger.Logger) *NodeAgent {
+	cluster := &conf.Cluster{HeartbeatInterval: 5, NodeTTL: 15}
+	if config != nil && config.Cluster != nil {
+		cluster = config.Cluster
+	}
+
+	port := 9090
+	if config != nil && config.Server != nil && config.Server.Game != nil {
+		port = config.Server.Game.Port
+	}
+
+	nodeID := cluster.NodeID
+	if nodeID == "" {
+		host, err := os.Hostname()
+		if err != nil || host == "" {
+			host = "localhost"
+		}
+		nodeID = fmt.Sprintf("%s-%d", host, port)
+	}
+
+	url := cluster.PublicURL
+	if url == "" {
+		url = fmt.Sprintf("ws://localhost:%d/ws", port)
+	}
+
+	return &NodeAgent{
+		registry:    registry,
+		roomCache:   roomCache,
+		hub:         hub,
+		gameUsecase: gameUsecase,
+		nodeID:      nodeID,
+		url:         url,
+		startedAt:   time.Now(),
+		interval:    time.Duration(cluster.HeartbeatInterval) * time.Second,
+		ttl:         time.Duration(cluster.NodeTTL) * time.Second,
+		logger:      logger.With("component", "node_agent", "node_id", nodeID),
+		report:      make(chan struct{}, 1),
+	}
+}
+
+// NodeID 獲取本節點 ID
+func (a *NodeAgent) NodeID() string {
+	return a.nodeID
+}
+
+// OnDrainRequested 設置收到下線請求時的回調，需在 Start 之前調用
+func (a *NodeAgent) OnDrainRequested(fn func()) {
+	a.onDrain = fn
+}
+
+// Start 立即註冊本節點並開始定期心跳
+func (a *NodeAgent) Start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stop != nil {
+		return
+	}
+	a.stop = make(chan struct{})
+	a.stopped = make(chan struct{})
+
+	a.logger.Infof("Registering game server node: url=%s, heartbeat=%v, ttl=%v", a.url, a.interval, a.ttl)
+	go a.run(a.stop, a.stopped)
+}
+
+// Stop 停止心跳並從集群中移除本節點
+func (a *NodeAgent) Stop() {
+	a.mu.Lock()
+	stop, stopped := a.stop, a.stopped
+	a.stop, a.stopped = nil, nil
+	a.mu.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-stopped
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := a.registry.DeregisterNode(ctx, a.nodeID); err != nil {
+		a.logger.Errorf("Failed to deregister node: %v", err)
+		return
+	}
+	a.logger.Info("Game server node deregistered")
+}
+
+// run 心跳循環
+func (a *NodeAgent) run(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), a.interval)
+		if err := a.heartbeat(ctx); err != nil {
+			a.logger.Errorf("Node heartbeat failed: %v", err)
+		}
+		cancel()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-a.report:
+		}
+	}
+}
+
+// RequestReport 請求在下一次循環中立即上報房間列表，不阻塞
+func (a *NodeAgent) RequestReport() {
+	select {
+	case a.report <- struct{}{}:
+	default:
+	}
+}
+
+// heartbeat 上報房間列表並刷新節點註冊
+func (a *NodeAgent) heartbeat(ctx context.Context) error {
+	rooms, err := a.collectRooms(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := a.roomCache.UpdateRoomInfo(ctx, a.nodeID, rooms); err != nil {
+		return fmt.Errorf("failed to report rooms: %w", err)
+	}
+
+	node := &lobby.GameNode{
+		NodeID:        a.nodeID,
+		URL:           a.url,
+		Connections:   a.hub.GetStats().ActiveConnections,
+		Rooms:         len(rooms),
+		Draining:      a.draining.Load(),
+		StartedAt:     a.startedAt,
+		LastHeartbeat: time.Now(),
+	}
+	if err := a.registry.RegisterNode(ctx, node, a.ttl); err != nil {
+		return fmt.Errorf("failed to register node: %w", err)
+	}
+
+	if !node.Draining && a.onDrain != nil {
+		requested, err := a.registry.DrainRequested(ctx, a.nodeID)
+		if err != nil {
+			return fmt.Errorf("failed to check drain request: %w", err)
+		}
+		if requested {
+			a.logger.Info("Drain requested by admin")
+			go a.onDrain()
+		}
+	}
+	return nil
+}
+
+// markDraining 標記本節點進入下線模式並立即上報，大廳不再路由新玩家到本節點
+func (a *NodeAgent) markDraining(ctx context.Context) {
+	if a.draining.Swap(true) {
+		return
+	}
+	if err := a.heartbeat(ctx); err != nil {
+		a.logger.Errorf("Failed to report draining state: %v", err)
+	}
+}
+
+// alternateURL 選擇連接數最少的其他在線節點，供客戶端重連；沒有可用節點時返回空字符串
+func (a *NodeAgent) alternateURL(ctx context.Context) string {
+	nodes, err := a.registry.ListNodes(ctx)
+	if err != nil {
+		a.logger.Warnf("Failed to list nodes for reconnect: %v", err)
+		return ""
+	}
+
+	var best *lobby.GameNode
+	for _, node := range nodes {
+		if node.NodeID == a.nodeID || node.Draining || time.Since(node.LastHeartbeat) > a.ttl {
+			continue
+		}
+		if best == nil || node.Connections < best.Connections {
+			best = node
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.URL
+}
+
+// collectRooms 將本節點的房間轉換為大廳房間資訊
+func (a *NodeAgent) collectRooms(ctx context.Context) ([]*lobby.RoomInfo, error) {
+	rooms, err := a.gameUsecase.GetRoomList(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room list: %w", err)
+	}
+
+	infos := make([]*lobby.RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		info := &lobby.RoomInfo{
+			RoomID:         room.ID,
+			RoomName:       room.Name,
+			BetMultiplier:  int(room.Config.BulletCostMultiplier),
+			MinCoins:       room.Config.MinBet,
+			Status:         string(room.Status),
+			CurrentPlayers: len(room.Players),
+			MaxPlayers:     int(room.MaxPlayers),
+			GameServerID:   a.nodeID,
+			ConfigVersion:  room.Config.Version,
+		}
+		if private, ok := a.gameUsecase.PrivateRoomInfo(room.ID); ok {
+			info.Private = true
+			info.InviteCode = private.InviteCode
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"net/http"
+	"strings"
+
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ========================================
+// 消息編解碼（按連接協商）
+// ========================================
+
+// WebSocket 子協議名稱
+const (
+	SubprotocolProtobuf  = "fish.protobuf.v1"
+	SubprotocolProtoJSON = "fish.protojson.v1"
+)
+
+// MessageCodec GameMessage 的線上編碼格式
+type MessageCodec interface {
+	// Name 編碼名稱（protobuf / protojson）
+	Name() string
+	// FrameType 使用的 WebSocket 幀類型
+	FrameType() int
+	Marshal(msg *pb.GameMessage) ([]byte, error)
+	Unmarshal(data []byte, msg *pb.GameMessage) error
+}
+
+// protobufCodec 二進制 Protobuf（默認）
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string   { return "protobuf" }
+func (protobufCodec) FrameType() int { return websocket.BinaryMessage }
+
+func (protobufCodec) Marshal(msg *pb.GameMessage) ([]byte, error) {
+	return proto.Marshal(msg)
+}
+
+func (protobufCodec) Unmarshal(data []byte, msg *pb.GameMessage) error {
+	return proto.Unmarshal(data, msg)
+}
+
+// protoJSONCodec protojson 文本格式，便於瀏覽器調試和第三方接入
+type protoJSONCodec struct{}
+
+var (
+	protoJSONMarshal   = protojson.MarshalOptions{}
+	protoJSONUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+func (protoJSONCodec) Name() string   { return "protojson" }
+func (protoJSONCodec) FrameType() int { return websocket.TextMessage }
+
+func (protoJSONCodec) Marshal(msg *pb.GameMessage) ([]byte, error) {
+	return protoJSONMarshal.Marshal(msg)
+}
+
+func (protoJSONCodec) Unmarshal(data []byte, msg *pb.GameMessage) error {
+	return protoJSONUnmarshal.Unmarshal(data, msg)
+}
+
+var (
+	ProtobufCodec  MessageCodec = protobufCodec{}
+	ProtoJSONCodec MessageCodec = protoJSONCodec{}
+)
+
+// supportedSubprotocols 伺服器支持的子協議（按優先順序）
+var supportedSubprotocols = []string{SubprotocolProtobuf, SubprotocolProtoJSON}
+
+// negotiateCodec 根據握手結果決定連接的編碼
+// 優先使用已協商的子協議，其次是 codec 查詢參數，默認為 Protobuf
+func negotiateCodec(conn *websocket.Conn, r *http.Request) MessageCodec {
+	switch conn.Subprotocol() {
+	case SubprotocolProtoJSON:
+		return ProtoJSONCodec
+	case SubprotocolProtobuf:
+		return ProtobufCodec
+	}
+
+	switch strings.ToLower(r.URL.Query().Get("codec")) {
+	case "json", "protojson":
+		return ProtoJSONCodec
+	}
+	return ProtobufCodec
+}
+
+// encodeForCodec 將內部統一使用的 Protobuf 字節轉換為連接協商的編碼
+// Hub 和 RoomManager 的廣播只序列化一次 Protobuf，由各連接在寫出時按需轉碼
+func encodeForCodec(codec MessageCodec, data []byte) ([]byte, error) {
+	if codec == nil || codec == ProtobufCodec {
+		return data, nil
+	}
+
+	var msg pb.GameMessage
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return codec.Marshal(&msg)
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// dialWithCodec 啟動測試伺服器並返回協商出的編碼和兩端連接
+func dialWithCodec(t *testing.T, query string, subprotocols []string) (MessageCodec, *websocket.Conn, *websocket.Conn) {
+	type result struct {
+		codec MessageCodec
+		conn  *websocket.Conn
+	}
+	ch := make(chan result, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := websocket.Upgrader{Subprotocols: supportedSubprotocols}
+		conn, err := u.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		ch <- result{negotiateCodec(conn, r), conn}
+	}))
+	t.Cleanup(srv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: subprotocols}
+	clientConn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { clientConn.Close() })
+
+	res := <-ch
+	return res.codec, res.conn, clientConn
+}
+
+func TestNegotiateCodec(t *testing.T) {
+	codec, _, _ := dialWithCodec(t, "", nil)
+	assert.Equal(t, ProtobufCodec, codec)
+
+	codec, _, conn := dialWithCodec(t, "", []string{SubprotocolProtoJSON})
+	assert.Equal(t, ProtoJSONCodec, codec)
+	assert.Equal(t, SubprotocolProtoJSON, conn.Subprotocol())
+
+	codec, _, _ = dialWithCodec(t, "?codec=protojson", nil)
+	assert.Equal(t, ProtoJSONCodec, codec)
+
+	// 已協商的子協議優先於查詢參數
+	codec, _, _ = dialWithCodec(t, "?codec=json", []string{SubprotocolProtobuf})
+	assert.Equal(t, ProtobufCodec, codec)
+}
+
+func TestWriteFrame_ProtoJSONClient(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	codec, serverConn, clientConn := dialWithCodec(t, "", []string{SubprotocolProtoJSON})
+
+	client := NewClient(serverConn, nil, log)
+	client.codec = codec
+
+	// 廣播路徑傳入的是 Protobuf 字節，寫出時按連接編碼轉為 protojson 文本幀
+	msg := &pb.GameMessage{
+		Type: pb.MessageType_FISH_DIED,
+		Data: &pb.GameMessage_FishDied{FishDied: &pb.FishDiedEvent{FishId: 7, Reward: 300}},
+	}
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, client.writeFrame(data, 1, false))
+
+	// 批量消息同樣轉碼
+	require.NoError(t, client.writeFrame(encodeBatch([][]byte{data, data}, 1), 2, true))
+
+	clientConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frameType, text, err := clientConn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, frameType)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(text, &decoded))
+	assert.Equal(t, "FISH_DIED", decoded["type"])
+
+	var roundTrip pb.GameMessage
+	require.NoError(t, ProtoJSONCodec.Unmarshal(text, &roundTrip))
+	assert.True(t, proto.Equal(msg, &roundTrip))
+
+	_, text, err = clientConn.ReadMessage()
+	require.NoError(t, err)
+	var batch pb.GameMessage
+	require.NoError(t, ProtoJSONCodec.Unmarshal(text, &batch))
+	assert.Len(t, batch.GetBatch().Messages, 2)
+
+	assert.Equal(t, "protojson", client.TrafficStats().Codec)
+}
@@ -0,0 +1,201 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+)
+
+// ========================================
+// 下線模式 - 滾動部署時的優雅下線
+// ========================================
+//
+// 1. 拒絕新連接和加入房間，在集群中標記為下線中，大廳不再路由新玩家到本節點
+// 2. 通知所有客戶端重連到其他節點（SERVER_DRAINING）
+// 3. 等待客戶端自行離開，超過一半的下線時間後強制斷開
+// 4. 離場玩家退還飛行中的子彈並結束遊戲記錄，最後關閉所有房間
+
+const drainPollInterval = 200 * time.Millisecond
+
+// Draining 節點是否處於下線模式
+func (h *Hub) Draining() bool {
+	return h.draining.Load()
+}
+
+// leavePlayer 玩家離開房間；下線模式中先結算飛行中的子彈再結束遊戲記錄
+func (h *Hub) leavePlayer(ctx context.Context, roomID string, playerID int64) error {
+	if h.draining.Load() {
+		_, err := h.gameUsecase.SettleAndLeaveRoom(ctx, roomID, playerID, game.EndReasonDrain)
+		return err
+	}
+	return h.gameUsecase.LeaveRoom(ctx, roomID, playerID)
+}
+
+// connectionCount 當前連接數
+func (h *Hub) connectionCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// notifyAll 向所有連接發送消息，返回通知的連接數
+func (h *Hub) notifyAll(message *pb.GameMessage) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		client.sendProtobuf(message)
+	}
+	return len(h.clients)
+}
+
+// disconnectAll 以 1001 (Going Away) 關閉所有連接，readPump 退出後按正常流程註銷並結算
+func (h *Hub) disconnectAll(reason string) int {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		if client.conn == nil {
+			continue
+		}
+		client.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, reason), time.Now().Add(writeWait))
+		client.conn.Close()
+	}
+	return len(clients)
+}
+
+// newServerDrainingMessage 創建下線通知
+func newServerDrainingMessage(reconnectURL string, deadline time.Time) *pb.GameMessage {
+	return &pb.GameMessage{
+		Type: pb.MessageType_SERVER_DRAINING,
+		Data: &pb.GameMessage_ServerDraining{
+			ServerDraining: &pb.ServerDrainingEvent{
+				Reason:       game.EndReasonDrain,
+				ReconnectUrl: reconnectURL,
+				Deadline:     deadline.UnixMilli(),
+				Timestamp:    time.Now().Unix(),
+			},
+		},
+	}
+}
+
+// Drain 進入下線模式，直到所有玩家離開並結算完成或超時後返回
+// 可重複調用，只會執行一次；timeout 為 0 時使用配置的 cluster.drain_timeout
+func (app *GameApp) Drain(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = app.drainTimeout()
+	}
+	app.drainOnce.Do(func() {
+		defer close(app.drained)
+		app.drain(timeout)
+	})
+	<-app.drained
+}
+
+// Drained 下線完成後關閉的通道
+func (app *GameApp) Drained() <-chan struct{} {
+	return app.drained
+}
+
+// drainTimeout 配置的下線超時時間
+func (app *GameApp) drainTimeout() time.Duration {
+	if app.config != nil && app.config.Cluster != nil && app.config.Cluster.DrainTimeout > 0 {
+		return time.Duration(app.config.Cluster.DrainTimeout) * time.Second
+	}
+	return 60 * time.Second
+}
+
+// drain 執行下線流程
+func (app *GameApp) drain(timeout time.Duration) {
+	start := time.Now()
+	deadline := start.Add(timeout)
+	disconnectAt := start.Add(timeout / 2)
+	app.logger.Infof("Draining game server: timeout=%v", timeout)
+
+	app.hub.draining.Store(true)
+	app.matchmaker.Stop()
+	app.seatKeeper.Stop()
+	app.roomConfigs.Stop()
+	app.roomEvents.Stop()
+	app.gameUsecase.DrainRooms("node draining")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	app.nodeAgent.markDraining(ctx)
+	reconnectURL := app.nodeAgent.alternateURL(ctx)
+	cancel()
+
+	notified := app.hub.notifyAll(newServerDrainingMessage(reconnectURL, disconnectAt))
+	app.logger.Infof("Notified %d clients to reconnect (reconnect_url=%q)", notified, reconnectURL)
+
+	// 先讓客戶端自行離開，超時後強制斷開
+	if !waitUntil(disconnectAt, func() bool { return app.hub.connectionCount() == 0 }) {
+		disconnected := app.hub.disconnectAll("server draining")
+		app.logger.Infof("Disconnected %d remaining clients", disconnected)
+	}
+
+	// 等待連接註銷和離場結算完成
+	if !waitUntil(deadline, func() bool {
+		return app.hub.connectionCount() == 0 && app.hub.pendingLeaves.Load() == 0
+	}) {
+		app.logger.Warnf("Drain timed out: connections=%d, pending leaves=%d",
+			app.hub.connectionCount(), app.hub.pendingLeaves.Load())
+	}
+
+	settled := app.settleRemaining()
+	// 所有玩家已結算、房間已關閉，不需要再恢復
+	app.checkpointer.Clear()
+	app.logger.Infof("Drain completed in %v: notified=%d, settled on close=%d",
+		time.Since(start).Round(time.Millisecond), notified, settled)
+}
+
+// settleRemaining 結算仍留在房間中的玩家（例如連接斷開但離場未完成）並關閉所有房間
+func (app *GameApp) settleRemaining() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rooms, err := app.gameUsecase.GetRoomList(ctx, "")
+	if err != nil {
+		app.logger.Errorf("Failed to get rooms for settlement: %v", err)
+		return 0
+	}
+
+	settled := 0
+	for _, room := range rooms {
+		playerIDs := make([]int64, 0, len(room.Players))
+		for playerID := range room.Players {
+			playerIDs = append(playerIDs, playerID)
+		}
+
+		for _, playerID := range playerIDs {
+			if _, err := app.gameUsecase.SettleAndLeaveRoom(ctx, room.ID, playerID, game.EndReasonDrain); err != nil {
+				app.logger.Errorf("Failed to settle player %d in room %s: %v", playerID, room.ID, err)
+				continue
+			}
+			settled++
+		}
+
+		if err := app.gameUsecase.CloseRoom(ctx, room.ID); err != nil {
+			app.logger.Errorf("Failed to close room %s: %v", room.ID, err)
+		}
+	}
+	return settled
+}
+
+// waitUntil 輪詢直到條件成立或到達截止時間，返回條件是否成立
+func waitUntil(deadline time.Time, done func() bool) bool {
+	for !done() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(drainPollInterval)
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// stubNodeRegistry 返回固定節點列表的註冊表
+type stubNodeRegistry struct {
+	nodes []*lobby.GameNode
+}
+
+func (r *stubNodeRegistry) RegisterNode(ctx context.Context, node *lobby.GameNode, ttl time.Duration) error {
+	return nil
+}
+func (r *stubNodeRegistry) DeregisterNode(ctx context.Context, nodeID string) error { return nil }
+func (r *stubNodeRegistry) RequestDrain(ctx context.Context, nodeID string) error   { return nil }
+func (r *stubNodeRegistry) DrainRequested(ctx context.Context, nodeID string) (bool, error) {
+	return false, nil
+}
+func (r *stubNodeRegistry) ListNodes(ctx context.Context) ([]*lobby.GameNode, error) {
+	return r.nodes, nil
+}
+func (r *stubNodeRegistry) EvictExpiredNodes(ctx context.Context, now time.Time) ([]string, error) {
+	return nil, nil
+}
+
+func TestServeWS_RejectsWhenDraining(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	hub := &Hub{rateLimiter: NewMessageRateLimiter(nil, log)}
+	hub.draining.Store(true)
+	handler := NewWebSocketHandler(hub, nil, nil, nil, nil, log)
+
+	srv := httptest.NewServer(http.HandlerFunc(handler.ServeWS))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(data, &msg))
+	assert.Equal(t, pb.ErrorCode_NODE_DRAINING, msg.GetError().ErrorCode)
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater))
+}
+
+func TestNotifyAll_ServerDraining(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	hub := &Hub{clients: make(map[*Client]bool)}
+	clients := []*Client{NewClient(nil, hub, log), NewClient(nil, hub, log)}
+	for _, client := range clients {
+		hub.clients[client] = true
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	notified := hub.notifyAll(newServerDrainingMessage("ws://other/ws", deadline))
+	assert.Equal(t, 2, notified)
+
+	for _, client := range clients {
+		var msg pb.GameMessage
+		require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+		require.Equal(t, pb.MessageType_SERVER_DRAINING, msg.Type)
+		assert.Equal(t, "ws://other/ws", msg.GetServerDraining().ReconnectUrl)
+		assert.Equal(t, deadline.UnixMilli(), msg.GetServerDraining().Deadline)
+	}
+}
+
+func TestNodeAgent_AlternateURL(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	now := time.Now()
+	registry := &stubNodeRegistry{nodes: []*lobby.GameNode{
+		{NodeID: "self", URL: "ws://self/ws", LastHeartbeat: now},
+		{NodeID: "busy", URL: "ws://busy/ws", Connections: 50, LastHeartbeat: now},
+		{NodeID: "idle", URL: "ws://idle/ws", Connections: 1, LastHeartbeat: now},
+		{NodeID: "draining", URL: "ws://draining/ws", Draining: true, LastHeartbeat: now},
+		{NodeID: "stale", URL: "ws://stale/ws", LastHeartbeat: now.Add(-time.Hour)},
+	}}
+
+	agent := NewNodeAgent(registry, nil, nil, nil, nil, log)
+	agent.nodeID = "self"
+
+	assert.Equal(t, "ws://idle/ws", agent.alternateURL(context.Background()))
+
+	registry.nodes = registry.nodes[:1]
+	assert.Empty(t, agent.alternateURL(context.Background()))
+}
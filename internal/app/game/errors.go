@@ -0,0 +1,133 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	bizgame "github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+)
+
+// ========================================
+// 客戶端錯誤碼
+// ========================================
+
+// errorHint 錯誤碼的重試提示
+type errorHint struct {
+	retryable  bool
+	retryAfter time.Duration
+}
+
+// errorHints 可重試的錯誤碼及建議等待時間，未列出的錯誤碼不可重試
+var errorHints = map[pb.ErrorCode]errorHint{
+	pb.ErrorCode_INTERNAL_ERROR:     {retryable: true, retryAfter: time.Second},
+	pb.ErrorCode_REQUEST_TIMEOUT:    {retryable: true, retryAfter: time.Second},
+	pb.ErrorCode_RATE_LIMITED:       {retryable: true, retryAfter: time.Second},
+	pb.ErrorCode_TEMPORARILY_BANNED: {retryable: true},
+	pb.ErrorCode_ROOM_FULL:          {retryable: true, retryAfter: 5 * time.Second},
+	pb.ErrorCode_NO_ROOM_AVAILABLE:  {retryable: true, retryAfter: 5 * time.Second},
+	pb.ErrorCode_SPECTATORS_FULL:    {retryable: true, retryAfter: 5 * time.Second},
+	pb.ErrorCode_WALLET_UNAVAILABLE: {retryable: true, retryAfter: 2 * time.Second},
+}
+
+// errorCodeOf 將業務層錯誤映射為客戶端錯誤碼
+func errorCodeOf(err error) pb.ErrorCode {
+	switch {
+	case err == nil:
+		return pb.ErrorCode_ERROR_CODE_UNSPECIFIED
+
+	// 錢包層錯誤優先判斷：遊戲層會以 ErrWalletOperation 包裹它們
+	case errors.Is(err, wallet.ErrInsufficientBalance), errors.Is(err, bizgame.ErrInsufficientBalance):
+		return pb.ErrorCode_INSUFFICIENT_BALANCE
+	case errors.Is(err, wallet.ErrWalletFrozen):
+		return pb.ErrorCode_WALLET_FROZEN
+	case errors.Is(err, wallet.ErrWalletNotFound):
+		return pb.ErrorCode_WALLET_NOT_FOUND
+	case errors.Is(err, wallet.ErrInvalidAmount):
+		return pb.ErrorCode_INVALID_ARGUMENT
+	case errors.Is(err, bizgame.ErrWalletOperation):
+		return pb.ErrorCode_WALLET_UNAVAILABLE
+
+	case errors.Is(err, account.ErrAccountBanned):
+		return pb.ErrorCode_ACCOUNT_BANNED
+	case errors.Is(err, account.ErrRoomTypeRestricted):
+		return pb.ErrorCode_ROOM_TYPE_RESTRICTED
+	case errors.Is(err, account.ErrChatMuted):
+		return pb.ErrorCode_CHAT_MUTED
+
+	case errors.Is(err, bizgame.ErrRoomNotFound):
+		return pb.ErrorCode_ROOM_NOT_FOUND
+	case errors.Is(err, bizgame.ErrRoomDraining):
+		return pb.ErrorCode_NODE_DRAINING
+	case errors.Is(err, bizgame.ErrRoomFull):
+		return pb.ErrorCode_ROOM_FULL
+	case errors.Is(err, bizgame.ErrNoRoomAvailable):
+		return pb.ErrorCode_NO_ROOM_AVAILABLE
+	case errors.Is(err, bizgame.ErrSpectatorsFull):
+		return pb.ErrorCode_SPECTATORS_FULL
+	case errors.Is(err, bizgame.ErrPlayerSpectating):
+		return pb.ErrorCode_SPECTATOR_ACTION_FORBIDDEN
+	case errors.Is(err, bizgame.ErrRoomLocked):
+		return pb.ErrorCode_ROOM_LOCKED
+	case errors.Is(err, bizgame.ErrInvalidInviteCode):
+		return pb.ErrorCode_INVALID_INVITE_CODE
+	case errors.Is(err, bizgame.ErrWrongRoomPassword):
+		return pb.ErrorCode_WRONG_ROOM_PASSWORD
+	case errors.Is(err, bizgame.ErrKickedFromRoom):
+		return pb.ErrorCode_KICKED_FROM_ROOM
+	case errors.Is(err, bizgame.ErrNotRoomOwner):
+		return pb.ErrorCode_NOT_ROOM_OWNER
+	case errors.Is(err, bizgame.ErrPrivateRoomLimit):
+		return pb.ErrorCode_PRIVATE_ROOM_LIMIT
+	case errors.Is(err, bizgame.ErrInvalidSeat):
+		return pb.ErrorCode_INVALID_SEAT
+	case errors.Is(err, bizgame.ErrSeatTaken):
+		return pb.ErrorCode_SEAT_TAKEN
+	case errors.Is(err, bizgame.ErrSeatReserved):
+		return pb.ErrorCode_SEAT_RESERVED
+	case errors.Is(err, bizgame.ErrSwapRequestNotFound):
+		return pb.ErrorCode_SWAP_REQUEST_NOT_FOUND
+	case errors.Is(err, bizgame.ErrPlayerAlreadyInRoom):
+		return pb.ErrorCode_ALREADY_IN_ROOM
+	case errors.Is(err, bizgame.ErrPlayerNotInRoom):
+		return pb.ErrorCode_NOT_IN_ROOM
+	case errors.Is(err, bizgame.ErrPlayerNotFound):
+		return pb.ErrorCode_PLAYER_NOT_FOUND
+	case errors.Is(err, bizgame.ErrInvalidBulletPower):
+		return pb.ErrorCode_INVALID_BULLET_POWER
+	case errors.Is(err, bizgame.ErrBulletNotFound):
+		return pb.ErrorCode_BULLET_NOT_FOUND
+	case errors.Is(err, bizgame.ErrFishNotFound):
+		return pb.ErrorCode_FISH_NOT_FOUND
+
+	case errors.Is(err, context.DeadlineExceeded):
+		return pb.ErrorCode_REQUEST_TIMEOUT
+	}
+	return pb.ErrorCode_INTERNAL_ERROR
+}
+
+// newErrorMessage 構建帶錯誤碼和重試提示的錯誤消息
+// retryAfter 大於 0 時覆蓋默認的建議等待時間（如封禁剩餘時長）
+func newErrorMessage(code pb.ErrorCode, message string, retryAfter time.Duration) *pb.GameMessage {
+	hint := errorHints[code]
+	if retryAfter > 0 {
+		hint.retryAfter = retryAfter
+	}
+
+	return &pb.GameMessage{
+		Type: pb.MessageType_ERROR,
+		Data: &pb.GameMessage_Error{
+			Error: &pb.ErrorMessage{
+				Message:      message,
+				Code:         code.String(),
+				Timestamp:    time.Now().Unix(),
+				ErrorCode:    code,
+				Retryable:    hint.retryable,
+				RetryAfterMs: hint.retryAfter.Milliseconds(),
+			},
+		},
+	}
+}
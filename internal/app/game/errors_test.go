@@ -0,0 +1,65 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	bizgame "github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	cases := []struct {
+		err  error
+		want pb.ErrorCode
+	}{
+		{fmt.Errorf("%w: room_1", bizgame.ErrRoomNotFound), pb.ErrorCode_ROOM_NOT_FOUND},
+		{bizgame.ErrRoomFull, pb.ErrorCode_ROOM_FULL},
+		{fmt.Errorf("%w to join room", bizgame.ErrInsufficientBalance), pb.ErrorCode_INSUFFICIENT_BALANCE},
+		{bizgame.ErrPlayerNotInRoom, pb.ErrorCode_NOT_IN_ROOM},
+		{fmt.Errorf("%w: 2", bizgame.ErrSeatReserved), pb.ErrorCode_SEAT_RESERVED},
+		{fmt.Errorf("%w: %w", bizgame.ErrWalletOperation, wallet.ErrInsufficientBalance), pb.ErrorCode_INSUFFICIENT_BALANCE},
+		{fmt.Errorf("%w: %w", bizgame.ErrWalletOperation, wallet.ErrWalletFrozen), pb.ErrorCode_WALLET_FROZEN},
+		{fmt.Errorf("%w: %w", bizgame.ErrWalletOperation, errors.New("connection refused")), pb.ErrorCode_WALLET_UNAVAILABLE},
+		{errors.New("unexpected"), pb.ErrorCode_INTERNAL_ERROR},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, errorCodeOf(c.err), c.err.Error())
+	}
+}
+
+func TestSendBizError_IncludesRetryHints(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	client := NewClient(nil, nil, log)
+
+	client.sendBizError(fmt.Errorf("%w: %w", bizgame.ErrWalletOperation, errors.New("timeout")), "Failed to fire bullet")
+
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+	errMsg := msg.GetError()
+	require.NotNil(t, errMsg)
+	assert.Equal(t, pb.ErrorCode_WALLET_UNAVAILABLE, errMsg.ErrorCode)
+	assert.Equal(t, "WALLET_UNAVAILABLE", errMsg.Code)
+	assert.True(t, errMsg.Retryable)
+	assert.Equal(t, int64(2000), errMsg.RetryAfterMs)
+
+	// 不可重試的錯誤
+	client.sendErrorPB(pb.ErrorCode_SEAT_TAKEN, "Seat already taken")
+	require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+	assert.False(t, msg.GetError().Retryable)
+	assert.Zero(t, msg.GetError().RetryAfterMs)
+
+	// 顯式的等待時間覆蓋默認提示
+	banned := newErrorMessage(pb.ErrorCode_TEMPORARILY_BANNED, "banned", 30*time.Second).GetError()
+	assert.True(t, banned.Retryable)
+	assert.Equal(t, int64(30000), banned.RetryAfterMs)
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// guestTracker - 遊客進度
+// ========================================
+//
+// 帶會話的遊客連接時從 Redis 加載進度（餘額和入座記錄），入座、離座和斷開連接時保存，
+// 遊戲中只在內存中累計開火和捕獲。遊客升級為正式帳號後保存會被拒絕，之後不再保存。
+
+const guestProgressSaveTimeout = 3 * time.Second
+
+// guestTracker 一個遊客連接的進度，方法可以在 nil 上調用（舊版遊客令牌不保存進度）
+type guestTracker struct {
+	guests *account.GuestUsecase
+	logger logger.Logger
+
+	mu       sync.Mutex
+	progress *account.GuestProgress
+	upgraded bool
+}
+
+// loadGuestTracker 加載遊客進度；遊客已升級時返回 account.ErrGuestAlreadyUpgraded，
+// 其他錯誤時使用新遊客的進度繼續
+func loadGuestTracker(ctx context.Context, guests *account.GuestUsecase, nickname string, logger logger.Logger) (*guestTracker, error) {
+	progress, err := guests.LoadProgress(ctx, nickname)
+	if errors.Is(err, account.ErrGuestAlreadyUpgraded) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Warnf("Failed to load guest %s progress, starting fresh: %v", nickname, err)
+		progress = account.NewGuestProgress(nickname, time.Now().UTC())
+	}
+	return &guestTracker{guests: guests, logger: logger, progress: progress}, nil
+}
+
+// balance 已保存的餘額
+func (t *guestTracker) balance() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.progress.Balance
+}
+
+// startRound 遊客入座
+func (t *guestTracker) startRound(roomID string, balance int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.progress.StartRound(roomID, balance, time.Now().UTC())
+	t.mu.Unlock()
+	t.save()
+}
+
+// recordShot 記錄一次開火
+func (t *guestTracker) recordShot(cost int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.progress.RecordShot(cost)
+	t.mu.Unlock()
+}
+
+// recordCatch 記錄一次捕獲
+func (t *guestTracker) recordCatch(reward int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.progress.RecordCatch(reward)
+	t.mu.Unlock()
+}
+
+// endRound 遊客離座或斷開連接
+func (t *guestTracker) endRound(balance int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.progress.EndRound(balance, time.Now().UTC())
+	t.mu.Unlock()
+	t.save()
+}
+
+// save 保存進度的快照
+func (t *guestTracker) save() {
+	t.mu.Lock()
+	if t.upgraded {
+		t.mu.Unlock()
+		return
+	}
+	snapshot := *t.progress
+	snapshot.Rounds = append([]account.GuestRound(nil), t.progress.Rounds...)
+	if t.progress.Current != nil {
+		current := *t.progress.Current
+		snapshot.Current = &current
+	}
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), guestProgressSaveTimeout)
+	defer cancel()
+	err := t.guests.SaveProgress(ctx, &snapshot)
+	if errors.Is(err, account.ErrGuestAlreadyUpgraded) {
+		t.mu.Lock()
+		t.upgraded = true
+		t.mu.Unlock()
+		return
+	}
+	if err != nil {
+		t.logger.Errorf("Failed to save guest %s progress: %v", snapshot.Nickname, err)
+	}
+}
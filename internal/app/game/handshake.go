@@ -0,0 +1,172 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/conf"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+)
+
+// ========================================
+// 協議握手（HELLO / WELCOME）
+// ========================================
+
+// 協議版本
+// v1: 連接後直接收到 WELCOME，不發送 HELLO（舊客戶端）
+// v2: 客戶端以 HELLO 聲明版本和能力，伺服器以 WELCOME 回覆協商結果
+const (
+	ProtocolVersion       int32 = 2
+	MinProtocolVersion    int32 = 1
+	LegacyProtocolVersion int32 = 1 // 未聲明版本的客戶端按此版本兼容處理
+)
+
+// 伺服器功能開關（在 WELCOME.features 中聲明）
+const (
+	FeatureHello       = "hello"
+	FeatureBatch       = "batch"
+	FeatureProtoJSON   = "protojson"
+	FeatureCompression = "permessage_deflate"
+	FeatureRateLimit   = "rate_limit"
+)
+
+// 客戶端能力（在 HELLO.capabilities 中聲明）
+const (
+	CapabilityBatch = "batch" // 能解析 MESSAGE_BATCH
+)
+
+// handshakeState 連接的握手結果
+type handshakeState struct {
+	mu            sync.RWMutex
+	version       int32
+	capabilities  []string
+	clientVersion string
+	platform      string
+	declared      bool // 客戶端是否主動聲明過版本
+}
+
+// newHandshakeState 創建默認按舊版協議處理的握手狀態
+func newHandshakeState() *handshakeState {
+	return &handshakeState{version: LegacyProtocolVersion}
+}
+
+// Version 獲取協商的協議版本
+func (s *handshakeState) Version() int32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// Capabilities 獲取伺服器接受的客戶端能力
+func (s *handshakeState) Capabilities() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.capabilities...)
+}
+
+// checkProtocolVersion 檢查客戶端聲明的協議版本是否受支持
+func checkProtocolVersion(version int32) error {
+	if version < MinProtocolVersion || version > ProtocolVersion {
+		return fmt.Errorf("protocol version %d is not supported, server supports %d-%d",
+			version, MinProtocolVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// serverFeatures 根據配置計算伺服器啟用的功能開關
+func serverFeatures(wsConfig *conf.GameWebSocket, rateLimiter *MessageRateLimiter) []string {
+	features := []string{FeatureHello, FeatureProtoJSON}
+	if wsConfig != nil && wsConfig.Batching.Enable {
+		features = append(features, FeatureBatch)
+	}
+	if wsConfig != nil && wsConfig.Compression.Enable {
+		features = append(features, FeatureCompression)
+	}
+	if rateLimiter != nil && rateLimiter.Enabled() {
+		features = append(features, FeatureRateLimit)
+	}
+	return features
+}
+
+// parseConnectHandshake 解析連接請求中的握手參數（protocol_version、capabilities）
+// 未攜帶 protocol_version 時 declared 為 false，由後續 HELLO 或舊版兼容處理
+func parseConnectHandshake(r *http.Request) (version int32, capabilities []string, declared bool, err error) {
+	query := r.URL.Query()
+	raw := query.Get("protocol_version")
+	if raw == "" {
+		return LegacyProtocolVersion, nil, false, nil
+	}
+
+	v, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		return 0, nil, true, fmt.Errorf("invalid protocol_version %q", raw)
+	}
+
+	for _, c := range strings.Split(query.Get("capabilities"), ",") {
+		if c = strings.TrimSpace(strings.ToLower(c)); c != "" {
+			capabilities = append(capabilities, c)
+		}
+	}
+	return int32(v), capabilities, true, checkProtocolVersion(int32(v))
+}
+
+// applyHandshake 記錄協商結果並啟用客戶端支持的能力，返回伺服器接受的能力
+func (c *Client) applyHandshake(version int32, capabilities []string, clientVersion, platform string) []string {
+	var accepted []string
+	for _, capability := range capabilities {
+		switch strings.ToLower(capability) {
+		case CapabilityBatch:
+			if c.outbound.batchAllowed {
+				c.batching.Store(true)
+				accepted = append(accepted, CapabilityBatch)
+			}
+		}
+	}
+
+	c.handshake.mu.Lock()
+	c.handshake.version = version
+	c.handshake.capabilities = accepted
+	c.handshake.clientVersion = clientVersion
+	c.handshake.platform = platform
+	c.handshake.declared = true
+	c.handshake.mu.Unlock()
+
+	return accepted
+}
+
+// welcomeMessage 構建包含協商結果的 WELCOME 消息
+// 舊版客戶端只讀取 client_id 和 server_time，新增字段對其透明
+func (c *Client) welcomeMessage() *pb.GameMessage {
+	codec := ProtobufCodec.Name()
+	if c.codec != nil {
+		codec = c.codec.Name()
+	}
+
+	return &pb.GameMessage{
+		Type: pb.MessageType_WELCOME,
+		Data: &pb.GameMessage_Welcome{
+			Welcome: &pb.WelcomeMessage{
+				ClientId:           c.ID,
+				ServerTime:         time.Now().Unix(),
+				ProtocolVersion:    c.handshake.Version(),
+				MinProtocolVersion: MinProtocolVersion,
+				MaxProtocolVersion: ProtocolVersion,
+				Features:           c.features,
+				Capabilities:       c.handshake.Capabilities(),
+				Codec:              codec,
+			},
+		},
+	}
+}
+
+// closeWithError 發送錯誤消息後關閉連接（用於註冊後的協議錯誤）
+// 錯誤消息經 writePump 寫出後再發送關閉幀
+func (c *Client) closeWithError(code pb.ErrorCode, message string) {
+	c.sendErrorPB(code, message)
+	c.closeAfterFlush(websocket.CloseProtocolError, code.String())
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseConnectHandshake(t *testing.T) {
+	// 未聲明版本：按舊版協議兼容處理
+	version, caps, declared, err := parseConnectHandshake(httptest.NewRequest(http.MethodGet, "/ws", nil))
+	require.NoError(t, err)
+	assert.False(t, declared)
+	assert.Equal(t, LegacyProtocolVersion, version)
+	assert.Empty(t, caps)
+
+	version, caps, declared, err = parseConnectHandshake(
+		httptest.NewRequest(http.MethodGet, "/ws?protocol_version=2&capabilities=Batch,%20unknown", nil))
+	require.NoError(t, err)
+	assert.True(t, declared)
+	assert.Equal(t, ProtocolVersion, version)
+	assert.Equal(t, []string{"batch", "unknown"}, caps)
+
+	_, _, _, err = parseConnectHandshake(httptest.NewRequest(http.MethodGet, "/ws?protocol_version=99", nil))
+	assert.Error(t, err)
+	_, _, _, err = parseConnectHandshake(httptest.NewRequest(http.MethodGet, "/ws?protocol_version=abc", nil))
+	assert.Error(t, err)
+}
+
+func TestHandleHello_NegotiatesCapabilities(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	client := NewClient(nil, nil, log)
+	client.ID = "hello_client"
+	client.outbound.batchAllowed = true
+	client.features = []string{FeatureHello, FeatureBatch}
+
+	handler := NewMessageHandler(nil, nil, log)
+	handler.HandleMessage(client, &pb.GameMessage{
+		Type: pb.MessageType_HELLO,
+		Data: &pb.GameMessage_Hello{Hello: &pb.HelloMessage{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    []string{CapabilityBatch, "future_feature"},
+			ClientVersion:   "1.2.0",
+		}},
+	})
+
+	assert.True(t, client.batching.Load())
+	assert.Equal(t, ProtocolVersion, client.handshake.Version())
+
+	var welcome pb.GameMessage
+	require.NoError(t, proto.Unmarshal(<-client.send, &welcome))
+	require.Equal(t, pb.MessageType_WELCOME, welcome.Type)
+	w := welcome.GetWelcome()
+	assert.Equal(t, ProtocolVersion, w.ProtocolVersion)
+	assert.Equal(t, MinProtocolVersion, w.MinProtocolVersion)
+	assert.Equal(t, ProtocolVersion, w.MaxProtocolVersion)
+	assert.Equal(t, []string{CapabilityBatch}, w.Capabilities)
+	assert.Equal(t, []string{FeatureHello, FeatureBatch}, w.Features)
+	assert.Equal(t, "protobuf", w.Codec)
+}
+
+func TestHandleHello_BatchNotAllowed(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	client := NewClient(nil, nil, log)
+
+	accepted := client.applyHandshake(ProtocolVersion, []string{CapabilityBatch}, "", "")
+	assert.Empty(t, accepted)
+	assert.False(t, client.batching.Load())
+}
+
+func TestServeWS_RejectsUnsupportedProtocolVersion(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	hub := &Hub{rateLimiter: NewMessageRateLimiter(nil, log)}
+	handler := NewWebSocketHandler(hub, nil, nil, nil, nil, log)
+
+	srv := httptest.NewServer(http.HandlerFunc(handler.ServeWS))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?protocol_version=99", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(data, &msg))
+	require.Equal(t, pb.MessageType_ERROR, msg.Type)
+	assert.Equal(t, pb.ErrorCode_PROTOCOL_VERSION_UNSUPPORTED, msg.GetError().ErrorCode)
+	assert.Equal(t, "PROTOCOL_VERSION_UNSUPPORTED", msg.GetError().Code)
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+}
+
+func TestCloseWithError_FlushesErrorBeforeClose(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	serverConn := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConn <- conn
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := NewClient(<-serverConn, nil, log)
+	client.ID = "old_client"
+	go client.writePump()
+
+	client.closeWithError(pb.ErrorCode_PROTOCOL_VERSION_UNSUPPORTED, "protocol version 99 not supported")
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err, "error message is written before the close frame")
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(data, &msg))
+	assert.Equal(t, pb.ErrorCode_PROTOCOL_VERSION_UNSUPPORTED, msg.GetError().ErrorCode)
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseProtocolError))
+}
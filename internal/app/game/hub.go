@@ -3,8 +3,10 @@ package game
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/b7777777v/fish_server/internal/biz/account"
 	"github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/biz/player"
 	"github.com/b7777777v/fish_server/internal/pkg/logger"
@@ -42,6 +44,18 @@ type Hub struct {
 	gameUsecase   *game.GameUsecase
 	playerUsecase *player.PlayerUsecase
 
+	// WebSocket 消息限流器
+	rateLimiter *MessageRateLimiter
+
+	// 快速加入匹配器，為 nil 時不支持 QUICK_JOIN
+	matchmaker *Matchmaker
+
+	// 座位管理，為 nil 時斷線立即離開房間
+	seatKeeper *SeatKeeper
+
+	// 玩家處罰，為 nil 時不檢查封禁和房間類型限制
+	sanctions *account.SanctionUsecase
+
 	// 通道
 	register   chan *Client
 	unregister chan *Client
@@ -53,6 +67,11 @@ type Hub struct {
 	// 互斥鎖
 	mu sync.RWMutex
 
+	// 下線模式：拒絕新連接和加入房間，離場時結算飛行中的子彈
+	draining atomic.Bool
+	// 進行中的離場結算數量
+	pendingLeaves atomic.Int64
+
 	// 日誌記錄器
 	logger logger.Logger
 
@@ -72,6 +91,18 @@ type HubStats struct {
 	TotalMessages     int64     `json:"total_messages"`
 	LastActivity      time.Time `json:"last_activity"`
 	StartTime         time.Time `json:"start_time"`
+
+	// 出站流量統計（含已斷開連接的累計值）
+	BytesSent    int64 `json:"bytes_sent"`
+	FramesSent   int64 `json:"frames_sent"`
+	MessagesSent int64 `json:"messages_sent"`
+	BatchesSent  int64 `json:"batches_sent"`
+
+	// 當前連接的出站流量，key 為客戶端 ID
+	Clients map[string]ClientTrafficStats `json:"clients,omitempty"`
+
+	// 消息限流統計
+	RateLimit *RateLimitStats `json:"rate_limit,omitempty"`
 }
 
 // JoinRoomMessage 加入房間消息
@@ -100,10 +131,11 @@ type BroadcastMessage struct {
 	RoomID  string // 空字符串表示全局廣播
 	Message []byte
 	Exclude *Client // 排除的客戶端
+	TickEnd bool    // 房間 tick 結束，通知批量模式的客戶端送出本 tick 的消息
 }
 
 // NewHub 創建新的 Hub
-func NewHub(gameUsecase *game.GameUsecase, playerUsecase *player.PlayerUsecase, logger logger.Logger) *Hub {
+func NewHub(gameUsecase *game.GameUsecase, playerUsecase *player.PlayerUsecase, rateLimiter *MessageRateLimiter, matchmaker *Matchmaker, logger logger.Logger) *Hub {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Hub{
@@ -112,6 +144,8 @@ func NewHub(gameUsecase *game.GameUsecase, playerUsecase *player.PlayerUsecase,
 		roomManagers:  make(map[string]*RoomManager),
 		gameUsecase:   gameUsecase,
 		playerUsecase: playerUsecase,
+		rateLimiter:   rateLimiter,
+		matchmaker:    matchmaker,
 		register:      make(chan *Client, ChannelBufferSmall),             // 低頻操作使用小緩衝區
 		unregister:    make(chan *Client, ChannelBufferSmall),             // 低頻操作使用小緩衝區
 		joinRoom:      make(chan *JoinRoomMessage, ChannelBufferSmall),    // 低頻操作使用小緩衝區
@@ -184,6 +218,10 @@ func (h *Hub) Run() {
 				h.handleGameAction(msg)
 
 			case msg := <-h.broadcast:
+				if msg.TickEnd {
+					h.flushRoomTick(msg.RoomID)
+					return
+				}
 				h.logger.Infof("[HUB] Processing broadcast for room %s, size=%d", msg.RoomID, len(msg.Message))
 				h.handleBroadcast(msg)
 				h.logger.Infof("[HUB] Completed broadcast for room %s", msg.RoomID)
@@ -211,17 +249,11 @@ func (h *Hub) handleRegister(client *Client) {
 
 	h.logger.Infof("Client registered: %s (total: %d)", client.ID, len(h.clients))
 
-	// 發送歡迎消息
-	welcomeMsg := &pb.GameMessage{
-		Type: pb.MessageType_WELCOME,
-		Data: &pb.GameMessage_Welcome{
-			Welcome: &pb.WelcomeMessage{
-				ClientId:   client.ID,
-				ServerTime: time.Now().Unix(),
-			},
-		},
-	}
-	client.sendProtobuf(welcomeMsg)
+	// 發送歡迎消息（舊版客戶端以此完成連接；新版客戶端會在 HELLO 後收到協商結果）
+	client.sendProtobuf(client.welcomeMessage())
+
+	// 節點恢復房間或斷線保留座位後，玩家重新連接時回到原座位
+	go h.resumeSession(client)
 }
 
 // handleUnregister 處理客戶端註銷
@@ -234,15 +266,36 @@ func (h *Hub) handleUnregister(client *Client) {
         delete(h.clients, client)
         close(client.send)
 
+        // 累計已斷開連接的流量
+        traffic := client.traffic.snapshot()
+        h.stats.BytesSent += traffic.BytesSent
+        h.stats.FramesSent += traffic.FramesSent
+        h.stats.MessagesSent += traffic.MessagesSent
+        h.stats.BatchesSent += traffic.BatchesSent
+
         // 從房間移除
         if client.RoomID != "" {
             // 調用業務邏輯以確保結算與紀錄完成
-            go func(roomID string, playerID int64) {
+            h.pendingLeaves.Add(1)
+            go func(roomID string, playerID int64, spectating bool) {
+                defer h.pendingLeaves.Add(-1)
                 if roomID == "" || playerID == 0 {
                     return
                 }
-                _ = h.gameUsecase.LeaveRoom(context.Background(), roomID, playerID)
-            }(client.RoomID, client.PlayerID)
+                if spectating {
+                    _ = h.gameUsecase.StopWatching(context.Background(), roomID, playerID)
+                    return
+                }
+                // 結算後保存遊客進度，重新連接恢復座位時重新開始一輪
+                if client.guest != nil {
+                    defer client.guest.endRound(client.GuestPlayer.Balance)
+                }
+                // 保留座位等待重新連接，未啟用或下線模式中直接離開結算
+                if h.seatKeeper != nil && h.seatKeeper.reserve(context.Background(), roomID, playerID) {
+                    return
+                }
+                _ = h.leavePlayer(context.Background(), roomID, playerID)
+            }(client.RoomID, client.PlayerID, client.Spectating)
             h.removeClientFromRoom(client, client.RoomID)
         }
 
@@ -288,6 +341,11 @@ func (h *Hub) handleJoinRoom(msg *JoinRoomMessage) {
 	// 通知房間管理器
 	h.roomManagers[roomID].AddClient(client)
 
+	// 觀戰者由消息處理器回覆 WATCH_ROOM_RESPONSE，也不通知房間其他玩家
+	if client.Spectating {
+		return
+	}
+
 	// 發送加入成功消息
 	joinMsg := &pb.GameMessage{
 		Type: pb.MessageType_JOIN_ROOM_RESPONSE,
@@ -321,6 +379,19 @@ func (h *Hub) handleJoinRoom(msg *JoinRoomMessage) {
 	h.mu.Lock() // 重新獲取鎖以確保 defer 能正常工作
 }
 
+// roomClient 查找房間內指定玩家的連接，不在本節點時返回 nil
+func (h *Hub) roomClient(roomID string, playerID int64) *Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.rooms[roomID] {
+		if client.PlayerID == playerID {
+			return client
+		}
+	}
+	return nil
+}
+
 // handleLeaveRoom 處理離開房間
 func (h *Hub) handleLeaveRoom(msg *LeaveRoomMessage) {
 	h.mu.Lock()
@@ -330,6 +401,7 @@ func (h *Hub) handleLeaveRoom(msg *LeaveRoomMessage) {
 	roomID := msg.RoomID
 
 	h.removeClientFromRoom(client, roomID)
+	client.Spectating = false
 
 	h.logger.Infof("Client %s left room %s", client.ID, roomID)
 
@@ -366,7 +438,7 @@ func (h *Hub) removeClientFromRoom(client *Client, roomID string) {
 					roomManager.Stop()
 					delete(h.roomManagers, roomID)
 				}
-			} else {
+			} else if !client.Spectating {
 				// 通知房間其他玩家
 				playerLeaveMsg := &pb.GameMessage{
 					Type: pb.MessageType_PLAYER_LEFT,
@@ -401,7 +473,7 @@ func (h *Hub) handleGameAction(msg *GameActionMessage) {
 		roomManager.HandleGameAction(msg)
 	} else {
 		h.logger.Warnf("Room manager not found for room: %s", msg.RoomID)
-		msg.Client.sendError("Room not found")
+		msg.Client.sendError(pb.ErrorCode_ROOM_NOT_FOUND, "Room not found")
 	}
 }
 
@@ -502,6 +574,18 @@ func (h *Hub) GetStats() *HubStats {
 	statsCopy := *h.stats
 	statsCopy.ActiveConnections = len(h.clients)
 	statsCopy.ActiveRooms = len(h.rooms)
+	statsCopy.Clients = make(map[string]ClientTrafficStats, len(h.clients))
+	for client := range h.clients {
+		traffic := client.TrafficStats()
+		statsCopy.Clients[client.ID] = traffic
+		statsCopy.BytesSent += traffic.BytesSent
+		statsCopy.FramesSent += traffic.FramesSent
+		statsCopy.MessagesSent += traffic.MessagesSent
+		statsCopy.BatchesSent += traffic.BatchesSent
+	}
+	if h.rateLimiter != nil {
+		statsCopy.RateLimit = h.rateLimiter.GetStats()
+	}
 
 	return &statsCopy
 }
@@ -542,6 +626,31 @@ func (h *Hub) BroadcastToRoom(roomID string, message []byte, exclude *Client) {
 	}
 }
 
+// FlushRoomTick 房間 tick 結束時調用，讓批量模式的客戶端把本 tick 的消息合併為一幀送出
+// 標記與廣播走同一通道以保持順序；通道已滿時放棄，由客戶端的合併窗口兜底
+func (h *Hub) FlushRoomTick(roomID string) {
+	select {
+	case h.broadcast <- &BroadcastMessage{RoomID: roomID, TickEnd: true}:
+	default:
+	}
+}
+
+// flushRoomTick 向房間內批量模式的客戶端發送 tick 結束標記
+func (h *Hub) flushRoomTick(roomID string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.rooms[roomID] {
+		if !client.batching.Load() {
+			continue
+		}
+		select {
+		case client.send <- tickFlush:
+		default: // 發送隊列已滿，由合併窗口兜底
+		}
+	}
+}
+
 // BroadcastGlobal 全局廣播消息
 func (h *Hub) BroadcastGlobal(message []byte) {
 	h.broadcast <- &BroadcastMessage{
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// RoomLifecycleMonitor - 房間生命週期鉤子
+// ========================================
+
+// RoomLifecycleMonitor 註冊房間狀態轉換鉤子：把轉換記錄寫入 Redis 供管理後台查詢、
+// 請求節點立即上報房間列表，並統計各類轉換的次數
+type RoomLifecycleMonitor struct {
+	history     game.RoomHistoryRepo
+	gameUsecase *game.GameUsecase
+	nodeAgent   *NodeAgent
+
+	logger logger.Logger
+
+	once        sync.Once
+	mu          sync.Mutex
+	transitions map[string]int64 // key 為 "from->to"
+}
+
+// RoomLifecycleStats 房間生命週期統計
+type RoomLifecycleStats struct {
+	Rooms       map[game.RoomStatus]int `json:"rooms"`       // 各狀態的當前房間數
+	Transitions map[string]int64        `json:"transitions"` // 啟動以來各類轉換的次數
+}
+
+// NewRoomLifecycleMonitor 創建房間生命週期監控
+func NewRoomLifecycleMonitor(history game.RoomHistoryRepo, gameUsecase *game.GameUsecase, nodeAgent *NodeAgent, logger logger.Logger) *RoomLifecycleMonitor {
+	return &RoomLifecycleMonitor{
+		history:     history,
+		gameUsecase: gameUsecase,
+		nodeAgent:   nodeAgent,
+		logger:      logger.With("component", "room_lifecycle"),
+		transitions: make(map[string]int64),
+	}
+}
+
+// Start 註冊鉤子，需在恢復和創建房間之前調用
+func (m *RoomLifecycleMonitor) Start() {
+	m.once.Do(func() {
+		m.gameUsecase.OnRoomTransition(m.persist)
+		m.gameUsecase.OnRoomTransition(m.report)
+		m.gameUsecase.OnRoomTransition(m.count)
+	})
+}
+
+// persist 把轉換記錄寫入 Redis
+func (m *RoomLifecycleMonitor) persist(transition game.RoomTransition) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := m.history.AppendRoomTransition(ctx, transition); err != nil {
+		m.logger.Errorf("Failed to persist transition of room %s: %v", transition.RoomID, err)
+	}
+}
+
+// report 房間狀態變化後立即上報大廳
+func (m *RoomLifecycleMonitor) report(transition game.RoomTransition) {
+	m.nodeAgent.RequestReport()
+}
+
+// count 統計轉換次數
+func (m *RoomLifecycleMonitor) count(transition game.RoomTransition) {
+	m.mu.Lock()
+	m.transitions[fmt.Sprintf("%s->%s", transition.From, transition.To)]++
+	m.mu.Unlock()
+}
+
+// Stats 獲取房間生命週期統計
+func (m *RoomLifecycleMonitor) Stats() *RoomLifecycleStats {
+	stats := &RoomLifecycleStats{
+		Rooms:       make(map[game.RoomStatus]int),
+		Transitions: make(map[string]int64),
+	}
+
+	rooms, err := m.gameUsecase.GetRoomList(context.Background(), "")
+	if err != nil {
+		m.logger.Warnf("Failed to get rooms for lifecycle stats: %v", err)
+	}
+	for _, room := range rooms {
+		stats.Rooms[room.Status]++
+	}
+
+	m.mu.Lock()
+	for key, n := range m.transitions {
+		stats.Transitions[key] = n
+	}
+	m.mu.Unlock()
+	return stats
+}
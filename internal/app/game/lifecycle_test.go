@@ -0,0 +1,190 @@
+package game
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRoomHistoryRepo 內存房間轉換歷史
+type memoryRoomHistoryRepo struct {
+	mu      sync.Mutex
+	history map[string][]game.RoomTransition
+}
+
+func newMemoryRoomHistoryRepo() *memoryRoomHistoryRepo {
+	return &memoryRoomHistoryRepo{history: make(map[string][]game.RoomTransition)}
+}
+
+func (r *memoryRoomHistoryRepo) AppendRoomTransition(ctx context.Context, transition game.RoomTransition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history[transition.RoomID] = append(r.history[transition.RoomID], transition)
+	return nil
+}
+
+func (r *memoryRoomHistoryRepo) RoomHistory(ctx context.Context, roomID string) ([]game.RoomTransition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]game.RoomTransition(nil), r.history[roomID]...), nil
+}
+
+// transitionPath 轉換記錄的狀態路徑
+func transitionPath(history []game.RoomTransition) []game.RoomStatus {
+	path := make([]game.RoomStatus, 0, len(history)+1)
+	for i, transition := range history {
+		if i == 0 {
+			path = append(path, transition.From)
+		}
+		path = append(path, transition.To)
+	}
+	return path
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to game.RoomStatus
+		want     bool
+	}{
+		{game.RoomStatusCreated, game.RoomStatusWaiting, true},
+		{game.RoomStatusCreated, game.RoomStatusPlaying, false},
+		{game.RoomStatusWaiting, game.RoomStatusPlaying, true},
+		{game.RoomStatusPlaying, game.RoomStatusWaiting, true},
+		{game.RoomStatusPaused, game.RoomStatusPlaying, true},
+		{game.RoomStatusPlaying, game.RoomStatusDraining, true},
+		{game.RoomStatusDraining, game.RoomStatusPlaying, false},
+		{game.RoomStatusDraining, game.RoomStatusClosed, true},
+		{game.RoomStatusClosed, game.RoomStatusWaiting, false},
+		{game.RoomStatusWaiting, game.RoomStatusWaiting, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, game.CanTransition(tt.from, tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestRoomLifecycle_Transitions(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+
+	transitions := make(chan game.RoomTransition, 16)
+	gu.OnRoomTransition(func(transition game.RoomTransition) { transitions <- transition })
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	assert.Equal(t, game.RoomStatusWaiting, room.Status)
+
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+	require.NoError(t, guestJoiner(gu, 2)(room.ID))
+	assert.Equal(t, game.RoomStatusPlaying, room.Status)
+	require.NoError(t, gu.LeaveRoom(ctx, room.ID, 1))
+	assert.Equal(t, game.RoomStatusPlaying, room.Status)
+	require.NoError(t, gu.LeaveRoom(ctx, room.ID, 2))
+	assert.Equal(t, game.RoomStatusWaiting, room.Status)
+
+	// 下線中的房間不再接受新玩家
+	require.NoError(t, guestJoiner(gu, 3)(room.ID))
+	assert.Equal(t, 1, gu.DrainRooms("node draining"))
+	assert.Equal(t, game.RoomStatusDraining, room.Status)
+	assert.ErrorIs(t, guestJoiner(gu, 4)(room.ID), game.ErrRoomDraining)
+	assert.Zero(t, gu.DrainRooms("node draining"))
+
+	history, err := gu.RoomHistory(room.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []game.RoomStatus{
+		game.RoomStatusCreated, game.RoomStatusWaiting, game.RoomStatusPlaying,
+		game.RoomStatusWaiting, game.RoomStatusPlaying, game.RoomStatusDraining,
+	}, transitionPath(history))
+	assert.Equal(t, "player joined", history[1].Reason)
+	assert.Equal(t, 1, history[1].Players)
+
+	require.NoError(t, gu.CloseRoom(ctx, room.ID))
+	assert.Equal(t, game.RoomStatusClosed, room.Status)
+
+	// 鉤子按轉換順序收到所有轉換，包括房間關閉
+	var received []game.RoomTransition
+	for len(received) < len(history)+1 {
+		select {
+		case transition := <-transitions:
+			received = append(received, transition)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d transitions, want %d", len(received), len(history)+1)
+		}
+	}
+	assert.Equal(t, append(transitionPath(history), game.RoomStatusClosed), transitionPath(received))
+	assert.Equal(t, room.ID, received[0].RoomID)
+	assert.Equal(t, game.RoomTypeNovice, received[0].RoomType)
+}
+
+func TestRoomLifecycle_RestoredRoomPausedUntilReconnect(t *testing.T) {
+	guA := newMatchmakerTestUsecase(t)
+	guB := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, guA)
+	closeAllRooms(t, guB)
+	ctx := context.Background()
+
+	room, err := guA.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(guA, 1)(room.ID))
+	checkpoints := guA.SnapshotRooms("node-a")
+	require.Len(t, checkpoints, 1)
+
+	restored, err := guB.RestoreRoom(ctx, checkpoints[0], time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, game.RoomStatusPaused, restored.Status)
+
+	_, err = guB.ResumeSession(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, game.RoomStatusPlaying, restored.Status)
+
+	// 恢復的房間保留原節點的歷史，並在本節點重新開始生命週期
+	history, err := guB.RoomHistory(room.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []game.RoomStatus{
+		game.RoomStatusCreated, game.RoomStatusWaiting, game.RoomStatusPlaying,
+		game.RoomStatusCreated, game.RoomStatusPaused, game.RoomStatusPlaying,
+	}, transitionPath(history))
+	assert.Equal(t, "restored from checkpoint of node node-a", history[2].Reason)
+}
+
+func TestRoomLifecycleMonitor(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+	log := logger.New(os.Stdout, "error", "console")
+
+	repo := newMemoryRoomHistoryRepo()
+	hub := NewHub(gu, nil, nil, nil, log)
+	agent := NewNodeAgent(&stubNodeRegistry{}, nil, hub, gu, nil, log)
+	monitor := NewRoomLifecycleMonitor(repo, gu, agent, log)
+	monitor.Start()
+	monitor.Start()
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+
+	require.Eventually(t, func() bool {
+		history, _ := repo.RoomHistory(ctx, room.ID)
+		return len(history) == 2
+	}, time.Second, 10*time.Millisecond)
+	history, _ := repo.RoomHistory(ctx, room.ID)
+	assert.Equal(t, []game.RoomStatus{game.RoomStatusCreated, game.RoomStatusWaiting, game.RoomStatusPlaying}, transitionPath(history))
+
+	require.Eventually(t, func() bool {
+		return monitor.Stats().Transitions["waiting->playing"] == 1
+	}, time.Second, 10*time.Millisecond)
+	stats := monitor.Stats()
+	assert.Equal(t, int64(1), stats.Transitions["created->waiting"])
+	assert.Equal(t, 1, stats.Rooms[game.RoomStatusPlaying])
+
+	// 轉換後請求節點立即上報房間列表
+	assert.Len(t, agent.report, 1)
+}
@@ -0,0 +1,391 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// Matchmaker - 快速加入與房間擴縮容
+// ========================================
+//
+// 每種房間類型的房間數保持在 [min_count, max_count] 之間：
+// 1. 房間數不足 min_count 時補齊
+// 2. 所有房間都達到目標填充率時預先創建一個新房間（不超過 max_count）
+// 3. 空房間超過 idle_timeout 且房間數多於 min_count 時關閉
+// 私人房間不參與匹配和擴縮容，沒有玩家超過 private_rooms.idle_timeout 後關閉
+
+// quickJoinAttempts 快速加入時遇到房間已滿（並發加入）的重試次數
+const quickJoinAttempts = 3
+
+// roomPool 一種房間類型的擴縮容範圍
+type roomPool struct {
+	roomType   game.RoomType
+	maxPlayers int32
+	minCount   int
+	maxCount   int
+}
+
+// QuickJoinRequest 快速加入請求
+type QuickJoinRequest struct {
+	RoomType      game.RoomType // 為空時按主幣種餘額在主幣種房間中選擇
+	Balance       int64
+	Balances      map[string]int64 // 正式玩家各幣種餘額（分），為空時所有幣種都使用 Balance（遊客）
+	FriendIDs     []int64
+	PreferredFill float64 // 0 表示使用配置的目標填充率
+}
+
+// balanceIn 返回玩家在房間遊戲幣種下的餘額
+func (req QuickJoinRequest) balanceIn(currency string) int64 {
+	if req.Balances == nil {
+		return req.Balance
+	}
+	return req.Balances[currency]
+}
+
+// Matchmaker 快速加入匹配器，同時負責房間的擴縮容
+type Matchmaker struct {
+	gameUsecase *game.GameUsecase
+	logger      logger.Logger
+
+	pools []*roomPool // 按配置順序
+
+	targetFill    float64
+	stakeShots    int64
+	scaleInterval time.Duration
+	idleTimeout   time.Duration
+
+	privateIdleTimeout time.Duration
+	maxPrivatePerOwner int
+
+	// 創建和關閉房間串行執行，保證房間數不越界
+	mu         sync.Mutex
+	emptySince map[string]time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMatchmaker 創建快速加入匹配器
+func NewMatchmaker(gameUsecase *game.GameUsecase, config *conf.Config, logger logger.Logger) *Matchmaker {
+	mm := &Matchmaker{
+		gameUsecase:   gameUsecase,
+		logger:        logger.With("component", "matchmaker"),
+		targetFill:    0.75,
+		stakeShots:    100,
+		scaleInterval: 10 * time.Second,
+		idleTimeout:   120 * time.Second,
+		emptySince:    make(map[string]time.Time),
+		stopCh:        make(chan struct{}),
+
+		privateIdleTimeout: 300 * time.Second,
+		maxPrivatePerOwner: 1,
+	}
+
+	if config == nil || config.Game == nil {
+		return mm
+	}
+	if m := config.Game.Matchmaking; m != nil {
+		mm.targetFill = m.TargetFill
+		mm.stakeShots = m.StakeShots
+		mm.scaleInterval = time.Duration(m.ScaleInterval) * time.Second
+		mm.idleTimeout = time.Duration(m.IdleTimeout) * time.Second
+	}
+	if p := config.Game.PrivateRooms; p != nil {
+		mm.privateIdleTimeout = time.Duration(p.IdleTimeout) * time.Second
+		mm.maxPrivatePerOwner = p.MaxPerOwner
+	}
+
+	for _, pr := range config.Game.PrebuiltRooms {
+		rt, ok := parseRoomType(pr.Type)
+		if !ok {
+			mm.logger.Warnf("Unknown prebuilt room type: %s, skipping", pr.Type)
+			continue
+		}
+		mm.pools = append(mm.pools, &roomPool{
+			roomType:   rt,
+			maxPlayers: pr.MaxPlayers,
+			minCount:   pr.MinCount,
+			maxCount:   pr.MaxCount,
+		})
+	}
+	return mm
+}
+
+// parseRoomType 解析配置和客戶端傳入的房間類型
+func parseRoomType(s string) (game.RoomType, bool) {
+	switch strings.ToLower(s) {
+	case "novice":
+		return game.RoomTypeNovice, true
+	case "intermediate":
+		return game.RoomTypeIntermediate, true
+	case "advanced":
+		return game.RoomTypeAdvanced, true
+	case "vip":
+		return game.RoomTypeVIP, true
+	}
+	return "", false
+}
+
+// Start 創建最少數量的房間並啟動擴縮容循環
+func (mm *Matchmaker) Start() {
+	if len(mm.pools) == 0 {
+		mm.logger.Info("No prebuilt rooms configured")
+	}
+
+	go func() {
+		mm.scale(context.Background())
+
+		ticker := time.NewTicker(mm.scaleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mm.scale(context.Background())
+			case <-mm.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止擴縮容循環（下線模式和關閉時調用）
+func (mm *Matchmaker) Stop() {
+	mm.stopOnce.Do(func() { close(mm.stopCh) })
+}
+
+// stopped 擴縮容是否已停止
+func (mm *Matchmaker) stopped() bool {
+	select {
+	case <-mm.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// pool 獲取房間類型的擴縮容範圍
+func (mm *Matchmaker) pool(roomType game.RoomType) *roomPool {
+	for _, p := range mm.pools {
+		if p.roomType == roomType {
+			return p
+		}
+	}
+	return nil
+}
+
+// roomTypes 配置的房間類型
+func (mm *Matchmaker) roomTypes() []game.RoomType {
+	types := make([]game.RoomType, 0, len(mm.pools))
+	for _, p := range mm.pools {
+		types = append(types, p.roomType)
+	}
+	return types
+}
+
+// defaultCurrencyRoomTypes 返回使用主錢包幣種的房間類型，其他幣種的房間需要指定房間類型加入
+func (mm *Matchmaker) defaultCurrencyRoomTypes() []game.RoomType {
+	var types []game.RoomType
+	for _, rt := range mm.roomTypes() {
+		if mm.gameUsecase.RoomTypeConfig(rt).PlayCurrency() == wallet.DefaultCurrency {
+			types = append(types, rt)
+		}
+	}
+	return types
+}
+
+// QuickJoin 為玩家選擇或創建房間並通過 join 加入，返回加入的房間 ID 和類型
+// 房間在選中後被其他玩家坐滿時排除該房間重新選擇
+func (mm *Matchmaker) QuickJoin(ctx context.Context, req QuickJoinRequest, join func(roomID string) error) (string, game.RoomType, error) {
+	roomType := req.RoomType
+	if roomType == "" {
+		rt, ok := mm.gameUsecase.StakeRoomType(req.Balance, mm.stakeShots, mm.defaultCurrencyRoomTypes())
+		if !ok {
+			return "", "", game.ErrNoRoomAvailable
+		}
+		roomType = rt
+	}
+
+	pool := mm.pool(roomType)
+	if pool == nil {
+		return "", "", fmt.Errorf("%w: room type %s is not open", game.ErrNoRoomAvailable, roomType)
+	}
+	config := mm.gameUsecase.RoomTypeConfig(roomType)
+	if req.balanceIn(config.PlayCurrency()) < config.MinBet {
+		return "", "", fmt.Errorf("%w for room type %s", game.ErrInsufficientBalance, roomType)
+	}
+
+	criteria := game.MatchCriteria{
+		RoomType:   roomType,
+		FriendIDs:  req.FriendIDs,
+		TargetFill: mm.targetFill,
+		Exclude:    make(map[string]bool),
+	}
+	if req.PreferredFill > 0 && req.PreferredFill <= 1 {
+		criteria.TargetFill = req.PreferredFill
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < quickJoinAttempts; attempt++ {
+		roomID, err := mm.findRoom(ctx, pool, criteria)
+		if err != nil {
+			return "", "", err
+		}
+
+		err = join(roomID)
+		if err == nil {
+			mm.mu.Lock()
+			delete(mm.emptySince, roomID)
+			mm.mu.Unlock()
+			return roomID, roomType, nil
+		}
+		if !errors.Is(err, game.ErrRoomFull) && !errors.Is(err, game.ErrRoomNotFound) {
+			return "", "", err
+		}
+		criteria.Exclude[roomID] = true
+		lastErr = err
+	}
+	return "", "", fmt.Errorf("%w: %v", game.ErrNoRoomAvailable, lastErr)
+}
+
+// findRoom 選出最合適的房間，沒有可加入的房間時在 max_count 內創建新房間
+func (mm *Matchmaker) findRoom(ctx context.Context, pool *roomPool, criteria game.MatchCriteria) (string, error) {
+	if room, ok := game.SelectRoom(mm.gameUsecase.RoomStats(pool.roomType), criteria); ok {
+		return room.ID, nil
+	}
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	// 持鎖後重新檢查，避免並發請求重複創建
+	stats := mm.gameUsecase.RoomStats(pool.roomType)
+	if room, ok := game.SelectRoom(stats, criteria); ok {
+		return room.ID, nil
+	}
+	if len(stats) >= pool.maxCount {
+		return "", fmt.Errorf("%w: %s rooms reached max count %d", game.ErrNoRoomAvailable, pool.roomType, pool.maxCount)
+	}
+
+	room, err := mm.gameUsecase.CreateRoom(ctx, pool.roomType, pool.maxPlayers)
+	if err != nil {
+		return "", err
+	}
+	mm.logger.Infof("Scaled up %s rooms for quick join: created %s (%d/%d)", pool.roomType, room.ID, len(stats)+1, pool.maxCount)
+	return room.ID, nil
+}
+
+// scale 對所有房間類型執行一次擴縮容
+func (mm *Matchmaker) scale(ctx context.Context) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if mm.stopped() {
+		return
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	for _, pool := range mm.pools {
+		stats := mm.gameUsecase.RoomStats(pool.roomType)
+		for _, room := range stats {
+			seen[room.ID] = true
+		}
+		mm.scalePool(ctx, pool, stats, now)
+	}
+
+	// 清理已不存在的房間
+	for roomID := range mm.emptySince {
+		if !seen[roomID] {
+			delete(mm.emptySince, roomID)
+		}
+	}
+
+	for _, roomID := range mm.gameUsecase.CloseIdlePrivateRooms(ctx, now) {
+		mm.logger.Infof("Closed idle private room %s", roomID)
+	}
+}
+
+// CreatePrivateRoom 為玩家創建私人房間
+func (mm *Matchmaker) CreatePrivateRoom(ctx context.Context, roomType game.RoomType, ownerID int64, password string) (*game.Room, error) {
+	return mm.gameUsecase.CreatePrivateRoom(ctx, roomType, game.PrivateRoomOptions{
+		OwnerID:     ownerID,
+		Password:    password,
+		IdleTimeout: mm.privateIdleTimeout,
+		MaxPerOwner: mm.maxPrivatePerOwner,
+	})
+}
+
+// scalePool 對一種房間類型執行擴縮容，調用方需持有 mm.mu
+func (mm *Matchmaker) scalePool(ctx context.Context, pool *roomPool, stats []game.RoomStats, now time.Time) {
+	count := len(stats)
+
+	// 補齊最少房間數，本輪不再做其他調整
+	if count < pool.minCount {
+		for ; count < pool.minCount; count++ {
+			room, err := mm.gameUsecase.CreateRoom(ctx, pool.roomType, pool.maxPlayers)
+			if err != nil {
+				mm.logger.Errorf("Failed to create %s room: %v", pool.roomType, err)
+				return
+			}
+			mm.logger.Infof("Created %s room %s (%d/%d min)", pool.roomType, room.ID, count+1, pool.minCount)
+		}
+		return
+	}
+
+	// 所有房間都達到目標填充率時預先擴容
+	if count > 0 && count < pool.maxCount && allAtFill(stats, mm.targetFill) {
+		room, err := mm.gameUsecase.CreateRoom(ctx, pool.roomType, pool.maxPlayers)
+		if err != nil {
+			mm.logger.Errorf("Failed to scale up %s rooms: %v", pool.roomType, err)
+		} else {
+			mm.logger.Infof("Scaled up %s rooms: created %s (%d/%d)", pool.roomType, room.ID, count+1, pool.maxCount)
+		}
+		return
+	}
+
+	// 關閉空閒過久的多餘房間，先關閉最晚創建的
+	for i := len(stats) - 1; i >= 0; i-- {
+		room := stats[i]
+		if room.Players > 0 || room.Spectators > 0 {
+			delete(mm.emptySince, room.ID)
+			continue
+		}
+		since, ok := mm.emptySince[room.ID]
+		if !ok {
+			mm.emptySince[room.ID] = now
+			continue
+		}
+		if count <= pool.minCount || now.Sub(since) < mm.idleTimeout {
+			continue
+		}
+
+		if err := mm.gameUsecase.CloseIdleRoom(ctx, room.ID); err != nil {
+			if !errors.Is(err, game.ErrRoomNotEmpty) {
+				mm.logger.Errorf("Failed to close idle room %s: %v", room.ID, err)
+			}
+			continue
+		}
+		delete(mm.emptySince, room.ID)
+		count--
+		mm.logger.Infof("Scaled down %s rooms: closed idle room %s (%d/%d min)", pool.roomType, room.ID, count, pool.minCount)
+	}
+}
+
+// allAtFill 所有房間的填充率是否都達到 target
+func allAtFill(stats []game.RoomStats, target float64) bool {
+	for _, room := range stats {
+		if !room.Full() && room.Fill() < target {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,302 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newMatchmakerTestUsecase 創建使用內存房間的遊戲用例
+func newMatchmakerTestUsecase(t *testing.T) *game.GameUsecase {
+	gu, _ := newTestRoomUsecase(t)
+	return gu
+}
+
+// newTestRoomUsecase 創建使用內存房間的遊戲用例，同時返回房間管理器
+func newTestRoomUsecase(t *testing.T) (*game.GameUsecase, *game.RoomManager) {
+	log := logger.New(os.Stdout, "error", "console")
+
+	spawner := game.NewFishSpawner(log, game.RoomConfig{MaxFishCount: 20, RoomWidth: 1200, RoomHeight: 800})
+	mathModel := game.NewMathModel(log)
+	inventoryManager, err := game.NewInventoryManager(NewMockInventoryRepo(), log)
+	require.NoError(t, err)
+	rtpController := game.NewRTPController(inventoryManager, log)
+	roomManager := game.NewRoomManager(log, spawner, mathModel, inventoryManager, rtpController)
+
+	gameRecordRepo := &MockGameRecordRepo{}
+	gameRecordRepo.On("FindActiveByUserID", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+	gameRecordRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	walletUC := wallet.NewWalletUsecase(&MockWalletRepo{}, log)
+	return game.NewGameUsecase(&MockGameRepo{}, &MockPlayerRepo{}, gameRecordRepo, walletUC, roomManager, spawner, mathModel, inventoryManager, rtpController, log), roomManager
+}
+
+// newTestMatchmaker 創建只配置新手房的匹配器
+func newTestMatchmaker(gu *game.GameUsecase, minCount, maxCount int, targetFill float64) *Matchmaker {
+	config := &conf.Config{Game: &conf.Game{
+		PrebuiltRooms: []conf.PrebuiltRoom{{Type: "novice", MaxPlayers: 4, MinCount: minCount, MaxCount: maxCount}},
+		Matchmaking:   &conf.Matchmaking{TargetFill: targetFill, StakeShots: 100, ScaleInterval: 10, IdleTimeout: 120},
+	}}
+	return NewMatchmaker(gu, config, logger.New(os.Stdout, "error", "console"))
+}
+
+// closeAllRooms 停止測試中創建的房間（包括私人房間）遊戲循環
+func closeAllRooms(t *testing.T, gu *game.GameUsecase) {
+	t.Cleanup(func() {
+		rooms, _ := gu.GetRoomList(context.Background(), "")
+		for _, room := range rooms {
+			gu.CloseRoom(context.Background(), room.ID)
+		}
+	})
+}
+
+// guestJoiner 以遊客身份加入房間
+func guestJoiner(gu *game.GameUsecase, playerID int64) func(roomID string) error {
+	return func(roomID string) error {
+		return gu.JoinRoomWithPlayer(context.Background(), roomID, &game.Player{ID: playerID, Balance: 10000})
+	}
+}
+
+func TestSelectRoom(t *testing.T) {
+	now := time.Now()
+	rooms := []game.RoomStats{
+		{ID: "empty", Type: game.RoomTypeNovice, Players: 0, MaxPlayers: 4, CreatedAt: now},
+		{ID: "one", Type: game.RoomTypeNovice, Players: 1, MaxPlayers: 4, PlayerIDs: []int64{7}, CreatedAt: now},
+		{ID: "two", Type: game.RoomTypeNovice, Players: 2, MaxPlayers: 4, PlayerIDs: []int64{1, 2}, CreatedAt: now},
+		{ID: "full", Type: game.RoomTypeNovice, Players: 4, MaxPlayers: 4, PlayerIDs: []int64{3, 4, 5, 6}, CreatedAt: now},
+		{ID: "vip", Type: game.RoomTypeVIP, Players: 2, MaxPlayers: 4, CreatedAt: now},
+	}
+
+	t.Run("closest to target fill", func(t *testing.T) {
+		room, ok := game.SelectRoom(rooms, game.MatchCriteria{RoomType: game.RoomTypeNovice, TargetFill: 0.75})
+		require.True(t, ok)
+		assert.Equal(t, "two", room.ID)
+	})
+
+	t.Run("low target fill prefers emptier rooms", func(t *testing.T) {
+		room, ok := game.SelectRoom(rooms, game.MatchCriteria{RoomType: game.RoomTypeNovice, TargetFill: 0.25})
+		require.True(t, ok)
+		assert.Equal(t, "empty", room.ID)
+	})
+
+	t.Run("friends outweigh fill", func(t *testing.T) {
+		room, ok := game.SelectRoom(rooms, game.MatchCriteria{RoomType: game.RoomTypeNovice, TargetFill: 0.75, FriendIDs: []int64{7}})
+		require.True(t, ok)
+		assert.Equal(t, "one", room.ID)
+	})
+
+	t.Run("full rooms are skipped even with friends", func(t *testing.T) {
+		room, ok := game.SelectRoom(rooms, game.MatchCriteria{RoomType: game.RoomTypeNovice, TargetFill: 0.75, FriendIDs: []int64{3}})
+		require.True(t, ok)
+		assert.Equal(t, "two", room.ID)
+	})
+
+	t.Run("excluded rooms are skipped", func(t *testing.T) {
+		room, ok := game.SelectRoom(rooms, game.MatchCriteria{
+			RoomType:   game.RoomTypeNovice,
+			TargetFill: 0.75,
+			Exclude:    map[string]bool{"two": true},
+		})
+		require.True(t, ok)
+		// 剩下 one（差 0.25）和 empty（差 0.5）
+		assert.Equal(t, "one", room.ID)
+	})
+
+	t.Run("no candidate", func(t *testing.T) {
+		_, ok := game.SelectRoom(rooms, game.MatchCriteria{RoomType: game.RoomTypeAdvanced, TargetFill: 0.75})
+		assert.False(t, ok)
+	})
+}
+
+func TestStakeRoomType(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	types := []game.RoomType{game.RoomTypeVIP, game.RoomTypeNovice, game.RoomTypeIntermediate}
+
+	tests := []struct {
+		balance int64
+		want    game.RoomType
+	}{
+		{balance: 50, want: game.RoomTypeNovice},
+		{balance: 9999, want: game.RoomTypeNovice},
+		{balance: 10000, want: game.RoomTypeIntermediate},
+		{balance: 1000000, want: game.RoomTypeVIP},
+	}
+	for _, tt := range tests {
+		got, ok := gu.StakeRoomType(tt.balance, 100, types)
+		require.True(t, ok)
+		assert.Equal(t, tt.want, got, "balance %d", tt.balance)
+	}
+
+	_, ok := gu.StakeRoomType(1000, 100, nil)
+	assert.False(t, ok)
+}
+
+func TestMatchmaker_QuickJoinCreatesRoomsUpToMax(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 1, 2, 0.75)
+	ctx := context.Background()
+
+	mm.scale(ctx)
+	require.Len(t, gu.RoomStats(game.RoomTypeNovice), 1)
+
+	joined := make(map[string]int)
+	for i := int64(1); i <= 8; i++ {
+		roomID, roomType, err := mm.QuickJoin(ctx, QuickJoinRequest{Balance: 10000}, guestJoiner(gu, -i))
+		require.NoError(t, err)
+		assert.Equal(t, game.RoomTypeNovice, roomType)
+		joined[roomID]++
+	}
+	assert.Len(t, joined, 2)
+	for roomID, n := range joined {
+		assert.Equal(t, 4, n, "room %s", roomID)
+	}
+
+	_, _, err := mm.QuickJoin(ctx, QuickJoinRequest{Balance: 10000}, guestJoiner(gu, -9))
+	assert.ErrorIs(t, err, game.ErrNoRoomAvailable)
+	assert.Equal(t, "NO_ROOM_AVAILABLE", errorCodeOf(err).String())
+}
+
+func TestMatchmaker_QuickJoinRetriesWhenRoomFills(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 2, 2, 0.75)
+	ctx := context.Background()
+	mm.scale(ctx)
+
+	attempts := 0
+	join := func(roomID string) error {
+		attempts++
+		if attempts == 1 {
+			return game.ErrRoomFull
+		}
+		return guestJoiner(gu, -1)(roomID)
+	}
+	roomID, _, err := mm.QuickJoin(ctx, QuickJoinRequest{Balance: 10000}, join)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NotEmpty(t, roomID)
+}
+
+func TestMatchmaker_RejectsUnaffordableRoomType(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 1, 1, 0.75)
+
+	_, _, err := mm.QuickJoin(context.Background(), QuickJoinRequest{RoomType: game.RoomTypeNovice, Balance: 5}, guestJoiner(gu, -1))
+	assert.ErrorIs(t, err, game.ErrInsufficientBalance)
+
+	_, _, err = mm.QuickJoin(context.Background(), QuickJoinRequest{RoomType: game.RoomTypeVIP, Balance: 1000000}, guestJoiner(gu, -1))
+	assert.ErrorIs(t, err, game.ErrNoRoomAvailable)
+}
+
+func TestMatchmaker_ChecksRoomCurrencyBalance(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	coinConfig := noviceConfigVersion(2, 5)
+	coinConfig.Config.Currency = wallet.CurrencyCoin
+	_, _, err := gu.ApplyRoomConfig(coinConfig)
+	require.NoError(t, err)
+	mm := newTestMatchmaker(gu, 1, 1, 0.75)
+	ctx := context.Background()
+
+	rich := map[string]int64{wallet.CurrencyCNY: 1000000}
+	_, _, err = mm.QuickJoin(ctx, QuickJoinRequest{RoomType: game.RoomTypeNovice, Balance: 1000000, Balances: rich}, guestJoiner(gu, -1))
+	assert.ErrorIs(t, err, game.ErrInsufficientBalance, "CNY balance does not count in a COIN room")
+
+	// 自動選擇只考慮主幣種房間
+	_, _, err = mm.QuickJoin(ctx, QuickJoinRequest{Balance: 1000000, Balances: rich}, guestJoiner(gu, -1))
+	assert.ErrorIs(t, err, game.ErrNoRoomAvailable)
+
+	roomID, roomType, err := mm.QuickJoin(ctx, QuickJoinRequest{
+		RoomType: game.RoomTypeNovice,
+		Balances: map[string]int64{wallet.CurrencyCoin: 10000},
+	}, guestJoiner(gu, -1))
+	require.NoError(t, err)
+	assert.Equal(t, game.RoomTypeNovice, roomType)
+	assert.NotEmpty(t, roomID)
+}
+
+func TestMatchmaker_ScaleUpAtTargetFill(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 1, 3, 0.5)
+	ctx := context.Background()
+
+	mm.scale(ctx)
+	rooms := gu.RoomStats(game.RoomTypeNovice)
+	require.Len(t, rooms, 1)
+
+	require.NoError(t, guestJoiner(gu, -1)(rooms[0].ID))
+	mm.scale(ctx)
+	assert.Len(t, gu.RoomStats(game.RoomTypeNovice), 1, "fill 0.25 is below target")
+
+	require.NoError(t, guestJoiner(gu, -2)(rooms[0].ID))
+	mm.scale(ctx)
+	assert.Len(t, gu.RoomStats(game.RoomTypeNovice), 2, "fill 0.5 reached target")
+
+	// 新房間是空的，不再繼續擴容
+	mm.scale(ctx)
+	assert.Len(t, gu.RoomStats(game.RoomTypeNovice), 2)
+}
+
+func TestMatchmaker_ScaleDownIdleRooms(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 1, 4, 0.75)
+	mm.idleTimeout = 0
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+		require.NoError(t, err)
+	}
+	rooms := gu.RoomStats(game.RoomTypeNovice)
+	require.Len(t, rooms, 3)
+	require.NoError(t, guestJoiner(gu, -1)(rooms[0].ID))
+
+	// 第一次只記錄空閒開始時間
+	mm.scale(ctx)
+	assert.Len(t, gu.RoomStats(game.RoomTypeNovice), 3)
+
+	mm.scale(ctx)
+	remaining := gu.RoomStats(game.RoomTypeNovice)
+	require.Len(t, remaining, 1, "idle rooms above min_count are closed")
+	assert.Equal(t, rooms[0].ID, remaining[0].ID, "occupied room is kept")
+
+	_, err := gu.GetRoom(ctx, rooms[1].ID)
+	assert.ErrorIs(t, err, game.ErrRoomNotFound)
+}
+
+func TestMatchmaker_StopsScalingAfterStop(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 2, 4, 0.75)
+
+	mm.Stop()
+	mm.scale(context.Background())
+	assert.Empty(t, gu.RoomStats(game.RoomTypeNovice))
+}
+
+func TestRoomManager_UniqueRoomIDs(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+
+	ids := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		room, err := gu.CreateRoom(context.Background(), game.RoomTypeNovice, 4)
+		require.NoError(t, err)
+		ids[room.ID] = true
+	}
+	assert.Len(t, ids, 5)
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/b7777777v/fish_server/internal/biz/game"
@@ -55,6 +56,10 @@ func (mh *MessageHandler) HandleMessage(client *Client, message *pb.GameMessage)
 		mh.handleSwitchCannon(client, message)
 	case pb.MessageType_JOIN_ROOM:
 		mh.handleJoinRoom(client, message)
+	case pb.MessageType_QUICK_JOIN:
+		mh.handleQuickJoin(client, message)
+	case pb.MessageType_WATCH_ROOM:
+		mh.handleWatchRoom(client, message)
 	case pb.MessageType_LEAVE_ROOM:
 		mh.handleLeaveRoom(client, message)
 	case pb.MessageType_HIT_FISH:
@@ -65,29 +70,48 @@ func (mh *MessageHandler) HandleMessage(client *Client, message *pb.GameMessage)
 		mh.handleGetRoomList(client, message)
 	case pb.MessageType_GET_PLAYER_INFO:
 		mh.handleGetPlayerInfo(client, message)
+	case pb.MessageType_HELLO:
+		mh.handleHello(client, message)
+	case pb.MessageType_CREATE_PRIVATE_ROOM:
+		mh.handleCreatePrivateRoom(client, message)
+	case pb.MessageType_KICK_PLAYER:
+		mh.handleKickPlayer(client, message)
+	case pb.MessageType_LOCK_ROOM:
+		mh.handleLockRoom(client, message)
+	case pb.MessageType_SELECT_SEAT:
+		mh.handleSelectSeat(client, message)
+	case pb.MessageType_SWAP_SEAT:
+		mh.handleSwapSeat(client, message)
+	case pb.MessageType_RESPOND_SEAT_SWAP:
+		mh.handleRespondSeatSwap(client, message)
+	case pb.MessageType_CHAT:
+		mh.handleChat(client, message)
 	default:
 		mh.logger.Warnf("Unknown message type: %v from client: %s", message.Type, client.ID)
-		mh.sendErrorResponse(client, "Unknown message type")
+		mh.sendErrorResponse(client, pb.ErrorCode_UNSUPPORTED_MESSAGE, "Unknown message type")
 	}
 }
 
 // handleFireBullet 處理開火消息
 func (mh *MessageHandler) handleFireBullet(client *Client, message *pb.GameMessage) {
 	if client.RoomID == "" {
-		mh.sendErrorResponse(client, "Not in any room")
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if mh.rejectSpectator(client) {
 		return
 	}
 	
 	// 解析開火數據
 	fireData := message.GetFireBullet()
 	if fireData == nil {
-		mh.sendErrorResponse(client, "Invalid fire bullet data")
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid fire bullet data")
 		return
 	}
 	
 	// 驗證參數
 	if fireData.Power < 1 || fireData.Power > 100 {
-		mh.sendErrorResponse(client, "Invalid bullet power")
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_BULLET_POWER, "Invalid bullet power")
 		return
 	}
 	
@@ -110,7 +134,7 @@ func (mh *MessageHandler) handleFireBullet(client *Client, message *pb.GameMessa
 		fireData.Direction, fireData.Power, position, targetFishID)
 	if err != nil {
 		mh.logger.Errorf("Failed to fire bullet: %v", err)
-		mh.sendErrorResponse(client, "Failed to fire bullet")
+		mh.sendBizErrorResponse(client, err, "Failed to fire bullet")
 		return
 	}
 	
@@ -128,6 +152,8 @@ func (mh *MessageHandler) handleFireBullet(client *Client, message *pb.GameMessa
 		},
 	}
 	
+	client.guest.recordShot(bullet.Cost)
+
 	// 發送響應給客戶端
 	client.sendProtobuf(response)
 	
@@ -162,30 +188,37 @@ func (mh *MessageHandler) handleFireBullet(client *Client, message *pb.GameMessa
 // handleSwitchCannon 處理切換砲台消息
 func (mh *MessageHandler) handleSwitchCannon(client *Client, message *pb.GameMessage) {
 	if client.RoomID == "" {
-		mh.sendErrorResponse(client, "Not in any room")
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if mh.rejectSpectator(client) {
 		return
 	}
 	
 	// 解析砲台數據
 	cannonData := message.GetSwitchCannon()
 	if cannonData == nil {
-		mh.sendErrorResponse(client, "Invalid cannon data")
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid cannon data")
 		return
 	}
 	
 	// 驗證砲台類型和等級
 	if cannonData.CannonType < 1 || cannonData.CannonType > 10 {
-		mh.sendErrorResponse(client, "Invalid cannon type")
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_CANNON, "Invalid cannon type")
 		return
 	}
 	
 	if cannonData.Level < 1 || cannonData.Level > 10 {
-		mh.sendErrorResponse(client, "Invalid cannon level")
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_CANNON, "Invalid cannon level")
 		return
 	}
 	
 	// 計算砲台威力
 	power := cannonData.Level * 10
+
+	// 開火限流速率跟隨所選砲台
+	client.rateLimit.SetCannon(cannonData.CannonType)
+	mh.gameUsecase.TouchPlayer(client.RoomID, client.PlayerID)
 	
 	// 構建響應消息
 	response := &pb.GameMessage{
@@ -228,43 +261,170 @@ func (mh *MessageHandler) handleSwitchCannon(client *Client, message *pb.GameMes
 func (mh *MessageHandler) handleJoinRoom(client *Client, message *pb.GameMessage) {
     joinData := message.GetJoinRoom()
     if joinData == nil {
-        mh.sendErrorResponse(client, "Invalid join room data")
+        mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid join room data")
         return
     }
     
     roomID := joinData.RoomId
-    if roomID == "" {
-        mh.sendErrorResponse(client, "Room ID is required")
+    if roomID == "" && joinData.InviteCode == "" {
+        mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Room ID is required")
         return
     }
+
+    // 下線模式中不再接受加入房間
+    if mh.hub.Draining() {
+        mh.sendErrorResponse(client, pb.ErrorCode_NODE_DRAINING, "Server is draining, please reconnect to another server")
+        return
+    }
+
+    // 私人房間通過邀請碼和密碼驗證
+    if joinData.InviteCode != "" {
+        invitedRoomID, err := mh.gameUsecase.AuthorizeInvite(context.Background(), joinData.InviteCode, joinData.Password, client.PlayerID)
+        if err != nil {
+            mh.sendBizErrorResponse(client, err, "Failed to join private room")
+            return
+        }
+        if roomID != "" && roomID != invitedRoomID {
+            mh.sendErrorResponse(client, pb.ErrorCode_INVALID_INVITE_CODE, "Invite code does not match the room")
+            return
+        }
+        roomID = invitedRoomID
+    }
     
+    // 觀戰中只能在當前房間入座
+    if client.Spectating && client.RoomID != roomID {
+        mh.sendErrorResponse(client, pb.ErrorCode_ALREADY_IN_ROOM, "Leave the spectated room first")
+        return
+    }
+
+    if err := mh.joinRoom(context.Background(), client, roomID); err != nil {
+        mh.logger.Errorf("Failed to join room: %v", err)
+        mh.sendBizErrorResponse(client, err, "Failed to join room")
+        return
+    }
+    mh.sendJoinRoomResponse(client, roomID, "")
+}
+
+// handleQuickJoin 處理快速加入消息：由匹配器按房間類型、餘額、填充率和好友選擇或創建房間
+func (mh *MessageHandler) handleQuickJoin(client *Client, message *pb.GameMessage) {
+    quickJoin := message.GetQuickJoin()
+    if quickJoin == nil {
+        mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid quick join data")
+        return
+    }
+    if mh.hub.matchmaker == nil {
+        mh.sendErrorResponse(client, pb.ErrorCode_UNSUPPORTED_MESSAGE, "Quick join is not available")
+        return
+    }
+    if client.RoomID != "" {
+        mh.sendErrorResponse(client, pb.ErrorCode_ALREADY_IN_ROOM, "Already in a room")
+        return
+    }
+
+    // 下線模式中不再接受加入房間
+    if mh.hub.Draining() {
+        mh.sendErrorResponse(client, pb.ErrorCode_NODE_DRAINING, "Server is draining, please reconnect to another server")
+        return
+    }
+
+    req := QuickJoinRequest{
+        FriendIDs:     quickJoin.FriendIds,
+        PreferredFill: quickJoin.PreferredFill,
+    }
+    if quickJoin.RoomType != "" {
+        roomType, ok := parseRoomType(quickJoin.RoomType)
+        if !ok {
+            mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Unknown room type")
+            return
+        }
+        req.RoomType = roomType
+    }
+
     ctx := context.Background()
 
-    // 處理加入房間的業務邏輯
+    // 按餘額選擇合適的房間類型
+    if client.IsGuest && client.GuestPlayer != nil {
+        req.Balance = client.GuestPlayer.Balance
+    } else if client.PlayerID != 0 {
+        player, err := mh.gameUsecase.GetPlayerInfo(ctx, client.PlayerID)
+        if err != nil {
+            mh.logger.Errorf("Failed to get player info for quick join: %v", err)
+            mh.sendBizErrorResponse(client, err, "Failed to get player info")
+            return
+        }
+        req.Balance = player.Balance
+        balances, err := mh.gameUsecase.PlayerBalances(ctx, client.PlayerID)
+        if err != nil {
+            mh.logger.Errorf("Failed to get wallet balances for quick join: %v", err)
+            mh.sendBizErrorResponse(client, err, "Failed to get player info")
+            return
+        }
+        req.Balances = balances
+    }
+
+    roomID, roomType, err := mh.hub.matchmaker.QuickJoin(ctx, req, func(roomID string) error {
+        return mh.joinRoom(ctx, client, roomID)
+    })
+    if err != nil {
+        mh.logger.Warnf("Quick join failed for player %d: %v", client.PlayerID, err)
+        mh.sendBizErrorResponse(client, err, "Failed to quick join")
+        return
+    }
+    mh.sendJoinRoomResponse(client, roomID, roomType)
+}
+
+// joinRoom 玩家加入指定房間並註冊到 Hub 的房間分組
+func (mh *MessageHandler) joinRoom(ctx context.Context, client *Client, roomID string) error {
+    // 客服觀戰連接只能觀戰
+    if client.supportRoomID != "" {
+        return fmt.Errorf("%w: support connection cannot take a seat", game.ErrPlayerSpectating)
+    }
+
     if client.IsGuest {
         // 遊客使用虛擬 Player 對象加入房間
         if client.GuestPlayer == nil {
             mh.logger.Errorf("Guest player object is nil for client %s", client.ID)
-            mh.sendErrorResponse(client, "Guest player data error")
-            return
+            return fmt.Errorf("guest player data missing for client %s", client.ID)
         }
         if err := mh.gameUsecase.JoinRoomWithPlayer(ctx, roomID, client.GuestPlayer); err != nil {
-            mh.logger.Errorf("Failed to join room (guest): %v", err)
-            mh.sendErrorResponse(client, "Failed to join room")
-            return
+            return err
         }
     } else if client.PlayerID != 0 {
+        if err := mh.checkRoomRestriction(ctx, client, roomID); err != nil {
+            return err
+        }
         // 正式玩家通過 PlayerID 加入房間
         if err := mh.gameUsecase.JoinRoom(ctx, roomID, client.PlayerID); err != nil {
-            mh.logger.Errorf("Failed to join room: %v", err)
-            mh.sendErrorResponse(client, "Failed to join room")
-            return
+            return err
         }
     }
-    
+
+    // 觀戰者在當前房間入座
+    client.Spectating = false
     client.RoomID = roomID
     mh.hub.joinRoom <- &JoinRoomMessage{Client: client, RoomID: roomID}
-    
+    if client.IsGuest {
+        client.guest.startRound(roomID, client.GuestPlayer.Balance)
+    }
+    return nil
+}
+
+// checkRoomRestriction 玩家被限制進入該類型的房間時返回 account.ErrRoomTypeRestricted；房間不存在時由 JoinRoom 報錯
+func (mh *MessageHandler) checkRoomRestriction(ctx context.Context, client *Client, roomID string) error {
+    if mh.hub == nil || mh.hub.sanctions == nil || client.accountID <= 0 {
+        return nil
+    }
+    room, err := mh.gameUsecase.GetRoomState(ctx, roomID)
+    if err != nil || room == nil {
+        return nil
+    }
+    return mh.hub.sanctions.CheckRoomType(ctx, client.accountID, string(room.Type))
+}
+
+// sendJoinRoomResponse 發送加入房間響應並開始推送房間狀態
+func (mh *MessageHandler) sendJoinRoomResponse(client *Client, roomID string, roomType game.RoomType) {
+    ctx := context.Background()
+
     room, err := mh.gameUsecase.GetRoom(ctx, roomID)
     if err != nil {
         mh.logger.Errorf("Failed to get room info after join: %v", err)
@@ -272,6 +432,7 @@ func (mh *MessageHandler) handleJoinRoom(client *Client, message *pb.GameMessage
     playerCount := int32(1)
     if room != nil {
         playerCount = int32(len(room.Players))
+        roomType = room.Type
     } else {
         mh.hub.mu.RLock()
         if clients, ok := mh.hub.rooms[roomID]; ok {
@@ -288,9 +449,17 @@ func (mh *MessageHandler) handleJoinRoom(client *Client, message *pb.GameMessage
                 RoomId:      roomID,
                 Timestamp:   time.Now().Unix(),
                 PlayerCount: playerCount,
+                RoomType:    string(roomType),
             },
         },
     }
+    if private, ok := mh.gameUsecase.PrivateRoomInfo(roomID); ok {
+        joinResp := response.GetJoinRoomResponse()
+        joinResp.Private = true
+        joinResp.InviteCode = private.InviteCode
+        joinResp.OwnerId = private.OwnerID
+        joinResp.Locked = private.Locked
+    }
     client.sendProtobuf(response)
     
     go mh.broadcastRoomState(roomID)
@@ -305,20 +474,29 @@ func (mh *MessageHandler) handleJoinRoom(client *Client, message *pb.GameMessage
 // handleLeaveRoom 處理離開房間消息
 func (mh *MessageHandler) handleLeaveRoom(client *Client, message *pb.GameMessage) {
 	if client.RoomID == "" {
-		mh.sendErrorResponse(client, "Not in any room")
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
 		return
 	}
 	
 	roomID := client.RoomID
 	
-	// 調用業務邏輯
+	// 調用業務邏輯（觀戰者沒有遊戲記錄和子彈需要結算）
 	ctx := context.Background()
-	err := mh.gameUsecase.LeaveRoom(ctx, roomID, client.PlayerID)
+	var err error
+	spectating := client.Spectating
+	if spectating {
+		err = mh.gameUsecase.StopWatching(ctx, roomID, client.PlayerID)
+	} else {
+		err = mh.hub.leavePlayer(ctx, roomID, client.PlayerID)
+	}
 	if err != nil {
 		mh.logger.Errorf("Failed to leave room: %v", err)
-		mh.sendErrorResponse(client, "Failed to leave room")
+		mh.sendBizErrorResponse(client, err, "Failed to leave room")
 		return
 	}
+	if !spectating && client.IsGuest {
+		client.guest.endRound(client.GuestPlayer.Balance)
+	}
 	
 	// 通知 Hub
 	mh.hub.leaveRoom <- &LeaveRoomMessage{
@@ -349,20 +527,23 @@ func (mh *MessageHandler) handleLeaveRoom(client *Client, message *pb.GameMessag
 // handleHitFish 處理擊中魚類消息
 func (mh *MessageHandler) handleHitFish(client *Client, message *pb.GameMessage) {
 	if client.RoomID == "" {
-		mh.sendErrorResponse(client, "Not in any room")
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if mh.rejectSpectator(client) {
 		return
 	}
 
 	// 解析擊中數據
 	hitData := message.GetHitFish()
 	if hitData == nil {
-		mh.sendErrorResponse(client, "Invalid hit fish data")
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid hit fish data")
 		return
 	}
 
 	// 驗證參數
 	if hitData.GetBulletId() <= 0 || hitData.GetFishId() <= 0 {
-		mh.sendErrorResponse(client, "Invalid bullet or fish ID")
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Invalid bullet or fish ID")
 		return
 	}
 
@@ -371,7 +552,7 @@ func (mh *MessageHandler) handleHitFish(client *Client, message *pb.GameMessage)
 	hitResult, err := mh.gameUsecase.HitFish(ctx, client.RoomID, hitData.GetBulletId(), hitData.GetFishId())
 	if err != nil {
 		mh.logger.Errorf("Failed to process hit fish: %v", err)
-		mh.sendErrorResponse(client, "Failed to process hit")
+		mh.sendBizErrorResponse(client, err, "Failed to process hit")
 		return
 	}
 
@@ -389,6 +570,8 @@ func (mh *MessageHandler) handleHitFish(client *Client, message *pb.GameMessage)
 				IsCritical: hitResult.IsCritical,
 				Multiplier: hitResult.Multiplier,
 				Timestamp:  time.Now().Unix(),
+				EventId:    hitResult.EventID,
+				EventBonus: hitResult.EventBonus,
 			},
 		},
 	}
@@ -398,6 +581,8 @@ func (mh *MessageHandler) handleHitFish(client *Client, message *pb.GameMessage)
 
 	// 如果擊殺了魚，廣播給房間所有玩家
 	if hitResult.Reward > 0 {
+		client.guest.recordCatch(hitResult.Reward)
+
 		// 廣播魚死亡事件
 		fishDiedMsg := &pb.GameMessage{
 			Type: pb.MessageType_FISH_DIED,
@@ -436,6 +621,28 @@ func (mh *MessageHandler) handleHitFish(client *Client, message *pb.GameMessage)
 	}
 }
 
+// handleHello 處理握手消息：檢查協議版本，協商能力後回覆 WELCOME
+func (mh *MessageHandler) handleHello(client *Client, message *pb.GameMessage) {
+	hello := message.GetHello()
+	if hello == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid hello data")
+		return
+	}
+
+	if err := checkProtocolVersion(hello.ProtocolVersion); err != nil {
+		mh.logger.Warnf("Client %s handshake rejected: %v (client_version=%s, platform=%s)",
+			client.ID, err, hello.ClientVersion, hello.Platform)
+		client.closeWithError(pb.ErrorCode_PROTOCOL_VERSION_UNSUPPORTED, err.Error())
+		return
+	}
+
+	accepted := client.applyHandshake(hello.ProtocolVersion, hello.Capabilities, hello.ClientVersion, hello.Platform)
+	mh.logger.Infof("Client %s handshake: protocol=%d, capabilities=%v, accepted=%v, client_version=%s, platform=%s",
+		client.ID, hello.ProtocolVersion, hello.Capabilities, accepted, hello.ClientVersion, hello.Platform)
+
+	client.sendProtobuf(client.welcomeMessage())
+}
+
 // handleHeartbeat 處理心跳消息
 func (mh *MessageHandler) handleHeartbeat(client *Client, message *pb.GameMessage) {
 	response := &pb.GameMessage{
@@ -457,20 +664,25 @@ func (mh *MessageHandler) handleGetRoomList(client *Client, message *pb.GameMess
 	rooms, err := mh.gameUsecase.GetRoomList(ctx, "")
 	if err != nil {
 		mh.logger.Errorf("Failed to get room list: %v", err)
-		mh.sendErrorResponse(client, "Failed to get room list")
+		mh.sendBizErrorResponse(client, err, "Failed to get room list")
 		return
 	}
 	
 	// 轉換房間數據到 Protobuf 格式
 	var pbRooms []*pb.RoomInfo
 	for _, room := range rooms {
+		// 私人房間只能通過邀請碼加入，不出現在公開列表中
+		if room.Private != nil {
+			continue
+		}
 		pbRoom := &pb.RoomInfo{
 			RoomId:      room.ID,
 			Name:        room.Name,
 			Type:        string(room.Type),
-			PlayerCount: int32(len(room.Players)),
-			MaxPlayers:  room.MaxPlayers,
-			Status:      string(room.Status),
+			PlayerCount:   int32(len(room.Players)),
+			MaxPlayers:    room.MaxPlayers,
+			Status:        string(room.Status),
+			ConfigVersion: room.Config.Version,
 		}
 		pbRooms = append(pbRooms, pbRoom)
 	}
@@ -525,7 +737,7 @@ func (mh *MessageHandler) handleGetPlayerInfo(client *Client, message *pb.GameMe
         player, err := mh.gameUsecase.GetPlayerInfo(ctx, client.PlayerID)
         if err != nil {
             mh.logger.Errorf("Failed to get player info: %v", err)
-            mh.sendErrorResponse(client, "Failed to get player info")
+            mh.sendBizErrorResponse(client, err, "Failed to get player info")
             return
         }
         nickname = player.Nickname
@@ -557,19 +769,13 @@ func (mh *MessageHandler) handleGetPlayerInfo(client *Client, message *pb.GameMe
 }
 
 // sendErrorResponse 發送錯誤響應
-func (mh *MessageHandler) sendErrorResponse(client *Client, errorMsg string) {
-	response := &pb.GameMessage{
-		Type: pb.MessageType_ERROR,
-		Data: &pb.GameMessage_Error{
-			Error: &pb.ErrorMessage{
-				Message:   errorMsg,
-				Code:      "GENERAL_ERROR",
-				Timestamp: time.Now().Unix(),
-			},
-		},
-	}
-	
-	client.sendProtobuf(response)
+func (mh *MessageHandler) sendErrorResponse(client *Client, code pb.ErrorCode, errorMsg string) {
+	client.sendErrorPB(code, errorMsg)
+}
+
+// sendBizErrorResponse 將業務層錯誤映射為錯誤碼後發送錯誤響應
+func (mh *MessageHandler) sendBizErrorResponse(client *Client, err error, errorMsg string) {
+	client.sendBizError(err, errorMsg)
 }
 
 // broadcastToRoom 向房間廣播 Protobuf 消息
@@ -708,13 +914,16 @@ func (mh *MessageHandler) broadcastRoomState(roomID string) {
 		Type: pb.MessageType_ROOM_STATE_UPDATE,
 		Data: &pb.GameMessage_RoomStateUpdate{
 			RoomStateUpdate: &pb.RoomStateUpdate{
-				RoomId:      roomID,
-				Fishes:      fishInfos,
-				Bullets:     bulletInfos,
-				Formations:  formationInfos,
-				PlayerCount: int32(len(room.Players)),
-				Timestamp:   time.Now().Unix(),
-				RoomStatus:  string(room.Status),
+				RoomId:         roomID,
+				Fishes:         fishInfos,
+				Bullets:        bulletInfos,
+				Formations:     formationInfos,
+				PlayerCount:    int32(len(room.Players)),
+				Timestamp:      time.Now().Unix(),
+				RoomStatus:     string(room.Status),
+				SpectatorCount: int32(room.SpectatorCount()),
+				ConfigVersion:  room.Config.Version,
+				Events:         roomEventInfos(mh.gameUsecase.ActiveRoomEvents(room.Type)),
 			},
 		},
 	}
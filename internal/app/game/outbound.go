@@ -0,0 +1,143 @@
+package game
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/conf"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ========================================
+// 出站消息：批量合併與壓縮
+// ========================================
+
+// tickFlush 房間 tick 結束標記
+// 經 Hub 廣播通道排在本 tick 的廣播之後進入批量模式客戶端的發送隊列，writePump 收到後立即送出已合併的消息；
+// 序列化後的 GameMessage 不會為空，因此以空消息表示
+var tickFlush = []byte{}
+
+// isTickFlush 是否為房間 tick 結束標記
+func isTickFlush(message []byte) bool {
+	return len(message) == 0
+}
+
+// outboundOptions 單個連接協商後的出站選項
+type outboundOptions struct {
+	batching     bool          // 連接時是否已以 batch=1 開啟批量模式
+	batchAllowed bool          // 伺服器是否允許批量模式（可由 HELLO 能力開啟）
+	batchWindow  time.Duration // tick 之外的消息最長合併時間，房間 tick 結束時提前送出
+	maxMessages  int           // 單批最大消息數
+	maxBytes     int           // 單批最大字節數
+
+	compression          bool // 是否已協商 permessage-deflate
+	compressionThreshold int  // 幀大小達到此值才壓縮
+}
+
+// newOutboundOptions 根據配置和握手請求決定連接的出站選項
+func newOutboundOptions(cfg *conf.GameWebSocket, r *http.Request) outboundOptions {
+	if cfg == nil {
+		return outboundOptions{}
+	}
+
+	opts := outboundOptions{
+		batchWindow:          time.Duration(cfg.Batching.WindowMs) * time.Millisecond,
+		maxMessages:          cfg.Batching.MaxMessages,
+		maxBytes:             cfg.Batching.MaxBytes,
+		compressionThreshold: cfg.Compression.Threshold,
+	}
+
+	// 批量模式需要客戶端能解析 MESSAGE_BATCH，因此由客戶端以 batch=1 主動開啟
+	opts.batchAllowed = cfg.Batching.Enable
+	if cfg.Batching.Enable {
+		switch strings.ToLower(r.URL.Query().Get("batch")) {
+		case "1", "true", "yes":
+			opts.batching = true
+		}
+	}
+
+	// permessage-deflate 由瀏覽器在握手時自動提出，伺服器啟用即可協商成功
+	if cfg.Compression.Enable {
+		for _, ext := range r.Header.Values("Sec-Websocket-Extensions") {
+			if strings.Contains(ext, "permessage-deflate") {
+				opts.compression = true
+				break
+			}
+		}
+	}
+
+	return opts
+}
+
+// encodeBatch 將多條已序列化的 GameMessage 直接拼接為 MESSAGE_BATCH 消息
+// 不需要反序列化：repeated 子消息的編碼就是逐條寫入 tag + 長度 + 原始字節
+func encodeBatch(messages [][]byte, timestamp int64) []byte {
+	size := 0
+	for _, m := range messages {
+		size += len(m) + 8
+	}
+
+	batch := make([]byte, 0, size+16)
+	for _, m := range messages {
+		batch = protowire.AppendTag(batch, 1, protowire.BytesType)
+		batch = protowire.AppendBytes(batch, m)
+	}
+	batch = protowire.AppendTag(batch, 2, protowire.VarintType)
+	batch = protowire.AppendVarint(batch, uint64(timestamp))
+
+	// GameMessage{type = MESSAGE_BATCH, batch = ...}
+	out := make([]byte, 0, len(batch)+16)
+	out = protowire.AppendTag(out, 1, protowire.VarintType)
+	out = protowire.AppendVarint(out, uint64(pb.MessageType_MESSAGE_BATCH))
+	out = protowire.AppendTag(out, 90, protowire.BytesType)
+	out = protowire.AppendBytes(out, batch)
+	return out
+}
+
+// ClientTrafficStats 單個連接的出站流量統計
+type ClientTrafficStats struct {
+	BytesSent        int64  `json:"bytes_sent"`        // 寫出的幀載荷字節數（壓縮前）
+	FramesSent       int64  `json:"frames_sent"`       // 寫出的 WebSocket 幀數
+	MessagesSent     int64  `json:"messages_sent"`     // 寫出的 GameMessage 數（批量內逐條計算）
+	BatchesSent      int64  `json:"batches_sent"`      // 其中 MESSAGE_BATCH 幀數
+	CompressedFrames int64  `json:"compressed_frames"` // 啟用壓縮的幀數
+	Batching         bool   `json:"batching"`
+	Compression      bool   `json:"compression"`
+	Codec            string `json:"codec"`
+}
+
+// clientTraffic 連接的流量計數器（writePump 寫入，Hub 讀取）
+type clientTraffic struct {
+	bytesSent        int64
+	framesSent       int64
+	messagesSent     int64
+	batchesSent      int64
+	compressedFrames int64
+}
+
+// record 記錄一次幀寫出
+func (t *clientTraffic) record(frameBytes, messages int, batch, compressed bool) {
+	atomic.AddInt64(&t.bytesSent, int64(frameBytes))
+	atomic.AddInt64(&t.framesSent, 1)
+	atomic.AddInt64(&t.messagesSent, int64(messages))
+	if batch {
+		atomic.AddInt64(&t.batchesSent, 1)
+	}
+	if compressed {
+		atomic.AddInt64(&t.compressedFrames, 1)
+	}
+}
+
+// snapshot 讀取當前計數
+func (t *clientTraffic) snapshot() ClientTrafficStats {
+	return ClientTrafficStats{
+		BytesSent:        atomic.LoadInt64(&t.bytesSent),
+		FramesSent:       atomic.LoadInt64(&t.framesSent),
+		MessagesSent:     atomic.LoadInt64(&t.messagesSent),
+		BatchesSent:      atomic.LoadInt64(&t.batchesSent),
+		CompressedFrames: atomic.LoadInt64(&t.compressedFrames),
+	}
+}
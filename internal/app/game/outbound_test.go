@@ -0,0 +1,204 @@
+package game
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEncodeBatch_RoundTrip(t *testing.T) {
+	var raw [][]byte
+	for i := int64(1); i <= 3; i++ {
+		msg := &pb.GameMessage{
+			Type: pb.MessageType_FISH_DIED,
+			Data: &pb.GameMessage_FishDied{FishDied: &pb.FishDiedEvent{FishId: i, Reward: i * 100}},
+		}
+		b, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		raw = append(raw, b)
+	}
+
+	var decoded pb.GameMessage
+	require.NoError(t, proto.Unmarshal(encodeBatch(raw, 12345), &decoded))
+
+	assert.Equal(t, pb.MessageType_MESSAGE_BATCH, decoded.Type)
+	batch := decoded.GetBatch()
+	require.NotNil(t, batch)
+	assert.Equal(t, int64(12345), batch.Timestamp)
+	require.Len(t, batch.Messages, 3)
+	for i, m := range batch.Messages {
+		assert.Equal(t, pb.MessageType_FISH_DIED, m.Type)
+		assert.Equal(t, int64(i+1), m.GetFishDied().FishId)
+	}
+}
+
+func TestNewOutboundOptions(t *testing.T) {
+	cfg := &conf.GameWebSocket{
+		Batching:    conf.WSBatching{Enable: true, WindowMs: 100, MaxMessages: 64, MaxBytes: 65536},
+		Compression: conf.WSCompression{Enable: true, Threshold: 1024, Level: 1},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/ws?batch=1", nil)
+	r.Header.Set("Sec-Websocket-Extensions", "permessage-deflate; client_max_window_bits")
+	opts := newOutboundOptions(cfg, r)
+	assert.True(t, opts.batching)
+	assert.True(t, opts.batchAllowed)
+	assert.True(t, opts.compression)
+	assert.Equal(t, 100*time.Millisecond, opts.batchWindow)
+
+	// 客戶端未協商時保持逐條發送、不壓縮
+	opts = newOutboundOptions(cfg, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	assert.False(t, opts.batching)
+	assert.False(t, opts.compression)
+
+	// 伺服器關閉批量模式時忽略客戶端請求
+	cfg.Batching.Enable = false
+	opts = newOutboundOptions(cfg, r)
+	assert.False(t, opts.batching)
+}
+
+func TestWritePump_BatchesWithinWindow(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	serverConn := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := websocket.Upgrader{EnableCompression: true}
+		conn, err := u.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConn <- conn
+	}))
+	defer srv.Close()
+
+	dialer := websocket.Dialer{EnableCompression: true}
+	clientConn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	client := NewClient(<-serverConn, nil, log)
+	client.ID = "batch_client"
+	client.outbound = outboundOptions{
+		batching:             true,
+		batchAllowed:         true,
+		batchWindow:          50 * time.Millisecond,
+		maxMessages:          64,
+		maxBytes:             65536,
+		compression:          true,
+		compressionThreshold: 16,
+	}
+	client.batching.Store(true)
+
+	// 同一窗口內排入三條消息
+	for i := 0; i < 3; i++ {
+		client.sendProtobuf(&pb.GameMessage{
+			Type: pb.MessageType_HEARTBEAT_RESPONSE,
+			Data: &pb.GameMessage_HeartbeatResponse{HeartbeatResponse: &pb.HeartbeatResponse{ServerTime: int64(i)}},
+		})
+	}
+	go client.writePump()
+
+	clientConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	msgType, data, err := clientConn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, msgType)
+
+	var decoded pb.GameMessage
+	require.NoError(t, proto.Unmarshal(data, &decoded))
+	require.Equal(t, pb.MessageType_MESSAGE_BATCH, decoded.Type)
+	assert.Len(t, decoded.GetBatch().Messages, 3)
+
+	stats := client.TrafficStats()
+	assert.Equal(t, int64(1), stats.FramesSent)
+	assert.Equal(t, int64(3), stats.MessagesSent)
+	assert.Equal(t, int64(1), stats.BatchesSent)
+	assert.Equal(t, int64(1), stats.CompressedFrames)
+	assert.Equal(t, int64(len(data)), stats.BytesSent)
+
+	close(client.send)
+}
+
+func TestWritePump_FlushesAtRoomTick(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	serverConn := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConn <- conn
+	}))
+	defer srv.Close()
+
+	clientConn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	hub := &Hub{rooms: make(map[string]map[*Client]bool)}
+	client := NewClient(<-serverConn, hub, log)
+	client.ID = "tick_client"
+	// 合併窗口遠大於 tick，幀只能由 tick 結束標記觸發
+	client.outbound = outboundOptions{batching: true, batchAllowed: true, batchWindow: time.Minute, maxMessages: 64, maxBytes: 65536}
+	client.batching.Store(true)
+	legacy := NewClient(nil, hub, log)
+	hub.rooms["room_1"] = map[*Client]bool{client: true, legacy: true}
+
+	for i := 0; i < 2; i++ {
+		client.sendProtobuf(&pb.GameMessage{
+			Type: pb.MessageType_HEARTBEAT_RESPONSE,
+			Data: &pb.GameMessage_HeartbeatResponse{HeartbeatResponse: &pb.HeartbeatResponse{ServerTime: int64(i)}},
+		})
+	}
+	hub.flushRoomTick("room_1")
+	assert.Empty(t, legacy.send, "clients without batching do not receive tick markers")
+	go client.writePump()
+
+	clientConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := clientConn.ReadMessage()
+	require.NoError(t, err)
+	var decoded pb.GameMessage
+	require.NoError(t, proto.Unmarshal(data, &decoded))
+	require.Equal(t, pb.MessageType_MESSAGE_BATCH, decoded.Type)
+	assert.Len(t, decoded.GetBatch().Messages, 2)
+	assert.Equal(t, int64(2), client.TrafficStats().MessagesSent, "the tick marker is not written to the client")
+
+	close(client.send)
+}
+
+func TestWritePump_FlushesBeforeCloseAfterFlush(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	serverConn := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConn <- conn
+	}))
+	defer srv.Close()
+
+	clientConn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	client := NewClient(<-serverConn, nil, log)
+	client.ID = "flooding_client"
+	go client.writePump()
+
+	client.sendErrorPB(pb.ErrorCode_TEMPORARILY_BANNED, "banned")
+	client.closeAfterFlush(websocket.ClosePolicyViolation, pb.ErrorCode_RATE_LIMITED.String())
+
+	clientConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := clientConn.ReadMessage()
+	require.NoError(t, err, "queued error is written before the close frame")
+	var decoded pb.GameMessage
+	require.NoError(t, proto.Unmarshal(data, &decoded))
+	assert.Equal(t, pb.ErrorCode_TEMPORARILY_BANNED, decoded.GetError().GetErrorCode())
+
+	_, _, err = clientConn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+)
+
+// ========================================
+// 私人房間
+// ========================================
+//
+// 玩家通過 CREATE_PRIVATE_ROOM 創建私人房間並自動入座，其他玩家使用 JOIN_ROOM 的
+// invite_code（和 password）加入。房主可以 KICK_PLAYER 和 LOCK_ROOM，
+// 狀態變更以 PRIVATE_ROOM_UPDATE 廣播給房間內所有人。
+
+// maxRoomPasswordLength 私人房間密碼的最大長度
+const maxRoomPasswordLength = 32
+
+// handleCreatePrivateRoom 處理創建私人房間消息：創建後房主自動入座
+func (mh *MessageHandler) handleCreatePrivateRoom(client *Client, message *pb.GameMessage) {
+	createData := message.GetCreatePrivateRoom()
+	if createData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid create private room data")
+		return
+	}
+	if mh.hub.matchmaker == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_UNSUPPORTED_MESSAGE, "Private rooms are not available")
+		return
+	}
+	if client.RoomID != "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_ALREADY_IN_ROOM, "Already in a room")
+		return
+	}
+	if client.supportRoomID != "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_SPECTATOR_ACTION_FORBIDDEN, "Support connections cannot create rooms")
+		return
+	}
+
+	roomType, ok := parseRoomType(createData.RoomType)
+	if !ok {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Unknown room type")
+		return
+	}
+	if len(createData.Password) > maxRoomPasswordLength {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Room password is too long")
+		return
+	}
+
+	// 下線模式中不再創建房間
+	if mh.hub.Draining() {
+		mh.sendErrorResponse(client, pb.ErrorCode_NODE_DRAINING, "Server is draining, please reconnect to another server")
+		return
+	}
+
+	ctx := context.Background()
+	room, err := mh.hub.matchmaker.CreatePrivateRoom(ctx, roomType, client.PlayerID, createData.Password)
+	if err != nil {
+		mh.sendBizErrorResponse(client, err, "Failed to create private room")
+		return
+	}
+
+	if err := mh.joinRoom(ctx, client, room.ID); err != nil {
+		mh.logger.Warnf("Owner %d failed to join private room %s: %v", client.PlayerID, room.ID, err)
+		if closeErr := mh.gameUsecase.CloseIdleRoom(ctx, room.ID); closeErr != nil {
+			mh.logger.Errorf("Failed to close private room %s: %v", room.ID, closeErr)
+		}
+		mh.sendBizErrorResponse(client, err, "Failed to join private room")
+		return
+	}
+	mh.sendJoinRoomResponse(client, room.ID, room.Type)
+
+	mh.logger.Infof("Player %d created private room %s", client.PlayerID, room.ID)
+}
+
+// handleKickPlayer 處理房主踢人消息
+func (mh *MessageHandler) handleKickPlayer(client *Client, message *pb.GameMessage) {
+	kickData := message.GetKickPlayer()
+	if kickData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid kick player data")
+		return
+	}
+	if client.RoomID == "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if kickData.PlayerId == 0 {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Player ID is required")
+		return
+	}
+
+	ctx := context.Background()
+	roomID := client.RoomID
+	seated, err := mh.gameUsecase.KickPlayer(ctx, roomID, client.PlayerID, kickData.PlayerId)
+	if err != nil {
+		mh.sendBizErrorResponse(client, err, "Failed to kick player")
+		return
+	}
+
+	// 已入座的玩家按正常流程離開，結算遊戲記錄
+	if seated {
+		if err := mh.hub.leavePlayer(ctx, roomID, kickData.PlayerId); err != nil {
+			mh.logger.Errorf("Failed to remove kicked player %d from room %s: %v", kickData.PlayerId, roomID, err)
+		}
+	}
+
+	update := mh.privateRoomUpdate(roomID, kickData.PlayerId)
+	target := mh.hub.roomClient(roomID, kickData.PlayerId)
+	if target != nil {
+		// 先通知被踢出的玩家，再把它移出房間分組
+		target.sendProtobuf(update)
+		target.RoomID = ""
+		mh.hub.leaveRoom <- &LeaveRoomMessage{Client: target, RoomID: roomID}
+	}
+	mh.broadcastToRoom(roomID, update, target)
+
+	mh.logger.Infof("Owner %d kicked player %d from room %s", client.PlayerID, kickData.PlayerId, roomID)
+}
+
+// handleLockRoom 處理房主鎖定房間消息
+func (mh *MessageHandler) handleLockRoom(client *Client, message *pb.GameMessage) {
+	lockData := message.GetLockRoom()
+	if lockData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid lock room data")
+		return
+	}
+	if client.RoomID == "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+
+	roomID := client.RoomID
+	if err := mh.gameUsecase.SetRoomLocked(context.Background(), roomID, client.PlayerID, lockData.Locked); err != nil {
+		mh.sendBizErrorResponse(client, err, "Failed to lock room")
+		return
+	}
+
+	mh.broadcastToRoom(roomID, mh.privateRoomUpdate(roomID, 0), nil)
+}
+
+// privateRoomUpdate 構建私人房間狀態變更消息
+func (mh *MessageHandler) privateRoomUpdate(roomID string, kickedPlayerID int64) *pb.GameMessage {
+	private, _ := mh.gameUsecase.PrivateRoomInfo(roomID)
+	return &pb.GameMessage{
+		Type: pb.MessageType_PRIVATE_ROOM_UPDATE,
+		Data: &pb.GameMessage_PrivateRoomUpdate{
+			PrivateRoomUpdate: &pb.PrivateRoomUpdate{
+				RoomId:         roomID,
+				OwnerId:        private.OwnerID,
+				Locked:         private.Locked,
+				KickedPlayerId: kickedPlayerID,
+				Timestamp:      time.Now().Unix(),
+			},
+		},
+	}
+}
@@ -0,0 +1,192 @@
+package game
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPrivateRoom_InviteCodeAndPassword(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 1, 2, 0.75)
+	ctx := context.Background()
+
+	room, err := mm.CreatePrivateRoom(ctx, game.RoomTypeNovice, 1, "secret")
+	require.NoError(t, err)
+	private, ok := gu.PrivateRoomInfo(room.ID)
+	require.True(t, ok)
+	assert.Len(t, private.InviteCode, 6)
+	assert.True(t, private.HasPassword)
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+
+	// 私人房間不參與快速加入
+	assert.Empty(t, gu.RoomStats(game.RoomTypeNovice))
+	roomID, _, err := mm.QuickJoin(ctx, QuickJoinRequest{RoomType: game.RoomTypeNovice, Balance: 10000}, guestJoiner(gu, 2))
+	require.NoError(t, err)
+	assert.NotEqual(t, room.ID, roomID)
+
+	// 沒有邀請碼不能加入
+	assert.ErrorIs(t, guestJoiner(gu, 3)(room.ID), game.ErrInvalidInviteCode)
+	assert.ErrorIs(t, gu.WatchRoom(ctx, room.ID, &game.Spectator{PlayerID: 3}), game.ErrInvalidInviteCode)
+
+	_, err = gu.AuthorizeInvite(ctx, "ZZZZZZ", "secret", 3)
+	assert.ErrorIs(t, err, game.ErrInvalidInviteCode)
+	_, err = gu.AuthorizeInvite(ctx, private.InviteCode, "wrong", 3)
+	assert.ErrorIs(t, err, game.ErrWrongRoomPassword)
+
+	// 邀請碼不區分大小寫
+	invitedRoomID, err := gu.AuthorizeInvite(ctx, " "+strings.ToLower(private.InviteCode)+" ", "secret", 3)
+	require.NoError(t, err)
+	assert.Equal(t, room.ID, invitedRoomID)
+	assert.NoError(t, guestJoiner(gu, 3)(room.ID))
+}
+
+func TestPrivateRoom_OwnerControls(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 1, 2, 0.75)
+	ctx := context.Background()
+
+	room, err := mm.CreatePrivateRoom(ctx, game.RoomTypeNovice, 1, "")
+	require.NoError(t, err)
+	private, _ := gu.PrivateRoomInfo(room.ID)
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+
+	_, err = gu.AuthorizeInvite(ctx, private.InviteCode, "", 2)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(gu, 2)(room.ID))
+
+	// 只有房主可以鎖定和踢人
+	assert.ErrorIs(t, gu.SetRoomLocked(ctx, room.ID, 2, true), game.ErrNotRoomOwner)
+	_, err = gu.KickPlayer(ctx, room.ID, 2, 1)
+	assert.ErrorIs(t, err, game.ErrNotRoomOwner)
+
+	// 鎖定後不再接受新玩家，房主仍可重新加入
+	require.NoError(t, gu.SetRoomLocked(ctx, room.ID, 1, true))
+	_, err = gu.AuthorizeInvite(ctx, private.InviteCode, "", 3)
+	assert.ErrorIs(t, err, game.ErrRoomLocked)
+	require.NoError(t, gu.LeaveRoom(ctx, room.ID, 1))
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+
+	// 被踢出的玩家解鎖後也不能再加入
+	seated, err := gu.KickPlayer(ctx, room.ID, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, seated)
+	require.NoError(t, gu.LeaveRoom(ctx, room.ID, 2))
+	require.NoError(t, gu.SetRoomLocked(ctx, room.ID, 1, false))
+	_, err = gu.AuthorizeInvite(ctx, private.InviteCode, "", 2)
+	assert.ErrorIs(t, err, game.ErrKickedFromRoom)
+	assert.ErrorIs(t, guestJoiner(gu, 2)(room.ID), game.ErrKickedFromRoom)
+}
+
+func TestPrivateRoom_LimitAndIdleTimeout(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	mm := newTestMatchmaker(gu, 1, 2, 0.75)
+	mm.privateIdleTimeout = time.Minute
+	ctx := context.Background()
+
+	room, err := mm.CreatePrivateRoom(ctx, game.RoomTypeNovice, 1, "")
+	require.NoError(t, err)
+	_, err = mm.CreatePrivateRoom(ctx, game.RoomTypeVIP, 1, "")
+	assert.ErrorIs(t, err, game.ErrPrivateRoomLimit)
+
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+	assert.Empty(t, gu.CloseIdlePrivateRooms(ctx, time.Now().Add(time.Hour)), "occupied rooms are kept")
+
+	require.NoError(t, gu.LeaveRoom(ctx, room.ID, 1))
+	assert.Empty(t, gu.CloseIdlePrivateRooms(ctx, time.Now()))
+	assert.Equal(t, []string{room.ID}, gu.CloseIdlePrivateRooms(ctx, time.Now().Add(time.Minute)))
+
+	_, err = gu.GetRoom(ctx, room.ID)
+	assert.ErrorIs(t, err, game.ErrRoomNotFound)
+
+	// 房間關閉後可以再次創建
+	_, err = mm.CreatePrivateRoom(ctx, game.RoomTypeVIP, 1, "")
+	assert.NoError(t, err)
+}
+
+func TestMessageHandler_PrivateRoomFlow(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	log := logger.New(os.Stdout, "error", "console")
+	hub := NewHub(gu, nil, nil, newTestMatchmaker(gu, 1, 2, 0.75), log)
+	mh := NewMessageHandler(gu, hub, log)
+
+	owner := NewClient(nil, hub, log)
+	owner.PlayerID = 1
+	owner.IsGuest = true
+	owner.GuestPlayer = &game.Player{ID: 1, Balance: 10000}
+	mh.HandleMessage(owner, &pb.GameMessage{
+		Type: pb.MessageType_CREATE_PRIVATE_ROOM,
+		Data: &pb.GameMessage_CreatePrivateRoom{CreatePrivateRoom: &pb.CreatePrivateRoomRequest{RoomType: "novice", Password: "pw"}},
+	})
+
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(<-owner.send, &msg))
+	require.Equal(t, pb.MessageType_JOIN_ROOM_RESPONSE, msg.Type)
+	joinResp := msg.GetJoinRoomResponse()
+	assert.True(t, joinResp.Private)
+	assert.Equal(t, int64(1), joinResp.OwnerId)
+	assert.NotEmpty(t, joinResp.InviteCode)
+	assert.Equal(t, joinResp.RoomId, owner.RoomID)
+	<-hub.joinRoom
+
+	// 公開房間列表不包含私人房間
+	mh.HandleMessage(owner, &pb.GameMessage{
+		Type: pb.MessageType_GET_ROOM_LIST,
+		Data: &pb.GameMessage_GetRoomList{GetRoomList: &pb.GetRoomListRequest{}},
+	})
+	require.NoError(t, proto.Unmarshal(<-owner.send, &msg))
+	for _, room := range msg.GetRoomListResponse().Rooms {
+		assert.NotEqual(t, joinResp.RoomId, room.RoomId)
+	}
+
+	// 通過邀請碼加入
+	guest := NewClient(nil, hub, log)
+	guest.PlayerID = 2
+	guest.IsGuest = true
+	guest.GuestPlayer = &game.Player{ID: 2, Balance: 10000}
+	mh.HandleMessage(guest, &pb.GameMessage{
+		Type: pb.MessageType_JOIN_ROOM,
+		Data: &pb.GameMessage_JoinRoom{JoinRoom: &pb.JoinRoomRequest{InviteCode: joinResp.InviteCode, Password: "wrong"}},
+	})
+	require.NoError(t, proto.Unmarshal(<-guest.send, &msg))
+	assert.Equal(t, pb.ErrorCode_WRONG_ROOM_PASSWORD, msg.GetError().ErrorCode)
+
+	mh.HandleMessage(guest, &pb.GameMessage{
+		Type: pb.MessageType_JOIN_ROOM,
+		Data: &pb.GameMessage_JoinRoom{JoinRoom: &pb.JoinRoomRequest{InviteCode: joinResp.InviteCode, Password: "pw"}},
+	})
+	require.NoError(t, proto.Unmarshal(<-guest.send, &msg))
+	require.Equal(t, pb.MessageType_JOIN_ROOM_RESPONSE, msg.Type)
+	assert.Equal(t, joinResp.RoomId, msg.GetJoinRoomResponse().RoomId)
+	<-hub.joinRoom
+
+	// 非房主不能鎖定房間
+	mh.HandleMessage(guest, &pb.GameMessage{
+		Type: pb.MessageType_LOCK_ROOM,
+		Data: &pb.GameMessage_LockRoom{LockRoom: &pb.LockRoomRequest{Locked: true}},
+	})
+	require.NoError(t, proto.Unmarshal(<-guest.send, &msg))
+	assert.Equal(t, pb.ErrorCode_NOT_ROOM_OWNER, msg.GetError().ErrorCode)
+
+	// 房主踢人後玩家離開房間
+	mh.HandleMessage(owner, &pb.GameMessage{
+		Type: pb.MessageType_KICK_PLAYER,
+		Data: &pb.GameMessage_KickPlayer{KickPlayer: &pb.KickPlayerRequest{PlayerId: 2}},
+	})
+	room, err := gu.GetRoom(context.Background(), joinResp.RoomId)
+	require.NoError(t, err)
+	assert.NotContains(t, room.Players, int64(2))
+}
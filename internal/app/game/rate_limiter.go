@@ -0,0 +1,367 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+)
+
+// ========================================
+// WebSocket 消息限流
+// ========================================
+
+// RateLimitAction 限流檢查結果對應的處理動作
+type RateLimitAction int
+
+const (
+	RateLimitAllow      RateLimitAction = iota // 放行
+	RateLimitDrop                              // 靜默丟棄
+	RateLimitWarn                              // 丟棄並回覆警告
+	RateLimitDisconnect                        // 丟棄並斷開連接
+)
+
+// RateLimitStats 限流統計信息
+type RateLimitStats struct {
+	Allowed          int64            `json:"allowed"`
+	Dropped          int64            `json:"dropped"`
+	Warned           int64            `json:"warned"`
+	Disconnects      int64            `json:"disconnects"`
+	Bans             int64            `json:"bans"`
+	RejectedBanned   int64            `json:"rejected_banned"`
+	ViolationsByType map[string]int64 `json:"violations_by_type"`
+	ActiveBans       int              `json:"active_bans"`
+}
+
+// MessageRateLimiter 管理限流配置、臨時封禁名單和違規統計
+// 每個客戶端通過 NewClientLimiter 取得自己的令牌桶集合
+type MessageRateLimiter struct {
+	config *conf.WSRateLimit
+	logger logger.Logger
+
+	// 臨時封禁（key 為 player:<id>；沒有玩家 ID 的連接才使用 ip:<addr>）
+	banMu   sync.Mutex
+	bans    map[string]time.Time
+	strikes map[string][]time.Time
+
+	// 統計
+	allowed        int64
+	dropped        int64
+	warned         int64
+	disconnects    int64
+	bansIssued     int64
+	rejectedBanned int64
+	statsMu        sync.Mutex
+	violations     map[string]int64
+
+	// 便於測試注入時間
+	now func() time.Time
+}
+
+// NewMessageRateLimiter 創建 WebSocket 消息限流器
+func NewMessageRateLimiter(config *conf.Config, logger logger.Logger) *MessageRateLimiter {
+	wsConfig := conf.DefaultWSRateLimit()
+	if config != nil && config.RateLimit != nil && config.RateLimit.WebSocket != nil {
+		wsConfig = config.RateLimit.WebSocket
+	}
+
+	return &MessageRateLimiter{
+		config:     wsConfig,
+		logger:     logger.With("component", "rate_limiter"),
+		bans:       make(map[string]time.Time),
+		strikes:    make(map[string][]time.Time),
+		violations: make(map[string]int64),
+		now:        time.Now,
+	}
+}
+
+// Enabled 是否啟用限流
+func (l *MessageRateLimiter) Enabled() bool {
+	return l != nil && l.config.Enable
+}
+
+// NewClientLimiter 為新連接創建令牌桶集合
+func (l *MessageRateLimiter) NewClientLimiter() *ClientRateLimiter {
+	return &ClientRateLimiter{
+		parent:  l,
+		buckets: make(map[pb.MessageType]*tokenBucket),
+	}
+}
+
+// bucketConfig 取得指定消息類型的令牌桶配置
+func (l *MessageRateLimiter) bucketConfig(msgType pb.MessageType) conf.TokenBucket {
+	name := strings.ToLower(msgType.String())
+	if bucket, ok := l.config.Messages[name]; ok && bucket.Rate > 0 {
+		return bucket
+	}
+	return l.config.Default
+}
+
+// fireRateForCannon 取得砲台類型對應的開火速率，未配置時返回 0
+func (l *MessageRateLimiter) fireRateForCannon(cannonType int32) float64 {
+	return l.config.CannonFireRates[strconv.Itoa(int(cannonType))]
+}
+
+// IsBanned 檢查任一 key 是否處於封禁期，返回剩餘封禁時間
+func (l *MessageRateLimiter) IsBanned(playerID int64, remoteIP string) (bool, time.Duration) {
+	if !l.Enabled() {
+		return false, 0
+	}
+
+	now := l.now()
+	l.banMu.Lock()
+	defer l.banMu.Unlock()
+
+	for _, key := range banKeys(playerID, remoteIP) {
+		until, ok := l.bans[key]
+		if !ok {
+			continue
+		}
+		if now.Before(until) {
+			atomic.AddInt64(&l.rejectedBanned, 1)
+			return true, until.Sub(now)
+		}
+		delete(l.bans, key)
+	}
+	return false, 0
+}
+
+// recordDisconnect 記錄一次因限流導致的斷線，達到閾值則臨時封禁
+func (l *MessageRateLimiter) recordDisconnect(playerID int64, remoteIP string) bool {
+	atomic.AddInt64(&l.disconnects, 1)
+
+	penalty := l.config.Penalty
+	now := l.now()
+	windowStart := now.Add(-time.Duration(penalty.BanWindow) * time.Second)
+	banned := false
+
+	l.banMu.Lock()
+	defer l.banMu.Unlock()
+
+	for _, key := range banKeys(playerID, remoteIP) {
+		history := l.strikes[key][:0]
+		for _, t := range l.strikes[key] {
+			if t.After(windowStart) {
+				history = append(history, t)
+			}
+		}
+		history = append(history, now)
+		l.strikes[key] = history
+
+		if len(history) >= penalty.BanAfter {
+			l.bans[key] = now.Add(time.Duration(penalty.BanDuration) * time.Second)
+			delete(l.strikes, key)
+			banned = true
+		}
+	}
+
+	if banned {
+		atomic.AddInt64(&l.bansIssued, 1)
+		l.logger.Warnf("Temporarily banned player %d (ip=%s) for %ds after repeated rate limit violations",
+			playerID, remoteIP, penalty.BanDuration)
+	}
+	return banned
+}
+
+// recordViolation 記錄違規統計
+func (l *MessageRateLimiter) recordViolation(msgType pb.MessageType, action RateLimitAction) {
+	l.statsMu.Lock()
+	l.violations[strings.ToLower(msgType.String())]++
+	l.statsMu.Unlock()
+
+	switch action {
+	case RateLimitDrop:
+		atomic.AddInt64(&l.dropped, 1)
+	case RateLimitWarn:
+		atomic.AddInt64(&l.warned, 1)
+	}
+}
+
+// GetStats 獲取限流統計信息
+func (l *MessageRateLimiter) GetStats() *RateLimitStats {
+	stats := &RateLimitStats{
+		Allowed:          atomic.LoadInt64(&l.allowed),
+		Dropped:          atomic.LoadInt64(&l.dropped),
+		Warned:           atomic.LoadInt64(&l.warned),
+		Disconnects:      atomic.LoadInt64(&l.disconnects),
+		Bans:             atomic.LoadInt64(&l.bansIssued),
+		RejectedBanned:   atomic.LoadInt64(&l.rejectedBanned),
+		ViolationsByType: make(map[string]int64),
+	}
+
+	l.statsMu.Lock()
+	for k, v := range l.violations {
+		stats.ViolationsByType[k] = v
+	}
+	l.statsMu.Unlock()
+
+	now := l.now()
+	l.banMu.Lock()
+	for _, until := range l.bans {
+		if now.Before(until) {
+			stats.ActiveBans++
+		}
+	}
+	l.banMu.Unlock()
+
+	return stats
+}
+
+// banKeys 生成封禁使用的 key 列表
+// 有玩家 ID 時只按玩家封禁，避免同一 NAT 或代理後的其他玩家被連帶封禁
+func banKeys(playerID int64, remoteIP string) []string {
+	if playerID != 0 {
+		return []string{fmt.Sprintf("player:%d", playerID)}
+	}
+	if remoteIP != "" {
+		return []string{"ip:" + remoteIP}
+	}
+	return nil
+}
+
+// ========================================
+// ClientRateLimiter - 單個連接的令牌桶集合
+// ========================================
+
+// ClientRateLimiter 單個客戶端的限流狀態
+type ClientRateLimiter struct {
+	parent *MessageRateLimiter
+
+	mu          sync.Mutex
+	buckets     map[pb.MessageType]*tokenBucket
+	fireRate    float64 // 當前砲台的開火速率，0 表示使用 fire_bullet 配置
+	violations  int
+	windowStart time.Time
+}
+
+// Check 檢查消息是否允許處理，並根據窗口內違規次數返回逐級懲罰
+func (c *ClientRateLimiter) Check(msgType pb.MessageType) RateLimitAction {
+	if c == nil || !c.parent.Enabled() {
+		return RateLimitAllow
+	}
+
+	now := c.parent.now()
+	c.mu.Lock()
+	bucket := c.bucketLocked(msgType, now)
+	if bucket.allow(now) {
+		c.mu.Unlock()
+		atomic.AddInt64(&c.parent.allowed, 1)
+		return RateLimitAllow
+	}
+
+	penalty := c.parent.config.Penalty
+	if c.windowStart.IsZero() || now.Sub(c.windowStart) > time.Duration(penalty.Window)*time.Second {
+		c.windowStart = now
+		c.violations = 0
+	}
+	c.violations++
+	violations := c.violations
+	c.mu.Unlock()
+
+	action := RateLimitDrop
+	switch {
+	case violations >= penalty.DisconnectAfter:
+		action = RateLimitDisconnect
+	case violations >= penalty.WarnAfter:
+		action = RateLimitWarn
+	}
+	c.parent.recordViolation(msgType, action)
+	return action
+}
+
+// SetCannon 根據砲台類型調整開火令牌桶的補充速率
+func (c *ClientRateLimiter) SetCannon(cannonType int32) {
+	if c == nil {
+		return
+	}
+
+	rate := c.parent.fireRateForCannon(cannonType)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fireRate = rate
+	if bucket, ok := c.buckets[pb.MessageType_FIRE_BULLET]; ok {
+		cfg := c.fireBucketConfigLocked()
+		bucket.reconfigure(cfg.Rate, cfg.Burst, c.parent.now())
+	}
+}
+
+// bucketLocked 取得（必要時創建）消息類型對應的令牌桶，調用者需持有 c.mu
+func (c *ClientRateLimiter) bucketLocked(msgType pb.MessageType, now time.Time) *tokenBucket {
+	if bucket, ok := c.buckets[msgType]; ok {
+		return bucket
+	}
+
+	cfg := c.parent.bucketConfig(msgType)
+	if msgType == pb.MessageType_FIRE_BULLET {
+		cfg = c.fireBucketConfigLocked()
+	}
+	bucket := newTokenBucket(cfg.Rate, cfg.Burst, now)
+	c.buckets[msgType] = bucket
+	return bucket
+}
+
+// fireBucketConfigLocked 計算開火令牌桶配置：砲台速率優先，突發量至少容納一秒的開火
+func (c *ClientRateLimiter) fireBucketConfigLocked() conf.TokenBucket {
+	cfg := c.parent.bucketConfig(pb.MessageType_FIRE_BULLET)
+	if c.fireRate > 0 {
+		cfg.Rate = c.fireRate
+		if minBurst := int(math.Ceil(c.fireRate)); cfg.Burst < minBurst {
+			cfg.Burst = minBurst
+		}
+	}
+	return cfg
+}
+
+// tokenBucket 簡單的令牌桶實現
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	b := &tokenBucket{last: now}
+	b.reconfigure(rate, burst, now)
+	b.tokens = b.burst
+	return b
+}
+
+// reconfigure 更新速率和容量，保留已有令牌（不超過新容量）
+func (b *tokenBucket) reconfigure(rate float64, burst int, now time.Time) {
+	b.refill(now)
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	b.rate = rate
+	b.burst = float64(burst)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package game
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestRateLimiter 創建使用可控時鐘的限流器
+func newTestRateLimiter(ws *conf.WSRateLimit) (*MessageRateLimiter, *time.Time) {
+	log := logger.New(os.Stdout, "error", "console")
+	cfg := &conf.Config{RateLimit: &conf.RateLimit{WebSocket: ws}}
+	limiter := NewMessageRateLimiter(cfg, log)
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestClientRateLimiter_TokenBucket(t *testing.T) {
+	ws := conf.DefaultWSRateLimit()
+	ws.Messages["heartbeat"] = conf.TokenBucket{Rate: 1, Burst: 2}
+	limiter, now := newTestRateLimiter(ws)
+	client := limiter.NewClientLimiter()
+
+	assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_HEARTBEAT))
+	assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_HEARTBEAT))
+	assert.Equal(t, RateLimitDrop, client.Check(pb.MessageType_HEARTBEAT))
+
+	// 其他消息類型使用獨立的令牌桶
+	assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_GET_ROOM_LIST))
+
+	// 一秒後補充一個令牌
+	*now = now.Add(time.Second)
+	assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_HEARTBEAT))
+	assert.Equal(t, RateLimitDrop, client.Check(pb.MessageType_HEARTBEAT))
+
+	stats := limiter.GetStats()
+	assert.Equal(t, int64(2), stats.Dropped)
+	assert.Equal(t, int64(2), stats.ViolationsByType["heartbeat"])
+}
+
+func TestClientRateLimiter_FireRateFollowsCannon(t *testing.T) {
+	ws := conf.DefaultWSRateLimit()
+	ws.Messages["fire_bullet"] = conf.TokenBucket{Rate: 2, Burst: 2}
+	ws.CannonFireRates = map[string]float64{"3": 6}
+	limiter, now := newTestRateLimiter(ws)
+	client := limiter.NewClientLimiter()
+
+	// 默認砲台：2 發突發
+	assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_FIRE_BULLET))
+	assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_FIRE_BULLET))
+	assert.Equal(t, RateLimitDrop, client.Check(pb.MessageType_FIRE_BULLET))
+
+	// 切換到高速砲台後，每秒可開火 6 次
+	client.SetCannon(3)
+	*now = now.Add(time.Second)
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if client.Check(pb.MessageType_FIRE_BULLET) == RateLimitAllow {
+			allowed++
+		}
+	}
+	assert.Equal(t, 6, allowed)
+}
+
+func TestClientRateLimiter_ProgressivePenalties(t *testing.T) {
+	ws := conf.DefaultWSRateLimit()
+	ws.Messages["join_room"] = conf.TokenBucket{Rate: 0.1, Burst: 1}
+	ws.Penalty = conf.WSPenalty{Window: 10, WarnAfter: 2, DisconnectAfter: 4, BanAfter: 2, BanWindow: 60, BanDuration: 30}
+	limiter, now := newTestRateLimiter(ws)
+	client := limiter.NewClientLimiter()
+
+	assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_JOIN_ROOM))
+	assert.Equal(t, RateLimitDrop, client.Check(pb.MessageType_JOIN_ROOM))
+	assert.Equal(t, RateLimitWarn, client.Check(pb.MessageType_JOIN_ROOM))
+	assert.Equal(t, RateLimitWarn, client.Check(pb.MessageType_JOIN_ROOM))
+	assert.Equal(t, RateLimitDisconnect, client.Check(pb.MessageType_JOIN_ROOM))
+
+	// 違規窗口過後重新計數
+	*now = now.Add(11 * time.Second)
+	client.Check(pb.MessageType_JOIN_ROOM) // 補充的令牌
+	assert.Equal(t, RateLimitDrop, client.Check(pb.MessageType_JOIN_ROOM))
+
+	// 多次斷線後臨時封禁
+	assert.False(t, limiter.recordDisconnect(42, "10.0.0.1"))
+	assert.True(t, limiter.recordDisconnect(42, "10.0.0.1"))
+
+	banned, remaining := limiter.IsBanned(42, "")
+	assert.True(t, banned)
+	assert.Equal(t, 30*time.Second, remaining)
+	banned, _ = limiter.IsBanned(43, "10.0.0.1")
+	assert.False(t, banned, "other players behind the same address are not banned")
+
+	*now = now.Add(31 * time.Second)
+	banned, _ = limiter.IsBanned(42, "10.0.0.1")
+	assert.False(t, banned)
+
+	stats := limiter.GetStats()
+	assert.Equal(t, int64(2), stats.Disconnects)
+	assert.Equal(t, int64(1), stats.Bans)
+	assert.Equal(t, int64(1), stats.RejectedBanned)
+}
+
+func TestClientRateLimiter_Disabled(t *testing.T) {
+	ws := conf.DefaultWSRateLimit()
+	ws.Enable = false
+	ws.Messages["heartbeat"] = conf.TokenBucket{Rate: 1, Burst: 1}
+	limiter, _ := newTestRateLimiter(ws)
+	client := limiter.NewClientLimiter()
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, RateLimitAllow, client.Check(pb.MessageType_HEARTBEAT))
+	}
+}
+
+func TestReadPump_FloodRecordsSingleDisconnect(t *testing.T) {
+	ws := conf.DefaultWSRateLimit()
+	ws.Messages["heartbeat"] = conf.TokenBucket{Rate: 0.1, Burst: 1}
+	ws.Penalty = conf.WSPenalty{Window: 10, WarnAfter: 2, DisconnectAfter: 3, BanAfter: 3, BanWindow: 60, BanDuration: 30}
+	limiter, _ := newTestRateLimiter(ws)
+
+	log := logger.New(os.Stdout, "error", "console")
+	serverConn := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConn <- conn
+	}))
+	defer srv.Close()
+
+	clientConn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer clientConn.Close()
+
+	hub := &Hub{rateLimiter: limiter, unregister: make(chan *Client, 1)}
+	client := NewClient(<-serverConn, hub, log)
+	client.ID = "flooding_client"
+	client.PlayerID = 42
+	client.rateLimit = limiter.NewClientLimiter()
+	// 先耗盡令牌，之後的每一幀都超出限額
+	require.Equal(t, RateLimitAllow, client.rateLimit.Check(pb.MessageType_HEARTBEAT))
+
+	frame, err := proto.Marshal(&pb.GameMessage{Type: pb.MessageType_HEARTBEAT})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, clientConn.WriteMessage(websocket.BinaryMessage, frame))
+	}
+
+	go client.writePump()
+	go client.readPump()
+
+	select {
+	case <-hub.unregister:
+	case <-time.After(2 * time.Second):
+		t.Fatal("flooding connection was not closed")
+	}
+
+	stats := limiter.GetStats()
+	assert.Equal(t, int64(1), stats.Disconnects)
+	assert.Equal(t, int64(0), stats.Bans, "one flood burst must not escalate to a ban")
+	banned, _ := limiter.IsBanned(42, "")
+	assert.False(t, banned)
+}
@@ -0,0 +1,174 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// RoomConfigWatcher - 房間配置熱更新
+// ========================================
+//
+// 啟動時從 room_configs 加載各房間類型的配置（在恢復和創建房間之前），之後訂閱管理後台發布的配置變更；
+// pub/sub 消息可能在斷線期間丟失，因此還會定期重新同步。業務層只接受版本更新的配置，
+// 重複或過時的通知會被忽略，運行中的房間在下一個遊戲循環週期套用新配置。
+
+const (
+	roomConfigLoadTimeout = 5 * time.Second
+	roomConfigRetryMin    = time.Second
+	roomConfigRetryMax    = 30 * time.Second
+)
+
+// RoomConfigWatcher 房間配置熱更新
+type RoomConfigWatcher struct {
+	repo        game.RoomConfigRepo
+	bus         game.RoomConfigBus
+	gameUsecase *game.GameUsecase
+
+	hotReload      bool
+	resyncInterval time.Duration
+
+	logger logger.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// NewRoomConfigWatcher 創建房間配置熱更新
+func NewRoomConfigWatcher(repo game.RoomConfigRepo, bus game.RoomConfigBus, gameUsecase *game.GameUsecase, config *conf.Config, logger logger.Logger) *RoomConfigWatcher {
+	settings := &conf.RoomConfigs{HotReload: true, ResyncInterval: 60}
+	if config != nil && config.Game != nil && config.Game.RoomConfigs != nil {
+		settings = config.Game.RoomConfigs
+	}
+
+	return &RoomConfigWatcher{
+		repo:           repo,
+		bus:            bus,
+		gameUsecase:    gameUsecase,
+		hotReload:      settings.HotReload,
+		resyncInterval: time.Duration(settings.ResyncInterval) * time.Second,
+		logger:         logger.With("component", "room_config_watcher"),
+	}
+}
+
+// Load 從資料庫加載所有房間類型的配置，返回更新的房間類型數；加載失敗時繼續使用當前配置
+func (w *RoomConfigWatcher) Load(ctx context.Context) int {
+	versions, err := w.repo.ListRoomConfigs(ctx)
+	if err != nil {
+		w.logger.Errorf("Failed to load room configs, keeping current configs: %v", err)
+		return 0
+	}
+
+	updated := 0
+	for _, version := range versions {
+		if w.apply(version) {
+			updated++
+		}
+	}
+	return updated
+}
+
+// Start 開始訂閱配置變更和定期重新同步，需在 Load 之後調用
+func (w *RoomConfigWatcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil || !w.hotReload {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done.Add(2)
+	go w.subscribe(ctx)
+	go w.resync(ctx)
+	w.logger.Infof("Room config hot reload started: resync_interval=%v", w.resyncInterval)
+}
+
+// Stop 停止訂閱和重新同步
+func (w *RoomConfigWatcher) Stop() {
+	w.mu.Lock()
+	cancel := w.cancel
+	w.cancel = nil
+	w.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	w.done.Wait()
+}
+
+// subscribe 訂閱配置變更，連接斷開後退避重試，重新訂閱後立即同步一次
+func (w *RoomConfigWatcher) subscribe(ctx context.Context) {
+	defer w.done.Done()
+
+	backoff := roomConfigRetryMin
+	for {
+		err := w.bus.SubscribeRoomConfigs(ctx, func(version *game.RoomConfigVersion) { w.apply(version) })
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Warnf("Room config subscription lost, retrying in %v: %v", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, roomConfigRetryMax)
+
+		loadCtx, cancel := context.WithTimeout(ctx, roomConfigLoadTimeout)
+		w.Load(loadCtx)
+		cancel()
+	}
+}
+
+// resync 定期從資料庫重新同步
+func (w *RoomConfigWatcher) resync(ctx context.Context) {
+	defer w.done.Done()
+
+	ticker := time.NewTicker(w.resyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		loadCtx, cancel := context.WithTimeout(ctx, roomConfigLoadTimeout)
+		if updated := w.Load(loadCtx); updated > 0 {
+			w.logger.Infof("Resync picked up %d room config updates", updated)
+		}
+		cancel()
+	}
+}
+
+// apply 套用配置版本，返回是否更新了當前配置
+func (w *RoomConfigWatcher) apply(version *game.RoomConfigVersion) bool {
+	scheduled, updated, err := w.gameUsecase.ApplyRoomConfig(version)
+	if err != nil {
+		w.logger.Errorf("Rejected room config %s version %d: %v", version.RoomType, version.Version, err)
+		return false
+	}
+	if updated {
+		w.logger.Infof("Room config %s updated to version %d by %s, %d live rooms will apply it",
+			version.RoomType, version.Version, version.UpdatedBy, scheduled)
+	}
+	return updated
+}
+
+// configVersion 房間已套用的配置版本，隨房間狀態下發給客戶端
+func (rm *RoomManager) configVersion() int64 {
+	if rm.businessRoomID == "" {
+		return 0
+	}
+	return rm.gameUsecase.AppliedConfigVersion(rm.businessRoomID)
+}
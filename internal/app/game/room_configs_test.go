@@ -0,0 +1,476 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRoomConfigRepo 內存房間配置存儲
+type memoryRoomConfigRepo struct {
+	mu      sync.Mutex
+	current map[game.RoomType]*game.RoomConfigVersion
+	history map[game.RoomType][]*game.RoomConfigVersion
+	listErr error
+}
+
+func newMemoryRoomConfigRepo() *memoryRoomConfigRepo {
+	return &memoryRoomConfigRepo{
+		current: make(map[game.RoomType]*game.RoomConfigVersion),
+		history: make(map[game.RoomType][]*game.RoomConfigVersion),
+	}
+}
+
+func (r *memoryRoomConfigRepo) GetRoomConfig(ctx context.Context, roomType string) (*game.RoomConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.current[game.RoomType(roomType)]; ok && v.Active {
+		config := v.Config
+		return &config, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (r *memoryRoomConfigRepo) GetAllRoomConfigs(ctx context.Context) (map[string]*game.RoomConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	configs := make(map[string]*game.RoomConfig)
+	for roomType, v := range r.current {
+		if v.Active {
+			config := v.Config
+			configs[string(roomType)] = &config
+		}
+	}
+	return configs, nil
+}
+
+func (r *memoryRoomConfigRepo) CurrentRoomConfig(ctx context.Context, roomType game.RoomType) (*game.RoomConfigVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.current[roomType]; ok {
+		copied := *v
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryRoomConfigRepo) ListRoomConfigs(ctx context.Context) ([]*game.RoomConfigVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.listErr != nil {
+		return nil, r.listErr
+	}
+	versions := make([]*game.RoomConfigVersion, 0, len(r.current))
+	for _, v := range r.current {
+		copied := *v
+		versions = append(versions, &copied)
+	}
+	return versions, nil
+}
+
+func (r *memoryRoomConfigRepo) SaveRoomConfig(ctx context.Context, v *game.RoomConfigVersion, expectedVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var current int64
+	if existing, ok := r.current[v.RoomType]; ok {
+		current = existing.Version
+	}
+	if current != expectedVersion {
+		return game.ErrRoomConfigConflict
+	}
+	v.Version = expectedVersion + 1
+	v.Config.Version = v.Version
+	v.CreatedAt = time.Now()
+	copied := *v
+	r.current[v.RoomType] = &copied
+	r.history[v.RoomType] = append([]*game.RoomConfigVersion{&copied}, r.history[v.RoomType]...)
+	return nil
+}
+
+func (r *memoryRoomConfigRepo) RoomConfigHistory(ctx context.Context, roomType game.RoomType, limit int) ([]*game.RoomConfigVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	history := r.history[roomType]
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
+// memoryRoomConfigBus 內存配置變更通知，訂閱者在 ctx 取消或 drop 時返回
+type memoryRoomConfigBus struct {
+	mu        sync.Mutex
+	handlers  []func(*game.RoomConfigVersion)
+	published []*game.RoomConfigVersion
+	drop      chan struct{}
+}
+
+func newMemoryRoomConfigBus() *memoryRoomConfigBus {
+	return &memoryRoomConfigBus{drop: make(chan struct{}, 1)}
+}
+
+func (b *memoryRoomConfigBus) PublishRoomConfig(ctx context.Context, version *game.RoomConfigVersion) error {
+	b.mu.Lock()
+	copied := *version
+	b.published = append(b.published, &copied)
+	handlers := append([]func(*game.RoomConfigVersion){}, b.handlers...)
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(&copied)
+	}
+	return nil
+}
+
+func (b *memoryRoomConfigBus) SubscribeRoomConfigs(ctx context.Context, handler func(*game.RoomConfigVersion)) error {
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handler)
+	index := len(b.handlers) - 1
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.handlers[index] = func(*game.RoomConfigVersion) {}
+		b.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-b.drop:
+		return errors.New("connection lost")
+	}
+}
+
+// subscribers 已訂閱的次數（包括斷開後重新訂閱）
+func (b *memoryRoomConfigBus) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.handlers)
+}
+
+// noviceConfigVersion 新手房默認配置的一個修改版本
+func noviceConfigVersion(version int64, minBet int64) *game.RoomConfigVersion {
+	config, _ := game.DefaultRoomConfig(game.RoomTypeNovice)
+	config.MinBet = minBet
+	config.MaxPlayers = 6
+	config.Version = version
+	return &game.RoomConfigVersion{
+		RoomType: game.RoomTypeNovice,
+		Version:  version,
+		Name:     "新手房間",
+		Active:   true,
+		Config:   config,
+	}
+}
+
+func TestValidateRoomConfig(t *testing.T) {
+	valid, ok := game.DefaultRoomConfig(game.RoomTypeNovice)
+	require.True(t, ok)
+	require.NoError(t, game.ValidateRoomConfig(valid))
+
+	cases := map[string]func(c *game.RoomConfig){
+		"no seats":           func(c *game.RoomConfig) { c.MaxPlayers = 0 },
+		"too many seats":     func(c *game.RoomConfig) { c.MaxPlayers = 9 },
+		"negative spectator": func(c *game.RoomConfig) { c.MaxSpectators = -1 },
+		"zero min bet":       func(c *game.RoomConfig) { c.MinBet = 0 },
+		"max below min":      func(c *game.RoomConfig) { c.MaxBet = c.MinBet - 1 },
+		"zero multiplier":    func(c *game.RoomConfig) { c.BulletCostMultiplier = 0 },
+		"spawn rate above 1": func(c *game.RoomConfig) { c.FishSpawnRate = 1.5 },
+		"no fish":            func(c *game.RoomConfig) { c.MaxFishCount = 0 },
+		"min above max fish": func(c *game.RoomConfig) { c.MinFishCount = c.MaxFishCount + 1 },
+		"zero width":         func(c *game.RoomConfig) { c.RoomWidth = 0 },
+		"rtp above 1":        func(c *game.RoomConfig) { c.TargetRTP = 1.2 },
+		"rtp too low":        func(c *game.RoomConfig) { c.TargetRTP = 0.2 },
+		"unknown currency":   func(c *game.RoomConfig) { c.Currency = "USD" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			mutate(&config)
+			assert.ErrorIs(t, game.ValidateRoomConfig(config), game.ErrInvalidRoomConfig)
+		})
+	}
+
+	t.Run("reports every problem", func(t *testing.T) {
+		config := valid
+		config.MinBet = 0
+		config.TargetRTP = 2
+		err := game.ValidateRoomConfig(config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "min_bet")
+		assert.Contains(t, err.Error(), "target_rtp")
+	})
+}
+
+func TestApplyRoomConfigUpdatesLiveRoomsOnNextTick(t *testing.T) {
+	gu, _ := newTestRoomUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	other, err := gu.CreateRoom(ctx, game.RoomTypeVIP, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), gu.AppliedConfigVersion(room.ID))
+
+	scheduled, updated, err := gu.ApplyRoomConfig(noviceConfigVersion(2, 5))
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, 1, scheduled)
+
+	require.Eventually(t, func() bool {
+		return gu.AppliedConfigVersion(room.ID) == 2
+	}, 2*time.Second, 20*time.Millisecond)
+
+	current, err := gu.GetRoom(ctx, room.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), current.Config.MinBet)
+	assert.Equal(t, int32(4), current.Config.MaxPlayers, "live rooms keep their seat count")
+	assert.Equal(t, int64(0), gu.AppliedConfigVersion(other.ID), "other room types are untouched")
+
+	// 新建房間直接使用新配置
+	created, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), created.Config.Version)
+	assert.Equal(t, int32(6), created.MaxPlayers)
+	assert.Equal(t, int64(2), gu.RoomConfigVersions()[game.RoomTypeNovice])
+}
+
+func TestJoinRoomUsesRoomCurrencyWallet(t *testing.T) {
+	gu, _ := newTestRoomUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+
+	coinConfig := noviceConfigVersion(2, 5)
+	coinConfig.Config.Currency = wallet.CurrencyCoin
+	_, _, err := gu.ApplyRoomConfig(coinConfig)
+	require.NoError(t, err)
+
+	coinRoom, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 0)
+	require.NoError(t, err)
+	require.NoError(t, gu.JoinRoom(ctx, coinRoom.ID, 7))
+
+	current, err := gu.GetRoom(ctx, coinRoom.ID)
+	require.NoError(t, err)
+	require.Contains(t, current.Players, int64(7))
+	assert.Equal(t, wallet.CurrencyCoin, current.Players[7].Currency)
+	assert.Equal(t, int64(100000), current.Players[7].Balance, "balance comes from the COIN wallet")
+
+	// 主幣種房間沿用玩家信息中的主錢包
+	cnyRoom, err := gu.CreateRoom(ctx, game.RoomTypeVIP, 0)
+	require.NoError(t, err)
+	require.NoError(t, gu.JoinRoom(ctx, cnyRoom.ID, 8))
+	current, err = gu.GetRoom(ctx, cnyRoom.ID)
+	require.NoError(t, err)
+	require.Contains(t, current.Players, int64(8))
+	assert.Equal(t, int64(10000), current.Players[8].Balance)
+
+	// 運行中的房間不跟隨配置切換幣種
+	tokenConfig := noviceConfigVersion(3, 5)
+	tokenConfig.Config.Currency = wallet.CurrencyToken
+	_, _, err = gu.ApplyRoomConfig(tokenConfig)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return gu.AppliedConfigVersion(coinRoom.ID) == 3
+	}, 2*time.Second, 20*time.Millisecond)
+	current, err = gu.GetRoom(ctx, coinRoom.ID)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.CurrencyCoin, current.Config.PlayCurrency())
+}
+
+func TestApplyRoomConfigIgnoresStaleVersions(t *testing.T) {
+	gu, _ := newTestRoomUsecase(t)
+
+	_, updated, err := gu.ApplyRoomConfig(noviceConfigVersion(3, 5))
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	for _, version := range []int64{3, 2} {
+		_, updated, err = gu.ApplyRoomConfig(noviceConfigVersion(version, 50))
+		require.NoError(t, err)
+		assert.False(t, updated, "version %d is not newer", version)
+	}
+	assert.Equal(t, int64(3), gu.RoomConfigVersions()[game.RoomTypeNovice])
+
+	invalid := noviceConfigVersion(4, 0)
+	_, updated, err = gu.ApplyRoomConfig(invalid)
+	assert.ErrorIs(t, err, game.ErrInvalidRoomConfig)
+	assert.False(t, updated)
+
+	_, _, err = gu.ApplyRoomConfig(&game.RoomConfigVersion{RoomType: "unknown", Version: 1, Active: true})
+	assert.ErrorIs(t, err, game.ErrUnknownRoomType)
+}
+
+func TestApplyDeletedRoomConfigRevertsToDefaults(t *testing.T) {
+	gu, _ := newTestRoomUsecase(t)
+	closeAllRooms(t, gu)
+
+	_, _, err := gu.ApplyRoomConfig(noviceConfigVersion(1, 5))
+	require.NoError(t, err)
+
+	deleted := noviceConfigVersion(2, 5)
+	deleted.Active = false
+	_, updated, err := gu.ApplyRoomConfig(deleted)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	room, err := gu.CreateRoom(context.Background(), game.RoomTypeNovice, 0)
+	require.NoError(t, err)
+	defaults, _ := game.DefaultRoomConfig(game.RoomTypeNovice)
+	assert.Equal(t, int64(2), room.Config.Version)
+	assert.Equal(t, defaults.MinBet, room.Config.MinBet)
+	assert.Equal(t, defaults.MaxPlayers, room.MaxPlayers)
+}
+
+func TestRoomConfigUsecase(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRoomConfigRepo()
+	bus := newMemoryRoomConfigBus()
+	uc := game.NewRoomConfigUsecase(repo, bus, logger.New(os.Stdout, "error", "console"))
+
+	t.Run("list falls back to builtin configs", func(t *testing.T) {
+		versions, err := uc.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, versions, len(game.RoomTypes()))
+		for _, v := range versions {
+			assert.Equal(t, int64(0), v.Version)
+			assert.True(t, v.Active)
+		}
+	})
+
+	created := noviceConfigVersion(0, 5)
+	created.UpdatedBy = "admin:1"
+	t.Run("create", func(t *testing.T) {
+		require.NoError(t, uc.Create(ctx, created))
+		assert.Equal(t, int64(1), created.Version)
+		assert.ErrorIs(t, uc.Create(ctx, noviceConfigVersion(0, 5)), game.ErrRoomConfigExists)
+		require.Len(t, bus.published, 1)
+		assert.Equal(t, int64(1), bus.published[0].Config.Version)
+	})
+
+	t.Run("update checks version and validates", func(t *testing.T) {
+		update := noviceConfigVersion(0, 8)
+		update.Name = ""
+		require.NoError(t, uc.Update(ctx, update, 1))
+		assert.Equal(t, int64(2), update.Version)
+		assert.Equal(t, "新手房間", update.Name, "name is kept when omitted")
+
+		assert.ErrorIs(t, uc.Update(ctx, noviceConfigVersion(0, 9), 1), game.ErrRoomConfigConflict)
+		assert.ErrorIs(t, uc.Update(ctx, noviceConfigVersion(0, 0), 2), game.ErrInvalidRoomConfig)
+
+		current, err := uc.Get(ctx, game.RoomTypeNovice)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), current.Version)
+		assert.Equal(t, int64(8), current.Config.MinBet)
+	})
+
+	t.Run("update unknown or missing type", func(t *testing.T) {
+		vip := noviceConfigVersion(0, 5)
+		vip.RoomType = game.RoomTypeVIP
+		assert.ErrorIs(t, uc.Update(ctx, vip, 0), game.ErrRoomConfigNotFound)
+
+		unknown := noviceConfigVersion(0, 5)
+		unknown.RoomType = "unknown"
+		assert.ErrorIs(t, uc.Update(ctx, unknown, 0), game.ErrUnknownRoomType)
+	})
+
+	t.Run("delete keeps history", func(t *testing.T) {
+		_, err := uc.Delete(ctx, game.RoomTypeNovice, 1, "admin:2")
+		assert.ErrorIs(t, err, game.ErrRoomConfigConflict)
+
+		deleted, err := uc.Delete(ctx, game.RoomTypeNovice, 2, "admin:2")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), deleted.Version)
+		assert.False(t, deleted.Active)
+
+		_, err = uc.Delete(ctx, game.RoomTypeNovice, 0, "admin:2")
+		assert.ErrorIs(t, err, game.ErrRoomConfigNotFound)
+
+		history, err := uc.History(ctx, game.RoomTypeNovice, 10)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Equal(t, []int64{3, 2, 1}, []int64{history[0].Version, history[1].Version, history[2].Version})
+
+		// 刪除後可以重新創建，版本號繼續遞增
+		recreated := noviceConfigVersion(0, 5)
+		require.NoError(t, uc.Create(ctx, recreated))
+		assert.Equal(t, int64(4), recreated.Version)
+	})
+}
+
+func TestRoomConfigWatcher(t *testing.T) {
+	gu, _ := newTestRoomUsecase(t)
+	closeAllRooms(t, gu)
+	repo := newMemoryRoomConfigRepo()
+	bus := newMemoryRoomConfigBus()
+	log := logger.New(os.Stdout, "error", "console")
+	uc := game.NewRoomConfigUsecase(repo, bus, log)
+
+	require.NoError(t, uc.Create(context.Background(), noviceConfigVersion(0, 5)))
+
+	config := &conf.Config{Game: &conf.Game{RoomConfigs: &conf.RoomConfigs{HotReload: true, ResyncInterval: 1}}}
+	watcher := NewRoomConfigWatcher(repo, bus, gu, config, log)
+
+	t.Run("load applies stored configs", func(t *testing.T) {
+		assert.Equal(t, 1, watcher.Load(context.Background()))
+		assert.Equal(t, 0, watcher.Load(context.Background()), "reloading the same versions is a no-op")
+		assert.Equal(t, int64(1), gu.RoomConfigVersions()[game.RoomTypeNovice])
+	})
+
+	t.Run("load failure keeps current configs", func(t *testing.T) {
+		repo.mu.Lock()
+		repo.listErr = errors.New("db down")
+		repo.mu.Unlock()
+		assert.Equal(t, 0, watcher.Load(context.Background()))
+		assert.Equal(t, int64(1), gu.RoomConfigVersions()[game.RoomTypeNovice])
+
+		repo.mu.Lock()
+		repo.listErr = nil
+		repo.mu.Unlock()
+	})
+
+	watcher.Start()
+	defer watcher.Stop()
+	require.Eventually(t, func() bool { return bus.subscribers() == 1 }, time.Second, 10*time.Millisecond)
+
+	t.Run("published updates reach live rooms", func(t *testing.T) {
+		room, err := gu.CreateRoom(context.Background(), game.RoomTypeNovice, 4)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), room.Config.Version)
+
+		require.NoError(t, uc.Update(context.Background(), noviceConfigVersion(0, 7), 1))
+		require.Eventually(t, func() bool {
+			return gu.AppliedConfigVersion(room.ID) == 2
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("missed messages are picked up by resync", func(t *testing.T) {
+		missed := noviceConfigVersion(0, 9)
+		require.NoError(t, repo.SaveRoomConfig(context.Background(), missed, 2))
+
+		require.Eventually(t, func() bool {
+			return gu.RoomConfigVersions()[game.RoomTypeNovice] == 3
+		}, 3*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("resubscribes after connection loss", func(t *testing.T) {
+		bus.drop <- struct{}{}
+		require.Eventually(t, func() bool { return bus.subscribers() == 2 }, 3*time.Second, 20*time.Millisecond)
+
+		require.NoError(t, uc.Update(context.Background(), noviceConfigVersion(0, 10), 3))
+		require.Eventually(t, func() bool {
+			return gu.RoomConfigVersions()[game.RoomTypeNovice] == 4
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,259 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+)
+
+// ========================================
+// RoomEventScheduler - 定時房間活動排程器
+// ========================================
+//
+// 定期從 room_event_schedules 加載排程，每秒計算正在進行的活動交給業務層。活動開始或結束時
+// 向受影響的房間廣播 ROOM_EVENT，並刷新 Redis 中的大廳活動看板（正在進行和 lookahead 內開始的活動）。
+// 每個節點按相同的排程獨立計算，活動場次 ID 由排程 ID 和開始時間組成，所有節點一致。
+
+const (
+	roomEventTickInterval = time.Second
+	roomEventLoadTimeout  = 5 * time.Second
+)
+
+// RoomEventScheduler 定時房間活動排程器
+type RoomEventScheduler struct {
+	repo        game.RoomEventRepo
+	board       lobby.RoomEventBoard
+	hub         *Hub
+	gameUsecase *game.GameUsecase
+
+	enabled        bool
+	reloadInterval time.Duration
+	lookahead      time.Duration
+
+	logger logger.Logger
+
+	schedulesMu sync.RWMutex
+	schedules   []*game.RoomEventSchedule
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// NewRoomEventScheduler 創建定時房間活動排程器
+func NewRoomEventScheduler(repo game.RoomEventRepo, board lobby.RoomEventBoard, hub *Hub, gameUsecase *game.GameUsecase, config *conf.Config, logger logger.Logger) *RoomEventScheduler {
+	settings := &conf.RoomEvents{Enabled: true, ReloadInterval: 30, LookaheadHours: 24}
+	if config != nil && config.Game != nil && config.Game.RoomEvents != nil {
+		settings = config.Game.RoomEvents
+	}
+
+	return &RoomEventScheduler{
+		repo:           repo,
+		board:          board,
+		hub:            hub,
+		gameUsecase:    gameUsecase,
+		enabled:        settings.Enabled,
+		reloadInterval: time.Duration(settings.ReloadInterval) * time.Second,
+		lookahead:      time.Duration(settings.LookaheadHours) * time.Hour,
+		logger:         logger.With("component", "room_event_scheduler"),
+	}
+}
+
+// Load 從資料庫加載排程，加載失敗時繼續使用當前排程
+func (s *RoomEventScheduler) Load(ctx context.Context) error {
+	schedules, err := s.repo.ListRoomEventSchedules(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to load room event schedules, keeping current schedules: %v", err)
+		return err
+	}
+
+	s.schedulesMu.Lock()
+	s.schedules = schedules
+	s.schedulesMu.Unlock()
+	return nil
+}
+
+// Start 加載排程並開始計算活動，需在房間恢復之前調用，恢復的房間從第一次狀態更新起帶上活動
+func (s *RoomEventScheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil || !s.enabled {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	loadCtx, loadCancel := context.WithTimeout(ctx, roomEventLoadTimeout)
+	s.Load(loadCtx)
+	loadCancel()
+	s.Tick(ctx, time.Now())
+	s.publishBoard(ctx, time.Now())
+
+	s.done.Add(1)
+	go s.run(ctx)
+	s.logger.Infof("Room event scheduler started: reload_interval=%v, lookahead=%v", s.reloadInterval, s.lookahead)
+}
+
+// Stop 停止排程器，正在進行的活動保持到節點關閉
+func (s *RoomEventScheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	s.done.Wait()
+}
+
+// run 每秒計算活動，定期重新加載排程
+func (s *RoomEventScheduler) run(ctx context.Context) {
+	defer s.done.Done()
+
+	ticker := time.NewTicker(roomEventTickInterval)
+	defer ticker.Stop()
+	reload := time.NewTicker(s.reloadInterval)
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Tick(ctx, now)
+		case now := <-reload.C:
+			loadCtx, cancel := context.WithTimeout(ctx, roomEventLoadTimeout)
+			if s.Load(loadCtx) == nil {
+				s.Tick(ctx, now)
+				s.publishBoard(loadCtx, now)
+			}
+			cancel()
+		}
+	}
+}
+
+// Tick 計算 now 正在進行的活動，向受影響的房間廣播開始和結束的活動
+func (s *RoomEventScheduler) Tick(ctx context.Context, now time.Time) {
+	s.schedulesMu.RLock()
+	active := game.ActiveRoomEventsAt(s.schedules, now)
+	s.schedulesMu.RUnlock()
+
+	started, ended := s.gameUsecase.SetActiveRoomEvents(active)
+	if len(started) == 0 && len(ended) == 0 {
+		return
+	}
+
+	for _, event := range ended {
+		s.announce(ctx, "ended", event, now)
+	}
+	for _, event := range started {
+		s.announce(ctx, "started", event, now)
+	}
+	s.publishBoard(ctx, now)
+}
+
+// announce 向活動生效的房間廣播活動開始或結束
+func (s *RoomEventScheduler) announce(ctx context.Context, phase string, event *game.RoomEvent, now time.Time) {
+	rooms, err := s.gameUsecase.GetRoomList(ctx, "")
+	if err != nil {
+		s.logger.Warnf("Failed to list rooms for room event %s: %v", event.ID, err)
+		return
+	}
+
+	info := roomEventInfo(event)
+	for _, room := range rooms {
+		if !event.Affects(room.Type) {
+			continue
+		}
+		s.hub.broadcastToRoom(room.ID, &pb.GameMessage{
+			Type: pb.MessageType_ROOM_EVENT,
+			Data: &pb.GameMessage_RoomEvent{
+				RoomEvent: &pb.RoomEventNotification{
+					RoomId:    room.ID,
+					Phase:     phase,
+					Event:     info,
+					Timestamp: now.Unix(),
+				},
+			},
+		}, nil)
+	}
+}
+
+// publishBoard 把正在進行和 lookahead 內開始的活動寫入大廳活動看板
+func (s *RoomEventScheduler) publishBoard(ctx context.Context, now time.Time) {
+	s.schedulesMu.RLock()
+	events := game.RoomEventsBetween(s.schedules, now, now.Add(s.lookahead))
+	s.schedulesMu.RUnlock()
+
+	board := make([]*lobby.RoomEvent, 0, len(events))
+	for _, event := range events {
+		board = append(board, lobbyRoomEvent(event, now))
+	}
+	// 看板在節點全部停止後過期，不會一直顯示已停止舉行的活動
+	if err := s.board.PublishRoomEvents(ctx, board, 3*s.reloadInterval); err != nil {
+		s.logger.Warnf("Failed to publish room event board: %v", err)
+	}
+}
+
+// lobbyRoomEvent 轉換為大廳活動看板的活動
+func lobbyRoomEvent(event *game.RoomEvent, now time.Time) *lobby.RoomEvent {
+	roomTypes := make([]string, 0, len(event.RoomTypes))
+	for _, rt := range event.RoomTypes {
+		roomTypes = append(roomTypes, string(rt))
+	}
+	return &lobby.RoomEvent{
+		ID:               event.ID,
+		ScheduleID:       event.ScheduleID,
+		Name:             event.Name,
+		Type:             string(event.Type),
+		RoomTypes:        roomTypes,
+		StartsAt:         event.StartsAt,
+		EndsAt:           event.EndsAt,
+		RewardMultiplier: event.RewardMultiplier,
+		Announcement:     event.Announcement,
+		Active:           !event.StartsAt.After(now) && event.EndsAt.After(now),
+	}
+}
+
+// roomEventInfo 轉換為下發給客戶端的活動信息
+func roomEventInfo(event *game.RoomEvent) *pb.RoomEventInfo {
+	return &pb.RoomEventInfo{
+		EventId:          event.ID,
+		ScheduleId:       event.ScheduleID,
+		Name:             event.Name,
+		Type:             string(event.Type),
+		StartsAt:         event.StartsAt.Unix(),
+		EndsAt:           event.EndsAt.Unix(),
+		RewardMultiplier: event.RewardMultiplier,
+		Announcement:     event.Announcement,
+	}
+}
+
+// roomEventInfos 轉換活動列表，沒有活動時返回 nil
+func roomEventInfos(events []*game.RoomEvent) []*pb.RoomEventInfo {
+	if len(events) == 0 {
+		return nil
+	}
+	infos := make([]*pb.RoomEventInfo, 0, len(events))
+	for _, event := range events {
+		infos = append(infos, roomEventInfo(event))
+	}
+	return infos
+}
+
+// activeEvents 對房間生效的活動，隨房間狀態下發給客戶端
+func (rm *RoomManager) activeEvents() []*game.RoomEvent {
+	if rm.businessRoomID == "" {
+		return nil
+	}
+	return rm.gameUsecase.RoomEventsForRoom(rm.businessRoomID)
+}
@@ -0,0 +1,580 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryRoomEventRepo 內存活動排程存儲
+type memoryRoomEventRepo struct {
+	mu        sync.Mutex
+	schedules map[int64]*game.RoomEventSchedule
+	nextID    int64
+	listErr   error
+}
+
+func newMemoryRoomEventRepo(schedules ...*game.RoomEventSchedule) *memoryRoomEventRepo {
+	r := &memoryRoomEventRepo{schedules: make(map[int64]*game.RoomEventSchedule)}
+	for _, s := range schedules {
+		r.CreateRoomEventSchedule(context.Background(), s)
+	}
+	return r
+}
+
+func (r *memoryRoomEventRepo) ListRoomEventSchedules(ctx context.Context) ([]*game.RoomEventSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.listErr != nil {
+		return nil, r.listErr
+	}
+	schedules := make([]*game.RoomEventSchedule, 0, len(r.schedules))
+	for id := int64(1); id <= r.nextID; id++ {
+		if s, ok := r.schedules[id]; ok {
+			copied := *s
+			schedules = append(schedules, &copied)
+		}
+	}
+	return schedules, nil
+}
+
+func (r *memoryRoomEventRepo) GetRoomEventSchedule(ctx context.Context, id int64) (*game.RoomEventSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.schedules[id]
+	if !ok {
+		return nil, game.ErrRoomEventNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *memoryRoomEventRepo) CreateRoomEventSchedule(ctx context.Context, s *game.RoomEventSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	s.ID = r.nextID
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	copied := *s
+	r.schedules[s.ID] = &copied
+	return nil
+}
+
+func (r *memoryRoomEventRepo) UpdateRoomEventSchedule(ctx context.Context, s *game.RoomEventSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.schedules[s.ID]; !ok {
+		return game.ErrRoomEventNotFound
+	}
+	s.UpdatedAt = time.Now()
+	copied := *s
+	r.schedules[s.ID] = &copied
+	return nil
+}
+
+func (r *memoryRoomEventRepo) DeleteRoomEventSchedule(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.schedules[id]; !ok {
+		return game.ErrRoomEventNotFound
+	}
+	delete(r.schedules, id)
+	return nil
+}
+
+// memoryRoomEventBoard 內存大廳活動看板
+type memoryRoomEventBoard struct {
+	mu        sync.Mutex
+	events    []*lobby.RoomEvent
+	ttl       time.Duration
+	publishes int
+}
+
+func (b *memoryRoomEventBoard) PublishRoomEvents(ctx context.Context, events []*lobby.RoomEvent, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = events
+	b.ttl = ttl
+	b.publishes++
+	return nil
+}
+
+func (b *memoryRoomEventBoard) GetRoomEvents(ctx context.Context) ([]*lobby.RoomEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.events, nil
+}
+
+func (b *memoryRoomEventBoard) snapshot() ([]*lobby.RoomEvent, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.events, b.publishes
+}
+
+// mustLocation 加載測試使用的時區
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+// doubleRewardSchedule 對新手房生效的雙倍獎勵排程
+func doubleRewardSchedule(startAt time.Time, minutes int, recurrence game.Recurrence) *game.RoomEventSchedule {
+	return &game.RoomEventSchedule{
+		Name:             "雙倍獎勵時段",
+		Type:             game.RoomEventDoubleReward,
+		RoomTypes:        []game.RoomType{game.RoomTypeNovice},
+		StartAt:          startAt,
+		DurationMinutes:  minutes,
+		Timezone:         "UTC",
+		Recurrence:       recurrence,
+		RewardMultiplier: 2,
+		Announcement:     "雙倍獎勵開始了",
+		Enabled:          true,
+	}
+}
+
+func TestRoomEventOccurrences(t *testing.T) {
+	t.Run("daily keeps local start time across DST", func(t *testing.T) {
+		ny := mustLocation(t, "America/New_York")
+		s := doubleRewardSchedule(time.Date(2026, 3, 6, 20, 0, 0, 0, ny), 60, game.Recurrence{Frequency: game.RecurrenceDaily})
+		s.Timezone = "America/New_York"
+
+		starts := s.Occurrences(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+		require.Len(t, starts, 3)
+		for _, start := range starts {
+			assert.Equal(t, 20, start.In(ny).Hour())
+		}
+		// 3 月 8 日起為夏令時，UTC 開始時間提前一小時
+		assert.Equal(t, 1, starts[0].UTC().Hour())
+		assert.Equal(t, 0, starts[2].UTC().Hour())
+	})
+
+	t.Run("weekly on chosen weekdays every other week", func(t *testing.T) {
+		// 2026-10-05 為週一
+		start := time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)
+		require.Equal(t, time.Monday, start.Weekday())
+		s := doubleRewardSchedule(start, 30, game.Recurrence{
+			Frequency: game.RecurrenceWeekly,
+			Interval:  2,
+			Weekdays:  []time.Weekday{time.Wednesday, time.Monday},
+		})
+
+		starts := s.Occurrences(start, start.AddDate(0, 0, 28))
+		var days []string
+		for _, start := range starts {
+			days = append(days, start.Format("01-02 Mon"))
+		}
+		assert.Equal(t, []string{"10-05 Mon", "10-07 Wed", "10-19 Mon", "10-21 Wed"}, days)
+	})
+
+	t.Run("weekly skips weekdays before the first start", func(t *testing.T) {
+		// 首場在週三，同一週的週一不舉行
+		start := time.Date(2026, 10, 7, 12, 0, 0, 0, time.UTC)
+		s := doubleRewardSchedule(start, 30, game.Recurrence{
+			Frequency: game.RecurrenceWeekly,
+			Weekdays:  []time.Weekday{time.Monday, time.Wednesday},
+		})
+
+		starts := s.Occurrences(start.AddDate(0, 0, -7), start.AddDate(0, 0, 7))
+		require.Len(t, starts, 2)
+		assert.Equal(t, start, starts[0])
+		assert.Equal(t, time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC), starts[1])
+	})
+
+	t.Run("monthly skips months without the day", func(t *testing.T) {
+		start := time.Date(2026, 1, 31, 18, 0, 0, 0, time.UTC)
+		s := doubleRewardSchedule(start, 120, game.Recurrence{Frequency: game.RecurrenceMonthly})
+
+		starts := s.Occurrences(start, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+		var months []time.Month
+		for _, start := range starts {
+			assert.Equal(t, 31, start.Day())
+			months = append(months, start.Month())
+		}
+		assert.Equal(t, []time.Month{time.January, time.March, time.May}, months)
+	})
+
+	t.Run("count and until end the recurrence", func(t *testing.T) {
+		start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		counted := doubleRewardSchedule(start, 60, game.Recurrence{Frequency: game.RecurrenceDaily, Count: 3})
+		assert.Len(t, counted.Occurrences(start, start.AddDate(0, 0, 30)), 3)
+
+		until := start.AddDate(0, 0, 4)
+		limited := doubleRewardSchedule(start, 60, game.Recurrence{Frequency: game.RecurrenceDaily, Until: &until})
+		assert.Len(t, limited.Occurrences(start, start.AddDate(0, 0, 30)), 5)
+	})
+
+	t.Run("one-off event includes a running occurrence", func(t *testing.T) {
+		start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		s := doubleRewardSchedule(start, 60, game.Recurrence{Frequency: game.RecurrenceNone})
+
+		assert.Len(t, s.Occurrences(start.Add(30*time.Minute), start.AddDate(0, 1, 0)), 1)
+		assert.Empty(t, s.Occurrences(start.Add(time.Hour), start.AddDate(0, 1, 0)))
+		assert.Empty(t, s.Occurrences(start.Add(-time.Hour), start))
+	})
+
+	t.Run("active events skip disabled schedules", func(t *testing.T) {
+		start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		enabled := doubleRewardSchedule(start, 60, game.Recurrence{Frequency: game.RecurrenceDaily})
+		enabled.ID = 1
+		disabled := doubleRewardSchedule(start, 60, game.Recurrence{Frequency: game.RecurrenceDaily})
+		disabled.ID = 2
+		disabled.Enabled = false
+
+		active := game.ActiveRoomEventsAt([]*game.RoomEventSchedule{enabled, disabled}, start.AddDate(0, 0, 2).Add(10*time.Minute))
+		require.Len(t, active, 1)
+		assert.Equal(t, "1-1791018000", active[0].ID)
+		assert.Equal(t, start.AddDate(0, 0, 2), active[0].StartsAt)
+		assert.Equal(t, start.AddDate(0, 0, 2).Add(time.Hour), active[0].EndsAt)
+
+		assert.Empty(t, game.ActiveRoomEventsAt([]*game.RoomEventSchedule{enabled}, start.AddDate(0, 0, 2).Add(time.Hour)))
+	})
+}
+
+func TestValidateRoomEventSchedule(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	valid := func() *game.RoomEventSchedule {
+		return doubleRewardSchedule(start, 60, game.Recurrence{Frequency: game.RecurrenceDaily, Interval: 1})
+	}
+	require.NoError(t, game.ValidateRoomEventSchedule(valid()))
+
+	tide := &game.RoomEventSchedule{
+		Name: "魚潮風暴", Type: game.RoomEventTideStorm, StartAt: start, DurationMinutes: 10, Timezone: "Asia/Taipei",
+		Recurrence: game.Recurrence{Frequency: game.RecurrenceNone}, TideFishTypeID: 33, TideWaveSize: 10, TideWaveSeconds: 3,
+	}
+	require.NoError(t, game.ValidateRoomEventSchedule(tide))
+
+	tests := []struct {
+		name   string
+		modify func(s *game.RoomEventSchedule)
+		reason string
+	}{
+		{"missing name", func(s *game.RoomEventSchedule) { s.Name = "" }, "name is required"},
+		{"unknown type", func(s *game.RoomEventSchedule) { s.Type = "jackpot" }, "unknown event type"},
+		{"unknown room type", func(s *game.RoomEventSchedule) { s.RoomTypes = []game.RoomType{"lava"} }, "unknown room type"},
+		{"multiplier not a boost", func(s *game.RoomEventSchedule) { s.RewardMultiplier = 1 }, "reward_multiplier"},
+		{"multiplier too high", func(s *game.RoomEventSchedule) { s.RewardMultiplier = 20 }, "reward_multiplier"},
+		{"no duration", func(s *game.RoomEventSchedule) { s.DurationMinutes = 0 }, "duration_minutes"},
+		{"unknown timezone", func(s *game.RoomEventSchedule) { s.Timezone = "Mars/Olympus" }, "unknown timezone"},
+		{"overlapping daily occurrences", func(s *game.RoomEventSchedule) { s.DurationMinutes = 25 * 60 }, "time between occurrences"},
+		{"overlapping weekly occurrences", func(s *game.RoomEventSchedule) {
+			s.Recurrence = game.Recurrence{Frequency: game.RecurrenceWeekly, Weekdays: []time.Weekday{time.Saturday, time.Sunday}}
+			s.DurationMinutes = 36 * 60
+		}, "time between occurrences"},
+		{"weekdays on daily", func(s *game.RoomEventSchedule) { s.Recurrence.Weekdays = []time.Weekday{time.Monday} }, "weekdays"},
+		{"until before start", func(s *game.RoomEventSchedule) {
+			until := start.Add(-time.Hour)
+			s.Recurrence.Until = &until
+		}, "recurrence.until"},
+		{"unknown frequency", func(s *game.RoomEventSchedule) { s.Recurrence.Frequency = "hourly" }, "unknown recurrence frequency"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(s)
+			err := game.ValidateRoomEventSchedule(s)
+			require.ErrorIs(t, err, game.ErrInvalidRoomEvent)
+			assert.Contains(t, err.Error(), tt.reason)
+		})
+	}
+
+	t.Run("tide storm needs a known fish type", func(t *testing.T) {
+		s := *tide
+		s.TideFishTypeID = 99
+		s.TideWaveSize = 100
+		err := game.ValidateRoomEventSchedule(&s)
+		require.ErrorIs(t, err, game.ErrInvalidRoomEvent)
+		assert.Contains(t, err.Error(), "tide_fish_type_id")
+		assert.Contains(t, err.Error(), "tide_wave_size")
+	})
+}
+
+func TestSetActiveRoomEvents(t *testing.T) {
+	gu, _ := newTestRoomUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+
+	novice, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	vip, err := gu.CreateRoom(ctx, game.RoomTypeVIP, 4)
+	require.NoError(t, err)
+
+	start := time.Now().Add(-time.Minute)
+	noviceOnly := &game.RoomEvent{ID: "1-a", Type: game.RoomEventDoubleReward, RoomTypes: []game.RoomType{game.RoomTypeNovice}, StartsAt: start, RewardMultiplier: 2}
+	everywhere := &game.RoomEvent{ID: "2-a", Type: game.RoomEventBossRush, StartsAt: start.Add(time.Second)}
+
+	started, ended := gu.SetActiveRoomEvents([]*game.RoomEvent{everywhere, noviceOnly})
+	assert.Equal(t, []*game.RoomEvent{noviceOnly, everywhere}, started)
+	assert.Empty(t, ended)
+
+	assert.Equal(t, []*game.RoomEvent{noviceOnly, everywhere}, gu.RoomEventsForRoom(novice.ID))
+	assert.Equal(t, []*game.RoomEvent{everywhere}, gu.RoomEventsForRoom(vip.ID))
+	assert.Nil(t, gu.RoomEventsForRoom("missing"))
+
+	// 同一組活動不會重複開始
+	started, ended = gu.SetActiveRoomEvents([]*game.RoomEvent{noviceOnly, everywhere})
+	assert.Empty(t, started)
+	assert.Empty(t, ended)
+
+	started, ended = gu.SetActiveRoomEvents([]*game.RoomEvent{everywhere})
+	assert.Empty(t, started)
+	assert.Equal(t, []*game.RoomEvent{noviceOnly}, ended)
+	assert.Equal(t, []*game.RoomEvent{everywhere}, gu.ActiveRoomEvents(game.RoomTypeNovice))
+}
+
+func TestRoomEventEffects(t *testing.T) {
+	t.Run("double reward boosts and tags kills", func(t *testing.T) {
+		gu, _ := newTestRoomUsecase(t)
+		closeAllRooms(t, gu)
+		ctx := context.Background()
+
+		room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+		require.NoError(t, err)
+		require.NoError(t, gu.JoinRoomWithPlayer(ctx, room.ID, &game.Player{ID: -1, Balance: 1_000_000}))
+		gu.SetActiveRoomEvents([]*game.RoomEvent{{
+			ID: "7-1", Type: game.RoomEventDoubleReward, StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour), RewardMultiplier: 2,
+		}})
+
+		var kill *game.HitResult
+		require.Eventually(t, func() bool {
+			current, err := gu.GetRoom(ctx, room.ID)
+			if err != nil {
+				return false
+			}
+			for fishID := range current.Fishes {
+				bullet, err := gu.FireBullet(ctx, room.ID, -1, 0, 100, game.Position{}, fishID)
+				if err != nil {
+					return false
+				}
+				if hit, err := gu.HitFish(ctx, room.ID, bullet.ID, fishID); err == nil && hit.Reward > 0 {
+					kill = hit
+					return true
+				}
+			}
+			return false
+		}, 5*time.Second, 100*time.Millisecond)
+
+		assert.Equal(t, "7-1", kill.EventID)
+		assert.Equal(t, game.RoomEventDoubleReward, kill.EventType)
+		assert.Positive(t, kill.EventBonus)
+		assert.Equal(t, 2*kill.EventBonus, kill.Reward)
+	})
+
+	t.Run("tide storm spawns waves of the configured fish", func(t *testing.T) {
+		gu, _ := newTestRoomUsecase(t)
+		closeAllRooms(t, gu)
+		ctx := context.Background()
+
+		room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+		require.NoError(t, err)
+		gu.SetActiveRoomEvents([]*game.RoomEvent{{
+			ID: "8-1", Type: game.RoomEventTideStorm, StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour),
+			TideFishTypeID: 33, TideWaveSize: 6, TideWaveInterval: time.Hour,
+		}})
+
+		require.Eventually(t, func() bool {
+			current, err := gu.GetRoom(ctx, room.ID)
+			if err != nil {
+				return false
+			}
+			tideFish := 0
+			for _, fish := range current.Fishes {
+				if fish.Type.ID == 33 {
+					tideFish++
+				}
+			}
+			return tideFish >= 6
+		}, 3*time.Second, 50*time.Millisecond)
+	})
+}
+
+func TestGameRecordRecordEventPayout(t *testing.T) {
+	record := &game.GameRecord{}
+	record.RecordEventPayout("7-1", game.RoomEventDoubleReward, 1.5)
+	record.RecordEventPayout("7-1", game.RoomEventDoubleReward, 2.5)
+	record.RecordEventPayout("9-1", game.RoomEventDoubleReward, 1)
+
+	events := record.Metadata["events"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "double_reward", "catches": 2.0, "bonus": 4.0}, events["7-1"])
+	assert.Equal(t, map[string]interface{}{"type": "double_reward", "catches": 1.0, "bonus": 1.0}, events["9-1"])
+}
+
+func TestRoomEventUsecase(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRoomEventRepo()
+	uc := game.NewRoomEventUsecase(repo, logger.New(os.Stdout, "error", "console"))
+
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	tide := &game.RoomEventSchedule{
+		Name: " 魚潮風暴 ", Type: game.RoomEventTideStorm, StartAt: start, DurationMinutes: 15,
+		TideFishTypeID: 33, Enabled: true,
+	}
+	require.NoError(t, uc.Create(ctx, tide))
+	assert.Equal(t, int64(1), tide.ID)
+	// 未填寫的字段使用默認值
+	assert.Equal(t, "魚潮風暴", tide.Name)
+	assert.Equal(t, "UTC", tide.Timezone)
+	assert.Equal(t, game.RecurrenceNone, tide.Recurrence.Frequency)
+	assert.Equal(t, 10, tide.TideWaveSize)
+	assert.Equal(t, 3, tide.TideWaveSeconds)
+
+	invalid := doubleRewardSchedule(start, 60, game.Recurrence{})
+	invalid.RewardMultiplier = 0
+	require.ErrorIs(t, uc.Create(ctx, invalid), game.ErrInvalidRoomEvent)
+
+	daily := doubleRewardSchedule(start, 60, game.Recurrence{Frequency: game.RecurrenceDaily})
+	require.NoError(t, uc.Create(ctx, daily))
+
+	upcoming, err := uc.Upcoming(ctx, start, start.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	require.Len(t, upcoming, 3)
+	assert.Equal(t, tide.ID, upcoming[0].ScheduleID)
+	assert.Equal(t, daily.ID, upcoming[1].ScheduleID)
+
+	missing := doubleRewardSchedule(start, 60, game.Recurrence{})
+	missing.ID = 99
+	require.ErrorIs(t, uc.Update(ctx, missing), game.ErrRoomEventNotFound)
+	require.NoError(t, uc.Delete(ctx, tide.ID, "admin:1"))
+	_, err = uc.Get(ctx, tide.ID)
+	require.ErrorIs(t, err, game.ErrRoomEventNotFound)
+}
+
+func TestRoomEventScheduler(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	ctx := context.Background()
+
+	// roomClient 在 hub 中為房間註冊一個連接
+	roomClient := func(hub *Hub, roomID string) *Client {
+		client := NewClient(nil, hub, log)
+		client.RoomID = roomID
+		hub.rooms[roomID] = map[*Client]bool{client: true}
+		return client
+	}
+	// nextRoomEvent 讀取連接收到的下一條 ROOM_EVENT
+	nextRoomEvent := func(t *testing.T, client *Client) *pb.RoomEventNotification {
+		t.Helper()
+		select {
+		case data := <-client.send:
+			var msg pb.GameMessage
+			require.NoError(t, proto.Unmarshal(data, &msg))
+			require.Equal(t, pb.MessageType_ROOM_EVENT, msg.Type)
+			return msg.GetRoomEvent()
+		case <-time.After(time.Second):
+			t.Fatal("no room event broadcast")
+			return nil
+		}
+	}
+
+	t.Run("announces start and end to affected rooms", func(t *testing.T) {
+		gu, _ := newTestRoomUsecase(t)
+		closeAllRooms(t, gu)
+
+		novice, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+		require.NoError(t, err)
+		vip, err := gu.CreateRoom(ctx, game.RoomTypeVIP, 4)
+		require.NoError(t, err)
+
+		now := time.Now().Truncate(time.Second)
+		schedule := doubleRewardSchedule(now.Add(-10*time.Minute), 60, game.Recurrence{Frequency: game.RecurrenceDaily})
+		repo := newMemoryRoomEventRepo(schedule)
+		board := &memoryRoomEventBoard{}
+		hub := NewHub(gu, nil, nil, nil, log)
+		noviceClient := roomClient(hub, novice.ID)
+		vipClient := roomClient(hub, vip.ID)
+
+		config := &conf.Config{Game: &conf.Game{RoomEvents: &conf.RoomEvents{Enabled: true, ReloadInterval: 30, LookaheadHours: 48}}}
+		scheduler := NewRoomEventScheduler(repo, board, hub, gu, config, log)
+		require.NoError(t, scheduler.Load(ctx))
+
+		scheduler.Tick(ctx, now)
+		event := nextRoomEvent(t, noviceClient)
+		assert.Equal(t, "started", event.Phase)
+		assert.Equal(t, novice.ID, event.RoomId)
+		assert.Equal(t, "double_reward", event.Event.Type)
+		assert.Equal(t, 2.0, event.Event.RewardMultiplier)
+		assert.Equal(t, "雙倍獎勵開始了", event.Event.Announcement)
+		assert.Empty(t, vipClient.send)
+		require.Len(t, gu.ActiveRoomEvents(game.RoomTypeNovice), 1)
+
+		// 看板包含正在進行的場次和 lookahead 內的下一場
+		events, publishes := board.snapshot()
+		assert.Equal(t, 1, publishes)
+		require.Len(t, events, 3)
+		assert.True(t, events[0].Active)
+		assert.False(t, events[1].Active)
+		assert.Equal(t, []string{"novice"}, events[0].RoomTypes)
+		assert.Equal(t, 90*time.Second, board.ttl)
+
+		// 活動沒有變化時不重複廣播
+		scheduler.Tick(ctx, now.Add(time.Second))
+		assert.Empty(t, noviceClient.send)
+		_, publishes = board.snapshot()
+		assert.Equal(t, 1, publishes)
+
+		scheduler.Tick(ctx, now.Add(time.Hour))
+		event = nextRoomEvent(t, noviceClient)
+		assert.Equal(t, "ended", event.Phase)
+		assert.Empty(t, gu.ActiveRoomEvents(game.RoomTypeNovice))
+	})
+
+	t.Run("load failure keeps current schedules", func(t *testing.T) {
+		gu, _ := newTestRoomUsecase(t)
+		now := time.Now()
+		repo := newMemoryRoomEventRepo(doubleRewardSchedule(now.Add(-time.Minute), 60, game.Recurrence{}))
+		scheduler := NewRoomEventScheduler(repo, &memoryRoomEventBoard{}, NewHub(gu, nil, nil, nil, log), gu, nil, log)
+		require.NoError(t, scheduler.Load(ctx))
+
+		repo.mu.Lock()
+		repo.listErr = errors.New("database unavailable")
+		repo.mu.Unlock()
+		require.Error(t, scheduler.Load(ctx))
+
+		scheduler.Tick(ctx, now)
+		assert.Len(t, gu.ActiveRoomEvents(game.RoomTypeNovice), 1)
+	})
+
+	t.Run("start applies running events and stop is idempotent", func(t *testing.T) {
+		gu, _ := newTestRoomUsecase(t)
+		repo := newMemoryRoomEventRepo(doubleRewardSchedule(time.Now().Add(-time.Minute), 60, game.Recurrence{}))
+		board := &memoryRoomEventBoard{}
+		scheduler := NewRoomEventScheduler(repo, board, NewHub(gu, nil, nil, nil, log), gu, nil, log)
+
+		scheduler.Start()
+		defer scheduler.Stop()
+		assert.Len(t, gu.ActiveRoomEvents(game.RoomTypeNovice), 1)
+		events, _ := board.snapshot()
+		assert.Len(t, events, 1)
+
+		scheduler.Stop()
+		scheduler.Stop()
+	})
+
+	t.Run("disabled scheduler does nothing", func(t *testing.T) {
+		gu, _ := newTestRoomUsecase(t)
+		repo := newMemoryRoomEventRepo(doubleRewardSchedule(time.Now().Add(-time.Minute), 60, game.Recurrence{}))
+		board := &memoryRoomEventBoard{}
+		config := &conf.Config{Game: &conf.Game{RoomEvents: &conf.RoomEvents{Enabled: false, ReloadInterval: 30, LookaheadHours: 24}}}
+		scheduler := NewRoomEventScheduler(repo, board, NewHub(gu, nil, nil, nil, log), gu, config, log)
+
+		scheduler.Start()
+		defer scheduler.Stop()
+		assert.Empty(t, gu.ActiveRoomEvents(game.RoomTypeNovice))
+		_, publishes := board.snapshot()
+		assert.Zero(t, publishes)
+	})
+}
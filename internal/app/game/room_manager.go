@@ -67,7 +67,7 @@ type RoomManager struct {
 // GameState 房間遊戲狀態
 type GameState struct {
 	RoomID        string                 `json:"room_id"`
-	Status        string                 `json:"status"` // waiting, playing, paused
+	Status        string                 `json:"status"` // 與業務層房間狀態相同：waiting、playing
 	MaxPlayers    int                    `json:"max_players"` // 最大玩家數（座位數）
 	Players       map[string]*PlayerInfo `json:"players"`
 	Fishes        map[int64]*FishInfo    `json:"fishes"`
@@ -159,7 +159,7 @@ func NewRoomManager(roomID string, gameUsecase *game.GameUsecase, hub *Hub, logg
 func NewGameState(roomID string, maxPlayers int) *GameState {
 	return &GameState{
 		RoomID:        roomID,
-		Status:        "waiting",
+		Status:        string(game.RoomStatusWaiting),
 		MaxPlayers:    maxPlayers,
 		Players:       make(map[string]*PlayerInfo),
 		Fishes:        make(map[int64]*FishInfo),
@@ -198,6 +198,8 @@ func (rm *RoomManager) Run() {
 				}()
 				rm.gameLoop()
 			}()
+			// 本 tick 的廣播已排入 Hub，通知批量模式的客戶端送出
+			rm.hub.FlushRoomTick(rm.roomID)
 			
 		case client := <-rm.addClient:
 			rm.logger.Debugf("Handling add client for room: %s", rm.roomID)
@@ -267,7 +269,7 @@ func (rm *RoomManager) HandleGameAction(action *GameActionMessage) {
 	default:
 		rm.logger.Errorf("Failed to handle game action for room %s: gameAction channel full", rm.roomID)
 		if action.Client != nil {
-			action.Client.sendError("Server is busy, please try again")
+			action.Client.sendError(pb.ErrorCode_REQUEST_TIMEOUT, "Server is busy, please try again")
 		}
 	}
 }
@@ -286,6 +288,12 @@ func (rm *RoomManager) handleAddClient(client *Client) {
 	// 取消空閒回收定時器（如果存在）
 	rm.cancelEmptyRoomTimer()
 
+	// 觀戰者只接收遊戲狀態，不加入玩家列表
+	if client.Spectating {
+		rm.sendGameStateToClient(client)
+		return
+	}
+
 	// 添加玩家到遊戲狀態
 	playerInfo := &PlayerInfo{
 		ID:       client.ID,
@@ -308,7 +316,7 @@ func (rm *RoomManager) handleAddClient(client *Client) {
 	rm.gameState.Players[client.ID] = playerInfo
 
 	// 如果是第一個玩家且遊戲未開始，開始遊戲
-	if len(rm.gameState.Players) == 1 && rm.gameState.Status == "waiting" {
+	if len(rm.gameState.Players) == 1 && rm.gameState.Status == string(game.RoomStatusWaiting) {
 		rm.startGame()
 	}
 
@@ -342,7 +350,7 @@ func (rm *RoomManager) handleGameAction(action *GameActionMessage) {
 		if r := recover(); r != nil {
 			rm.logger.Errorf("Recovered from panic in handleGameAction: %v", r)
 			if action.Client != nil {
-				action.Client.sendError("Error processing game action")
+				action.Client.sendError(pb.ErrorCode_INTERNAL_ERROR, "Error processing game action")
 			}
 		}
 	}()
@@ -366,7 +374,7 @@ func (rm *RoomManager) handleGameAction(action *GameActionMessage) {
 		rm.handleSelectSeat(action)
 	default:
 		rm.logger.Warnf("Unknown game action: %s", action.Action)
-		action.Client.sendError(fmt.Sprintf("Unknown action: %s", action.Action))
+		action.Client.sendError(pb.ErrorCode_UNSUPPORTED_MESSAGE, fmt.Sprintf("Unknown action: %s", action.Action))
 	}
 }
 
@@ -377,19 +385,19 @@ func (rm *RoomManager) handleFireBullet(action *GameActionMessage) {
 	// 檢查玩家是否在房間中
 	playerInfo, exists := rm.gameState.Players[client.ID]
 	if !exists {
-		client.sendError("Player not in game")
+		client.sendError(pb.ErrorCode_NOT_IN_ROOM, "Player not in game")
 		return
 	}
 
 	if playerInfo.SeatID == -1 {
-		client.sendError("Please select a seat first")
+		client.sendError(pb.ErrorCode_SEAT_REQUIRED, "Please select a seat first")
 		return
 	}
 
 	// 解析消息
 	gameMsg, ok := action.Data.(*pb.GameMessage)
 	if !ok {
-		client.sendError("Invalid message format")
+		client.sendError(pb.ErrorCode_INVALID_MESSAGE, "Invalid message format")
 		return
 	}
 
@@ -422,7 +430,7 @@ func (rm *RoomManager) handleFireBullet(action *GameActionMessage) {
 
 	// 檢查玩家餘額
 	if playerInfo.Balance < bulletCost {
-		client.sendError("Insufficient balance")
+		client.sendError(pb.ErrorCode_INSUFFICIENT_BALANCE, "Insufficient balance")
 		return
 	}
 
@@ -494,14 +502,14 @@ func (rm *RoomManager) handleSwitchCannon(action *GameActionMessage) {
 	// 檢查玩家是否在房間中
 	playerInfo, exists := rm.gameState.Players[client.ID]
 	if !exists {
-		client.sendError("Player not in game")
+		client.sendError(pb.ErrorCode_NOT_IN_ROOM, "Player not in game")
 		return
 	}
 
 	// 解析 Protobuf 消息獲取砲台信息
 	gameMsg, ok := action.Data.(*pb.GameMessage)
 	if !ok {
-		client.sendError("Invalid message format")
+		client.sendError(pb.ErrorCode_INVALID_MESSAGE, "Invalid message format")
 		return
 	}
 
@@ -567,21 +575,21 @@ func (rm *RoomManager) handleSelectSeat(action *GameActionMessage) {
 	// 檢查玩家是否在房間中
 	playerInfo, exists := rm.gameState.Players[client.ID]
 	if !exists {
-		client.sendError("Player not in game")
+		client.sendError(pb.ErrorCode_NOT_IN_ROOM, "Player not in game")
 		return
 	}
 
 	// 解析 Protobuf 消息獲取座位信息
 	gameMsg, ok := action.Data.(*pb.GameMessage)
 	if !ok {
-		client.sendError("Invalid message format")
+		client.sendError(pb.ErrorCode_INVALID_MESSAGE, "Invalid message format")
 		return
 	}
 
 	// 獲取選擇的座位 ID
 	selectData := gameMsg.GetSelectSeat()
 	if selectData == nil {
-		client.sendError("Invalid select seat data")
+		client.sendError(pb.ErrorCode_INVALID_MESSAGE, "Invalid select seat data")
 		return
 	}
 
@@ -590,14 +598,14 @@ func (rm *RoomManager) handleSelectSeat(action *GameActionMessage) {
 	// 驗證座位 ID 範圍（根據房間配置動態驗證）
 	maxSeatID := int32(rm.gameState.MaxPlayers - 1)
 	if requestedSeatID < 0 || requestedSeatID > maxSeatID {
-		client.sendError(fmt.Sprintf("Invalid seat ID, must be between 0 and %d", maxSeatID))
+		client.sendError(pb.ErrorCode_INVALID_SEAT, fmt.Sprintf("Invalid seat ID, must be between 0 and %d", maxSeatID))
 		return
 	}
 
 	// 檢查座位是否已被佔用
 	for _, p := range rm.gameState.Players {
 		if p.SeatID == int(requestedSeatID) && p.ID != client.ID {
-			client.sendError("Seat already taken")
+			client.sendError(pb.ErrorCode_SEAT_TAKEN, "Seat already taken")
 			return
 		}
 	}
@@ -628,7 +636,7 @@ func (rm *RoomManager) handleSelectSeat(action *GameActionMessage) {
 
 // gameLoop 遊戲主循環
 func (rm *RoomManager) gameLoop() {
-	if rm.gameState.Status != "playing" {
+	if rm.gameState.Status != string(game.RoomStatusPlaying) {
 		// 記錄非運行狀態
 		rm.logger.Warnf("Game loop called but status is '%s' in room %s", rm.gameState.Status, rm.roomID)
 		return
@@ -904,10 +912,10 @@ func (rm *RoomManager) cleanupExpiredObjects() {
 // startGame 開始遊戲
 func (rm *RoomManager) startGame() {
 	rm.logger.Infof("Starting game in room: %s", rm.roomID)
-	rm.gameState.Status = "playing"
+	if !rm.setStatus(game.RoomStatusPlaying) {
+		return
+	}
 	rm.gameState.GameStartTime = time.Now()
-	rm.logger.Infof("Game state changed to 'playing' for room: %s", rm.roomID)
-	rm.logger.Infof("Ticker should start working now. Status: %s", rm.gameState.Status)
 
 	// 同步創建業務邏輯層的房間（不能異步，否則 syncFishesFromBizLayer 會失敗）
 	// 注意：業務邏輯層的房間 ID 和 WebSocket 房間 ID 不同
@@ -941,13 +949,27 @@ func (rm *RoomManager) startGame() {
 
 // pauseGame 暫停遊戲
 func (rm *RoomManager) pauseGame() {
-	rm.gameState.Status = "waiting"
+	if !rm.setStatus(game.RoomStatusWaiting) {
+		return
+	}
 
 	// 不再發送 game_paused JSON 事件，前端已經通過 ROOM_STATE_UPDATE 知道遊戲狀態
 
 	rm.logger.Infof("Game paused in room: %s", rm.roomID)
 }
 
+// setStatus 按業務層的生命週期規則轉換遊戲狀態，轉換不合法時記錄並返回 false
+func (rm *RoomManager) setStatus(to game.RoomStatus) bool {
+	from := game.RoomStatus(rm.gameState.Status)
+	if !game.CanTransition(from, to) {
+		rm.logger.Warnf("Invalid game state transition in room %s: %s -> %s", rm.roomID, from, to)
+		return false
+	}
+	rm.gameState.Status = string(to)
+	rm.logger.Infof("Game state changed in room %s: %s -> %s", rm.roomID, from, to)
+	return true
+}
+
 // sendGameStateToClient 發送遊戲狀態給特定客戶端
 func (rm *RoomManager) sendGameStateToClient(client *Client) {
 	// 使用與廣播相同的 Protobuf 格式
@@ -999,13 +1021,16 @@ func (rm *RoomManager) sendGameStateProtobufToClient(client *Client) {
 
 	// 創建房間狀態更新消息
 	roomStateUpdate := &pb.RoomStateUpdate{
-		RoomId:       rm.roomID,
-		Fishes:       fishInfos,
-		Bullets:      bulletInfos,
-		Seats:        seatInfos,
-		PlayerCount:  int32(len(rm.gameState.Players)),
-		Timestamp:    time.Now().Unix(),
-		RoomStatus:   rm.gameState.Status,
+		RoomId:         rm.roomID,
+		Fishes:         fishInfos,
+		Bullets:        bulletInfos,
+		Seats:          seatInfos,
+		PlayerCount:    int32(len(rm.gameState.Players)),
+		Timestamp:      time.Now().Unix(),
+		RoomStatus:     rm.gameState.Status,
+		SpectatorCount: rm.spectatorCount(),
+		ConfigVersion:  rm.configVersion(),
+		Events:         roomEventInfos(rm.activeEvents()),
 	}
 
 	// 創建 GameMessage
@@ -1082,13 +1107,16 @@ func (rm *RoomManager) broadcastGameStateProtobuf() {
 
 	// 創建房間狀態更新消息
 	roomStateUpdate := &pb.RoomStateUpdate{
-		RoomId:       rm.roomID,
-		Fishes:       fishInfos,
-		Bullets:      bulletInfos,
-		Seats:        seatInfos,
-		PlayerCount:  int32(len(rm.gameState.Players)),
-		Timestamp:    time.Now().Unix(),
-		RoomStatus:   rm.gameState.Status,
+		RoomId:         rm.roomID,
+		Fishes:         fishInfos,
+		Bullets:        bulletInfos,
+		Seats:          seatInfos,
+		PlayerCount:    int32(len(rm.gameState.Players)),
+		Timestamp:      time.Now().Unix(),
+		RoomStatus:     rm.gameState.Status,
+		SpectatorCount: rm.spectatorCount(),
+		ConfigVersion:  rm.configVersion(),
+		Events:         roomEventInfos(rm.activeEvents()),
 	}
 
 	// 創建 GameMessage
@@ -1192,6 +1220,11 @@ func (rm *RoomManager) generateSeatInfos() []*pb.SeatInfo {
 	return seatInfos
 }
 
+// spectatorCount 觀戰人數：房間內不在玩家列表中的連接都是觀戰者
+func (rm *RoomManager) spectatorCount() int32 {
+	return int32(len(rm.clients) - len(rm.gameState.Players))
+}
+
 // startEmptyRoomTimer 啟動空閒房間回收定時器
 func (rm *RoomManager) startEmptyRoomTimer() {
 	rm.emptyRoomMu.Lock()
@@ -1204,8 +1237,8 @@ func (rm *RoomManager) startEmptyRoomTimer() {
 
 	// 創建新的定時器：1分鐘後檢查房間是否仍為空
 	rm.emptyRoomTimer = time.AfterFunc(1*time.Minute, func() {
-		// 檢查房間是否仍為空
-		if len(rm.gameState.Players) == 0 {
+		// 檢查房間是否仍為空（仍有觀戰者時保留）
+		if len(rm.gameState.Players) == 0 && len(rm.clients) == 0 {
 			rm.logger.Infof("Room %s has been empty for 1 minute, shutting down", rm.roomID)
 
 			// 通知 Hub 關閉房間
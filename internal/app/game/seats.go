@@ -0,0 +1,273 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+)
+
+// ========================================
+// SeatKeeper - 座位保留、換座和 AFK 回收
+// ========================================
+//
+// 玩家斷線時座位保留 reconnect_grace，期間使用原來的會話重新連接回到原座位（見 Hub.resumeSession），
+// 過期後結算離開；在線玩家超過 afk_timeout 只有心跳、沒有開火、切換砲台或換座時結算離座並轉為觀戰者。
+// 業務層的所有座位變化（入座、離座、換座、保留、重新連接、AFK）都以 SEAT_EVENT 廣播給房間。
+
+// SeatKeeper 座位管理
+type SeatKeeper struct {
+	hub         *Hub
+	gameUsecase *game.GameUsecase
+
+	reconnectGrace time.Duration
+	swapTimeout    time.Duration
+	afkTimeout     time.Duration
+	interval       time.Duration
+
+	logger logger.Logger
+
+	hookOnce sync.Once
+	mu       sync.Mutex
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewSeatKeeper 創建座位管理
+func NewSeatKeeper(hub *Hub, gameUsecase *game.GameUsecase, config *conf.Config, logger logger.Logger) *SeatKeeper {
+	seats := &conf.Seats{ReconnectGrace: 30, SwapTimeout: 15, AFKTimeout: 300, SweepInterval: 5}
+	if config != nil && config.Game != nil && config.Game.Seats != nil {
+		seats = config.Game.Seats
+	}
+
+	return &SeatKeeper{
+		hub:            hub,
+		gameUsecase:    gameUsecase,
+		reconnectGrace: time.Duration(seats.ReconnectGrace) * time.Second,
+		swapTimeout:    time.Duration(seats.SwapTimeout) * time.Second,
+		afkTimeout:     time.Duration(seats.AFKTimeout) * time.Second,
+		interval:       time.Duration(seats.SweepInterval) * time.Second,
+		logger:         logger.With("component", "seat_keeper"),
+	}
+}
+
+// Start 註冊座位事件廣播並開始定期檢查
+func (k *SeatKeeper) Start() {
+	k.hookOnce.Do(func() { k.gameUsecase.OnSeatEvent(k.broadcast) })
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.stop != nil {
+		return
+	}
+	k.stop = make(chan struct{})
+	k.stopped = make(chan struct{})
+
+	k.logger.Infof("Seat keeper started: reconnect_grace=%v, swap_timeout=%v, afk_timeout=%v, interval=%v",
+		k.reconnectGrace, k.swapTimeout, k.afkTimeout, k.interval)
+	go k.run(k.stop, k.stopped)
+}
+
+// Stop 停止定期檢查（下線模式和關閉時調用，下線流程會結算所有玩家）
+func (k *SeatKeeper) Stop() {
+	k.mu.Lock()
+	stop, stopped := k.stop, k.stopped
+	k.stop, k.stopped = nil, nil
+	k.mu.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-stopped
+}
+
+// run 檢查循環
+func (k *SeatKeeper) run(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), k.interval)
+		k.sweep(ctx, time.Now())
+		cancel()
+	}
+}
+
+// sweep 結算座位保留已過期的玩家，AFK 玩家結算離座並轉為觀戰者，返回兩者的人數
+func (k *SeatKeeper) sweep(ctx context.Context, now time.Time) (expired, afk int) {
+	result := k.gameUsecase.SweepSeats(now, k.afkTimeout)
+
+	for _, seat := range result.Expired {
+		if _, err := k.gameUsecase.SettleAndLeaveRoom(ctx, seat.RoomID, seat.PlayerID, game.EndReasonReservationExpired); err != nil {
+			k.logger.Errorf("Failed to release reserved seat %d of player %d in room %s: %v", seat.SeatID, seat.PlayerID, seat.RoomID, err)
+			continue
+		}
+		k.logger.Infof("Player %d did not reconnect to room %s, seat %d released", seat.PlayerID, seat.RoomID, seat.SeatID)
+		expired++
+	}
+
+	for _, seat := range result.AFK {
+		if err := k.reclaim(ctx, seat); err != nil {
+			k.logger.Errorf("Failed to reclaim seat %d of AFK player %d in room %s: %v", seat.SeatID, seat.PlayerID, seat.RoomID, err)
+			continue
+		}
+		afk++
+	}
+	return expired, afk
+}
+
+// reclaim 結算 AFK 玩家並空出座位，連接仍在本節點時轉為觀戰者繼續接收房間狀態
+func (k *SeatKeeper) reclaim(ctx context.Context, seat game.SeatRef) error {
+	client := k.hub.roomClient(seat.RoomID, seat.PlayerID)
+	if client == nil {
+		_, err := k.gameUsecase.SettleAndLeaveRoom(ctx, seat.RoomID, seat.PlayerID, game.EndReasonAFK)
+		return err
+	}
+
+	reason := fmt.Sprintf("no action for %v", k.afkTimeout)
+	if _, err := k.gameUsecase.ReclaimSeat(ctx, seat.RoomID, seat.PlayerID, reason); err != nil {
+		return err
+	}
+	client.Spectating = true
+	k.logger.Infof("AFK player %d left seat %d in room %s and is now spectating", seat.PlayerID, seat.SeatID, seat.RoomID)
+	return nil
+}
+
+// reserve 玩家斷線時保留座位，返回 false 時調用方應直接離開結算
+func (k *SeatKeeper) reserve(ctx context.Context, roomID string, playerID int64) bool {
+	if k.reconnectGrace <= 0 || k.hub.Draining() {
+		return false
+	}
+	if err := k.gameUsecase.ReserveSeat(ctx, roomID, playerID, time.Now().Add(k.reconnectGrace)); err != nil {
+		k.logger.Warnf("Failed to reserve seat of player %d in room %s: %v", playerID, roomID, err)
+		return false
+	}
+	return true
+}
+
+// broadcast 把座位事件廣播給房間
+func (k *SeatKeeper) broadcast(event game.SeatEvent) {
+	k.hub.broadcastToRoom(event.RoomID, newSeatEventMessage(event), nil)
+}
+
+// newSeatEventMessage 構建 SEAT_EVENT 消息
+func newSeatEventMessage(event game.SeatEvent) *pb.GameMessage {
+	var expiresAt int64
+	if !event.ExpiresAt.IsZero() {
+		expiresAt = event.ExpiresAt.UnixMilli()
+	}
+	return &pb.GameMessage{
+		Type: pb.MessageType_SEAT_EVENT,
+		Data: &pb.GameMessage_SeatEvent{
+			SeatEvent: &pb.SeatEvent{
+				RoomId:        event.RoomID,
+				Type:          string(event.Type),
+				PlayerId:      event.PlayerID,
+				SeatId:        int32(event.SeatID),
+				OtherPlayerId: event.OtherPlayerID,
+				OtherSeatId:   int32(event.OtherSeatID),
+				ExpiresAt:     expiresAt,
+				Reason:        event.Reason,
+				Timestamp:     event.At.Unix(),
+			},
+		},
+	}
+}
+
+// ========================================
+// 座位消息處理
+// ========================================
+
+// handleSelectSeat 處理換到空座位的消息
+func (mh *MessageHandler) handleSelectSeat(client *Client, message *pb.GameMessage) {
+	if client.RoomID == "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if mh.rejectSpectator(client) {
+		return
+	}
+	selectData := message.GetSelectSeat()
+	if selectData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid select seat data")
+		return
+	}
+
+	if err := mh.gameUsecase.SelectSeat(context.Background(), client.RoomID, client.PlayerID, int(selectData.SeatId)); err != nil {
+		mh.sendBizErrorResponse(client, err, "Failed to select seat")
+		return
+	}
+
+	client.sendProtobuf(&pb.GameMessage{
+		Type: pb.MessageType_SELECT_SEAT_RESPONSE,
+		Data: &pb.GameMessage_SelectSeatResponse{
+			SelectSeatResponse: &pb.SelectSeatResponse{
+				Success:   true,
+				SeatId:    selectData.SeatId,
+				Timestamp: time.Now().Unix(),
+			},
+		},
+	})
+}
+
+// handleSwapSeat 處理換座請求：對方收到 swap_requested 座位事件後以 RESPOND_SEAT_SWAP 回應
+func (mh *MessageHandler) handleSwapSeat(client *Client, message *pb.GameMessage) {
+	if client.RoomID == "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if mh.rejectSpectator(client) {
+		return
+	}
+	if mh.hub.seatKeeper == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_UNSUPPORTED_MESSAGE, "Seat swap is not available")
+		return
+	}
+	swapData := message.GetSwapSeat()
+	if swapData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid swap seat data")
+		return
+	}
+
+	_, err := mh.gameUsecase.RequestSeatSwap(context.Background(), client.RoomID, client.PlayerID,
+		int(swapData.SeatId), mh.hub.seatKeeper.swapTimeout)
+	if err != nil {
+		mh.sendBizErrorResponse(client, err, "Failed to request seat swap")
+	}
+}
+
+// handleRespondSeatSwap 處理接受或拒絕換座請求
+func (mh *MessageHandler) handleRespondSeatSwap(client *Client, message *pb.GameMessage) {
+	if client.RoomID == "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
+		return
+	}
+	if mh.rejectSpectator(client) {
+		return
+	}
+	respondData := message.GetRespondSeatSwap()
+	if respondData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid respond seat swap data")
+		return
+	}
+
+	err := mh.gameUsecase.RespondSeatSwap(context.Background(), client.RoomID, client.PlayerID,
+		respondData.RequesterId, respondData.Accept)
+	if err != nil {
+		mh.sendBizErrorResponse(client, err, "Failed to respond to seat swap")
+	}
+}
@@ -0,0 +1,270 @@
+package game
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seatEvents 收集座位事件
+func seatEvents(gu *game.GameUsecase) <-chan game.SeatEvent {
+	events := make(chan game.SeatEvent, 32)
+	gu.OnSeatEvent(func(event game.SeatEvent) { events <- event })
+	return events
+}
+
+// nextSeatEvent 等待下一個指定類型的座位事件，跳過其他類型
+func nextSeatEvent(t *testing.T, events <-chan game.SeatEvent, eventType game.SeatEventType) game.SeatEvent {
+	t.Helper()
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType {
+				return event
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s seat event received", eventType)
+			return game.SeatEvent{}
+		}
+	}
+}
+
+// seatOf 玩家當前的座位
+func seatOf(t *testing.T, gu *game.GameUsecase, roomID string, playerID int64) int {
+	t.Helper()
+	room, err := gu.GetRoom(context.Background(), roomID)
+	require.NoError(t, err)
+	player, ok := room.Players[playerID]
+	require.True(t, ok, "player %d not in room", playerID)
+	return player.SeatID
+}
+
+func TestSeats_SelectSeat(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+	events := seatEvents(gu)
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+	require.NoError(t, guestJoiner(gu, 2)(room.ID))
+	taken := nextSeatEvent(t, events, game.SeatEventTaken)
+	assert.Equal(t, int64(1), taken.PlayerID)
+
+	from := seatOf(t, gu, room.ID, 1)
+	other := seatOf(t, gu, room.ID, 2)
+	assert.ErrorIs(t, gu.SelectSeat(ctx, room.ID, 1, other), game.ErrSeatTaken)
+	assert.ErrorIs(t, gu.SelectSeat(ctx, room.ID, 1, 99), game.ErrInvalidSeat)
+	assert.ErrorIs(t, gu.SelectSeat(ctx, room.ID, 3, 3), game.ErrPlayerNotInRoom)
+
+	require.NoError(t, gu.SelectSeat(ctx, room.ID, 1, 3))
+	assert.Equal(t, 3, seatOf(t, gu, room.ID, 1))
+	changed := nextSeatEvent(t, events, game.SeatEventChanged)
+	assert.Equal(t, room.ID, changed.RoomID)
+	assert.Equal(t, 3, changed.SeatID)
+	assert.Equal(t, from, changed.OtherSeatID)
+
+	// 原座位已空出，其他玩家可以入座
+	require.NoError(t, gu.SelectSeat(ctx, room.ID, 2, from))
+}
+
+func TestSeats_SwapSeats(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+	events := seatEvents(gu)
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+	require.NoError(t, guestJoiner(gu, 2)(room.ID))
+	seat1, seat2 := seatOf(t, gu, room.ID, 1), seatOf(t, gu, room.ID, 2)
+
+	t.Run("invalid targets", func(t *testing.T) {
+		_, err := gu.RequestSeatSwap(ctx, room.ID, 1, seat1, time.Minute)
+		assert.ErrorIs(t, err, game.ErrInvalidSeat)
+		_, err = gu.RequestSeatSwap(ctx, room.ID, 1, 3, time.Minute)
+		assert.ErrorIs(t, err, game.ErrInvalidSeat)
+		assert.ErrorIs(t, gu.RespondSeatSwap(ctx, room.ID, 2, 1, true), game.ErrSwapRequestNotFound)
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		request, err := gu.RequestSeatSwap(ctx, room.ID, 1, seat2, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), request.TargetID)
+		requested := nextSeatEvent(t, events, game.SeatEventSwapRequested)
+		assert.Equal(t, int64(2), requested.OtherPlayerID)
+		assert.Equal(t, seat2, requested.OtherSeatID)
+		assert.False(t, requested.ExpiresAt.IsZero())
+
+		// 只有被請求的玩家可以回應
+		assert.ErrorIs(t, gu.RespondSeatSwap(ctx, room.ID, 1, 1, true), game.ErrSwapRequestNotFound)
+		require.NoError(t, gu.RespondSeatSwap(ctx, room.ID, 2, 1, false))
+		declined := nextSeatEvent(t, events, game.SeatEventSwapDeclined)
+		assert.Equal(t, "declined", declined.Reason)
+		assert.Equal(t, seat1, seatOf(t, gu, room.ID, 1))
+
+		// 請求只能回應一次
+		assert.ErrorIs(t, gu.RespondSeatSwap(ctx, room.ID, 2, 1, true), game.ErrSwapRequestNotFound)
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		_, err := gu.RequestSeatSwap(ctx, room.ID, 1, seat2, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, gu.RespondSeatSwap(ctx, room.ID, 2, 1, true))
+		swapped := nextSeatEvent(t, events, game.SeatEventSwapped)
+		assert.Equal(t, int64(1), swapped.PlayerID)
+		assert.Equal(t, seat2, swapped.SeatID)
+		assert.Equal(t, seat1, swapped.OtherSeatID)
+		assert.Equal(t, seat2, seatOf(t, gu, room.ID, 1))
+		assert.Equal(t, seat1, seatOf(t, gu, room.ID, 2))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := gu.RequestSeatSwap(ctx, room.ID, 2, seat2, time.Minute)
+		require.NoError(t, err)
+		gu.SweepSeats(time.Now().Add(2*time.Minute), 0)
+		expired := nextSeatEvent(t, events, game.SeatEventSwapDeclined)
+		assert.Equal(t, "expired", expired.Reason)
+		assert.ErrorIs(t, gu.RespondSeatSwap(ctx, room.ID, 1, 2, true), game.ErrSwapRequestNotFound)
+	})
+
+	t.Run("cancelled when requester leaves", func(t *testing.T) {
+		_, err := gu.RequestSeatSwap(ctx, room.ID, 2, seat2, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, gu.LeaveRoom(ctx, room.ID, 2))
+		cancelled := nextSeatEvent(t, events, game.SeatEventSwapDeclined)
+		assert.Equal(t, "player left", cancelled.Reason)
+		nextSeatEvent(t, events, game.SeatEventReleased)
+	})
+}
+
+func TestSeats_ReserveAndResume(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+	events := seatEvents(gu)
+	log := logger.New(os.Stdout, "error", "console")
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+	require.NoError(t, guestJoiner(gu, 2)(room.ID))
+	seat1 := seatOf(t, gu, room.ID, 1)
+
+	hub := NewHub(gu, nil, nil, nil, log)
+	keeper := NewSeatKeeper(hub, gu, &conf.Config{Game: &conf.Game{
+		Seats: &conf.Seats{ReconnectGrace: 30, SwapTimeout: 15, AFKTimeout: 300, SweepInterval: 5},
+	}}, log)
+	hub.seatKeeper = keeper
+
+	require.True(t, keeper.reserve(ctx, room.ID, 1))
+	reserved := nextSeatEvent(t, events, game.SeatEventReserved)
+	assert.Equal(t, seat1, reserved.SeatID)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), reserved.ExpiresAt, time.Second)
+
+	// 保留中的座位不能被換走或請求交換
+	assert.ErrorIs(t, gu.SelectSeat(ctx, room.ID, 2, seat1), game.ErrSeatReserved)
+	_, err = gu.RequestSeatSwap(ctx, room.ID, 2, seat1, time.Minute)
+	assert.ErrorIs(t, err, game.ErrSeatReserved)
+
+	// 所有玩家斷線時房間暫停
+	require.True(t, keeper.reserve(ctx, room.ID, 2))
+	assert.Equal(t, game.RoomStatusPaused, room.Status)
+
+	// 重新連接回到原座位
+	client := NewClient(nil, hub, log)
+	client.PlayerID = 1
+	require.True(t, hub.resumeSession(client))
+	assert.Equal(t, room.ID, client.RoomID)
+	assert.Equal(t, seat1, seatOf(t, gu, room.ID, 1))
+	resumed := nextSeatEvent(t, events, game.SeatEventResumed)
+	assert.Equal(t, int64(1), resumed.PlayerID)
+	assert.Equal(t, game.RoomStatusPlaying, room.Status)
+
+	// 沒有重新連接的玩家保留過期後結算離開
+	expired, afk := keeper.sweep(ctx, time.Now().Add(time.Minute))
+	assert.Equal(t, 1, expired)
+	assert.Zero(t, afk)
+	restored, err := gu.GetRoom(ctx, room.ID)
+	require.NoError(t, err)
+	assert.Contains(t, restored.Players, int64(1))
+	assert.NotContains(t, restored.Players, int64(2))
+
+	// 下線中不保留座位
+	hub.draining.Store(true)
+	assert.False(t, keeper.reserve(ctx, room.ID, 1))
+}
+
+func TestSeats_AFKReclaim(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+	events := seatEvents(gu)
+	log := logger.New(os.Stdout, "error", "console")
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, guestJoiner(gu, 1)(room.ID))
+	require.NoError(t, guestJoiner(gu, 2)(room.ID))
+	seat1 := seatOf(t, gu, room.ID, 1)
+
+	hub := NewHub(gu, nil, nil, nil, log)
+	keeper := NewSeatKeeper(hub, gu, nil, log)
+
+	// 玩家 1 的連接在本節點
+	client := NewClient(nil, hub, log)
+	client.PlayerID = 1
+	client.RoomID = room.ID
+	hub.rooms[room.ID] = map[*Client]bool{client: true}
+
+	// 剛有操作的玩家不會被回收
+	gu.TouchPlayer(room.ID, 1)
+	expired, afk := keeper.sweep(ctx, time.Now())
+	assert.Zero(t, expired)
+	assert.Zero(t, afk)
+
+	expired, afk = keeper.sweep(ctx, time.Now().Add(5*time.Minute))
+	assert.Zero(t, expired)
+	assert.Equal(t, 2, afk)
+
+	restored, err := gu.GetRoom(ctx, room.ID)
+	require.NoError(t, err)
+	assert.Empty(t, restored.Players)
+	// 本節點的連接轉為觀戰者，不在本節點的玩家直接離開
+	assert.True(t, client.Spectating)
+	assert.Contains(t, restored.Spectators, int64(1))
+	assert.NotContains(t, restored.Spectators, int64(2))
+
+	event := nextSeatEvent(t, events, game.SeatEventAFK)
+	assert.Equal(t, int64(1), event.PlayerID)
+	assert.Equal(t, seat1, event.SeatID)
+	assert.NotEmpty(t, event.Reason)
+}
+
+func TestNewSeatEventMessage(t *testing.T) {
+	at := time.Now()
+	msg := newSeatEventMessage(game.SeatEvent{
+		RoomID: "room", Type: game.SeatEventReserved, PlayerID: 7, SeatID: 2,
+		OtherSeatID: -1, ExpiresAt: at.Add(time.Second), At: at,
+	})
+	assert.Equal(t, pb.MessageType_SEAT_EVENT, msg.Type)
+	event := msg.GetSeatEvent()
+	require.NotNil(t, event)
+	assert.Equal(t, "reserved", event.Type)
+	assert.Equal(t, int32(2), event.SeatId)
+	assert.Equal(t, int32(-1), event.OtherSeatId)
+	assert.Equal(t, at.Add(time.Second).UnixMilli(), event.ExpiresAt)
+
+	msg = newSeatEventMessage(game.SeatEvent{Type: game.SeatEventTaken, At: at})
+	assert.Zero(t, msg.GetSeatEvent().ExpiresAt)
+}
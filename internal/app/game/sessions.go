@@ -0,0 +1,222 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/gorilla/websocket"
+)
+
+// ========================================
+// SessionWatcher - 撤銷會話時關閉連接
+// ========================================
+//
+// 玩家登出某台設備、登出所有設備或刷新令牌被重複使用時，帳號服務刪除會話並發布撤銷通知，
+// 本節點關閉屬於這些會話的 WebSocket 連接（1008 Policy Violation），之後按正常流程註銷並結算。
+// pub/sub 消息可能在斷線期間丟失，因此還會定期檢查已連接客戶端的會話是否仍然有效。
+// 舊版令牌沒有會話 ID，不受影響；封禁或停用帳號的通知帶 AllConnections，關閉該帳號的所有連接。
+
+const (
+	sessionSweepInterval = time.Minute
+	sessionCheckTimeout  = 5 * time.Second
+	sessionRetryMin      = time.Second
+	sessionRetryMax      = 30 * time.Second
+)
+
+// SessionWatcher 監聽會話撤銷並關閉對應的連接
+type SessionWatcher struct {
+	sessions *account.SessionUsecase
+	hub      *Hub
+
+	sweepInterval time.Duration
+
+	logger logger.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// NewSessionWatcher 創建會話撤銷監聽
+func NewSessionWatcher(sessions *account.SessionUsecase, hub *Hub, logger logger.Logger) *SessionWatcher {
+	return &SessionWatcher{
+		sessions:      sessions,
+		hub:           hub,
+		sweepInterval: sessionSweepInterval,
+		logger:        logger.With("component", "session_watcher"),
+	}
+}
+
+// Start 開始訂閱撤銷通知和定期檢查
+func (w *SessionWatcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil || w.sessions == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done.Add(2)
+	go w.subscribe(ctx)
+	go w.sweepLoop(ctx)
+	w.logger.Infof("Session revocation watcher started: sweep_interval=%v", w.sweepInterval)
+}
+
+// Stop 停止訂閱和定期檢查
+func (w *SessionWatcher) Stop() {
+	w.mu.Lock()
+	cancel := w.cancel
+	w.cancel = nil
+	w.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	w.done.Wait()
+}
+
+// subscribe 訂閱撤銷通知，連接斷開後退避重試，重新訂閱後立即檢查一次
+func (w *SessionWatcher) subscribe(ctx context.Context) {
+	defer w.done.Done()
+
+	backoff := sessionRetryMin
+	for {
+		err := w.sessions.WatchRevocations(ctx, w.handleRevocation)
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Warnf("Session revocation subscription lost, retrying in %v: %v", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, sessionRetryMax)
+		w.sweep(ctx)
+	}
+}
+
+// sweepLoop 定期檢查已連接客戶端的會話
+func (w *SessionWatcher) sweepLoop(ctx context.Context) {
+	defer w.done.Done()
+
+	ticker := time.NewTicker(w.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.sweep(ctx)
+	}
+}
+
+// handleRevocation 關閉被撤銷會話的連接
+func (w *SessionWatcher) handleRevocation(revocation *account.SessionRevocation) {
+	revoked := make(map[string]bool, len(revocation.SessionIDs))
+	for _, id := range revocation.SessionIDs {
+		revoked[id] = true
+	}
+	closed := w.hub.disconnectSessions(revoked, "session revoked")
+	if revocation.AllConnections {
+		closed += w.hub.disconnectAccount(revocation.UserID, revocation.Reason)
+	}
+	if closed > 0 {
+		w.logger.Infof("Closed %d connections of user %d: sessions revoked (%s)", closed, revocation.UserID, revocation.Reason)
+	}
+}
+
+// sweep 關閉會話已失效的連接；查詢失敗時保留連接
+func (w *SessionWatcher) sweep(ctx context.Context) int {
+	revoked := make(map[string]bool)
+	for _, sessionID := range w.hub.connectedSessions() {
+		checkCtx, cancel := context.WithTimeout(ctx, sessionCheckTimeout)
+		active, err := w.sessions.SessionActive(checkCtx, sessionID)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Warnf("Failed to check session %s: %v", sessionID, err)
+			}
+			continue
+		}
+		if !active {
+			revoked[sessionID] = true
+		}
+	}
+	if len(revoked) == 0 {
+		return 0
+	}
+
+	closed := w.hub.disconnectSessions(revoked, "session expired")
+	w.logger.Infof("Sweep closed %d connections of %d inactive sessions", closed, len(revoked))
+	return closed
+}
+
+// connectedSessions 已連接客戶端的會話 ID（去重）
+func (h *Hub) connectedSessions() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	ids := make([]string, 0, len(h.clients))
+	for client := range h.clients {
+		if client.sessionID != "" && !seen[client.sessionID] {
+			seen[client.sessionID] = true
+			ids = append(ids, client.sessionID)
+		}
+	}
+	return ids
+}
+
+// disconnectSessions 以 1008 (Policy Violation) 關閉屬於指定會話的連接，readPump 退出後按正常流程註銷並結算
+func (h *Hub) disconnectSessions(sessionIDs map[string]bool, reason string) int {
+	h.mu.RLock()
+	clients := make([]*Client, 0)
+	for client := range h.clients {
+		if client.sessionID != "" && sessionIDs[client.sessionID] {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	closePolicyViolation(clients, reason)
+	return len(clients)
+}
+
+// disconnectAccount 以 1008 (Policy Violation) 關閉帳號的所有連接，包括沒有會話的舊版令牌
+func (h *Hub) disconnectAccount(accountID int64, reason string) int {
+	if accountID <= 0 {
+		return 0
+	}
+	h.mu.RLock()
+	clients := make([]*Client, 0)
+	for client := range h.clients {
+		if client.accountID == accountID {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	closePolicyViolation(clients, reason)
+	return len(clients)
+}
+
+// closePolicyViolation 發送關閉幀並關閉連接，readPump 退出後按正常流程註銷並結算
+func closePolicyViolation(clients []*Client, reason string) {
+	for _, client := range clients {
+		if client.conn == nil {
+			continue
+		}
+		client.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason), time.Now().Add(writeWait))
+		client.conn.Close()
+	}
+}
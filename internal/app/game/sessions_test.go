@@ -0,0 +1,97 @@
+package game
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSessionStore 只實現 SessionActive 的會話存儲
+type stubSessionStore struct {
+	account.SessionStore
+	active map[string]bool
+}
+
+func (s *stubSessionStore) SessionActive(ctx context.Context, sessionID string) (bool, error) {
+	return s.active[sessionID], nil
+}
+
+func TestSessionWatcher_ClosesRevokedSessions(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	hub := &Hub{clients: make(map[*Client]bool)}
+
+	newSessionClient := func(sessionID string) *Client {
+		client := NewClient(nil, hub, log)
+		client.sessionID = sessionID
+		hub.clients[client] = true
+		return client
+	}
+	newSessionClient("phone")
+	newSessionClient("phone")
+	newSessionClient("laptop")
+	newSessionClient("") // 舊版令牌
+
+	assert.ElementsMatch(t, []string{"phone", "laptop"}, hub.connectedSessions())
+
+	store := &stubSessionStore{active: map[string]bool{"laptop": true}}
+	sessions := account.NewSessionUsecase(store, nil, nil, log)
+	watcher := NewSessionWatcher(sessions, hub, log)
+
+	// 撤銷通知關閉該會話的所有連接
+	assert.Equal(t, 2, hub.disconnectSessions(map[string]bool{"phone": true}, "session revoked"))
+
+	// 定期檢查只關閉已失效的會話
+	assert.Equal(t, 2, watcher.sweep(context.Background()))
+	store.active["phone"] = true
+	assert.Equal(t, 0, watcher.sweep(context.Background()))
+}
+
+func TestSessionWatcher_AllConnectionsClosesAccount(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	hub := &Hub{clients: make(map[*Client]bool)}
+
+	newAccountClient := func(accountID int64, sessionID string) *Client {
+		client := NewClient(nil, hub, log)
+		client.accountID = accountID
+		client.sessionID = sessionID
+		hub.clients[client] = true
+		return client
+	}
+	newAccountClient(7, "")      // 舊版令牌，沒有會話 ID
+	newAccountClient(7, "phone") // 已按會話 ID 關閉
+	newAccountClient(8, "other")
+	newAccountClient(0, "") // 遊客
+
+	// 封禁通知不帶會話 ID 也關閉該帳號的所有連接，遊客不受影響
+	assert.Equal(t, 0, hub.disconnectAccount(0, account.RevokeReasonBanned))
+	assert.Equal(t, 2, hub.disconnectAccount(7, account.RevokeReasonBanned))
+	assert.Equal(t, 1, hub.disconnectAccount(8, account.RevokeReasonBanned))
+}
+
+func TestSessionWatcher_StartStop(t *testing.T) {
+	log := logger.New(os.Stdout, "error", "console")
+	hub := &Hub{clients: make(map[*Client]bool)}
+	sessions := account.NewSessionUsecase(&stubSessionStore{}, nil, nil, log)
+
+	watcher := NewSessionWatcher(sessions, hub, log)
+	watcher.sweepInterval = 10 * time.Millisecond
+	watcher.Start()
+	time.Sleep(30 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		watcher.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "watcher did not stop")
+	}
+}
@@ -32,7 +32,22 @@ func (m *MockGameRepo) GetRoom(ctx context.Context, roomID string) (*game.Room,
 func (m *MockGameRepo) ListRooms(ctx context.Context, roomType game.RoomType) ([]*game.Room, error) {
 	return []*game.Room{}, nil
 }
-func (m *MockGameRepo) DeleteRoom(ctx context.Context, roomID string) error { return nil }
+func (m *MockGameRepo) DeleteRoom(ctx context.Context, roomID string) error          { return nil }
+func (m *MockGameRepo) SaveRoomToRedis(ctx context.Context, room *game.Room) error   { return nil }
+func (m *MockGameRepo) DeleteRoomFromRedis(ctx context.Context, roomID string) error { return nil }
+func (m *MockGameRepo) IncrementRoomCount(ctx context.Context, roomType game.RoomType) error {
+	return nil
+}
+func (m *MockGameRepo) DecrementRoomCount(ctx context.Context, roomType game.RoomType) error {
+	return nil
+}
+func (m *MockGameRepo) GetRoomCount(ctx context.Context, roomType game.RoomType) (int64, error) {
+	return 0, nil
+}
+func (m *MockGameRepo) GetTotalRoomCount(ctx context.Context) (int64, error) { return 0, nil }
+func (m *MockGameRepo) GetAllRoomCounts(ctx context.Context) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
 func (m *MockGameRepo) SaveGameStatistics(ctx context.Context, playerID int64, stats *game.GameStatistics) error {
 	return nil
 }
@@ -56,9 +71,6 @@ type MockPlayerRepo struct{}
 func (m *MockPlayerRepo) GetPlayer(ctx context.Context, playerID int64) (*game.Player, error) {
 	return &game.Player{ID: playerID, UserID: playerID, Nickname: "TestPlayer", Balance: 10000, WalletID: 1, Status: game.PlayerStatusIdle}, nil
 }
-func (m *MockPlayerRepo) UpdatePlayerBalance(ctx context.Context, playerID int64, balance int64) error {
-	return nil
-}
 func (m *MockPlayerRepo) UpdatePlayerStatus(ctx context.Context, playerID int64, status game.PlayerStatus) error {
 	return nil
 }
@@ -183,9 +195,8 @@ func (r *MockInventoryRepo) GetAllInventories(ctx context.Context) (map[string]*
 }
 
 type MockBizPlayerRepo struct {
-	mu        sync.Mutex
-	players   map[string]*player.Player
-	idCounter uint
+	mu      sync.Mutex
+	players map[string]*player.Player
 }
 
 func NewMockBizPlayerRepo() *MockBizPlayerRepo {
@@ -201,13 +212,15 @@ func (m *MockBizPlayerRepo) FindByUsername(ctx context.Context, username string)
 	return nil, nil // Return nil, nil for not found
 }
 
-func (m *MockBizPlayerRepo) Create(ctx context.Context, p *player.Player) (*player.Player, error) {
+func (m *MockBizPlayerRepo) GetProfile(ctx context.Context, userID int64) (*player.Profile, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.idCounter++
-	p.ID = m.idCounter
-	m.players[p.Username] = p
-	return p, nil
+	for _, p := range m.players {
+		if int64(p.ID) == userID {
+			return &player.Profile{UserID: userID, Username: p.Username, Nickname: p.Username, Active: true, Currency: wallet.DefaultCurrency}, nil
+		}
+	}
+	return nil, nil
 }
 
 // ========================================
@@ -259,7 +272,7 @@ func TestSimpleGameComponents(t *testing.T) {
 
 	// 2. Run tests for the app/game layer components
 	t.Run("Test Hub", func(t *testing.T) {
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 		go hub.Run()
 		defer hub.Stop()
 
@@ -283,7 +296,7 @@ func TestSimpleGameComponents(t *testing.T) {
 	})
 
 	t.Run("Test MessageHandler", func(t *testing.T) {
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 		go hub.Run()
 		defer hub.Stop()
 
@@ -331,7 +344,7 @@ func TestSimpleGameComponents(t *testing.T) {
 		room, err := gameUsecase.CreateRoom(context.Background(), "test_room_001", 4)
 		assert.NoError(t, err)
 
-		hub := NewHub(gameUsecase, playerUsecase, log)
+		hub := NewHub(gameUsecase, playerUsecase, NewMessageRateLimiter(nil, log), nil, log)
 		go hub.Run()
 		defer hub.Stop()
 
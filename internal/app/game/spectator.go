@@ -0,0 +1,103 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+)
+
+// ========================================
+// 觀戰模式
+// ========================================
+//
+// 觀戰者加入 Hub 的房間分組以接收房間狀態和事件廣播，但不佔座位、不計入 MaxPlayers，
+// 也不能開火、切換砲台或擊中魚。觀戰人數按房間類型單獨限制；
+// 通過管理後台簽發的客服觀戰令牌連接時不受上限限制，但只能觀戰令牌指定的房間。
+
+// handleWatchRoom 處理觀戰請求
+func (mh *MessageHandler) handleWatchRoom(client *Client, message *pb.GameMessage) {
+	watchData := message.GetWatchRoom()
+	if watchData == nil {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_MESSAGE, "Invalid watch room data")
+		return
+	}
+
+	roomID := watchData.RoomId
+	if roomID == "" {
+		roomID = client.supportRoomID
+	}
+	if roomID == "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Room ID is required")
+		return
+	}
+	if client.supportRoomID != "" && roomID != client.supportRoomID {
+		mh.sendErrorResponse(client, pb.ErrorCode_INVALID_ARGUMENT, "Spectate token is issued for another room")
+		return
+	}
+	if client.RoomID != "" {
+		mh.sendErrorResponse(client, pb.ErrorCode_ALREADY_IN_ROOM, "Already in a room")
+		return
+	}
+
+	// 下線模式中不再接受進入房間
+	if mh.hub.Draining() {
+		mh.sendErrorResponse(client, pb.ErrorCode_NODE_DRAINING, "Server is draining, please reconnect to another server")
+		return
+	}
+
+	spectator := &game.Spectator{
+		PlayerID: client.PlayerID,
+		Support:  client.supportRoomID != "",
+	}
+	if client.GuestPlayer != nil {
+		spectator.Nickname = client.GuestPlayer.Nickname
+	}
+
+	ctx := context.Background()
+	if err := mh.gameUsecase.WatchRoom(ctx, roomID, spectator); err != nil {
+		mh.sendBizErrorResponse(client, err, "Failed to watch room")
+		return
+	}
+
+	client.Spectating = true
+	client.RoomID = roomID
+	mh.hub.joinRoom <- &JoinRoomMessage{Client: client, RoomID: roomID}
+
+	var playerCount, spectatorCount int32
+	if room, err := mh.gameUsecase.GetRoom(ctx, roomID); err == nil {
+		playerCount = int32(len(room.Players))
+		spectatorCount = int32(room.SpectatorCount())
+	}
+
+	client.sendProtobuf(&pb.GameMessage{
+		Type: pb.MessageType_WATCH_ROOM_RESPONSE,
+		Data: &pb.GameMessage_WatchRoomResponse{
+			WatchRoomResponse: &pb.WatchRoomResponse{
+				Success:        true,
+				RoomId:         roomID,
+				PlayerCount:    playerCount,
+				SpectatorCount: spectatorCount,
+				Timestamp:      time.Now().Unix(),
+			},
+		},
+	})
+
+	// 沒有玩家的房間不會推送狀態，由第一個觀戰者啟動
+	go mh.broadcastRoomState(roomID)
+	if playerCount == 0 && spectatorCount == 1 {
+		mh.StartRoomStateUpdates(roomID)
+	}
+
+	mh.logger.Infof("Player %d is spectating room %s (support=%v)", client.PlayerID, roomID, spectator.Support)
+}
+
+// rejectSpectator 觀戰者不能進行遊戲操作，返回 true 表示已拒絕
+func (mh *MessageHandler) rejectSpectator(client *Client) bool {
+	if !client.Spectating {
+		return false
+	}
+	mh.sendErrorResponse(client, pb.ErrorCode_SPECTATOR_ACTION_FORBIDDEN, "Spectators cannot perform game actions")
+	return true
+}
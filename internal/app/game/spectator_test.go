@@ -0,0 +1,148 @@
+package game
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	pb "github.com/b7777777v/fish_server/pkg/pb/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWatchRoom_SpectatorCap(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeVIP, 4)
+	require.NoError(t, err)
+	limit := int(gu.RoomTypeConfig(game.RoomTypeVIP).MaxSpectators)
+
+	for i := 0; i < limit; i++ {
+		require.NoError(t, gu.WatchRoom(ctx, room.ID, &game.Spectator{PlayerID: int64(100 + i)}))
+	}
+	err = gu.WatchRoom(ctx, room.ID, &game.Spectator{PlayerID: 999})
+	assert.ErrorIs(t, err, game.ErrSpectatorsFull)
+
+	// 客服觀戰不受上限限制
+	require.NoError(t, gu.WatchRoom(ctx, room.ID, &game.Spectator{PlayerID: 1000, Support: true}))
+
+	// 觀戰者不計入玩家人數
+	stats := gu.RoomStats(game.RoomTypeVIP)
+	require.Len(t, stats, 1)
+	assert.Equal(t, 0, stats[0].Players)
+	assert.Equal(t, limit+1, stats[0].Spectators)
+
+	// 重複觀戰
+	err = gu.WatchRoom(ctx, room.ID, &game.Spectator{PlayerID: 100})
+	assert.ErrorIs(t, err, game.ErrPlayerSpectating)
+
+	require.NoError(t, gu.StopWatching(ctx, room.ID, 100))
+	assert.ErrorIs(t, gu.StopWatching(ctx, room.ID, 100), game.ErrPlayerNotInRoom)
+}
+
+func TestWatchRoom_SpectatorTakesSeat(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+
+	roomA, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	roomB, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+
+	require.NoError(t, gu.WatchRoom(ctx, roomA.ID, &game.Spectator{PlayerID: 1}))
+
+	// 觀戰中不能坐進其他房間
+	err = guestJoiner(gu, 1)(roomB.ID)
+	assert.ErrorIs(t, err, game.ErrPlayerSpectating)
+
+	// 在同一房間入座後不再是觀戰者
+	require.NoError(t, guestJoiner(gu, 1)(roomA.ID))
+	room, err := gu.GetRoom(ctx, roomA.ID)
+	require.NoError(t, err)
+	assert.Contains(t, room.Players, int64(1))
+	assert.Zero(t, room.SpectatorCount())
+
+	// 已入座的玩家不能觀戰
+	err = gu.WatchRoom(ctx, roomB.ID, &game.Spectator{PlayerID: 1})
+	assert.ErrorIs(t, err, game.ErrPlayerAlreadyInRoom)
+}
+
+func TestCloseIdleRoom_KeepsWatchedRoom(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	ctx := context.Background()
+
+	room, err := gu.CreateRoom(ctx, game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+	require.NoError(t, gu.WatchRoom(ctx, room.ID, &game.Spectator{PlayerID: 1}))
+
+	assert.ErrorIs(t, gu.CloseIdleRoom(ctx, room.ID), game.ErrRoomNotEmpty)
+
+	require.NoError(t, gu.StopWatching(ctx, room.ID, 1))
+	assert.NoError(t, gu.CloseIdleRoom(ctx, room.ID))
+}
+
+func TestMessageHandler_WatchRoom(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	log := logger.New(os.Stdout, "error", "console")
+	hub := NewHub(gu, nil, nil, nil, log)
+	mh := NewMessageHandler(gu, hub, log)
+
+	room, err := gu.CreateRoom(context.Background(), game.RoomTypeNovice, 4)
+	require.NoError(t, err)
+
+	client := NewClient(nil, hub, log)
+	client.PlayerID = 42
+	mh.HandleMessage(client, &pb.GameMessage{
+		Type: pb.MessageType_WATCH_ROOM,
+		Data: &pb.GameMessage_WatchRoom{WatchRoom: &pb.WatchRoomRequest{RoomId: room.ID}},
+	})
+
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+	require.Equal(t, pb.MessageType_WATCH_ROOM_RESPONSE, msg.Type)
+	assert.True(t, msg.GetWatchRoomResponse().Success)
+	assert.Equal(t, int32(0), msg.GetWatchRoomResponse().PlayerCount)
+	assert.Equal(t, int32(1), msg.GetWatchRoomResponse().SpectatorCount)
+	assert.True(t, client.Spectating)
+
+	join := <-hub.joinRoom
+	assert.Equal(t, room.ID, join.RoomID)
+
+	// 觀戰者不能開火
+	mh.HandleMessage(client, &pb.GameMessage{
+		Type: pb.MessageType_FIRE_BULLET,
+		Data: &pb.GameMessage_FireBullet{FireBullet: &pb.FireBulletRequest{Direction: 1, Power: 10}},
+	})
+	require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+	assert.Equal(t, pb.ErrorCode_SPECTATOR_ACTION_FORBIDDEN, msg.GetError().ErrorCode)
+}
+
+func TestMessageHandler_SupportSpectatorScopedToRoom(t *testing.T) {
+	gu := newMatchmakerTestUsecase(t)
+	closeAllRooms(t, gu)
+	log := logger.New(os.Stdout, "error", "console")
+	hub := NewHub(gu, nil, nil, nil, log)
+	mh := NewMessageHandler(gu, hub, log)
+
+	client := NewClient(nil, hub, log)
+	client.PlayerID = 1
+	client.supportRoomID = "room_support"
+
+	mh.HandleMessage(client, &pb.GameMessage{
+		Type: pb.MessageType_WATCH_ROOM,
+		Data: &pb.GameMessage_WatchRoom{WatchRoom: &pb.WatchRoomRequest{RoomId: "room_other"}},
+	})
+
+	var msg pb.GameMessage
+	require.NoError(t, proto.Unmarshal(<-client.send, &msg))
+	assert.Equal(t, pb.ErrorCode_INVALID_ARGUMENT, msg.GetError().ErrorCode)
+	assert.False(t, client.Spectating)
+}
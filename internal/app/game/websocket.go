@@ -2,12 +2,14 @@ package game
 
 import (
     "encoding/json"
+    "errors"
     "fmt"
     "hash/fnv"
     "net"
     "net/http"
     "net/url"
     "strings"
+    "sync/atomic"
     "time"
 
     "github.com/b7777777v/fish_server/internal/biz/account"
@@ -30,6 +32,7 @@ const (
 	pongWait       = 60 * time.Second    // Pong 超時
 	pingPeriod     = (pongWait * 9) / 10 // Ping 間隔
 	maxMessageSize = 512                 // 最大消息大小
+	maxJSONMessageSize = 4096            // protojson 編碼的最大消息大小
 )
 
 var upgrader = websocket.Upgrader{
@@ -73,9 +76,28 @@ type Client struct {
 	IsGuest       bool               `json:"is_guest"`        // 是否為遊客
 	GuestPlayer   *bizgame.Player    `json:"guest_player"`    // 遊客的虛擬 Player 對象
 
+	// 觀戰相關
+	Spectating    bool               `json:"spectating"`      // 是否以觀戰者身份在房間中
+	supportRoomID string             // 客服觀戰令牌限定的房間，非空時只能觀戰該房間
+
+	// 令牌綁定的登入會話，會話被撤銷時關閉連接；舊版令牌為空
+	sessionID string
+
+	// 正式帳號的 users.id，用於處罰檢查和封禁時關閉連接；遊客和客服觀戰為 0
+	accountID int64
+
+	// 遊客進度，帶會話的遊客才有
+	guest *guestTracker
+
 	// 消息通道
 	send chan []byte
 
+	// 請求 writePump 送出已排隊的消息後關閉連接
+	closeReq chan closeRequest
+
+	// 已決定關閉連接（例如限流斷線），之後讀到的消息不再計數和處理
+	closing atomic.Bool
+
 	// Hub 引用
 	hub *Hub
 
@@ -87,6 +109,24 @@ type Client struct {
 
 	// 最後活動時間
 	lastActivity time.Time
+
+	// 客戶端遠端 IP（用於臨時封禁）
+	remoteIP string
+
+	// 消息限流狀態
+	rateLimit *ClientRateLimiter
+
+	// 協商的消息編碼（protobuf / protojson）
+	codec MessageCodec
+
+	// 出站選項（批量合併、壓縮）與流量統計
+	outbound outboundOptions
+	batching atomic.Bool // 當前是否批量發送（握手後可能開啟）
+	traffic  clientTraffic
+
+	// 協議握手結果和伺服器功能開關
+	handshake *handshakeState
+	features  []string
 }
 
 // NewClient 創建新的客戶端
@@ -94,6 +134,9 @@ func NewClient(conn *websocket.Conn, hub *Hub, logger logger.Logger) *Client {
 	return &Client{
 		conn:         conn,
 		send:         make(chan []byte, 256),
+		closeReq:     make(chan closeRequest, 1),
+		codec:        ProtobufCodec,
+		handshake:    newHandshakeState(),
 		hub:          hub,
 		logger:       logger.With("component", "websocket_client"),
 		connectedAt:  time.Now(),
@@ -130,8 +173,8 @@ func (c *Client) sendProtobuf(msg *pb.GameMessage) {
 }
 
 // sendError 將錯誤消息發送到客戶端
-func (c *Client) sendError(message string) {
-	c.sendErrorPB(message)
+func (c *Client) sendError(code pb.ErrorCode, message string) {
+	c.sendErrorPB(code, message)
 }
 
 // sendJSON 將 interface{} 序列化為 JSON 後發送到客戶端
@@ -139,7 +182,7 @@ func (c *Client) sendJSON(v interface{}) {
 	bytes, err := json.Marshal(v)
 	if err != nil {
 		c.logger.Errorf("Failed to marshal JSON message: %v", err)
-		c.sendError("Internal server error: could not serialize JSON response")
+		c.sendError(pb.ErrorCode_INTERNAL_ERROR, "Internal server error: could not serialize JSON response")
 		return
 	}
 	
@@ -168,15 +211,33 @@ type WebSocketHandler struct {
 	hub            *Hub
 	tokenHelper    *token.TokenHelper
 	accountUsecase account.AccountUsecase
+	guests         *account.GuestUsecase
+	wsConfig       *conf.GameWebSocket
+	upgrader       websocket.Upgrader
+	features       []string
 	logger         logger.Logger
 }
 
 // NewWebSocketHandler 創建 WebSocket 處理器
-func NewWebSocketHandler(hub *Hub, tokenHelper *token.TokenHelper, accountUsecase account.AccountUsecase, logger logger.Logger) *WebSocketHandler {
+func NewWebSocketHandler(hub *Hub, tokenHelper *token.TokenHelper, accountUsecase account.AccountUsecase, guests *account.GuestUsecase, config *conf.Config, logger logger.Logger) *WebSocketHandler {
+	var wsConfig *conf.GameWebSocket
+	if config != nil && config.Game != nil {
+		wsConfig = config.Game.WebSocket
+	}
+
+	// 複製全局 upgrader，按配置開啟 permessage-deflate 協商
+	wsUpgrader := upgrader
+	wsUpgrader.EnableCompression = wsConfig != nil && wsConfig.Compression.Enable
+	wsUpgrader.Subprotocols = supportedSubprotocols
+
 	return &WebSocketHandler{
 		hub:            hub,
 		tokenHelper:    tokenHelper,
 		accountUsecase: accountUsecase,
+		guests:         guests,
+		wsConfig:       wsConfig,
+		upgrader:       wsUpgrader,
+		features:       serverFeatures(wsConfig, hub.rateLimiter),
 		logger:         logger.With("component", "websocket_handler"),
 	}
 }
@@ -193,7 +254,7 @@ func generateGuestID(nickname string) int64 {
 // ServeWS 處理 WebSocket 升級和連接
 func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	// 升級 HTTP 連接為 WebSocket
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		h.logger.Errorf("WebSocket upgrade failed: %v", err)
 		return
@@ -201,6 +262,21 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 
 	// 創建客戶端
 	client := NewClient(conn, h.hub, h.logger)
+	client.codec = negotiateCodec(conn, r)
+
+	// 下線模式中不再接受新連接
+	if h.hub.Draining() {
+		rejectConnection(conn, client.codec, pb.ErrorCode_NODE_DRAINING, "Server is draining, please reconnect to another server", 0)
+		return
+	}
+
+	// 連接請求可直接聲明協議版本，不兼容時在認證前拒絕
+	version, capabilities, declared, err := parseConnectHandshake(r)
+	if err != nil {
+		h.logger.Warnf("WebSocket connection rejected: %v", err)
+		rejectConnection(conn, client.codec, pb.ErrorCode_PROTOCOL_VERSION_UNSUPPORTED, err.Error(), 0)
+		return
+	}
 
 	// 嘗試從 token 獲取用戶信息（支持遊客模式）
 	var playerUsername string
@@ -225,21 +301,53 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// 管理員令牌的 UserID 是 admin_users.id，不能當作玩家連接
+		if claims.Admin {
+			h.logger.Warnf("WebSocket connection rejected: admin token used as player token (adminID=%d)", claims.UserID)
+			conn.Close()
+			return
+		}
+
 		userID = claims.UserID
+		client.sessionID = claims.SessionID
 
-		// 如果是遊客，直接使用 token 中的 nickname，不查詢數據庫
-		if claims.IsGuest {
+		// 客服觀戰令牌只能觀戰指定房間，不受觀戰人數上限限制；不對應玩家帳號，按令牌主體生成負數 ID
+		if claims.SpectateRoom != "" {
+			if claims.Subject == "" {
+				h.logger.Warnf("WebSocket connection rejected: spectate token without subject")
+				conn.Close()
+				return
+			}
+			client.supportRoomID = claims.SpectateRoom
+			playerUsername = claims.Subject
+			userID = generateGuestID(claims.Subject)
+			h.logger.Infof("WebSocket connection (support spectator): subject=%s, room=%s", claims.Subject, claims.SpectateRoom)
+		} else if claims.IsGuest {
+			// 遊客直接使用 token 中的 nickname，不查詢數據庫
 			playerUsername = claims.Nickname
 			// 為遊客生成唯一的負數 ID
 			guestID := generateGuestID(playerUsername)
 			userID = guestID
 
+			// 帶會話的遊客從保存的進度恢復餘額，已升級的遊客不能再以遊客身份連接
+			balance := account.GuestInitialBalance // 遊客初始餘額 1000.00 元（以分為單位）
+			if claims.SessionID != "" && h.guests != nil {
+				tracker, err := loadGuestTracker(r.Context(), h.guests, playerUsername, h.logger)
+				if err != nil {
+					h.logger.Warnf("WebSocket connection rejected: guest %s already upgraded", playerUsername)
+					conn.Close()
+					return
+				}
+				client.guest = tracker
+				balance = tracker.balance()
+			}
+
 			// 創建遊客的虛擬 Player 對象
 			guestPlayer := &bizgame.Player{
 				ID:       guestID,
 				UserID:   guestID,
 				Nickname: playerUsername,
-				Balance:  100000, // 遊客初始餘額 1000.00 元（以分為單位）
+				Balance:  balance,
 				WalletID: 0,      // 遊客無錢包ID
 				RoomID:   "",
 				SeatID:   -1,
@@ -252,7 +360,7 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 			client.GuestPlayer = guestPlayer
 
 			h.logger.Infof("WebSocket connection (guest mode): nickname=%s, guestID=%d", playerUsername, guestID)
-			// ✨ 遊客不在數據庫中創建記錄，跳過 GetOrCreateByUsername
+			// ✨ 遊客不在數據庫中創建記錄
 		} else {
 			// 一般用戶：從 AccountUsecase 獲取用戶信息
 			user, err := h.accountUsecase.GetUserByID(r.Context(), userID)
@@ -262,54 +370,105 @@ func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			// 使用用戶的 nickname 作為玩家名稱
-			playerUsername = user.Nickname
-			h.logger.Infof("WebSocket connection (authenticated user): userID=%d, nickname=%s", userID, playerUsername)
-
-			// 根據 nickname 獲取或創建玩家（僅一般用戶）
-			_, err = h.hub.playerUsecase.GetOrCreateByUsername(r.Context(), playerUsername)
-			if err != nil {
-				h.logger.Errorf("Failed to get or create player for user %d: %v", userID, err)
-				conn.Close()
+			// 被封禁的帳號不能連接
+			if !h.admitAccount(r, conn, client, userID) {
 				return
 			}
-		}
-	} else {
-		// 3. 如果沒有 token，回退到舊的 player_id 模式（向後兼容）
-		playerUsername = r.URL.Query().Get("player_id")
-		if playerUsername == "" {
-			h.logger.Error("WebSocket connection rejected: token or player_id is required")
-			conn.Close()
-			return
-		}
 
-		// 根據 player_id (username) 獲取或創建玩家
-		player, err := h.hub.playerUsecase.GetOrCreateByUsername(r.Context(), playerUsername)
-		if err != nil {
-			h.logger.Errorf("Failed to get or create player %s: %v", playerUsername, err)
-			conn.Close()
-			return
+			// 使用用戶的 nickname 作為玩家名稱；玩家資料按帳號 ID 讀取，不再按暱稱查找或創建
+			playerUsername = user.Nickname
+			h.logger.Infof("WebSocket connection (authenticated user): userID=%d, nickname=%s", userID, playerUsername)
 		}
+	} else {
+		// 3. 必須使用令牌連接；舊版 player_id 連接會按暱稱創建影子帳號，已不再支持
+		h.logger.Warnf("WebSocket connection rejected: token is required (player_id=%q)", r.URL.Query().Get("player_id"))
+		conn.Close()
+		return
+	}
 
-		userID = int64(player.ID)
-		h.logger.Infof("WebSocket connection with player_id: player=%s", playerUsername)
+	// 檢查是否處於限流臨時封禁期
+	remoteIP := clientIP(r)
+	if banned, remaining := h.hub.rateLimiter.IsBanned(userID, remoteIP); banned {
+		h.logger.Warnf("WebSocket connection rejected: player=%s, userID=%d, ip=%s is banned for %v",
+			playerUsername, userID, remoteIP, remaining.Round(time.Second))
+		rejectConnection(conn, client.codec, pb.ErrorCode_TEMPORARILY_BANNED,
+			fmt.Sprintf("Temporarily banned for %d seconds due to message flooding", int(remaining.Seconds())+1), remaining)
+		return
 	}
 
 	// 設置客戶端信息
 	client.ID = playerUsername
 	client.PlayerID = userID
 	client.RoomID = r.URL.Query().Get("room_id") // 可選的 room_id
+	client.remoteIP = remoteIP
+	client.rateLimit = h.hub.rateLimiter.NewClientLimiter()
+	client.outbound = newOutboundOptions(h.wsConfig, r)
+	client.batching.Store(client.outbound.batching)
+	if client.outbound.compression {
+		conn.SetCompressionLevel(h.wsConfig.Compression.Level)
+	}
+	client.features = h.features
+	if declared {
+		client.applyHandshake(version, capabilities, "", "")
+	}
 
 	// 註冊客戶端到 Hub
 	h.hub.register <- client
 
-	h.logger.Infof("New WebSocket connection: player=%s, userID=%d, room=%s", client.ID, client.PlayerID, client.RoomID)
+	h.logger.Infof("New WebSocket connection: player=%s, userID=%d, room=%s, protocol=%d, codec=%s, batching=%v, compression=%v",
+		client.ID, client.PlayerID, client.RoomID, client.handshake.Version(), client.codec.Name(), client.batching.Load(), client.outbound.compression)
 
 	// 啟動客戶端的讀寫 goroutines
 	go client.writePump()
 	go client.readPump()
 }
 
+// admitAccount 記錄連接所屬的帳號並檢查封禁，被封禁時拒絕連接並返回 false
+func (h *WebSocketHandler) admitAccount(r *http.Request, conn *websocket.Conn, client *Client, userID int64) bool {
+	client.accountID = userID
+	if err := h.checkSanctions(r, userID); err != nil {
+		h.logger.Warnf("WebSocket connection rejected: user %d: %v", userID, err)
+		message := "Failed to check account status"
+		if errors.Is(err, account.ErrAccountBanned) {
+			message = err.Error()
+		}
+		rejectConnection(conn, client.codec, errorCodeOf(err), message, 0)
+		return false
+	}
+	return true
+}
+
+// checkSanctions 帳號被封禁時返回 account.ErrAccountBanned
+func (h *WebSocketHandler) checkSanctions(r *http.Request, userID int64) error {
+	if h.hub.sanctions == nil {
+		return nil
+	}
+	return h.hub.sanctions.CheckLogin(r.Context(), userID)
+}
+
+// clientIP 取得請求來源 IP（去除端口）
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// rejectConnection 在註冊前拒絕連接：直接寫出錯誤消息並關閉（下線模式使用 1013 提示稍後重連，其餘為策略違規）
+func rejectConnection(conn *websocket.Conn, codec MessageCodec, code pb.ErrorCode, message string, retryAfter time.Duration) {
+	if bytes, err := codec.Marshal(newErrorMessage(code, message, retryAfter)); err == nil {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		conn.WriteMessage(codec.FrameType(), bytes)
+	}
+	closeCode := websocket.ClosePolicyViolation
+	if code == pb.ErrorCode_NODE_DRAINING {
+		closeCode = websocket.CloseTryAgainLater
+	}
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(closeCode, code.String()), time.Now().Add(writeWait))
+	conn.Close()
+}
+
 // readPump 從 WebSocket 連接讀取消息
 func (c *Client) readPump() {
 	defer func() {
@@ -321,7 +480,11 @@ func (c *Client) readPump() {
 	}()
 
 	// 設置讀取限制
-	c.conn.SetReadLimit(maxMessageSize)
+	if c.codec == ProtoJSONCodec {
+		c.conn.SetReadLimit(maxJSONMessageSize)
+	} else {
+		c.conn.SetReadLimit(maxMessageSize)
+	}
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
@@ -339,7 +502,7 @@ func (c *Client) readPump() {
 		if time.Since(lastResetTime) > time.Minute {
 			if errorCount > 50 { // 每分鐘超過50個錯誤
 				c.logger.Warnf("High error rate detected: %d errors in the last minute", errorCount)
-				c.sendErrorPB("Too many errors, please check your message format")
+				c.sendErrorPB(pb.ErrorCode_INVALID_MESSAGE, "Too many errors, please check your message format")
 				time.Sleep(5 * time.Second) // 暫停5秒
 			}
 			messageCount = 0
@@ -356,6 +519,11 @@ func (c *Client) readPump() {
 			break
 		}
 
+		// 已決定斷線時丟棄剩餘消息，等待 writePump 送出關閉原因後關閉連接
+		if c.closing.Load() {
+			continue
+		}
+
 		messageCount++
 		c.lastActivity = time.Now()
 
@@ -364,7 +532,7 @@ func (c *Client) readPump() {
 			defer func() {
 				if r := recover(); r != nil {
 					c.logger.Errorf("Recovered from panic while processing message: %v", r)
-					c.sendErrorPB("Error processing your message")
+					c.sendErrorPB(pb.ErrorCode_INTERNAL_ERROR, "Error processing your message")
 					errorCount++
 				}
 			}()
@@ -373,12 +541,16 @@ func (c *Client) readPump() {
 			case websocket.BinaryMessage:
 				c.handleBinaryMessage(message)
 			case websocket.TextMessage:
-				c.logger.Warnf("Received text message, expected binary: %s", string(message))
-				c.sendErrorPB("Text messages not supported, please use binary format")
-				errorCount++
+				if c.codec != ProtoJSONCodec {
+					c.logger.Warnf("Received text message, expected binary: %s", string(message))
+					c.sendErrorPB(pb.ErrorCode_UNSUPPORTED_MESSAGE, "Text messages not supported, please use binary format or negotiate protojson")
+					errorCount++
+					return
+				}
+				c.handleTextMessage(message)
 			default:
 				c.logger.Warnf("Unknown message type: %d", messageType)
-				c.sendErrorPB(fmt.Sprintf("Unsupported message type: %d", messageType))
+				c.sendErrorPB(pb.ErrorCode_UNSUPPORTED_MESSAGE, fmt.Sprintf("Unsupported message type: %d", messageType))
 				errorCount++
 			}
 		}()
@@ -388,8 +560,38 @@ func (c *Client) readPump() {
 // writePump 向 WebSocket 連接寫入消息
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
+
+	// 批量模式下的待發送消息和合併窗口定時器
+	var pending [][]byte
+	pendingBytes := 0
+	var flushTimer *time.Timer
+	var flushC <-chan time.Time
+
+	flush := func() error {
+		if flushTimer != nil {
+			flushTimer.Stop()
+			flushTimer = nil
+			flushC = nil
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		var err error
+		if len(pending) == 1 {
+			err = c.writeFrame(pending[0], 1, false)
+		} else {
+			err = c.writeFrame(encodeBatch(pending, time.Now().UnixMilli()), len(pending), true)
+		}
+		pending = pending[:0]
+		pendingBytes = 0
+		return err
+	}
+
 	defer func() {
 		ticker.Stop()
+		if flushTimer != nil {
+			flushTimer.Stop()
+		}
 		c.conn.Close()
 		c.logger.Infof("[WRITEPUMP] WritePump stopped for client %s", c.ID)
 	}()
@@ -399,18 +601,45 @@ func (c *Client) writePump() {
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
-				// Hub 關閉了通道
+				// Hub 關閉了通道，盡量送出剩餘的批量消息
 				c.logger.Warnf("[WRITEPUMP] Send channel closed for client %s", c.ID)
+				flush()
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
 
+			if isTickFlush(message) {
+				// 房間 tick 結束：本 tick 的消息合併為一幀送出
+				if err := flush(); err != nil {
+					c.logger.Errorf("[WRITEPUMP] Failed to write batch to WebSocket for client %s: %v", c.ID, err)
+					return
+				}
+				continue
+			}
+
+			if c.batching.Load() {
+				// 合併本 tick 的消息，tick 結束、合併窗口到期或超過上限時作為一幀送出
+				pending = append(pending, message)
+				pendingBytes += len(message)
+				if flushTimer == nil {
+					flushTimer = time.NewTimer(c.outbound.batchWindow)
+					flushC = flushTimer.C
+				}
+				if len(pending) >= c.outbound.maxMessages || pendingBytes >= c.outbound.maxBytes {
+					if err := flush(); err != nil {
+						c.logger.Errorf("[WRITEPUMP] Failed to write batch to WebSocket for client %s: %v", c.ID, err)
+						return
+					}
+				}
+				continue
+			}
+
 			c.logger.Infof("[WRITEPUMP] Received message from channel for client %s, size=%d bytes", c.ID, len(message))
 
 			// 發送第一個消息
-			if err := c.conn.WriteMessage(websocket.BinaryMessage, message); err != nil {
+			if err := c.writeFrame(message, 1, false); err != nil {
 				c.logger.Errorf("[WRITEPUMP] Failed to write message to WebSocket for client %s: %v", c.ID, err)
 				return
 			}
@@ -423,8 +652,10 @@ func (c *Client) writePump() {
 			}
 			for i := 0; i < n; i++ {
 				queuedMsg := <-c.send
-				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-				if err := c.conn.WriteMessage(websocket.BinaryMessage, queuedMsg); err != nil {
+				if isTickFlush(queuedMsg) {
+					continue
+				}
+				if err := c.writeFrame(queuedMsg, 1, false); err != nil {
 					c.logger.Errorf("[WRITEPUMP] Failed to write queued message %d to WebSocket for client %s: %v", i, c.ID, err)
 					return
 				}
@@ -433,6 +664,37 @@ func (c *Client) writePump() {
 				c.logger.Infof("[WRITEPUMP] ✓ Wrote %d queued messages to WebSocket for client %s", n, c.ID)
 			}
 
+		case req := <-c.closeReq:
+			// 送出已排隊的消息（包括關閉原因）後再發送關閉幀
+			for queued := len(c.send); queued > 0; queued-- {
+				message, ok := <-c.send
+				if !ok {
+					break
+				}
+				if isTickFlush(message) {
+					continue
+				}
+				pending = append(pending, message)
+			}
+			if c.batching.Load() {
+				flush()
+			} else {
+				for _, message := range pending {
+					if err := c.writeFrame(message, 1, false); err != nil {
+						break
+					}
+				}
+			}
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(req.code, req.text), time.Now().Add(writeWait))
+			return
+
+		case <-flushC:
+			if err := flush(); err != nil {
+				c.logger.Errorf("[WRITEPUMP] Failed to write batch to WebSocket for client %s: %v", c.ID, err)
+				return
+			}
+
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -443,41 +705,94 @@ func (c *Client) writePump() {
 	}
 }
 
+// writeFrame 按協商的編碼寫出一幀，按閾值決定是否壓縮並記錄流量統計
+// data 為 Protobuf 字節（所有發送路徑統一），非 Protobuf 連接在此轉碼
+func (c *Client) writeFrame(data []byte, messages int, batch bool) error {
+	frameType := websocket.BinaryMessage
+	if c.codec != nil && c.codec != ProtobufCodec {
+		encoded, err := encodeForCodec(c.codec, data)
+		if err != nil {
+			// 單條消息轉碼失敗不應中斷連接
+			c.logger.Errorf("[WRITEPUMP] Failed to encode message as %s for client %s: %v", c.codec.Name(), c.ID, err)
+			return nil
+		}
+		data = encoded
+		frameType = c.codec.FrameType()
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+	compressed := c.outbound.compression && len(data) >= c.outbound.compressionThreshold
+	c.conn.EnableWriteCompression(compressed)
+
+	if err := c.conn.WriteMessage(frameType, data); err != nil {
+		return err
+	}
+	c.traffic.record(len(data), messages, batch, compressed)
+	return nil
+}
+
+// TrafficStats 獲取連接的出站流量統計
+func (c *Client) TrafficStats() ClientTrafficStats {
+	stats := c.traffic.snapshot()
+	stats.Batching = c.batching.Load()
+	stats.Compression = c.outbound.compression
+	if c.codec != nil {
+		stats.Codec = c.codec.Name()
+	}
+	return stats
+}
+
 // handleBinaryMessage 處理二進制消息（Protobuf 格式）
 func (c *Client) handleBinaryMessage(message []byte) {
+	c.handleEncodedMessage(message, ProtobufCodec)
+}
+
+// handleTextMessage 處理文本消息（protojson 格式，需先協商）
+func (c *Client) handleTextMessage(message []byte) {
+	c.handleEncodedMessage(message, ProtoJSONCodec)
+}
+
+// handleEncodedMessage 按指定編碼解析消息並交給 MessageHandler 處理
+func (c *Client) handleEncodedMessage(message []byte, codec MessageCodec) {
 	// 添加 recover 機制防止 panic 導致整個連接崩潰
 	defer func() {
 		if r := recover(); r != nil {
-			c.logger.Errorf("Recovered from panic in handleBinaryMessage: %v", r)
-			c.sendErrorPB("Internal server error occurred while processing message")
+			c.logger.Errorf("Recovered from panic in handleEncodedMessage: %v", r)
+			c.sendErrorPB(pb.ErrorCode_INTERNAL_ERROR, "Internal server error occurred while processing message")
 		}
 	}()
 
 	// 基本消息大小檢查
 	if len(message) == 0 {
 		c.logger.Warnf("Received empty message")
-		c.sendErrorPB("Empty message received")
+		c.sendErrorPB(pb.ErrorCode_INVALID_MESSAGE, "Empty message received")
 		return
 	}
 
 	if len(message) > 1024*1024 { // 1MB 限制
 		c.logger.Warnf("Received oversized message: %d bytes", len(message))
-		c.sendErrorPB("Message too large")
+		c.sendErrorPB(pb.ErrorCode_INVALID_MESSAGE, "Message too large")
 		return
 	}
 
-	// 解析 Protobuf 消息
+	// 解析消息
 	var gameMsg pb.GameMessage
-	if err := proto.Unmarshal(message, &gameMsg); err != nil {
-		c.logger.Errorf("Failed to parse protobuf message: %v", err)
-		c.sendErrorPB("Invalid message format")
+	if err := codec.Unmarshal(message, &gameMsg); err != nil {
+		c.logger.Errorf("Failed to parse %s message: %v", codec.Name(), err)
+		c.sendErrorPB(pb.ErrorCode_INVALID_MESSAGE, "Invalid message format")
 		return
 	}
 
 	// 消息類型驗證
 	if gameMsg.Type == pb.MessageType_INVALID {
 		c.logger.Warnf("Received invalid message type")
-		c.sendErrorPB("Invalid message type")
+		c.sendErrorPB(pb.ErrorCode_INVALID_MESSAGE, "Invalid message type")
+		return
+	}
+
+	// 按消息類型限流
+	if !c.checkRateLimit(gameMsg.Type) {
 		return
 	}
 
@@ -488,7 +803,7 @@ func (c *Client) handleBinaryMessage(message []byte) {
         defer func() {
             if r := recover(); r != nil {
                 c.logger.Errorf("Recovered from panic in centralized MessageHandler: %v", r)
-                c.sendErrorPB("Error processing message")
+                c.sendErrorPB(pb.ErrorCode_INTERNAL_ERROR, "Error processing message")
             }
             close(done)
         }()
@@ -498,10 +813,60 @@ func (c *Client) handleBinaryMessage(message []byte) {
     case <-done:
     case <-time.After(5 * time.Second):
         c.logger.Errorf("Message processing timeout for type: %v", gameMsg.Type)
-        c.sendErrorPB("Message processing timeout")
+        c.sendErrorPB(pb.ErrorCode_REQUEST_TIMEOUT, "Message processing timeout")
     }
 }
 
+// checkRateLimit 執行限流檢查並套用逐級懲罰，返回 false 表示消息應被丟棄
+func (c *Client) checkRateLimit(msgType pb.MessageType) bool {
+	if c.closing.Load() {
+		return false
+	}
+	switch c.rateLimit.Check(msgType) {
+	case RateLimitAllow:
+		return true
+	case RateLimitDrop:
+		c.logger.Debugf("Rate limited %v from client %s, dropped", msgType, c.ID)
+	case RateLimitWarn:
+		c.logger.Warnf("Rate limited %v from client %s, warning sent", msgType, c.ID)
+		c.sendErrorPB(pb.ErrorCode_RATE_LIMITED, fmt.Sprintf("Too many %v messages, slow down", msgType))
+	case RateLimitDisconnect:
+		// 同一連接只記錄一次斷線，避免一輪洪水直接累積到封禁閾值
+		if !c.closing.CompareAndSwap(false, true) {
+			return false
+		}
+		c.logger.Warnf("Client %s (player=%d, ip=%s) exceeded rate limit repeatedly, disconnecting",
+			c.ID, c.PlayerID, c.remoteIP)
+		if c.hub.rateLimiter.recordDisconnect(c.PlayerID, c.remoteIP) {
+			c.sendErrorPB(pb.ErrorCode_TEMPORARILY_BANNED, "Temporarily banned due to message flooding")
+		}
+		// 由 writePump 送出封禁消息後關閉連接，之後 readPump 會退出並註銷客戶端
+		c.closeAfterFlush(websocket.ClosePolicyViolation, pb.ErrorCode_RATE_LIMITED.String())
+	}
+	return false
+}
+
+// closeRequest 關閉連接的狀態碼和原因
+type closeRequest struct {
+	code int
+	text string
+}
+
+// closeAfterFlush 請求 writePump 送出已排隊的消息後以指定狀態碼關閉連接
+// 沒有 writePump 的客戶端（測試構造）直接關閉
+func (c *Client) closeAfterFlush(code int, text string) {
+	if c.closeReq == nil {
+		c.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(code, text), time.Now().Add(writeWait))
+		c.conn.Close()
+		return
+	}
+	select {
+	case c.closeReq <- closeRequest{code: code, text: text}:
+	default: // 已經在關閉中
+	}
+}
+
 // handleMessageByType 根據消息類型處理消息
 func (c *Client) handleMessageByType(gameMsg *pb.GameMessage) {
 	switch gameMsg.Type {
@@ -523,14 +888,14 @@ func (c *Client) handleMessageByType(gameMsg *pb.GameMessage) {
 		c.handleSelectSeat(gameMsg)
 	default:
 		c.logger.Warnf("Unknown protobuf message type: %v", gameMsg.Type)
-		c.sendErrorPB(fmt.Sprintf("Unsupported message type: %v", gameMsg.Type))
+		c.sendErrorPB(pb.ErrorCode_UNSUPPORTED_MESSAGE, fmt.Sprintf("Unsupported message type: %v", gameMsg.Type))
 	}
 }
 
 // handleFireBullet 處理開火請求
 func (c *Client) handleFireBullet(msg *pb.GameMessage) {
 	if c.RoomID == "" {
-		c.sendErrorPB("Not in any room")
+		c.sendErrorPB(pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
 		return
 	}
 
@@ -547,7 +912,7 @@ func (c *Client) handleFireBullet(msg *pb.GameMessage) {
 // handleSwitchCannon 處理切換砲台請求
 func (c *Client) handleSwitchCannon(msg *pb.GameMessage) {
 	if c.RoomID == "" {
-		c.sendErrorPB("Not in any room")
+		c.sendErrorPB(pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
 		return
 	}
 
@@ -565,13 +930,13 @@ func (c *Client) handleSwitchCannon(msg *pb.GameMessage) {
 func (c *Client) handleJoinRoomPB(msg *pb.GameMessage) {
 	joinRoomMsg := msg.GetJoinRoom()
 	if joinRoomMsg == nil {
-		c.sendErrorPB("Invalid JoinRoom message")
+		c.sendErrorPB(pb.ErrorCode_INVALID_MESSAGE, "Invalid JoinRoom message")
 		return
 	}
 
 	roomID := joinRoomMsg.GetRoomId()
 	if roomID == "" {
-		c.sendErrorPB("Room ID cannot be empty")
+		c.sendErrorPB(pb.ErrorCode_INVALID_ARGUMENT, "Room ID cannot be empty")
 		return
 	}
 
@@ -584,7 +949,7 @@ func (c *Client) handleJoinRoomPB(msg *pb.GameMessage) {
 // handleLeaveRoomPB 處理離開房間請求
 func (c *Client) handleLeaveRoomPB(msg *pb.GameMessage) {
 	if c.RoomID == "" {
-		c.sendErrorPB("Not in any room")
+		c.sendErrorPB(pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
 		return
 	}
 
@@ -638,18 +1003,14 @@ func (c *Client) handleGetPlayerInfo(msg *pb.GameMessage) {
 	c.sendProtobuf(responseMsg)
 }
 
-func (c *Client) sendErrorPB(message string) {
-	errorMsg := &pb.GameMessage{
-		Type: pb.MessageType_ERROR,
-		Data: &pb.GameMessage_Error{
-			Error: &pb.ErrorMessage{
-				Message:   message,
-				Code:      "GENERAL_ERROR",
-				Timestamp: time.Now().Unix(),
-			},
-		},
-	}
-	c.sendProtobuf(errorMsg)
+// sendErrorPB 發送帶錯誤碼和重試提示的錯誤消息
+func (c *Client) sendErrorPB(code pb.ErrorCode, message string) {
+	c.sendProtobuf(newErrorMessage(code, message, 0))
+}
+
+// sendBizError 按業務層錯誤映射錯誤碼後發送錯誤消息
+func (c *Client) sendBizError(err error, message string) {
+	c.sendErrorPB(errorCodeOf(err), message)
 }
 
 // handleGetRoomList 處理獲取房間列表請求
@@ -700,7 +1061,7 @@ func (c *Client) handleHeartbeat(msg *pb.GameMessage) {
 // handleSelectSeat 處理選擇座位請求
 func (c *Client) handleSelectSeat(msg *pb.GameMessage) {
 	if c.RoomID == "" {
-		c.sendErrorPB("Not in any room")
+		c.sendErrorPB(pb.ErrorCode_NOT_IN_ROOM, "Not in any room")
 		return
 	}
 
@@ -723,5 +1084,8 @@ func (c *Client) GetConnectionInfo() map[string]interface{} {
 		"connected_at":  c.connectedAt,
 		"last_activity": c.lastActivity,
 		"send_queue":    len(c.send),
+		"protocol":      c.handshake.Version(),
+		"capabilities":  c.handshake.Capabilities(),
+		"traffic":       c.TrafficStats(),
 	}
 }
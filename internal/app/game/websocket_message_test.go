@@ -148,7 +148,7 @@ func TestProtobufMessageSending(t *testing.T) {
 		}
 
 		errorMessage := "Test error message"
-		client.sendErrorPB(errorMessage)
+		client.sendErrorPB(pb.ErrorCode_GENERAL_ERROR, errorMessage)
 
 		// 接收並驗證
 		select {
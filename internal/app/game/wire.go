@@ -11,9 +11,18 @@ import (
 // ProviderSet 遊戲應用層提供者集合
 var ProviderSet = wire.NewSet(
 	// WebSocket 相關組件
+	NewMessageRateLimiter,
+	NewMatchmaker,
 	NewHub,
 	NewWebSocketHandler,
 	NewMessageHandler,
+	NewNodeAgent,
+	NewRoomCheckpointer,
+	NewRoomLifecycleMonitor,
+	NewSeatKeeper,
+	NewRoomConfigWatcher,
+	NewRoomEventScheduler,
+	NewSessionWatcher,
 	
 	// 遊戲應用
 	NewGameApp,
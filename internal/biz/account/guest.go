@@ -0,0 +1,380 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 遊客進度和升級為正式帳號
+// ========================================
+//
+// 遊客沒有數據庫記錄，進度（餘額、累計投注和獎勵、每次入座的記錄）以遊客昵稱為 key 保存在 Redis，
+// 與遊客會話同樣按刷新令牌有效期過期，因此重新連接或刷新令牌後進度不會丟失。
+// 遊客可以把當前會話升級為用戶名密碼或 OAuth 帳號：按策略把部分餘額轉入新帳號的錢包，
+// 把入座記錄遷移為遊戲記錄，撤銷遊客會話並為新帳號創建會話。升級時遊客不能在房間中。
+
+// GuestInitialBalance 遊客初始餘額（分）
+const GuestInitialBalance int64 = 100000
+
+// maxGuestRounds 遊客進度保留的入座記錄數，超過時丟棄最早的記錄
+const maxGuestRounds = 100
+
+// guestCarryOverCurrency 遊客餘額轉入的錢包幣種，與註冊時創建的初始錢包一致
+const guestCarryOverCurrency = "CNY"
+
+// RevokeReasonGuestUpgrade 遊客升級為正式帳號後撤銷遊客會話
+const RevokeReasonGuestUpgrade = "guest_upgrade"
+
+// 遊客升級相關錯誤
+var (
+	ErrNotGuest             = errors.New("not a guest session")
+	ErrGuestInRoom          = errors.New("guest is seated in a room, leave the room before upgrading")
+	ErrGuestAlreadyUpgraded = errors.New("guest has already been upgraded")
+	ErrOAuthIdentityTaken   = errors.New("oauth identity is already linked to an account")
+)
+
+// GuestRound 遊客一次入座的記錄
+type GuestRound struct {
+	RoomID       string     `json:"room_id"`
+	StartedAt    time.Time  `json:"started_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	StartBalance int64      `json:"start_balance"`
+	EndBalance   int64      `json:"end_balance"`
+	TotalBets    int64      `json:"total_bets"`
+	TotalWins    int64      `json:"total_wins"`
+	BulletsFired int64      `json:"bullets_fired"`
+	FishCaught   int64      `json:"fish_caught"`
+}
+
+// GuestProgress 遊客的遊戲進度，金額單位為分
+type GuestProgress struct {
+	Nickname     string       `json:"nickname"`
+	Balance      int64        `json:"balance"`
+	TotalBets    int64        `json:"total_bets"`
+	TotalWins    int64        `json:"total_wins"`
+	BulletsFired int64        `json:"bullets_fired"`
+	FishCaught   int64        `json:"fish_caught"`
+	Rounds       []GuestRound `json:"rounds,omitempty"`
+	Current      *GuestRound  `json:"current,omitempty"` // 當前入座記錄，不在房間中時為空
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// NewGuestProgress 新遊客的進度
+func NewGuestProgress(nickname string, now time.Time) *GuestProgress {
+	return &GuestProgress{
+		Nickname:  nickname,
+		Balance:   GuestInitialBalance,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// RoomID 遊客當前所在的房間
+func (p *GuestProgress) RoomID() string {
+	if p.Current == nil {
+		return ""
+	}
+	return p.Current.RoomID
+}
+
+// StartRound 遊客入座，上一次入座未結束時先結束
+func (p *GuestProgress) StartRound(roomID string, balance int64, now time.Time) {
+	if p.Current != nil {
+		p.EndRound(balance, now)
+	}
+	p.Balance = balance
+	p.Current = &GuestRound{RoomID: roomID, StartedAt: now, StartBalance: balance}
+	p.UpdatedAt = now
+}
+
+// RecordShot 記錄一次開火
+func (p *GuestProgress) RecordShot(cost int64) {
+	p.TotalBets += cost
+	p.BulletsFired++
+	if p.Current != nil {
+		p.Current.TotalBets += cost
+		p.Current.BulletsFired++
+	}
+}
+
+// RecordCatch 記錄一次捕獲
+func (p *GuestProgress) RecordCatch(reward int64) {
+	p.TotalWins += reward
+	p.FishCaught++
+	if p.Current != nil {
+		p.Current.TotalWins += reward
+		p.Current.FishCaught++
+	}
+}
+
+// EndRound 遊客離座，保存餘額並把當前入座記錄歸檔
+func (p *GuestProgress) EndRound(balance int64, now time.Time) {
+	p.Balance = balance
+	p.UpdatedAt = now
+	if p.Current == nil {
+		return
+	}
+
+	round := *p.Current
+	round.EndedAt = &now
+	round.EndBalance = balance
+	p.Rounds = append(p.Rounds, round)
+	if len(p.Rounds) > maxGuestRounds {
+		p.Rounds = p.Rounds[len(p.Rounds)-maxGuestRounds:]
+	}
+	p.Current = nil
+}
+
+// GuestUpgradePolicy 遊客升級時餘額的轉入策略
+type GuestUpgradePolicy struct {
+	CarryOverRatio float64 // 轉入比例，0 表示不轉入
+	MaxCarryOver   int64   // 最多轉入（分），0 表示不限
+}
+
+// CarryOver 按策略計算轉入正式錢包的金額（分）
+func (p GuestUpgradePolicy) CarryOver(balance int64) int64 {
+	if balance <= 0 || p.CarryOverRatio <= 0 {
+		return 0
+	}
+	amount := int64(float64(balance) * min(p.CarryOverRatio, 1))
+	if p.MaxCarryOver > 0 && amount > p.MaxCarryOver {
+		amount = p.MaxCarryOver
+	}
+	return amount
+}
+
+// GuestIdentity 發起升級的遊客，來自遊客訪問令牌
+type GuestIdentity struct {
+	SessionID string
+	Nickname  string
+}
+
+// GuestUpgradeResult 升級結果
+type GuestUpgradeResult struct {
+	User           *User          `json:"user"`
+	Tokens         *SessionTokens `json:"-"`
+	GuestBalance   int64          `json:"guest_balance"`   // 升級時的遊客餘額（分）
+	CarriedOver    int64          `json:"carried_over"`    // 轉入錢包的金額（分）
+	MigratedRounds int            `json:"migrated_rounds"` // 遷移為遊戲記錄的入座記錄數
+}
+
+// GuestProgressStore 遊客進度的存儲（Redis）
+type GuestProgressStore interface {
+	// LoadGuestProgress 獲取遊客進度，沒有保存過時返回 nil；已升級時返回 ErrGuestAlreadyUpgraded
+	LoadGuestProgress(ctx context.Context, nickname string) (*GuestProgress, error)
+	// SaveGuestProgress 保存遊客進度並重新設置過期時間；已升級時返回 ErrGuestAlreadyUpgraded
+	SaveGuestProgress(ctx context.Context, progress *GuestProgress, ttl time.Duration) error
+	// ClaimGuestProgress 原子地把遊客標記為升級中並返回進度（沒有保存過時為 nil），之後的保存都會被拒絕；
+	// 遊客在房間中時返回 ErrGuestInRoom，已升級時返回 ErrGuestAlreadyUpgraded
+	ClaimGuestProgress(ctx context.Context, nickname string, ttl time.Duration) (*GuestProgress, error)
+	// ReleaseGuestProgress 升級失敗時撤銷升級標記
+	ReleaseGuestProgress(ctx context.Context, nickname string) error
+}
+
+// WalletCreditor 向玩家錢包轉入金額
+type WalletCreditor interface {
+	CreditWallet(ctx context.Context, userID int64, currency string, amount int64, txType, referenceID, description string, metadata map[string]interface{}) error
+}
+
+// GuestRecordMigrator 把遊客的入座記錄遷移為正式帳號的遊戲記錄
+type GuestRecordMigrator interface {
+	MigrateGuestRounds(ctx context.Context, userID int64, nickname string, rounds []GuestRound) (int, error)
+}
+
+// GuestUsecase 遊客進度和升級
+type GuestUsecase struct {
+	accounts      AccountUsecase
+	repo          AccountRepo
+	store         GuestProgressStore
+	sessions      *SessionUsecase
+	oauthService  OAuthService
+	walletCreator WalletCreator
+	wallets       WalletCreditor
+	records       GuestRecordMigrator
+	policy        GuestUpgradePolicy
+	logger        logger.Logger
+}
+
+// NewGuestUsecase 創建遊客用例
+func NewGuestUsecase(
+	accounts AccountUsecase,
+	repo AccountRepo,
+	store GuestProgressStore,
+	sessions *SessionUsecase,
+	oauthService OAuthService,
+	walletCreator WalletCreator,
+	wallets WalletCreditor,
+	records GuestRecordMigrator,
+	policy GuestUpgradePolicy,
+	logger logger.Logger,
+) *GuestUsecase {
+	return &GuestUsecase{
+		accounts:      accounts,
+		repo:          repo,
+		store:         store,
+		sessions:      sessions,
+		oauthService:  oauthService,
+		walletCreator: walletCreator,
+		wallets:       wallets,
+		records:       records,
+		policy:        policy,
+		logger:        logger.With("component", "guest_usecase"),
+	}
+}
+
+// progressTTL 遊客進度的有效期，與遊客會話一致
+func (uc *GuestUsecase) progressTTL() time.Duration {
+	return uc.sessions.tokens.RefreshTokenTTL()
+}
+
+// LoadProgress 獲取遊客進度，沒有保存過時返回新遊客的進度
+func (uc *GuestUsecase) LoadProgress(ctx context.Context, nickname string) (*GuestProgress, error) {
+	progress, err := uc.store.LoadGuestProgress(ctx, nickname)
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		progress = NewGuestProgress(nickname, time.Now().UTC())
+	}
+	return progress, nil
+}
+
+// CarryOver 遊客現在升級時可以轉入錢包的金額（分）
+func (uc *GuestUsecase) CarryOver(progress *GuestProgress) int64 {
+	return uc.policy.CarryOver(progress.Balance)
+}
+
+// SaveProgress 保存遊客進度
+func (uc *GuestUsecase) SaveProgress(ctx context.Context, progress *GuestProgress) error {
+	return uc.store.SaveGuestProgress(ctx, progress, uc.progressTTL())
+}
+
+// UpgradeWithPassword 把遊客升級為用戶名密碼帳號
+func (uc *GuestUsecase) UpgradeWithPassword(ctx context.Context, guest GuestIdentity, username, password string, client ClientInfo) (*GuestUpgradeResult, error) {
+	return uc.upgrade(ctx, guest, client, func(ctx context.Context) (*User, error) {
+		return uc.accounts.Register(ctx, username, password)
+	})
+}
+
+// UpgradeWithOAuth 把遊客升級為 OAuth 帳號，該第三方身份不能已經綁定其他帳號
+func (uc *GuestUsecase) UpgradeWithOAuth(ctx context.Context, guest GuestIdentity, provider, code, state string, client ClientInfo) (*GuestUpgradeResult, error) {
+	if _, err := uc.guestSession(ctx, guest); err != nil {
+		return nil, err
+	}
+
+	info, err := uc.oauthService.GetUserInfo(ctx, provider, code, state, OAuthIntent{Purpose: OAuthPurposeLogin})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth user info: %w", err)
+	}
+	existing, err := uc.repo.GetUserByThirdParty(ctx, provider, info.ThirdPartyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by third party: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrOAuthIdentityTaken
+	}
+
+	return uc.upgrade(ctx, guest, client, func(ctx context.Context) (*User, error) {
+		user, err := uc.repo.CreateUser(ctx, &User{
+			Nickname:           info.Nickname,
+			AvatarURL:          info.AvatarURL,
+			ThirdPartyProvider: provider,
+			ThirdPartyID:       info.ThirdPartyID,
+		}, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create oauth user: %w", err)
+		}
+		if uc.walletCreator != nil {
+			if err := uc.walletCreator.CreateWallet(ctx, uint(user.ID), guestCarryOverCurrency); err != nil {
+				return nil, fmt.Errorf("failed to create wallet for user %d: %w", user.ID, err)
+			}
+		}
+		return user, nil
+	})
+}
+
+// guestSession 獲取遊客會話，舊版遊客令牌沒有會話，不能升級
+func (uc *GuestUsecase) guestSession(ctx context.Context, guest GuestIdentity) (*Session, error) {
+	if guest.SessionID == "" || guest.Nickname == "" {
+		return nil, ErrNotGuest
+	}
+	session, _, err := uc.sessions.store.GetSession(ctx, guest.SessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, ErrNotGuest
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsGuest || session.Nickname != guest.Nickname {
+		return nil, ErrNotGuest
+	}
+	return session, nil
+}
+
+// upgrade 鎖定遊客進度、創建帳號、轉入餘額、遷移記錄並切換會話
+// 帳號創建後的步驟失敗只記錄日誌，不影響升級結果
+func (uc *GuestUsecase) upgrade(ctx context.Context, guest GuestIdentity, client ClientInfo, create func(ctx context.Context) (*User, error)) (*GuestUpgradeResult, error) {
+	session, err := uc.guestSession(ctx, guest)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := uc.store.ClaimGuestProgress(ctx, guest.Nickname, uc.progressTTL())
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		progress = NewGuestProgress(guest.Nickname, time.Now().UTC())
+	}
+
+	user, err := create(ctx)
+	if err != nil {
+		if releaseErr := uc.store.ReleaseGuestProgress(ctx, guest.Nickname); releaseErr != nil {
+			uc.logger.Errorf("Failed to release guest %s after failed upgrade: %v", guest.Nickname, releaseErr)
+		}
+		return nil, err
+	}
+
+	result := &GuestUpgradeResult{User: user, GuestBalance: progress.Balance}
+
+	if amount := uc.policy.CarryOver(progress.Balance); amount > 0 {
+		err := uc.wallets.CreditWallet(ctx, user.ID, guestCarryOverCurrency, amount,
+			"guest_upgrade", "guest_upgrade:"+guest.Nickname, "遊客升級轉入餘額",
+			map[string]interface{}{
+				"guest_nickname": guest.Nickname,
+				"guest_balance":  progress.Balance,
+			})
+		if err != nil {
+			uc.logger.Errorf("Failed to carry over %d of guest %s balance to user %d: %v", amount, guest.Nickname, user.ID, err)
+		} else {
+			result.CarriedOver = amount
+		}
+	}
+
+	if len(progress.Rounds) > 0 {
+		migrated, err := uc.records.MigrateGuestRounds(ctx, user.ID, guest.Nickname, progress.Rounds)
+		if err != nil {
+			uc.logger.Errorf("Failed to migrate guest %s records to user %d: %v", guest.Nickname, user.ID, err)
+		}
+		result.MigratedRounds = migrated
+	}
+
+	if err := uc.sessions.revoke(ctx, session, RevokeReasonGuestUpgrade); err != nil {
+		uc.logger.Errorf("Failed to revoke guest session %s after upgrade: %v", session.ID, err)
+	}
+
+	result.Tokens, err = uc.sessions.StartSession(ctx, user.ID, false, "", client)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Infof("Guest %s upgraded to user %d: balance=%d, carried_over=%d, migrated_rounds=%d",
+		guest.Nickname, user.ID, progress.Balance, result.CarriedOver, result.MigratedRounds)
+	return result, nil
+}
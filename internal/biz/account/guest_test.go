@@ -0,0 +1,242 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGuestProgressStore 記憶體中的遊客進度存儲，行為與 Redis 腳本一致
+type fakeGuestProgressStore struct {
+	progress map[string]*GuestProgress
+	upgraded map[string]bool
+}
+
+func newFakeGuestProgressStore() *fakeGuestProgressStore {
+	return &fakeGuestProgressStore{progress: make(map[string]*GuestProgress), upgraded: make(map[string]bool)}
+}
+
+func (s *fakeGuestProgressStore) LoadGuestProgress(ctx context.Context, nickname string) (*GuestProgress, error) {
+	if s.upgraded[nickname] {
+		return nil, ErrGuestAlreadyUpgraded
+	}
+	return s.progress[nickname], nil
+}
+
+func (s *fakeGuestProgressStore) SaveGuestProgress(ctx context.Context, progress *GuestProgress, ttl time.Duration) error {
+	if s.upgraded[progress.Nickname] {
+		return ErrGuestAlreadyUpgraded
+	}
+	copied := *progress
+	s.progress[progress.Nickname] = &copied
+	return nil
+}
+
+func (s *fakeGuestProgressStore) ClaimGuestProgress(ctx context.Context, nickname string, ttl time.Duration) (*GuestProgress, error) {
+	if s.upgraded[nickname] {
+		return nil, ErrGuestAlreadyUpgraded
+	}
+	progress := s.progress[nickname]
+	if progress != nil && progress.RoomID() != "" {
+		return nil, ErrGuestInRoom
+	}
+	s.upgraded[nickname] = true
+	return progress, nil
+}
+
+func (s *fakeGuestProgressStore) ReleaseGuestProgress(ctx context.Context, nickname string) error {
+	delete(s.upgraded, nickname)
+	return nil
+}
+
+// stubRegistrar 只實現 Register 的帳號用例
+type stubRegistrar struct {
+	AccountUsecase
+	err error
+}
+
+func (r *stubRegistrar) Register(ctx context.Context, username, password string) (*User, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &User{ID: 42, Username: username, Nickname: username}, nil
+}
+
+// fakeWalletCreditor 記錄轉入的金額
+type fakeWalletCreditor struct {
+	credited map[int64]int64
+}
+
+func (w *fakeWalletCreditor) CreditWallet(ctx context.Context, userID int64, currency string, amount int64, txType, referenceID, description string, metadata map[string]interface{}) error {
+	w.credited[userID] += amount
+	return nil
+}
+
+// fakeGuestRecordMigrator 記錄遷移的入座記錄
+type fakeGuestRecordMigrator struct {
+	rounds []GuestRound
+}
+
+func (m *fakeGuestRecordMigrator) MigrateGuestRounds(ctx context.Context, userID int64, nickname string, rounds []GuestRound) (int, error) {
+	m.rounds = append(m.rounds, rounds...)
+	return len(rounds), nil
+}
+
+type guestTestEnv struct {
+	uc        *GuestUsecase
+	sessions  *SessionUsecase
+	events    *fakeSessionEvents
+	store     *fakeGuestProgressStore
+	registrar *stubRegistrar
+	wallets   *fakeWalletCreditor
+	records   *fakeGuestRecordMigrator
+}
+
+func setupTestGuestUsecase() *guestTestEnv {
+	sessions, _, events := setupTestSessionUsecase()
+	env := &guestTestEnv{
+		sessions:  sessions,
+		events:    events,
+		store:     newFakeGuestProgressStore(),
+		registrar: &stubRegistrar{},
+		wallets:   &fakeWalletCreditor{credited: make(map[int64]int64)},
+		records:   &fakeGuestRecordMigrator{},
+	}
+	env.uc = NewGuestUsecase(env.registrar, nil, env.store, sessions, nil, nil, env.wallets, env.records,
+		GuestUpgradePolicy{CarryOverRatio: 0.1, MaxCarryOver: 10000}, logger.New(io.Discard, "info", "console"))
+	return env
+}
+
+// startGuest 創建遊客會話
+func (env *guestTestEnv) startGuest(t *testing.T, nickname string) GuestIdentity {
+	tokens, err := env.sessions.StartSession(context.Background(), 0, true, nickname, ClientInfo{})
+	require.NoError(t, err)
+	return GuestIdentity{SessionID: tokens.SessionID, Nickname: nickname}
+}
+
+func TestGuestProgressRounds(t *testing.T) {
+	now := time.Now().UTC()
+	progress := NewGuestProgress("guest_1", now)
+	assert.Equal(t, GuestInitialBalance, progress.Balance)
+	assert.Empty(t, progress.RoomID())
+
+	progress.StartRound("room_1", 100000, now)
+	progress.RecordShot(100)
+	progress.RecordShot(100)
+	progress.RecordCatch(500)
+	assert.Equal(t, "room_1", progress.RoomID())
+
+	progress.EndRound(100300, now.Add(time.Minute))
+	assert.Empty(t, progress.RoomID())
+	assert.Equal(t, int64(100300), progress.Balance)
+	require.Len(t, progress.Rounds, 1)
+	round := progress.Rounds[0]
+	assert.Equal(t, int64(200), round.TotalBets)
+	assert.Equal(t, int64(500), round.TotalWins)
+	assert.Equal(t, int64(2), round.BulletsFired)
+	assert.Equal(t, int64(1), round.FishCaught)
+	assert.Equal(t, int64(100000), round.StartBalance)
+	assert.Equal(t, int64(100300), round.EndBalance)
+	require.NotNil(t, round.EndedAt)
+
+	// 未結束的入座在下一次入座時結束
+	progress.StartRound("room_2", 100300, now)
+	progress.StartRound("room_3", 99000, now)
+	assert.Len(t, progress.Rounds, 2)
+	assert.Equal(t, "room_3", progress.RoomID())
+
+	for i := 0; i < maxGuestRounds+10; i++ {
+		progress.StartRound("room", 1000, now)
+	}
+	progress.EndRound(1000, now)
+	assert.Len(t, progress.Rounds, maxGuestRounds)
+}
+
+func TestGuestUpgradePolicyCarryOver(t *testing.T) {
+	policy := GuestUpgradePolicy{CarryOverRatio: 0.1, MaxCarryOver: 10000}
+	assert.Equal(t, int64(5000), policy.CarryOver(50000))
+	assert.Equal(t, int64(10000), policy.CarryOver(500000))
+	assert.Equal(t, int64(0), policy.CarryOver(-10))
+
+	assert.Equal(t, int64(0), GuestUpgradePolicy{}.CarryOver(50000))
+	assert.Equal(t, int64(50000), GuestUpgradePolicy{CarryOverRatio: 2}.CarryOver(50000))
+}
+
+func TestGuestUpgradeWithPassword(t *testing.T) {
+	ctx := context.Background()
+	env := setupTestGuestUsecase()
+	guest := env.startGuest(t, "guest_1")
+
+	progress, err := env.uc.LoadProgress(ctx, guest.Nickname)
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	progress.StartRound("room_1", progress.Balance, now)
+	progress.RecordShot(1000)
+	progress.EndRound(80000, now)
+	require.NoError(t, env.uc.SaveProgress(ctx, progress))
+
+	result, err := env.uc.UpgradeWithPassword(ctx, guest, "alice", "secret1", ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), result.User.ID)
+	assert.Equal(t, int64(80000), result.GuestBalance)
+	assert.Equal(t, int64(8000), result.CarriedOver)
+	assert.Equal(t, 1, result.MigratedRounds)
+	assert.Equal(t, int64(8000), env.wallets.credited[42])
+	require.Len(t, env.records.rounds, 1)
+
+	// 新會話屬於正式帳號，遊客會話被撤銷
+	require.NotNil(t, result.Tokens)
+	active, err := env.sessions.SessionActive(ctx, guest.SessionID)
+	require.NoError(t, err)
+	assert.False(t, active)
+	require.Len(t, env.events.published, 1)
+	assert.Equal(t, RevokeReasonGuestUpgrade, env.events.published[0].Reason)
+
+	// 升級後遊客不能再保存進度或重複升級
+	assert.ErrorIs(t, env.uc.SaveProgress(ctx, progress), ErrGuestAlreadyUpgraded)
+	_, err = env.uc.LoadProgress(ctx, guest.Nickname)
+	assert.ErrorIs(t, err, ErrGuestAlreadyUpgraded)
+	_, err = env.uc.UpgradeWithPassword(ctx, guest, "alice2", "secret1", ClientInfo{})
+	assert.ErrorIs(t, err, ErrNotGuest)
+}
+
+func TestGuestUpgradeRejected(t *testing.T) {
+	ctx := context.Background()
+	env := setupTestGuestUsecase()
+
+	// 舊版令牌和正式帳號的會話不能升級
+	_, err := env.uc.UpgradeWithPassword(ctx, GuestIdentity{Nickname: "guest_1"}, "alice", "secret1", ClientInfo{})
+	assert.ErrorIs(t, err, ErrNotGuest)
+	userTokens, err := env.sessions.StartSession(ctx, 7, false, "", ClientInfo{})
+	require.NoError(t, err)
+	_, err = env.uc.UpgradeWithPassword(ctx, GuestIdentity{SessionID: userTokens.SessionID, Nickname: "guest_1"}, "alice", "secret1", ClientInfo{})
+	assert.ErrorIs(t, err, ErrNotGuest)
+
+	// 在房間中不能升級
+	guest := env.startGuest(t, "guest_1")
+	progress, err := env.uc.LoadProgress(ctx, guest.Nickname)
+	require.NoError(t, err)
+	progress.StartRound("room_1", progress.Balance, time.Now().UTC())
+	require.NoError(t, env.uc.SaveProgress(ctx, progress))
+	_, err = env.uc.UpgradeWithPassword(ctx, guest, "alice", "secret1", ClientInfo{})
+	assert.ErrorIs(t, err, ErrGuestInRoom)
+
+	// 創建帳號失敗時撤銷升級標記，遊客可以繼續遊戲
+	progress.EndRound(progress.Balance, time.Now().UTC())
+	require.NoError(t, env.uc.SaveProgress(ctx, progress))
+	env.registrar.err = errors.New("username taken")
+	_, err = env.uc.UpgradeWithPassword(ctx, guest, "alice", "secret1", ClientInfo{})
+	assert.EqualError(t, err, "username taken")
+	assert.NoError(t, env.uc.SaveProgress(ctx, progress))
+	assert.Empty(t, env.wallets.credited)
+
+	active, err := env.sessions.SessionActive(ctx, guest.SessionID)
+	require.NoError(t, err)
+	assert.True(t, active)
+}
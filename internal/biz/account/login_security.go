@@ -0,0 +1,341 @@
+package account
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 登入安全：失敗限流、臨時鎖定和登入記錄
+// ========================================
+//
+// 密碼登入按帳號名和來源 IP 分別統計失敗次數（Redis，統計窗口內有效）。超過免費次數後每次失敗
+// 都要等待指數增長的時間才能再試，達到鎖定閾值時臨時鎖定；被限流時在查詢帳號和校驗密碼之前拒絕，
+// 不洩露帳號是否存在。登入成功只清除帳號名的計數，IP 計數繼續累積，避免撞庫時用自己的帳號重置。
+// 每次登入（包括 OAuth 和失敗的嘗試）記錄 IP、User-Agent 和設備 ID，首次出現的設備標記為新設備。
+
+// 登入限流相關錯誤
+var (
+	ErrLoginThrottled = errors.New("too many failed login attempts")
+	ErrInvalidLogin   = errors.New("invalid username or password")
+)
+
+// 限流統計的範圍
+const (
+	ThrottleScopeUsername = "username"
+	ThrottleScopeIP       = "ip"
+)
+
+// 登入方式
+const (
+	LoginMethodPassword = "password"
+	LoginMethodOAuth    = "oauth"
+)
+
+// 登入失敗原因
+const (
+	LoginFailureBadCredentials = "bad_credentials"
+	LoginFailureBanned         = "banned"
+)
+
+// LoginThrottledError 登入被限流，RetryAfter 後可以再試
+type LoginThrottledError struct {
+	Scope      string // ThrottleScopeUsername 或 ThrottleScopeIP
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrLoginThrottled, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap 支持 errors.Is(err, ErrLoginThrottled)
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// ThrottleRule 一個統計範圍的限流閾值
+type ThrottleRule struct {
+	FreeAttempts     int // 不需要等待的失敗次數
+	LockoutThreshold int // 達到後臨時鎖定 LockoutDuration，0 表示不鎖定
+}
+
+// LoginThrottlePolicy 登入失敗限流策略
+type LoginThrottlePolicy struct {
+	Username        ThrottleRule
+	IP              ThrottleRule
+	Window          time.Duration // 失敗計數的統計窗口，最近一次失敗後超過窗口清零
+	BaseDelay       time.Duration // 超過免費次數後第一次失敗的等待時間，之後每次翻倍
+	MaxDelay        time.Duration // 單次退避的最長等待時間
+	LockoutDuration time.Duration // 臨時鎖定時長
+}
+
+// DefaultLoginThrottlePolicy 默認策略：同一帳號 5 次、同一 IP 20 次失敗後開始退避，10 次、50 次後鎖定 15 分鐘
+func DefaultLoginThrottlePolicy() LoginThrottlePolicy {
+	return LoginThrottlePolicy{
+		Username:        ThrottleRule{FreeAttempts: 5, LockoutThreshold: 10},
+		IP:              ThrottleRule{FreeAttempts: 20, LockoutThreshold: 50},
+		Window:          15 * time.Minute,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutDuration: 15 * time.Minute,
+	}
+}
+
+// delay 第 failures 次失敗後需要等待的時間，以及是否為臨時鎖定
+func (p LoginThrottlePolicy) delay(rule ThrottleRule, failures int) (time.Duration, bool) {
+	if rule.LockoutThreshold > 0 && failures >= rule.LockoutThreshold {
+		return p.LockoutDuration, true
+	}
+	if failures <= rule.FreeAttempts {
+		return 0, false
+	}
+	delay := p.BaseDelay
+	for i := rule.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay, false
+}
+
+// LoginAttemptStore 登入失敗計數和鎖定（Redis），key 為 "<scope>:<值>"
+type LoginAttemptStore interface {
+	// RecordFailure 增加失敗次數並返回累計值，計數在最近一次失敗 window 後過期
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock 在 until 之前拒絕該 key 的登入
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil 鎖定到期時間，未鎖定時返回零值
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Reset 清除失敗次數和鎖定
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginRecord 一次登入記錄
+type LoginRecord struct {
+	ID            int64     `json:"id"`
+	UserID        int64     `json:"user_id"`
+	Method        string    `json:"method"` // password 或 oauth:<平台>
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IP            string    `json:"ip"`
+	UserAgent     string    `json:"user_agent"`
+	DeviceID      string    `json:"device_id,omitempty"` // 客戶端提供的設備 ID
+	Fingerprint   string    `json:"fingerprint"`         // 設備指紋，見 DeviceFingerprint
+	NewDevice     bool      `json:"new_device"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Device 帳號登入過的設備
+type Device struct {
+	UserID      int64     `json:"user_id"`
+	Fingerprint string    `json:"fingerprint"`
+	DeviceID    string    `json:"device_id,omitempty"`
+	UserAgent   string    `json:"user_agent"`
+	LastIP      string    `json:"last_ip"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// LoginHistoryRepo 登入記錄和已知設備的持久化
+type LoginHistoryRepo interface {
+	// AppendLogin 追加登入記錄並寫回 ID 和時間
+	AppendLogin(ctx context.Context, record *LoginRecord) error
+	// ListLogins 帳號最近的登入記錄，最新的在前
+	ListLogins(ctx context.Context, userID int64, limit int) ([]*LoginRecord, error)
+	// TouchDevice 記錄設備登入成功，返回設備是否首次出現
+	TouchDevice(ctx context.Context, device *Device) (bool, error)
+	// CountDevices 帳號已知的設備數量
+	CountDevices(ctx context.Context, userID int64) (int, error)
+	// ListDevices 帳號已知的設備，最近使用的在前
+	ListDevices(ctx context.Context, userID int64) ([]*Device, error)
+}
+
+// DeviceFingerprint 設備指紋：客戶端提供了設備 ID 時使用設備 ID，否則使用 User-Agent
+func DeviceFingerprint(client ClientInfo) string {
+	source := "ua:" + client.UserAgent
+	if id := strings.TrimSpace(client.DeviceID); id != "" {
+		source = "device:" + id
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:16])
+}
+
+// 登入記錄查詢數量
+const (
+	defaultLoginHistoryLimit = 50
+	maxLoginHistoryLimit     = 200
+)
+
+// LoginSecurityUsecase 登入失敗限流、登入記錄和新設備檢測
+type LoginSecurityUsecase struct {
+	attempts LoginAttemptStore
+	history  LoginHistoryRepo
+	policy   LoginThrottlePolicy
+	logger   logger.Logger
+}
+
+// NewLoginSecurityUsecase 創建登入安全用例
+func NewLoginSecurityUsecase(attempts LoginAttemptStore, history LoginHistoryRepo, policy LoginThrottlePolicy, logger logger.Logger) *LoginSecurityUsecase {
+	return &LoginSecurityUsecase{
+		attempts: attempts,
+		history:  history,
+		policy:   policy,
+		logger:   logger.With("component", "login_security"),
+	}
+}
+
+// throttleKey 一個限流計數 key 及其閾值
+type throttleKey struct {
+	scope string
+	key   string
+	rule  ThrottleRule
+}
+
+// usernameThrottleKey 帳號名的計數 key，不區分大小寫
+func usernameThrottleKey(username string) string {
+	return ThrottleScopeUsername + ":" + strings.ToLower(strings.TrimSpace(username))
+}
+
+// throttleKeys 帳號名和 IP 的計數 key
+func (s *LoginSecurityUsecase) throttleKeys(username, ip string) []throttleKey {
+	keys := []throttleKey{{ThrottleScopeUsername, usernameThrottleKey(username), s.policy.Username}}
+	if ip != "" {
+		keys = append(keys, throttleKey{ThrottleScopeIP, ThrottleScopeIP + ":" + ip, s.policy.IP})
+	}
+	return keys
+}
+
+// CheckAllowed 帳號名或 IP 處於退避或鎖定中時返回 *LoginThrottledError，Redis 不可用時放行
+func (s *LoginSecurityUsecase) CheckAllowed(ctx context.Context, username, ip string) error {
+	now := time.Now()
+	for _, k := range s.throttleKeys(username, ip) {
+		until, err := s.attempts.LockedUntil(ctx, k.key)
+		if err != nil {
+			s.logger.Warnf("Failed to check login throttle %s: %v", k.key, err)
+			continue
+		}
+		if until.After(now) {
+			return &LoginThrottledError{Scope: k.scope, RetryAfter: until.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// RecordFailure 記錄一次密碼錯誤：累加帳號名和 IP 的失敗次數，超過閾值時退避或鎖定；
+// userID 大於 0（帳號存在）時寫入該帳號的登入記錄
+func (s *LoginSecurityUsecase) RecordFailure(ctx context.Context, userID int64, username string, client ClientInfo) {
+	now := time.Now()
+	for _, k := range s.throttleKeys(username, client.IP) {
+		failures, err := s.attempts.RecordFailure(ctx, k.key, s.policy.Window)
+		if err != nil {
+			s.logger.Warnf("Failed to record login failure %s: %v", k.key, err)
+			continue
+		}
+		delay, locked := s.policy.delay(k.rule, failures)
+		if delay <= 0 {
+			continue
+		}
+		if err := s.attempts.Lock(ctx, k.key, now.Add(delay)); err != nil {
+			s.logger.Warnf("Failed to lock login %s: %v", k.key, err)
+			continue
+		}
+		if locked {
+			s.logger.Warnf("Login %s locked for %s after %d failed attempts", k.key, delay, failures)
+		}
+	}
+
+	if userID > 0 {
+		s.appendLogin(ctx, &LoginRecord{UserID: userID, Method: LoginMethodPassword, FailureReason: LoginFailureBadCredentials}, client)
+	}
+}
+
+// RecordRejected 記錄憑證正確但被拒絕的登入（例如帳號被封禁），不計入失敗次數
+func (s *LoginSecurityUsecase) RecordRejected(ctx context.Context, userID int64, method, reason string, client ClientInfo) {
+	s.appendLogin(ctx, &LoginRecord{UserID: userID, Method: method, FailureReason: reason}, client)
+}
+
+// RecordSuccess 記錄登入成功：清除帳號名的失敗計數，登記設備，返回是否為新設備。
+// 帳號第一次登入的設備不算新設備
+func (s *LoginSecurityUsecase) RecordSuccess(ctx context.Context, userID int64, username, method string, client ClientInfo) bool {
+	if username != "" {
+		key := usernameThrottleKey(username)
+		if err := s.attempts.Reset(ctx, key); err != nil {
+			s.logger.Warnf("Failed to reset login throttle %s: %v", key, err)
+		}
+	}
+
+	record := &LoginRecord{UserID: userID, Method: method, Success: true}
+	known, err := s.history.CountDevices(ctx, userID)
+	if err != nil {
+		s.logger.Warnf("Failed to count devices of user %d: %v", userID, err)
+	}
+	firstSeen, err := s.history.TouchDevice(ctx, &Device{
+		UserID:      userID,
+		Fingerprint: DeviceFingerprint(client),
+		DeviceID:    strings.TrimSpace(client.DeviceID),
+		UserAgent:   client.UserAgent,
+		LastIP:      client.IP,
+	})
+	if err != nil {
+		s.logger.Warnf("Failed to record device of user %d: %v", userID, err)
+	}
+	record.NewDevice = firstSeen && known > 0
+	if record.NewDevice {
+		s.logger.Warnf("User %d logged in from a new device (ip %s, user agent %q)", userID, client.IP, client.UserAgent)
+	}
+
+	s.appendLogin(ctx, record, client)
+	return record.NewDevice
+}
+
+// appendLogin 補齊客戶端信息後寫入登入記錄，失敗只記日誌
+func (s *LoginSecurityUsecase) appendLogin(ctx context.Context, record *LoginRecord, client ClientInfo) {
+	record.IP = client.IP
+	record.UserAgent = client.UserAgent
+	record.DeviceID = strings.TrimSpace(client.DeviceID)
+	record.Fingerprint = DeviceFingerprint(client)
+	if err := s.history.AppendLogin(ctx, record); err != nil {
+		s.logger.Warnf("Failed to append login record of user %d: %v", record.UserID, err)
+	}
+}
+
+// History 帳號最近的登入記錄，limit 默認 50、最多 200
+func (s *LoginSecurityUsecase) History(ctx context.Context, userID int64, limit int) ([]*LoginRecord, error) {
+	if limit <= 0 {
+		limit = defaultLoginHistoryLimit
+	}
+	if limit > maxLoginHistoryLimit {
+		limit = maxLoginHistoryLimit
+	}
+	records, err := s.history.ListLogins(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login history: %w", err)
+	}
+	return records, nil
+}
+
+// Devices 帳號已知的設備
+func (s *LoginSecurityUsecase) Devices(ctx context.Context, userID int64) ([]*Device, error) {
+	devices, err := s.history.ListDevices(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	return devices, nil
+}
+
+// Unlock 清除帳號名的失敗次數和鎖定（管理員操作）
+func (s *LoginSecurityUsecase) Unlock(ctx context.Context, username string) error {
+	key := usernameThrottleKey(username)
+	if err := s.attempts.Reset(ctx, key); err != nil {
+		return fmt.Errorf("failed to unlock login %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeLoginAttemptStore 記憶體中的失敗計數和鎖定，不模擬統計窗口過期
+type fakeLoginAttemptStore struct {
+	failures map[string]int
+	locks    map[string]time.Time
+}
+
+func newFakeLoginAttemptStore() *fakeLoginAttemptStore {
+	return &fakeLoginAttemptStore{failures: make(map[string]int), locks: make(map[string]time.Time)}
+}
+
+func (s *fakeLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.failures[key]++
+	return s.failures[key], nil
+}
+
+func (s *fakeLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	if until.After(s.locks[key]) {
+		s.locks[key] = until
+	}
+	return nil
+}
+
+func (s *fakeLoginAttemptStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	return s.locks[key], nil
+}
+
+func (s *fakeLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	delete(s.failures, key)
+	delete(s.locks, key)
+	return nil
+}
+
+// fakeLoginHistoryRepo 記憶體中的登入記錄和設備
+type fakeLoginHistoryRepo struct {
+	records []*LoginRecord
+	devices map[int64][]*Device
+}
+
+func newFakeLoginHistoryRepo() *fakeLoginHistoryRepo {
+	return &fakeLoginHistoryRepo{devices: make(map[int64][]*Device)}
+}
+
+func (r *fakeLoginHistoryRepo) AppendLogin(ctx context.Context, record *LoginRecord) error {
+	record.ID = int64(len(r.records) + 1)
+	record.CreatedAt = time.Now()
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *fakeLoginHistoryRepo) ListLogins(ctx context.Context, userID int64, limit int) ([]*LoginRecord, error) {
+	var result []*LoginRecord
+	for i := len(r.records) - 1; i >= 0 && len(result) < limit; i-- {
+		if r.records[i].UserID == userID {
+			result = append(result, r.records[i])
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeLoginHistoryRepo) TouchDevice(ctx context.Context, device *Device) (bool, error) {
+	now := time.Now()
+	for _, d := range r.devices[device.UserID] {
+		if d.Fingerprint == device.Fingerprint {
+			d.LastIP, d.LastSeenAt = device.LastIP, now
+			return false, nil
+		}
+	}
+	device.FirstSeenAt, device.LastSeenAt = now, now
+	r.devices[device.UserID] = append(r.devices[device.UserID], device)
+	return true, nil
+}
+
+func (r *fakeLoginHistoryRepo) CountDevices(ctx context.Context, userID int64) (int, error) {
+	return len(r.devices[userID]), nil
+}
+
+func (r *fakeLoginHistoryRepo) ListDevices(ctx context.Context, userID int64) ([]*Device, error) {
+	return r.devices[userID], nil
+}
+
+type loginSecurityTestEnv struct {
+	uc       AccountUsecase
+	security *LoginSecurityUsecase
+	attempts *fakeLoginAttemptStore
+	history  *fakeLoginHistoryRepo
+	user     *User
+}
+
+// setupTestLoginSecurity 創建帶有帳號 alice（密碼 secret1）的登入環境
+func setupTestLoginSecurity(t *testing.T, policy LoginThrottlePolicy) *loginSecurityTestEnv {
+	t.Helper()
+	sessions, _, _ := setupTestSessionUsecase()
+	attempts, history := newFakeLoginAttemptStore(), newFakeLoginHistoryRepo()
+	security := NewLoginSecurityUsecase(attempts, history, policy, logger.New(io.Discard, "info", "console"))
+	repo := newFakeAccountRepo()
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret1"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user, err := repo.CreateUser(context.Background(), &User{Username: "alice"}, string(hash))
+	require.NoError(t, err)
+	return &loginSecurityTestEnv{
+		uc:       NewAccountUsecase(repo, sessions, nil, security, nil, nil),
+		security: security,
+		attempts: attempts,
+		history:  history,
+		user:     user,
+	}
+}
+
+func TestLoginThrottlePolicyDelay(t *testing.T) {
+	policy := DefaultLoginThrottlePolicy()
+	cases := []struct {
+		failures int
+		delay    time.Duration
+		locked   bool
+	}{
+		{5, 0, false},
+		{6, time.Second, false},
+		{7, 2 * time.Second, false},
+		{9, 8 * time.Second, false},
+		{10, 15 * time.Minute, true},
+	}
+	for _, tc := range cases {
+		delay, locked := policy.delay(policy.Username, tc.failures)
+		assert.Equal(t, tc.delay, delay, "failures %d", tc.failures)
+		assert.Equal(t, tc.locked, locked, "failures %d", tc.failures)
+	}
+
+	// 沒有鎖定閾值時退避不超過 MaxDelay
+	delay, locked := policy.delay(ThrottleRule{FreeAttempts: 1}, 40)
+	assert.Equal(t, policy.MaxDelay, delay)
+	assert.False(t, locked)
+}
+
+func TestLoginBackoffAndLockout(t *testing.T) {
+	ctx := context.Background()
+	policy := DefaultLoginThrottlePolicy()
+	policy.Username = ThrottleRule{FreeAttempts: 2, LockoutThreshold: 4}
+	policy.BaseDelay = time.Minute
+	env := setupTestLoginSecurity(t, policy)
+	client := ClientInfo{IP: "198.51.100.1", UserAgent: "test"}
+
+	for i := 0; i < 3; i++ {
+		_, err := env.uc.Login(ctx, "alice", "wrong", client)
+		assert.ErrorIs(t, err, ErrInvalidLogin)
+	}
+
+	// 退避期間正確的密碼也被拒絕
+	_, err := env.uc.Login(ctx, "alice", "secret1", client)
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled))
+	assert.ErrorIs(t, err, ErrLoginThrottled)
+	assert.Equal(t, ThrottleScopeUsername, throttled.Scope)
+	assert.InDelta(t, time.Minute.Seconds(), throttled.RetryAfter.Seconds(), 1)
+
+	// 退避結束後再失敗達到閾值，臨時鎖定
+	delete(env.attempts.locks, usernameThrottleKey("alice"))
+	_, err = env.uc.Login(ctx, "alice", "wrong", client)
+	assert.ErrorIs(t, err, ErrInvalidLogin)
+	_, err = env.uc.Login(ctx, "Alice", "secret1", client)
+	require.True(t, errors.As(err, &throttled))
+	assert.Greater(t, throttled.RetryAfter, 14*time.Minute)
+
+	// 管理員解鎖後可以登入，成功後清除帳號名的計數
+	require.NoError(t, env.security.Unlock(ctx, "ALICE"))
+	tokens, err := env.uc.Login(ctx, "alice", "secret1", client)
+	require.NoError(t, err)
+	assert.False(t, tokens.NewDevice)
+
+	history, err := env.security.History(ctx, env.user.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+	assert.True(t, history[0].Success)
+	assert.Equal(t, LoginMethodPassword, history[0].Method)
+	assert.Equal(t, LoginFailureBadCredentials, history[1].FailureReason)
+	assert.Equal(t, "198.51.100.1", history[1].IP)
+}
+
+func TestLoginIPThrottle(t *testing.T) {
+	ctx := context.Background()
+	policy := DefaultLoginThrottlePolicy()
+	policy.IP = ThrottleRule{FreeAttempts: 2, LockoutThreshold: 3}
+	env := setupTestLoginSecurity(t, policy)
+	attacker := ClientInfo{IP: "203.0.113.9"}
+
+	// 不存在的帳號同樣計入 IP 的失敗次數，但不寫入登入記錄
+	for _, name := range []string{"bob", "carol", "dave"} {
+		_, err := env.uc.Login(ctx, name, "guess", attacker)
+		assert.ErrorIs(t, err, ErrInvalidLogin)
+	}
+	assert.Empty(t, env.history.records)
+
+	_, err := env.uc.Login(ctx, "alice", "secret1", attacker)
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled))
+	assert.Equal(t, ThrottleScopeIP, throttled.Scope)
+
+	// 其他 IP 不受影響，登入成功也不清除攻擊 IP 的計數
+	_, err = env.uc.Login(ctx, "alice", "secret1", ClientInfo{IP: "198.51.100.1"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, env.attempts.failures[ThrottleScopeIP+":203.0.113.9"])
+}
+
+func TestLoginNewDeviceDetection(t *testing.T) {
+	ctx := context.Background()
+	env := setupTestLoginSecurity(t, DefaultLoginThrottlePolicy())
+	phone := ClientInfo{IP: "198.51.100.1", UserAgent: "FishApp/1.0", DeviceID: "phone-1"}
+
+	// 帳號第一次登入的設備不算新設備
+	tokens, err := env.uc.Login(ctx, "alice", "secret1", phone)
+	require.NoError(t, err)
+	assert.False(t, tokens.NewDevice)
+
+	// 同一設備 ID 換了 IP 和 User-Agent 仍是已知設備
+	phone.IP, phone.UserAgent = "198.51.100.2", "FishApp/1.1"
+	tokens, err = env.uc.Login(ctx, "alice", "secret1", phone)
+	require.NoError(t, err)
+	assert.False(t, tokens.NewDevice)
+
+	tokens, err = env.uc.Login(ctx, "alice", "secret1", ClientInfo{IP: "192.0.2.50", UserAgent: "Mozilla/5.0"})
+	require.NoError(t, err)
+	assert.True(t, tokens.NewDevice)
+
+	devices, err := env.security.Devices(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "198.51.100.2", devices[0].LastIP)
+	assert.Equal(t, DeviceFingerprint(ClientInfo{DeviceID: "phone-1"}), devices[0].Fingerprint)
+
+	history, err := env.security.History(ctx, env.user.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.True(t, history[0].NewDevice)
+	assert.Equal(t, "192.0.2.50", history[0].IP)
+}
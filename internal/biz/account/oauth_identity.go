@@ -0,0 +1,124 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ========================================
+// 第三方身份綁定
+// ========================================
+//
+// 一個帳號可以綁定多個平台，每個平台最多一個身份；第三方身份只能屬於一個帳號。
+// 通過 OAuth 註冊的帳號創建時自動綁定該身份。解綁時帳號必須保留至少一種登入方式（密碼或其他身份）。
+
+// 身份綁定相關錯誤
+var (
+	ErrOAuthAlreadyLinked    = errors.New("account already has an identity for this oauth provider")
+	ErrOAuthIdentityNotFound = errors.New("oauth identity is not linked to this account")
+	ErrLastLoginMethod       = errors.New("cannot unlink the only login method of the account")
+	ErrOAuthLinkGuest        = errors.New("guests cannot link oauth identities, upgrade the guest instead")
+)
+
+// OAuthIdentity 帳號綁定的第三方身份
+type OAuthIdentity struct {
+	UserID       int64     `json:"user_id"`
+	Provider     string    `json:"provider"`
+	ThirdPartyID string    `json:"third_party_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OAuthProviders 已配置的第三方平台
+func (uc *accountUsecase) OAuthProviders() []string {
+	return uc.oauthService.Providers()
+}
+
+// AuthorizeOAuth 生成第三方平台的授權地址
+func (uc *accountUsecase) AuthorizeOAuth(ctx context.Context, provider string, intent OAuthIntent) (*OAuthAuthorization, error) {
+	if intent.Purpose == OAuthPurposeLink && intent.UserID <= 0 {
+		return nil, ErrOAuthLinkGuest
+	}
+	return uc.oauthService.Authorize(ctx, provider, intent)
+}
+
+// ListOAuthIdentities 帳號綁定的第三方身份
+func (uc *accountUsecase) ListOAuthIdentities(ctx context.Context, userID int64) ([]*OAuthIdentity, error) {
+	identities, err := uc.repo.ListOAuthIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oauth identities: %w", err)
+	}
+	return identities, nil
+}
+
+// LinkOAuth 為帳號綁定第三方身份，授權必須由同一個帳號發起
+func (uc *accountUsecase) LinkOAuth(ctx context.Context, userID int64, provider, code, state string) (*OAuthIdentity, error) {
+	if userID <= 0 {
+		return nil, ErrOAuthLinkGuest
+	}
+	info, err := uc.oauthService.GetUserInfo(ctx, provider, code, state, OAuthIntent{Purpose: OAuthPurposeLink, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	// 已經綁定了同一個身份時直接返回
+	existing, err := uc.repo.GetUserByThirdParty(ctx, provider, info.ThirdPartyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by third party: %w", err)
+	}
+	if existing != nil && existing.ID != userID {
+		return nil, ErrOAuthIdentityTaken
+	}
+
+	identity := &OAuthIdentity{
+		UserID:       userID,
+		Provider:     provider,
+		ThirdPartyID: info.ThirdPartyID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if existing != nil {
+		return identity, nil
+	}
+	if err := uc.repo.CreateOAuthIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// UnlinkOAuth 解綁第三方身份
+func (uc *accountUsecase) UnlinkOAuth(ctx context.Context, userID int64, provider string) error {
+	user, err := uc.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	identities, err := uc.repo.ListOAuthIdentities(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list oauth identities: %w", err)
+	}
+
+	linked := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			linked = true
+		}
+	}
+	if !linked {
+		return ErrOAuthIdentityNotFound
+	}
+	if !user.HasPassword && len(identities) == 1 {
+		return ErrLastLoginMethod
+	}
+
+	deleted, err := uc.repo.DeleteOAuthIdentity(ctx, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to unlink oauth identity: %w", err)
+	}
+	if !deleted {
+		return ErrOAuthIdentityNotFound
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccountRepo 記憶體中的帳號存儲，第三方身份的唯一約束與數據庫一致
+type fakeAccountRepo struct {
+	users      map[int64]*User
+	passwords  map[int64]string
+	identities []*OAuthIdentity
+	nextID     int64
+}
+
+func newFakeAccountRepo() *fakeAccountRepo {
+	return &fakeAccountRepo{users: make(map[int64]*User), passwords: make(map[int64]string), nextID: 1}
+}
+
+func (r *fakeAccountRepo) CreateUser(ctx context.Context, user *User, passwordHash string) (*User, error) {
+	created := *user
+	created.ID = r.nextID
+	created.HasPassword = passwordHash != ""
+	r.nextID++
+	r.users[created.ID] = &created
+	r.passwords[created.ID] = passwordHash
+	if user.ThirdPartyProvider != "" {
+		r.identities = append(r.identities, &OAuthIdentity{UserID: created.ID, Provider: user.ThirdPartyProvider, ThirdPartyID: user.ThirdPartyID})
+	}
+	return &created, nil
+}
+
+func (r *fakeAccountRepo) GetUserByUsername(ctx context.Context, username string) (*User, string, error) {
+	for id, user := range r.users {
+		if username != "" && user.Username == username {
+			return user, r.passwords[id], nil
+		}
+	}
+	return nil, "", nil
+}
+
+func (r *fakeAccountRepo) GetUserByID(ctx context.Context, userID int64) (*User, error) {
+	return r.users[userID], nil
+}
+
+func (r *fakeAccountRepo) GetUserByThirdParty(ctx context.Context, provider, thirdPartyID string) (*User, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.ThirdPartyID == thirdPartyID {
+			return r.users[identity.UserID], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAccountRepo) UpdateUser(ctx context.Context, user *User) error {
+	return nil
+}
+
+func (r *fakeAccountRepo) DisableUser(ctx context.Context, userID int64) (bool, error) {
+	if _, ok := r.users[userID]; !ok {
+		return false, nil
+	}
+	delete(r.users, userID)
+	return true, nil
+}
+
+func (r *fakeAccountRepo) ListOAuthIdentities(ctx context.Context, userID int64) ([]*OAuthIdentity, error) {
+	var identities []*OAuthIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *fakeAccountRepo) CreateOAuthIdentity(ctx context.Context, identity *OAuthIdentity) error {
+	for _, existing := range r.identities {
+		if existing.Provider == identity.Provider && existing.ThirdPartyID == identity.ThirdPartyID {
+			return ErrOAuthIdentityTaken
+		}
+		if existing.UserID == identity.UserID && existing.Provider == identity.Provider {
+			return ErrOAuthAlreadyLinked
+		}
+	}
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeAccountRepo) DeleteOAuthIdentity(ctx context.Context, userID int64, provider string) (bool, error) {
+	for i, identity := range r.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// oauthTestEnv 帳號用例、假 OAuth 服務器和記憶體存儲
+type oauthTestEnv struct {
+	uc     AccountUsecase
+	repo   *fakeAccountRepo
+	server *fakeOAuthServer
+}
+
+func setupTestOAuthAccounts(t *testing.T) *oauthTestEnv {
+	svc, server := setupTestOAuthService(t)
+	sessions, _, _ := setupTestSessionUsecase()
+	repo := newFakeAccountRepo()
+	return &oauthTestEnv{uc: NewAccountUsecase(repo, sessions, nil, nil, svc, nil), repo: repo, server: server}
+}
+
+// callback 發起授權並模擬第三方回調
+func (env *oauthTestEnv) callback(t *testing.T, provider string, intent OAuthIntent, user fakeOAuthUser) (code, state string) {
+	authorization, err := env.uc.AuthorizeOAuth(context.Background(), provider, intent)
+	require.NoError(t, err)
+	return env.server.authorize(t, authorization.AuthorizeURL, user)
+}
+
+func TestOAuthLoginCreatesAndReusesAccount(t *testing.T) {
+	ctx := context.Background()
+	env := setupTestOAuthAccounts(t)
+	alice := fakeOAuthUser{ID: "g-1", Name: "Alice"}
+	login := OAuthIntent{Purpose: OAuthPurposeLogin}
+
+	code, state := env.callback(t, "google", login, alice)
+	tokens, err := env.uc.OAuthLogin(ctx, "google", code, state, ClientInfo{})
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.Len(t, env.repo.users, 1)
+	identities, err := env.uc.ListOAuthIdentities(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, "g-1", identities[0].ThirdPartyID)
+
+	// 再次登入使用同一個帳號
+	code, state = env.callback(t, "google", login, alice)
+	_, err = env.uc.OAuthLogin(ctx, "google", code, state, ClientInfo{})
+	require.NoError(t, err)
+	assert.Len(t, env.repo.users, 1)
+
+	_, err = env.uc.OAuthLogin(ctx, "google", code, state, ClientInfo{})
+	assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+}
+
+func TestOAuthLinkAndUnlink(t *testing.T) {
+	ctx := context.Background()
+	env := setupTestOAuthAccounts(t)
+
+	// 帳號 1 有密碼，帳號 2 通過 facebook 註冊
+	owner, err := env.repo.CreateUser(ctx, &User{Username: "alice", Nickname: "alice"}, "hash")
+	require.NoError(t, err)
+	other, err := env.repo.CreateUser(ctx, &User{Nickname: "bob", ThirdPartyProvider: "facebook", ThirdPartyID: "f-2"}, "")
+	require.NoError(t, err)
+	link := OAuthIntent{Purpose: OAuthPurposeLink, UserID: owner.ID}
+
+	_, err = env.uc.AuthorizeOAuth(ctx, "google", OAuthIntent{Purpose: OAuthPurposeLink})
+	assert.ErrorIs(t, err, ErrOAuthLinkGuest)
+
+	// 綁定 google
+	code, state := env.callback(t, "google", link, fakeOAuthUser{ID: "g-1", Name: "Alice"})
+	identity, err := env.uc.LinkOAuth(ctx, owner.ID, "google", code, state)
+	require.NoError(t, err)
+	assert.Equal(t, "g-1", identity.ThirdPartyID)
+
+	// 已屬於其他帳號的身份不能綁定
+	code, state = env.callback(t, "facebook", link, fakeOAuthUser{ID: "f-2", Name: "Bob"})
+	_, err = env.uc.LinkOAuth(ctx, owner.ID, "facebook", code, state)
+	assert.ErrorIs(t, err, ErrOAuthIdentityTaken)
+
+	// 同一平台只能綁定一個身份
+	code, state = env.callback(t, "google", link, fakeOAuthUser{ID: "g-9", Name: "Alice2"})
+	_, err = env.uc.LinkOAuth(ctx, owner.ID, "google", code, state)
+	assert.ErrorIs(t, err, ErrOAuthAlreadyLinked)
+
+	// 綁定後可以用 google 登入同一個帳號
+	code, state = env.callback(t, "google", OAuthIntent{Purpose: OAuthPurposeLogin}, fakeOAuthUser{ID: "g-1", Name: "Alice"})
+	_, err = env.uc.OAuthLogin(ctx, "google", code, state, ClientInfo{})
+	require.NoError(t, err)
+	assert.Len(t, env.repo.users, 2)
+
+	// 有密碼的帳號可以解綁；只有一個身份且沒有密碼的帳號不能解綁
+	require.NoError(t, env.uc.UnlinkOAuth(ctx, owner.ID, "google"))
+	assert.ErrorIs(t, env.uc.UnlinkOAuth(ctx, owner.ID, "google"), ErrOAuthIdentityNotFound)
+	assert.ErrorIs(t, env.uc.UnlinkOAuth(ctx, other.ID, "facebook"), ErrLastLoginMethod)
+
+	code, state = env.callback(t, "qq", OAuthIntent{Purpose: OAuthPurposeLink, UserID: other.ID}, fakeOAuthUser{ID: "q-2", Name: "Bob"})
+	_, err = env.uc.LinkOAuth(ctx, other.ID, "qq", code, state)
+	require.NoError(t, err)
+	require.NoError(t, env.uc.UnlinkOAuth(ctx, other.ID, "facebook"))
+
+	identities, err := env.uc.ListOAuthIdentities(ctx, other.ID)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, "qq", identities[0].Provider)
+	assert.WithinDuration(t, time.Now(), identities[0].CreatedAt, time.Minute)
+}
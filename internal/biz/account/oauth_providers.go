@@ -0,0 +1,389 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ========================================
+// OAuth 平台
+// ========================================
+//
+// 內置 google、facebook、qq 和通用 oidc 四種類型，都是標準的授權碼 + PKCE 流程，區別在於
+// 默認端點、scope 分隔符和使用者資訊的格式。其他平台可以通過 RegisterType 註冊新類型，
+// 或直接 Register 一個實現了 OAuthProvider 的對象。
+
+// OAuthProvider 一個第三方平台的授權碼流程
+type OAuthProvider interface {
+	// AuthCodeURL 帶 state 和 code_challenge 的授權地址
+	AuthCodeURL(state, codeChallenge string) string
+	// Exchange 使用 authorization code 和 code_verifier 換取訪問令牌
+	Exchange(ctx context.Context, code, codeVerifier string) (string, error)
+	// UserInfo 使用訪問令牌獲取使用者資訊
+	UserInfo(ctx context.Context, accessToken string) (*OAuthUserInfo, error)
+}
+
+// OAuthProviderConfig 平台配置，端點為空時使用該類型的默認端點
+type OAuthProviderConfig struct {
+	Name         string
+	Type         string // google、facebook、qq、oidc，為空時與 Name 相同
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	OpenIDURL    string // 只有 qq 使用：用訪問令牌換取 openid
+	Scopes       []string
+}
+
+// OAuthProviderFactory 按配置創建平台
+type OAuthProviderFactory func(cfg OAuthProviderConfig, client *http.Client) (OAuthProvider, error)
+
+// OAuthProviderRegistry 平台註冊表
+type OAuthProviderRegistry struct {
+	client    *http.Client
+	factories map[string]OAuthProviderFactory
+	providers map[string]OAuthProvider
+}
+
+// NewOAuthProviderRegistry 創建包含內置平台類型的註冊表
+func NewOAuthProviderRegistry(client *http.Client) *OAuthProviderRegistry {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &OAuthProviderRegistry{
+		client: client,
+		factories: map[string]OAuthProviderFactory{
+			"google":   newGoogleProvider,
+			"oidc":     newOIDCProvider,
+			"facebook": newFacebookProvider,
+			"qq":       newQQProvider,
+		},
+		providers: make(map[string]OAuthProvider),
+	}
+}
+
+// RegisterType 註冊新的平台類型
+func (r *OAuthProviderRegistry) RegisterType(typ string, factory OAuthProviderFactory) {
+	r.factories[typ] = factory
+}
+
+// Register 以指定名稱註冊平台
+func (r *OAuthProviderRegistry) Register(name string, provider OAuthProvider) {
+	r.providers[name] = provider
+}
+
+// Configure 按配置創建並註冊平台
+func (r *OAuthProviderRegistry) Configure(cfg OAuthProviderConfig) error {
+	if cfg.Type == "" {
+		cfg.Type = cfg.Name
+	}
+	factory, ok := r.factories[cfg.Type]
+	if !ok {
+		return fmt.Errorf("unknown oauth provider type %q for %s", cfg.Type, cfg.Name)
+	}
+	if cfg.ClientID == "" {
+		return fmt.Errorf("oauth provider %s has no client_id", cfg.Name)
+	}
+	provider, err := factory(cfg, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to configure oauth provider %s: %w", cfg.Name, err)
+	}
+	r.providers[cfg.Name] = provider
+	return nil
+}
+
+// Get 獲取平台
+func (r *OAuthProviderRegistry) Get(name string) (OAuthProvider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// Names 已註冊的平台名稱
+func (r *OAuthProviderRegistry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ========================================
+// 標準授權碼流程
+// ========================================
+
+// oauth2Provider 標準 OAuth 2.0 授權碼流程，使用者資訊由各類型解析
+type oauth2Provider struct {
+	cfg            OAuthProviderConfig
+	client         *http.Client
+	scopeSeparator string
+	tokenParams    url.Values // 換取令牌時的額外參數
+	userInfo       func(ctx context.Context, p *oauth2Provider, accessToken string) (*OAuthUserInfo, error)
+}
+
+// withDefaults 補全默認端點和 scope，並檢查必需的端點
+func withDefaults(cfg OAuthProviderConfig, defaults OAuthProviderConfig) (OAuthProviderConfig, error) {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = defaults.AuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = defaults.TokenURL
+	}
+	if cfg.UserInfoURL == "" {
+		cfg.UserInfoURL = defaults.UserInfoURL
+	}
+	if cfg.OpenIDURL == "" {
+		cfg.OpenIDURL = defaults.OpenIDURL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaults.Scopes
+	}
+	if cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "" {
+		return cfg, fmt.Errorf("auth_url, token_url and user_info_url are required")
+	}
+	return cfg, nil
+}
+
+// AuthCodeURL 帶 state 和 code_challenge 的授權地址
+func (p *oauth2Provider) AuthCodeURL(state, codeChallenge string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	if p.cfg.RedirectURL != "" {
+		params.Set("redirect_uri", p.cfg.RedirectURL)
+	}
+	if len(p.cfg.Scopes) > 0 {
+		params.Set("scope", strings.Join(p.cfg.Scopes, p.scopeSeparator))
+	}
+	return appendQuery(p.cfg.AuthURL, params)
+}
+
+// Exchange 使用 authorization code 和 code_verifier 換取訪問令牌
+func (p *oauth2Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.RedirectURL != "" {
+		form.Set("redirect_uri", p.cfg.RedirectURL)
+	}
+	for key, values := range p.tokenParams {
+		form[key] = values
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return "", err
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("%s: %s", token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access_token")
+	}
+	return token.AccessToken, nil
+}
+
+// UserInfo 使用訪問令牌獲取使用者資訊
+func (p *oauth2Provider) UserInfo(ctx context.Context, accessToken string) (*OAuthUserInfo, error) {
+	return p.userInfo(ctx, p, accessToken)
+}
+
+// getJSON 以 Bearer 令牌請求 JSON 接口
+func (p *oauth2Provider) getJSON(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	return p.do(req, out)
+}
+
+// do 發送請求並解析 JSON 響應，非 2xx 時返回響應內容
+func (p *oauth2Provider) do(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", req.URL.Path, err)
+	}
+	return nil
+}
+
+// appendQuery 在地址後追加查詢參數，地址已有查詢參數時保留
+func appendQuery(endpoint string, params url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + params.Encode()
+	}
+	return endpoint + "?" + params.Encode()
+}
+
+// ========================================
+// 各平台類型
+// ========================================
+
+// oidcUserInfo OpenID Connect 標準的使用者資訊（google 相同）
+func oidcUserInfo(ctx context.Context, p *oauth2Provider, accessToken string) (*OAuthUserInfo, error) {
+	var info struct {
+		Sub     string `json:"sub"`
+		Email   string `json:"email"`
+		Name    string `json:"name"`
+		Picture string `json:"picture"`
+	}
+	if err := p.getJSON(ctx, p.cfg.UserInfoURL, accessToken, &info); err != nil {
+		return nil, err
+	}
+	return &OAuthUserInfo{ThirdPartyID: info.Sub, Email: info.Email, Nickname: info.Name, AvatarURL: info.Picture}, nil
+}
+
+func newGoogleProvider(cfg OAuthProviderConfig, client *http.Client) (OAuthProvider, error) {
+	cfg, err := withDefaults(cfg, OAuthProviderConfig{
+		AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:    "https://oauth2.googleapis.com/token",
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:      []string{"openid", "email", "profile"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2Provider{cfg: cfg, client: client, scopeSeparator: " ", userInfo: oidcUserInfo}, nil
+}
+
+func newOIDCProvider(cfg OAuthProviderConfig, client *http.Client) (OAuthProvider, error) {
+	cfg, err := withDefaults(cfg, OAuthProviderConfig{Scopes: []string{"openid", "email", "profile"}})
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2Provider{cfg: cfg, client: client, scopeSeparator: " ", userInfo: oidcUserInfo}, nil
+}
+
+func newFacebookProvider(cfg OAuthProviderConfig, client *http.Client) (OAuthProvider, error) {
+	cfg, err := withDefaults(cfg, OAuthProviderConfig{
+		AuthURL:     "https://www.facebook.com/v19.0/dialog/oauth",
+		TokenURL:    "https://graph.facebook.com/v19.0/oauth/access_token",
+		UserInfoURL: "https://graph.facebook.com/v19.0/me?fields=id,name,email,picture",
+		Scopes:      []string{"email", "public_profile"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2Provider{cfg: cfg, client: client, scopeSeparator: ",", userInfo: facebookUserInfo}, nil
+}
+
+func facebookUserInfo(ctx context.Context, p *oauth2Provider, accessToken string) (*OAuthUserInfo, error) {
+	var info struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		Picture struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	if err := p.getJSON(ctx, p.cfg.UserInfoURL, accessToken, &info); err != nil {
+		return nil, err
+	}
+	return &OAuthUserInfo{ThirdPartyID: info.ID, Email: info.Email, Nickname: info.Name, AvatarURL: info.Picture.Data.URL}, nil
+}
+
+// newQQProvider QQ 互聯：令牌和 openid 接口需要 fmt=json 才返回 JSON，使用者資訊需要 openid 和 oauth_consumer_key
+func newQQProvider(cfg OAuthProviderConfig, client *http.Client) (OAuthProvider, error) {
+	cfg, err := withDefaults(cfg, OAuthProviderConfig{
+		AuthURL:     "https://graph.qq.com/oauth2.0/authorize",
+		TokenURL:    "https://graph.qq.com/oauth2.0/token",
+		OpenIDURL:   "https://graph.qq.com/oauth2.0/me",
+		UserInfoURL: "https://graph.qq.com/user/get_user_info",
+		Scopes:      []string{"get_user_info"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.OpenIDURL == "" {
+		return nil, fmt.Errorf("open_id_url is required")
+	}
+	return &oauth2Provider{
+		cfg:            cfg,
+		client:         client,
+		scopeSeparator: ",",
+		tokenParams:    url.Values{"fmt": {"json"}},
+		userInfo:       qqUserInfo,
+	}, nil
+}
+
+func qqUserInfo(ctx context.Context, p *oauth2Provider, accessToken string) (*OAuthUserInfo, error) {
+	var me struct {
+		OpenID           string `json:"openid"`
+		Error            int    `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	meURL := appendQuery(p.cfg.OpenIDURL, url.Values{"access_token": {accessToken}, "fmt": {"json"}})
+	if err := p.getJSON(ctx, meURL, accessToken, &me); err != nil {
+		return nil, err
+	}
+	if me.Error != 0 || me.OpenID == "" {
+		return nil, fmt.Errorf("failed to get qq openid: %d %s", me.Error, me.ErrorDescription)
+	}
+
+	var info struct {
+		Ret          int    `json:"ret"`
+		Msg          string `json:"msg"`
+		Nickname     string `json:"nickname"`
+		FigureURLQQ1 string `json:"figureurl_qq_1"`
+		FigureURLQQ2 string `json:"figureurl_qq_2"`
+	}
+	infoURL := appendQuery(p.cfg.UserInfoURL, url.Values{
+		"access_token":       {accessToken},
+		"oauth_consumer_key": {p.cfg.ClientID},
+		"openid":             {me.OpenID},
+	})
+	if err := p.getJSON(ctx, infoURL, accessToken, &info); err != nil {
+		return nil, err
+	}
+	if info.Ret != 0 {
+		return nil, fmt.Errorf("failed to get qq user info: %d %s", info.Ret, info.Msg)
+	}
+
+	avatar := info.FigureURLQQ2
+	if avatar == "" {
+		avatar = info.FigureURLQQ1
+	}
+	return &OAuthUserInfo{ThirdPartyID: me.OpenID, Nickname: info.Nickname, AvatarURL: avatar}, nil
+}
@@ -2,16 +2,45 @@ package account
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
 )
 
-// TODO: 實現 OAuth 第三方登入服務
-// 此檔案提供與第三方平台（Google, Facebook, QQ 等）的 OAuth 整合
+// ========================================
+// OAuth 第三方登入
+// ========================================
+//
+// 授權碼流程：客戶端先請求授權地址，服務端生成一次性的 state 和 PKCE code_verifier 保存在 Redis，
+// 把 state 和 code_challenge（S256）放進第三方平台的授權地址；第三方回調後客戶端提交 code 和 state，
+// 服務端取出並刪除 state，校驗平台和用途一致後用 code_verifier 換取訪問令牌並獲取使用者資訊。
+// 各平台的差異封裝在 OAuthProvider 中，由 OAuthProviderRegistry 按配置創建。
+
+// OAuth 授權的用途
+const (
+	OAuthPurposeLogin = "login" // 登入或註冊（包括遊客升級）
+	OAuthPurposeLink  = "link"  // 為已登入的帳號綁定第三方身份
+)
+
+// OAuth 相關錯誤
+var (
+	ErrOAuthProviderNotFound = errors.New("oauth provider is not configured")
+	ErrOAuthStateInvalid     = errors.New("oauth state is invalid or expired")
+)
 
 // OAuthService 定義 OAuth 服務介面
 type OAuthService interface {
-	// GetUserInfo 使用 authorization code 換取使用者資訊
-	GetUserInfo(ctx context.Context, provider, code string) (*OAuthUserInfo, error)
+	// Providers 已配置的平台名稱
+	Providers() []string
+	// Authorize 生成 state 和 PKCE 參數，返回第三方平台的授權地址
+	Authorize(ctx context.Context, provider string, intent OAuthIntent) (*OAuthAuthorization, error)
+	// GetUserInfo 校驗 state 後使用 authorization code 換取使用者資訊，state 只能使用一次
+	GetUserInfo(ctx context.Context, provider, code, state string, intent OAuthIntent) (*OAuthUserInfo, error)
 }
 
 // OAuthUserInfo OAuth 獲取的使用者資訊
@@ -23,43 +52,139 @@ type OAuthUserInfo struct {
 	AvatarURL     string // 頭像 URL
 }
 
+// OAuthIntent 發起授權的用途和玩家，回調時必須與授權時一致
+type OAuthIntent struct {
+	Purpose string
+	UserID  int64 // 綁定時為當前玩家，登入時為 0
+}
+
+// OAuthAuthorization 授權地址
+type OAuthAuthorization struct {
+	Provider     string `json:"provider"`
+	AuthorizeURL string `json:"authorize_url"`
+	State        string `json:"state"`
+	ExpiresIn    int64  `json:"expires_in"` // state 有效期（秒）
+}
+
+// OAuthState 保存在服務端的一次性授權狀態
+type OAuthState struct {
+	Provider     string    `json:"provider"`
+	Purpose      string    `json:"purpose"`
+	UserID       int64     `json:"user_id,omitempty"`
+	CodeVerifier string    `json:"code_verifier"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OAuthStateStore 授權狀態的存儲（Redis）
+type OAuthStateStore interface {
+	// SaveOAuthState 保存授權狀態
+	SaveOAuthState(ctx context.Context, state string, data *OAuthState, ttl time.Duration) error
+	// TakeOAuthState 取出並刪除授權狀態，不存在或已過期時返回 nil
+	TakeOAuthState(ctx context.Context, state string) (*OAuthState, error)
+}
+
 // oAuthService 實現 OAuthService 介面
 type oAuthService struct {
-	// TODO: 添加第三方平台的 OAuth 配置
-	// - Client ID
-	// - Client Secret
-	// - Redirect URI
+	providers *OAuthProviderRegistry
+	states    OAuthStateStore
+	stateTTL  time.Duration
+	logger    logger.Logger
 }
 
 // NewOAuthService 建立新的 OAuthService 實例
-func NewOAuthService( /* TODO: 添加配置參數 */ ) OAuthService {
+func NewOAuthService(providers *OAuthProviderRegistry, states OAuthStateStore, stateTTL time.Duration, logger logger.Logger) OAuthService {
 	return &oAuthService{
-		// TODO: 初始化配置
+		providers: providers,
+		states:    states,
+		stateTTL:  stateTTL,
+		logger:    logger.With("component", "oauth_service"),
 	}
 }
 
-// GetUserInfo 使用 authorization code 換取使用者資訊
-func (s *oAuthService) GetUserInfo(ctx context.Context, provider, code string) (*OAuthUserInfo, error) {
-	// TODO: 實現 OAuth 流程
-	// 1. 根據 provider 選擇對應的第三方平台
-	// 2. 使用 code 換取 access_token
-	// 3. 使用 access_token 獲取使用者資訊
-	// 4. 將第三方使用者資訊轉換為 OAuthUserInfo
-
-	switch provider {
-	case "google":
-		// TODO: 實現 Google OAuth
-		// 參考：https://developers.google.com/identity/protocols/oauth2
-		return nil, fmt.Errorf("google oauth is not implemented yet")
-	case "facebook":
-		// TODO: 實現 Facebook OAuth
-		// 參考：https://developers.facebook.com/docs/facebook-login/manually-build-a-login-flow
-		return nil, fmt.Errorf("facebook oauth is not implemented yet")
-	case "qq":
-		// TODO: 實現 QQ OAuth
-		// 參考：https://wiki.connect.qq.com/oauth2-0%e7%ae%80%e4%bb%8b
-		return nil, fmt.Errorf("qq oauth is not implemented yet")
-	default:
-		return nil, fmt.Errorf("unsupported oauth provider: %s", provider)
+// Providers 已配置的平台名稱
+func (s *oAuthService) Providers() []string {
+	return s.providers.Names()
+}
+
+// Authorize 生成 state 和 PKCE 參數，返回第三方平台的授權地址
+func (s *oAuthService) Authorize(ctx context.Context, provider string, intent OAuthIntent) (*OAuthAuthorization, error) {
+	p, ok := s.providers.Get(provider)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
 	}
+
+	state, err := randomURLToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomURLToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.states.SaveOAuthState(ctx, state, &OAuthState{
+		Provider:     provider,
+		Purpose:      intent.Purpose,
+		UserID:       intent.UserID,
+		CodeVerifier: verifier,
+		CreatedAt:    time.Now().UTC(),
+	}, s.stateTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save oauth state: %w", err)
+	}
+
+	return &OAuthAuthorization{
+		Provider:     provider,
+		AuthorizeURL: p.AuthCodeURL(state, pkceChallenge(verifier)),
+		State:        state,
+		ExpiresIn:    int64(s.stateTTL.Seconds()),
+	}, nil
+}
+
+// GetUserInfo 校驗 state 後使用 authorization code 換取使用者資訊
+func (s *oAuthService) GetUserInfo(ctx context.Context, provider, code, state string, intent OAuthIntent) (*OAuthUserInfo, error) {
+	p, ok := s.providers.Get(provider)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+	if state == "" {
+		return nil, ErrOAuthStateInvalid
+	}
+
+	saved, err := s.states.TakeOAuthState(ctx, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth state: %w", err)
+	}
+	if saved == nil || saved.Provider != provider || saved.Purpose != intent.Purpose || saved.UserID != intent.UserID {
+		return nil, ErrOAuthStateInvalid
+	}
+
+	accessToken, err := p.Exchange(ctx, code, saved.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange %s authorization code: %w", provider, err)
+	}
+	info, err := p.UserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s user info: %w", provider, err)
+	}
+	if info.ThirdPartyID == "" {
+		return nil, fmt.Errorf("%s user info has no user id", provider)
+	}
+	info.Provider = provider
+	return info, nil
+}
+
+// randomURLToken 32 字節隨機數的 base64url 編碼，用作 state 和 code_verifier
+func randomURLToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// pkceChallenge S256 方式的 code_challenge
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
 }
@@ -0,0 +1,316 @@
+package account
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOAuthUser 假 OAuth 服務器上的使用者
+type fakeOAuthUser struct {
+	ID     string
+	Name   string
+	Email  string
+	Avatar string
+}
+
+// fakeOAuthServer 本地的假 OAuth 服務器，同時提供 oidc、facebook 和 qq 格式的接口，
+// 換取令牌時校驗 client 憑證、redirect_uri 和 PKCE
+type fakeOAuthServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	codes  map[string]fakeOAuthGrant
+	tokens map[string]fakeOAuthUser
+}
+
+type fakeOAuthGrant struct {
+	challenge string
+	user      fakeOAuthUser
+}
+
+const (
+	fakeClientID     = "client-1"
+	fakeClientSecret = "secret-1"
+	fakeRedirectURL  = "https://game.example.com/oauth/callback"
+)
+
+func newFakeOAuthServer(t *testing.T) *fakeOAuthServer {
+	s := &fakeOAuthServer{codes: make(map[string]fakeOAuthGrant), tokens: make(map[string]fakeOAuthUser)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", s.handleToken)
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.bearerUser(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, map[string]string{"sub": user.ID, "name": user.Name, "email": user.Email, "picture": user.Avatar})
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.bearerUser(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"id": user.ID, "name": user.Name, "email": user.Email,
+			"picture": map[string]interface{}{"data": map[string]string{"url": user.Avatar}},
+		})
+	})
+	mux.HandleFunc("/qq/me", func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.queryUser(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, map[string]string{"client_id": fakeClientID, "openid": user.ID})
+	})
+	mux.HandleFunc("/qq/get_user_info", func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.queryUser(w, r)
+		if !ok {
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("oauth_consumer_key") != fakeClientID || query.Get("openid") != user.ID {
+			writeJSON(w, map[string]interface{}{"ret": 1002, "msg": "invalid openid"})
+			return
+		}
+		writeJSON(w, map[string]interface{}{"ret": 0, "nickname": user.Name, "figureurl_qq_2": user.Avatar})
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// authorize 模擬使用者在授權頁同意授權，返回回調中的 code 和 state
+func (s *fakeOAuthServer) authorize(t *testing.T, authorizeURL string, user fakeOAuthUser) (code, state string) {
+	parsed, err := url.Parse(authorizeURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	require.Equal(t, fakeClientID, query.Get("client_id"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code = fmt.Sprintf("code-%d", len(s.codes)+1)
+	s.codes[code] = fakeOAuthGrant{challenge: query.Get("code_challenge"), user: user}
+	return code, query.Get("state")
+}
+
+func (s *fakeOAuthServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if r.PostForm.Get("client_id") != fakeClientID || r.PostForm.Get("client_secret") != fakeClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeJSON(w, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	grant, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("redirect_uri") != fakeRedirectURL ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		writeJSON(w, map[string]string{"error": "invalid_grant", "error_description": "code or verifier mismatch"})
+		return
+	}
+
+	accessToken := "token-" + grant.user.ID
+	s.tokens[accessToken] = grant.user
+	writeJSON(w, map[string]interface{}{"access_token": accessToken, "token_type": "Bearer", "expires_in": 3600})
+}
+
+func (s *fakeOAuthServer) bearerUser(w http.ResponseWriter, r *http.Request) (fakeOAuthUser, bool) {
+	return s.lookupToken(w, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+}
+
+func (s *fakeOAuthServer) queryUser(w http.ResponseWriter, r *http.Request) (fakeOAuthUser, bool) {
+	return s.lookupToken(w, r.URL.Query().Get("access_token"))
+}
+
+func (s *fakeOAuthServer) lookupToken(w http.ResponseWriter, accessToken string) (fakeOAuthUser, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.tokens[accessToken]
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeJSON(w, map[string]string{"error": "invalid_token"})
+	}
+	return user, ok
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// fakeOAuthStateStore 記憶體中的授權狀態存儲
+type fakeOAuthStateStore struct {
+	mu     sync.Mutex
+	states map[string]*OAuthState
+}
+
+func (s *fakeOAuthStateStore) SaveOAuthState(ctx context.Context, state string, data *OAuthState, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state] = data
+	return nil
+}
+
+func (s *fakeOAuthStateStore) TakeOAuthState(ctx context.Context, state string) (*OAuthState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := s.states[state]
+	delete(s.states, state)
+	return data, nil
+}
+
+// setupTestOAuthService 三個平台都指向假 OAuth 服務器
+func setupTestOAuthService(t *testing.T) (OAuthService, *fakeOAuthServer) {
+	server := newFakeOAuthServer(t)
+	registry := NewOAuthProviderRegistry(server.Client())
+	base := OAuthProviderConfig{ClientID: fakeClientID, ClientSecret: fakeClientSecret, RedirectURL: fakeRedirectURL, TokenURL: server.URL + "/token"}
+
+	google := base
+	google.Name, google.AuthURL, google.UserInfoURL = "google", server.URL+"/authorize", server.URL+"/userinfo"
+	require.NoError(t, registry.Configure(google))
+
+	facebook := base
+	facebook.Name, facebook.AuthURL, facebook.UserInfoURL = "facebook", server.URL+"/dialog/oauth", server.URL+"/me?fields=id,name,email,picture"
+	require.NoError(t, registry.Configure(facebook))
+
+	qq := base
+	qq.Name, qq.AuthURL, qq.OpenIDURL, qq.UserInfoURL = "qq", server.URL+"/authorize", server.URL+"/qq/me", server.URL+"/qq/get_user_info"
+	require.NoError(t, registry.Configure(qq))
+
+	states := &fakeOAuthStateStore{states: make(map[string]*OAuthState)}
+	return NewOAuthService(registry, states, 10*time.Minute, logger.New(io.Discard, "info", "console")), server
+}
+
+func TestOAuthProviderRegistry(t *testing.T) {
+	registry := NewOAuthProviderRegistry(nil)
+
+	err := registry.Configure(OAuthProviderConfig{Name: "line", ClientID: "id"})
+	assert.ErrorContains(t, err, "unknown oauth provider type")
+	err = registry.Configure(OAuthProviderConfig{Name: "google"})
+	assert.ErrorContains(t, err, "no client_id")
+	err = registry.Configure(OAuthProviderConfig{Name: "corp", Type: "oidc", ClientID: "id"})
+	assert.ErrorContains(t, err, "required")
+
+	require.NoError(t, registry.Configure(OAuthProviderConfig{Name: "google", ClientID: "id"}))
+	require.NoError(t, registry.Configure(OAuthProviderConfig{Name: "qq", ClientID: "id"}))
+	assert.Equal(t, []string{"google", "qq"}, registry.Names())
+
+	// 默認端點和 scope
+	google, ok := registry.Get("google")
+	require.True(t, ok)
+	authURL, err := url.Parse(google.AuthCodeURL("s", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "accounts.google.com", authURL.Host)
+	assert.Equal(t, "openid email profile", authURL.Query().Get("scope"))
+
+	qq, _ := registry.Get("qq")
+	authURL, err = url.Parse(qq.AuthCodeURL("s", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "get_user_info", authURL.Query().Get("scope"))
+}
+
+func TestOAuthAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	svc, server := setupTestOAuthService(t)
+	assert.Equal(t, []string{"facebook", "google", "qq"}, svc.Providers())
+
+	user := fakeOAuthUser{ID: "u-100", Name: "Alice", Email: "alice@example.com", Avatar: "https://img.example.com/a.png"}
+	login := OAuthIntent{Purpose: OAuthPurposeLogin}
+
+	for _, provider := range []string{"google", "facebook", "qq"} {
+		t.Run(provider, func(t *testing.T) {
+			authorization, err := svc.Authorize(ctx, provider, login)
+			require.NoError(t, err)
+			assert.Equal(t, int64(600), authorization.ExpiresIn)
+
+			code, state := server.authorize(t, authorization.AuthorizeURL, user)
+			assert.Equal(t, authorization.State, state)
+
+			info, err := svc.GetUserInfo(ctx, provider, code, state, login)
+			require.NoError(t, err)
+			assert.Equal(t, provider, info.Provider)
+			assert.Equal(t, "u-100", info.ThirdPartyID)
+			assert.Equal(t, "Alice", info.Nickname)
+			assert.Equal(t, "https://img.example.com/a.png", info.AvatarURL)
+			if provider != "qq" {
+				assert.Equal(t, "alice@example.com", info.Email)
+			}
+
+			// state 只能使用一次
+			_, err = svc.GetUserInfo(ctx, provider, code, state, login)
+			assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+		})
+	}
+
+	_, err := svc.Authorize(ctx, "line", login)
+	assert.ErrorIs(t, err, ErrOAuthProviderNotFound)
+}
+
+func TestOAuthStateAndPKCEValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, server := setupTestOAuthService(t)
+	user := fakeOAuthUser{ID: "u-1", Name: "Bob"}
+	login := OAuthIntent{Purpose: OAuthPurposeLogin}
+
+	// 缺少 state 或 state 不存在
+	_, err := svc.GetUserInfo(ctx, "google", "code", "", login)
+	assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+	_, err = svc.GetUserInfo(ctx, "google", "code", "forged", login)
+	assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+
+	// 平台、用途或玩家與授權時不一致
+	mismatches := []struct {
+		provider string
+		intent   OAuthIntent
+	}{
+		{"facebook", login},
+		{"google", OAuthIntent{Purpose: OAuthPurposeLink, UserID: 7}},
+	}
+	for _, m := range mismatches {
+		authorization, err := svc.Authorize(ctx, "google", login)
+		require.NoError(t, err)
+		code, state := server.authorize(t, authorization.AuthorizeURL, user)
+		_, err = svc.GetUserInfo(ctx, m.provider, code, state, m.intent)
+		assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+	}
+
+	link := OAuthIntent{Purpose: OAuthPurposeLink, UserID: 7}
+	authorization, err := svc.Authorize(ctx, "google", link)
+	require.NoError(t, err)
+	code, state := server.authorize(t, authorization.AuthorizeURL, user)
+	_, err = svc.GetUserInfo(ctx, "google", code, state, OAuthIntent{Purpose: OAuthPurposeLink, UserID: 8})
+	assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+
+	// 授權碼被攔截後使用另一個授權的 state：code_verifier 與 code_challenge 不匹配
+	first, err := svc.Authorize(ctx, "google", login)
+	require.NoError(t, err)
+	stolenCode, _ := server.authorize(t, first.AuthorizeURL, user)
+	second, err := svc.Authorize(ctx, "google", login)
+	require.NoError(t, err)
+	_, err = svc.GetUserInfo(ctx, "google", stolenCode, second.State, login)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
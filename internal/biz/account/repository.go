@@ -9,7 +9,7 @@ import (
 
 // AccountRepo 定義帳號資料訪問的介面
 type AccountRepo interface {
-	// CreateUser 建立新使用者
+	// CreateUser 建立新使用者，設置了 ThirdPartyProvider 時同時綁定該第三方身份
 	CreateUser(ctx context.Context, user *User, passwordHash string) (*User, error)
 
 	// GetUserByUsername 根據使用者名稱獲取使用者
@@ -18,9 +18,22 @@ type AccountRepo interface {
 	// GetUserByID 根據 ID 獲取使用者
 	GetUserByID(ctx context.Context, userID int64) (*User, error)
 
-	// GetUserByThirdParty 根據第三方平台 ID 獲取使用者
+	// GetUserByThirdParty 根據綁定的第三方身份獲取使用者
 	GetUserByThirdParty(ctx context.Context, provider, thirdPartyID string) (*User, error)
 
+	// ListOAuthIdentities 獲取帳號綁定的第三方身份
+	ListOAuthIdentities(ctx context.Context, userID int64) ([]*OAuthIdentity, error)
+
+	// CreateOAuthIdentity 綁定第三方身份，身份已屬於其他帳號時返回 ErrOAuthIdentityTaken，
+	// 帳號已綁定該平台時返回 ErrOAuthAlreadyLinked
+	CreateOAuthIdentity(ctx context.Context, identity *OAuthIdentity) error
+
+	// DeleteOAuthIdentity 解綁第三方身份，返回是否存在
+	DeleteOAuthIdentity(ctx context.Context, userID int64, provider string) (bool, error)
+
 	// UpdateUser 更新使用者資料
 	UpdateUser(ctx context.Context, user *User) error
+
+	// DisableUser 停用帳號（is_active = false），停用後按 ID、使用者名稱和第三方身份都查不到，返回帳號是否存在且原本啟用
+	DisableUser(ctx context.Context, userID int64) (bool, error)
 }
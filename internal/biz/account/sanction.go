@@ -0,0 +1,325 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 玩家處罰
+// ========================================
+//
+// 管理員可以對正式帳號發出封禁、禁言、房間類型限制和提款限制，記錄原因、到期時間（空為永久）和發出的管理員；
+// 解除處罰時保留記錄並記下解除的管理員和原因。同類處罰可以同時存在多條，以最晚到期的為準。
+// 封禁在登入和建立遊戲連接時檢查，發出封禁時撤銷玩家所有會話並通知遊戲伺服器立即關閉該玩家的所有連接；
+// 房間類型限制在加入房間時檢查，提款限制由錢包在提款時檢查，禁言在房間聊天（CHAT）時檢查。
+
+// SanctionType 處罰類型
+type SanctionType string
+
+const (
+	SanctionBan             SanctionType = "ban"              // 封禁帳號：不能登入和連接遊戲伺服器
+	SanctionMute            SanctionType = "mute"             // 禁言：不能在房間內聊天
+	SanctionRoomRestriction SanctionType = "room_restriction" // 禁止進入指定類型的房間
+	SanctionWithdrawBlock   SanctionType = "withdraw_block"   // 禁止提款
+)
+
+// Valid 是否為已知的處罰類型
+func (t SanctionType) Valid() bool {
+	switch t {
+	case SanctionBan, SanctionMute, SanctionRoomRestriction, SanctionWithdrawBlock:
+		return true
+	}
+	return false
+}
+
+// 處罰相關錯誤
+var (
+	ErrAccountBanned      = errors.New("account is banned")
+	ErrChatMuted          = errors.New("player is muted")
+	ErrRoomTypeRestricted = errors.New("player is restricted from this room type")
+	ErrWithdrawBlocked    = errors.New("withdrawals are blocked for this player")
+	ErrInvalidSanction    = errors.New("invalid sanction")
+	ErrSanctionNotFound   = errors.New("sanction not found or already lifted")
+)
+
+// Sanction 一條處罰記錄
+type Sanction struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Type       SanctionType `json:"type"`
+	Reason     string       `json:"reason"`
+	RoomTypes  []string     `json:"room_types,omitempty"` // 房間類型限制的房間類型
+	IssuedBy   int64        `json:"issued_by"`            // 發出處罰的管理員 ID
+	CreatedAt  time.Time    `json:"created_at"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"` // 空為永久
+	LiftedAt   *time.Time   `json:"lifted_at,omitempty"`
+	LiftedBy   int64        `json:"lifted_by,omitempty"`
+	LiftReason string       `json:"lift_reason,omitempty"`
+}
+
+// Active 處罰在 now 時是否生效
+func (s *Sanction) Active(now time.Time) bool {
+	if s.LiftedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || s.ExpiresAt.After(now)
+}
+
+// outlasts 是否比 other 更晚到期（永久處罰最晚）
+func (s *Sanction) outlasts(other *Sanction) bool {
+	if other == nil {
+		return true
+	}
+	if s.ExpiresAt == nil || other.ExpiresAt == nil {
+		return s.ExpiresAt == nil && other.ExpiresAt != nil
+	}
+	return s.ExpiresAt.After(*other.ExpiresAt)
+}
+
+// sanctionError 帶到期時間和原因的處罰錯誤
+func sanctionError(sentinel error, s *Sanction) error {
+	if s.ExpiresAt == nil {
+		return fmt.Errorf("%w permanently: %s", sentinel, s.Reason)
+	}
+	return fmt.Errorf("%w until %s: %s", sentinel, s.ExpiresAt.UTC().Format(time.RFC3339), s.Reason)
+}
+
+// SanctionRequest 發出處罰的參數
+type SanctionRequest struct {
+	Type      SanctionType
+	Reason    string
+	RoomTypes []string      // 僅房間類型限制
+	Duration  time.Duration // 0 為永久
+}
+
+// SanctionStatus 玩家當前生效的處罰
+type SanctionStatus struct {
+	UserID              int64       `json:"user_id"`
+	Banned              bool        `json:"banned"`
+	Muted               bool        `json:"muted"`
+	WithdrawBlocked     bool        `json:"withdraw_blocked"`
+	RestrictedRoomTypes []string    `json:"restricted_room_types"`
+	Active              []*Sanction `json:"active"`
+}
+
+// SanctionRepo 處罰記錄的存儲
+type SanctionRepo interface {
+	// CreateSanction 保存處罰並設置 ID
+	CreateSanction(ctx context.Context, sanction *Sanction) error
+	// ListSanctions 玩家的所有處罰記錄，最新的在前
+	ListSanctions(ctx context.Context, userID int64) ([]*Sanction, error)
+	// ListActiveSanctions 玩家在 now 時生效的處罰
+	ListActiveSanctions(ctx context.Context, userID int64, now time.Time) ([]*Sanction, error)
+	// LiftSanction 解除一條生效中的處罰，返回是否存在
+	LiftSanction(ctx context.Context, userID, sanctionID, liftedBy int64, reason string, at time.Time) (bool, error)
+	// LiftSanctionsByType 解除玩家某一類型的所有生效中的處罰，返回解除的條數
+	LiftSanctionsByType(ctx context.Context, userID int64, sanctionType SanctionType, liftedBy int64, reason string, at time.Time) (int, error)
+}
+
+// SanctionUsecase 發出、解除和檢查玩家處罰
+type SanctionUsecase struct {
+	repo     SanctionRepo
+	sessions *SessionUsecase
+	logger   logger.Logger
+}
+
+// NewSanctionUsecase 創建處罰用例
+func NewSanctionUsecase(repo SanctionRepo, sessions *SessionUsecase, logger logger.Logger) *SanctionUsecase {
+	return &SanctionUsecase{
+		repo:     repo,
+		sessions: sessions,
+		logger:   logger.With("component", "sanction_usecase"),
+	}
+}
+
+// Issue 對玩家發出處罰；封禁時撤銷玩家所有會話並關閉遊戲連接
+func (uc *SanctionUsecase) Issue(ctx context.Context, userID, adminID int64, req SanctionRequest) (*Sanction, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("%w: guests cannot be sanctioned", ErrInvalidSanction)
+	}
+	if !req.Type.Valid() {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSanction, req.Type)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidSanction)
+	}
+	if req.Duration < 0 {
+		return nil, fmt.Errorf("%w: duration must not be negative", ErrInvalidSanction)
+	}
+
+	var roomTypes []string
+	if req.Type == SanctionRoomRestriction {
+		seen := make(map[string]bool)
+		for _, roomType := range req.RoomTypes {
+			roomType = strings.ToLower(strings.TrimSpace(roomType))
+			if roomType != "" && !seen[roomType] {
+				seen[roomType] = true
+				roomTypes = append(roomTypes, roomType)
+			}
+		}
+		if len(roomTypes) == 0 {
+			return nil, fmt.Errorf("%w: room restriction requires room types", ErrInvalidSanction)
+		}
+		sort.Strings(roomTypes)
+	}
+
+	now := time.Now().UTC()
+	sanction := &Sanction{
+		UserID:    userID,
+		Type:      req.Type,
+		Reason:    reason,
+		RoomTypes: roomTypes,
+		IssuedBy:  adminID,
+		CreatedAt: now,
+	}
+	if req.Duration > 0 {
+		expiresAt := now.Add(req.Duration)
+		sanction.ExpiresAt = &expiresAt
+	}
+	if err := uc.repo.CreateSanction(ctx, sanction); err != nil {
+		return nil, fmt.Errorf("failed to create sanction: %w", err)
+	}
+	uc.logger.Infof("Admin %d issued %s sanction %d on user %d: %s", adminID, sanction.Type, sanction.ID, userID, reason)
+
+	if sanction.Type == SanctionBan && uc.sessions != nil {
+		if _, err := uc.sessions.RevokeUser(ctx, userID, RevokeReasonBanned); err != nil {
+			// 會話撤銷失敗時登入和連接檢查仍然生效
+			uc.logger.Errorf("Failed to revoke sessions of banned user %d: %v", userID, err)
+		}
+	}
+	return sanction, nil
+}
+
+// Lift 解除一條處罰
+func (uc *SanctionUsecase) Lift(ctx context.Context, userID, sanctionID, adminID int64, reason string) error {
+	lifted, err := uc.repo.LiftSanction(ctx, userID, sanctionID, adminID, strings.TrimSpace(reason), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to lift sanction: %w", err)
+	}
+	if !lifted {
+		return ErrSanctionNotFound
+	}
+	uc.logger.Infof("Admin %d lifted sanction %d of user %d", adminID, sanctionID, userID)
+	return nil
+}
+
+// LiftType 解除玩家某一類型的所有處罰，返回解除的條數
+func (uc *SanctionUsecase) LiftType(ctx context.Context, userID int64, sanctionType SanctionType, adminID int64, reason string) (int, error) {
+	if !sanctionType.Valid() {
+		return 0, fmt.Errorf("%w: unknown type %q", ErrInvalidSanction, sanctionType)
+	}
+	n, err := uc.repo.LiftSanctionsByType(ctx, userID, sanctionType, adminID, strings.TrimSpace(reason), time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to lift sanctions: %w", err)
+	}
+	if n == 0 {
+		return 0, ErrSanctionNotFound
+	}
+	uc.logger.Infof("Admin %d lifted %d %s sanctions of user %d", adminID, n, sanctionType, userID)
+	return n, nil
+}
+
+// List 玩家的所有處罰記錄，最新的在前
+func (uc *SanctionUsecase) List(ctx context.Context, userID int64) ([]*Sanction, error) {
+	sanctions, err := uc.repo.ListSanctions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sanctions: %w", err)
+	}
+	return sanctions, nil
+}
+
+// Status 玩家當前生效的處罰
+func (uc *SanctionUsecase) Status(ctx context.Context, userID int64) (*SanctionStatus, error) {
+	active, err := uc.active(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SanctionStatus{UserID: userID, RestrictedRoomTypes: []string{}, Active: active}
+	seen := make(map[string]bool)
+	for _, s := range active {
+		switch s.Type {
+		case SanctionBan:
+			status.Banned = true
+		case SanctionMute:
+			status.Muted = true
+		case SanctionWithdrawBlock:
+			status.WithdrawBlocked = true
+		case SanctionRoomRestriction:
+			for _, roomType := range s.RoomTypes {
+				if !seen[roomType] {
+					seen[roomType] = true
+					status.RestrictedRoomTypes = append(status.RestrictedRoomTypes, roomType)
+				}
+			}
+		}
+	}
+	sort.Strings(status.RestrictedRoomTypes)
+	return status, nil
+}
+
+// active 玩家當前生效的處罰，遊客沒有處罰
+func (uc *SanctionUsecase) active(ctx context.Context, userID int64) ([]*Sanction, error) {
+	if userID <= 0 {
+		return []*Sanction{}, nil
+	}
+	sanctions, err := uc.repo.ListActiveSanctions(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active sanctions of user %d: %w", userID, err)
+	}
+	return sanctions, nil
+}
+
+// check 玩家有生效中的 sanctionType 處罰且 match 成立時返回最晚到期的處罰
+func (uc *SanctionUsecase) check(ctx context.Context, userID int64, sanctionType SanctionType, sentinel error, match func(s *Sanction) bool) error {
+	active, err := uc.active(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var longest *Sanction
+	for _, s := range active {
+		if s.Type == sanctionType && (match == nil || match(s)) && s.outlasts(longest) {
+			longest = s
+		}
+	}
+	if longest != nil {
+		return sanctionError(sentinel, longest)
+	}
+	return nil
+}
+
+// CheckLogin 玩家被封禁時返回 ErrAccountBanned
+func (uc *SanctionUsecase) CheckLogin(ctx context.Context, userID int64) error {
+	return uc.check(ctx, userID, SanctionBan, ErrAccountBanned, nil)
+}
+
+// CheckRoomType 玩家被限制進入該類型的房間時返回 ErrRoomTypeRestricted
+func (uc *SanctionUsecase) CheckRoomType(ctx context.Context, userID int64, roomType string) error {
+	roomType = strings.ToLower(roomType)
+	return uc.check(ctx, userID, SanctionRoomRestriction, ErrRoomTypeRestricted, func(s *Sanction) bool {
+		for _, restricted := range s.RoomTypes {
+			if restricted == roomType {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// CheckWithdraw 玩家被禁止提款時返回 ErrWithdrawBlocked
+func (uc *SanctionUsecase) CheckWithdraw(ctx context.Context, userID int64) error {
+	return uc.check(ctx, userID, SanctionWithdrawBlock, ErrWithdrawBlocked, nil)
+}
+
+// CheckChat 玩家被禁言時返回 ErrChatMuted
+func (uc *SanctionUsecase) CheckChat(ctx context.Context, userID int64) error {
+	return uc.check(ctx, userID, SanctionMute, ErrChatMuted, nil)
+}
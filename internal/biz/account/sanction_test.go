@@ -0,0 +1,233 @@
+package account
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSanctionRepo 記憶體中的處罰存儲
+type fakeSanctionRepo struct {
+	sanctions []*Sanction
+	nextID    int64
+}
+
+func (r *fakeSanctionRepo) CreateSanction(ctx context.Context, sanction *Sanction) error {
+	r.nextID++
+	sanction.ID = r.nextID
+	r.sanctions = append(r.sanctions, sanction)
+	return nil
+}
+
+func (r *fakeSanctionRepo) ListSanctions(ctx context.Context, userID int64) ([]*Sanction, error) {
+	var sanctions []*Sanction
+	for i := len(r.sanctions) - 1; i >= 0; i-- {
+		if r.sanctions[i].UserID == userID {
+			sanctions = append(sanctions, r.sanctions[i])
+		}
+	}
+	return sanctions, nil
+}
+
+func (r *fakeSanctionRepo) ListActiveSanctions(ctx context.Context, userID int64, now time.Time) ([]*Sanction, error) {
+	var active []*Sanction
+	for _, s := range r.sanctions {
+		if s.UserID == userID && s.Active(now) {
+			active = append(active, s)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeSanctionRepo) LiftSanction(ctx context.Context, userID, sanctionID, liftedBy int64, reason string, at time.Time) (bool, error) {
+	for _, s := range r.sanctions {
+		if s.ID == sanctionID && s.UserID == userID && s.Active(at) {
+			r.lift(s, liftedBy, reason, at)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeSanctionRepo) LiftSanctionsByType(ctx context.Context, userID int64, sanctionType SanctionType, liftedBy int64, reason string, at time.Time) (int, error) {
+	n := 0
+	for _, s := range r.sanctions {
+		if s.UserID == userID && s.Type == sanctionType && s.Active(at) {
+			r.lift(s, liftedBy, reason, at)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeSanctionRepo) lift(s *Sanction, liftedBy int64, reason string, at time.Time) {
+	s.LiftedAt = &at
+	s.LiftedBy = liftedBy
+	s.LiftReason = reason
+}
+
+func setupTestSanctionUsecase() (*SanctionUsecase, *fakeSanctionRepo, *SessionUsecase, *fakeSessionEvents) {
+	sessions, _, events := setupTestSessionUsecase()
+	repo := &fakeSanctionRepo{}
+	return NewSanctionUsecase(repo, sessions, logger.New(io.Discard, "info", "console")), repo, sessions, events
+}
+
+func TestSanctionIssueValidation(t *testing.T) {
+	ctx := context.Background()
+	uc, _, _, _ := setupTestSanctionUsecase()
+
+	cases := []SanctionRequest{
+		{Type: "kick", Reason: "spam"},
+		{Type: SanctionBan, Reason: "  "},
+		{Type: SanctionBan, Reason: "cheat", Duration: -time.Hour},
+		{Type: SanctionRoomRestriction, Reason: "abuse", RoomTypes: []string{" "}},
+	}
+	for _, req := range cases {
+		_, err := uc.Issue(ctx, 7, 1, req)
+		assert.ErrorIs(t, err, ErrInvalidSanction, "request %+v", req)
+	}
+	_, err := uc.Issue(ctx, 0, 1, SanctionRequest{Type: SanctionBan, Reason: "cheat"})
+	assert.ErrorIs(t, err, ErrInvalidSanction)
+
+	sanction, err := uc.Issue(ctx, 7, 1, SanctionRequest{
+		Type:      SanctionRoomRestriction,
+		Reason:    "abuse",
+		RoomTypes: []string{"VIP", "novice", "vip"},
+		Duration:  time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"novice", "vip"}, sanction.RoomTypes)
+	assert.Equal(t, int64(1), sanction.IssuedBy)
+	require.NotNil(t, sanction.ExpiresAt)
+}
+
+func TestSanctionBanRevokesSessionsAndBlocksLogin(t *testing.T) {
+	ctx := context.Background()
+	uc, _, sessions, events := setupTestSanctionUsecase()
+
+	_, err := sessions.StartSession(ctx, 7, false, "", ClientInfo{})
+	require.NoError(t, err)
+	require.NoError(t, uc.CheckLogin(ctx, 7))
+
+	_, err = uc.Issue(ctx, 7, 1, SanctionRequest{Type: SanctionBan, Reason: "cheating", Duration: 24 * time.Hour})
+	require.NoError(t, err)
+
+	err = uc.CheckLogin(ctx, 7)
+	assert.ErrorIs(t, err, ErrAccountBanned)
+	assert.Contains(t, err.Error(), "cheating")
+	require.NoError(t, uc.CheckLogin(ctx, 8))
+
+	// 封禁時撤銷所有會話，並通知遊戲服關閉該帳號的所有連接
+	list, err := sessions.ListSessions(ctx, 7, "")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	require.NotEmpty(t, events.published)
+	revocation := events.published[len(events.published)-1]
+	assert.Equal(t, int64(7), revocation.UserID)
+	assert.Equal(t, RevokeReasonBanned, revocation.Reason)
+	assert.True(t, revocation.AllConnections)
+}
+
+func TestSanctionChecksByType(t *testing.T) {
+	ctx := context.Background()
+	uc, _, _, _ := setupTestSanctionUsecase()
+
+	_, err := uc.Issue(ctx, 7, 1, SanctionRequest{Type: SanctionRoomRestriction, Reason: "abuse", RoomTypes: []string{"vip"}})
+	require.NoError(t, err)
+	_, err = uc.Issue(ctx, 7, 1, SanctionRequest{Type: SanctionWithdrawBlock, Reason: "fraud review"})
+	require.NoError(t, err)
+
+	assert.NoError(t, uc.CheckLogin(ctx, 7))
+	assert.NoError(t, uc.CheckChat(ctx, 7))
+	assert.ErrorIs(t, uc.CheckRoomType(ctx, 7, "VIP"), ErrRoomTypeRestricted)
+	assert.NoError(t, uc.CheckRoomType(ctx, 7, "novice"))
+	assert.ErrorIs(t, uc.CheckWithdraw(ctx, 7), ErrWithdrawBlocked)
+
+	status, err := uc.Status(ctx, 7)
+	require.NoError(t, err)
+	assert.False(t, status.Banned)
+	assert.True(t, status.WithdrawBlocked)
+	assert.Equal(t, []string{"vip"}, status.RestrictedRoomTypes)
+	assert.Len(t, status.Active, 2)
+
+	// 遊客沒有處罰
+	assert.NoError(t, uc.CheckRoomType(ctx, 0, "vip"))
+}
+
+func TestSanctionExpiryAndLift(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _, _ := setupTestSanctionUsecase()
+
+	expired := time.Now().UTC().Add(-time.Minute)
+	require.NoError(t, repo.CreateSanction(ctx, &Sanction{UserID: 7, Type: SanctionMute, Reason: "spam", ExpiresAt: &expired}))
+	assert.NoError(t, uc.CheckChat(ctx, 7))
+
+	mute, err := uc.Issue(ctx, 7, 1, SanctionRequest{Type: SanctionMute, Reason: "spam again"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, uc.CheckChat(ctx, 7), ErrChatMuted)
+
+	require.NoError(t, uc.Lift(ctx, 7, mute.ID, 2, "appeal accepted"))
+	assert.NoError(t, uc.CheckChat(ctx, 7))
+	assert.Equal(t, int64(2), mute.LiftedBy)
+	assert.ErrorIs(t, uc.Lift(ctx, 7, mute.ID, 2, "again"), ErrSanctionNotFound)
+
+	// 按類型解除所有封禁
+	_, err = uc.Issue(ctx, 7, 1, SanctionRequest{Type: SanctionBan, Reason: "a"})
+	require.NoError(t, err)
+	_, err = uc.Issue(ctx, 7, 1, SanctionRequest{Type: SanctionBan, Reason: "b", Duration: time.Hour})
+	require.NoError(t, err)
+	n, err := uc.LiftType(ctx, 7, SanctionBan, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NoError(t, uc.CheckLogin(ctx, 7))
+	_, err = uc.LiftType(ctx, 7, SanctionBan, 2, "")
+	assert.ErrorIs(t, err, ErrSanctionNotFound)
+
+	history, err := uc.List(ctx, 7)
+	require.NoError(t, err)
+	assert.Len(t, history, 4)
+}
+
+func TestOAuthLoginRejectsBannedAccount(t *testing.T) {
+	ctx := context.Background()
+	svc, server := setupTestOAuthService(t)
+	sanctions, _, sessions, _ := setupTestSanctionUsecase()
+	repo := newFakeAccountRepo()
+	env := &oauthTestEnv{uc: NewAccountUsecase(repo, sessions, sanctions, nil, svc, nil), repo: repo, server: server}
+	alice := fakeOAuthUser{ID: "g-1", Name: "Alice"}
+	login := OAuthIntent{Purpose: OAuthPurposeLogin}
+
+	code, state := env.callback(t, "google", login, alice)
+	_, err := env.uc.OAuthLogin(ctx, "google", code, state, ClientInfo{})
+	require.NoError(t, err)
+
+	_, err = sanctions.Issue(ctx, 1, 1, SanctionRequest{Type: SanctionBan, Reason: "cheating"})
+	require.NoError(t, err)
+	code, state = env.callback(t, "google", login, alice)
+	_, err = env.uc.OAuthLogin(ctx, "google", code, state, ClientInfo{})
+	assert.ErrorIs(t, err, ErrAccountBanned)
+}
+
+func TestDisableUserRevokesSessions(t *testing.T) {
+	ctx := context.Background()
+	sessions, _, events := setupTestSessionUsecase()
+	repo := newFakeAccountRepo()
+	uc := NewAccountUsecase(repo, sessions, nil, nil, nil, nil)
+
+	user, err := repo.CreateUser(ctx, &User{Username: "alice"}, "hash")
+	require.NoError(t, err)
+	_, err = sessions.StartSession(ctx, user.ID, false, "", ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, uc.DisableUser(ctx, user.ID))
+	_, err = uc.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	require.NotEmpty(t, events.published)
+	assert.Equal(t, RevokeReasonDisabled, events.published[len(events.published)-1].Reason)
+	assert.ErrorIs(t, uc.DisableUser(ctx, user.ID), ErrUserNotFound)
+}
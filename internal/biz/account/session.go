@@ -0,0 +1,351 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 登入會話和刷新令牌
+// ========================================
+//
+// 每次登入創建一個會話（一台設備），返回短期訪問令牌（JWT，帶會話 ID）和刷新令牌。
+// 刷新令牌格式為 "<會話ID>.<隨機數>"，Redis 只保存它的 SHA-256；每次刷新都輪換刷新令牌，
+// 並記住已輪換掉的雜湊。已輪換的刷新令牌再次使用說明已被竊取，整個會話立即撤銷；
+// 從未簽發過的令牌（例如只猜到會話 ID）只返回無效令牌，不影響會話。
+// 登出或撤銷會話後訪問令牌立即失效，遊戲伺服器收到撤銷通知後關閉該會話的 WebSocket 連接。
+
+// 會話相關錯誤
+var (
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
+	ErrRefreshTokenReused   = errors.New("refresh token reused, session revoked")
+	ErrGuestSessionsListing = errors.New("guests cannot manage sessions")
+)
+
+// 會話撤銷原因
+const (
+	RevokeReasonLogout     = "logout"
+	RevokeReasonLogoutAll  = "logout_all"
+	RevokeReasonTokenReuse = "refresh_token_reuse"
+	RevokeReasonBanned     = "banned"           // 管理員封禁帳號
+	RevokeReasonDisabled   = "account_disabled" // 管理員停用帳號
+)
+
+// Session 一個登入會話
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"user_id"`
+	IsGuest    bool      `json:"is_guest"`
+	Nickname   string    `json:"nickname,omitempty"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current,omitempty"` // 是否為發起請求的會話，僅在列表中設置
+}
+
+// ClientInfo 發起登入或刷新的客戶端
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+	DeviceID  string // 客戶端提供的設備 ID（X-Device-ID），用於設備指紋和新設備檢測
+}
+
+// SessionTokens 登入或刷新返回的令牌
+type SessionTokens struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`         // 訪問令牌有效期（秒）
+	RefreshExpiresIn int64  `json:"refresh_expires_in"` // 刷新令牌閒置有效期（秒）
+	SessionID        string `json:"session_id"`
+	NewDevice        bool   `json:"new_device,omitempty"` // 從帳號未使用過的設備登入
+}
+
+// SessionRevocation 會話撤銷通知，遊戲伺服器據此關閉連接
+type SessionRevocation struct {
+	UserID     int64    `json:"user_id"`
+	SessionIDs []string `json:"session_ids"`
+	Reason     string   `json:"reason"`
+	// AllConnections 為 true 時關閉玩家的所有連接（包括沒有會話的舊版令牌），用於封禁和停用帳號
+	AllConnections bool `json:"all_connections,omitempty"`
+}
+
+// SessionStore 會話的存儲（Redis），會話在 ExpiresAt 後自動過期
+type SessionStore interface {
+	// CreateSession 保存新會話和刷新令牌雜湊
+	CreateSession(ctx context.Context, session *Session, refreshHash string) error
+	// GetSession 獲取會話和當前刷新令牌雜湊，不存在時返回 ErrSessionNotFound
+	GetSession(ctx context.Context, sessionID string) (*Session, string, error)
+	// RotateRefreshToken 當前雜湊等於 oldHash 時原子地替換為 newHash 並延長有效期，返回是否替換
+	// 被替換的 oldHash 記入會話的已輪換雜湊，直到會話過期或被刪除
+	RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string, lastUsedAt, expiresAt time.Time) (bool, error)
+	// RefreshTokenRotated 雜湊是否為該會話曾經簽發、已被輪換掉的刷新令牌
+	RefreshTokenRotated(ctx context.Context, sessionID, refreshHash string) (bool, error)
+	// ListUserSessions 玩家所有有效會話
+	ListUserSessions(ctx context.Context, userID int64) ([]*Session, error)
+	// DeleteSession 刪除會話
+	DeleteSession(ctx context.Context, session *Session) error
+	// DeleteUserSessions 刪除玩家所有會話，返回被刪除的會話 ID
+	DeleteUserSessions(ctx context.Context, userID int64) ([]string, error)
+	// SessionActive 會話是否仍然有效
+	SessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// SessionEvents 在服務之間廣播會話撤銷
+type SessionEvents interface {
+	PublishSessionRevoked(ctx context.Context, revocation *SessionRevocation) error
+	// SubscribeSessionRevoked 訂閱撤銷通知直到 ctx 取消
+	SubscribeSessionRevoked(ctx context.Context, handler func(revocation *SessionRevocation)) error
+}
+
+// SessionUsecase 登入會話、刷新令牌輪換和登出
+type SessionUsecase struct {
+	store  SessionStore
+	events SessionEvents
+	tokens TokenService
+	logger logger.Logger
+}
+
+// NewSessionUsecase 創建會話用例
+func NewSessionUsecase(store SessionStore, events SessionEvents, tokens TokenService, logger logger.Logger) *SessionUsecase {
+	return &SessionUsecase{
+		store:  store,
+		events: events,
+		tokens: tokens,
+		logger: logger.With("component", "session_usecase"),
+	}
+}
+
+// randomToken 生成 n 字節的 URL 安全隨機字符串
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashRefreshToken 刷新令牌的 SHA-256
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken 為會話生成新的刷新令牌和它的雜湊
+func newRefreshToken(sessionID string) (string, string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken := sessionID + "." + secret
+	return refreshToken, hashRefreshToken(refreshToken), nil
+}
+
+// issueTokens 為會話簽發訪問令牌，並返回給客戶端的令牌
+func (uc *SessionUsecase) issueTokens(session *Session, refreshToken string) (*SessionTokens, error) {
+	accessToken, err := uc.tokens.GenerateSessionToken(session.UserID, session.IsGuest, session.Nickname, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	return &SessionTokens{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(uc.tokens.AccessTokenTTL() / time.Second),
+		RefreshExpiresIn: int64(uc.tokens.RefreshTokenTTL() / time.Second),
+		SessionID:        session.ID,
+	}, nil
+}
+
+// StartSession 為登入成功的玩家或遊客創建會話並簽發令牌
+func (uc *SessionUsecase) StartSession(ctx context.Context, userID int64, isGuest bool, nickname string, client ClientInfo) (*SessionTokens, error) {
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshHash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &Session{
+		ID:         sessionID,
+		UserID:     userID,
+		IsGuest:    isGuest,
+		Nickname:   nickname,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(uc.tokens.RefreshTokenTTL()),
+	}
+	if err := uc.store.CreateSession(ctx, session, refreshHash); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return uc.issueTokens(session, refreshToken)
+}
+
+// Refresh 使用刷新令牌換取新的訪問令牌和刷新令牌；已輪換的舊刷新令牌被重複使用時撤銷整個會話
+func (uc *SessionUsecase) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*SessionTokens, error) {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, currentHash, err := uc.store.GetSession(ctx, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	presentedHash := hashRefreshToken(refreshToken)
+	if subtle.ConstantTimeCompare([]byte(presentedHash), []byte(currentHash)) != 1 {
+		// 只有曾經簽發的舊令牌才算重複使用，偽造的令牌不能用來登出他人的設備
+		rotated, err := uc.store.RefreshTokenRotated(ctx, sessionID, presentedHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check rotated refresh token: %w", err)
+		}
+		if !rotated {
+			return nil, ErrInvalidRefreshToken
+		}
+		uc.revokeReused(ctx, session)
+		return nil, ErrRefreshTokenReused
+	}
+
+	newToken, newHash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	expiresAt := now.Add(uc.tokens.RefreshTokenTTL())
+	rotated, err := uc.store.RotateRefreshToken(ctx, sessionID, presentedHash, newHash, now, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// 同一個刷新令牌被並發使用，同樣視為被竊取
+		uc.revokeReused(ctx, session)
+		return nil, ErrRefreshTokenReused
+	}
+
+	session.LastUsedAt = now
+	session.ExpiresAt = expiresAt
+	if client.IP != "" {
+		session.IP = client.IP
+	}
+	return uc.issueTokens(session, newToken)
+}
+
+// revokeReused 刷新令牌被重複使用時撤銷會話
+func (uc *SessionUsecase) revokeReused(ctx context.Context, session *Session) {
+	uc.logger.Warnf("Refresh token reuse detected for session %s (user %d), revoking session", session.ID, session.UserID)
+	if err := uc.revoke(ctx, session, RevokeReasonTokenReuse); err != nil {
+		uc.logger.Errorf("Failed to revoke session %s after refresh token reuse: %v", session.ID, err)
+	}
+}
+
+// revoke 刪除會話並通知遊戲伺服器
+func (uc *SessionUsecase) revoke(ctx context.Context, session *Session, reason string) error {
+	if err := uc.store.DeleteSession(ctx, session); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", session.ID, err)
+	}
+	uc.publish(ctx, &SessionRevocation{UserID: session.UserID, SessionIDs: []string{session.ID}, Reason: reason})
+	return nil
+}
+
+// publish 廣播撤銷通知；發布失敗時遊戲伺服器在下一次定期檢查時關閉連接
+func (uc *SessionUsecase) publish(ctx context.Context, revocation *SessionRevocation) {
+	if uc.events == nil || (len(revocation.SessionIDs) == 0 && !revocation.AllConnections) {
+		return
+	}
+	if err := uc.events.PublishSessionRevoked(ctx, revocation); err != nil {
+		uc.logger.Errorf("Failed to publish session revocation for user %d: %v", revocation.UserID, err)
+	}
+}
+
+// ListSessions 玩家所有有效會話，按最近使用時間倒序，標記當前會話
+func (uc *SessionUsecase) ListSessions(ctx context.Context, userID int64, currentSessionID string) ([]*Session, error) {
+	if userID <= 0 {
+		return nil, ErrGuestSessionsListing
+	}
+	sessions, err := uc.store.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		s.Current = s.ID == currentSessionID
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+// Logout 登出玩家的一個會話；會話不屬於該玩家時返回 ErrSessionNotFound
+func (uc *SessionUsecase) Logout(ctx context.Context, userID int64, sessionID string) error {
+	session, _, err := uc.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	return uc.revoke(ctx, session, RevokeReasonLogout)
+}
+
+// LogoutAll 登出玩家所有設備，返回登出的會話數
+func (uc *SessionUsecase) LogoutAll(ctx context.Context, userID int64) (int, error) {
+	if userID <= 0 {
+		return 0, ErrGuestSessionsListing
+	}
+	ids, err := uc.store.DeleteUserSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions of user %d: %w", userID, err)
+	}
+	uc.publish(ctx, &SessionRevocation{UserID: userID, SessionIDs: ids, Reason: RevokeReasonLogoutAll})
+	uc.logger.Infof("Logged out %d sessions of user %d", len(ids), userID)
+	return len(ids), nil
+}
+
+// RevokeUser 撤銷玩家所有會話並通知遊戲伺服器關閉該玩家的所有連接，返回撤銷的會話數
+func (uc *SessionUsecase) RevokeUser(ctx context.Context, userID int64, reason string) (int, error) {
+	if userID <= 0 {
+		return 0, ErrGuestSessionsListing
+	}
+	ids, err := uc.store.DeleteUserSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions of user %d: %w", userID, err)
+	}
+	uc.publish(ctx, &SessionRevocation{UserID: userID, SessionIDs: ids, Reason: reason, AllConnections: true})
+	uc.logger.Infof("Revoked %d sessions of user %d (%s)", len(ids), userID, reason)
+	return len(ids), nil
+}
+
+// SessionActive 會話是否仍然有效
+func (uc *SessionUsecase) SessionActive(ctx context.Context, sessionID string) (bool, error) {
+	return uc.store.SessionActive(ctx, sessionID)
+}
+
+// WatchRevocations 訂閱會話撤銷通知直到 ctx 取消
+func (uc *SessionUsecase) WatchRevocations(ctx context.Context, handler func(revocation *SessionRevocation)) error {
+	if uc.events == nil {
+		<-ctx.Done()
+		return nil
+	}
+	return uc.events.SubscribeSessionRevoked(ctx, handler)
+}
@@ -0,0 +1,266 @@
+package account
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSessionStore 記憶體中的會話存儲
+type fakeSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	hashes   map[string]string
+	rotated  map[string]map[string]bool
+}
+
+func newFakeSessionStore() *fakeSessionStore {
+	return &fakeSessionStore{
+		sessions: make(map[string]*Session),
+		hashes:   make(map[string]string),
+		rotated:  make(map[string]map[string]bool),
+	}
+}
+
+func (s *fakeSessionStore) CreateSession(ctx context.Context, session *Session, refreshHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *session
+	s.sessions[session.ID] = &copied
+	s.hashes[session.ID] = refreshHash
+	return nil
+}
+
+func (s *fakeSessionStore) GetSession(ctx context.Context, sessionID string) (*Session, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, "", ErrSessionNotFound
+	}
+	copied := *session
+	return &copied, s.hashes[sessionID], nil
+}
+
+func (s *fakeSessionStore) RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string, lastUsedAt, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[sessionID]; !ok || s.hashes[sessionID] != oldHash {
+		return false, nil
+	}
+	if s.rotated[sessionID] == nil {
+		s.rotated[sessionID] = make(map[string]bool)
+	}
+	s.rotated[sessionID][oldHash] = true
+	s.hashes[sessionID] = newHash
+	s.sessions[sessionID].LastUsedAt = lastUsedAt
+	s.sessions[sessionID].ExpiresAt = expiresAt
+	return true, nil
+}
+
+func (s *fakeSessionStore) RefreshTokenRotated(ctx context.Context, sessionID, refreshHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotated[sessionID][refreshHash], nil
+}
+
+func (s *fakeSessionStore) ListUserSessions(ctx context.Context, userID int64) ([]*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sessions []*Session
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *fakeSessionStore) DeleteSession(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, session.ID)
+	delete(s.hashes, session.ID)
+	delete(s.rotated, session.ID)
+	return nil
+}
+
+func (s *fakeSessionStore) DeleteUserSessions(ctx context.Context, userID int64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			ids = append(ids, id)
+			delete(s.sessions, id)
+			delete(s.hashes, id)
+			delete(s.rotated, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *fakeSessionStore) SessionActive(ctx context.Context, sessionID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.sessions[sessionID]
+	return ok, nil
+}
+
+// fakeSessionEvents 記錄發布的撤銷通知
+type fakeSessionEvents struct {
+	published []*SessionRevocation
+}
+
+func (e *fakeSessionEvents) PublishSessionRevoked(ctx context.Context, revocation *SessionRevocation) error {
+	e.published = append(e.published, revocation)
+	return nil
+}
+
+func (e *fakeSessionEvents) SubscribeSessionRevoked(ctx context.Context, handler func(revocation *SessionRevocation)) error {
+	<-ctx.Done()
+	return nil
+}
+
+// fakeTokenService 簽發可讀的假訪問令牌
+type fakeTokenService struct{}
+
+func (fakeTokenService) GenerateSessionToken(userID int64, isGuest bool, nickname, sessionID string) (string, error) {
+	return "access:" + sessionID, nil
+}
+func (fakeTokenService) AccessTokenTTL() time.Duration  { return 15 * time.Minute }
+func (fakeTokenService) RefreshTokenTTL() time.Duration { return 30 * 24 * time.Hour }
+
+func setupTestSessionUsecase() (*SessionUsecase, *fakeSessionStore, *fakeSessionEvents) {
+	store := newFakeSessionStore()
+	events := &fakeSessionEvents{}
+	uc := NewSessionUsecase(store, events, fakeTokenService{}, logger.New(io.Discard, "info", "console"))
+	return uc, store, events
+}
+
+func TestSessionStartAndRefreshRotates(t *testing.T) {
+	ctx := context.Background()
+	uc, store, _ := setupTestSessionUsecase()
+
+	tokens, err := uc.StartSession(ctx, 7, false, "", ClientInfo{UserAgent: "ios", IP: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, "access:"+tokens.SessionID, tokens.AccessToken)
+	assert.Equal(t, int64(900), tokens.ExpiresIn)
+	assert.Equal(t, int64(30*24*3600), tokens.RefreshExpiresIn)
+	// 存儲中只有刷新令牌的雜湊
+	assert.Equal(t, hashRefreshToken(tokens.RefreshToken), store.hashes[tokens.SessionID])
+
+	refreshed, err := uc.Refresh(ctx, tokens.RefreshToken, ClientInfo{IP: "10.0.0.2"})
+	require.NoError(t, err)
+	assert.Equal(t, tokens.SessionID, refreshed.SessionID)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+	// 新的刷新令牌可以繼續使用
+	again, err := uc.Refresh(ctx, refreshed.RefreshToken, ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, tokens.SessionID, again.SessionID)
+
+	_, err = uc.Refresh(ctx, "garbage", ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = uc.Refresh(ctx, "unknown.secret", ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestSessionRefreshReuseRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	uc, _, events := setupTestSessionUsecase()
+
+	tokens, err := uc.StartSession(ctx, 7, false, "", ClientInfo{})
+	require.NoError(t, err)
+	refreshed, err := uc.Refresh(ctx, tokens.RefreshToken, ClientInfo{})
+	require.NoError(t, err)
+
+	// 舊刷新令牌被再次使用：撤銷整個會話
+	_, err = uc.Refresh(ctx, tokens.RefreshToken, ClientInfo{})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	active, err := uc.SessionActive(ctx, tokens.SessionID)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	require.Len(t, events.published, 1)
+	assert.Equal(t, []string{tokens.SessionID}, events.published[0].SessionIDs)
+	assert.Equal(t, RevokeReasonTokenReuse, events.published[0].Reason)
+
+	// 合法持有者的新刷新令牌也隨會話失效
+	_, err = uc.Refresh(ctx, refreshed.RefreshToken, ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestSessionRefreshForgedSecretDoesNotRevoke(t *testing.T) {
+	ctx := context.Background()
+	uc, _, events := setupTestSessionUsecase()
+
+	tokens, err := uc.StartSession(ctx, 7, false, "", ClientInfo{})
+	require.NoError(t, err)
+
+	// 只知道會話 ID 的攻擊者偽造刷新令牌：拒絕但不撤銷會話
+	_, err = uc.Refresh(ctx, tokens.SessionID+".garbage", ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	active, err := uc.SessionActive(ctx, tokens.SessionID)
+	require.NoError(t, err)
+	assert.True(t, active)
+	assert.Empty(t, events.published)
+
+	// 合法持有者仍可正常刷新
+	_, err = uc.Refresh(ctx, tokens.RefreshToken, ClientInfo{})
+	require.NoError(t, err)
+}
+
+func TestSessionListAndLogout(t *testing.T) {
+	ctx := context.Background()
+	uc, _, events := setupTestSessionUsecase()
+
+	phone, err := uc.StartSession(ctx, 7, false, "", ClientInfo{UserAgent: "phone"})
+	require.NoError(t, err)
+	laptop, err := uc.StartSession(ctx, 7, false, "", ClientInfo{UserAgent: "laptop"})
+	require.NoError(t, err)
+	other, err := uc.StartSession(ctx, 8, false, "", ClientInfo{})
+	require.NoError(t, err)
+
+	sessions, err := uc.ListSessions(ctx, 7, laptop.SessionID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for _, s := range sessions {
+		assert.Equal(t, s.ID == laptop.SessionID, s.Current)
+	}
+
+	_, err = uc.ListSessions(ctx, 0, "")
+	assert.ErrorIs(t, err, ErrGuestSessionsListing)
+
+	// 不能登出其他玩家的會話
+	err = uc.Logout(ctx, 7, other.SessionID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	require.NoError(t, uc.Logout(ctx, 7, phone.SessionID))
+	sessions, err = uc.ListSessions(ctx, 7, laptop.SessionID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, laptop.SessionID, sessions[0].ID)
+
+	count, err := uc.LogoutAll(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	active, err := uc.SessionActive(ctx, other.SessionID)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	require.Len(t, events.published, 2)
+	assert.Equal(t, RevokeReasonLogout, events.published[0].Reason)
+	assert.Equal(t, RevokeReasonLogoutAll, events.published[1].Reason)
+	assert.Equal(t, []string{laptop.SessionID}, events.published[1].SessionIDs)
+}
@@ -16,25 +16,46 @@ import (
 // - 使用者資料管理
 // - JWT Token 生成與驗證
 
+// ErrUserNotFound 使用者不存在或已停用
+var ErrUserNotFound = errors.New("user not found")
+
 // AccountUsecase 定義帳號業務邏輯的介面
 type AccountUsecase interface {
 	// Register 註冊新使用者
 	Register(ctx context.Context, username, password string) (*User, error)
 
-	// Login 使用者登入（使用者名稱+密碼）
-	Login(ctx context.Context, username, password string) (string, error)
+	// Login 使用者登入（使用者名稱+密碼），為客戶端創建登入會話
+	Login(ctx context.Context, username, password string, client ClientInfo) (*SessionTokens, error)
+
+	// GuestLogin 遊客登入，為客戶端創建登入會話
+	GuestLogin(ctx context.Context, client ClientInfo) (*SessionTokens, error)
+
+	// OAuthLogin 第三方 OAuth 登入，校驗授權時的 state 後為客戶端創建登入會話
+	OAuthLogin(ctx context.Context, provider, code, state string, client ClientInfo) (*SessionTokens, error)
+
+	// OAuthProviders 已配置的第三方平台
+	OAuthProviders() []string
+
+	// AuthorizeOAuth 生成第三方平台的授權地址（登入或綁定）
+	AuthorizeOAuth(ctx context.Context, provider string, intent OAuthIntent) (*OAuthAuthorization, error)
+
+	// ListOAuthIdentities 帳號綁定的第三方身份
+	ListOAuthIdentities(ctx context.Context, userID int64) ([]*OAuthIdentity, error)
 
-	// GuestLogin 遊客登入
-	GuestLogin(ctx context.Context) (string, error)
+	// LinkOAuth 為帳號綁定第三方身份
+	LinkOAuth(ctx context.Context, userID int64, provider, code, state string) (*OAuthIdentity, error)
 
-	// OAuthLogin 第三方 OAuth 登入
-	OAuthLogin(ctx context.Context, provider string, code string) (string, error)
+	// UnlinkOAuth 解綁第三方身份
+	UnlinkOAuth(ctx context.Context, userID int64, provider string) error
 
 	// GetUserByID 根據 ID 獲取使用者資料
 	GetUserByID(ctx context.Context, userID int64) (*User, error)
 
 	// UpdateUser 更新使用者資料
 	UpdateUser(ctx context.Context, userID int64, nickname, avatarURL string) error
+
+	// DisableUser 停用帳號：不能再登入，撤銷所有會話並關閉遊戲連接
+	DisableUser(ctx context.Context, userID int64) error
 }
 
 // User 代表使用者實體
@@ -46,12 +67,17 @@ type User struct {
 	IsGuest           bool   `json:"is_guest"`
 	ThirdPartyProvider string `json:"third_party_provider,omitempty"`
 	ThirdPartyID      string `json:"third_party_id,omitempty"`
+	HasPassword       bool   `json:"-"` // 是否設置了密碼，解綁第三方身份時檢查
 }
 
 // TokenService 定義 Token 生成服務介面
 type TokenService interface {
-	GenerateTokenWithClaims(userID int64, isGuest bool) (string, error)
-	GenerateGuestToken(nickname string) (string, error)
+	// GenerateSessionToken 生成綁定登入會話的訪問令牌
+	GenerateSessionToken(userID int64, isGuest bool, nickname, sessionID string) (string, error)
+	// AccessTokenTTL 訪問令牌有效期
+	AccessTokenTTL() time.Duration
+	// RefreshTokenTTL 刷新令牌閒置有效期
+	RefreshTokenTTL() time.Duration
 }
 
 // WalletCreator 定義錢包創建服務介面
@@ -62,16 +88,20 @@ type WalletCreator interface {
 // accountUsecase 實現 AccountUsecase 介面
 type accountUsecase struct {
 	repo          AccountRepo
-	tokenService  TokenService
+	sessions      *SessionUsecase
+	sanctions     *SanctionUsecase
+	security      *LoginSecurityUsecase
 	oauthService  OAuthService
 	walletCreator WalletCreator
 }
 
 // NewAccountUsecase 建立新的 AccountUsecase 實例
-func NewAccountUsecase(repo AccountRepo, tokenService TokenService, oauthService OAuthService, walletCreator WalletCreator) AccountUsecase {
+func NewAccountUsecase(repo AccountRepo, sessions *SessionUsecase, sanctions *SanctionUsecase, security *LoginSecurityUsecase, oauthService OAuthService, walletCreator WalletCreator) AccountUsecase {
 	return &accountUsecase{
 		repo:          repo,
-		tokenService:  tokenService,
+		sessions:      sessions,
+		sanctions:     sanctions,
+		security:      security,
 		oauthService:  oauthService,
 		walletCreator: walletCreator,
 	}
@@ -106,74 +136,79 @@ func (uc *accountUsecase) Register(ctx context.Context, username, password strin
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// 自動創建初始錢包（CNY幣種）
-	if uc.walletCreator != nil {
-		err = uc.walletCreator.CreateWallet(ctx, uint(createdUser.ID), "CNY")
-		if err != nil {
-			// 錢包創建失敗記錄錯誤，但不影響註冊流程
-			// TODO: 可以考慮使用消息隊列異步創建
-			fmt.Printf("Warning: failed to create initial wallet for user %d: %v\n", createdUser.ID, err)
-		}
-	}
+	uc.createInitialWallet(ctx, createdUser.ID)
 
 	return createdUser, nil
 }
 
 // Login 使用者登入（使用者名稱+密碼）
-func (uc *accountUsecase) Login(ctx context.Context, username, password string) (string, error) {
+// 帳號名或 IP 失敗次數過多時在查詢帳號之前返回 *LoginThrottledError
+func (uc *accountUsecase) Login(ctx context.Context, username, password string, client ClientInfo) (*SessionTokens, error) {
+	if uc.security != nil {
+		if err := uc.security.CheckAllowed(ctx, username, client.IP); err != nil {
+			return nil, err
+		}
+	}
+
 	// 根據使用者名稱獲取使用者
 	user, passwordHash, err := uc.repo.GetUserByUsername(ctx, username)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
-	if user == nil {
-		return "", errors.New("invalid username or password")
+
+	// 帳號不存在和密碼錯誤同樣計入失敗次數
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) != nil {
+		if uc.security != nil {
+			var userID int64
+			if user != nil {
+				userID = user.ID
+			}
+			uc.security.RecordFailure(ctx, userID, username, client)
+		}
+		return nil, ErrInvalidLogin
 	}
 
-	// 驗證密碼
-	err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
-	if err != nil {
-		return "", errors.New("invalid username or password")
+	// 密碼正確後才檢查封禁，避免洩露帳號狀態
+	if err := uc.checkBanned(ctx, user.ID); err != nil {
+		uc.recordRejectedLogin(ctx, user.ID, LoginMethodPassword, err, client)
+		return nil, err
 	}
 
-	// 生成 JWT token
-	token, err := uc.tokenService.GenerateTokenWithClaims(user.ID, false)
+	// 創建登入會話並簽發令牌
+	tokens, err := uc.sessions.StartSession(ctx, user.ID, false, "", client)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
-
-	return token, nil
+	if uc.security != nil {
+		tokens.NewDevice = uc.security.RecordSuccess(ctx, user.ID, username, LoginMethodPassword, client)
+	}
+	return tokens, nil
 }
 
 // GuestLogin 遊客登入（純內存模式，不創建數據庫記錄）
-func (uc *accountUsecase) GuestLogin(ctx context.Context) (string, error) {
+func (uc *accountUsecase) GuestLogin(ctx context.Context, client ClientInfo) (*SessionTokens, error) {
 	// 生成唯一的遊客昵稱（使用時間戳和隨機數）
 	guestNickname := fmt.Sprintf("guest_%d", generateGuestID())
 
-	// 生成遊客專用 JWT token（不使用數據庫 user_id）
-	token, err := uc.tokenService.GenerateGuestToken(guestNickname)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate guest token: %w", err)
-	}
-
-	return token, nil
+	// 遊客會話不使用數據庫 user_id，昵稱保存在令牌中
+	return uc.sessions.StartSession(ctx, 0, true, guestNickname, client)
 }
 
 // OAuthLogin 第三方 OAuth 登入
-func (uc *accountUsecase) OAuthLogin(ctx context.Context, provider string, code string) (string, error) {
+func (uc *accountUsecase) OAuthLogin(ctx context.Context, provider, code, state string, client ClientInfo) (*SessionTokens, error) {
 	// 使用 OAuth 服務獲取使用者資訊
-	oauthUserInfo, err := uc.oauthService.GetUserInfo(ctx, provider, code)
+	oauthUserInfo, err := uc.oauthService.GetUserInfo(ctx, provider, code, state, OAuthIntent{Purpose: OAuthPurposeLogin})
 	if err != nil {
-		return "", fmt.Errorf("failed to get oauth user info: %w", err)
+		return nil, fmt.Errorf("failed to get oauth user info: %w", err)
 	}
 
 	// 根據第三方平台 ID 查找使用者
 	user, err := uc.repo.GetUserByThirdParty(ctx, provider, oauthUserInfo.ThirdPartyID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user by third party: %w", err)
+		return nil, fmt.Errorf("failed to get user by third party: %w", err)
 	}
 
-	// 如果使用者不存在，建立新使用者
+	// 如果使用者不存在，建立新使用者並綁定該身份
 	if user == nil {
 		user = &User{
 			Nickname:          oauthUserInfo.Nickname,
@@ -185,17 +220,23 @@ func (uc *accountUsecase) OAuthLogin(ctx context.Context, provider string, code
 
 		user, err = uc.repo.CreateUser(ctx, user, "")
 		if err != nil {
-			return "", fmt.Errorf("failed to create oauth user: %w", err)
+			return nil, fmt.Errorf("failed to create oauth user: %w", err)
 		}
+		uc.createInitialWallet(ctx, user.ID)
+	} else if err := uc.checkBanned(ctx, user.ID); err != nil {
+		uc.recordRejectedLogin(ctx, user.ID, LoginMethodOAuth+":"+provider, err, client)
+		return nil, err
 	}
 
-	// 生成 JWT token
-	token, err := uc.tokenService.GenerateTokenWithClaims(user.ID, false)
+	// 創建登入會話並簽發令牌
+	tokens, err := uc.sessions.StartSession(ctx, user.ID, false, "", client)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
-
-	return token, nil
+	if uc.security != nil {
+		tokens.NewDevice = uc.security.RecordSuccess(ctx, user.ID, "", LoginMethodOAuth+":"+provider, client)
+	}
+	return tokens, nil
 }
 
 // GetUserByID 根據 ID 獲取使用者資料
@@ -205,7 +246,7 @@ func (uc *accountUsecase) GetUserByID(ctx context.Context, userID int64) (*User,
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	return user, nil
 }
@@ -218,7 +259,7 @@ func (uc *accountUsecase) UpdateUser(ctx context.Context, userID int64, nickname
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
 	// 更新使用者資料
@@ -237,6 +278,48 @@ func (uc *accountUsecase) UpdateUser(ctx context.Context, userID int64, nickname
 	return nil
 }
 
+// DisableUser 停用帳號
+func (uc *accountUsecase) DisableUser(ctx context.Context, userID int64) error {
+	disabled, err := uc.repo.DisableUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to disable user: %w", err)
+	}
+	if !disabled {
+		return ErrUserNotFound
+	}
+	if _, err := uc.sessions.RevokeUser(ctx, userID, RevokeReasonDisabled); err != nil {
+		return fmt.Errorf("failed to revoke sessions of disabled user: %w", err)
+	}
+	return nil
+}
+
+// createInitialWallet 為新帳號創建主錢包（CNY 幣種），遊戲內餘額和大廳金幣都來自主錢包
+func (uc *accountUsecase) createInitialWallet(ctx context.Context, userID int64) {
+	if uc.walletCreator == nil {
+		return
+	}
+	if err := uc.walletCreator.CreateWallet(ctx, uint(userID), "CNY"); err != nil {
+		// 錢包創建失敗記錄錯誤，但不影響註冊流程
+		// TODO: 可以考慮使用消息隊列異步創建
+		fmt.Printf("Warning: failed to create initial wallet for user %d: %v\n", userID, err)
+	}
+}
+
+// checkBanned 玩家被封禁時返回 ErrAccountBanned
+func (uc *accountUsecase) checkBanned(ctx context.Context, userID int64) error {
+	if uc.sanctions == nil {
+		return nil
+	}
+	return uc.sanctions.CheckLogin(ctx, userID)
+}
+
+// recordRejectedLogin 記錄憑證正確但被封禁拒絕的登入
+func (uc *accountUsecase) recordRejectedLogin(ctx context.Context, userID int64, method string, err error, client ClientInfo) {
+	if uc.security != nil && errors.Is(err, ErrAccountBanned) {
+		uc.security.RecordRejected(ctx, userID, method, LoginFailureBanned, client)
+	}
+}
+
 // generateGuestID 生成遊客 ID（使用納秒級時間戳）
 func generateGuestID() int64 {
 	return time.Now().UnixNano() / 1000000 // 毫秒級時間戳
@@ -0,0 +1,351 @@
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 管理操作審計日誌
+// ========================================
+//
+// 管理後台每個修改類請求（POST/PUT/PATCH/DELETE）都會追加審計記錄：
+// 誰（管理員和當時的角色）、從哪裡（IP、請求 ID）、對什麼（路由和目標）做了什麼，以及修改前後的數據。
+// 處理器執行前先追加一條狀態為 AuditStatusPending 的意圖記錄，寫入失敗時拒絕請求，
+// 處理完成後再追加一條帶響應狀態和修改前後數據的結果記錄，兩條記錄通過請求 ID 關聯。
+// 審計日誌只能追加：序號連續遞增，每條記錄的雜湊包含上一條記錄的雜湊，
+// 刪除或修改任何一條記錄都會讓 Verify 在該序號處檢測到斷鏈；
+// 截斷鏈尾無法通過鏈本身發現，需要定期把 Verify 返回的鏈頭雜湊保存到外部。
+
+const (
+	// defaultAuditPageSize 審計查詢默認每頁條數
+	defaultAuditPageSize = 50
+	// maxAuditPageSize 審計查詢每頁最多條數
+	maxAuditPageSize = 500
+	// auditBatchSize 導出和校驗時每批讀取的條數
+	auditBatchSize = 500
+	// MaxAuditExportRows 單次導出的最多條數
+	MaxAuditExportRows = 50000
+	// AuditStatusPending 處理器執行前寫入的意圖記錄的狀態
+	AuditStatusPending = 0
+)
+
+// genesisAuditHash 第一條記錄的上一條雜湊
+var genesisAuditHash = strings.Repeat("0", sha256.Size*2)
+
+// AuditEntry 一條審計記錄
+type AuditEntry struct {
+	Seq       int64           `json:"seq"`
+	ActorID   int64           `json:"actor_id"`
+	ActorName string          `json:"actor_name"`
+	Role      string          `json:"role"`
+	Method    string          `json:"method"`
+	Route     string          `json:"route"`  // 路由模板，例如 /admin/wallets/:id/deposit
+	Path      string          `json:"path"`   // 實際請求路徑
+	Target    string          `json:"target"` // 操作對象，例如 wallet:12
+	Status    int             `json:"status"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Diff      json.RawMessage `json:"diff,omitempty"` // 按字段路徑列出變化的值
+	IP        string          `json:"ip"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
+// auditHashInput 參與雜湊計算的字段，字段順序固定
+type auditHashInput struct {
+	Seq       int64           `json:"seq"`
+	PrevHash  string          `json:"prev_hash"`
+	ActorID   int64           `json:"actor_id"`
+	ActorName string          `json:"actor_name"`
+	Role      string          `json:"role"`
+	Method    string          `json:"method"`
+	Route     string          `json:"route"`
+	Path      string          `json:"path"`
+	Target    string          `json:"target"`
+	Status    int             `json:"status"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Diff      json.RawMessage `json:"diff"`
+	IP        string          `json:"ip"`
+	RequestID string          `json:"request_id"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// ComputeHash 計算記錄的雜湊，JSON 字段會被壓縮，與存儲時的空白無關
+func (e *AuditEntry) ComputeHash() (string, error) {
+	data, err := json.Marshal(auditHashInput{
+		Seq:       e.Seq,
+		PrevHash:  e.PrevHash,
+		ActorID:   e.ActorID,
+		ActorName: e.ActorName,
+		Role:      e.Role,
+		Method:    e.Method,
+		Route:     e.Route,
+		Path:      e.Path,
+		Target:    e.Target,
+		Status:    e.Status,
+		Before:    e.Before,
+		After:     e.After,
+		Diff:      e.Diff,
+		IP:        e.IP,
+		RequestID: e.RequestID,
+		CreatedAt: e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Chain 把記錄接在 prev 之後（prev 為 nil 表示第一條），設置序號、上一條雜湊和本條雜湊
+// 存儲實現必須在持有全局鎖時讀取鏈尾並調用 Chain
+func (e *AuditEntry) Chain(prev *AuditEntry) error {
+	e.Seq = 1
+	e.PrevHash = genesisAuditHash
+	if prev != nil {
+		e.Seq = prev.Seq + 1
+		e.PrevHash = prev.Hash
+	}
+	hash, err := e.ComputeHash()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+	return nil
+}
+
+// AuditFilter 審計日誌查詢條件，零值字段不過濾
+type AuditFilter struct {
+	ActorID int64
+	Method  string
+	Route   string
+	Target  string
+	From    time.Time
+	To      time.Time
+	// BeforeSeq 只返回序號小於它的記錄，分批讀取時新追加的記錄不會打亂分頁
+	BeforeSeq int64
+	Limit     int
+	Offset    int
+}
+
+// AuditVerification 審計鏈校驗結果
+type AuditVerification struct {
+	Valid     bool   `json:"valid"`
+	Checked   int64  `json:"checked"`
+	HeadSeq   int64  `json:"head_seq"`
+	HeadHash  string `json:"head_hash"`
+	BrokenSeq int64  `json:"broken_seq,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// AuditRepo 審計日誌的持久化（admin_audit_log）
+type AuditRepo interface {
+	// AppendAudit 在全局鎖內讀取鏈尾，調用 entry.Chain 後寫入
+	AppendAudit(ctx context.Context, entry *AuditEntry) error
+	// ListAudit 按條件查詢，序號倒序
+	ListAudit(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+	// ScanAudit 讀取序號大於 afterSeq 的最多 limit 條記錄，序號正序
+	ScanAudit(ctx context.Context, afterSeq int64, limit int) ([]*AuditEntry, error)
+}
+
+// AuditUsecase 記錄、查詢和校驗管理操作審計日誌
+type AuditUsecase struct {
+	repo   AuditRepo
+	logger logger.Logger
+}
+
+// NewAuditUsecase 創建審計日誌用例
+func NewAuditUsecase(repo AuditRepo, logger logger.Logger) *AuditUsecase {
+	return &AuditUsecase{
+		repo:   repo,
+		logger: logger.With("component", "audit_usecase"),
+	}
+}
+
+// Record 追加一條審計記錄，提供了修改前後數據且未提供 Diff 時自動計算
+func (uc *AuditUsecase) Record(ctx context.Context, entry *AuditEntry) error {
+	// 數據庫時間戳精度為微秒，截斷後雜湊在讀回時才能重現
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	if entry.Diff == nil && (entry.Before != nil || entry.After != nil) {
+		diff, err := DiffJSON(entry.Before, entry.After)
+		if err != nil {
+			return err
+		}
+		entry.Diff = diff
+	}
+	if err := uc.repo.AppendAudit(ctx, entry); err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	return nil
+}
+
+// normalizeAuditFilter 校驗查詢條件並填充分頁默認值
+func normalizeAuditFilter(filter AuditFilter, maxLimit int) (AuditFilter, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return filter, fmt.Errorf("%w: from is after to", ErrInvalidAuditFilter)
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return filter, fmt.Errorf("%w: limit and offset must not be negative", ErrInvalidAuditFilter)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	filter.Method = strings.ToUpper(filter.Method)
+	return filter, nil
+}
+
+// Query 按條件查詢審計日誌，序號倒序
+func (uc *AuditUsecase) Query(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error) {
+	filter, err := normalizeAuditFilter(filter, maxAuditPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.ListAudit(ctx, filter)
+}
+
+// Export 按條件分批讀取審計日誌（序號倒序）並逐條交給 fn，最多 MaxAuditExportRows 條
+func (uc *AuditUsecase) Export(ctx context.Context, filter AuditFilter, fn func(*AuditEntry) error) error {
+	filter.Limit = auditBatchSize
+	filter, err := normalizeAuditFilter(filter, auditBatchSize)
+	if err != nil {
+		return err
+	}
+	for exported := 0; exported < MaxAuditExportRows; {
+		entries, err := uc.repo.ListAudit(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if exported >= MaxAuditExportRows {
+				return nil
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+			exported++
+		}
+		if len(entries) < filter.Limit {
+			return nil
+		}
+		filter.BeforeSeq = entries[len(entries)-1].Seq
+		filter.Offset = 0
+	}
+	return nil
+}
+
+// Verify 從第一條記錄開始校驗序號連續性和雜湊鏈，返回第一個斷點
+func (uc *AuditUsecase) Verify(ctx context.Context) (*AuditVerification, error) {
+	result := &AuditVerification{Valid: true, HeadHash: genesisAuditHash}
+	prev := &AuditEntry{Seq: 0, Hash: genesisAuditHash}
+	for {
+		entries, err := uc.repo.ScanAudit(ctx, prev.Seq, auditBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if reason := checkAuditLink(prev, entry); reason != "" {
+				result.Valid = false
+				result.BrokenSeq = prev.Seq + 1
+				result.Reason = reason
+				uc.logger.Errorf("Audit log chain broken at seq %d: %s", result.BrokenSeq, reason)
+				return result, nil
+			}
+			result.Checked++
+			result.HeadSeq = entry.Seq
+			result.HeadHash = entry.Hash
+			prev = entry
+		}
+		if len(entries) < auditBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// checkAuditLink 檢查 entry 是否正確接在 prev 之後，返回空字符串表示正確
+func checkAuditLink(prev, entry *AuditEntry) string {
+	if entry.Seq != prev.Seq+1 {
+		return fmt.Sprintf("expected seq %d, found %d (entries deleted)", prev.Seq+1, entry.Seq)
+	}
+	if entry.PrevHash != prev.Hash {
+		return fmt.Sprintf("prev_hash of seq %d does not match hash of seq %d", entry.Seq, prev.Seq)
+	}
+	hash, err := entry.ComputeHash()
+	if err != nil {
+		return err.Error()
+	}
+	if hash != entry.Hash {
+		return fmt.Sprintf("hash of seq %d does not match its content (entry modified)", entry.Seq)
+	}
+	return ""
+}
+
+// AuditChange 一個字段的修改前後值
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DiffJSON 比較修改前後的 JSON，返回按字段路徑（例如 config.min_bet）列出的變化，沒有變化時返回 nil
+// 嵌套對象逐層展開，數組和標量整體比較；根節點不是對象時使用空路徑
+func DiffJSON(before, after json.RawMessage) (json.RawMessage, error) {
+	var b, a interface{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, fmt.Errorf("failed to decode audit before value: %w", err)
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, fmt.Errorf("failed to decode audit after value: %w", err)
+		}
+	}
+
+	changes := make(map[string]AuditChange)
+	diffValue("", b, a, changes)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(changes)
+}
+
+// diffValue 遞歸比較兩個 JSON 值，把不同的葉子寫入 changes
+func diffValue(path string, before, after interface{}, changes map[string]AuditChange) {
+	bm, bok := before.(map[string]interface{})
+	am, aok := after.(map[string]interface{})
+	if !bok || !aok {
+		if !reflect.DeepEqual(before, after) {
+			changes[path] = AuditChange{Before: before, After: after}
+		}
+		return
+	}
+
+	keys := make(map[string]struct{}, len(bm)+len(am))
+	for k := range bm {
+		keys[k] = struct{}{}
+	}
+	for k := range am {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		child := k
+		if path != "" {
+			child = path + "." + k
+		}
+		diffValue(child, bm[k], am[k], changes)
+	}
+}
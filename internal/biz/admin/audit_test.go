@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditRepo 記憶體中的審計日誌，entries 按序號正序保存
+type fakeAuditRepo struct {
+	entries []*AuditEntry
+}
+
+func (r *fakeAuditRepo) AppendAudit(ctx context.Context, entry *AuditEntry) error {
+	var prev *AuditEntry
+	if len(r.entries) > 0 {
+		prev = r.entries[len(r.entries)-1]
+	}
+	if err := entry.Chain(prev); err != nil {
+		return err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeAuditRepo) ListAudit(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error) {
+	var matched []*AuditEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		e := r.entries[i]
+		if filter.ActorID != 0 && e.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.Target != "" && e.Target != filter.Target {
+			continue
+		}
+		if filter.BeforeSeq != 0 && e.Seq >= filter.BeforeSeq {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	if filter.Offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[filter.Offset:]
+	if len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	return matched, nil
+}
+
+func (r *fakeAuditRepo) ScanAudit(ctx context.Context, afterSeq int64, limit int) ([]*AuditEntry, error) {
+	var entries []*AuditEntry
+	for _, e := range r.entries {
+		if e.Seq > afterSeq && len(entries) < limit {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func setupTestAuditUsecase(t *testing.T, count int) (*AuditUsecase, *fakeAuditRepo) {
+	repo := &fakeAuditRepo{}
+	uc := NewAuditUsecase(repo, logger.New(io.Discard, "info", "console"))
+	for i := 0; i < count; i++ {
+		err := uc.Record(context.Background(), &AuditEntry{
+			ActorID:   int64(i%2 + 1),
+			ActorName: "finance",
+			Role:      RoleFinance,
+			Method:    "POST",
+			Route:     "/admin/wallets/:id/deposit",
+			Path:      "/admin/wallets/7/deposit",
+			Target:    "wallet:7",
+			Status:    200,
+			Before:    json.RawMessage(`{"balance": 100, "status": 1}`),
+			After:     json.RawMessage(`{"balance": 150, "status": 1}`),
+			IP:        "10.0.0.1",
+			RequestID: "req-1",
+		})
+		require.NoError(t, err)
+	}
+	return uc, repo
+}
+
+func TestAuditRecordChainsEntries(t *testing.T) {
+	_, repo := setupTestAuditUsecase(t, 3)
+	require.Len(t, repo.entries, 3)
+
+	assert.Equal(t, int64(1), repo.entries[0].Seq)
+	assert.Equal(t, genesisAuditHash, repo.entries[0].PrevHash)
+	for i := 1; i < len(repo.entries); i++ {
+		assert.Equal(t, int64(i+1), repo.entries[i].Seq)
+		assert.Equal(t, repo.entries[i-1].Hash, repo.entries[i].PrevHash)
+	}
+
+	// 自動計算修改前後的差異
+	var diff map[string]AuditChange
+	require.NoError(t, json.Unmarshal(repo.entries[0].Diff, &diff))
+	assert.Equal(t, AuditChange{Before: float64(100), After: float64(150)}, diff["balance"])
+	assert.NotContains(t, diff, "status")
+}
+
+func TestAuditVerifyDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+
+	uc, _ := setupTestAuditUsecase(t, 5)
+	result, err := uc.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(5), result.Checked)
+	assert.Equal(t, int64(5), result.HeadSeq)
+
+	// 修改內容
+	uc, repo := setupTestAuditUsecase(t, 5)
+	repo.entries[2].After = json.RawMessage(`{"balance": 999999, "status": 1}`)
+	result, err = uc.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(3), result.BrokenSeq)
+	assert.Equal(t, int64(2), result.Checked)
+
+	// 刪除中間的記錄
+	uc, repo = setupTestAuditUsecase(t, 5)
+	repo.entries = append(repo.entries[:1], repo.entries[2:]...)
+	result, err = uc.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.BrokenSeq)
+
+	// 刪除後重新編號也會斷鏈
+	uc, repo = setupTestAuditUsecase(t, 5)
+	repo.entries = append(repo.entries[:1], repo.entries[2:]...)
+	for i, e := range repo.entries {
+		e.Seq = int64(i + 1)
+	}
+	result, err = uc.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.BrokenSeq)
+}
+
+func TestAuditQueryAndExport(t *testing.T) {
+	ctx := context.Background()
+	uc, _ := setupTestAuditUsecase(t, 7)
+
+	entries, err := uc.Query(ctx, AuditFilter{ActorID: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(7), entries[0].Seq)
+	assert.Equal(t, int64(5), entries[1].Seq)
+
+	_, err = uc.Query(ctx, AuditFilter{Limit: -1})
+	assert.ErrorIs(t, err, ErrInvalidAuditFilter)
+
+	var exported []int64
+	err = uc.Export(ctx, AuditFilter{}, func(e *AuditEntry) error {
+		exported = append(exported, e.Seq)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 6, 5, 4, 3, 2, 1}, exported)
+}
+
+func TestDiffJSON(t *testing.T) {
+	diff, err := DiffJSON(
+		json.RawMessage(`{"enabled": true, "weights": {"v": 1, "line": 2}, "tags": ["a"]}`),
+		json.RawMessage(`{"enabled": true, "weights": {"v": 3, "line": 2}, "tags": ["a", "b"], "new": 1}`),
+	)
+	require.NoError(t, err)
+
+	var changes map[string]AuditChange
+	require.NoError(t, json.Unmarshal(diff, &changes))
+	assert.Len(t, changes, 3)
+	assert.Equal(t, AuditChange{Before: float64(1), After: float64(3)}, changes["weights.v"])
+	assert.Equal(t, AuditChange{Before: nil, After: float64(1)}, changes["new"])
+	assert.Contains(t, changes, "tags")
+
+	// 沒有變化
+	diff, err = DiffJSON(json.RawMessage(`{"a": 1}`), json.RawMessage(`{"a":1}`))
+	require.NoError(t, err)
+	assert.Nil(t, diff)
+}
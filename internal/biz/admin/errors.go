@@ -0,0 +1,35 @@
+package admin
+
+import "errors"
+
+// 管理員帳號和角色相關錯誤，管理後台據此返回 400、401、403、404 或 409
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrAdminDisabled      = errors.New("admin account is disabled")
+	ErrAdminNotFound      = errors.New("admin not found")
+	ErrAdminExists        = errors.New("admin username already exists")
+	ErrInvalidAdmin       = errors.New("invalid admin account")
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleExists         = errors.New("role already exists")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrRoleImmutable      = errors.New("role cannot be modified")
+	ErrRoleInUse          = errors.New("role is assigned to admins")
+	ErrLastSuperAdmin     = errors.New("cannot remove the last active super admin")
+	ErrSelfModification   = errors.New("admins cannot change their own role or status")
+	ErrIPNotAllowed       = errors.New("ip address is not allowed for this role")
+	ErrSelfLockout        = errors.New("allow-list would lock out the current admin")
+)
+
+// 兩步驗證相關錯誤
+var (
+	ErrOTPRequired       = errors.New("two-factor code required")
+	ErrInvalidOTP        = errors.New("invalid two-factor code")
+	ErrOTPNotEnrolled    = errors.New("two-factor authentication is not enabled")
+	ErrOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrOTPLocked         = errors.New("two-factor verification is temporarily locked")
+)
+
+// 審計日誌相關錯誤
+var (
+	ErrInvalidAuditFilter = errors.New("invalid audit log filter")
+)
@@ -0,0 +1,274 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ========================================
+// 管理員兩步驗證
+// ========================================
+//
+// 管理員先申請密鑰（EnrollOTP），用驗證器掃碼後提交一次驗證碼確認（ConfirmOTP），
+// 確認後啟用兩步驗證並返回一組一次性恢復碼。啟用後登入必須提供驗證碼或恢復碼，
+// 充值、扣款、修改 RTP 等敏感操作要求令牌在最近一段時間內通過過兩步驗證（StepUp）。
+// 驗證碼和恢復碼共用失敗計數，連續錯誤 otpMaxFailures 次後鎖定 otpLockoutDuration。
+
+const (
+	otpMaxFailures     = 5
+	otpLockoutDuration = 15 * time.Minute
+)
+
+// OTPState 管理員的兩步驗證密鑰和狀態
+type OTPState struct {
+	Secret   string // base32 密鑰，申請後未確認時 Enabled 為 false
+	Enabled  bool
+	LastStep int64 // 最近一次使用的驗證碼步長，用於防止重放
+
+	FailedAttempts int        // 連續驗證失敗次數，成功或鎖定後清零
+	LockedUntil    *time.Time // 驗證鎖定的截止時間
+}
+
+// OTPEnrollment 兩步驗證申請結果，密鑰只在申請時返回一次
+type OTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// 地址，用於生成二維碼
+}
+
+// OTPStatus 管理員的兩步驗證狀態
+type OTPStatus struct {
+	Enabled                bool `json:"enabled"`
+	Pending                bool `json:"pending"` // 已申請密鑰但尚未確認
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// GetOTPStatus 查詢管理員的兩步驗證狀態
+func (uc *AdminUsecase) GetOTPStatus(ctx context.Context, adminID int64) (*OTPStatus, error) {
+	state, err := uc.repo.GetAdminOTP(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	status := &OTPStatus{Enabled: state.Enabled, Pending: !state.Enabled && state.Secret != ""}
+	if state.Enabled {
+		if status.RecoveryCodesRemaining, err = uc.repo.CountRecoveryCodes(ctx, adminID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// EnrollOTP 為管理員生成新的兩步驗證密鑰，確認前不生效，重複申請會替換未確認的密鑰
+func (uc *AdminUsecase) EnrollOTP(ctx context.Context, principal *Principal) (*OTPEnrollment, error) {
+	state, err := uc.repo.GetAdminOTP(ctx, principal.Admin.ID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, ErrOTPAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.SetAdminOTP(ctx, principal.Admin.ID, secret, false); err != nil {
+		return nil, err
+	}
+	return &OTPEnrollment{Secret: secret, URI: TOTPURI(principal.Admin.Username, secret)}, nil
+}
+
+// ConfirmOTP 用驗證器生成的驗證碼確認密鑰並啟用兩步驗證，返回恢復碼明文（只返回這一次）
+func (uc *AdminUsecase) ConfirmOTP(ctx context.Context, principal *Principal, code string) ([]string, error) {
+	id := principal.Admin.ID
+	state, err := uc.repo.GetAdminOTP(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, ErrOTPAlreadyEnabled
+	}
+	if state.Secret == "" {
+		return nil, fmt.Errorf("%w: enroll first", ErrOTPNotEnrolled)
+	}
+	if err := uc.guardOTP(ctx, id, state, func() error {
+		return uc.verifyTOTPCode(ctx, id, state, code)
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.SetAdminOTP(ctx, id, state.Secret, true); err != nil {
+		return nil, err
+	}
+	codes, err := uc.replaceRecoveryCodes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Infof("Admin %d (%s) enabled two-factor authentication", id, principal.Admin.Username)
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes 驗證驗證碼後生成一組新的恢復碼，舊的恢復碼全部失效
+func (uc *AdminUsecase) RegenerateRecoveryCodes(ctx context.Context, principal *Principal, code string) ([]string, error) {
+	id := principal.Admin.ID
+	state, err := uc.enabledOTP(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.guardOTP(ctx, id, state, func() error {
+		return uc.verifyTOTPCode(ctx, id, state, code)
+	}); err != nil {
+		return nil, err
+	}
+	codes, err := uc.replaceRecoveryCodes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Infof("Admin %d regenerated two-factor recovery codes", id)
+	return codes, nil
+}
+
+// DisableOTP 驗證驗證碼或恢復碼後關閉自己的兩步驗證
+func (uc *AdminUsecase) DisableOTP(ctx context.Context, principal *Principal, otpCode, recoveryCode string) error {
+	id := principal.Admin.ID
+	if err := uc.verifySecondFactor(ctx, id, otpCode, recoveryCode); err != nil {
+		return err
+	}
+	if err := uc.repo.SetAdminOTP(ctx, id, "", false); err != nil {
+		return err
+	}
+	uc.logger.Infof("Admin %d (%s) disabled two-factor authentication", id, principal.Admin.Username)
+	return nil
+}
+
+// ResetOTP 清除其他管理員的兩步驗證（例如丟失設備且恢復碼用完），對方需要重新申請
+func (uc *AdminUsecase) ResetOTP(ctx context.Context, actor *Principal, id int64) (*AdminUser, error) {
+	if actor.Admin.ID == id {
+		return nil, ErrSelfModification
+	}
+	admin, err := uc.repo.GetAdmin(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.SetAdminOTP(ctx, id, "", false); err != nil {
+		return nil, err
+	}
+	admin.OTPEnabled = false
+	uc.logger.Warnf("Two-factor authentication of admin %d (%s) reset by admin %d", id, admin.Username, actor.Admin.ID)
+	return admin, nil
+}
+
+// StepUp 驗證驗證碼或恢復碼，簽發記錄了驗證時間的新令牌，用於敏感操作
+func (uc *AdminUsecase) StepUp(ctx context.Context, principal *Principal, otpCode, recoveryCode string, ttl time.Duration) (*LoginResult, error) {
+	id := principal.Admin.ID
+	if err := uc.verifySecondFactor(ctx, id, otpCode, recoveryCode); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	token, err := uc.issueToken(id, ttl, &now)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Infof("Admin %d (%s) passed step-up verification", id, principal.Admin.Username)
+	return &LoginResult{
+		Token:     token,
+		ExpiresIn: int64(ttl.Seconds()),
+		Admin:     principal.Admin,
+		Role:      principal.Role,
+		StepUpAt:  &now,
+	}, nil
+}
+
+// verifySecondFactor 校驗驗證碼或恢復碼（優先驗證碼），都未提供時返回 ErrOTPRequired
+func (uc *AdminUsecase) verifySecondFactor(ctx context.Context, id int64, otpCode, recoveryCode string) error {
+	state, err := uc.enabledOTP(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return uc.guardOTP(ctx, id, state, func() error {
+		switch {
+		case otpCode != "":
+			return uc.verifyTOTPCode(ctx, id, state, otpCode)
+		case recoveryCode != "":
+			used, err := uc.repo.UseRecoveryCode(ctx, id, hashRecoveryCode(recoveryCode))
+			if err != nil {
+				return err
+			}
+			if !used {
+				return ErrInvalidOTP
+			}
+			uc.logger.Warnf("Admin %d used a two-factor recovery code", id)
+			return nil
+		default:
+			return ErrOTPRequired
+		}
+	})
+}
+
+// guardOTP 在失敗計數的保護下執行驗證：鎖定期間直接拒絕，驗證失敗累計次數並在達到上限時鎖定，成功後清零
+func (uc *AdminUsecase) guardOTP(ctx context.Context, id int64, state *OTPState, verify func() error) error {
+	now := time.Now()
+	if state.LockedUntil != nil && now.Before(*state.LockedUntil) {
+		return fmt.Errorf("%w until %s", ErrOTPLocked, state.LockedUntil.UTC().Format(time.RFC3339))
+	}
+
+	err := verify()
+	switch {
+	case errors.Is(err, ErrInvalidOTP):
+		lockedUntil, recordErr := uc.repo.RecordOTPFailure(ctx, id, otpMaxFailures, otpLockoutDuration)
+		if recordErr != nil {
+			return recordErr
+		}
+		if lockedUntil != nil && lockedUntil.After(now) {
+			uc.logger.Warnf("Two-factor verification of admin %d locked until %s after %d failed attempts", id, lockedUntil.UTC().Format(time.RFC3339), otpMaxFailures)
+			return fmt.Errorf("%w until %s", ErrOTPLocked, lockedUntil.UTC().Format(time.RFC3339))
+		}
+		return err
+	case err == nil && state.FailedAttempts > 0:
+		return uc.repo.ResetOTPFailures(ctx, id)
+	}
+	return err
+}
+
+// verifyTOTPCode 校驗驗證碼並記錄使用的步長，同一個驗證碼不能使用兩次
+func (uc *AdminUsecase) verifyTOTPCode(ctx context.Context, id int64, state *OTPState, code string) error {
+	step, ok := verifyTOTP(state.Secret, code, time.Now())
+	if !ok || step <= state.LastStep {
+		return ErrInvalidOTP
+	}
+	fresh, err := uc.repo.UseOTPStep(ctx, id, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidOTP
+	}
+	return nil
+}
+
+// enabledOTP 讀取已啟用的兩步驗證，未啟用時返回 ErrOTPNotEnrolled
+func (uc *AdminUsecase) enabledOTP(ctx context.Context, id int64) (*OTPState, error) {
+	state, err := uc.repo.GetAdminOTP(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Enabled {
+		return nil, ErrOTPNotEnrolled
+	}
+	return state, nil
+}
+
+// replaceRecoveryCodes 生成並保存一組新的恢復碼
+func (uc *AdminUsecase) replaceRecoveryCodes(ctx context.Context, id int64) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.ReplaceRecoveryCodes(ctx, id, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,256 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 附錄 B 的 SHA1 測試向量（取後 6 位）
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range cases {
+		code, err := TOTPCode(secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+
+	// 允許前後一個步長的時鐘偏差
+	now := time.Unix(1111111109, 0)
+	_, ok := verifyTOTP(secret, "081804", now.Add(totpPeriod*time.Second))
+	assert.True(t, ok)
+	_, ok = verifyTOTP(secret, "081804", now.Add(3*totpPeriod*time.Second))
+	assert.False(t, ok)
+	_, ok = verifyTOTP("not base32!", "081804", now)
+	assert.False(t, ok)
+
+	uri := TOTPURI("root", secret)
+	assert.Contains(t, uri, "otpauth://totp/")
+	assert.Contains(t, uri, "secret="+secret)
+}
+
+// enrollTestOTP 為管理員綁定兩步驗證，返回密鑰和恢復碼
+func enrollTestOTP(t *testing.T, uc *AdminUsecase, principal *Principal) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	enrollment, err := uc.EnrollOTP(ctx, principal)
+	require.NoError(t, err)
+	code, err := TOTPCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	codes, err := uc.ConfirmOTP(ctx, principal, code)
+	require.NoError(t, err)
+	return enrollment.Secret, codes
+}
+
+func TestOTPEnrollmentAndLogin(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, root := newTestAdminUsecase(t)
+
+	// 未確認的密鑰不生效
+	enrollment, err := uc.EnrollOTP(ctx, root)
+	require.NoError(t, err)
+	status, err := uc.GetOTPStatus(ctx, root.Admin.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Pending)
+	result, err := uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password"}, time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, result.StepUpAt)
+
+	_, err = uc.ConfirmOTP(ctx, root, "000000")
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+	code, err := TOTPCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := uc.ConfirmOTP(ctx, root, code)
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+	_, err = uc.EnrollOTP(ctx, root)
+	assert.ErrorIs(t, err, ErrOTPAlreadyEnabled)
+
+	status, err = uc.GetOTPStatus(ctx, root.Admin.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, recoveryCodeCount, status.RecoveryCodesRemaining)
+
+	// 啟用後登入必須提供驗證碼
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password"}, time.Hour)
+	assert.ErrorIs(t, err, ErrOTPRequired)
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", OTPCode: "000000"}, time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+
+	// 確認時用過的驗證碼不能再用於登入，下一個步長的驗證碼可以
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", OTPCode: code}, time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+	next, err := TOTPCode(enrollment.Secret, time.Now().Add(totpPeriod*time.Second))
+	require.NoError(t, err)
+	result, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", OTPCode: next}, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, result.StepUpAt)
+	assert.Equal(t, "admin-step-up-token-1", result.Token)
+	assert.True(t, repo.admins[1].OTPEnabled)
+
+	// 恢復碼只能使用一次，格式不區分大小寫和連字符
+	recovery := recoveryCodes[0]
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", RecoveryCode: " " + recovery + " "}, time.Hour)
+	require.NoError(t, err)
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", RecoveryCode: recovery}, time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+	status, err = uc.GetOTPStatus(ctx, root.Admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+}
+
+func TestOTPStepUpAndRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	uc, _, root := newTestAdminUsecase(t)
+
+	_, err := uc.StepUp(ctx, root, "123456", "", time.Hour)
+	assert.ErrorIs(t, err, ErrOTPNotEnrolled)
+
+	secret, recoveryCodes := enrollTestOTP(t, uc, root)
+	root, err = uc.Authenticate(ctx, root.Admin.ID, "")
+	require.NoError(t, err)
+	assert.False(t, root.SteppedUp(time.Now(), time.Minute))
+
+	_, err = uc.StepUp(ctx, root, "", "", time.Hour)
+	assert.ErrorIs(t, err, ErrOTPRequired)
+	result, err := uc.StepUp(ctx, root, "", recoveryCodes[1], time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, result.StepUpAt)
+
+	// 通過驗證的時間在窗口內才允許敏感操作
+	root.StepUpAt = result.StepUpAt
+	assert.True(t, root.SteppedUp(time.Now(), time.Minute))
+	assert.False(t, root.SteppedUp(time.Now().Add(2*time.Minute), time.Minute))
+
+	// 重新生成恢復碼後舊的恢復碼失效
+	code, err := TOTPCode(secret, time.Now().Add(totpPeriod*time.Second))
+	require.NoError(t, err)
+	fresh, err := uc.RegenerateRecoveryCodes(ctx, root, code)
+	require.NoError(t, err)
+	_, err = uc.StepUp(ctx, root, "", recoveryCodes[2], time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+
+	// 關閉後登入不再需要驗證碼
+	require.NoError(t, uc.DisableOTP(ctx, root, "", fresh[0]))
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password"}, time.Hour)
+	require.NoError(t, err)
+}
+
+func TestResetOTP(t *testing.T) {
+	ctx := context.Background()
+	uc, _, root := newTestAdminUsecase(t)
+
+	finance, err := uc.CreateAdmin(ctx, root, "finance1", "finance-password", RoleFinance)
+	require.NoError(t, err)
+	principal, err := uc.Authenticate(ctx, finance.ID, "")
+	require.NoError(t, err)
+	enrollTestOTP(t, uc, principal)
+
+	_, err = uc.ResetOTP(ctx, principal, finance.ID)
+	assert.ErrorIs(t, err, ErrSelfModification)
+	reset, err := uc.ResetOTP(ctx, root, finance.ID)
+	require.NoError(t, err)
+	assert.False(t, reset.OTPEnabled)
+
+	status, err := uc.GetOTPStatus(ctx, finance.ID)
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.Zero(t, status.RecoveryCodesRemaining)
+	_, err = uc.Login(ctx, LoginRequest{Username: "finance1", Password: "finance-password"}, time.Hour)
+	require.NoError(t, err)
+}
+
+func TestRoleAllowedIPs(t *testing.T) {
+	ctx := context.Background()
+	uc, _, root := newTestAdminUsecase(t)
+
+	role := &Role{Name: "night_shift", Permissions: []Permission{PermPlayerRead}, AllowedIPs: []string{"10.0.0.0/8", " 192.0.2.7 ", "10.0.0.0/8"}}
+	require.NoError(t, uc.CreateRole(ctx, root, role))
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.7"}, role.AllowedIPs)
+	assert.True(t, role.AllowsIP("10.1.2.3"))
+	assert.True(t, role.AllowsIP("192.0.2.7"))
+	assert.False(t, role.AllowsIP("192.0.2.8"))
+	assert.False(t, role.AllowsIP(""))
+
+	err := uc.CreateRole(ctx, root, &Role{Name: "bad_ips", AllowedIPs: []string{"10.0.0.0/33", "example.com"}})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	admin, err := uc.CreateAdmin(ctx, root, "nightly", "night-password", "night_shift")
+	require.NoError(t, err)
+	_, err = uc.Login(ctx, LoginRequest{Username: "nightly", Password: "night-password", ClientIP: "203.0.113.5"}, time.Hour)
+	assert.ErrorIs(t, err, ErrIPNotAllowed)
+	_, err = uc.Login(ctx, LoginRequest{Username: "nightly", Password: "night-password", ClientIP: "10.9.8.7"}, time.Hour)
+	require.NoError(t, err)
+	_, err = uc.Authenticate(ctx, admin.ID, "203.0.113.5")
+	assert.ErrorIs(t, err, ErrIPNotAllowed)
+
+	// 修改描述和權限不影響 IP 白名單
+	require.NoError(t, uc.UpdateRole(ctx, root, &Role{Name: "night_shift", Permissions: []Permission{PermPlayerRead, PermWalletRead}}))
+	_, err = uc.Authenticate(ctx, admin.ID, "203.0.113.5")
+	assert.ErrorIs(t, err, ErrIPNotAllowed)
+
+	// super_admin 也可以設置白名單，但不能把當前管理員鎖在外面
+	_, err = uc.SetRoleAllowedIPs(ctx, root, RoleSuperAdmin, []string{"10.0.0.0/8"}, "203.0.113.5")
+	assert.ErrorIs(t, err, ErrSelfLockout)
+	updated, err := uc.SetRoleAllowedIPs(ctx, root, RoleSuperAdmin, []string{"10.0.0.0/8"}, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, updated.AllowedIPs)
+	_, err = uc.Authenticate(ctx, root.Admin.ID, "203.0.113.5")
+	assert.ErrorIs(t, err, ErrIPNotAllowed)
+
+	updated, err = uc.SetRoleAllowedIPs(ctx, root, "night_shift", nil, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Empty(t, updated.AllowedIPs)
+	_, err = uc.Authenticate(ctx, admin.ID, "203.0.113.5")
+	require.NoError(t, err)
+}
+
+func TestOTPLockoutAfterRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, root := newTestAdminUsecase(t)
+	secret, recoveryCodes := enrollTestOTP(t, uc, root)
+
+	// 驗證碼和恢復碼共用失敗計數，成功後清零
+	for i := 0; i < otpMaxFailures-1; i++ {
+		_, err := uc.StepUp(ctx, root, "", "not-a-recovery-code", time.Hour)
+		assert.ErrorIs(t, err, ErrInvalidOTP)
+	}
+	_, err := uc.StepUp(ctx, root, "", recoveryCodes[0], time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, repo.otp[root.Admin.ID].FailedAttempts)
+
+	for i := 0; i < otpMaxFailures-1; i++ {
+		_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", OTPCode: "000000"}, time.Hour)
+		assert.ErrorIs(t, err, ErrInvalidOTP)
+	}
+	_, err = uc.StepUp(ctx, root, "", "not-a-recovery-code", time.Hour)
+	assert.ErrorIs(t, err, ErrOTPLocked)
+
+	// 鎖定期間正確的驗證碼和恢復碼也被拒絕，且不會消耗恢復碼
+	code, err := TOTPCode(secret, time.Now().Add(totpPeriod*time.Second))
+	require.NoError(t, err)
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", OTPCode: code}, time.Hour)
+	assert.ErrorIs(t, err, ErrOTPLocked)
+	_, err = uc.RegenerateRecoveryCodes(ctx, root, code)
+	assert.ErrorIs(t, err, ErrOTPLocked)
+	err = uc.DisableOTP(ctx, root, "", recoveryCodes[1])
+	assert.ErrorIs(t, err, ErrOTPLocked)
+	status, err := uc.GetOTPStatus(ctx, root.Admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+
+	// 鎖定過期後恢復
+	expired := time.Now().Add(-time.Second)
+	repo.otp[root.Admin.ID].LockedUntil = &expired
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password", OTPCode: code}, time.Hour)
+	require.NoError(t, err)
+}
@@ -0,0 +1,278 @@
+package admin
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ========================================
+// 管理後台角色和權限
+// ========================================
+//
+// 管理員帳號與玩家帳號分開保存（admin_users），每個管理員屬於一個角色（admin_roles），
+// 角色持有一組權限，管理後台的每條路由聲明它需要的權限。
+// 內置角色在 000014 遷移中創建：super_admin 擁有所有權限且不能修改或刪除，
+// 其他內置角色可以調整權限但不能刪除；自定義角色在沒有管理員使用時可以刪除。
+
+// Permission 管理後台權限，格式為 "資源:操作"
+type Permission string
+
+const (
+	PermSystemRead        Permission = "system:read"        // 伺服器狀態、指標、環境信息和集群節點
+	PermClusterManage     Permission = "cluster:manage"     // 節點下線
+	PermPlayerRead        Permission = "player:read"        // 查詢玩家和玩家錢包
+	PermPlayerWrite       Permission = "player:write"       // 創建、修改和刪除玩家
+	PermPlayerBan         Permission = "player:ban"         // 封禁和解封玩家
+	PermWalletRead        Permission = "wallet:read"        // 查詢錢包和交易記錄
+	PermWalletFreeze      Permission = "wallet:freeze"      // 凍結和解凍錢包
+	PermWalletAdjust      Permission = "wallet:adjust"      // 充值和扣款
+	PermRoomRead          Permission = "room:read"          // 房間狀態歷史、房間配置和活動排程
+	PermRoomSpectate      Permission = "room:spectate"      // 簽發客服觀戰令牌
+	PermRoomConfigure     Permission = "room:configure"     // 修改房間配置和活動排程
+	PermFormationRead     Permission = "formation:read"     // 查詢陣型配置和統計
+	PermFormationWrite    Permission = "formation:write"    // 修改陣型配置和觸發特殊陣型
+	PermFishTideRead      Permission = "fish_tide:read"     // 查詢魚潮配置
+	PermFishTideWrite     Permission = "fish_tide:write"    // 修改、啟動和停止魚潮
+	PermAnnouncementWrite Permission = "announcement:write" // 發布、修改和刪除公告
+	PermAdminManage       Permission = "admin:manage"       // 管理管理員帳號和角色
+	PermAuditRead         Permission = "audit:read"         // 查詢、導出和校驗審計日誌
+)
+
+// allPermissions 所有權限，按資源分組排列
+var allPermissions = []Permission{
+	PermSystemRead, PermClusterManage,
+	PermPlayerRead, PermPlayerWrite, PermPlayerBan,
+	PermWalletRead, PermWalletFreeze, PermWalletAdjust,
+	PermRoomRead, PermRoomSpectate, PermRoomConfigure,
+	PermFormationRead, PermFormationWrite,
+	PermFishTideRead, PermFishTideWrite,
+	PermAnnouncementWrite,
+	PermAdminManage,
+	PermAuditRead,
+}
+
+// AllPermissions 所有權限
+func AllPermissions() []Permission {
+	return append([]Permission(nil), allPermissions...)
+}
+
+// knownPermission 是否為已定義的權限
+func knownPermission(p Permission) bool {
+	for _, known := range allPermissions {
+		if known == p {
+			return true
+		}
+	}
+	return false
+}
+
+// 內置角色
+const (
+	RoleSuperAdmin = "super_admin"
+	RoleOperator   = "operator"
+	RoleFinance    = "finance"
+	RoleSupport    = "support"
+	RoleReadOnly   = "read_only"
+)
+
+// BuiltinRoles 內置角色及其默認權限，與 000014、000015 遷移的初始數據一致
+func BuiltinRoles() []*Role {
+	return []*Role{
+		{
+			Name:        RoleSuperAdmin,
+			Description: "所有權限，包括管理員帳號和角色管理",
+			Permissions: AllPermissions(),
+			BuiltIn:     true,
+		},
+		{
+			Name:        RoleOperator,
+			Description: "遊戲運營：房間、陣型、魚潮、公告和集群節點",
+			Permissions: []Permission{
+				PermSystemRead, PermClusterManage,
+				PermPlayerRead, PermWalletRead,
+				PermRoomRead, PermRoomSpectate, PermRoomConfigure,
+				PermFormationRead, PermFormationWrite,
+				PermFishTideRead, PermFishTideWrite,
+				PermAnnouncementWrite,
+			},
+			BuiltIn: true,
+		},
+		{
+			Name:        RoleFinance,
+			Description: "財務：錢包充值、扣款和凍結",
+			Permissions: []Permission{
+				PermPlayerRead,
+				PermWalletRead, PermWalletFreeze, PermWalletAdjust,
+			},
+			BuiltIn: true,
+		},
+		{
+			Name:        RoleSupport,
+			Description: "客服：查詢玩家、封禁玩家和觀戰",
+			Permissions: []Permission{
+				PermPlayerRead, PermPlayerBan,
+				PermWalletRead,
+				PermRoomRead, PermRoomSpectate,
+			},
+			BuiltIn: true,
+		},
+		{
+			Name:        RoleReadOnly,
+			Description: "只讀：查看所有數據，不能做任何修改",
+			Permissions: []Permission{
+				PermSystemRead,
+				PermPlayerRead, PermWalletRead,
+				PermRoomRead,
+				PermFormationRead, PermFishTideRead,
+			},
+			BuiltIn: true,
+		},
+	}
+}
+
+// roleNamePattern 角色名只允許小寫字母、數字和下劃線
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// Role 管理員角色
+type Role struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	AllowedIPs  []string     `json:"allowed_ips"` // 允許登入和訪問的 IP 或 CIDR，為空時不限制
+	BuiltIn     bool         `json:"built_in"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Has 角色是否擁有權限
+func (r *Role) Has(p Permission) bool {
+	for _, granted := range r.Permissions {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP 角色是否允許從 ip 登入和訪問，沒有配置 IP 白名單時允許所有地址
+func (r *Role) AllowsIP(ip string) bool {
+	if len(r.AllowedIPs) == 0 {
+		return true
+	}
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if addr == nil {
+		return false
+	}
+	for _, entry := range r.AllowedIPs {
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize 去掉重複的權限並排序，讓保存的權限列表穩定
+func (r *Role) normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Description = strings.TrimSpace(r.Description)
+	seen := make(map[Permission]bool, len(r.Permissions))
+	permissions := make([]Permission, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+	r.Permissions = permissions
+	r.AllowedIPs = normalizeAllowedIPs(r.AllowedIPs)
+}
+
+// normalizeAllowedIPs 去掉空白和重複的 IP 白名單條目並排序
+func normalizeAllowedIPs(entries []string) []string {
+	seen := make(map[string]bool, len(entries))
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry != "" && !seen[entry] {
+			seen[entry] = true
+			result = append(result, entry)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// validateAllowedIPs 檢查 IP 白名單條目都是合法的 IP 或 CIDR
+func validateAllowedIPs(entries []string) []string {
+	var problems []string
+	for _, entry := range entries {
+		var err error
+		if strings.Contains(entry, "/") {
+			_, _, err = net.ParseCIDR(entry)
+		} else if net.ParseIP(entry) == nil {
+			err = fmt.Errorf("not an ip")
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid allowed ip %q", entry))
+		}
+	}
+	return problems
+}
+
+// ValidateRole 檢查角色名和權限，返回的錯誤包含所有不合法的字段
+func ValidateRole(role *Role) error {
+	var problems []string
+	if !roleNamePattern.MatchString(role.Name) {
+		problems = append(problems, "name must be 2-50 lowercase letters, digits or underscores and start with a letter")
+	}
+	for _, p := range role.Permissions {
+		if !knownPermission(p) {
+			problems = append(problems, fmt.Sprintf("unknown permission %q", p))
+		}
+	}
+	problems = append(problems, validateAllowedIPs(role.AllowedIPs)...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidRole, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// AdminUser 管理員帳號，與玩家帳號（users/players）相互獨立
+type AdminUser struct {
+	ID          int64      `json:"id"`
+	Username    string     `json:"username"`
+	Role        string     `json:"role"`
+	Active      bool       `json:"active"`
+	OTPEnabled  bool       `json:"otp_enabled"` // 是否已啟用兩步驗證
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Principal 已認證的管理員及其當前角色的權限
+type Principal struct {
+	Admin    *AdminUser `json:"admin"`
+	Role     *Role      `json:"role"`
+	StepUpAt *time.Time `json:"step_up_at,omitempty"` // 令牌最近一次通過兩步驗證的時間
+}
+
+// SteppedUp 管理員是否在 window 內通過了兩步驗證，敏感操作據此要求重新驗證
+func (p *Principal) SteppedUp(now time.Time, window time.Duration) bool {
+	return p != nil && p.Admin != nil && p.Admin.OTPEnabled &&
+		p.StepUpAt != nil && now.Sub(*p.StepUpAt) <= window
+}
+
+// Can 管理員是否擁有權限
+func (p *Principal) Can(perm Permission) bool {
+	return p != nil && p.Role != nil && p.Role.Has(perm)
+}
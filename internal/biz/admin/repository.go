@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"context"
+	"time"
+)
+
+// AdminRepo 管理員帳號和角色的持久化（admin_users、admin_roles）
+type AdminRepo interface {
+	// GetAdmin 根據 ID 獲取管理員，不存在時返回 ErrAdminNotFound
+	GetAdmin(ctx context.Context, id int64) (*AdminUser, error)
+	// GetAdminByUsername 根據帳號名獲取管理員和密碼雜湊，不存在時返回 ErrAdminNotFound
+	GetAdminByUsername(ctx context.Context, username string) (*AdminUser, string, error)
+	// ListAdmins 所有管理員
+	ListAdmins(ctx context.Context) ([]*AdminUser, error)
+	// CreateAdmin 創建管理員並寫回 ID 和時間戳，帳號名已存在時返回 ErrAdminExists
+	CreateAdmin(ctx context.Context, admin *AdminUser, passwordHash string) error
+	// UpdateAdmin 更新管理員的角色和啟用狀態
+	UpdateAdmin(ctx context.Context, admin *AdminUser) error
+	// UpdateAdminPassword 更新管理員密碼雜湊
+	UpdateAdminPassword(ctx context.Context, id int64, passwordHash string) error
+	// RecordAdminLogin 記錄最後登入時間
+	RecordAdminLogin(ctx context.Context, id int64) error
+	// CountActiveAdmins 擁有角色的啟用管理員數量
+	CountActiveAdmins(ctx context.Context, role string) (int, error)
+	// CountAdmins 所有管理員數量
+	CountAdmins(ctx context.Context) (int, error)
+
+	// GetAdminOTP 讀取管理員的兩步驗證密鑰和狀態，不存在時返回 ErrAdminNotFound
+	GetAdminOTP(ctx context.Context, id int64) (*OTPState, error)
+	// SetAdminOTP 保存兩步驗證密鑰和啟用狀態，secret 為空時清除密鑰、恢復碼和失敗鎖定
+	SetAdminOTP(ctx context.Context, id int64, secret string, enabled bool) error
+	// UseOTPStep 記錄已使用的驗證碼步長，步長不大於上一次使用的步長時返回 false（重放）
+	UseOTPStep(ctx context.Context, id int64, step int64) (bool, error)
+	// RecordOTPFailure 原子地累加驗證失敗次數，達到 limit 時鎖定 lockout 並清零計數，返回鎖定截止時間
+	RecordOTPFailure(ctx context.Context, id int64, limit int, lockout time.Duration) (*time.Time, error)
+	// ResetOTPFailures 清零驗證失敗次數
+	ResetOTPFailures(ctx context.Context, id int64) error
+	// ReplaceRecoveryCodes 用一組新的恢復碼雜湊替換管理員所有的恢復碼
+	ReplaceRecoveryCodes(ctx context.Context, id int64, hashes []string) error
+	// UseRecoveryCode 把未使用的恢復碼標記為已使用，沒有匹配的恢復碼時返回 false
+	UseRecoveryCode(ctx context.Context, id int64, hash string) (bool, error)
+	// CountRecoveryCodes 管理員剩餘未使用的恢復碼數量
+	CountRecoveryCodes(ctx context.Context, id int64) (int, error)
+
+	// GetRole 根據名稱獲取角色，不存在時返回 ErrRoleNotFound
+	GetRole(ctx context.Context, name string) (*Role, error)
+	// ListRoles 所有角色
+	ListRoles(ctx context.Context) ([]*Role, error)
+	// CreateRole 創建角色，名稱已存在時返回 ErrRoleExists
+	CreateRole(ctx context.Context, role *Role) error
+	// UpdateRole 更新角色的描述和權限
+	UpdateRole(ctx context.Context, role *Role) error
+	// UpdateRoleAllowedIPs 更新角色的 IP 白名單
+	UpdateRoleAllowedIPs(ctx context.Context, role *Role) error
+	// DeleteRole 刪除角色
+	DeleteRole(ctx context.Context, name string) error
+}
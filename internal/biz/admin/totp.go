@@ -0,0 +1,143 @@
+package admin
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ========================================
+// TOTP 兩步驗證（RFC 6238）
+// ========================================
+//
+// 使用 HMAC-SHA1、30 秒步長和 6 位數字，與 Google Authenticator、1Password 等驗證器兼容。
+// 驗證時允許前後各一個步長的時鐘偏差，同一步長的驗證碼只能使用一次。
+
+const (
+	totpPeriod     = 30 // 步長（秒）
+	totpDigits     = 6
+	totpSkew       = 1  // 允許的時鐘偏差（步長數）
+	totpSecretSize = 20 // 密鑰長度（字節），base32 編碼後為 32 個字符
+
+	// otpIssuer 驗證器中顯示的發行方
+	otpIssuer = "Fish Server Admin"
+
+	recoveryCodeCount  = 10 // 每次生成的恢復碼數量
+	recoveryCodeLength = 10 // 恢復碼字符數，顯示為 xxxxx-xxxxx
+)
+
+// totpEncoding 不帶填充的 base32，驗證器掃碼和手動輸入都使用這個格式
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret 生成隨機的 base32 密鑰
+func GenerateTOTPSecret() (string, error) {
+	key := make([]byte, totpSecretSize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate otp secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(key), nil
+}
+
+// TOTPCode 密鑰在 t 時刻的驗證碼
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCodeAt(key, totpStep(t)), nil
+}
+
+// TOTPURI 驗證器掃碼使用的 otpauth:// 地址
+func TOTPURI(account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", otpIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(otpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// verifyTOTP 校驗 t 時刻前後的驗證碼，返回匹配的步長
+func verifyTOTP(secret, code string, t time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+	current := totpStep(t)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if hmac.Equal([]byte(totpCodeAt(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpStep t 時刻所在的步長
+func totpStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// totpCodeAt 按 RFC 4226 動態截斷計算步長的驗證碼
+func totpCodeAt(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// decodeTOTPSecret 解碼 base32 密鑰，兼容小寫、空格和填充
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimRight(secret, "="), " ", ""))
+	key, err := totpEncoding.DecodeString(normalized)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid otp secret")
+	}
+	return key, nil
+}
+
+// generateRecoveryCodes 生成一組恢復碼，返回明文（只展示一次）和保存用的雜湊
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(raw))[:recoveryCodeLength]
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode 恢復碼的 SHA-256 雜湊，忽略大小寫、空格和連字符
+// 恢復碼是 50 位的隨機值，不需要 bcrypt 這類慢雜湊
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
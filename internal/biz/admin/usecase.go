@@ -0,0 +1,383 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength 管理員密碼最短長度
+const minPasswordLength = 8
+
+// TokenIssuer 簽發管理員令牌，管理員令牌不能用於玩家接口
+type TokenIssuer interface {
+	GenerateAdminToken(adminID int64, ttl time.Duration) (string, error)
+	// GenerateAdminStepUpToken 簽發記錄了兩步驗證時間的管理員令牌
+	GenerateAdminStepUpToken(adminID int64, ttl time.Duration, stepUpAt time.Time) (string, error)
+}
+
+// LoginRequest 管理員登入請求
+type LoginRequest struct {
+	Username     string
+	Password     string
+	OTPCode      string // 已啟用兩步驗證時必須提供驗證碼或恢復碼之一
+	RecoveryCode string
+	ClientIP     string // 按角色的 IP 白名單檢查
+}
+
+// LoginResult 管理員登入結果
+type LoginResult struct {
+	Token     string     `json:"token"`
+	ExpiresIn int64      `json:"expires_in"`
+	Admin     *AdminUser `json:"admin"`
+	Role      *Role      `json:"role"`
+	StepUpAt  *time.Time `json:"step_up_at,omitempty"` // 通過兩步驗證時為驗證時間
+}
+
+// AdminUpdate 管理員帳號的修改，nil 字段保持不變
+type AdminUpdate struct {
+	Role     *string
+	Active   *bool
+	Password *string
+}
+
+// AdminUsecase 管理員登入、權限解析以及帳號和角色管理
+type AdminUsecase struct {
+	repo   AdminRepo
+	tokens TokenIssuer
+	logger logger.Logger
+}
+
+// NewAdminUsecase 創建管理員用例
+func NewAdminUsecase(repo AdminRepo, tokens TokenIssuer, logger logger.Logger) *AdminUsecase {
+	return &AdminUsecase{
+		repo:   repo,
+		tokens: tokens,
+		logger: logger.With("component", "admin_usecase"),
+	}
+}
+
+// EnsureBootstrapAdmin 沒有任何管理員時創建初始超級管理員，返回是否創建
+func (uc *AdminUsecase) EnsureBootstrapAdmin(ctx context.Context, username, password string) (bool, error) {
+	count, err := uc.repo.CountAdmins(ctx)
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	admin := &AdminUser{Username: strings.TrimSpace(username), Role: RoleSuperAdmin, Active: true}
+	if err := uc.createAdmin(ctx, admin, password); err != nil {
+		return false, fmt.Errorf("failed to create bootstrap admin: %w", err)
+	}
+	uc.logger.Warnf("Created bootstrap super admin %q, change its password after the first login", admin.Username)
+	return true, nil
+}
+
+// Login 驗證帳號密碼、IP 白名單和兩步驗證後簽發有效期為 ttl 的管理員令牌
+// 通過兩步驗證的登入同時完成一次敏感操作驗證
+func (uc *AdminUsecase) Login(ctx context.Context, req LoginRequest, ttl time.Duration) (*LoginResult, error) {
+	admin, passwordHash, err := uc.repo.GetAdminByUsername(ctx, strings.TrimSpace(req.Username))
+	if errors.Is(err, ErrAdminNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !admin.Active {
+		return nil, ErrAdminDisabled
+	}
+
+	role, err := uc.repo.GetRole(ctx, admin.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !role.AllowsIP(req.ClientIP) {
+		uc.logger.Warnf("Admin %d (%s) login rejected from %s by the allow-list of role %s", admin.ID, admin.Username, req.ClientIP, role.Name)
+		return nil, ErrIPNotAllowed
+	}
+
+	var stepUpAt *time.Time
+	if admin.OTPEnabled {
+		if err := uc.verifySecondFactor(ctx, admin.ID, req.OTPCode, req.RecoveryCode); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		stepUpAt = &now
+	}
+
+	token, err := uc.issueToken(admin.ID, ttl, stepUpAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.RecordAdminLogin(ctx, admin.ID); err != nil {
+		uc.logger.Warnf("Failed to record login of admin %d: %v", admin.ID, err)
+	}
+
+	uc.logger.Infof("Admin %d (%s) logged in with role %s", admin.ID, admin.Username, admin.Role)
+	return &LoginResult{
+		Token:     token,
+		ExpiresIn: int64(ttl.Seconds()),
+		Admin:     admin,
+		Role:      role,
+		StepUpAt:  stepUpAt,
+	}, nil
+}
+
+// issueToken 簽發管理員令牌，stepUpAt 不為空時令牌記錄兩步驗證時間
+func (uc *AdminUsecase) issueToken(adminID int64, ttl time.Duration, stepUpAt *time.Time) (string, error) {
+	var (
+		token string
+		err   error
+	)
+	if stepUpAt != nil {
+		token, err = uc.tokens.GenerateAdminStepUpToken(adminID, ttl, *stepUpAt)
+	} else {
+		token, err = uc.tokens.GenerateAdminToken(adminID, ttl)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to generate admin token: %w", err)
+	}
+	return token, nil
+}
+
+// Authenticate 解析令牌對應的管理員及其當前權限，每次請求都重新讀取，角色調整、停用和 IP 白名單立即生效
+func (uc *AdminUsecase) Authenticate(ctx context.Context, adminID int64, clientIP string) (*Principal, error) {
+	admin, err := uc.repo.GetAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin.Active {
+		return nil, ErrAdminDisabled
+	}
+	role, err := uc.repo.GetRole(ctx, admin.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !role.AllowsIP(clientIP) {
+		return nil, fmt.Errorf("%w: %s", ErrIPNotAllowed, clientIP)
+	}
+	return &Principal{Admin: admin, Role: role}, nil
+}
+
+// ========================================
+// 管理員帳號管理
+// ========================================
+
+// ListAdmins 所有管理員
+func (uc *AdminUsecase) ListAdmins(ctx context.Context) ([]*AdminUser, error) {
+	return uc.repo.ListAdmins(ctx)
+}
+
+// GetAdmin 查詢管理員
+func (uc *AdminUsecase) GetAdmin(ctx context.Context, id int64) (*AdminUser, error) {
+	return uc.repo.GetAdmin(ctx, id)
+}
+
+// CreateAdmin 創建管理員帳號
+func (uc *AdminUsecase) CreateAdmin(ctx context.Context, actor *Principal, username, password, role string) (*AdminUser, error) {
+	admin := &AdminUser{Username: strings.TrimSpace(username), Role: role, Active: true}
+	if err := uc.createAdmin(ctx, admin, password); err != nil {
+		return nil, err
+	}
+	uc.logger.Infof("Admin %d (%s) with role %s created by admin %d", admin.ID, admin.Username, admin.Role, actor.Admin.ID)
+	return admin, nil
+}
+
+// createAdmin 校驗帳號名、密碼和角色後創建管理員
+func (uc *AdminUsecase) createAdmin(ctx context.Context, admin *AdminUser, password string) error {
+	var problems []string
+	if len(admin.Username) < 3 || len(admin.Username) > 50 {
+		problems = append(problems, "username must be 3-50 characters")
+	}
+	if len(password) < minPasswordLength {
+		problems = append(problems, fmt.Sprintf("password must be at least %d characters", minPasswordLength))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidAdmin, strings.Join(problems, "; "))
+	}
+	if _, err := uc.repo.GetRole(ctx, admin.Role); err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	return uc.repo.CreateAdmin(ctx, admin, string(passwordHash))
+}
+
+// UpdateAdmin 修改管理員的角色、啟用狀態或密碼
+// 管理員不能修改自己的角色和狀態，也不能停用或降級最後一個啟用的超級管理員
+func (uc *AdminUsecase) UpdateAdmin(ctx context.Context, actor *Principal, id int64, update AdminUpdate) (*AdminUser, error) {
+	admin, err := uc.repo.GetAdmin(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roleChanged := update.Role != nil && *update.Role != admin.Role
+	activeChanged := update.Active != nil && *update.Active != admin.Active
+	if (roleChanged || activeChanged) && actor.Admin.ID == admin.ID {
+		return nil, ErrSelfModification
+	}
+	if roleChanged {
+		if _, err := uc.repo.GetRole(ctx, *update.Role); err != nil {
+			return nil, err
+		}
+	}
+	removesSuperAdmin := admin.Active && admin.Role == RoleSuperAdmin &&
+		(roleChanged || (activeChanged && !*update.Active))
+	if removesSuperAdmin {
+		if err := uc.ensureAnotherSuperAdmin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Password != nil {
+		if len(*update.Password) < minPasswordLength {
+			return nil, fmt.Errorf("%w: password must be at least %d characters", ErrInvalidAdmin, minPasswordLength)
+		}
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(*update.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		if err := uc.repo.UpdateAdminPassword(ctx, admin.ID, string(passwordHash)); err != nil {
+			return nil, err
+		}
+		uc.logger.Infof("Password of admin %d reset by admin %d", admin.ID, actor.Admin.ID)
+	}
+
+	if roleChanged || activeChanged {
+		if roleChanged {
+			admin.Role = *update.Role
+		}
+		if activeChanged {
+			admin.Active = *update.Active
+		}
+		if err := uc.repo.UpdateAdmin(ctx, admin); err != nil {
+			return nil, err
+		}
+		uc.logger.Infof("Admin %d (%s) updated by admin %d: role=%s, active=%t",
+			admin.ID, admin.Username, actor.Admin.ID, admin.Role, admin.Active)
+	}
+	return admin, nil
+}
+
+// ensureAnotherSuperAdmin 確認移除一個超級管理員後仍有其他啟用的超級管理員
+func (uc *AdminUsecase) ensureAnotherSuperAdmin(ctx context.Context) error {
+	count, err := uc.repo.CountActiveAdmins(ctx, RoleSuperAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return ErrLastSuperAdmin
+	}
+	return nil
+}
+
+// ========================================
+// 角色管理
+// ========================================
+
+// ListRoles 所有角色
+func (uc *AdminUsecase) ListRoles(ctx context.Context) ([]*Role, error) {
+	return uc.repo.ListRoles(ctx)
+}
+
+// GetRole 查詢角色
+func (uc *AdminUsecase) GetRole(ctx context.Context, name string) (*Role, error) {
+	return uc.repo.GetRole(ctx, name)
+}
+
+// CreateRole 校驗並創建自定義角色
+func (uc *AdminUsecase) CreateRole(ctx context.Context, actor *Principal, role *Role) error {
+	role.normalize()
+	role.BuiltIn = false
+	if err := ValidateRole(role); err != nil {
+		return err
+	}
+	if err := uc.repo.CreateRole(ctx, role); err != nil {
+		return err
+	}
+	uc.logger.Infof("Role %s created by admin %d with permissions %v", role.Name, actor.Admin.ID, role.Permissions)
+	return nil
+}
+
+// UpdateRole 修改角色的描述和權限，super_admin 不能修改
+func (uc *AdminUsecase) UpdateRole(ctx context.Context, actor *Principal, role *Role) error {
+	if role.Name == RoleSuperAdmin {
+		return fmt.Errorf("%w: %s", ErrRoleImmutable, role.Name)
+	}
+	existing, err := uc.repo.GetRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	role.AllowedIPs = existing.AllowedIPs
+	role.normalize()
+	role.BuiltIn = existing.BuiltIn
+	if err := ValidateRole(role); err != nil {
+		return err
+	}
+	if err := uc.repo.UpdateRole(ctx, role); err != nil {
+		return err
+	}
+	uc.logger.Infof("Role %s updated by admin %d with permissions %v", role.Name, actor.Admin.ID, role.Permissions)
+	return nil
+}
+
+// SetRoleAllowedIPs 修改角色的 IP 白名單，包括 super_admin，為空時不限制
+// 修改自己所屬的角色時，新的白名單必須包含當前請求的 IP，避免把自己鎖在外面
+func (uc *AdminUsecase) SetRoleAllowedIPs(ctx context.Context, actor *Principal, name string, allowedIPs []string, actorIP string) (*Role, error) {
+	role, err := uc.repo.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	updated := *role
+	updated.AllowedIPs = allowedIPs
+	updated.normalize()
+	if problems := validateAllowedIPs(updated.AllowedIPs); len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRole, strings.Join(problems, "; "))
+	}
+	if actor.Admin.Role == name && !updated.AllowsIP(actorIP) {
+		return nil, fmt.Errorf("%w: %s is not in the new allow-list", ErrSelfLockout, actorIP)
+	}
+	if err := uc.repo.UpdateRoleAllowedIPs(ctx, &updated); err != nil {
+		return nil, err
+	}
+	uc.logger.Infof("Allowed IPs of role %s set by admin %d: %v", name, actor.Admin.ID, updated.AllowedIPs)
+	return &updated, nil
+}
+
+// DeleteRole 刪除自定義角色，內置角色和仍有管理員使用的角色不能刪除
+func (uc *AdminUsecase) DeleteRole(ctx context.Context, actor *Principal, name string) error {
+	role, err := uc.repo.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return fmt.Errorf("%w: %s is built in", ErrRoleImmutable, name)
+	}
+	admins, err := uc.repo.ListAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		if admin.Role == name {
+			return fmt.Errorf("%w: %s", ErrRoleInUse, name)
+		}
+	}
+	if err := uc.repo.DeleteRole(ctx, name); err != nil {
+		return err
+	}
+	uc.logger.Infof("Role %s deleted by admin %d", name, actor.Admin.ID)
+	return nil
+}
@@ -0,0 +1,421 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeAdminRepo 記憶體中的管理員和角色存儲，初始包含所有內置角色
+type fakeAdminRepo struct {
+	admins    map[int64]*AdminUser
+	passwords map[int64]string
+	roles     map[string]*Role
+	nextID    int64
+
+	otp           map[int64]*OTPState
+	recoveryCodes map[int64]map[string]bool // 未使用的恢復碼雜湊
+}
+
+func newFakeAdminRepo() *fakeAdminRepo {
+	repo := &fakeAdminRepo{
+		admins:    make(map[int64]*AdminUser),
+		passwords: make(map[int64]string),
+		roles:     make(map[string]*Role),
+
+		otp:           make(map[int64]*OTPState),
+		recoveryCodes: make(map[int64]map[string]bool),
+	}
+	for _, role := range BuiltinRoles() {
+		repo.roles[role.Name] = role
+	}
+	return repo
+}
+
+func (r *fakeAdminRepo) GetAdmin(ctx context.Context, id int64) (*AdminUser, error) {
+	admin, ok := r.admins[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrAdminNotFound, id)
+	}
+	copied := *admin
+	return &copied, nil
+}
+
+func (r *fakeAdminRepo) GetAdminByUsername(ctx context.Context, username string) (*AdminUser, string, error) {
+	for id, admin := range r.admins {
+		if admin.Username == username {
+			copied := *admin
+			return &copied, r.passwords[id], nil
+		}
+	}
+	return nil, "", fmt.Errorf("%w: %s", ErrAdminNotFound, username)
+}
+
+func (r *fakeAdminRepo) ListAdmins(ctx context.Context) ([]*AdminUser, error) {
+	var admins []*AdminUser
+	for id := int64(1); id <= r.nextID; id++ {
+		if admin, ok := r.admins[id]; ok {
+			admins = append(admins, admin)
+		}
+	}
+	return admins, nil
+}
+
+func (r *fakeAdminRepo) CreateAdmin(ctx context.Context, admin *AdminUser, passwordHash string) error {
+	if _, _, err := r.GetAdminByUsername(ctx, admin.Username); err == nil {
+		return ErrAdminExists
+	}
+	r.nextID++
+	admin.ID = r.nextID
+	copied := *admin
+	r.admins[admin.ID] = &copied
+	r.passwords[admin.ID] = passwordHash
+	return nil
+}
+
+func (r *fakeAdminRepo) UpdateAdmin(ctx context.Context, admin *AdminUser) error {
+	copied := *admin
+	r.admins[admin.ID] = &copied
+	return nil
+}
+
+func (r *fakeAdminRepo) UpdateAdminPassword(ctx context.Context, id int64, passwordHash string) error {
+	r.passwords[id] = passwordHash
+	return nil
+}
+
+func (r *fakeAdminRepo) RecordAdminLogin(ctx context.Context, id int64) error {
+	now := time.Now()
+	r.admins[id].LastLoginAt = &now
+	return nil
+}
+
+func (r *fakeAdminRepo) CountActiveAdmins(ctx context.Context, role string) (int, error) {
+	count := 0
+	for _, admin := range r.admins {
+		if admin.Role == role && admin.Active {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeAdminRepo) CountAdmins(ctx context.Context) (int, error) {
+	return len(r.admins), nil
+}
+
+func (r *fakeAdminRepo) GetAdminOTP(ctx context.Context, id int64) (*OTPState, error) {
+	if _, ok := r.admins[id]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrAdminNotFound, id)
+	}
+	state := r.otp[id]
+	if state == nil {
+		return &OTPState{}, nil
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (r *fakeAdminRepo) SetAdminOTP(ctx context.Context, id int64, secret string, enabled bool) error {
+	state := r.otp[id]
+	if state == nil {
+		state = &OTPState{}
+		r.otp[id] = state
+	}
+	state.Secret, state.Enabled = secret, enabled
+	r.admins[id].OTPEnabled = enabled
+	if secret == "" {
+		delete(r.recoveryCodes, id)
+		state.FailedAttempts, state.LockedUntil = 0, nil
+	}
+	return nil
+}
+
+func (r *fakeAdminRepo) UseOTPStep(ctx context.Context, id int64, step int64) (bool, error) {
+	state := r.otp[id]
+	if state == nil || step <= state.LastStep {
+		return false, nil
+	}
+	state.LastStep = step
+	return true, nil
+}
+
+func (r *fakeAdminRepo) RecordOTPFailure(ctx context.Context, id int64, limit int, lockout time.Duration) (*time.Time, error) {
+	state := r.otp[id]
+	if state == nil {
+		state = &OTPState{}
+		r.otp[id] = state
+	}
+	state.FailedAttempts++
+	if state.FailedAttempts >= limit {
+		until := time.Now().Add(lockout)
+		state.FailedAttempts, state.LockedUntil = 0, &until
+	}
+	return state.LockedUntil, nil
+}
+
+func (r *fakeAdminRepo) ResetOTPFailures(ctx context.Context, id int64) error {
+	if state := r.otp[id]; state != nil {
+		state.FailedAttempts = 0
+	}
+	return nil
+}
+
+func (r *fakeAdminRepo) ReplaceRecoveryCodes(ctx context.Context, id int64, hashes []string) error {
+	codes := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		codes[hash] = true
+	}
+	r.recoveryCodes[id] = codes
+	return nil
+}
+
+func (r *fakeAdminRepo) UseRecoveryCode(ctx context.Context, id int64, hash string) (bool, error) {
+	if !r.recoveryCodes[id][hash] {
+		return false, nil
+	}
+	delete(r.recoveryCodes[id], hash)
+	return true, nil
+}
+
+func (r *fakeAdminRepo) CountRecoveryCodes(ctx context.Context, id int64) (int, error) {
+	return len(r.recoveryCodes[id]), nil
+}
+
+func (r *fakeAdminRepo) GetRole(ctx context.Context, name string) (*Role, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, name)
+	}
+	return role, nil
+}
+
+func (r *fakeAdminRepo) ListRoles(ctx context.Context) ([]*Role, error) {
+	var roles []*Role
+	for _, role := range r.roles {
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (r *fakeAdminRepo) CreateRole(ctx context.Context, role *Role) error {
+	if _, ok := r.roles[role.Name]; ok {
+		return ErrRoleExists
+	}
+	r.roles[role.Name] = role
+	return nil
+}
+
+func (r *fakeAdminRepo) UpdateRole(ctx context.Context, role *Role) error {
+	r.roles[role.Name] = role
+	return nil
+}
+
+func (r *fakeAdminRepo) UpdateRoleAllowedIPs(ctx context.Context, role *Role) error {
+	r.roles[role.Name] = role
+	return nil
+}
+
+func (r *fakeAdminRepo) DeleteRole(ctx context.Context, name string) error {
+	delete(r.roles, name)
+	return nil
+}
+
+// fakeTokenIssuer 記錄簽發的管理員令牌
+type fakeTokenIssuer struct {
+	issued []int64
+}
+
+func (f *fakeTokenIssuer) GenerateAdminToken(adminID int64, ttl time.Duration) (string, error) {
+	f.issued = append(f.issued, adminID)
+	return fmt.Sprintf("admin-token-%d", adminID), nil
+}
+
+func (f *fakeTokenIssuer) GenerateAdminStepUpToken(adminID int64, ttl time.Duration, stepUpAt time.Time) (string, error) {
+	f.issued = append(f.issued, adminID)
+	return fmt.Sprintf("admin-step-up-token-%d", adminID), nil
+}
+
+func newTestAdminUsecase(t *testing.T) (*AdminUsecase, *fakeAdminRepo, *Principal) {
+	t.Helper()
+	repo := newFakeAdminRepo()
+	uc := NewAdminUsecase(repo, &fakeTokenIssuer{}, logger.New(io.Discard, "info", "console"))
+
+	created, err := uc.EnsureBootstrapAdmin(context.Background(), "root", "root-password")
+	require.NoError(t, err)
+	require.True(t, created)
+	root, err := uc.Authenticate(context.Background(), 1, "")
+	require.NoError(t, err)
+	return uc, repo, root
+}
+
+func TestBuiltinRolePermissions(t *testing.T) {
+	roles := make(map[string]*Role)
+	for _, role := range BuiltinRoles() {
+		require.NoError(t, ValidateRole(role))
+		roles[role.Name] = role
+	}
+
+	for _, p := range AllPermissions() {
+		assert.True(t, roles[RoleSuperAdmin].Has(p), "super admin should have %s", p)
+	}
+	assert.True(t, roles[RoleFinance].Has(PermWalletAdjust))
+	assert.False(t, roles[RoleOperator].Has(PermWalletAdjust))
+	assert.True(t, roles[RoleOperator].Has(PermFormationWrite))
+	assert.True(t, roles[RoleOperator].Has(PermFishTideWrite))
+	assert.True(t, roles[RoleSupport].Has(PermPlayerBan))
+	assert.False(t, roles[RoleFinance].Has(PermPlayerBan))
+
+	// 只讀角色沒有任何寫權限
+	for _, p := range roles[RoleReadOnly].Permissions {
+		assert.Contains(t, string(p), ":read")
+	}
+	assert.False(t, roles[RoleOperator].Has(PermAdminManage))
+}
+
+func TestBootstrapAndLogin(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, root := newTestAdminUsecase(t)
+	assert.Equal(t, RoleSuperAdmin, root.Role.Name)
+
+	// 已有管理員時不再創建
+	created, err := uc.EnsureBootstrapAdmin(ctx, "another", "another-password")
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	_, err = uc.Login(ctx, LoginRequest{Username: "root", Password: "wrong-password"}, time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = uc.Login(ctx, LoginRequest{Username: "nobody", Password: "root-password"}, time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	result, err := uc.Login(ctx, LoginRequest{Username: "root", Password: "root-password"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "admin-token-1", result.Token)
+	assert.Equal(t, int64(3600), result.ExpiresIn)
+	assert.Equal(t, RoleSuperAdmin, result.Role.Name)
+	assert.NotNil(t, repo.admins[1].LastLoginAt)
+
+	// 密碼只保存雜湊
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(repo.passwords[1]), []byte("root-password")))
+}
+
+func TestCreateAndUpdateAdmin(t *testing.T) {
+	ctx := context.Background()
+	uc, _, root := newTestAdminUsecase(t)
+
+	_, err := uc.CreateAdmin(ctx, root, "finance1", "short", RoleFinance)
+	assert.ErrorIs(t, err, ErrInvalidAdmin)
+	_, err = uc.CreateAdmin(ctx, root, "finance1", "finance-password", "accountant")
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+
+	finance, err := uc.CreateAdmin(ctx, root, "finance1", "finance-password", RoleFinance)
+	require.NoError(t, err)
+	_, err = uc.CreateAdmin(ctx, root, "finance1", "finance-password", RoleFinance)
+	assert.ErrorIs(t, err, ErrAdminExists)
+
+	principal, err := uc.Authenticate(ctx, finance.ID, "")
+	require.NoError(t, err)
+	assert.True(t, principal.Can(PermWalletAdjust))
+	assert.False(t, principal.Can(PermFormationWrite))
+
+	// 角色調整在下一次認證時生效
+	operator := RoleOperator
+	_, err = uc.UpdateAdmin(ctx, root, finance.ID, AdminUpdate{Role: &operator})
+	require.NoError(t, err)
+	principal, err = uc.Authenticate(ctx, finance.ID, "")
+	require.NoError(t, err)
+	assert.False(t, principal.Can(PermWalletAdjust))
+	assert.True(t, principal.Can(PermFormationWrite))
+
+	// 停用後不能認證也不能登入
+	inactive := false
+	_, err = uc.UpdateAdmin(ctx, root, finance.ID, AdminUpdate{Active: &inactive})
+	require.NoError(t, err)
+	_, err = uc.Authenticate(ctx, finance.ID, "")
+	assert.ErrorIs(t, err, ErrAdminDisabled)
+	_, err = uc.Login(ctx, LoginRequest{Username: "finance1", Password: "finance-password"}, time.Hour)
+	assert.ErrorIs(t, err, ErrAdminDisabled)
+
+	// 重設密碼
+	password := "new-finance-password"
+	active := true
+	_, err = uc.UpdateAdmin(ctx, root, finance.ID, AdminUpdate{Active: &active, Password: &password})
+	require.NoError(t, err)
+	_, err = uc.Login(ctx, LoginRequest{Username: "finance1", Password: password}, time.Hour)
+	assert.NoError(t, err)
+}
+
+func TestSuperAdminSafeguards(t *testing.T) {
+	ctx := context.Background()
+	uc, _, root := newTestAdminUsecase(t)
+
+	// 不能修改自己的角色和狀態
+	readOnly := RoleReadOnly
+	_, err := uc.UpdateAdmin(ctx, root, root.Admin.ID, AdminUpdate{Role: &readOnly})
+	assert.ErrorIs(t, err, ErrSelfModification)
+
+	// 修改自己的密碼是允許的
+	password := "new-root-password"
+	_, err = uc.UpdateAdmin(ctx, root, root.Admin.ID, AdminUpdate{Password: &password})
+	assert.NoError(t, err)
+
+	second, err := uc.CreateAdmin(ctx, root, "root2", "root2-password", RoleSuperAdmin)
+	require.NoError(t, err)
+	secondPrincipal, err := uc.Authenticate(ctx, second.ID, "")
+	require.NoError(t, err)
+
+	// 有兩個超級管理員時可以降級其中一個，之後最後一個不能被降級或停用
+	_, err = uc.UpdateAdmin(ctx, secondPrincipal, root.Admin.ID, AdminUpdate{Role: &readOnly})
+	require.NoError(t, err)
+	readOnlyRoot, err := uc.Authenticate(ctx, root.Admin.ID, "")
+	require.NoError(t, err)
+	inactive := false
+	_, err = uc.UpdateAdmin(ctx, readOnlyRoot, second.ID, AdminUpdate{Active: &inactive})
+	assert.ErrorIs(t, err, ErrLastSuperAdmin)
+}
+
+func TestRoleManagement(t *testing.T) {
+	ctx := context.Background()
+	uc, _, root := newTestAdminUsecase(t)
+
+	err := uc.CreateRole(ctx, root, &Role{Name: "Bad Name", Permissions: []Permission{"wallet:steal"}})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	auditor := &Role{
+		Name:        "auditor",
+		Description: "審計",
+		Permissions: []Permission{PermWalletRead, PermPlayerRead, PermWalletRead},
+	}
+	require.NoError(t, uc.CreateRole(ctx, root, auditor))
+	assert.Equal(t, []Permission{PermPlayerRead, PermWalletRead}, auditor.Permissions)
+	assert.False(t, auditor.BuiltIn)
+	assert.ErrorIs(t, uc.CreateRole(ctx, root, &Role{Name: "auditor"}), ErrRoleExists)
+
+	// super_admin 不能修改，內置角色可以調整權限但不能刪除
+	assert.ErrorIs(t, uc.UpdateRole(ctx, root, &Role{Name: RoleSuperAdmin}), ErrRoleImmutable)
+	require.NoError(t, uc.UpdateRole(ctx, root, &Role{Name: RoleSupport, Permissions: []Permission{PermPlayerRead}}))
+	support, err := uc.GetRole(ctx, RoleSupport)
+	require.NoError(t, err)
+	assert.True(t, support.BuiltIn)
+	assert.False(t, support.Has(PermPlayerBan))
+	assert.ErrorIs(t, uc.DeleteRole(ctx, root, RoleSupport), ErrRoleImmutable)
+
+	// 仍有管理員使用的角色不能刪除
+	user, err := uc.CreateAdmin(ctx, root, "auditor1", "auditor-password", "auditor")
+	require.NoError(t, err)
+	assert.ErrorIs(t, uc.DeleteRole(ctx, root, "auditor"), ErrRoleInUse)
+
+	readOnly := RoleReadOnly
+	_, err = uc.UpdateAdmin(ctx, root, user.ID, AdminUpdate{Role: &readOnly})
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteRole(ctx, root, "auditor"))
+	_, err = uc.GetRole(ctx, "auditor")
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+}
@@ -0,0 +1,299 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ========================================
+// RoomCheckpoint 房間狀態檢查點
+// ========================================
+//
+// 遊戲節點定期把完整的房間狀態（座位、玩家餘額、魚和子彈、私人房間設置、房間內的陣型及其路線進度）
+// 寫入 Redis。節點重啟或由備用節點接管時按檢查點恢復房間，恢復的玩家標記為離線並保留座位，
+// 使用原來的會話令牌重新連接後回到原座位；觀戰者和換座請求不保存，需要重新觀戰或請求。
+
+// RoomCheckpointVersion 檢查點格式版本，格式不兼容時遞增，恢復時跳過其他版本
+const RoomCheckpointVersion = 1
+
+// RoomCheckpoint 單個房間的檢查點
+type RoomCheckpoint struct {
+	Version    int              `json:"version"`
+	NodeID     string           `json:"node_id"`
+	SavedAt    time.Time        `json:"saved_at"`
+	Room       *Room            `json:"room"`
+	Formations []*FishFormation `json:"formations,omitempty"` // 魚在房間中的陣型
+}
+
+// RoomCheckpointRepo 房間檢查點存儲
+type RoomCheckpointRepo interface {
+	// SaveCheckpoints 以本次檢查點替換節點的全部檢查點，已關閉的房間隨之移除
+	SaveCheckpoints(ctx context.Context, nodeID string, checkpoints []*RoomCheckpoint, ttl time.Duration) error
+	// LoadCheckpoints 讀取節點的全部檢查點
+	LoadCheckpoints(ctx context.Context, nodeID string) ([]*RoomCheckpoint, error)
+	// DeleteCheckpoints 刪除節點的全部檢查點
+	DeleteCheckpoints(ctx context.Context, nodeID string) error
+	// CheckpointNodes 有檢查點的節點 ID
+	CheckpointNodes(ctx context.Context) ([]string, error)
+	// ClaimCheckpoints 聲明由 claimer 恢復節點的檢查點，同一時間只有一個節點能聲明成功
+	ClaimCheckpoints(ctx context.Context, nodeID, claimer string, ttl time.Duration) (bool, error)
+}
+
+// clone 深拷貝房間狀態（不包括觀戰者），調用方需持有 rm.mu
+func (r *Room) clone() *Room {
+	c := *r
+	c.Players = make(map[int64]*Player, len(r.Players))
+	for id, player := range r.Players {
+		p := *player
+		c.Players[id] = &p
+	}
+	c.Seats = append([]int64(nil), r.Seats...)
+	c.Fishes = make(map[int64]*Fish, len(r.Fishes))
+	for id, fish := range r.Fishes {
+		f := *fish
+		c.Fishes[id] = &f
+	}
+	c.Bullets = make(map[int64]*Bullet, len(r.Bullets))
+	for id, bullet := range r.Bullets {
+		b := *bullet
+		c.Bullets[id] = &b
+	}
+	c.Spectators = nil
+	if r.Private != nil {
+		private := *r.Private
+		private.Invited = copyPlayerSet(r.Private.Invited)
+		private.Kicked = copyPlayerSet(r.Private.Kicked)
+		c.Private = &private
+	}
+	return &c
+}
+
+// copyPlayerSet 拷貝玩家 ID 集合
+func copyPlayerSet(set map[int64]bool) map[int64]bool {
+	c := make(map[int64]bool, len(set))
+	for id, v := range set {
+		c[id] = v
+	}
+	return c
+}
+
+// ========================================
+// RoomManager 檢查點和恢復
+// ========================================
+
+// Snapshot 生成所有未關閉房間的檢查點
+func (rm *RoomManager) Snapshot(nodeID string) []*RoomCheckpoint {
+	now := time.Now()
+	formations := rm.spawner.GetFormationManager().GetAllFormations()
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	checkpoints := make([]*RoomCheckpoint, 0, len(rm.rooms))
+	for _, room := range rm.rooms {
+		if room.Status == RoomStatusClosed {
+			continue
+		}
+		cp := &RoomCheckpoint{
+			Version: RoomCheckpointVersion,
+			NodeID:  nodeID,
+			SavedAt: now,
+			Room:    room.clone(),
+		}
+		for _, formation := range formations {
+			if formation.Status == FormationStatusComplete || !formationInRoom(formation, room) {
+				continue
+			}
+			f := *formation
+			// 陣型中的魚指向拷貝後的房間魚，序列化後按 ID 重新關聯
+			f.Fishes = make([]*Fish, 0, len(formation.Fishes))
+			for _, fish := range formation.Fishes {
+				if copied, ok := cp.Room.Fishes[fish.ID]; ok {
+					f.Fishes = append(f.Fishes, copied)
+				}
+			}
+			if formation.LeaderFish != nil {
+				f.LeaderFish = cp.Room.Fishes[formation.LeaderFish.ID]
+			}
+			cp.Formations = append(cp.Formations, &f)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints
+}
+
+// formationInRoom 陣型中是否有魚在房間內，調用方需持有 rm.mu
+func formationInRoom(formation *FishFormation, room *Room) bool {
+	for _, fish := range formation.Fishes {
+		if _, exists := room.Fishes[fish.ID]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// RestoreRoom 按檢查點恢復房間並啟動遊戲循環，房間內的玩家標記為離線，座位保留到 reserveUntil
+func (rm *RoomManager) RestoreRoom(cp *RoomCheckpoint, reserveUntil time.Time) (*Room, error) {
+	if cp.Version != RoomCheckpointVersion || cp.Room == nil {
+		return nil, fmt.Errorf("unsupported room checkpoint version %d", cp.Version)
+	}
+	room := cp.Room
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, exists := rm.rooms[room.ID]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrRoomExists, room.ID)
+	}
+
+	if room.Players == nil {
+		room.Players = make(map[int64]*Player)
+	}
+	if room.Fishes == nil {
+		room.Fishes = make(map[int64]*Fish)
+	}
+	if room.Bullets == nil {
+		room.Bullets = make(map[int64]*Bullet)
+	}
+	room.Spectators = make(map[int64]*Spectator)
+	if int32(len(room.Seats)) != room.MaxPlayers {
+		seats := make([]int64, room.MaxPlayers)
+		copy(seats, room.Seats)
+		room.Seats = seats
+	}
+	now := time.Now()
+	for _, player := range room.Players {
+		player.RoomID = room.ID
+		player.Status = PlayerStatusOffline
+		player.ReservedUntil = reserveUntil
+		player.LastActionAt = now
+	}
+	if room.Private != nil {
+		if room.Private.Invited == nil {
+			room.Private.Invited = make(map[int64]bool)
+		}
+		if room.Private.Kicked == nil {
+			room.Private.Kicked = make(map[int64]bool)
+		}
+		if len(room.Players) == 0 && room.Private.EmptySince.IsZero() {
+			room.Private.EmptySince = time.Now()
+		}
+	}
+
+	// 陣型中的魚重新關聯到房間中的魚，使陣型移動時更新房間內的魚
+	formationManager := rm.spawner.GetFormationManager()
+	for _, formation := range cp.Formations {
+		if formation.Route == nil {
+			continue
+		}
+		fishes := make([]*Fish, 0, len(formation.Fishes))
+		for _, fish := range formation.Fishes {
+			if roomFish, ok := room.Fishes[fish.ID]; ok {
+				fishes = append(fishes, roomFish)
+			}
+		}
+		if len(fishes) == 0 {
+			continue
+		}
+		formation.Fishes = fishes
+		if formation.LeaderFish != nil {
+			if leader, ok := room.Fishes[formation.LeaderFish.ID]; ok {
+				formation.LeaderFish = leader
+			} else {
+				formation.LeaderFish = fishes[0]
+			}
+		}
+		formationManager.RestoreFormation(formation)
+	}
+
+	// 恢復的房間在本節點重新開始生命週期，有玩家時暫停等待重新連接
+	rm.record(room, room.Status, RoomStatusCreated, fmt.Sprintf("restored from checkpoint of node %s", cp.NodeID))
+	if len(room.Players) > 0 {
+		rm.transition(room, RoomStatusPaused, "waiting for players to reconnect")
+	} else {
+		rm.transition(room, RoomStatusWaiting, "game loop started")
+	}
+	rm.rooms[room.ID] = room
+	// 檢查點之後配置有更新時，恢復的房間在第一個週期套用新配置
+	rm.scheduleConfig(room)
+	go rm.startRoomGameLoop(room)
+
+	rm.logger.Infof("Restored room %s from checkpoint of node %s saved at %s: players=%d, fishes=%d, formations=%d",
+		room.ID, cp.NodeID, cp.SavedAt.Format(time.RFC3339), len(room.Players), len(room.Fishes), len(cp.Formations))
+	return room, nil
+}
+
+// ResumePlayer 恢復離線玩家的會話（節點恢復或斷線保留的座位），返回房間中的玩家對象；
+// 玩家不在任何房間的離線座位時返回 ErrPlayerNotInRoom
+func (rm *RoomManager) ResumePlayer(playerID int64) (*Player, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	for _, room := range rm.rooms {
+		player, exists := room.Players[playerID]
+		if !exists || player.Status != PlayerStatusOffline || room.Status == RoomStatusClosed {
+			continue
+		}
+		player.Status = PlayerStatusPlaying
+		player.ReservedUntil = time.Time{}
+		player.LastActionAt = time.Now()
+		room.UpdatedAt = player.LastActionAt
+		if room.Status == RoomStatusPaused {
+			rm.transition(room, RoomStatusPlaying, "player reconnected")
+		}
+		rm.emitSeat(room, newSeatEvent(SeatEventResumed, playerID, player.SeatID))
+		rm.logger.Infof("Player %d resumed seat %d in room %s", playerID, player.SeatID, room.ID)
+		return player, nil
+	}
+	return nil, ErrPlayerNotInRoom
+}
+
+// OfflinePlayers 保留座位尚未重新連接的玩家，按房間 ID 分組
+func (rm *RoomManager) OfflinePlayers() map[string][]int64 {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	offline := make(map[string][]int64)
+	for _, room := range rm.rooms {
+		for playerID, player := range room.Players {
+			if player.Status == PlayerStatusOffline {
+				offline[room.ID] = append(offline[room.ID], playerID)
+			}
+		}
+	}
+	return offline
+}
+
+// ========================================
+// GameUsecase 檢查點用例
+// ========================================
+
+// SnapshotRooms 生成本節點所有房間的檢查點
+func (gu *GameUsecase) SnapshotRooms(nodeID string) []*RoomCheckpoint {
+	return gu.roomManager.Snapshot(nodeID)
+}
+
+// RestoreRoom 按檢查點恢復房間，玩家的座位保留到 reserveUntil
+// 房間計數在原節點創建房間時已經增加，恢復時不再重複計數
+func (gu *GameUsecase) RestoreRoom(ctx context.Context, cp *RoomCheckpoint, reserveUntil time.Time) (*Room, error) {
+	room, err := gu.roomManager.RestoreRoom(cp, reserveUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := gu.gameRepo.SaveRoomToRedis(ctx, room); err != nil {
+		gu.logger.Errorf("Failed to save restored room %s to Redis: %v", room.ID, err)
+	}
+	return room, nil
+}
+
+// ResumeSession 玩家重新連接後回到保留的原座位
+func (gu *GameUsecase) ResumeSession(ctx context.Context, playerID int64) (*Player, error) {
+	return gu.roomManager.ResumePlayer(playerID)
+}
+
+// OfflinePlayers 保留座位尚未重新連接的玩家，按房間 ID 分組
+func (gu *GameUsecase) OfflinePlayers() map[string][]int64 {
+	return gu.roomManager.OfflinePlayers()
+}
@@ -15,10 +15,13 @@ type Player struct {
 	Nickname string    `json:"nickname"`
 	Balance  int64     `json:"balance"`  // 玩家餘額（以分為單位）
 	WalletID uint      `json:"wallet_id"` // 錢包ID，用於交易記錄
+	Currency string    `json:"currency,omitempty"` // 錢包幣種，空表示主錢包幣種
 	RoomID   string    `json:"room_id"`  // 當前房間ID
 	SeatID   int       `json:"seat_id"`  // 座位ID (0-3)，-1 表示未分配
 	Status   PlayerStatus `json:"status"`
 	JoinTime time.Time `json:"join_time"`
+	LastActionAt  time.Time `json:"last_action_at"` // 最後一次開火、切換砲台或換座的時間，心跳不計入，用於 AFK 檢測
+	ReservedUntil time.Time `json:"reserved_until"` // 離線玩家座位保留的截止時間，零值表示不過期
 }
 
 // PlayerStatus 玩家狀態
@@ -105,7 +108,13 @@ type Room struct {
 	Seats       []int64          `json:"seats"`        // 座位切片，存储玩家ID，0表示空座位，长度由配置决定
 	Fishes      map[int64]*Fish   `json:"fishes"`
 	Bullets     map[int64]*Bullet `json:"bullets"`
+	Spectators  map[int64]*Spectator `json:"spectators"` // 觀戰者，不佔座位、不計入 MaxPlayers
+	Private     *PrivateRoom     `json:"private,omitempty"` // 私人房間設置，公開房間為 nil
 	Status      RoomStatus       `json:"status"`
+	History     []RoomTransition `json:"history,omitempty"` // 最近的狀態轉換記錄，只能通過 RoomManager 轉換狀態
+	SwapRequests map[int64]*SeatSwapRequest `json:"-"` // 等待回應的換座請求，key 為發起換座的玩家，不寫入檢查點
+	PendingConfig *RoomConfig `json:"-"` // 等待在下一個遊戲循環週期套用的新配置，見 room_configs.go
+	tideWaveAt  time.Time        // 上一波魚潮風暴的生成時間，見 room_events.go
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
 	Config      RoomConfig       `json:"config"`
@@ -124,15 +133,20 @@ const (
 // RoomStatus 房間狀態
 type RoomStatus string
 
+// 房間生命週期：created → waiting ⇄ playing ⇄ paused → draining → closed，合法轉換見 lifecycle.go
 const (
-	RoomStatusWaiting RoomStatus = "waiting" // 等待中
-	RoomStatusPlaying RoomStatus = "playing" // 遊戲中
-	RoomStatusClosed  RoomStatus = "closed"  // 已關閉
+	RoomStatusCreated  RoomStatus = "created"  // 已創建，遊戲循環尚未啟動
+	RoomStatusWaiting  RoomStatus = "waiting"  // 等待中（沒有玩家，魚繼續游動）
+	RoomStatusPlaying  RoomStatus = "playing"  // 遊戲中
+	RoomStatusPaused   RoomStatus = "paused"   // 已暫停（恢復後等待玩家重新連接），遊戲循環不更新
+	RoomStatusDraining RoomStatus = "draining" // 節點下線中，不再接受新玩家
+	RoomStatusClosed   RoomStatus = "closed"   // 已關閉
 )
 
 // RoomConfig 房間配置
 type RoomConfig struct {
 	MaxPlayers           int32   `json:"max_players"`       // 最大玩家數（座位數）
+	MaxSpectators        int32   `json:"max_spectators"`    // 最大觀戰人數（客服觀戰不受限制）
 	MinBet               int64   `json:"min_bet"`           // 最小下注
 	MaxBet               int64   `json:"max_bet"`           // 最大下注
 	BulletCostMultiplier float64 `json:"bullet_cost_multiplier"` // 子彈成本倍數
@@ -142,6 +156,8 @@ type RoomConfig struct {
 	RoomWidth            float64 `json:"room_width"`        // 房間寬度
 	RoomHeight           float64 `json:"room_height"`       // 房間高度
 	TargetRTP            float64 `json:"target_rtp"`           // 目標RTP, e.g., 0.96 for 96%
+	Currency             string  `json:"currency,omitempty"`   // 遊戲幣種，空表示主錢包幣種
+	Version              int64   `json:"version"`              // room_configs 中的配置版本，0 表示內置默認配置
 }
 
 // Inventory 遊戲庫存系統
@@ -174,6 +190,7 @@ const (
 	EventBulletFire   GameEventType = "bullet_fire"   // 開火
 	EventBulletHit    GameEventType = "bullet_hit"    // 子彈命中
 	EventPlayerReward GameEventType = "player_reward" // 玩家獲得獎勵
+	EventBulletRefund GameEventType = "bullet_refund" // 子彈費用退還
 )
 
 // HitResult 命中結果
@@ -183,6 +200,9 @@ type HitResult struct {
 	Reward    int64   `json:"reward"`    // 獲得獎勵
 	IsCritical bool   `json:"is_critical"` // 是否暴擊
 	Multiplier float64 `json:"multiplier"`  // 獎勵倍數
+	EventID    string        `json:"event_id,omitempty"`    // 加成獎勵的活動場次
+	EventType  RoomEventType `json:"event_type,omitempty"`  // 加成獎勵的活動類型
+	EventBonus int64         `json:"event_bonus,omitempty"` // 活動加成的獎勵，已包含在 Reward 中
 }
 
 // GameStatistics 遊戲統計
@@ -0,0 +1,53 @@
+package game
+
+import "errors"
+
+// ========================================
+// 業務錯誤定義
+// ========================================
+
+// 房間和玩家相關錯誤，調用方通過 errors.Is 判斷（應用層據此映射為客戶端錯誤碼）
+var (
+	ErrRoomNotFound        = errors.New("room not found")
+	ErrRoomFull            = errors.New("room is full, no available seats")
+	ErrInvalidSeat         = errors.New("invalid seat ID")
+	ErrPlayerAlreadyInRoom = errors.New("player already in room")
+	ErrPlayerNotInRoom     = errors.New("player not in room")
+	ErrPlayerNotFound      = errors.New("player not found")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrInvalidBulletPower  = errors.New("invalid bullet power")
+	ErrBulletNotFound      = errors.New("bullet not found")
+	ErrFishNotFound        = errors.New("fish not found")
+	ErrWalletOperation     = errors.New("wallet operation failed") // 包裹錢包層返回的錯誤
+	ErrRoomNotEmpty        = errors.New("room is not empty")
+	ErrNoRoomAvailable     = errors.New("no room available")
+	ErrSpectatorsFull      = errors.New("room spectator limit reached")
+	ErrPlayerSpectating    = errors.New("player is spectating")
+	ErrRoomLocked          = errors.New("room is locked")
+	ErrInvalidInviteCode   = errors.New("invalid invite code")
+	ErrWrongRoomPassword   = errors.New("wrong room password")
+	ErrKickedFromRoom      = errors.New("player was kicked from room")
+	ErrNotRoomOwner        = errors.New("player is not the room owner")
+	ErrPrivateRoomLimit    = errors.New("private room limit reached")
+	ErrRoomExists          = errors.New("room already exists")
+	ErrRoomDraining        = errors.New("room is draining")
+	ErrInvalidTransition   = errors.New("invalid room status transition")
+	ErrSeatTaken           = errors.New("seat is taken")
+	ErrSeatReserved        = errors.New("seat is reserved for a disconnected player")
+	ErrSwapRequestNotFound = errors.New("seat swap request not found or expired")
+)
+
+// 房間配置相關錯誤，管理後台據此返回 400、404 或 409
+var (
+	ErrInvalidRoomConfig  = errors.New("invalid room config")
+	ErrUnknownRoomType    = errors.New("unknown room type")
+	ErrRoomConfigNotFound = errors.New("room config not found")
+	ErrRoomConfigExists   = errors.New("room config already exists")
+	ErrRoomConfigConflict = errors.New("room config version conflict")
+)
+
+// 房間活動相關錯誤
+var (
+	ErrInvalidRoomEvent  = errors.New("invalid room event schedule")
+	ErrRoomEventNotFound = errors.New("room event schedule not found")
+)
@@ -451,6 +451,17 @@ func (fm *FishFormationManager) RemoveFormation(formationID string) bool {
 	return true
 }
 
+// RestoreFormation 恢復檢查點中的陣型，路線優先使用管理器中的同名路線
+func (fm *FishFormationManager) RestoreFormation(formation *FishFormation) {
+	if formation.Route != nil {
+		if route := fm.routes[formation.Route.ID]; route != nil {
+			formation.Route = route
+		}
+	}
+	fm.formations[formation.ID] = formation
+	fm.logger.Infof("Restored formation: id=%s, progress=%.2f", formation.ID, formation.Progress)
+}
+
 // 工具函數
 func generateFormationID() string {
 	return "formation_" + time.Now().Format("20060102150405") + "_" + string(rune(time.Now().UnixNano()%1000))
@@ -113,6 +113,50 @@ func (gr *GameRecord) RecordFishCaught(reward float64, isCritical bool) {
 	gr.UpdatedAt = time.Now()
 }
 
+// RecordBulletRefunded 記錄退還的子彈（未命中結算前離場時退回費用）
+func (gr *GameRecord) RecordBulletRefunded(cost float64) {
+	if gr.BulletsFired > 0 {
+		gr.BulletsFired--
+	}
+	gr.TotalBets -= cost
+	gr.NetProfit = gr.TotalWins - gr.TotalBets
+	if gr.BulletsFired > 0 {
+		gr.HitRate = float64(gr.BulletsHit) / float64(gr.BulletsFired) * 100
+	}
+	gr.UpdatedAt = time.Now()
+}
+
+// SetEndReason 記錄遊戲結束原因（例如節點下線）
+func (gr *GameRecord) SetEndReason(reason string) {
+	if gr.Metadata == nil {
+		gr.Metadata = make(map[string]interface{})
+	}
+	gr.Metadata["end_reason"] = reason
+}
+
+// RecordEventPayout 記錄活動加成的獎勵，按活動場次匯總在 Metadata["events"] 中
+func (gr *GameRecord) RecordEventPayout(eventID string, eventType RoomEventType, bonus float64) {
+	if gr.Metadata == nil {
+		gr.Metadata = make(map[string]interface{})
+	}
+	// 從數據庫讀取的 Metadata 經過 JSON 解碼，數字為 float64
+	events, _ := gr.Metadata["events"].(map[string]interface{})
+	if events == nil {
+		events = make(map[string]interface{})
+		gr.Metadata["events"] = events
+	}
+	entry, _ := events[eventID].(map[string]interface{})
+	if entry == nil {
+		entry = map[string]interface{}{"type": string(eventType), "catches": 0.0, "bonus": 0.0}
+		events[eventID] = entry
+	}
+	catches, _ := entry["catches"].(float64)
+	total, _ := entry["bonus"].(float64)
+	entry["catches"] = catches + 1
+	entry["bonus"] = total + bonus
+	gr.UpdatedAt = time.Now()
+}
+
 // Finish 結束遊戲記錄
 func (gr *GameRecord) Finish() {
 	now := time.Now()
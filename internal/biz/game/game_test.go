@@ -26,6 +26,21 @@ func (m *MockGameRepo) ListRooms(ctx context.Context, roomType game.RoomType) ([
 	return []*game.Room{}, nil
 }
 func (m *MockGameRepo) DeleteRoom(ctx context.Context, roomID string) error { return nil }
+func (m *MockGameRepo) SaveRoomToRedis(ctx context.Context, room *game.Room) error   { return nil }
+func (m *MockGameRepo) DeleteRoomFromRedis(ctx context.Context, roomID string) error { return nil }
+func (m *MockGameRepo) IncrementRoomCount(ctx context.Context, roomType game.RoomType) error {
+	return nil
+}
+func (m *MockGameRepo) DecrementRoomCount(ctx context.Context, roomType game.RoomType) error {
+	return nil
+}
+func (m *MockGameRepo) GetRoomCount(ctx context.Context, roomType game.RoomType) (int64, error) {
+	return 0, nil
+}
+func (m *MockGameRepo) GetTotalRoomCount(ctx context.Context) (int64, error) { return 0, nil }
+func (m *MockGameRepo) GetAllRoomCounts(ctx context.Context) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
 func (m *MockGameRepo) SaveGameStatistics(ctx context.Context, playerID int64, stats *game.GameStatistics) error {
 	return nil
 }
@@ -49,9 +64,6 @@ type MockPlayerRepo struct{}
 func (m *MockPlayerRepo) GetPlayer(ctx context.Context, playerID int64) (*game.Player, error) {
 	return &game.Player{ID: playerID, UserID: playerID, Nickname: "TestPlayer", Balance: 100000, WalletID: 1, Status: game.PlayerStatusIdle}, nil
 }
-func (m *MockPlayerRepo) UpdatePlayerBalance(ctx context.Context, playerID int64, balance int64) error {
-	return nil
-}
 func (m *MockPlayerRepo) UpdatePlayerStatus(ctx context.Context, playerID int64, status game.PlayerStatus) error {
 	return nil
 }
@@ -321,7 +333,7 @@ func TestGameFlowWithRTP(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 2. Fire a bullet
-	bullet, err := te.gameUsecase.FireBullet(te.ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750})
+	bullet, err := te.gameUsecase.FireBullet(te.ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 	assert.NoError(t, err)
 
 	// Check that the bet was recorded
@@ -356,7 +368,7 @@ func TestGameFlowWithRTP(t *testing.T) {
 		}
 		// If first attempt fails, create a new bullet for next attempt
 		if i < 9 {
-			bullet, err = te.gameUsecase.FireBullet(te.ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750})
+			bullet, err = te.gameUsecase.FireBullet(te.ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 			assert.NoError(t, err)
 		}
 	}
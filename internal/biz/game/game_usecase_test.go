@@ -107,7 +107,6 @@ func TestGameUsecase_FireBullet(t *testing.T) {
 	env.GameUsecase.JoinRoom(env.Ctx, room.ID, playerID)
 
 	// Setup mock for bullet firing
-	env.PlayerRepo.On("UpdatePlayerBalance", env.Ctx, playerID, mock.AnythingOfType("int64")).Return(nil).Maybe()
 
 	// Setup inventory mocks
 	inventory := testhelper.NewTestInventory("novice", 0, 0)
@@ -117,7 +116,7 @@ func TestGameUsecase_FireBullet(t *testing.T) {
 		Return(nil).Maybe()
 
 	t.Run("fire bullet successfully", func(t *testing.T) {
-		bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750})
+		bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, bullet)
@@ -129,7 +128,7 @@ func TestGameUsecase_FireBullet(t *testing.T) {
 	t.Run("bet is recorded in inventory", func(t *testing.T) {
 		initialIn := env.InventoryManager.GetInventory(game.RoomTypeNovice).TotalIn
 
-		bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750})
+		bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 		assert.NoError(t, err)
 
 		finalIn := env.InventoryManager.GetInventory(game.RoomTypeNovice).TotalIn
@@ -137,7 +136,7 @@ func TestGameUsecase_FireBullet(t *testing.T) {
 	})
 
 	t.Run("cannot fire from non-existing room", func(t *testing.T) {
-		bullet, err := env.GameUsecase.FireBullet(env.Ctx, "non-existing", playerID, 1.0, 10, game.Position{X: 600, Y: 750})
+		bullet, err := env.GameUsecase.FireBullet(env.Ctx, "non-existing", playerID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 
 		assert.Error(t, err)
 		assert.Nil(t, bullet)
@@ -159,7 +158,6 @@ func TestGameUsecase_HitFish(t *testing.T) {
 	env.GameUsecase.JoinRoom(env.Ctx, room.ID, playerID)
 
 	// Setup mock for balance updates
-	env.PlayerRepo.On("UpdatePlayerBalance", env.Ctx, playerID, mock.AnythingOfType("int64")).Return(nil).Maybe()
 
 	// Setup inventory - low RTP to force wins
 	lowRTPInv := testhelper.NewTestInventory("novice", 10000, 1000)
@@ -169,7 +167,7 @@ func TestGameUsecase_HitFish(t *testing.T) {
 		Return(nil).Maybe()
 
 	// Fire bullet
-	bullet, _ := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750})
+	bullet, _ := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 
 	t.Run("hit fish successfully", func(t *testing.T) {
 		// Get a fish from room
@@ -200,7 +198,7 @@ func TestGameUsecase_HitFish(t *testing.T) {
 
 			// Create new bullet for next attempt
 			if i < 19 {
-				bullet, _ = env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750})
+				bullet, _ = env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 			}
 		}
 
@@ -262,7 +260,6 @@ func TestGameUsecase_CompleteGameFlow(t *testing.T) {
 			players = append(players, player)
 			env.PlayerRepo.On("GetPlayer", env.Ctx, i).Return(player, nil)
 			env.PlayerRepo.On("UpdatePlayerStatus", env.Ctx, i, game.PlayerStatusPlaying).Return(nil)
-			env.PlayerRepo.On("UpdatePlayerBalance", env.Ctx, i, mock.AnythingOfType("int64")).Return(nil).Maybe()
 
 			err := env.GameUsecase.JoinRoom(env.Ctx, room.ID, i)
 			assert.NoError(t, err)
@@ -271,7 +268,7 @@ func TestGameUsecase_CompleteGameFlow(t *testing.T) {
 		// 3. Players fire bullets
 		bullets := make([]*game.Bullet, 0)
 		for _, player := range players {
-			bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, player.ID, 1.0, 10, game.Position{X: 600, Y: 750})
+			bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, player.ID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 			assert.NoError(t, err)
 			bullets = append(bullets, bullet)
 		}
@@ -343,7 +340,7 @@ func TestGameUsecase_EdgeCases(t *testing.T) {
 		env.RoomManager.JoinRoom(room.ID, poorPlayer)
 
 		// Try to fire expensive bullet
-		bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 10.0, 100, game.Position{X: 600, Y: 750})
+		bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 10.0, 100, game.Position{X: 600, Y: 750}, 0)
 
 		// Should either error or refuse
 		if err != nil {
@@ -97,6 +97,24 @@ func (im *InventoryManager) AddBet(roomType RoomType, amount int64) {
 	im.repo.SaveInventory(context.Background(), inv) // For simplicity, save on every change
 }
 
+// RefundBet reverses a bet that was refunded to the player, decreasing TotalIn.
+func (im *InventoryManager) RefundBet(roomType RoomType, amount int64) {
+	if amount <= 0 {
+		return
+	}
+
+	inv := im.GetInventory(roomType)
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	inv.TotalIn -= amount
+	inv.UpdatedAt = time.Now()
+	im.updateRTP(inv)
+
+	im.repo.SaveInventory(context.Background(), inv)
+}
+
 // AddWin records a player's win, increasing TotalOut for the room type's inventory.
 func (im *InventoryManager) AddWin(roomType RoomType, amount int64) {
 	if amount <= 0 {
@@ -0,0 +1,225 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 房間生命週期
+// ========================================
+//
+// 房間狀態只能通過 RoomManager.transition 按 roomTransitions 轉換：
+//
+//	created  → waiting（遊戲循環啟動）/ paused（恢復的房間等待玩家重新連接）
+//	waiting  ⇄ playing（第一個玩家加入 / 最後一個玩家離開）
+//	paused   → playing（玩家重新連接或加入）/ waiting（離線玩家全部離開）
+//	任意狀態 → draining（節點下線）→ closed
+//
+// 每次轉換記錄在 Room.History 中（最多 maxRoomHistory 條），並按順序異步通知已註冊的鉤子，
+// 用於持久化轉換歷史、上報大廳和統計指標。
+
+// maxRoomHistory 房間保留的最近轉換記錄數
+const maxRoomHistory = 50
+
+// roomTransitionBuffer 等待通知鉤子的事件緩衝區大小，已滿時丟棄事件
+const roomTransitionBuffer = 1024
+
+// roomTransitions 合法的狀態轉換
+var roomTransitions = map[RoomStatus][]RoomStatus{
+	RoomStatusCreated:  {RoomStatusWaiting, RoomStatusPaused, RoomStatusClosed},
+	RoomStatusWaiting:  {RoomStatusPlaying, RoomStatusPaused, RoomStatusDraining, RoomStatusClosed},
+	RoomStatusPlaying:  {RoomStatusWaiting, RoomStatusPaused, RoomStatusDraining, RoomStatusClosed},
+	RoomStatusPaused:   {RoomStatusWaiting, RoomStatusPlaying, RoomStatusDraining, RoomStatusClosed},
+	RoomStatusDraining: {RoomStatusClosed},
+}
+
+// CanTransition 房間狀態能否從 from 轉換為 to
+func CanTransition(from, to RoomStatus) bool {
+	for _, next := range roomTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// RoomTransition 房間狀態轉換記錄
+type RoomTransition struct {
+	RoomID   string     `json:"room_id"`
+	RoomType RoomType   `json:"room_type"`
+	From     RoomStatus `json:"from"`
+	To       RoomStatus `json:"to"`
+	Reason   string     `json:"reason"`
+	Players  int        `json:"players"` // 轉換時房間內的玩家數
+	At       time.Time  `json:"at"`
+}
+
+// RoomLifecycleHook 房間狀態轉換鉤子，在獨立的 goroutine 中按轉換順序調用
+type RoomLifecycleHook func(transition RoomTransition)
+
+// RoomHistoryRepo 房間狀態轉換歷史存儲，供管理後台查詢其他節點上的房間
+type RoomHistoryRepo interface {
+	// AppendRoomTransition 追加一條轉換記錄
+	AppendRoomTransition(ctx context.Context, transition RoomTransition) error
+	// RoomHistory 按時間順序獲取房間的轉換記錄
+	RoomHistory(ctx context.Context, roomID string) ([]RoomTransition, error)
+}
+
+// eventDispatcher 管理鉤子並在獨立的 goroutine 中按發布順序分發事件（房間狀態轉換、座位事件）
+type eventDispatcher[T any] struct {
+	name   string
+	mu     sync.RWMutex
+	hooks  []func(T)
+	events chan T
+	once   sync.Once
+	logger logger.Logger
+}
+
+// newEventDispatcher 創建事件分發器
+func newEventDispatcher[T any](name string, logger logger.Logger) *eventDispatcher[T] {
+	return &eventDispatcher[T]{
+		name:   name,
+		events: make(chan T, roomTransitionBuffer),
+		logger: logger,
+	}
+}
+
+// register 註冊鉤子，第一次註冊時啟動分發 goroutine
+func (d *eventDispatcher[T]) register(hook func(T)) {
+	d.mu.Lock()
+	d.hooks = append(d.hooks, hook)
+	d.mu.Unlock()
+	d.once.Do(func() { go d.dispatch() })
+}
+
+// publish 發布事件，調用方可能持有 rm.mu，因此不阻塞
+func (d *eventDispatcher[T]) publish(event T) {
+	d.mu.RLock()
+	hasHooks := len(d.hooks) > 0
+	d.mu.RUnlock()
+	if !hasHooks {
+		return
+	}
+
+	select {
+	case d.events <- event:
+	default:
+		d.logger.Warnf("%s buffer full, dropped event %+v", d.name, event)
+	}
+}
+
+// dispatch 按順序把事件交給所有鉤子
+func (d *eventDispatcher[T]) dispatch() {
+	for event := range d.events {
+		d.mu.RLock()
+		hooks := d.hooks
+		d.mu.RUnlock()
+		for _, hook := range hooks {
+			hook(event)
+		}
+	}
+}
+
+// ========================================
+// RoomManager 狀態轉換
+// ========================================
+
+// OnTransition 註冊房間狀態轉換鉤子
+func (rm *RoomManager) OnTransition(hook RoomLifecycleHook) {
+	rm.lifecycle.register(hook)
+}
+
+// transition 驗證並執行狀態轉換，調用方需持有 rm.mu
+func (rm *RoomManager) transition(room *Room, to RoomStatus, reason string) error {
+	if !CanTransition(room.Status, to) {
+		return fmt.Errorf("%w: room %s %s -> %s", ErrInvalidTransition, room.ID, room.Status, to)
+	}
+	rm.record(room, room.Status, to, reason)
+	return nil
+}
+
+// record 設置房間狀態並記錄轉換，不驗證轉換是否合法，調用方需持有 rm.mu
+func (rm *RoomManager) record(room *Room, from, to RoomStatus, reason string) {
+	now := time.Now()
+	transition := RoomTransition{
+		RoomID:   room.ID,
+		RoomType: room.Type,
+		From:     from,
+		To:       to,
+		Reason:   reason,
+		Players:  len(room.Players),
+		At:       now,
+	}
+
+	room.Status = to
+	room.UpdatedAt = now
+	room.History = append(room.History, transition)
+	if len(room.History) > maxRoomHistory {
+		room.History = append([]RoomTransition(nil), room.History[len(room.History)-maxRoomHistory:]...)
+	}
+
+	rm.logger.Infof("Room %s: %s -> %s (%s)", room.ID, from, to, reason)
+	rm.lifecycle.publish(transition)
+}
+
+// roomStatus 在 rm.mu 保護下讀取房間狀態，供不持鎖的遊戲循環使用
+func (rm *RoomManager) roomStatus(room *Room) RoomStatus {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return room.Status
+}
+
+// History 獲取房間的轉換記錄
+func (rm *RoomManager) History(roomID string) ([]RoomTransition, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	room, exists := rm.rooms[roomID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	return append([]RoomTransition(nil), room.History...), nil
+}
+
+// DrainRooms 節點下線時把所有房間轉換為 draining，房間不再接受新玩家，返回轉換的房間數
+func (rm *RoomManager) DrainRooms(reason string) int {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	drained := 0
+	for _, room := range rm.rooms {
+		if room.Status == RoomStatusDraining || room.Status == RoomStatusClosed {
+			continue
+		}
+		if err := rm.transition(room, RoomStatusDraining, reason); err != nil {
+			rm.logger.Warnf("Failed to drain room %s: %v", room.ID, err)
+			continue
+		}
+		drained++
+	}
+	return drained
+}
+
+// ========================================
+// GameUsecase 生命週期用例
+// ========================================
+
+// OnRoomTransition 註冊房間狀態轉換鉤子
+func (gu *GameUsecase) OnRoomTransition(hook RoomLifecycleHook) {
+	gu.roomManager.OnTransition(hook)
+}
+
+// RoomHistory 獲取本節點房間的轉換記錄
+func (gu *GameUsecase) RoomHistory(roomID string) ([]RoomTransition, error) {
+	return gu.roomManager.History(roomID)
+}
+
+// DrainRooms 把本節點所有房間轉換為 draining
+func (gu *GameUsecase) DrainRooms(reason string) int {
+	return gu.roomManager.DrainRooms(reason)
+}
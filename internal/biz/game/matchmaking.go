@@ -0,0 +1,195 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ========================================
+// Matchmaking 快速加入的房間選擇
+// ========================================
+
+// friendWeight 每位好友在房間中的加分，大於任何填充率差值，優先和好友同房
+const friendWeight = 1.0
+
+// RoomStats 房間快照，用於在鎖外做房間選擇和擴縮容判斷
+type RoomStats struct {
+	ID         string
+	Type       RoomType
+	Players    int
+	Spectators int
+	MaxPlayers int32
+	PlayerIDs  []int64
+	CreatedAt  time.Time
+}
+
+// Full 房間是否已滿
+func (s RoomStats) Full() bool {
+	return s.Players >= int(s.MaxPlayers)
+}
+
+// Fill 房間當前填充率
+func (s RoomStats) Fill() float64 {
+	if s.MaxPlayers <= 0 {
+		return 1
+	}
+	return float64(s.Players) / float64(s.MaxPlayers)
+}
+
+// MatchCriteria 房間選擇條件
+type MatchCriteria struct {
+	RoomType   RoomType        // 房間類型，為空時不限
+	FriendIDs  []int64         // 好友 ID，優先選擇有好友在的房間
+	TargetFill float64         // 偏好的加入後填充率（0-1）
+	Exclude    map[string]bool // 排除的房間（例如剛剛加入失敗的房間）
+}
+
+// SelectRoom 從房間快照中選出最合適的房間
+// 評分 = 好友數 × friendWeight − |加入後填充率 − 目標填充率|；
+// 同分時優先人多的房間，其次是較早創建的房間，保證結果穩定
+func SelectRoom(rooms []RoomStats, criteria MatchCriteria) (RoomStats, bool) {
+	friends := make(map[int64]bool, len(criteria.FriendIDs))
+	for _, id := range criteria.FriendIDs {
+		friends[id] = true
+	}
+
+	var best RoomStats
+	bestScore := math.Inf(-1)
+	found := false
+	for _, room := range rooms {
+		if criteria.RoomType != "" && room.Type != criteria.RoomType {
+			continue
+		}
+		if room.Full() || criteria.Exclude[room.ID] {
+			continue
+		}
+
+		friendCount := 0
+		for _, id := range room.PlayerIDs {
+			if friends[id] {
+				friendCount++
+			}
+		}
+		fillAfter := float64(room.Players+1) / float64(room.MaxPlayers)
+		score := float64(friendCount)*friendWeight - math.Abs(fillAfter-criteria.TargetFill)
+
+		if !found || score > bestScore || (score == bestScore && betterTieBreak(room, best)) {
+			best, bestScore, found = room, score, true
+		}
+	}
+	return best, found
+}
+
+// betterTieBreak 同分時 a 是否優於 b
+func betterTieBreak(a, b RoomStats) bool {
+	if a.Players != b.Players {
+		return a.Players > b.Players
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// ========================================
+// RoomManager 房間快照與關閉
+// ========================================
+
+// RoomStats 獲取公開房間快照，roomType 為空時返回所有類型
+func (rm *RoomManager) RoomStats(roomType RoomType) []RoomStats {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	stats := make([]RoomStats, 0, len(rm.rooms))
+	for _, room := range rm.rooms {
+		// 私人房間不參與快速加入和擴縮容
+		if room.Status == RoomStatusClosed || room.Private != nil {
+			continue
+		}
+		if roomType != "" && room.Type != roomType {
+			continue
+		}
+
+		playerIDs := make([]int64, 0, len(room.Players))
+		for id := range room.Players {
+			playerIDs = append(playerIDs, id)
+		}
+		stats = append(stats, RoomStats{
+			ID:         room.ID,
+			Type:       room.Type,
+			Players:    len(room.Players),
+			Spectators: len(room.Spectators),
+			MaxPlayers: room.MaxPlayers,
+			PlayerIDs:  playerIDs,
+			CreatedAt:  room.CreatedAt,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats
+}
+
+// CloseRoom 關閉房間：停止遊戲循環並從內存中移除
+// requireEmpty 為 true 時只關閉沒有玩家和觀戰者的房間
+func (rm *RoomManager) CloseRoom(roomID string, requireEmpty bool) (*Room, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, exists := rm.rooms[roomID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	if requireEmpty && (len(room.Players) > 0 || len(room.Spectators) > 0) {
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotEmpty, roomID)
+	}
+
+	reason := "closed"
+	if requireEmpty {
+		reason = "idle"
+	}
+	if err := rm.transition(room, RoomStatusClosed, reason); err != nil {
+		return nil, err
+	}
+	delete(rm.rooms, roomID)
+
+	rm.logger.Infof("Room %s removed from memory", roomID)
+	return room, nil
+}
+
+// ========================================
+// GameUsecase 快速加入相關用例
+// ========================================
+
+// RoomStats 獲取房間快照
+func (gu *GameUsecase) RoomStats(roomType RoomType) []RoomStats {
+	return gu.roomManager.RoomStats(roomType)
+}
+
+// RoomTypeConfig 獲取房間類型的配置（下注範圍、座位數等）
+func (gu *GameUsecase) RoomTypeConfig(roomType RoomType) RoomConfig {
+	return gu.roomManager.getRoomConfig(roomType)
+}
+
+// StakeRoomType 按餘額選擇房間類型：餘額足夠以最低下注發射 stakeShots 發子彈的最高類型
+// 沒有任何類型滿足時返回最低下注最小的類型；candidates 為空時返回 false
+func (gu *GameUsecase) StakeRoomType(balance int64, stakeShots int64, candidates []RoomType) (RoomType, bool) {
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	sorted := make([]RoomType, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return gu.RoomTypeConfig(sorted[i]).MinBet < gu.RoomTypeConfig(sorted[j]).MinBet
+	})
+
+	chosen := sorted[0]
+	for _, rt := range sorted[1:] {
+		if balance >= gu.RoomTypeConfig(rt).MinBet*stakeShots {
+			chosen = rt
+		}
+	}
+	return chosen, true
+}
@@ -0,0 +1,332 @@
+package game
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ========================================
+// PrivateRoom 私人房間
+// ========================================
+//
+// 私人房間由玩家創建，通過邀請碼（可選密碼）加入，不出現在公開房間列表中，也不參與快速加入。
+// 房主可以踢出玩家和鎖定房間；沒有玩家超過 IdleTimeout 後房間被關閉。
+
+const (
+	inviteCodeLength   = 6
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 去掉容易混淆的 I、O、0、1
+)
+
+// PrivateRoom 私人房間設置
+type PrivateRoom struct {
+	OwnerID      int64          `json:"owner_id"`
+	InviteCode   string         `json:"invite_code"`
+	PasswordHash string         `json:"password_hash,omitempty"`
+	Locked       bool           `json:"locked"`       // 鎖定後只有房主可以加入
+	IdleTimeout  time.Duration  `json:"idle_timeout"` // 沒有玩家超過此時間後關閉
+	EmptySince   time.Time      `json:"empty_since"`  // 最後一位玩家離開的時間，有玩家時為零值
+	Invited      map[int64]bool `json:"invited"`      // 已通過邀請碼驗證的玩家
+	Kicked       map[int64]bool `json:"kicked"`       // 被房主踢出的玩家，不能再次加入
+}
+
+// PrivateRoomOptions 創建私人房間的參數
+type PrivateRoomOptions struct {
+	OwnerID     int64
+	Password    string // 為空時不需要密碼
+	IdleTimeout time.Duration
+	MaxPerOwner int // 每個玩家同時擁有的私人房間數上限，0 表示不限
+}
+
+// PrivateRoomInfo 私人房間快照
+type PrivateRoomInfo struct {
+	OwnerID     int64
+	InviteCode  string
+	HasPassword bool
+	Locked      bool
+}
+
+// admit 檢查玩家能否進入房間，公開房間（nil）總是允許，調用方需持有 rm.mu
+func (p *PrivateRoom) admit(playerID int64) error {
+	if p == nil {
+		return nil
+	}
+	if p.Kicked[playerID] {
+		return ErrKickedFromRoom
+	}
+	if playerID == p.OwnerID {
+		return nil
+	}
+	if p.Locked {
+		return ErrRoomLocked
+	}
+	if !p.Invited[playerID] {
+		return fmt.Errorf("%w: invite code required", ErrInvalidInviteCode)
+	}
+	return nil
+}
+
+// generateInviteCode 生成隨機邀請碼
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	// 字母表長度為 32，取模不會產生偏差
+	for i, b := range buf {
+		buf[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// normalizeInviteCode 忽略大小寫和首尾空白
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ========================================
+// RoomManager 私人房間管理
+// ========================================
+
+// CreatePrivateRoom 創建私人房間
+func (rm *RoomManager) CreatePrivateRoom(roomType RoomType, maxPlayers int32, opts PrivateRoomOptions) (*Room, error) {
+	private := &PrivateRoom{
+		OwnerID:     opts.OwnerID,
+		IdleTimeout: opts.IdleTimeout,
+		EmptySince:  time.Now(),
+		Invited:     make(map[int64]bool),
+		Kicked:      make(map[int64]bool),
+	}
+	if opts.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash room password: %w", err)
+		}
+		private.PasswordHash = string(hash)
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if opts.MaxPerOwner > 0 {
+		owned := 0
+		for _, room := range rm.rooms {
+			if room.Private != nil && room.Private.OwnerID == opts.OwnerID {
+				owned++
+			}
+		}
+		if owned >= opts.MaxPerOwner {
+			return nil, fmt.Errorf("%w: player %d owns %d private rooms", ErrPrivateRoomLimit, opts.OwnerID, owned)
+		}
+	}
+
+	for {
+		code, err := generateInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		if rm.roomByInviteCode(code) == nil {
+			private.InviteCode = code
+			break
+		}
+	}
+
+	return rm.createRoom(roomType, maxPlayers, private), nil
+}
+
+// roomByInviteCode 按邀請碼查找私人房間，調用方需持有 rm.mu
+func (rm *RoomManager) roomByInviteCode(code string) *Room {
+	for _, room := range rm.rooms {
+		if room.Private != nil && room.Private.InviteCode == code && room.Status != RoomStatusClosed {
+			return room
+		}
+	}
+	return nil
+}
+
+// AuthorizeInvite 驗證邀請碼和密碼，通過後玩家可以加入或觀戰該房間，返回房間 ID
+func (rm *RoomManager) AuthorizeInvite(code, password string, playerID int64) (string, error) {
+	code = normalizeInviteCode(code)
+
+	rm.mu.RLock()
+	room := rm.roomByInviteCode(code)
+	var passwordHash string
+	if room != nil {
+		passwordHash = room.Private.PasswordHash
+	}
+	rm.mu.RUnlock()
+
+	if room == nil {
+		return "", ErrInvalidInviteCode
+	}
+	// bcrypt 比較較慢，在鎖外進行
+	if passwordHash != "" && bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) != nil {
+		return "", fmt.Errorf("%w: %s", ErrWrongRoomPassword, room.ID)
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	// 驗證密碼期間房間可能已關閉
+	if current, exists := rm.rooms[room.ID]; !exists || current != room || room.Status == RoomStatusClosed {
+		return "", ErrInvalidInviteCode
+	}
+	private := room.Private
+	if private.Kicked[playerID] {
+		return "", fmt.Errorf("%w: %s", ErrKickedFromRoom, room.ID)
+	}
+	if private.Locked && playerID != private.OwnerID {
+		return "", fmt.Errorf("%w: %s", ErrRoomLocked, room.ID)
+	}
+
+	private.Invited[playerID] = true
+	return room.ID, nil
+}
+
+// privateRoomOf 獲取房主的私人房間，調用方需持有 rm.mu
+func (rm *RoomManager) privateRoomOf(roomID string, ownerID int64) (*Room, error) {
+	room, exists := rm.rooms[roomID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	if room.Private == nil || room.Private.OwnerID != ownerID {
+		return nil, fmt.Errorf("%w: %s", ErrNotRoomOwner, roomID)
+	}
+	return room, nil
+}
+
+// SetRoomLocked 房主鎖定或解鎖房間，鎖定後已在房間內的玩家不受影響
+func (rm *RoomManager) SetRoomLocked(roomID string, ownerID int64, locked bool) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, err := rm.privateRoomOf(roomID, ownerID)
+	if err != nil {
+		return err
+	}
+	room.Private.Locked = locked
+	rm.logger.Infof("Private room %s locked=%v by owner %d", roomID, locked, ownerID)
+	return nil
+}
+
+// KickPlayer 房主踢出玩家或觀戰者，被踢出的玩家不能再次加入
+// 返回被踢出的玩家是否已入座，已入座的玩家由調用方按正常流程離開房間以結算
+func (rm *RoomManager) KickPlayer(roomID string, ownerID, targetID int64) (bool, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, err := rm.privateRoomOf(roomID, ownerID)
+	if err != nil {
+		return false, err
+	}
+	if targetID == ownerID {
+		return false, fmt.Errorf("%w: owner cannot kick themselves", ErrNotRoomOwner)
+	}
+
+	_, seated := room.Players[targetID]
+	_, watching := room.Spectators[targetID]
+	if !seated && !watching {
+		return false, fmt.Errorf("%w: %s", ErrPlayerNotInRoom, roomID)
+	}
+
+	room.Private.Kicked[targetID] = true
+	delete(room.Private.Invited, targetID)
+	delete(room.Spectators, targetID)
+
+	rm.logger.Infof("Player %d kicked from private room %s by owner %d", targetID, roomID, ownerID)
+	return seated, nil
+}
+
+// PrivateRoomInfo 獲取私人房間快照，公開房間返回 false
+func (rm *RoomManager) PrivateRoomInfo(roomID string) (PrivateRoomInfo, bool) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	room, exists := rm.rooms[roomID]
+	if !exists || room.Private == nil {
+		return PrivateRoomInfo{}, false
+	}
+	return PrivateRoomInfo{
+		OwnerID:     room.Private.OwnerID,
+		InviteCode:  room.Private.InviteCode,
+		HasPassword: room.Private.PasswordHash != "",
+		Locked:      room.Private.Locked,
+	}, true
+}
+
+// IdlePrivateRooms 沒有玩家和觀戰者超過 IdleTimeout 的私人房間
+func (rm *RoomManager) IdlePrivateRooms(now time.Time) []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	var idle []string
+	for _, room := range rm.rooms {
+		private := room.Private
+		if private == nil || private.EmptySince.IsZero() {
+			continue
+		}
+		if len(room.Players) > 0 || len(room.Spectators) > 0 {
+			continue
+		}
+		if now.Sub(private.EmptySince) >= private.IdleTimeout {
+			idle = append(idle, room.ID)
+		}
+	}
+	return idle
+}
+
+// ========================================
+// GameUsecase 私人房間用例
+// ========================================
+
+// CreatePrivateRoom 玩家創建私人房間
+func (gu *GameUsecase) CreatePrivateRoom(ctx context.Context, roomType RoomType, opts PrivateRoomOptions) (*Room, error) {
+	room, err := gu.roomManager.CreatePrivateRoom(roomType, gu.RoomTypeConfig(roomType).MaxPlayers, opts)
+	if err != nil {
+		gu.logger.Warnf("Failed to create private room for player %d: %v", opts.OwnerID, err)
+		return nil, err
+	}
+
+	if err := gu.initRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// AuthorizeInvite 驗證邀請碼，返回私人房間 ID
+func (gu *GameUsecase) AuthorizeInvite(ctx context.Context, code, password string, playerID int64) (string, error) {
+	return gu.roomManager.AuthorizeInvite(code, password, playerID)
+}
+
+// SetRoomLocked 房主鎖定或解鎖私人房間
+func (gu *GameUsecase) SetRoomLocked(ctx context.Context, roomID string, ownerID int64, locked bool) error {
+	return gu.roomManager.SetRoomLocked(roomID, ownerID, locked)
+}
+
+// KickPlayer 房主踢出玩家；已入座的玩家需要由調用方調用 LeaveRoom 結算
+func (gu *GameUsecase) KickPlayer(ctx context.Context, roomID string, ownerID, targetID int64) (bool, error) {
+	return gu.roomManager.KickPlayer(roomID, ownerID, targetID)
+}
+
+// PrivateRoomInfo 獲取私人房間信息，公開房間返回 false
+func (gu *GameUsecase) PrivateRoomInfo(roomID string) (PrivateRoomInfo, bool) {
+	return gu.roomManager.PrivateRoomInfo(roomID)
+}
+
+// CloseIdlePrivateRooms 關閉空閒超時的私人房間，返回已關閉的房間 ID
+func (gu *GameUsecase) CloseIdlePrivateRooms(ctx context.Context, now time.Time) []string {
+	var closed []string
+	for _, roomID := range gu.roomManager.IdlePrivateRooms(now) {
+		// CloseIdleRoom 在鎖內重新檢查房間是否為空，避免和加入的玩家競爭
+		if err := gu.CloseIdleRoom(ctx, roomID); err != nil {
+			gu.logger.Debugf("Skip closing private room %s: %v", roomID, err)
+			continue
+		}
+		closed = append(closed, roomID)
+	}
+	return closed
+}
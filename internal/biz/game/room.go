@@ -22,6 +22,14 @@ type RoomManager struct {
 	mathModel        *MathModel
 	inventoryManager *InventoryManager
 	rtpController    *RTPController
+	lifecycle        *eventDispatcher[RoomTransition]
+	seatEvents       *eventDispatcher[SeatEvent]
+
+	configMu sync.RWMutex
+	configs  map[RoomType]RoomConfig // 各房間類型當前的配置，見 room_configs.go
+
+	eventMu sync.RWMutex
+	events  map[string]*RoomEvent // 正在進行的活動，見 room_events.go
 }
 
 // NewRoomManager 創建房間管理器
@@ -33,6 +41,10 @@ func NewRoomManager(logger logger.Logger, spawner *FishSpawner, mathModel *MathM
 		mathModel:        mathModel,
 		inventoryManager: im,
 		rtpController:    rc,
+		lifecycle:        newEventDispatcher[RoomTransition]("Room transition", logger.With("component", "room_lifecycle")),
+		seatEvents:       newEventDispatcher[SeatEvent]("Seat event", logger.With("component", "room_seats")),
+		configs:          defaultRoomConfigs(),
+		events:           make(map[string]*RoomEvent),
 	}
 }
 
@@ -41,7 +53,20 @@ func (rm *RoomManager) CreateRoom(roomType RoomType, maxPlayers int32) (*Room, e
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	roomID := fmt.Sprintf("room_%s_%d", roomType, time.Now().Unix())
+	return rm.createRoom(roomType, maxPlayers, nil), nil
+}
+
+// createRoom 創建房間並啟動遊戲循環，private 為 nil 時為公開房間，調用方需持有 rm.mu
+func (rm *RoomManager) createRoom(roomType RoomType, maxPlayers int32, private *PrivateRoom) *Room {
+	prefix := "room"
+	if private != nil {
+		prefix = "private"
+	}
+	roomID := fmt.Sprintf("%s_%s_%d", prefix, roomType, time.Now().Unix())
+	// 同一秒內創建多個房間時追加序號，避免覆蓋已有房間
+	for n := 2; rm.rooms[roomID] != nil; n++ {
+		roomID = fmt.Sprintf("%s_%s_%d_%d", prefix, roomType, time.Now().Unix(), n)
+	}
 	config := rm.getRoomConfig(roomType)
 
 	// 使用配置中的 MaxPlayers，如果配置中有的话，否则使用传入的参数
@@ -59,22 +84,27 @@ func (rm *RoomManager) CreateRoom(roomType RoomType, maxPlayers int32) (*Room, e
 		Seats:      make([]int64, seatCount), // 初始化座位切片，默认值为0表示空座位
 		Fishes:     make(map[int64]*Fish),
 		Bullets:    make(map[int64]*Bullet),
-		Status:     RoomStatusWaiting,
+		Spectators: make(map[int64]*Spectator),
+		Private:    private,
+		Status:     RoomStatusCreated,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 		Config:     config,
 	}
+	if private != nil {
+		room.Name = fmt.Sprintf("%s私人房間", roomType)
+	}
 
 	rm.rooms[roomID] = room
-	rm.logger.Infof("Created room: %s, type: %s, seats: %d", roomID, roomType, seatCount)
+	rm.logger.Infof("Created room: %s, type: %s, seats: %d, private: %v", roomID, roomType, seatCount, private != nil)
 
 	// 立即啟動遊戲循環，不等待玩家加入
 	// 魚應該一直游動，不管有沒有玩家
-	room.Status = RoomStatusPlaying
+	rm.transition(room, RoomStatusWaiting, "game loop started")
 	go rm.startRoomGameLoop(room)
 	rm.logger.Infof("Game loop started for room: %s", roomID)
 
-	return room, nil
+	return room
 }
 
 // GetRoom 獲取房間
@@ -84,7 +114,7 @@ func (rm *RoomManager) GetRoom(roomID string) (*Room, error) {
 
 	room, exists := rm.rooms[roomID]
 	if !exists {
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 	
 	return room, nil
@@ -97,18 +127,29 @@ func (rm *RoomManager) JoinRoom(roomID string, player *Player) error {
 
 	room, exists := rm.rooms[roomID]
 	if !exists {
-		return fmt.Errorf("room not found: %s", roomID)
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	if room.Status == RoomStatusDraining {
+		return fmt.Errorf("%w: %s", ErrRoomDraining, roomID)
+	}
+
+	// 私人房間只允許房主和通過邀請碼驗證的玩家加入
+	if err := room.Private.admit(player.ID); err != nil {
+		return fmt.Errorf("%w: %s", err, roomID)
 	}
 
 	// 使用新的座位管理检查房间是否已满
 	if room.IsFull() {
-		return fmt.Errorf("room is full, no available seats")
+		return ErrRoomFull
 	}
 
-	// 檢查玩家是否已在其他房間
+	// 檢查玩家是否已在其他房間（在本房間觀戰的玩家可以直接入座）
 	for _, existingRoom := range rm.rooms {
 		if _, playerExists := existingRoom.Players[player.ID]; playerExists {
-			return fmt.Errorf("player already in room: %s", existingRoom.ID)
+			return fmt.Errorf("%w: %s", ErrPlayerAlreadyInRoom, existingRoom.ID)
+		}
+		if _, spectating := existingRoom.Spectators[player.ID]; spectating && existingRoom.ID != roomID {
+			return fmt.Errorf("%w: %s", ErrPlayerSpectating, existingRoom.ID)
 		}
 	}
 
@@ -118,12 +159,23 @@ func (rm *RoomManager) JoinRoom(roomID string, player *Player) error {
 		return fmt.Errorf("failed to allocate seat: %w", err)
 	}
 
+	delete(room.Spectators, player.ID)
+
 	player.RoomID = roomID
 	player.SeatID = seatID
 	player.Status = PlayerStatusPlaying
 	player.JoinTime = time.Now()
+	player.LastActionAt = player.JoinTime
+	player.ReservedUntil = time.Time{}
 	room.Players[player.ID] = player
 	room.UpdatedAt = time.Now()
+	if room.Private != nil {
+		room.Private.EmptySince = time.Time{}
+	}
+	if room.Status == RoomStatusWaiting || room.Status == RoomStatusPaused {
+		rm.transition(room, RoomStatusPlaying, "player joined")
+	}
+	rm.emitSeat(room, newSeatEvent(SeatEventTaken, player.ID, seatID))
 
 	// 遊戲循環已經在房間創建時啟動，不需要在這裡再次啟動
 
@@ -138,15 +190,18 @@ func (rm *RoomManager) LeaveRoom(roomID string, playerID int64) error {
 
 	room, exists := rm.rooms[roomID]
 	if !exists {
-		return fmt.Errorf("room not found: %s", roomID)
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	player, playerExists := room.Players[playerID]
 	if !playerExists {
-		return fmt.Errorf("player not in room")
+		return ErrPlayerNotInRoom
 	}
 
+	rm.cancelSwaps(room, playerID, "player left")
+
 	// 释放座位
+	seatID := player.SeatID
 	if player.SeatID >= 0 && player.SeatID < len(room.Seats) {
 		if err := room.ReleaseSeat(player.SeatID); err != nil {
 			rm.logger.Warnf("Failed to release seat %d for player %d: %v", player.SeatID, playerID, err)
@@ -159,7 +214,15 @@ func (rm *RoomManager) LeaveRoom(roomID string, playerID int64) error {
 	player.RoomID = ""
 	player.SeatID = -1 // 重置座位ID
 	player.Status = PlayerStatusIdle
+	player.ReservedUntil = time.Time{}
 	room.UpdatedAt = time.Now()
+	if room.Private != nil && len(room.Players) == 0 {
+		room.Private.EmptySince = room.UpdatedAt
+	}
+	if len(room.Players) == 0 && (room.Status == RoomStatusPlaying || room.Status == RoomStatusPaused) {
+		rm.transition(room, RoomStatusWaiting, "last player left")
+	}
+	rm.emitSeat(room, newSeatEvent(SeatEventReleased, playerID, seatID))
 
 	// 遊戲循環會繼續運行，即使沒有玩家
 	// 魚會繼續游動，等待新玩家加入
@@ -175,20 +238,20 @@ func (rm *RoomManager) FireBullet(roomID string, playerID int64, direction float
 	room, exists := rm.rooms[roomID]
 	if !exists {
 		rm.mu.RUnlock()
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	player, playerExists := room.Players[playerID]
 	if !playerExists {
 		rm.mu.RUnlock()
-		return nil, fmt.Errorf("player not in room")
+		return nil, ErrPlayerNotInRoom
 	}
 
 	// Calculate bullet cost
 	bulletCost := int64(float64(power) * room.Config.BulletCostMultiplier)
 	if player.Balance < bulletCost {
 		rm.mu.RUnlock()
-		return nil, fmt.Errorf("insufficient balance")
+		return nil, ErrInsufficientBalance
 	}
 	rm.mu.RUnlock()
 
@@ -214,21 +277,22 @@ func (rm *RoomManager) FireBullet(roomID string, playerID int64, direction float
 	// Double-check room and player still exist
 	room, exists = rm.rooms[roomID]
 	if !exists {
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	player, playerExists = room.Players[playerID]
 	if !playerExists {
-		return nil, fmt.Errorf("player not in room")
+		return nil, ErrPlayerNotInRoom
 	}
 
 	// Final balance check
 	if player.Balance < bulletCost {
-		return nil, fmt.Errorf("insufficient balance")
+		return nil, ErrInsufficientBalance
 	}
 
 	// Apply changes
 	player.Balance -= bulletCost
+	player.LastActionAt = time.Now()
 	room.Bullets[bulletID] = bullet
 	room.UpdatedAt = time.Now()
 
@@ -251,22 +315,22 @@ func (rm *RoomManager) ProcessBulletHit(roomID string, bulletID int64, fishID in
 
 	room, exists := rm.rooms[roomID]
 	if !exists {
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	bullet, bulletExists := room.Bullets[bulletID]
 	if !bulletExists {
-		return nil, fmt.Errorf("bullet not found")
+		return nil, ErrBulletNotFound
 	}
 
 	fish, fishExists := room.Fishes[fishID]
 	if !fishExists {
-		return nil, fmt.Errorf("fish not found")
+		return nil, ErrFishNotFound
 	}
 
 	player, playerExists := room.Players[bullet.PlayerID]
 	if !playerExists {
-		return nil, fmt.Errorf("player not found")
+		return nil, ErrPlayerNotFound
 	}
 
 	// 1. Calculate the potential outcome from the math model
@@ -286,8 +350,10 @@ func (rm *RoomManager) ProcessBulletHit(roomID string, bulletID int64, fishID in
 			fish.Status = FishStatusDead
 			delete(room.Fishes, fishID)
 
-			player.Balance += potentialHit.Reward
+			// 活動加成不計入庫存，RTP 控制不會因活動而收緊
 			rm.inventoryManager.AddWin(room.Type, potentialHit.Reward)
+			rm.applyRewardBoost(room.Type, potentialHit)
+			player.Balance += potentialHit.Reward
 
 			rm.logger.Infof("RTP APPROVED kill. Player %d killed fish %d, reward: %d", player.ID, fishID, potentialHit.Reward)
 			return potentialHit, nil
@@ -343,11 +409,17 @@ func (rm *RoomManager) startRoomGameLoop(room *Room) {
 	for {
 		select {
 		case <-ticker.C:
-			rm.updateRoom(room)
+			// 新的房間配置在週期之間套用，同一週期內的生成和命中判定使用同一份配置
+			rm.applyPendingConfig(room)
+
+			// 暫停的房間不更新魚和子彈
+			if rm.roomStatus(room) != RoomStatusPaused {
+				rm.updateRoom(room)
+			}
 
 			// 檢查房間是否應該關閉
 			// 注意：即使沒有玩家，遊戲循環也應該繼續，只有房間狀態為 Closed 時才停止
-			if room.Status == RoomStatusClosed {
+			if rm.roomStatus(room) == RoomStatusClosed {
 				rm.logger.Infof("Game loop ended for room %s", room.ID)
 				return
 			}
@@ -385,8 +457,13 @@ func (rm *RoomManager) updateRoom(room *Room) {
 				room.ID, fishCount, minFish, spawnCount, targetFishCount)
 			batchFishes = rm.spawner.BatchSpawnFish(spawnCount, room.Config)
 		}
-	} else if fishCount < maxFish {
-		// 正常情況下使用概率生成
+	}
+
+	// Boss 狂潮和魚潮風暴活動，沒有活動時使用概率生成
+	eventFish, tideWave, bossRush := rm.spawnEventFish(room, now, fishCount+len(batchFishes))
+	if bossRush {
+		newFish = eventFish
+	} else if fishCount >= minFish && fishCount < maxFish {
 		newFish = rm.spawner.TrySpawnFish(room.Config)
 	}
 
@@ -462,6 +539,14 @@ func (rm *RoomManager) updateRoom(room *Room) {
 			room.ID, len(batchFishes), len(room.Fishes))
 	}
 
+	// 魚潮風暴的魚在同一時刻生成，ID 可能重複，重複時順延
+	for _, fish := range tideWave {
+		for room.Fishes[fish.ID] != nil {
+			fish.ID++
+		}
+		room.Fishes[fish.ID] = fish
+	}
+
 	// Add formation fishes if spawned
 	if newFormation != nil {
 		for _, fish := range newFormation.Fishes {
@@ -524,11 +609,12 @@ func (rm *RoomManager) cleanupCompletedFormations(room *Room) {
 	}
 }
 
-// getRoomConfig 獲取房間配置
-func (rm *RoomManager) getRoomConfig(roomType RoomType) RoomConfig {
-	configs := map[RoomType]RoomConfig{
+// defaultRoomConfigs 各房間類型的內置默認配置（版本 0），room_configs 中的配置會覆蓋這些值
+func defaultRoomConfigs() map[RoomType]RoomConfig {
+	return map[RoomType]RoomConfig{
 		RoomTypeNovice: {
 			MaxPlayers:           4,    // 4人座位
+			MaxSpectators:        20,   // 觀戰人數上限
 			MinBet:               10,   // 0.1元
 			MaxBet:               100,  // 1元
 			BulletCostMultiplier: 1.0,
@@ -541,6 +627,7 @@ func (rm *RoomManager) getRoomConfig(roomType RoomType) RoomConfig {
 		},
 		RoomTypeIntermediate: {
 			MaxPlayers:           4,    // 4人座位
+			MaxSpectators:        20,   // 觀戰人數上限
 			MinBet:               100,  // 1元
 			MaxBet:               1000, // 10元
 			BulletCostMultiplier: 2.0,
@@ -553,6 +640,7 @@ func (rm *RoomManager) getRoomConfig(roomType RoomType) RoomConfig {
 		},
 		RoomTypeAdvanced: {
 			MaxPlayers:           4,    // 4人座位
+			MaxSpectators:        10,   // 觀戰人數上限
 			MinBet:               1000,  // 10元
 			MaxBet:               10000, // 100元
 			BulletCostMultiplier: 5.0,
@@ -565,6 +653,7 @@ func (rm *RoomManager) getRoomConfig(roomType RoomType) RoomConfig {
 		},
 		RoomTypeVIP: {
 			MaxPlayers:           4,    // 4人座位
+			MaxSpectators:        5,   // 觀戰人數上限
 			MinBet:               10000, // 100元
 			MaxBet:               100000, // 1000元
 			BulletCostMultiplier: 10.0,
@@ -576,8 +665,6 @@ func (rm *RoomManager) getRoomConfig(roomType RoomType) RoomConfig {
 			TargetRTP:            0.94, // VIP房RTP略低
 		},
 	}
-
-	return configs[roomType]
 }
 
 // ========================================
@@ -591,7 +678,7 @@ func (rm *RoomManager) SpawnFormationInRoom(roomID string, formationType FishFor
 
 	room, exists := rm.rooms[roomID]
 	if !exists {
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	formation := rm.spawner.TrySpawnFormation(room.Config, len(room.Players))
@@ -615,7 +702,7 @@ func (rm *RoomManager) SpawnSpecialFormationInRoom(roomID string, formationType
 
 	room, exists := rm.rooms[roomID]
 	if !exists {
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	formation := rm.spawner.SpawnSpecialFormation(formationType, routeID, fishTypeIDs, room.Config)
@@ -637,7 +724,7 @@ func (rm *RoomManager) SpawnSpecialFormationInRoom(roomID string, formationType
 func (rm *RoomManager) GetFormationsInRoom(roomID string) ([]*FishFormation, error) {
 	room, exists := rm.rooms[roomID]
 	if !exists {
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	formations := rm.spawner.GetFormationManager().GetAllFormations()
@@ -660,7 +747,7 @@ func (rm *RoomManager) GetFormationsInRoom(roomID string) ([]*FishFormation, err
 func (rm *RoomManager) StopFormationInRoom(roomID string, formationID string) error {
 	_, exists := rm.rooms[roomID]
 	if !exists {
-		return fmt.Errorf("room not found: %s", roomID)
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 
 	success := rm.spawner.GetFormationManager().StopFormation(formationID)
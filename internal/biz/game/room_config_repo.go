@@ -11,4 +11,17 @@ type RoomConfigRepo interface {
 
 	// GetAllRoomConfigs 获取所有房间配置
 	GetAllRoomConfigs(ctx context.Context) (map[string]*RoomConfig, error)
+
+	// CurrentRoomConfig 獲取房間類型的當前配置版本（包括已刪除的），沒有配置過時返回 nil
+	CurrentRoomConfig(ctx context.Context, roomType RoomType) (*RoomConfigVersion, error)
+
+	// ListRoomConfigs 獲取所有房間類型的當前配置版本（包括已刪除的）
+	ListRoomConfigs(ctx context.Context) ([]*RoomConfigVersion, error)
+
+	// SaveRoomConfig 保存新版本並記錄歷史，版本號為 expectedVersion+1（寫回 v.Version 和 v.CreatedAt）；
+	// 當前版本不等於 expectedVersion 時返回 ErrRoomConfigConflict
+	SaveRoomConfig(ctx context.Context, v *RoomConfigVersion, expectedVersion int64) error
+
+	// RoomConfigHistory 獲取房間類型最近的配置版本，按版本從新到舊排列
+	RoomConfigHistory(ctx context.Context, roomType RoomType, limit int) ([]*RoomConfigVersion, error)
 }
@@ -0,0 +1,431 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 房間配置版本和熱更新
+// ========================================
+//
+// room_configs 表中每種房間類型一行當前配置，每次修改版本號加一，舊版本保存在 room_config_versions。
+// 管理後台保存新版本後通過 RoomConfigBus 通知所有遊戲節點：新建房間立即使用新配置，
+// 運行中的房間在下一個遊戲循環週期開始時套用（座位數只影響新建房間）。
+// 刪除配置後房間類型回到內置默認配置，版本號仍然遞增，各節點只按版本號判斷新舊。
+
+// maxRoomSeats 房間座位數上限
+const maxRoomSeats = 8
+
+// roomTypes 所有房間類型，按下注範圍從低到高排列
+var roomTypes = []RoomType{RoomTypeNovice, RoomTypeIntermediate, RoomTypeAdvanced, RoomTypeVIP}
+
+// RoomTypes 所有房間類型
+func RoomTypes() []RoomType {
+	return append([]RoomType(nil), roomTypes...)
+}
+
+// knownRoomType 是否為已知的房間類型
+func knownRoomType(roomType RoomType) bool {
+	for _, rt := range roomTypes {
+		if rt == roomType {
+			return true
+		}
+	}
+	return false
+}
+
+// RoomConfigVersion 房間類型的一個配置版本
+type RoomConfigVersion struct {
+	RoomType    RoomType   `json:"room_type"`
+	Version     int64      `json:"version"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Active      bool       `json:"active"` // false 表示配置已刪除，房間使用內置默認配置
+	Config      RoomConfig `json:"config"`
+	UpdatedBy   string     `json:"updated_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Effective 遊戲節點實際使用的配置：已刪除的版本使用內置默認配置，版本號都為 v.Version
+func (v *RoomConfigVersion) Effective() RoomConfig {
+	config := v.Config
+	if !v.Active {
+		config, _ = DefaultRoomConfig(v.RoomType)
+	}
+	config.Version = v.Version
+	return config
+}
+
+// RoomConfigBus 房間配置變更通知（Redis pub/sub），消息可能丟失，遊戲節點需定期重新同步
+type RoomConfigBus interface {
+	// PublishRoomConfig 通知所有遊戲節點房間類型有新的配置版本
+	PublishRoomConfig(ctx context.Context, version *RoomConfigVersion) error
+	// SubscribeRoomConfigs 接收配置變更直到 ctx 取消或連接斷開
+	SubscribeRoomConfigs(ctx context.Context, handler func(version *RoomConfigVersion)) error
+}
+
+// DefaultRoomConfig 房間類型的內置默認配置，room_configs 中沒有啟用的配置時使用
+func DefaultRoomConfig(roomType RoomType) (RoomConfig, bool) {
+	config, ok := defaultRoomConfigs()[roomType]
+	return config, ok
+}
+
+// PlayCurrency 房間的遊戲幣種，沒有聲明時為主錢包幣種
+// 玩家入座時綁定該幣種的錢包，修改幣種只影響新建的房間
+func (c RoomConfig) PlayCurrency() string {
+	if c.Currency == "" {
+		return wallet.DefaultCurrency
+	}
+	return c.Currency
+}
+
+// ValidateRoomConfig 檢查配置是否可以用於房間，返回的錯誤包含所有不合法的字段
+func ValidateRoomConfig(config RoomConfig) error {
+	var problems []string
+	if config.MaxPlayers < 1 || config.MaxPlayers > maxRoomSeats {
+		problems = append(problems, fmt.Sprintf("max_players must be between 1 and %d", maxRoomSeats))
+	}
+	if config.MaxSpectators < 0 {
+		problems = append(problems, "max_spectators must not be negative")
+	}
+	if config.MinBet <= 0 {
+		problems = append(problems, "min_bet must be positive")
+	}
+	if config.MaxBet < config.MinBet {
+		problems = append(problems, "max_bet must not be less than min_bet")
+	}
+	if config.BulletCostMultiplier <= 0 {
+		problems = append(problems, "bullet_cost_multiplier must be positive")
+	}
+	if config.FishSpawnRate <= 0 || config.FishSpawnRate > 1 {
+		problems = append(problems, "fish_spawn_rate must be in (0, 1]")
+	}
+	if config.MaxFishCount <= 0 {
+		problems = append(problems, "max_fish_count must be positive")
+	}
+	if config.MinFishCount < 0 || config.MinFishCount > config.MaxFishCount {
+		problems = append(problems, "min_fish_count must be between 0 and max_fish_count")
+	}
+	if config.RoomWidth <= 0 || config.RoomHeight <= 0 {
+		problems = append(problems, "room_width and room_height must be positive")
+	}
+	if config.TargetRTP < 0.5 || config.TargetRTP > 1 {
+		problems = append(problems, "target_rtp must be between 0.5 and 1")
+	}
+	if config.Currency != "" {
+		if _, ok := wallet.LookupCurrency(config.Currency); !ok {
+			problems = append(problems, fmt.Sprintf("currency %q is not a supported currency", config.Currency))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidRoomConfig, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ========================================
+// RoomManager 配置熱更新
+// ========================================
+
+// getRoomConfig 獲取房間類型當前的配置
+func (rm *RoomManager) getRoomConfig(roomType RoomType) RoomConfig {
+	rm.configMu.RLock()
+	defer rm.configMu.RUnlock()
+	return rm.configs[roomType]
+}
+
+// RoomConfigVersions 各房間類型當前的配置版本
+func (rm *RoomManager) RoomConfigVersions() map[RoomType]int64 {
+	rm.configMu.RLock()
+	defer rm.configMu.RUnlock()
+
+	versions := make(map[RoomType]int64, len(rm.configs))
+	for roomType, config := range rm.configs {
+		versions[roomType] = config.Version
+	}
+	return versions
+}
+
+// ApplyRoomConfig 更新房間類型的配置並安排運行中的房間在下一個週期套用，返回安排的房間數；
+// 版本不比當前版本新時忽略並返回 false
+func (rm *RoomManager) ApplyRoomConfig(roomType RoomType, config RoomConfig) (int, bool, error) {
+	if !knownRoomType(roomType) {
+		return 0, false, fmt.Errorf("%w: %s", ErrUnknownRoomType, roomType)
+	}
+	if err := ValidateRoomConfig(config); err != nil {
+		return 0, false, err
+	}
+
+	rm.configMu.Lock()
+	if current := rm.configs[roomType]; config.Version <= current.Version {
+		rm.configMu.Unlock()
+		return 0, false, nil
+	}
+	rm.configs[roomType] = config
+	rm.configMu.Unlock()
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	scheduled := 0
+	for _, room := range rm.rooms {
+		if room.Type == roomType && rm.scheduleConfig(room) {
+			scheduled++
+		}
+	}
+	rm.logger.Infof("Room config of %s updated to version %d, %d rooms will apply it on next tick", roomType, config.Version, scheduled)
+	return scheduled, true, nil
+}
+
+// scheduleConfig 房間的配置比當前配置舊時安排在下一個週期套用，調用方需持有 rm.mu
+func (rm *RoomManager) scheduleConfig(room *Room) bool {
+	if room.Status == RoomStatusClosed {
+		return false
+	}
+	config := rm.getRoomConfig(room.Type)
+	if config.Version <= room.Config.Version {
+		return false
+	}
+	room.PendingConfig = &config
+	return true
+}
+
+// applyPendingConfig 在遊戲循環週期開始時套用等待中的配置，運行中房間的座位數和遊戲幣種保持不變
+func (rm *RoomManager) applyPendingConfig(room *Room) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	pending := room.PendingConfig
+	if pending == nil {
+		return
+	}
+	room.PendingConfig = nil
+	if pending.Version <= room.Config.Version {
+		return
+	}
+
+	from := room.Config.Version
+	config := *pending
+	config.MaxPlayers = room.MaxPlayers
+	config.Currency = room.Config.Currency // 已入座玩家綁定了原幣種的錢包
+	room.Config = config
+	room.UpdatedAt = time.Now()
+	rm.logger.Infof("Room %s applied config version %d (was %d)", room.ID, config.Version, from)
+}
+
+// RoomCurrency 返回房間的遊戲幣種
+func (rm *RoomManager) RoomCurrency(roomID string) (string, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	room, exists := rm.rooms[roomID]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	return room.Config.PlayCurrency(), nil
+}
+
+// ========================================
+// GameUsecase 配置熱更新用例
+// ========================================
+
+// ApplyRoomConfig 套用房間類型的配置版本，返回安排套用的運行中房間數
+func (gu *GameUsecase) ApplyRoomConfig(version *RoomConfigVersion) (int, bool, error) {
+	return gu.roomManager.ApplyRoomConfig(version.RoomType, version.Effective())
+}
+
+// RoomConfigVersions 各房間類型當前的配置版本
+func (gu *GameUsecase) RoomConfigVersions() map[RoomType]int64 {
+	return gu.roomManager.RoomConfigVersions()
+}
+
+// AppliedConfigVersion 房間已套用的配置版本
+func (gu *GameUsecase) AppliedConfigVersion(roomID string) int64 {
+	gu.roomManager.mu.RLock()
+	defer gu.roomManager.mu.RUnlock()
+
+	if room, ok := gu.roomManager.rooms[roomID]; ok {
+		return room.Config.Version
+	}
+	return 0
+}
+
+// ========================================
+// RoomConfigUsecase 管理後台配置管理
+// ========================================
+
+// RoomConfigUsecase 房間配置的增刪改查，保存後通知所有遊戲節點
+type RoomConfigUsecase struct {
+	repo   RoomConfigRepo
+	bus    RoomConfigBus
+	logger logger.Logger
+}
+
+// NewRoomConfigUsecase 創建房間配置用例
+func NewRoomConfigUsecase(repo RoomConfigRepo, bus RoomConfigBus, logger logger.Logger) *RoomConfigUsecase {
+	return &RoomConfigUsecase{
+		repo:   repo,
+		bus:    bus,
+		logger: logger.With("component", "room_config_usecase"),
+	}
+}
+
+// List 所有房間類型的當前配置，沒有配置過的類型返回版本 0 的內置默認配置
+func (uc *RoomConfigUsecase) List(ctx context.Context) ([]*RoomConfigVersion, error) {
+	stored, err := uc.repo.ListRoomConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[RoomType]*RoomConfigVersion, len(stored))
+	for _, v := range stored {
+		byType[v.RoomType] = v
+	}
+
+	versions := make([]*RoomConfigVersion, 0, len(roomTypes))
+	for _, roomType := range roomTypes {
+		if v, ok := byType[roomType]; ok {
+			versions = append(versions, v)
+			continue
+		}
+		versions = append(versions, builtinRoomConfig(roomType))
+	}
+	return versions, nil
+}
+
+// Get 房間類型的當前配置
+func (uc *RoomConfigUsecase) Get(ctx context.Context, roomType RoomType) (*RoomConfigVersion, error) {
+	if !knownRoomType(roomType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRoomType, roomType)
+	}
+	current, err := uc.repo.CurrentRoomConfig(ctx, roomType)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return builtinRoomConfig(roomType), nil
+	}
+	return current, nil
+}
+
+// History 房間類型最近的配置版本，按版本從新到舊排列
+func (uc *RoomConfigUsecase) History(ctx context.Context, roomType RoomType, limit int) ([]*RoomConfigVersion, error) {
+	if !knownRoomType(roomType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRoomType, roomType)
+	}
+	return uc.repo.RoomConfigHistory(ctx, roomType, limit)
+}
+
+// Create 為沒有啟用配置的房間類型創建配置
+func (uc *RoomConfigUsecase) Create(ctx context.Context, v *RoomConfigVersion) error {
+	current, err := uc.current(ctx, v.RoomType)
+	if err != nil {
+		return err
+	}
+	if current != nil && current.Active {
+		return fmt.Errorf("%w: %s", ErrRoomConfigExists, v.RoomType)
+	}
+
+	var expected int64
+	if current != nil {
+		expected = current.Version
+	}
+	v.Active = true
+	return uc.save(ctx, v, expected)
+}
+
+// Update 保存房間類型的新配置版本；expectedVersion 不為 0 時必須等於當前版本，避免覆蓋其他人的修改
+func (uc *RoomConfigUsecase) Update(ctx context.Context, v *RoomConfigVersion, expectedVersion int64) error {
+	current, err := uc.current(ctx, v.RoomType)
+	if err != nil {
+		return err
+	}
+	if current == nil || !current.Active {
+		return fmt.Errorf("%w: %s", ErrRoomConfigNotFound, v.RoomType)
+	}
+	if expectedVersion != 0 && expectedVersion != current.Version {
+		return fmt.Errorf("%w: %s is at version %d, not %d", ErrRoomConfigConflict, v.RoomType, current.Version, expectedVersion)
+	}
+
+	if v.Name == "" {
+		v.Name = current.Name
+	}
+	if v.Description == "" {
+		v.Description = current.Description
+	}
+	v.Active = true
+	return uc.save(ctx, v, current.Version)
+}
+
+// Delete 刪除房間類型的配置，遊戲節點回到內置默認配置；配置保留在歷史中
+func (uc *RoomConfigUsecase) Delete(ctx context.Context, roomType RoomType, expectedVersion int64, updatedBy string) (*RoomConfigVersion, error) {
+	current, err := uc.current(ctx, roomType)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || !current.Active {
+		return nil, fmt.Errorf("%w: %s", ErrRoomConfigNotFound, roomType)
+	}
+	if expectedVersion != 0 && expectedVersion != current.Version {
+		return nil, fmt.Errorf("%w: %s is at version %d, not %d", ErrRoomConfigConflict, roomType, current.Version, expectedVersion)
+	}
+
+	deleted := *current
+	deleted.Active = false
+	deleted.UpdatedBy = updatedBy
+	if err := uc.save(ctx, &deleted, current.Version); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// current 房間類型在存儲中的當前配置，沒有時返回 nil
+func (uc *RoomConfigUsecase) current(ctx context.Context, roomType RoomType) (*RoomConfigVersion, error) {
+	if !knownRoomType(roomType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRoomType, roomType)
+	}
+	return uc.repo.CurrentRoomConfig(ctx, roomType)
+}
+
+// save 校驗並保存新版本，然後通知遊戲節點；通知失敗時各節點在下一次重新同步時更新
+func (uc *RoomConfigUsecase) save(ctx context.Context, v *RoomConfigVersion, expectedVersion int64) error {
+	if v.Active {
+		if err := ValidateRoomConfig(v.Config); err != nil {
+			return err
+		}
+	}
+	if v.Name == "" {
+		v.Name = fmt.Sprintf("%s房間", v.RoomType)
+	}
+
+	if err := uc.repo.SaveRoomConfig(ctx, v, expectedVersion); err != nil {
+		return err
+	}
+	v.Config.Version = v.Version
+	uc.logger.Infof("Room config of %s saved as version %d by %s (active=%v)", v.RoomType, v.Version, v.UpdatedBy, v.Active)
+
+	if uc.bus == nil {
+		return nil
+	}
+	if err := uc.bus.PublishRoomConfig(ctx, v); err != nil {
+		uc.logger.Warnf("Failed to publish room config %s version %d, game nodes will pick it up on resync: %v", v.RoomType, v.Version, err)
+	}
+	return nil
+}
+
+// builtinRoomConfig 沒有存儲配置的房間類型使用的版本 0 內置配置
+func builtinRoomConfig(roomType RoomType) *RoomConfigVersion {
+	config, _ := DefaultRoomConfig(roomType)
+	return &RoomConfigVersion{
+		RoomType: roomType,
+		Name:     fmt.Sprintf("%s房間", roomType),
+		Active:   true,
+		Config:   config,
+	}
+}
@@ -0,0 +1,641 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// ========================================
+// 定時房間活動
+// ========================================
+//
+// 管理後台在 room_event_schedules 中配置按房間類型生效的定時活動（雙倍獎勵、Boss 狂潮、魚潮風暴），
+// 重複規則按活動時區的日曆計算（每天、每週的某幾天、每月的同一天），夏令時切換時保持當地開始時間。
+// 遊戲節點的排程器定期加載排程並計算正在進行的場次交給 RoomManager：
+//   - double_reward：RTP 控制批准擊殺後按倍數增加獎勵，加成部分不計入庫存，RTP 控制不會因活動而收緊
+//   - boss_rush：按 GetBossRushConfig 的魚體型偏好生成魚
+//   - tide_storm：按間隔生成指定魚種的魚群，不受房間魚數上限限制（最多為上限的兩倍）
+// 受活動加成的獎勵在錢包交易、遊戲事件和遊戲記錄中標記活動場次。
+
+// RoomEventType 活動類型
+type RoomEventType string
+
+const (
+	RoomEventDoubleReward RoomEventType = "double_reward" // 獎勵加成
+	RoomEventBossRush     RoomEventType = "boss_rush"     // Boss 狂潮
+	RoomEventTideStorm    RoomEventType = "tide_storm"    // 魚潮風暴
+)
+
+// RecurrenceFrequency 重複頻率
+type RecurrenceFrequency string
+
+const (
+	RecurrenceNone    RecurrenceFrequency = "none"
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+)
+
+const (
+	// maxRoomEventDuration 單場活動的最長時間
+	maxRoomEventDuration = 7 * 24 * time.Hour
+	// maxRewardMultiplier 獎勵加成倍數上限
+	maxRewardMultiplier = 10.0
+	// maxOccurrenceScan 計算場次時最多檢查的候選開始時間，避免錯誤的規則造成長時間循環
+	maxOccurrenceScan = 100000
+
+	defaultTideWaveSize     = 10
+	defaultTideWaveInterval = 3
+)
+
+// Recurrence 日曆式重複規則
+type Recurrence struct {
+	Frequency RecurrenceFrequency `json:"frequency"`
+	Interval  int                 `json:"interval,omitempty"` // 每隔幾天、幾週或幾個月，默認 1
+	Weekdays  []time.Weekday      `json:"weekdays,omitempty"` // 每週重複的星期（0 為週日），默認為首場的星期
+	Until     *time.Time          `json:"until,omitempty"`    // 最後一場的開始時間不晚於此時間
+	Count     int                 `json:"count,omitempty"`    // 最多舉行的場次，0 表示不限
+}
+
+// RoomEventSchedule 定時活動排程
+type RoomEventSchedule struct {
+	ID              int64         `json:"id"`
+	Name            string        `json:"name"`
+	Type            RoomEventType `json:"type"`
+	RoomTypes       []RoomType    `json:"room_types,omitempty"` // 生效的房間類型，空表示所有類型
+	StartAt         time.Time     `json:"start_at"`             // 首場開始時間
+	DurationMinutes int           `json:"duration_minutes"`
+	Timezone        string        `json:"timezone,omitempty"` // IANA 時區，重複規則按此時區的日曆計算，默認 UTC
+	Recurrence      Recurrence    `json:"recurrence"`
+
+	RewardMultiplier float64 `json:"reward_multiplier,omitempty"` // double_reward 的獎勵倍數
+	TideFishTypeID   int32   `json:"tide_fish_type_id,omitempty"` // tide_storm 的魚種
+	TideWaveSize     int     `json:"tide_wave_size,omitempty"`    // tide_storm 每波的魚數
+	TideWaveSeconds  int     `json:"tide_wave_seconds,omitempty"` // tide_storm 每波的間隔
+
+	Announcement string    `json:"announcement,omitempty"` // 活動開始時在房間和大廳顯示的公告
+	Enabled      bool      `json:"enabled"`
+	UpdatedBy    string    `json:"updated_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RoomEvent 一場正在進行或即將開始的活動
+type RoomEvent struct {
+	ID               string        `json:"id"` // 排程 ID 和開始時間，同一場活動在所有節點上相同
+	ScheduleID       int64         `json:"schedule_id"`
+	Name             string        `json:"name"`
+	Type             RoomEventType `json:"type"`
+	RoomTypes        []RoomType    `json:"room_types,omitempty"`
+	StartsAt         time.Time     `json:"starts_at"`
+	EndsAt           time.Time     `json:"ends_at"`
+	RewardMultiplier float64       `json:"reward_multiplier,omitempty"`
+	TideFishTypeID   int32         `json:"tide_fish_type_id,omitempty"`
+	TideWaveSize     int           `json:"tide_wave_size,omitempty"`
+	TideWaveInterval time.Duration `json:"-"`
+	Announcement     string        `json:"announcement,omitempty"`
+}
+
+// Affects 活動是否對房間類型生效
+func (e *RoomEvent) Affects(roomType RoomType) bool {
+	if len(e.RoomTypes) == 0 {
+		return true
+	}
+	for _, rt := range e.RoomTypes {
+		if rt == roomType {
+			return true
+		}
+	}
+	return false
+}
+
+// RoomEventRepo 活動排程存儲
+type RoomEventRepo interface {
+	ListRoomEventSchedules(ctx context.Context) ([]*RoomEventSchedule, error)
+	GetRoomEventSchedule(ctx context.Context, id int64) (*RoomEventSchedule, error)
+	// CreateRoomEventSchedule 創建排程並寫回 ID 和時間戳
+	CreateRoomEventSchedule(ctx context.Context, schedule *RoomEventSchedule) error
+	// UpdateRoomEventSchedule 更新排程，排程不存在時返回 ErrRoomEventNotFound
+	UpdateRoomEventSchedule(ctx context.Context, schedule *RoomEventSchedule) error
+	// DeleteRoomEventSchedule 刪除排程，排程不存在時返回 ErrRoomEventNotFound
+	DeleteRoomEventSchedule(ctx context.Context, id int64) error
+}
+
+// ========================================
+// 重複規則和場次計算
+// ========================================
+
+// Duration 單場活動的時間
+func (s *RoomEventSchedule) Duration() time.Duration {
+	return time.Duration(s.DurationMinutes) * time.Minute
+}
+
+// location 排程的時區，無效時使用 UTC（保存前已校驗）
+func (s *RoomEventSchedule) location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// interval 重複間隔，默認 1
+func (r Recurrence) interval() int {
+	if r.Interval <= 0 {
+		return 1
+	}
+	return r.Interval
+}
+
+// weekdays 每週重複的星期，按週日到週六排序
+func (s *RoomEventSchedule) weekdays(first time.Time) []time.Weekday {
+	if len(s.Recurrence.Weekdays) == 0 {
+		return []time.Weekday{first.Weekday()}
+	}
+	days := append([]time.Weekday(nil), s.Recurrence.Weekdays...)
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	return days
+}
+
+// candidate 第 n 個候選開始時間，weekly 時 n 按「週 × 星期」展開；ok 為 false 表示該候選不存在（如 2 月 30 日）
+func (s *RoomEventSchedule) candidate(first time.Time, n int) (time.Time, bool) {
+	step := s.Recurrence.interval()
+	hour, min, sec := first.Clock()
+
+	switch s.Recurrence.Frequency {
+	case RecurrenceDaily:
+		return first.AddDate(0, 0, n*step), true
+	case RecurrenceWeekly:
+		days := s.weekdays(first)
+		week, index := n/len(days), n%len(days)
+		weekStart := first.AddDate(0, 0, -int(first.Weekday())+week*7*step)
+		day := weekStart.AddDate(0, 0, int(days[index]))
+		start := time.Date(day.Year(), day.Month(), day.Day(), hour, min, sec, 0, first.Location())
+		return start, !start.Before(first)
+	case RecurrenceMonthly:
+		start := time.Date(first.Year(), first.Month()+time.Month(n*step), first.Day(), hour, min, sec, 0, first.Location())
+		return start, start.Day() == first.Day()
+	default:
+		return first, n == 0
+	}
+}
+
+// Occurrences 開始於 to 之前、結束於 from 之後的所有場次的開始時間
+func (s *RoomEventSchedule) Occurrences(from, to time.Time) []time.Time {
+	first := s.StartAt.In(s.location())
+	duration := s.Duration()
+
+	var starts []time.Time
+	held := 0
+	for n := 0; n < maxOccurrenceScan; n++ {
+		start, ok := s.candidate(first, n)
+		if s.Recurrence.Frequency == RecurrenceNone || s.Recurrence.Frequency == "" {
+			if n > 0 {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if !start.Before(to) {
+			break
+		}
+		if s.Recurrence.Until != nil && start.After(*s.Recurrence.Until) {
+			break
+		}
+		held++
+		if s.Recurrence.Count > 0 && held > s.Recurrence.Count {
+			break
+		}
+		if start.Add(duration).After(from) {
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// Event 排程在 start 開始的一場活動
+func (s *RoomEventSchedule) Event(start time.Time) *RoomEvent {
+	event := &RoomEvent{
+		ID:           fmt.Sprintf("%d-%d", s.ID, start.Unix()),
+		ScheduleID:   s.ID,
+		Name:         s.Name,
+		Type:         s.Type,
+		RoomTypes:    append([]RoomType(nil), s.RoomTypes...),
+		StartsAt:     start,
+		EndsAt:       start.Add(s.Duration()),
+		Announcement: s.Announcement,
+	}
+	switch s.Type {
+	case RoomEventDoubleReward:
+		event.RewardMultiplier = s.RewardMultiplier
+	case RoomEventTideStorm:
+		event.TideFishTypeID = s.TideFishTypeID
+		event.TideWaveSize = s.TideWaveSize
+		event.TideWaveInterval = time.Duration(s.TideWaveSeconds) * time.Second
+	}
+	return event
+}
+
+// RoomEventsBetween 啟用的排程在 [from, to) 內進行的所有場次，按開始時間排列
+func RoomEventsBetween(schedules []*RoomEventSchedule, from, to time.Time) []*RoomEvent {
+	var events []*RoomEvent
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+		for _, start := range schedule.Occurrences(from, to) {
+			events = append(events, schedule.Event(start))
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].StartsAt.Equal(events[j].StartsAt) {
+			return events[i].ID < events[j].ID
+		}
+		return events[i].StartsAt.Before(events[j].StartsAt)
+	})
+	return events
+}
+
+// ActiveRoomEventsAt 啟用的排程在 now 正在進行的場次
+func ActiveRoomEventsAt(schedules []*RoomEventSchedule, now time.Time) []*RoomEvent {
+	var active []*RoomEvent
+	for _, event := range RoomEventsBetween(schedules, now, now.Add(time.Nanosecond)) {
+		if !event.StartsAt.After(now) && event.EndsAt.After(now) {
+			active = append(active, event)
+		}
+	}
+	return active
+}
+
+// ========================================
+// 排程校驗
+// ========================================
+
+// normalize 填充默認值
+func (s *RoomEventSchedule) normalize() {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if s.Recurrence.Frequency == "" {
+		s.Recurrence.Frequency = RecurrenceNone
+	}
+	if s.Recurrence.Interval <= 0 {
+		s.Recurrence.Interval = 1
+	}
+	if s.Type == RoomEventTideStorm {
+		if s.TideWaveSize == 0 {
+			s.TideWaveSize = defaultTideWaveSize
+		}
+		if s.TideWaveSeconds == 0 {
+			s.TideWaveSeconds = defaultTideWaveInterval
+		}
+	}
+}
+
+// ValidateRoomEventSchedule 檢查排程是否可以保存，返回的錯誤包含所有不合法的字段
+func ValidateRoomEventSchedule(s *RoomEventSchedule) error {
+	var problems []string
+	if s.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	for _, rt := range s.RoomTypes {
+		if !knownRoomType(rt) {
+			problems = append(problems, fmt.Sprintf("unknown room type %q", rt))
+		}
+	}
+	if s.StartAt.IsZero() {
+		problems = append(problems, "start_at is required")
+	}
+	duration := s.Duration()
+	if duration <= 0 || duration > maxRoomEventDuration {
+		problems = append(problems, fmt.Sprintf("duration_minutes must be between 1 and %d", int(maxRoomEventDuration.Minutes())))
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("unknown timezone %q", s.Timezone))
+	}
+
+	switch s.Type {
+	case RoomEventDoubleReward:
+		if s.RewardMultiplier <= 1 || s.RewardMultiplier > maxRewardMultiplier {
+			problems = append(problems, fmt.Sprintf("reward_multiplier must be above 1 and at most %g", maxRewardMultiplier))
+		}
+	case RoomEventBossRush:
+	case RoomEventTideStorm:
+		if !defaultFishType(s.TideFishTypeID) {
+			problems = append(problems, fmt.Sprintf("unknown tide_fish_type_id %d", s.TideFishTypeID))
+		}
+		if s.TideWaveSize < 1 || s.TideWaveSize > 50 {
+			problems = append(problems, "tide_wave_size must be between 1 and 50")
+		}
+		if s.TideWaveSeconds < 1 || s.TideWaveSeconds > 60 {
+			problems = append(problems, "tide_wave_seconds must be between 1 and 60")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown event type %q", s.Type))
+	}
+
+	problems = append(problems, validateRecurrence(s)...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidRoomEvent, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateRecurrence 檢查重複規則，並確保同一排程的場次不會重疊
+func validateRecurrence(s *RoomEventSchedule) []string {
+	var problems []string
+	r := s.Recurrence
+	if r.Count < 0 {
+		problems = append(problems, "recurrence.count must not be negative")
+	}
+	if r.Until != nil && r.Until.Before(s.StartAt) {
+		problems = append(problems, "recurrence.until must not be before start_at")
+	}
+	if len(r.Weekdays) > 0 && r.Frequency != RecurrenceWeekly {
+		problems = append(problems, "recurrence.weekdays is only allowed for weekly events")
+	}
+
+	var gap time.Duration
+	switch r.Frequency {
+	case RecurrenceNone, "":
+		return problems
+	case RecurrenceDaily:
+		gap = time.Duration(r.interval()) * 24 * time.Hour
+	case RecurrenceWeekly:
+		seen := make(map[time.Weekday]bool)
+		for _, day := range r.Weekdays {
+			if day < time.Sunday || day > time.Saturday {
+				problems = append(problems, fmt.Sprintf("invalid weekday %d", day))
+			}
+			if seen[day] {
+				problems = append(problems, fmt.Sprintf("duplicate weekday %d", day))
+			}
+			seen[day] = true
+		}
+		gap = minWeekdayGap(s.weekdays(s.StartAt.In(s.location())), r.interval())
+	case RecurrenceMonthly:
+		gap = time.Duration(r.interval()) * 28 * 24 * time.Hour
+	default:
+		return append(problems, fmt.Sprintf("unknown recurrence frequency %q", r.Frequency))
+	}
+
+	if s.Duration() > gap {
+		problems = append(problems, "duration must not be longer than the time between occurrences")
+	}
+	return problems
+}
+
+// minWeekdayGap 每週重複時兩場之間的最短間隔
+func minWeekdayGap(days []time.Weekday, interval int) time.Duration {
+	gap := 7 * interval
+	for i := 1; i < len(days); i++ {
+		if d := int(days[i] - days[i-1]); d > 0 && d < gap {
+			gap = d
+		}
+	}
+	if len(days) > 1 && interval == 1 {
+		if d := int(days[0]) + 7 - int(days[len(days)-1]); d < gap {
+			gap = d
+		}
+	}
+	return time.Duration(gap) * 24 * time.Hour
+}
+
+// defaultFishType 是否為已知的魚種
+func defaultFishType(id int32) bool {
+	for _, fishType := range getDefaultFishTypes() {
+		if fishType.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ========================================
+// RoomManager 活動狀態
+// ========================================
+
+// SetActiveEvents 替換正在進行的活動，返回新開始和已結束的場次
+func (rm *RoomManager) SetActiveEvents(events []*RoomEvent) (started, ended []*RoomEvent) {
+	active := make(map[string]*RoomEvent, len(events))
+	for _, event := range events {
+		active[event.ID] = event
+	}
+
+	rm.eventMu.Lock()
+	previous := rm.events
+	rm.events = active
+	rm.eventMu.Unlock()
+
+	for id, event := range active {
+		if _, ok := previous[id]; !ok {
+			started = append(started, event)
+		}
+	}
+	for id, event := range previous {
+		if _, ok := active[id]; !ok {
+			ended = append(ended, event)
+		}
+	}
+	sortRoomEvents(started)
+	sortRoomEvents(ended)
+	return started, ended
+}
+
+// ActiveEvents 對房間類型生效的活動，按開始時間排列
+func (rm *RoomManager) ActiveEvents(roomType RoomType) []*RoomEvent {
+	rm.eventMu.RLock()
+	defer rm.eventMu.RUnlock()
+
+	var events []*RoomEvent
+	for _, event := range rm.events {
+		if event.Affects(roomType) {
+			events = append(events, event)
+		}
+	}
+	sortRoomEvents(events)
+	return events
+}
+
+// activeEvent 對房間類型生效的某類活動；同類活動同時進行時取獎勵倍數最高、其次最早開始的
+func (rm *RoomManager) activeEvent(roomType RoomType, eventType RoomEventType) *RoomEvent {
+	var chosen *RoomEvent
+	for _, event := range rm.ActiveEvents(roomType) {
+		if event.Type != eventType {
+			continue
+		}
+		if chosen == nil || event.RewardMultiplier > chosen.RewardMultiplier {
+			chosen = event
+		}
+	}
+	return chosen
+}
+
+// applyRewardBoost 按房間的獎勵加成活動增加擊殺獎勵，返回的 HitResult 標記活動場次
+func (rm *RoomManager) applyRewardBoost(roomType RoomType, hit *HitResult) {
+	event := rm.activeEvent(roomType, RoomEventDoubleReward)
+	if event == nil || hit.Reward <= 0 {
+		return
+	}
+	bonus := int64(float64(hit.Reward) * (event.RewardMultiplier - 1))
+	if bonus <= 0 {
+		return
+	}
+	hit.Reward += bonus
+	hit.EventID = event.ID
+	hit.EventType = event.Type
+	hit.EventBonus = bonus
+}
+
+// spawnEventFish 按房間的 Boss 狂潮和魚潮風暴活動生成魚，調用方不持有 rm.mu
+func (rm *RoomManager) spawnEventFish(room *Room, now time.Time, fishCount int) (fish *Fish, wave []*Fish, bossRush bool) {
+	if event := rm.activeEvent(room.Type, RoomEventBossRush); event != nil {
+		bossRush = true
+		if fishCount < int(room.Config.MaxFishCount) {
+			fish = rm.spawner.TrySpawnFishWithSizes(room.Config, GetBossRushConfig().FishSizePreferences)
+		}
+	}
+
+	event := rm.activeEvent(room.Type, RoomEventTideStorm)
+	if event == nil || now.Sub(room.tideWaveAt) < event.TideWaveInterval {
+		return fish, nil, bossRush
+	}
+	limit := 2*int(room.Config.MaxFishCount) - fishCount
+	size := min(event.TideWaveSize, limit)
+	for i := 0; i < size; i++ {
+		if tideFish := rm.spawner.SpawnSpecificFish(event.TideFishTypeID, room.Config); tideFish != nil {
+			wave = append(wave, tideFish)
+		}
+	}
+	room.tideWaveAt = now
+	return fish, wave, bossRush
+}
+
+// sortRoomEvents 按開始時間排列活動
+func sortRoomEvents(events []*RoomEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].StartsAt.Equal(events[j].StartsAt) {
+			return events[i].ID < events[j].ID
+		}
+		return events[i].StartsAt.Before(events[j].StartsAt)
+	})
+}
+
+// ========================================
+// GameUsecase 活動用例
+// ========================================
+
+// SetActiveRoomEvents 替換正在進行的活動，返回新開始和已結束的場次
+func (gu *GameUsecase) SetActiveRoomEvents(events []*RoomEvent) (started, ended []*RoomEvent) {
+	started, ended = gu.roomManager.SetActiveEvents(events)
+	for _, event := range started {
+		gu.logger.Infof("Room event %s (%s, %s) started, ends at %s", event.ID, event.Name, event.Type, event.EndsAt.Format(time.RFC3339))
+	}
+	for _, event := range ended {
+		gu.logger.Infof("Room event %s (%s, %s) ended", event.ID, event.Name, event.Type)
+	}
+	return started, ended
+}
+
+// ActiveRoomEvents 對房間類型生效的活動
+func (gu *GameUsecase) ActiveRoomEvents(roomType RoomType) []*RoomEvent {
+	return gu.roomManager.ActiveEvents(roomType)
+}
+
+// RoomEventsForRoom 對房間生效的活動，房間不存在時返回 nil
+func (gu *GameUsecase) RoomEventsForRoom(roomID string) []*RoomEvent {
+	gu.roomManager.mu.RLock()
+	room, ok := gu.roomManager.rooms[roomID]
+	var roomType RoomType
+	if ok {
+		roomType = room.Type
+	}
+	gu.roomManager.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return gu.roomManager.ActiveEvents(roomType)
+}
+
+// ========================================
+// RoomEventUsecase 管理後台活動排程管理
+// ========================================
+
+// RoomEventUsecase 活動排程的增刪改查，遊戲節點定期重新加載排程
+type RoomEventUsecase struct {
+	repo   RoomEventRepo
+	logger logger.Logger
+}
+
+// NewRoomEventUsecase 創建活動排程用例
+func NewRoomEventUsecase(repo RoomEventRepo, logger logger.Logger) *RoomEventUsecase {
+	return &RoomEventUsecase{
+		repo:   repo,
+		logger: logger.With("component", "room_event_usecase"),
+	}
+}
+
+// List 所有排程
+func (uc *RoomEventUsecase) List(ctx context.Context) ([]*RoomEventSchedule, error) {
+	return uc.repo.ListRoomEventSchedules(ctx)
+}
+
+// Get 查詢排程
+func (uc *RoomEventUsecase) Get(ctx context.Context, id int64) (*RoomEventSchedule, error) {
+	return uc.repo.GetRoomEventSchedule(ctx, id)
+}
+
+// Create 校驗並創建排程
+func (uc *RoomEventUsecase) Create(ctx context.Context, schedule *RoomEventSchedule) error {
+	schedule.normalize()
+	if err := ValidateRoomEventSchedule(schedule); err != nil {
+		return err
+	}
+	if err := uc.repo.CreateRoomEventSchedule(ctx, schedule); err != nil {
+		return err
+	}
+	uc.logger.Infof("Room event schedule %d (%s, %s) created by %s", schedule.ID, schedule.Name, schedule.Type, schedule.UpdatedBy)
+	return nil
+}
+
+// Update 校驗並更新排程，正在進行的場次在遊戲節點下一次重新加載時按新排程調整
+func (uc *RoomEventUsecase) Update(ctx context.Context, schedule *RoomEventSchedule) error {
+	schedule.normalize()
+	if err := ValidateRoomEventSchedule(schedule); err != nil {
+		return err
+	}
+	if err := uc.repo.UpdateRoomEventSchedule(ctx, schedule); err != nil {
+		return err
+	}
+	uc.logger.Infof("Room event schedule %d (%s, %s) updated by %s", schedule.ID, schedule.Name, schedule.Type, schedule.UpdatedBy)
+	return nil
+}
+
+// Delete 刪除排程
+func (uc *RoomEventUsecase) Delete(ctx context.Context, id int64, deletedBy string) error {
+	if err := uc.repo.DeleteRoomEventSchedule(ctx, id); err != nil {
+		return err
+	}
+	uc.logger.Infof("Room event schedule %d deleted by %s", id, deletedBy)
+	return nil
+}
+
+// Upcoming 所有啟用的排程在 [from, to) 內進行的場次
+func (uc *RoomEventUsecase) Upcoming(ctx context.Context, from, to time.Time) ([]*RoomEvent, error) {
+	schedules, err := uc.repo.ListRoomEventSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return RoomEventsBetween(schedules, from, to), nil
+}
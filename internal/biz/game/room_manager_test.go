@@ -133,14 +133,14 @@ func TestRoomManager_FireBullet(t *testing.T) {
 	_ = env.RoomManager.JoinRoom(room.ID, player)
 
 	t.Run("fire bullet successfully", func(t *testing.T) {
-		bullet, err := env.RoomManager.FireBullet(room.ID, player.ID, 1.0, 10, game.Position{X: 600, Y: 750})
+		bullet, err := env.RoomManager.FireBullet(room.ID, player.ID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 		assert.NoError(t, err)
 		assert.NotNil(t, bullet)
 		assert.Equal(t, player.ID, bullet.PlayerID)
 	})
 
 	t.Run("fire bullet in non-existing room", func(t *testing.T) {
-		bullet, err := env.RoomManager.FireBullet("non-existing", player.ID, 1.0, 10, game.Position{X: 600, Y: 750})
+		bullet, err := env.RoomManager.FireBullet("non-existing", player.ID, 1.0, 10, game.Position{X: 600, Y: 750}, 0)
 		assert.Error(t, err)
 		assert.Nil(t, bullet)
 	})
@@ -19,13 +19,13 @@ func (r *Room) AllocateSeat(playerID int64) (int, error) {
 		}
 	}
 
-	return -1, fmt.Errorf("room is full, no available seats")
+	return -1, ErrRoomFull
 }
 
 // ReleaseSeat 释放座位
 func (r *Room) ReleaseSeat(seatID int) error {
 	if seatID < 0 || seatID >= len(r.Seats) {
-		return fmt.Errorf("invalid seat ID: %d", seatID)
+		return fmt.Errorf("%w: %d", ErrInvalidSeat, seatID)
 	}
 
 	r.Seats[seatID] = 0
@@ -82,3 +82,32 @@ func (r *Room) GetSeatInfo() map[int]int64 {
 	}
 	return seatInfo
 }
+
+// MoveSeat 将玩家从当前座位移到空座位
+func (r *Room) MoveSeat(playerID int64, seatID int) error {
+	if seatID < 0 || seatID >= len(r.Seats) {
+		return fmt.Errorf("%w: %d", ErrInvalidSeat, seatID)
+	}
+	if r.Seats[seatID] != 0 {
+		return fmt.Errorf("%w: %d", ErrSeatTaken, seatID)
+	}
+
+	if current := r.GetPlayerSeat(playerID); current >= 0 {
+		r.Seats[current] = 0
+	}
+	r.Seats[seatID] = playerID
+	return nil
+}
+
+// SwapSeats 交换两个座位上的玩家
+func (r *Room) SwapSeats(a, b int) error {
+	if a < 0 || a >= len(r.Seats) {
+		return fmt.Errorf("%w: %d", ErrInvalidSeat, a)
+	}
+	if b < 0 || b >= len(r.Seats) {
+		return fmt.Errorf("%w: %d", ErrInvalidSeat, b)
+	}
+
+	r.Seats[a], r.Seats[b] = r.Seats[b], r.Seats[a]
+	return nil
+}
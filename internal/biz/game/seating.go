@@ -0,0 +1,452 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ========================================
+// 座位管理：換座、斷線保留和 AFK 回收
+// ========================================
+//
+// 玩家入座後可以換到空座位，或請求與其他在線玩家交換座位，對方在請求過期前接受或拒絕。
+// 玩家斷線時座位保留到 ReservedUntil，期間使用原來的會話重新連接回到原座位，過期後按正常流程離開結算；
+// 在線玩家超過 AFK 時間沒有開火、切換砲台或換座（只有心跳）時結算離座並轉為觀戰者。
+// 每次座位變化都以 SeatEvent 按順序異步通知已註冊的鉤子，由應用層廣播給房間。
+
+const (
+	// EndReasonAFK 長時間沒有操作被移出座位
+	EndReasonAFK = "afk"
+	// EndReasonReservationExpired 斷線後沒有在座位保留期內重新連接
+	EndReasonReservationExpired = "reservation_expired"
+)
+
+// SeatEventType 座位事件類型
+type SeatEventType string
+
+const (
+	SeatEventTaken         SeatEventType = "taken"          // 玩家加入房間並入座
+	SeatEventReleased      SeatEventType = "released"       // 玩家離開房間，座位空出
+	SeatEventChanged       SeatEventType = "changed"        // 玩家換到空座位，OtherSeatID 為原座位
+	SeatEventSwapRequested SeatEventType = "swap_requested" // 玩家請求與 OtherPlayerID 交換座位
+	SeatEventSwapped       SeatEventType = "swapped"        // 雙方已交換座位，SeatID 為發起方的新座位
+	SeatEventSwapDeclined  SeatEventType = "swap_declined"  // 換座請求被拒絕、取消或過期
+	SeatEventReserved      SeatEventType = "reserved"       // 玩家斷線，座位保留到 ExpiresAt
+	SeatEventResumed       SeatEventType = "resumed"        // 玩家重新連接回到保留的座位
+	SeatEventAFK           SeatEventType = "afk"            // 玩家長時間沒有操作，離座轉為觀戰者
+)
+
+// SeatEvent 座位事件
+type SeatEvent struct {
+	RoomID        string        `json:"room_id"`
+	Type          SeatEventType `json:"type"`
+	PlayerID      int64         `json:"player_id"`
+	SeatID        int           `json:"seat_id"`
+	OtherPlayerID int64         `json:"other_player_id,omitempty"` // 換座的另一方
+	OtherSeatID   int           `json:"other_seat_id"`             // 換座的另一個座位，-1 表示沒有
+	ExpiresAt     time.Time     `json:"expires_at"`                // 座位保留或換座請求的過期時間
+	Reason        string        `json:"reason,omitempty"`
+	At            time.Time     `json:"at"`
+}
+
+// SeatEventHook 座位事件鉤子，在獨立的 goroutine 中按事件順序調用
+type SeatEventHook func(event SeatEvent)
+
+// SeatSwapRequest 等待回應的換座請求，請求時記錄雙方座位，任一方換座後請求失效
+type SeatSwapRequest struct {
+	RequesterID   int64     `json:"requester_id"`
+	RequesterSeat int       `json:"requester_seat"`
+	TargetID      int64     `json:"target_id"`
+	TargetSeat    int       `json:"target_seat"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// SeatRef 房間中的玩家座位
+type SeatRef struct {
+	RoomID   string `json:"room_id"`
+	PlayerID int64  `json:"player_id"`
+	SeatID   int    `json:"seat_id"`
+}
+
+// SeatSweep 座位檢查結果
+type SeatSweep struct {
+	Expired []SeatRef // 座位保留已過期的離線玩家
+	AFK     []SeatRef // 超過 AFK 時間沒有操作的在線玩家
+}
+
+// newSeatEvent 創建沒有另一方的座位事件
+func newSeatEvent(eventType SeatEventType, playerID int64, seatID int) SeatEvent {
+	return SeatEvent{Type: eventType, PlayerID: playerID, SeatID: seatID, OtherSeatID: -1}
+}
+
+// occupant 座位上的玩家，空座位或座位不存在時返回 nil
+func (r *Room) occupant(seatID int) *Player {
+	if seatID < 0 || seatID >= len(r.Seats) || r.Seats[seatID] == 0 {
+		return nil
+	}
+	return r.Players[r.Seats[seatID]]
+}
+
+// ========================================
+// RoomManager 座位操作
+// ========================================
+
+// OnSeatEvent 註冊座位事件鉤子
+func (rm *RoomManager) OnSeatEvent(hook SeatEventHook) {
+	rm.seatEvents.register(hook)
+}
+
+// emitSeat 發布座位事件，調用方需持有 rm.mu
+func (rm *RoomManager) emitSeat(room *Room, event SeatEvent) {
+	event.RoomID = room.ID
+	event.At = time.Now()
+	rm.logger.Debugf("Seat event in room %s: %s player=%d seat=%d other=%d/%d",
+		room.ID, event.Type, event.PlayerID, event.SeatID, event.OtherPlayerID, event.OtherSeatID)
+	rm.seatEvents.publish(event)
+}
+
+// seatedPlayer 查找房間中的玩家，調用方需持有 rm.mu
+func (rm *RoomManager) seatedPlayer(roomID string, playerID int64) (*Room, *Player, error) {
+	room, exists := rm.rooms[roomID]
+	if !exists || room.Status == RoomStatusClosed {
+		return nil, nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	player, exists := room.Players[playerID]
+	if !exists {
+		return nil, nil, fmt.Errorf("%w: %s", ErrPlayerNotInRoom, roomID)
+	}
+	return room, player, nil
+}
+
+// SelectSeat 玩家換到空座位
+func (rm *RoomManager) SelectSeat(roomID string, playerID int64, seatID int) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, player, err := rm.seatedPlayer(roomID, playerID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if seatID == player.SeatID {
+		player.LastActionAt = now
+		return nil
+	}
+	if occupant := room.occupant(seatID); occupant != nil && occupant.Status == PlayerStatusOffline {
+		return fmt.Errorf("%w: %d", ErrSeatReserved, seatID)
+	}
+
+	from := player.SeatID
+	if err := room.MoveSeat(playerID, seatID); err != nil {
+		return err
+	}
+	player.SeatID = seatID
+	player.LastActionAt = now
+	room.UpdatedAt = now
+	rm.cancelSwaps(room, playerID, "seat changed")
+
+	event := newSeatEvent(SeatEventChanged, playerID, seatID)
+	event.OtherSeatID = from
+	rm.emitSeat(room, event)
+	rm.logger.Infof("Player %d moved from seat %d to %d in room %s", playerID, from, seatID, roomID)
+	return nil
+}
+
+// RequestSeatSwap 玩家請求與座位上的其他在線玩家交換座位，同一玩家的新請求替換舊請求
+func (rm *RoomManager) RequestSeatSwap(roomID string, playerID int64, seatID int, ttl time.Duration) (*SeatSwapRequest, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, player, err := rm.seatedPlayer(roomID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if seatID < 0 || seatID >= len(room.Seats) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSeat, seatID)
+	}
+	target := room.occupant(seatID)
+	if target == nil {
+		return nil, fmt.Errorf("%w: seat %d is empty", ErrInvalidSeat, seatID)
+	}
+	if target.ID == playerID {
+		return nil, fmt.Errorf("%w: already in seat %d", ErrInvalidSeat, seatID)
+	}
+	if target.Status == PlayerStatusOffline {
+		return nil, fmt.Errorf("%w: %d", ErrSeatReserved, seatID)
+	}
+
+	now := time.Now()
+	request := &SeatSwapRequest{
+		RequesterID:   playerID,
+		RequesterSeat: player.SeatID,
+		TargetID:      target.ID,
+		TargetSeat:    seatID,
+		ExpiresAt:     now.Add(ttl),
+	}
+	if room.SwapRequests == nil {
+		room.SwapRequests = make(map[int64]*SeatSwapRequest)
+	}
+	room.SwapRequests[playerID] = request
+	player.LastActionAt = now
+
+	event := newSeatEvent(SeatEventSwapRequested, playerID, request.RequesterSeat)
+	event.OtherPlayerID = target.ID
+	event.OtherSeatID = seatID
+	event.ExpiresAt = request.ExpiresAt
+	rm.emitSeat(room, event)
+
+	c := *request
+	return &c, nil
+}
+
+// RespondSeatSwap 被請求的玩家接受或拒絕換座請求
+func (rm *RoomManager) RespondSeatSwap(roomID string, playerID, requesterID int64, accept bool) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, player, err := rm.seatedPlayer(roomID, playerID)
+	if err != nil {
+		return err
+	}
+	request := room.SwapRequests[requesterID]
+	if request == nil || request.TargetID != playerID {
+		return fmt.Errorf("%w: from player %d", ErrSwapRequestNotFound, requesterID)
+	}
+	delete(room.SwapRequests, requesterID)
+
+	now := time.Now()
+	requester := room.Players[requesterID]
+	if now.After(request.ExpiresAt) || requester == nil ||
+		room.occupant(request.RequesterSeat) != requester || room.occupant(request.TargetSeat) != player {
+		return fmt.Errorf("%w: from player %d", ErrSwapRequestNotFound, requesterID)
+	}
+	player.LastActionAt = now
+
+	if !accept {
+		event := newSeatEvent(SeatEventSwapDeclined, requesterID, request.RequesterSeat)
+		event.OtherPlayerID = playerID
+		event.OtherSeatID = request.TargetSeat
+		event.Reason = "declined"
+		rm.emitSeat(room, event)
+		return nil
+	}
+
+	if err := room.SwapSeats(request.RequesterSeat, request.TargetSeat); err != nil {
+		return err
+	}
+	requester.SeatID = request.TargetSeat
+	player.SeatID = request.RequesterSeat
+	room.UpdatedAt = now
+	// 雙方座位已變化，其他換座請求失效
+	rm.cancelSwaps(room, requesterID, "seat changed")
+	rm.cancelSwaps(room, playerID, "seat changed")
+
+	event := newSeatEvent(SeatEventSwapped, requesterID, requester.SeatID)
+	event.OtherPlayerID = playerID
+	event.OtherSeatID = player.SeatID
+	rm.emitSeat(room, event)
+	rm.logger.Infof("Players %d and %d swapped seats %d and %d in room %s",
+		requesterID, playerID, request.RequesterSeat, request.TargetSeat, roomID)
+	return nil
+}
+
+// cancelSwaps 取消玩家發起或收到的換座請求，調用方需持有 rm.mu
+func (rm *RoomManager) cancelSwaps(room *Room, playerID int64, reason string) {
+	for requesterID, request := range room.SwapRequests {
+		if request.RequesterID != playerID && request.TargetID != playerID {
+			continue
+		}
+		delete(room.SwapRequests, requesterID)
+
+		event := newSeatEvent(SeatEventSwapDeclined, request.RequesterID, request.RequesterSeat)
+		event.OtherPlayerID = request.TargetID
+		event.OtherSeatID = request.TargetSeat
+		event.Reason = reason
+		rm.emitSeat(room, event)
+	}
+}
+
+// ReserveSeat 玩家斷線時保留座位到 until，所有玩家都斷線時暫停房間
+func (rm *RoomManager) ReserveSeat(roomID string, playerID int64, until time.Time) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, player, err := rm.seatedPlayer(roomID, playerID)
+	if err != nil {
+		return err
+	}
+	if player.Status == PlayerStatusOffline {
+		return nil
+	}
+
+	player.Status = PlayerStatusOffline
+	player.ReservedUntil = until
+	room.UpdatedAt = time.Now()
+	rm.cancelSwaps(room, playerID, "player disconnected")
+
+	event := newSeatEvent(SeatEventReserved, playerID, player.SeatID)
+	event.ExpiresAt = until
+	rm.emitSeat(room, event)
+
+	if room.Status == RoomStatusPlaying && allOffline(room) {
+		rm.transition(room, RoomStatusPaused, "all players disconnected")
+	}
+	rm.logger.Infof("Reserved seat %d in room %s for player %d until %s",
+		player.SeatID, roomID, playerID, until.Format(time.RFC3339))
+	return nil
+}
+
+// allOffline 房間內的玩家是否全部離線，調用方需持有 rm.mu
+func allOffline(room *Room) bool {
+	for _, player := range room.Players {
+		if player.Status != PlayerStatusOffline {
+			return false
+		}
+	}
+	return true
+}
+
+// TouchPlayer 記錄玩家的遊戲操作時間
+func (rm *RoomManager) TouchPlayer(roomID string, playerID int64) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if _, player, err := rm.seatedPlayer(roomID, playerID); err == nil {
+		player.LastActionAt = time.Now()
+	}
+}
+
+// SweepSeats 移除過期的換座請求，並找出座位保留已過期的離線玩家和超過 afkTimeout 沒有操作的在線玩家
+// afkTimeout 為 0 時不檢查 AFK；下線中的房間由下線流程結算，不檢查
+func (rm *RoomManager) SweepSeats(now time.Time, afkTimeout time.Duration) SeatSweep {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	var sweep SeatSweep
+	for _, room := range rm.rooms {
+		if room.Status == RoomStatusClosed || room.Status == RoomStatusDraining {
+			continue
+		}
+
+		for requesterID, request := range room.SwapRequests {
+			if now.Before(request.ExpiresAt) {
+				continue
+			}
+			delete(room.SwapRequests, requesterID)
+			event := newSeatEvent(SeatEventSwapDeclined, request.RequesterID, request.RequesterSeat)
+			event.OtherPlayerID = request.TargetID
+			event.OtherSeatID = request.TargetSeat
+			event.Reason = "expired"
+			rm.emitSeat(room, event)
+		}
+
+		for _, player := range room.Players {
+			ref := SeatRef{RoomID: room.ID, PlayerID: player.ID, SeatID: player.SeatID}
+			switch {
+			case player.Status == PlayerStatusOffline:
+				if !player.ReservedUntil.IsZero() && !now.Before(player.ReservedUntil) {
+					sweep.Expired = append(sweep.Expired, ref)
+				}
+			case afkTimeout > 0 && !player.LastActionAt.IsZero() && now.Sub(player.LastActionAt) >= afkTimeout:
+				sweep.AFK = append(sweep.AFK, ref)
+			}
+		}
+	}
+	return sweep
+}
+
+// roomPlayer 獲取房間中玩家的副本
+func (rm *RoomManager) roomPlayer(roomID string, playerID int64) (Player, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	_, player, err := rm.seatedPlayer(roomID, playerID)
+	if err != nil {
+		return Player{}, err
+	}
+	return *player, nil
+}
+
+// demoteToSpectator 已離座的玩家留在房間觀戰，不受觀戰人數上限限制
+func (rm *RoomManager) demoteToSpectator(roomID string, player Player, reason string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, exists := rm.rooms[roomID]
+	if !exists || room.Status == RoomStatusClosed {
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	if room.Spectators == nil {
+		room.Spectators = make(map[int64]*Spectator)
+	}
+	room.Spectators[player.ID] = &Spectator{
+		PlayerID: player.ID,
+		Nickname: player.Nickname,
+		JoinTime: time.Now(),
+	}
+
+	event := newSeatEvent(SeatEventAFK, player.ID, player.SeatID)
+	event.Reason = reason
+	rm.emitSeat(room, event)
+	rm.logger.Infof("Player %d left seat %d in room %s and is now spectating: %s", player.ID, player.SeatID, roomID, reason)
+	return nil
+}
+
+// ========================================
+// GameUsecase 座位用例
+// ========================================
+
+// OnSeatEvent 註冊座位事件鉤子
+func (gu *GameUsecase) OnSeatEvent(hook SeatEventHook) {
+	gu.roomManager.OnSeatEvent(hook)
+}
+
+// SelectSeat 玩家換到空座位
+func (gu *GameUsecase) SelectSeat(ctx context.Context, roomID string, playerID int64, seatID int) error {
+	return gu.roomManager.SelectSeat(roomID, playerID, seatID)
+}
+
+// RequestSeatSwap 玩家請求與其他玩家交換座位，請求在 ttl 後過期
+func (gu *GameUsecase) RequestSeatSwap(ctx context.Context, roomID string, playerID int64, seatID int, ttl time.Duration) (*SeatSwapRequest, error) {
+	return gu.roomManager.RequestSeatSwap(roomID, playerID, seatID, ttl)
+}
+
+// RespondSeatSwap 接受或拒絕換座請求
+func (gu *GameUsecase) RespondSeatSwap(ctx context.Context, roomID string, playerID, requesterID int64, accept bool) error {
+	return gu.roomManager.RespondSeatSwap(roomID, playerID, requesterID, accept)
+}
+
+// ReserveSeat 玩家斷線時保留座位
+func (gu *GameUsecase) ReserveSeat(ctx context.Context, roomID string, playerID int64, until time.Time) error {
+	return gu.roomManager.ReserveSeat(roomID, playerID, until)
+}
+
+// TouchPlayer 記錄玩家的遊戲操作時間（開火以外的操作，如切換砲台）
+func (gu *GameUsecase) TouchPlayer(roomID string, playerID int64) {
+	gu.roomManager.TouchPlayer(roomID, playerID)
+}
+
+// SweepSeats 檢查過期的座位保留和 AFK 玩家
+func (gu *GameUsecase) SweepSeats(now time.Time, afkTimeout time.Duration) SeatSweep {
+	return gu.roomManager.SweepSeats(now, afkTimeout)
+}
+
+// ReclaimSeat 結算 AFK 玩家並把座位空出，玩家留在房間觀戰
+func (gu *GameUsecase) ReclaimSeat(ctx context.Context, roomID string, playerID int64, reason string) (*Settlement, error) {
+	player, err := gu.roomManager.roomPlayer(roomID, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	settlement, err := gu.SettleAndLeaveRoom(ctx, roomID, playerID, EndReasonAFK)
+	// 錢包退款失敗時玩家已經離座，仍然轉為觀戰
+	if err != nil && !errors.Is(err, ErrWalletOperation) {
+		return settlement, err
+	}
+	if demoteErr := gu.roomManager.demoteToSpectator(roomID, player, reason); demoteErr != nil {
+		return settlement, demoteErr
+	}
+	return settlement, err
+}
@@ -0,0 +1,155 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ========================================
+// 離場結算（節點下線時使用）
+// ========================================
+
+// EndReasonDrain 節點下線導致的遊戲結束
+const EndReasonDrain = "server_drain"
+
+// Settlement 玩家離場結算結果
+type Settlement struct {
+	PlayerID       int64  `json:"player_id"`
+	RoomID         string `json:"room_id"`
+	RefundedBullet int    `json:"refunded_bullets"` // 退還的飛行中子彈數
+	RefundAmount   int64  `json:"refund_amount"`    // 退還的子彈費用（分）
+	Balance        int64  `json:"balance"`          // 結算後的內存餘額
+}
+
+// TakePlayerBullets 移除玩家仍在飛行中的子彈並退回內存餘額
+// 返回被移除的子彈、退款後的餘額和玩家錢包 ID，玩家不在房間時餘額和錢包 ID 為 0
+func (rm *RoomManager) TakePlayerBullets(roomID string, playerID int64) ([]*Bullet, int64, uint, error) {
+	rm.mu.Lock()
+	room, exists := rm.rooms[roomID]
+	if !exists {
+		rm.mu.Unlock()
+		return nil, 0, 0, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+
+	var bullets []*Bullet
+	var refund int64
+	for id, bullet := range room.Bullets {
+		if bullet.PlayerID != playerID || bullet.Status != BulletStatusFlying {
+			continue
+		}
+		delete(room.Bullets, id)
+		bullets = append(bullets, bullet)
+		refund += bullet.Cost
+	}
+
+	var balance int64
+	var walletID uint
+	if player, ok := room.Players[playerID]; ok {
+		player.Balance += refund
+		balance = player.Balance
+		walletID = player.WalletID
+	}
+	roomType := room.Type
+	rm.mu.Unlock()
+
+	rm.inventoryManager.RefundBet(roomType, refund)
+	return bullets, balance, walletID, nil
+}
+
+// SettleAndLeaveRoom 結算玩家後離開房間
+// 飛行中的子彈退還費用並寫入錢包流水，遊戲記錄以 endReason 正常結束
+func (gu *GameUsecase) SettleAndLeaveRoom(ctx context.Context, roomID string, playerID int64, endReason string) (*Settlement, error) {
+	bullets, balance, walletID, err := gu.roomManager.TakePlayerBullets(roomID, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	settlement := &Settlement{
+		PlayerID:       playerID,
+		RoomID:         roomID,
+		RefundedBullet: len(bullets),
+		Balance:        balance,
+	}
+	for _, bullet := range bullets {
+		settlement.RefundAmount += bullet.Cost
+	}
+
+	// 遊客（ID 為負數）只退回內存餘額
+	var refundErr error
+	if playerID > 0 && len(bullets) > 0 {
+		var failed int64
+		failed, refundErr = gu.refundBullets(ctx, roomID, playerID, walletID, bullets)
+		// 錢包退款失敗的部分不計入結算餘額，保持與錢包一致
+		settlement.RefundAmount -= failed
+		settlement.Balance -= failed
+	}
+
+	if err := gu.leaveRoom(ctx, roomID, playerID, endReason); err != nil {
+		return settlement, err
+	}
+
+	gu.logger.Infof("Settled player %d in room %s: refunded %d bullets (%d), balance=%d",
+		playerID, roomID, settlement.RefundedBullet, settlement.RefundAmount, settlement.Balance)
+	return settlement, refundErr
+}
+
+// refundBullets 為每顆退還的子彈寫入錢包流水並更新遊戲記錄，返回退款失敗的金額（分）
+func (gu *GameUsecase) refundBullets(ctx context.Context, roomID string, playerID int64, walletID uint, bullets []*Bullet) (int64, error) {
+	activeRecord, err := gu.gameRecordRepo.FindActiveByUserID(ctx, playerID)
+	if err != nil {
+		gu.logger.Warnf("Failed to find active game record: %v", err)
+	}
+
+	var failed int64
+	var firstErr error
+	for _, bullet := range bullets {
+		cost := float64(bullet.Cost) / 100.0 // 轉換為元
+		if walletID > 0 {
+			err := gu.walletUC.Deposit(
+				ctx,
+				walletID,
+				cost,
+				"game_bullet_refund",
+				fmt.Sprintf("game:%s:bullet:%d:refund", roomID, bullet.ID),
+				"子彈費用退還",
+				map[string]interface{}{
+					"room_id":   roomID,
+					"bullet_id": bullet.ID,
+					"player_id": playerID,
+				},
+			)
+			if err != nil {
+				gu.logger.Errorf("Failed to refund bullet %d for player %d: %v", bullet.ID, playerID, err)
+				failed += bullet.Cost
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%w: %w", ErrWalletOperation, err)
+				}
+				continue
+			}
+		}
+		if activeRecord != nil {
+			activeRecord.RecordBulletRefunded(cost)
+		}
+	}
+
+	if activeRecord != nil {
+		if err := gu.gameRecordRepo.Update(ctx, activeRecord); err != nil {
+			gu.logger.Warnf("Failed to update game record: %v", err)
+		}
+	}
+
+	event := &GameEvent{
+		ID:       time.Now().UnixNano(),
+		Type:     EventBulletRefund,
+		RoomID:   roomID,
+		PlayerID: playerID,
+		Data: map[string]interface{}{
+			"bullets": len(bullets),
+		},
+		Timestamp: time.Now(),
+	}
+	gu.gameRepo.SaveGameEvent(ctx, event)
+
+	return failed, firstErr
+}
@@ -44,6 +44,22 @@ func NewFishSpawner(logger logger.Logger, roomConfig RoomConfig) *FishSpawner {
 
 // TrySpawnFish 嘗試生成魚
 func (fs *FishSpawner) TrySpawnFish(config RoomConfig) *Fish {
+	return fs.trySpawn(config, fs.selectRandomFishType)
+}
+
+// TrySpawnFishWithSizes 嘗試按魚尺寸權重生成魚（如 Boss 狂潮活動），生成間隔與 TrySpawnFish 相同
+func (fs *FishSpawner) TrySpawnFishWithSizes(config RoomConfig, sizeWeights map[string]float64) *Fish {
+	return fs.trySpawn(config, func() *FishType {
+		candidates := fs.GetFishTypesBySize(selectWeightedFishSize(sizeWeights))
+		if len(candidates) == 0 {
+			return fs.selectRandomFishType()
+		}
+		return &candidates[fs.rng.Intn(len(candidates))]
+	})
+}
+
+// trySpawn 按生成間隔和生成率決定是否生成魚，魚類型由 selectType 選擇
+func (fs *FishSpawner) trySpawn(config RoomConfig, selectType func() *FishType) *Fish {
 	now := time.Now()
 	
 	// 檢查生成間隔（防止生成過於頻繁）
@@ -56,8 +72,8 @@ func (fs *FishSpawner) TrySpawnFish(config RoomConfig) *Fish {
 		return nil
 	}
 	
-	// 隨機選擇魚類型
-	fishType := fs.selectRandomFishType()
+	// 選擇魚類型
+	fishType := selectType()
 	if fishType == nil {
 		return nil
 	}
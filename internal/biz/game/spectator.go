@@ -0,0 +1,96 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ========================================
+// Spectator 觀戰
+// ========================================
+
+// Spectator 觀戰者：接收房間狀態但不佔座位，不能開火
+type Spectator struct {
+	PlayerID int64     `json:"player_id"`
+	Nickname string    `json:"nickname"`
+	Support  bool      `json:"support"` // 客服觀戰，不受觀戰人數上限限制
+	JoinTime time.Time `json:"join_time"`
+}
+
+// SpectatorCount 觀戰人數
+func (r *Room) SpectatorCount() int {
+	return len(r.Spectators)
+}
+
+// WatchRoom 以觀戰者身份進入房間
+func (rm *RoomManager) WatchRoom(roomID string, spectator *Spectator) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, exists := rm.rooms[roomID]
+	if !exists || room.Status == RoomStatusClosed {
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+
+	// 已入座或正在觀戰的玩家需要先離開
+	for _, existingRoom := range rm.rooms {
+		if _, ok := existingRoom.Players[spectator.PlayerID]; ok {
+			return fmt.Errorf("%w: %s", ErrPlayerAlreadyInRoom, existingRoom.ID)
+		}
+		if _, ok := existingRoom.Spectators[spectator.PlayerID]; ok {
+			return fmt.Errorf("%w: %s", ErrPlayerSpectating, existingRoom.ID)
+		}
+	}
+
+	if !spectator.Support {
+		if err := room.Private.admit(spectator.PlayerID); err != nil {
+			return fmt.Errorf("%w: %s", err, roomID)
+		}
+		if int32(len(room.Spectators)) >= room.Config.MaxSpectators {
+			return fmt.Errorf("%w: %s", ErrSpectatorsFull, roomID)
+		}
+	}
+
+	if room.Spectators == nil {
+		room.Spectators = make(map[int64]*Spectator)
+	}
+	spectator.JoinTime = time.Now()
+	room.Spectators[spectator.PlayerID] = spectator
+
+	rm.logger.Infof("Player %d is spectating room %s (support=%v, spectators=%d)",
+		spectator.PlayerID, roomID, spectator.Support, len(room.Spectators))
+	return nil
+}
+
+// StopWatching 觀戰者離開房間
+func (rm *RoomManager) StopWatching(roomID string, playerID int64) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	room, exists := rm.rooms[roomID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
+	}
+	if _, ok := room.Spectators[playerID]; !ok {
+		return fmt.Errorf("%w: %s", ErrPlayerNotInRoom, roomID)
+	}
+
+	delete(room.Spectators, playerID)
+	rm.logger.Infof("Spectator %d left room %s", playerID, roomID)
+	return nil
+}
+
+// WatchRoom 玩家觀戰
+func (gu *GameUsecase) WatchRoom(ctx context.Context, roomID string, spectator *Spectator) error {
+	if err := gu.roomManager.WatchRoom(roomID, spectator); err != nil {
+		gu.logger.Warnf("Failed to watch room %s: %v", roomID, err)
+		return err
+	}
+	return nil
+}
+
+// StopWatching 玩家停止觀戰
+func (gu *GameUsecase) StopWatching(ctx context.Context, roomID string, playerID int64) error {
+	return gu.roomManager.StopWatching(roomID, playerID)
+}
@@ -2,7 +2,9 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/b7777777v/fish_server/internal/biz/wallet"
@@ -53,7 +55,6 @@ type InventoryRepo interface {
 // PlayerRepo 玩家數據倉庫接口
 type PlayerRepo interface {
 	GetPlayer(ctx context.Context, playerID int64) (*Player, error)
-	UpdatePlayerBalance(ctx context.Context, playerID int64, balance int64) error
 	UpdatePlayerStatus(ctx context.Context, playerID int64, status PlayerStatus) error
 }
 
@@ -110,6 +111,14 @@ func (gu *GameUsecase) CreateRoom(ctx context.Context, roomType RoomType, maxPla
 		return nil, err
 	}
 
+	if err := gu.initRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// initRoom 初始化新房間的魚群，並保存到 Redis、記錄事件
+func (gu *GameUsecase) initRoom(ctx context.Context, room *Room) error {
 	// 初始化房間魚類
 	initialFishes := gu.spawner.BatchSpawnFish(5, room.Config)
 	for _, fish := range initialFishes {
@@ -119,11 +128,11 @@ func (gu *GameUsecase) CreateRoom(ctx context.Context, roomType RoomType, maxPla
 	// 保存房間基本信息到 Redis（不保存到 PostgreSQL）
 	if err := gu.gameRepo.SaveRoomToRedis(ctx, room); err != nil {
 		gu.logger.Errorf("Failed to save room to Redis: %v", err)
-		return nil, err
+		return err
 	}
 
 	// 增加房間計數器
-	if err := gu.gameRepo.IncrementRoomCount(ctx, roomType); err != nil {
+	if err := gu.gameRepo.IncrementRoomCount(ctx, room.Type); err != nil {
 		gu.logger.Errorf("Failed to increment room count: %v", err)
 		// 不阻斷房間創建流程，只記錄錯誤
 	}
@@ -140,7 +149,7 @@ func (gu *GameUsecase) CreateRoom(ctx context.Context, roomType RoomType, maxPla
 	gu.gameRepo.SaveGameEvent(ctx, event)
 
 	gu.logger.Infof("Created room %s with %d initial fishes", room.ID, len(initialFishes))
-	return room, nil
+	return nil
 }
 
 // JoinRoom 玩家加入房間
@@ -152,9 +161,14 @@ func (gu *GameUsecase) JoinRoom(ctx context.Context, roomID string, playerID int
 		return err
 	}
 
+	// 使用房間遊戲幣種的錢包
+	if err := gu.bindRoomWallet(ctx, roomID, player); err != nil {
+		return err
+	}
+
 	// 檢查玩家餘額
 	if player.Balance < 100 { // 最小餘額要求
-		return fmt.Errorf("insufficient balance to join room")
+		return fmt.Errorf("%w to join room", ErrInsufficientBalance)
 	}
 
 	// 加入房間
@@ -202,11 +216,59 @@ func (gu *GameUsecase) JoinRoom(ctx context.Context, roomID string, playerID int
 	return nil
 }
 
+// bindRoomWallet 把正式玩家的錢包切換為房間遊戲幣種的錢包，沒有該幣種的錢包時創建
+// 玩家信息默認帶主錢包，房間使用主錢包幣種時不需要查詢
+func (gu *GameUsecase) bindRoomWallet(ctx context.Context, roomID string, player *Player) error {
+	if gu.walletUC == nil {
+		return nil
+	}
+	currency, err := gu.roomManager.RoomCurrency(roomID)
+	if err != nil {
+		return err
+	}
+	playerCurrency := player.Currency
+	if playerCurrency == "" {
+		playerCurrency = wallet.DefaultCurrency
+	}
+	if playerCurrency == currency && player.WalletID != 0 {
+		return nil
+	}
+
+	w, err := gu.walletUC.GetWalletByUserID(ctx, uint(player.UserID), currency)
+	if errors.Is(err, wallet.ErrWalletNotFound) {
+		w, err = gu.walletUC.CreateWallet(ctx, uint(player.UserID), currency)
+	}
+	if err != nil {
+		gu.logger.Errorf("Failed to get %s wallet for player %d: %v", currency, player.ID, err)
+		return err
+	}
+	player.WalletID = w.ID
+	player.Currency = currency
+	player.Balance = int64(math.Round(w.Balance * 100))
+	return nil
+}
+
+// PlayerBalances 返回正式玩家各幣種錢包的餘額（分），用於按房間幣種檢查入場條件；沒有錢包服務時返回 nil
+func (gu *GameUsecase) PlayerBalances(ctx context.Context, userID int64) (map[string]int64, error) {
+	if gu.walletUC == nil {
+		return nil, nil
+	}
+	wallets, err := gu.walletUC.GetWalletsByUserID(ctx, uint(userID))
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]int64, len(wallets))
+	for _, w := range wallets {
+		balances[w.Currency] = int64(math.Round(w.Balance * 100))
+	}
+	return balances, nil
+}
+
 // JoinRoomWithPlayer 使用已有的 Player 對象加入房間（用於遊客）
 func (gu *GameUsecase) JoinRoomWithPlayer(ctx context.Context, roomID string, player *Player) error {
 	// 檢查玩家餘額
 	if player.Balance < 100 { // 最小餘額要求
-		return fmt.Errorf("insufficient balance to join room")
+		return fmt.Errorf("%w to join room", ErrInsufficientBalance)
 	}
 
 	// 加入房間
@@ -258,6 +320,11 @@ func (gu *GameUsecase) JoinRoomWithPlayer(ctx context.Context, roomID string, pl
 
 // LeaveRoom 玩家離開房間
 func (gu *GameUsecase) LeaveRoom(ctx context.Context, roomID string, playerID int64) error {
+	return gu.leaveRoom(ctx, roomID, playerID, "")
+}
+
+// leaveRoom 玩家離開房間並結束遊戲記錄，endReason 非空時記錄到遊戲記錄的 Metadata
+func (gu *GameUsecase) leaveRoom(ctx context.Context, roomID string, playerID int64, endReason string) error {
 	if err := gu.roomManager.LeaveRoom(roomID, playerID); err != nil {
 		gu.logger.Errorf("Failed to leave room %s: %v", roomID, err)
 		return err
@@ -279,6 +346,9 @@ func (gu *GameUsecase) LeaveRoom(ctx context.Context, roomID string, playerID in
 
 		if activeRecord != nil {
 			activeRecord.Finish()
+			if endReason != "" {
+				activeRecord.SetEndReason(endReason)
+			}
 			if err := gu.gameRecordRepo.Update(ctx, activeRecord); err != nil {
 				gu.logger.Errorf("Failed to finish game record: %v", err)
 			} else {
@@ -329,7 +399,7 @@ func (gu *GameUsecase) GetRoomList(ctx context.Context, roomType RoomType) ([]*R
 func (gu *GameUsecase) FireBullet(ctx context.Context, roomID string, playerID int64, direction float64, power int32, position Position, targetFishID int64) (*Bullet, error) {
 	// 檢查參數
 	if power < 1 || power > 100 {
-		return nil, fmt.Errorf("invalid bullet power: %d", power)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidBulletPower, power)
 	}
 
 	// 發射子彈（內部會檢查餘額）
@@ -366,14 +436,10 @@ func (gu *GameUsecase) FireBullet(ctx context.Context, roomID string, playerID i
 					gu.logger.Errorf("Failed to create wallet transaction for bullet cost: %v, rolling back", walletErr)
 					// 回滾內存餘額
 					player.Balance += bullet.Cost
-					// 不更新數據庫餘額，保持一致性
-					return nil, fmt.Errorf("wallet operation failed: %w", walletErr)
+					return nil, fmt.Errorf("%w: %w", ErrWalletOperation, walletErr)
 				}
 			}
 
-			// 只有錢包操作成功（或玩家沒有錢包）才更新數據庫餘額
-			gu.playerRepo.UpdatePlayerBalance(ctx, playerID, player.Balance)
-
 			// 只有錢包操作成功才更新遊戲記錄
 			if walletErr == nil {
 				activeRecord, err := gu.gameRecordRepo.FindActiveByUserID(ctx, playerID)
@@ -468,6 +534,9 @@ func (gu *GameUsecase) HitFish(ctx context.Context, roomID string, bulletID int6
 							"is_critical":  hitResult.IsCritical,
 							"multiplier":   hitResult.Multiplier,
 							"player_id":    playerID,
+							"event_id":     hitResult.EventID,
+							"event_type":   hitResult.EventType,
+							"event_bonus":  hitResult.EventBonus,
 						},
 					)
 					if walletErr != nil {
@@ -482,9 +551,6 @@ func (gu *GameUsecase) HitFish(ctx context.Context, roomID string, bulletID int6
 					}
 				}
 
-					// 只有錢包操作成功（或玩家沒有錢包）才更新數據庫餘額
-					gu.playerRepo.UpdatePlayerBalance(ctx, playerID, player.Balance)
-
 					// 只有錢包操作成功且有實際獎勵才更新遊戲記錄
 					if walletErr == nil && hitResult.Reward > 0 {
 						activeRecord, err := gu.gameRecordRepo.FindActiveByUserID(ctx, playerID)
@@ -495,6 +561,9 @@ func (gu *GameUsecase) HitFish(ctx context.Context, roomID string, bulletID int6
 						if activeRecord != nil {
 							reward := float64(hitResult.Reward) / 100.0 // 轉換為元
 							activeRecord.RecordFishCaught(reward, hitResult.IsCritical)
+							if hitResult.EventID != "" {
+								activeRecord.RecordEventPayout(hitResult.EventID, hitResult.EventType, float64(hitResult.EventBonus)/100.0)
+							}
 							if err := gu.gameRecordRepo.Update(ctx, activeRecord); err != nil {
 								gu.logger.Warnf("Failed to update game record: %v", err)
 							}
@@ -516,6 +585,8 @@ func (gu *GameUsecase) HitFish(ctx context.Context, roomID string, bulletID int6
 					"reward":       hitResult.Reward,
 					"is_critical":  hitResult.IsCritical,
 					"multiplier":   hitResult.Multiplier,
+					"event_id":     hitResult.EventID,
+					"event_bonus":  hitResult.EventBonus,
 				},
 				Timestamp: time.Now(),
 			}
@@ -661,13 +732,33 @@ func (gu *GameUsecase) UpdateRoomConfig(ctx context.Context, roomID string, conf
 
 // CloseRoom 關閉房間並清理資源
 func (gu *GameUsecase) CloseRoom(ctx context.Context, roomID string) error {
-	// 獲取房間信息以確定房間類型
-	room, err := gu.roomManager.GetRoom(roomID)
+	// 停止遊戲循環並從內存移除
+	room, err := gu.roomManager.CloseRoom(roomID, false)
 	if err != nil {
-		gu.logger.Errorf("Failed to get room %s for closing: %v", roomID, err)
+		gu.logger.Errorf("Failed to close room %s: %v", roomID, err)
 		return err
 	}
 
+	gu.cleanupClosedRoom(ctx, room)
+	return nil
+}
+
+// CloseIdleRoom 關閉沒有玩家和觀戰者的房間，否則返回 ErrRoomNotEmpty
+// 檢查和關閉在同一把鎖內完成，不會關閉剛好有玩家加入的房間
+func (gu *GameUsecase) CloseIdleRoom(ctx context.Context, roomID string) error {
+	room, err := gu.roomManager.CloseRoom(roomID, true)
+	if err != nil {
+		return err
+	}
+
+	gu.cleanupClosedRoom(ctx, room)
+	return nil
+}
+
+// cleanupClosedRoom 清理已關閉房間在 Redis 中的信息
+func (gu *GameUsecase) cleanupClosedRoom(ctx context.Context, room *Room) {
+	roomID := room.ID
+
 	// 減少 Redis 中的房間計數器
 	if err := gu.gameRepo.DecrementRoomCount(ctx, room.Type); err != nil {
 		gu.logger.Errorf("Failed to decrement room count for type %s: %v", room.Type, err)
@@ -681,7 +772,6 @@ func (gu *GameUsecase) CloseRoom(ctx context.Context, roomID string) error {
 	}
 
 	gu.logger.Infof("Closed room %s (type: %s)", roomID, room.Type)
-	return nil
 }
 
 // GetRoom 獲取房間詳細信息
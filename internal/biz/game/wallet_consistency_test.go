@@ -49,7 +49,7 @@ func TestFireBullet_WalletWithdrawFailure(t *testing.T) {
 	).Return(errors.New("wallet operation failed"))
 
 	// 測試開火（預期錢包扣款失敗並回滾）
-	bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 0.0, 10, game.Position{X: 600, Y: 750})
+	bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 0.0, 10, game.Position{X: 600, Y: 750}, 0)
 
 	// 驗證結果
 	assert.Error(t, err, "應該返回錢包操作失敗的錯誤")
@@ -78,7 +78,6 @@ func TestFireBullet_WalletWithdrawSuccess(t *testing.T) {
 	// Mock 玩家倉庫
 	env.PlayerRepo.On("GetPlayer", env.Ctx, playerID).Return(testPlayer, nil)
 	env.PlayerRepo.On("UpdatePlayerStatus", env.Ctx, playerID, game.PlayerStatusPlaying).Return(nil)
-	env.PlayerRepo.On("UpdatePlayerBalance", env.Ctx, playerID, mock.Anything).Return(nil)
 
 	// 玩家加入房間
 	err = env.GameUsecase.JoinRoom(env.Ctx, room.ID, playerID)
@@ -89,7 +88,7 @@ func TestFireBullet_WalletWithdrawSuccess(t *testing.T) {
 	initialBalance := roomState.Players[playerID].Balance
 
 	// 測試開火（預期成功）
-	bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 0.0, 10, game.Position{X: 600, Y: 750})
+	bullet, err := env.GameUsecase.FireBullet(env.Ctx, room.ID, playerID, 0.0, 10, game.Position{X: 600, Y: 750}, 0)
 
 	// 驗證結果
 	assert.NoError(t, err, "開火應該成功")
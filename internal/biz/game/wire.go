@@ -29,4 +29,6 @@ var ProviderSet = wire.NewSet(
 	NewFishSpawner,
 	NewDefaultRoomConfig,
 	NewFormationConfigService,
+	NewRoomConfigUsecase,
+	NewRoomEventUsecase,
 )
@@ -0,0 +1,200 @@
+package lobby
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cluster routing for multiple game servers
+// 此檔案實現大廳的 Game Server 路由：
+// - Game Server 節點通過 NodeRegistry 註冊並定期心跳
+// - 各節點通過 RoomCache 上報自己的房間
+// - 大廳根據房間所在節點返回連接地址，心跳過期的節點自動移除
+
+var (
+	// ErrNoGameServer 沒有可用的 Game Server 節點
+	ErrNoGameServer = errors.New("no game server available")
+	// ErrRoomNotRouted 房間不在任何在線節點上
+	ErrRoomNotRouted = errors.New("room is not hosted by any online game server")
+	// ErrGameServerNotFound 指定的節點不在線
+	ErrGameServerNotFound = errors.New("game server not found")
+	// ErrInviteNotFound 邀請碼對應的私人房間不存在
+	ErrInviteNotFound = errors.New("invite code not found")
+)
+
+// GameNode Game Server 節點資訊
+type GameNode struct {
+	NodeID        string    `json:"node_id"`
+	URL           string    `json:"url"`         // 客戶端連接的 WebSocket 地址
+	Connections   int       `json:"connections"` // 當前連接數
+	Rooms         int       `json:"rooms"`       // 當前房間數
+	Draining      bool      `json:"draining"`    // 下線模式中，不再接受新玩家
+	StartedAt     time.Time `json:"started_at"`
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+}
+
+// RouteGameServer 為房間選擇 Game Server，下線模式中的節點不參與路由
+func (uc *lobbyUsecase) RouteGameServer(ctx context.Context, roomID string) (*GameNode, error) {
+	nodes, err := uc.GetGameNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes = activeNodes(nodes)
+	if len(nodes) == 0 {
+		return nil, ErrNoGameServer
+	}
+
+	if roomID == "" {
+		return leastLoadedNode(nodes), nil
+	}
+
+	_, node, err := uc.routeRoom(ctx, nodes, func(room *RoomInfo) bool { return room.RoomID == roomID })
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotRouted, roomID)
+	}
+	return node, nil
+}
+
+// RouteInviteCode 按邀請碼查找私人房間及其所在節點
+func (uc *lobbyUsecase) RouteInviteCode(ctx context.Context, inviteCode string) (*RoomInfo, *GameNode, error) {
+	inviteCode = strings.ToUpper(strings.TrimSpace(inviteCode))
+	if inviteCode == "" {
+		return nil, nil, ErrInviteNotFound
+	}
+
+	nodes, err := uc.GetGameNodes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes = activeNodes(nodes)
+	if len(nodes) == 0 {
+		return nil, nil, ErrNoGameServer
+	}
+
+	room, node, err := uc.routeRoom(ctx, nodes, func(room *RoomInfo) bool {
+		return room.Private && room.InviteCode == inviteCode
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if node == nil {
+		return nil, nil, ErrInviteNotFound
+	}
+	return room, node, nil
+}
+
+// routeRoom 查找第一個滿足 match 且所在節點在線的房間，沒有時返回 nil
+func (uc *lobbyUsecase) routeRoom(ctx context.Context, nodes []*GameNode, match func(*RoomInfo) bool) (*RoomInfo, *GameNode, error) {
+	rooms, err := uc.roomCache.GetAllRooms(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get rooms from cache: %w", err)
+	}
+
+	byID := nodesByID(nodes)
+	for _, room := range rooms {
+		if !match(room) {
+			continue
+		}
+		if node, ok := byID[room.GameServerID]; ok {
+			return room, node, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// GetGameNodes 獲取所有在線的 Game Server 節點（先移除心跳過期的節點）
+func (uc *lobbyUsecase) GetGameNodes(ctx context.Context) ([]*GameNode, error) {
+	if uc.nodeRegistry == nil {
+		return nil, nil
+	}
+
+	if _, err := uc.nodeRegistry.EvictExpiredNodes(ctx, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to evict expired game servers: %w", err)
+	}
+
+	nodes, err := uc.nodeRegistry.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list game servers: %w", err)
+	}
+
+	if nodes == nil {
+		nodes = []*GameNode{}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeID < nodes[j].NodeID })
+	return nodes, nil
+}
+
+// DrainGameServer 請求節點進入下線模式，節點在下次心跳時開始下線
+func (uc *lobbyUsecase) DrainGameServer(ctx context.Context, nodeID string) error {
+	nodes, err := uc.GetGameNodes(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := nodesByID(nodes)[nodeID]; !ok {
+		return fmt.Errorf("%w: %s", ErrGameServerNotFound, nodeID)
+	}
+
+	if err := uc.nodeRegistry.RequestDrain(ctx, nodeID); err != nil {
+		return fmt.Errorf("failed to request drain: %w", err)
+	}
+	return nil
+}
+
+// routeRooms 過濾掉不在線或下線模式中節點的房間，並填充房間所在節點的連接地址
+// 未啟用節點註冊時（nodes 為 nil）原樣返回
+func routeRooms(rooms []*RoomInfo, nodes []*GameNode) []*RoomInfo {
+	if nodes == nil {
+		return rooms
+	}
+
+	byID := nodesByID(activeNodes(nodes))
+	routed := make([]*RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		node, ok := byID[room.GameServerID]
+		if !ok {
+			continue
+		}
+		room.GameServerURL = node.URL
+		routed = append(routed, room)
+	}
+	return routed
+}
+
+// activeNodes 過濾掉下線模式中的節點
+func activeNodes(nodes []*GameNode) []*GameNode {
+	active := make([]*GameNode, 0, len(nodes))
+	for _, node := range nodes {
+		if !node.Draining {
+			active = append(active, node)
+		}
+	}
+	return active
+}
+
+// leastLoadedNode 選擇連接數最少的節點，連接數相同時選擇房間數較少的節點
+func leastLoadedNode(nodes []*GameNode) *GameNode {
+	best := nodes[0]
+	for _, node := range nodes[1:] {
+		if node.Connections < best.Connections ||
+			(node.Connections == best.Connections && node.Rooms < best.Rooms) {
+			best = node
+		}
+	}
+	return best
+}
+
+// nodesByID 按節點 ID 建立索引
+func nodesByID(nodes []*GameNode) map[string]*GameNode {
+	byID := make(map[string]*GameNode, len(nodes))
+	for _, node := range nodes {
+		byID[node.NodeID] = node
+	}
+	return byID
+}
@@ -0,0 +1,219 @@
+package lobby
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNodeRegistry 記憶體中的節點註冊表，按過期時間模擬心跳
+type fakeNodeRegistry struct {
+	nodes   map[string]*GameNode
+	expires map[string]time.Time
+	drains  []string
+}
+
+func newFakeNodeRegistry() *fakeNodeRegistry {
+	return &fakeNodeRegistry{nodes: map[string]*GameNode{}, expires: map[string]time.Time{}}
+}
+
+func (r *fakeNodeRegistry) RegisterNode(ctx context.Context, node *GameNode, ttl time.Duration) error {
+	r.nodes[node.NodeID] = node
+	r.expires[node.NodeID] = time.Now().Add(ttl)
+	return nil
+}
+
+func (r *fakeNodeRegistry) DeregisterNode(ctx context.Context, nodeID string) error {
+	delete(r.nodes, nodeID)
+	delete(r.expires, nodeID)
+	return nil
+}
+
+func (r *fakeNodeRegistry) RequestDrain(ctx context.Context, nodeID string) error {
+	r.drains = append(r.drains, nodeID)
+	return nil
+}
+
+func (r *fakeNodeRegistry) DrainRequested(ctx context.Context, nodeID string) (bool, error) {
+	for _, id := range r.drains {
+		if id == nodeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeNodeRegistry) ListNodes(ctx context.Context) ([]*GameNode, error) {
+	var nodes []*GameNode
+	for _, node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (r *fakeNodeRegistry) EvictExpiredNodes(ctx context.Context, now time.Time) ([]string, error) {
+	var evicted []string
+	for id, expiresAt := range r.expires {
+		if expiresAt.Before(now) {
+			evicted = append(evicted, id)
+			_ = r.DeregisterNode(ctx, id)
+		}
+	}
+	return evicted, nil
+}
+
+// fakeRoomCache 記憶體中的房間快取
+type fakeRoomCache struct {
+	rooms map[string][]*RoomInfo
+}
+
+func (c *fakeRoomCache) GetAllRooms(ctx context.Context) ([]*RoomInfo, error) {
+	var all []*RoomInfo
+	for _, rooms := range c.rooms {
+		all = append(all, rooms...)
+	}
+	return all, nil
+}
+
+func (c *fakeRoomCache) UpdateRoomInfo(ctx context.Context, gameServerID string, rooms []*RoomInfo) error {
+	c.rooms[gameServerID] = rooms
+	return nil
+}
+
+func setupCluster(t *testing.T) (*lobbyUsecase, *fakeNodeRegistry) {
+	ctx := context.Background()
+	registry := newFakeNodeRegistry()
+	cache := &fakeRoomCache{rooms: map[string][]*RoomInfo{}}
+
+	require.NoError(t, registry.RegisterNode(ctx, &GameNode{NodeID: "node-a", URL: "ws://a/ws", Connections: 10, Rooms: 1}, time.Minute))
+	require.NoError(t, registry.RegisterNode(ctx, &GameNode{NodeID: "node-b", URL: "ws://b/ws", Connections: 2, Rooms: 1}, time.Minute))
+	require.NoError(t, registry.RegisterNode(ctx, &GameNode{NodeID: "node-dead", URL: "ws://dead/ws"}, -time.Second))
+
+	require.NoError(t, cache.UpdateRoomInfo(ctx, "node-a", []*RoomInfo{{RoomID: "room_a", GameServerID: "node-a"}}))
+	require.NoError(t, cache.UpdateRoomInfo(ctx, "node-b", []*RoomInfo{{RoomID: "room_b", GameServerID: "node-b"}}))
+	require.NoError(t, cache.UpdateRoomInfo(ctx, "node-dead", []*RoomInfo{{RoomID: "room_dead", GameServerID: "node-dead"}}))
+
+	uc := &lobbyUsecase{roomCache: cache, nodeRegistry: registry}
+	return uc, registry
+}
+
+func TestGetGameNodes_EvictsExpired(t *testing.T) {
+	uc, registry := setupCluster(t)
+
+	nodes, err := uc.GetGameNodes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "node-a", nodes[0].NodeID)
+	assert.Equal(t, "node-b", nodes[1].NodeID)
+	assert.NotContains(t, registry.nodes, "node-dead")
+}
+
+func TestRouteGameServer(t *testing.T) {
+	uc, _ := setupCluster(t)
+	ctx := context.Background()
+
+	node, err := uc.RouteGameServer(ctx, "room_a")
+	require.NoError(t, err)
+	assert.Equal(t, "ws://a/ws", node.URL)
+
+	// 未指定房間時選擇負載最低的節點
+	node, err = uc.RouteGameServer(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", node.NodeID)
+
+	// 節點已下線的房間不可路由
+	_, err = uc.RouteGameServer(ctx, "room_dead")
+	assert.True(t, errors.Is(err, ErrRoomNotRouted))
+
+	_, err = uc.RouteGameServer(ctx, "room_unknown")
+	assert.True(t, errors.Is(err, ErrRoomNotRouted))
+}
+
+func TestRouteGameServer_NoNodes(t *testing.T) {
+	uc := &lobbyUsecase{roomCache: &fakeRoomCache{rooms: map[string][]*RoomInfo{}}, nodeRegistry: newFakeNodeRegistry()}
+
+	_, err := uc.RouteGameServer(context.Background(), "")
+	assert.True(t, errors.Is(err, ErrNoGameServer))
+}
+
+func TestGetRoomList_FiltersDeadNodes(t *testing.T) {
+	uc, _ := setupCluster(t)
+
+	rooms, err := uc.GetRoomList(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rooms, 2)
+	for _, room := range rooms {
+		assert.NotEqual(t, "room_dead", room.RoomID)
+		assert.NotEmpty(t, room.GameServerURL)
+	}
+}
+
+func TestGetRoomList_WithoutRegistry(t *testing.T) {
+	cache := &fakeRoomCache{rooms: map[string][]*RoomInfo{
+		"node-a": {{RoomID: "room_a", GameServerID: "node-a"}},
+	}}
+	uc := &lobbyUsecase{roomCache: cache}
+
+	rooms, err := uc.GetRoomList(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, rooms, 1)
+}
+
+func TestRouteGameServer_SkipsDrainingNodes(t *testing.T) {
+	uc, registry := setupCluster(t)
+	ctx := context.Background()
+	registry.nodes["node-b"].Draining = true
+
+	node, err := uc.RouteGameServer(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", node.NodeID)
+
+	_, err = uc.RouteGameServer(ctx, "room_b")
+	assert.True(t, errors.Is(err, ErrRoomNotRouted))
+
+	rooms, err := uc.GetRoomList(ctx)
+	require.NoError(t, err)
+	require.Len(t, rooms, 1)
+	assert.Equal(t, "room_a", rooms[0].RoomID)
+}
+
+func TestDrainGameServer(t *testing.T) {
+	uc, registry := setupCluster(t)
+	ctx := context.Background()
+
+	require.NoError(t, uc.DrainGameServer(ctx, "node-a"))
+	requested, err := registry.DrainRequested(ctx, "node-a")
+	require.NoError(t, err)
+	assert.True(t, requested)
+
+	err = uc.DrainGameServer(ctx, "node-dead")
+	assert.True(t, errors.Is(err, ErrGameServerNotFound))
+}
+
+func TestPrivateRooms_HiddenButRoutableByInviteCode(t *testing.T) {
+	uc, _ := setupCluster(t)
+	ctx := context.Background()
+	cache := uc.roomCache.(*fakeRoomCache)
+	require.NoError(t, cache.UpdateRoomInfo(ctx, "node-b", []*RoomInfo{
+		{RoomID: "room_b", GameServerID: "node-b"},
+		{RoomID: "private_b", GameServerID: "node-b", Private: true, InviteCode: "ABC234"},
+	}))
+
+	rooms, err := uc.GetRoomList(ctx)
+	require.NoError(t, err)
+	for _, room := range rooms {
+		assert.NotEqual(t, "private_b", room.RoomID)
+	}
+
+	room, node, err := uc.RouteInviteCode(ctx, " abc234 ")
+	require.NoError(t, err)
+	assert.Equal(t, "private_b", room.RoomID)
+	assert.Equal(t, "node-b", node.NodeID)
+
+	_, _, err = uc.RouteInviteCode(ctx, "ZZZZZZ")
+	assert.True(t, errors.Is(err, ErrInviteNotFound))
+}
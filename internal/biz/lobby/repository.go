@@ -2,6 +2,7 @@ package lobby
 
 import (
 	"context"
+	"time"
 )
 
 // LobbyRepository interface is implemented in data/postgres/lobby.go and data/redis/lobby.go
@@ -30,3 +31,33 @@ type RoomCache interface {
 	// UpdateRoomInfo 更新房間資訊（Game Server 使用）
 	UpdateRoomInfo(ctx context.Context, gameServerID string, rooms []*RoomInfo) error
 }
+
+// NodeRegistry 定義 Game Server 節點註冊介面（Redis）
+type NodeRegistry interface {
+	// RegisterNode 註冊或刷新節點（Game Server 心跳使用），ttl 內未刷新即視為下線
+	RegisterNode(ctx context.Context, node *GameNode, ttl time.Duration) error
+
+	// DeregisterNode 移除節點及其上報的房間
+	DeregisterNode(ctx context.Context, nodeID string) error
+
+	// RequestDrain 請求節點進入下線模式（管理後台使用）
+	RequestDrain(ctx context.Context, nodeID string) error
+
+	// DrainRequested 檢查節點是否有待處理的下線請求（Game Server 心跳時使用）
+	DrainRequested(ctx context.Context, nodeID string) (bool, error)
+
+	// ListNodes 獲取所有在線節點
+	ListNodes(ctx context.Context) ([]*GameNode, error)
+
+	// EvictExpiredNodes 移除在 now 之前已過期的節點，返回被移除的節點 ID
+	EvictExpiredNodes(ctx context.Context, now time.Time) ([]string, error)
+}
+
+// RoomEventBoard 定義定時活動看板介面（Redis），Game Server 的活動排程器寫入正在進行和即將開始的活動
+type RoomEventBoard interface {
+	// PublishRoomEvents 替換活動看板，ttl 內未刷新即視為沒有活動
+	PublishRoomEvents(ctx context.Context, events []*RoomEvent, ttl time.Duration) error
+
+	// GetRoomEvents 獲取活動看板上的活動
+	GetRoomEvents(ctx context.Context) ([]*RoomEvent, error)
+}
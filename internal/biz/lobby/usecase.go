@@ -3,6 +3,7 @@ package lobby
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // LobbyUsecase implements lobby business logic
@@ -13,15 +14,30 @@ import (
 
 // LobbyUsecase 定義大廳業務邏輯的介面
 type LobbyUsecase interface {
-	// GetRoomList 獲取遊戲房間列表
+	// GetRoomList 獲取遊戲房間列表（僅包含在線節點的房間）
 	GetRoomList(ctx context.Context) ([]*RoomInfo, error)
 
+	// RouteGameServer 為房間選擇 Game Server，roomID 為空時選擇負載最低的節點
+	RouteGameServer(ctx context.Context, roomID string) (*GameNode, error)
+
+	// RouteInviteCode 按邀請碼查找私人房間所在的 Game Server
+	RouteInviteCode(ctx context.Context, inviteCode string) (*RoomInfo, *GameNode, error)
+
+	// GetGameNodes 獲取所有在線的 Game Server 節點
+	GetGameNodes(ctx context.Context) ([]*GameNode, error)
+
+	// DrainGameServer 請求 Game Server 節點進入下線模式（管理員功能）
+	DrainGameServer(ctx context.Context, nodeID string) error
+
 	// GetPlayerStatus 獲取玩家狀態
 	GetPlayerStatus(ctx context.Context, userID int64) (*PlayerStatus, error)
 
 	// GetAnnouncements 獲取公告列表
 	GetAnnouncements(ctx context.Context, limit int) ([]*Announcement, error)
 
+	// GetRoomEvents 獲取正在進行和即將開始的定時活動
+	GetRoomEvents(ctx context.Context) ([]*RoomEvent, error)
+
 	// CreateAnnouncement 建立新公告（管理員功能）
 	CreateAnnouncement(ctx context.Context, title, content string, priority int) error
 
@@ -36,11 +52,16 @@ type LobbyUsecase interface {
 type RoomInfo struct {
 	RoomID          string `json:"room_id"`
 	RoomName        string `json:"room_name"`
+	Status          string `json:"status,omitempty"` // 房間生命週期狀態
 	BetMultiplier   int    `json:"bet_multiplier"`   // 下注倍率
 	MinCoins        int64  `json:"min_coins"`        // 最低金幣要求
 	CurrentPlayers  int    `json:"current_players"`  // 當前玩家數
 	MaxPlayers      int    `json:"max_players"`      // 最大玩家數
 	GameServerID    string `json:"game_server_id"`   // Game Server 實例 ID
+	GameServerURL   string `json:"game_server_url,omitempty"` // 房間所在節點的 WebSocket 地址（由大廳路由填充）
+	Private         bool   `json:"private,omitempty"`         // 私人房間不出現在房間列表中
+	InviteCode      string `json:"invite_code,omitempty"`     // 私人房間邀請碼，用於跨節點路由
+	ConfigVersion   int64  `json:"config_version,omitempty"`  // 房間當前套用的配置版本
 }
 
 // PlayerStatus 玩家狀態
@@ -48,7 +69,7 @@ type PlayerStatus struct {
 	UserID    int64  `json:"user_id"`
 	Nickname  string `json:"nickname"`
 	AvatarURL string `json:"avatar_url"`
-	Coins     int64  `json:"coins"`      // 金幣數量
+	Coins     int64  `json:"coins"`      // 金幣數量：主錢包餘額（分），與遊戲內餘額相同
 	Level     int    `json:"level"`      // 等級
 	EXP       int64  `json:"exp"`        // 經驗值
 }
@@ -60,6 +81,24 @@ type Announcement struct {
 	Content   string `json:"content"`
 	Priority  int    `json:"priority"`   // 優先級（數字越大越重要）
 	CreatedAt string `json:"created_at"`
+	EventID   string `json:"event_id,omitempty"` // 正在進行的定時活動，非活動公告為空
+}
+
+// eventAnnouncementPriority 正在進行的活動公告的優先級，排在所有公告之前
+const eventAnnouncementPriority = 100
+
+// RoomEvent 定時活動
+type RoomEvent struct {
+	ID               string    `json:"id"`
+	ScheduleID       int64     `json:"schedule_id"`
+	Name             string    `json:"name"`
+	Type             string    `json:"type"`                 // double_reward, boss_rush, tide_storm
+	RoomTypes        []string  `json:"room_types,omitempty"` // 生效的房間類型，空表示所有類型
+	StartsAt         time.Time `json:"starts_at"`
+	EndsAt           time.Time `json:"ends_at"`
+	RewardMultiplier float64   `json:"reward_multiplier,omitempty"`
+	Announcement     string    `json:"announcement,omitempty"`
+	Active           bool      `json:"active"` // 查詢時是否正在進行
 }
 
 // WalletRepo 定義錢包資料訪問介面
@@ -74,19 +113,23 @@ type PlayerRepo interface {
 
 // lobbyUsecase 實現 LobbyUsecase 介面
 type lobbyUsecase struct {
-	lobbyRepo  LobbyRepo
-	roomCache  RoomCache
-	walletRepo WalletRepo
-	playerRepo PlayerRepo
+	lobbyRepo    LobbyRepo
+	roomCache    RoomCache
+	nodeRegistry NodeRegistry
+	walletRepo   WalletRepo
+	playerRepo   PlayerRepo
+	eventBoard   RoomEventBoard
 }
 
 // NewLobbyUsecase 建立新的 LobbyUsecase 實例
-func NewLobbyUsecase(lobbyRepo LobbyRepo, roomCache RoomCache, walletRepo WalletRepo, playerRepo PlayerRepo) LobbyUsecase {
+func NewLobbyUsecase(lobbyRepo LobbyRepo, roomCache RoomCache, nodeRegistry NodeRegistry, walletRepo WalletRepo, playerRepo PlayerRepo, eventBoard RoomEventBoard) LobbyUsecase {
 	return &lobbyUsecase{
-		lobbyRepo:  lobbyRepo,
-		roomCache:  roomCache,
-		walletRepo: walletRepo,
-		playerRepo: playerRepo,
+		lobbyRepo:    lobbyRepo,
+		roomCache:    roomCache,
+		nodeRegistry: nodeRegistry,
+		walletRepo:   walletRepo,
+		playerRepo:   playerRepo,
+		eventBoard:   eventBoard,
 	}
 }
 
@@ -97,7 +140,23 @@ func (uc *lobbyUsecase) GetRoomList(ctx context.Context) ([]*RoomInfo, error) {
 		return nil, fmt.Errorf("failed to get rooms from cache: %w", err)
 	}
 
-	return rooms, nil
+	nodes, err := uc.GetGameNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return routeRooms(publicRooms(rooms), nodes), nil
+}
+
+// publicRooms 過濾掉私人房間
+func publicRooms(rooms []*RoomInfo) []*RoomInfo {
+	public := make([]*RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		if !room.Private {
+			public = append(public, room)
+		}
+	}
+	return public
 }
 
 // GetPlayerStatus 獲取玩家狀態
@@ -130,7 +189,56 @@ func (uc *lobbyUsecase) GetAnnouncements(ctx context.Context, limit int) ([]*Ann
 		return nil, fmt.Errorf("failed to get announcements: %w", err)
 	}
 
-	return announcements, nil
+	// 正在進行的活動排在公告之前；活動看板不可用時只返回公告
+	events, err := uc.GetRoomEvents(ctx)
+	if err != nil {
+		return announcements, nil
+	}
+	var eventAnnouncements []*Announcement
+	for _, event := range events {
+		if event.Active {
+			eventAnnouncements = append(eventAnnouncements, eventAnnouncement(event))
+		}
+	}
+	return append(eventAnnouncements, announcements...), nil
+}
+
+// eventAnnouncement 將正在進行的活動轉換為公告
+func eventAnnouncement(event *RoomEvent) *Announcement {
+	content := event.Announcement
+	if content == "" {
+		content = fmt.Sprintf("%s 進行中，%s 結束", event.Name, event.EndsAt.UTC().Format(time.RFC3339))
+	}
+	return &Announcement{
+		Title:     event.Name,
+		Content:   content,
+		Priority:  eventAnnouncementPriority,
+		CreatedAt: event.StartsAt.UTC().Format(time.RFC3339),
+		EventID:   event.ID,
+	}
+}
+
+// GetRoomEvents 獲取正在進行和即將開始的定時活動，過濾掉看板上已結束的活動
+func (uc *lobbyUsecase) GetRoomEvents(ctx context.Context) ([]*RoomEvent, error) {
+	if uc.eventBoard == nil {
+		return nil, nil
+	}
+
+	events, err := uc.eventBoard.GetRoomEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room events: %w", err)
+	}
+
+	now := time.Now()
+	current := make([]*RoomEvent, 0, len(events))
+	for _, event := range events {
+		if !event.EndsAt.After(now) {
+			continue
+		}
+		event.Active = !event.StartsAt.After(now)
+		current = append(current, event)
+	}
+	return current, nil
 }
 
 // CreateAnnouncement 建立新公告（管理員功能）
@@ -0,0 +1,91 @@
+package lobby
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLobbyRepo 記憶體中的公告存儲
+type fakeLobbyRepo struct {
+	announcements []*Announcement
+}
+
+func (r *fakeLobbyRepo) GetAnnouncements(ctx context.Context, limit int) ([]*Announcement, error) {
+	if len(r.announcements) > limit {
+		return r.announcements[:limit], nil
+	}
+	return r.announcements, nil
+}
+
+func (r *fakeLobbyRepo) CreateAnnouncement(ctx context.Context, title, content string, priority int) error {
+	return nil
+}
+
+func (r *fakeLobbyRepo) UpdateAnnouncement(ctx context.Context, id int64, title, content string, priority int) error {
+	return nil
+}
+
+func (r *fakeLobbyRepo) DeleteAnnouncement(ctx context.Context, id int64) error {
+	return nil
+}
+
+// fakeRoomEventBoard 記憶體中的活動看板
+type fakeRoomEventBoard struct {
+	events []*RoomEvent
+	err    error
+}
+
+func (b *fakeRoomEventBoard) PublishRoomEvents(ctx context.Context, events []*RoomEvent, ttl time.Duration) error {
+	b.events = events
+	return nil
+}
+
+func (b *fakeRoomEventBoard) GetRoomEvents(ctx context.Context) ([]*RoomEvent, error) {
+	return b.events, b.err
+}
+
+func TestGetRoomEventsAndAnnouncements(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := &fakeLobbyRepo{announcements: []*Announcement{{ID: 1, Title: "維護公告", Priority: 5}}}
+	board := &fakeRoomEventBoard{events: []*RoomEvent{
+		{ID: "1-100", Name: "已結束", StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)},
+		{ID: "2-200", Name: "雙倍獎勵", Type: "double_reward", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), Announcement: "雙倍獎勵進行中"},
+		{ID: "3-300", Name: "Boss 狂潮", Type: "boss_rush", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)},
+	}}
+	uc := &lobbyUsecase{lobbyRepo: repo, eventBoard: board}
+
+	events, err := uc.GetRoomEvents(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.True(t, events[0].Active)
+	assert.False(t, events[1].Active)
+
+	// 正在進行的活動排在公告之前
+	announcements, err := uc.GetAnnouncements(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, announcements, 2)
+	assert.Equal(t, "2-200", announcements[0].EventID)
+	assert.Equal(t, "雙倍獎勵進行中", announcements[0].Content)
+	assert.Equal(t, eventAnnouncementPriority, announcements[0].Priority)
+	assert.Equal(t, int64(1), announcements[1].ID)
+
+	// 活動看板不可用時只返回公告
+	board.err = errors.New("redis unavailable")
+	announcements, err = uc.GetAnnouncements(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, announcements, 1)
+	_, err = uc.GetRoomEvents(ctx)
+	require.Error(t, err)
+
+	// 沒有配置活動看板
+	uc.eventBoard = nil
+	events, err = uc.GetRoomEvents(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
@@ -1,17 +1,40 @@
 // internal/biz/player/player.go
 package player
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
-// Player 是玩家的領域模型
+// ErrPlayerNotFound 玩家帳號不存在
+var ErrPlayerNotFound = errors.New("player not found")
+
+// Player 是玩家的登入憑證，僅供舊版 gRPC 帳號密碼登入使用
 type Player struct {
 	ID           uint
 	Username     string
 	PasswordHash string // 資料庫中應儲存密碼的雜湊值，而非明文
 }
 
+// Profile 玩家資料，以帳號 ID（users.id）為唯一標識。
+// 遊戲內玩家（game.Player）、大廳玩家狀態和管理後台都從這裡構建，餘額和錢包 ID 只來自主錢包，不另外保存
+type Profile struct {
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username,omitempty"`
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+	IsGuest   bool   `json:"is_guest"`
+	Level     int    `json:"level"`
+	EXP       int64  `json:"exp"`
+	Active    bool   `json:"active"`    // 帳號是否啟用
+	WalletID  uint   `json:"wallet_id"` // 主錢包 ID，沒有主錢包時為 0
+	Currency  string `json:"currency"`
+	Balance   int64  `json:"balance"` // 主錢包餘額（分）
+}
+
 // PlayerRepo 定義了玩家數據倉庫的接口
 type PlayerRepo interface {
 	FindByUsername(ctx context.Context, username string) (*Player, error)
-	Create(ctx context.Context, player *Player) (*Player, error)
+	// GetProfile 按帳號 ID 讀取玩家資料（包括已停用的帳號），不存在時返回 nil
+	GetProfile(ctx context.Context, userID int64) (*Profile, error)
 }
@@ -64,34 +64,15 @@ func (uc *PlayerUsecase) Login(ctx context.Context, username, password string) (
 	return token, nil
 }
 
-// GetOrCreateByUsername 根據用戶名查找玩家，如果不存在則創建一個
-func (uc *PlayerUsecase) GetOrCreateByUsername(ctx context.Context, username string) (*Player, error) {
-	// 1. 嘗試查找用戶
-	player, err := uc.repo.FindByUsername(ctx, username)
+// GetProfile 按帳號 ID 獲取玩家資料
+func (uc *PlayerUsecase) GetProfile(ctx context.Context, userID int64) (*Profile, error) {
+	profile, err := uc.repo.GetProfile(ctx, userID)
 	if err != nil {
-		// 如果是資料庫本身錯誤，直接返回
-		uc.logger.Errorf("failed to find player by username %s: %v", username, err)
+		uc.logger.Errorf("failed to get profile of player %d: %v", userID, err)
 		return nil, err
 	}
-
-	// 2. 如果用戶已存在，直接返回
-	if player != nil {
-		uc.logger.Debugf("Found existing player %s with ID %d", username, player.ID)
-		return player, nil
-	}
-
-	// 3. 如果用戶不存在，創建新用戶
-	uc.logger.Infof("Player %s not found, creating a new one.", username)
-	newPlayer := &Player{
-		Username: username,
-		// PasswordHash is left empty as this user is created on-the-fly
-	}
-
-	createdPlayer, err := uc.repo.Create(ctx, newPlayer)
-	if err != nil {
-		uc.logger.Errorf("failed to create new player %s: %v", username, err)
-		return nil, err
+	if profile == nil {
+		return nil, ErrPlayerNotFound
 	}
-
-	return createdPlayer, nil
+	return profile, nil
 }
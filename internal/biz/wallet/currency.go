@@ -0,0 +1,59 @@
+// internal/biz/wallet/currency.go
+package wallet
+
+import "strings"
+
+// CurrencyKind 幣種類別
+type CurrencyKind string
+
+const (
+	CurrencyKindReal  CurrencyKind = "real"  // 真實貨幣，可以充值和提款
+	CurrencyKindFree  CurrencyKind = "free"  // 免費金幣
+	CurrencyKindEvent CurrencyKind = "event" // 活動代幣
+)
+
+// 支持的幣種代碼
+const (
+	CurrencyCNY   = "CNY"
+	CurrencyCoin  = "COIN"
+	CurrencyToken = "TOKEN"
+)
+
+// DefaultCurrency 主錢包幣種，註冊時創建，沒有聲明遊戲幣種的房間使用
+const DefaultCurrency = CurrencyCNY
+
+// Currency 幣種定義
+type Currency struct {
+	Code string       `json:"code"`
+	Name string       `json:"name"`
+	Kind CurrencyKind `json:"kind"`
+}
+
+// currencies 幣種目錄，玩家每種幣種最多一個錢包（wallets 按 (user_id, currency) 唯一）
+var currencies = []Currency{
+	{Code: CurrencyCNY, Name: "人民幣", Kind: CurrencyKindReal},
+	{Code: CurrencyCoin, Name: "免費金幣", Kind: CurrencyKindFree},
+	{Code: CurrencyToken, Name: "活動代幣", Kind: CurrencyKindEvent},
+}
+
+// Currencies 返回所有支持的幣種
+func Currencies() []Currency {
+	out := make([]Currency, len(currencies))
+	copy(out, currencies)
+	return out
+}
+
+// LookupCurrency 按代碼查找幣種
+func LookupCurrency(code string) (Currency, bool) {
+	for _, c := range currencies {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return Currency{}, false
+}
+
+// NormalizeCurrency 規範化幣種代碼（去空白、大寫）
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,20 @@
+// internal/biz/wallet/errors.go
+package wallet
+
+import "errors"
+
+// 錢包業務錯誤，由 WalletRepo 實現返回，調用方通過 errors.Is 判斷
+var (
+	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrWalletFrozen        = errors.New("wallet is frozen")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrInvalidAmount       = errors.New("amount must be positive")
+	ErrUnknownCurrency     = errors.New("unknown currency")
+)
+
+// 幣種兌換錯誤
+var (
+	ErrExchangeNotAllowed   = errors.New("currency exchange not allowed")
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidExchangeRate  = errors.New("invalid exchange rate")
+)
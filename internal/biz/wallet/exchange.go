@@ -0,0 +1,188 @@
+// internal/biz/wallet/exchange.go
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+)
+
+// 兌換流水的交易類型，兩邊流水使用相同的參考 ID
+const (
+	TxTypeExchangeOut = "exchange_out"
+	TxTypeExchangeIn  = "exchange_in"
+)
+
+// ExchangeRate 管理後台配置的兌換比例，只有存在且啟用的方向可以兌換
+type ExchangeRate struct {
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Rate         float64   `json:"rate"` // 1 單位來源幣種兌換的目標幣種數量
+	Enabled      bool      `json:"enabled"`
+	UpdatedBy    string    `json:"updated_by,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Exchange 一次兌換，由 ExchangeRepo.Exchange 在同一事務中完成扣款和入帳
+type Exchange struct {
+	ReferenceID  string  `json:"reference_id"`
+	UserID       uint    `json:"user_id"`
+	FromWalletID uint    `json:"from_wallet_id"`
+	ToWalletID   uint    `json:"to_wallet_id"`
+	FromCurrency string  `json:"from_currency"`
+	ToCurrency   string  `json:"to_currency"`
+	Debited      float64 `json:"debited"`
+	Credited     float64 `json:"credited"`
+	Rate         float64 `json:"rate"`
+	FromBalance  float64 `json:"from_balance"` // 兌換後來源錢包餘額，由 Exchange 寫回
+	ToBalance    float64 `json:"to_balance"`   // 兌換後目標錢包餘額，由 Exchange 寫回
+}
+
+// ExchangeRepo 兌換比例和原子兌換的存儲
+type ExchangeRepo interface {
+	ListExchangeRates(ctx context.Context) ([]*ExchangeRate, error)
+	// GetExchangeRate 沒有配置時返回 nil, nil
+	GetExchangeRate(ctx context.Context, from, to string) (*ExchangeRate, error)
+	SaveExchangeRate(ctx context.Context, rate *ExchangeRate) error
+	DeleteExchangeRate(ctx context.Context, from, to string) (bool, error)
+	// Exchange 鎖定兩個錢包，從來源錢包扣款並向目標錢包入帳，兩邊各記一筆流水
+	// 錢包不存在、凍結或餘額不足時返回對應的錢包錯誤且不做任何修改
+	Exchange(ctx context.Context, ex *Exchange) error
+}
+
+// ExchangeUsecase 幣種兌換
+type ExchangeUsecase struct {
+	wallets WalletRepo
+	repo    ExchangeRepo
+	logger  logger.Logger
+}
+
+// NewExchangeUsecase 創建幣種兌換用例
+func NewExchangeUsecase(wallets WalletRepo, repo ExchangeRepo, logger logger.Logger) *ExchangeUsecase {
+	return &ExchangeUsecase{
+		wallets: wallets,
+		repo:    repo,
+		logger:  logger.With("module", "biz/wallet/exchange"),
+	}
+}
+
+// ListRates 返回所有兌換比例（包括停用的）
+func (uc *ExchangeUsecase) ListRates(ctx context.Context) ([]*ExchangeRate, error) {
+	return uc.repo.ListExchangeRates(ctx)
+}
+
+// SetRate 新增或修改一個方向的兌換比例
+func (uc *ExchangeUsecase) SetRate(ctx context.Context, rate *ExchangeRate) error {
+	rate.FromCurrency = NormalizeCurrency(rate.FromCurrency)
+	rate.ToCurrency = NormalizeCurrency(rate.ToCurrency)
+	for _, code := range []string{rate.FromCurrency, rate.ToCurrency} {
+		if _, ok := LookupCurrency(code); !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+		}
+	}
+	if rate.FromCurrency == rate.ToCurrency {
+		return fmt.Errorf("%w: from and to currency must differ", ErrInvalidExchangeRate)
+	}
+	if !(rate.Rate > 0) || math.IsInf(rate.Rate, 0) {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidExchangeRate)
+	}
+	if err := uc.repo.SaveExchangeRate(ctx, rate); err != nil {
+		return err
+	}
+	uc.logger.Infof("exchange rate %s->%s set to %v (enabled=%v) by %s",
+		rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.Enabled, rate.UpdatedBy)
+	return nil
+}
+
+// DeleteRate 刪除一個方向的兌換比例
+func (uc *ExchangeUsecase) DeleteRate(ctx context.Context, from, to string) error {
+	deleted, err := uc.repo.DeleteExchangeRate(ctx, NormalizeCurrency(from), NormalizeCurrency(to))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrExchangeRateNotFound
+	}
+	return nil
+}
+
+// Exchange 按配置的比例把玩家 amount 數量的來源幣種兌換為目標幣種
+// 入帳金額向下取整到分，目標幣種錢包不存在時自動創建
+func (uc *ExchangeUsecase) Exchange(ctx context.Context, userID uint, from, to string, amount float64) (*Exchange, error) {
+	from, to = NormalizeCurrency(from), NormalizeCurrency(to)
+	for _, code := range []string{from, to} {
+		if _, ok := LookupCurrency(code); !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+		}
+	}
+	amount = math.Round(amount*100) / 100
+	if !(amount > 0) {
+		return nil, fmt.Errorf("exchange %w", ErrInvalidAmount)
+	}
+
+	rate, err := uc.repo.GetExchangeRate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if from == to || rate == nil || !rate.Enabled {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrExchangeNotAllowed, from, to)
+	}
+	credited := exchangeCredit(amount, rate.Rate)
+	if credited <= 0 {
+		return nil, fmt.Errorf("exchange %w: amount too small for rate %v", ErrInvalidAmount, rate.Rate)
+	}
+
+	source, err := uc.wallets.FindByUserID(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := uc.ensureWallet(ctx, userID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ex := &Exchange{
+		ReferenceID:  fmt.Sprintf("exchange:%d:%d", userID, time.Now().UnixNano()),
+		UserID:       userID,
+		FromWalletID: source.ID,
+		ToWalletID:   target.ID,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Debited:      amount,
+		Credited:     credited,
+		Rate:         rate.Rate,
+	}
+	if err := uc.repo.Exchange(ctx, ex); err != nil {
+		return nil, err
+	}
+	uc.logger.Infof("user %d exchanged %.2f %s to %.2f %s (ref=%s)", userID, amount, from, credited, to, ex.ReferenceID)
+	return ex, nil
+}
+
+// ensureWallet 返回玩家該幣種的錢包，不存在時創建；並發創建衝突時重新查詢
+func (uc *ExchangeUsecase) ensureWallet(ctx context.Context, userID uint, currency string) (*Wallet, error) {
+	w, err := uc.wallets.FindByUserID(ctx, userID, currency)
+	if err == nil {
+		return w, nil
+	}
+	if !errors.Is(err, ErrWalletNotFound) {
+		return nil, err
+	}
+	w = &Wallet{UserID: userID, Currency: currency, Status: 1}
+	if err := uc.wallets.Create(ctx, w); err != nil {
+		if existing, findErr := uc.wallets.FindByUserID(ctx, userID, currency); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+// exchangeCredit 計算入帳金額，向下取整到分（加上微小容差避免浮點誤差少算一分）
+func exchangeCredit(amount, rate float64) float64 {
+	return math.Floor(amount*rate*100+1e-6) / 100
+}
@@ -0,0 +1,175 @@
+package wallet
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWalletRepo 記憶體中的錢包，只實現兌換用到的查詢和創建
+type fakeWalletRepo struct {
+	WalletRepo
+	wallets []*Wallet
+}
+
+func (r *fakeWalletRepo) FindByUserID(ctx context.Context, userID uint, currency string) (*Wallet, error) {
+	for _, w := range r.wallets {
+		if w.UserID == userID && w.Currency == currency {
+			return w, nil
+		}
+	}
+	return nil, ErrWalletNotFound
+}
+
+func (r *fakeWalletRepo) Create(ctx context.Context, w *Wallet) error {
+	w.ID = uint(len(r.wallets) + 1)
+	r.wallets = append(r.wallets, w)
+	return nil
+}
+
+func (r *fakeWalletRepo) byID(id uint) *Wallet {
+	for _, w := range r.wallets {
+		if w.ID == id {
+			return w
+		}
+	}
+	return nil
+}
+
+// fakeExchangeRepo 記憶體中的兌換比例，兌換直接修改 fakeWalletRepo 中的餘額並記錄流水
+type fakeExchangeRepo struct {
+	wallets *fakeWalletRepo
+	rates   map[string]*ExchangeRate
+	ledger  []*Transaction
+}
+
+func newFakeExchangeRepo(wallets *fakeWalletRepo) *fakeExchangeRepo {
+	return &fakeExchangeRepo{wallets: wallets, rates: make(map[string]*ExchangeRate)}
+}
+
+func (r *fakeExchangeRepo) ListExchangeRates(ctx context.Context) ([]*ExchangeRate, error) {
+	var rates []*ExchangeRate
+	for _, rate := range r.rates {
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func (r *fakeExchangeRepo) GetExchangeRate(ctx context.Context, from, to string) (*ExchangeRate, error) {
+	return r.rates[from+"-"+to], nil
+}
+
+func (r *fakeExchangeRepo) SaveExchangeRate(ctx context.Context, rate *ExchangeRate) error {
+	r.rates[rate.FromCurrency+"-"+rate.ToCurrency] = rate
+	return nil
+}
+
+func (r *fakeExchangeRepo) DeleteExchangeRate(ctx context.Context, from, to string) (bool, error) {
+	_, ok := r.rates[from+"-"+to]
+	delete(r.rates, from+"-"+to)
+	return ok, nil
+}
+
+func (r *fakeExchangeRepo) Exchange(ctx context.Context, ex *Exchange) error {
+	source, target := r.wallets.byID(ex.FromWalletID), r.wallets.byID(ex.ToWalletID)
+	if source == nil || target == nil {
+		return ErrWalletNotFound
+	}
+	if source.Status != 1 || target.Status != 1 {
+		return ErrWalletFrozen
+	}
+	if source.Balance < ex.Debited {
+		return ErrInsufficientBalance
+	}
+	source.Balance -= ex.Debited
+	target.Balance += ex.Credited
+	r.ledger = append(r.ledger,
+		&Transaction{WalletID: source.ID, Amount: -ex.Debited, Type: TxTypeExchangeOut, ReferenceID: ex.ReferenceID},
+		&Transaction{WalletID: target.ID, Amount: ex.Credited, Type: TxTypeExchangeIn, ReferenceID: ex.ReferenceID},
+	)
+	ex.FromBalance, ex.ToBalance = source.Balance, target.Balance
+	return nil
+}
+
+func newTestExchangeUsecase(wallets ...*Wallet) (*ExchangeUsecase, *fakeWalletRepo, *fakeExchangeRepo) {
+	walletRepo := &fakeWalletRepo{wallets: wallets}
+	repo := newFakeExchangeRepo(walletRepo)
+	return NewExchangeUsecase(walletRepo, repo, logger.New(io.Discard, "info", "console")), walletRepo, repo
+}
+
+func TestExchangeUsecase_SetRateValidates(t *testing.T) {
+	uc, _, repo := newTestExchangeUsecase()
+	ctx := context.Background()
+
+	assert.ErrorIs(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: "CNY", ToCurrency: "USD", Rate: 1}), ErrUnknownCurrency)
+	assert.ErrorIs(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: "CNY", ToCurrency: "CNY", Rate: 1}), ErrInvalidExchangeRate)
+	assert.ErrorIs(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: "CNY", ToCurrency: "COIN", Rate: 0}), ErrInvalidExchangeRate)
+	assert.Empty(t, repo.rates)
+
+	require.NoError(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: " cny", ToCurrency: "coin", Rate: 100, Enabled: true}))
+	assert.Contains(t, repo.rates, "CNY-COIN", "currency codes are normalized")
+
+	require.NoError(t, uc.DeleteRate(ctx, "cny", "coin"))
+	assert.ErrorIs(t, uc.DeleteRate(ctx, "CNY", "COIN"), ErrExchangeRateNotFound)
+}
+
+func TestExchangeUsecase_ExchangeWritesBothSides(t *testing.T) {
+	uc, wallets, repo := newTestExchangeUsecase(&Wallet{ID: 1, UserID: 7, Balance: 50, Currency: CurrencyCNY, Status: 1})
+	ctx := context.Background()
+	require.NoError(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: CurrencyCNY, ToCurrency: CurrencyCoin, Rate: 100, Enabled: true}))
+
+	ex, err := uc.Exchange(ctx, 7, CurrencyCNY, CurrencyCoin, 12.5)
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, ex.Debited)
+	assert.Equal(t, 1250.0, ex.Credited)
+	assert.Equal(t, 37.5, ex.FromBalance)
+	assert.Equal(t, 1250.0, ex.ToBalance)
+
+	coin, err := wallets.FindByUserID(ctx, 7, CurrencyCoin)
+	require.NoError(t, err, "missing target wallet is created")
+	assert.Equal(t, ex.ToWalletID, coin.ID)
+
+	require.Len(t, repo.ledger, 2)
+	assert.Equal(t, TxTypeExchangeOut, repo.ledger[0].Type)
+	assert.Equal(t, TxTypeExchangeIn, repo.ledger[1].Type)
+	assert.Equal(t, repo.ledger[0].ReferenceID, repo.ledger[1].ReferenceID)
+}
+
+func TestExchangeUsecase_ExchangeRejects(t *testing.T) {
+	uc, _, repo := newTestExchangeUsecase(
+		&Wallet{ID: 1, UserID: 7, Balance: 10, Currency: CurrencyCNY, Status: 1},
+		&Wallet{ID: 2, UserID: 7, Balance: 0, Currency: CurrencyToken, Status: 0},
+	)
+	ctx := context.Background()
+	require.NoError(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: CurrencyCNY, ToCurrency: CurrencyCoin, Rate: 100, Enabled: true}))
+	require.NoError(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: CurrencyCNY, ToCurrency: CurrencyToken, Rate: 0.001, Enabled: true}))
+	require.NoError(t, uc.SetRate(ctx, &ExchangeRate{FromCurrency: CurrencyCoin, ToCurrency: CurrencyCNY, Rate: 0.01, Enabled: false}))
+
+	_, err := uc.Exchange(ctx, 7, CurrencyCoin, CurrencyCNY, 100)
+	assert.ErrorIs(t, err, ErrExchangeNotAllowed, "disabled direction")
+	_, err = uc.Exchange(ctx, 7, CurrencyCoin, CurrencyToken, 100)
+	assert.ErrorIs(t, err, ErrExchangeNotAllowed, "unconfigured direction")
+	_, err = uc.Exchange(ctx, 7, CurrencyCNY, "USD", 1)
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+	_, err = uc.Exchange(ctx, 7, CurrencyCNY, CurrencyCoin, -1)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = uc.Exchange(ctx, 7, CurrencyCNY, CurrencyToken, 1)
+	assert.ErrorIs(t, err, ErrInvalidAmount, "credit rounds down to zero")
+	_, err = uc.Exchange(ctx, 7, CurrencyCNY, CurrencyCoin, 20)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	_, err = uc.Exchange(ctx, 8, CurrencyCNY, CurrencyCoin, 1)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+	_, err = uc.Exchange(ctx, 7, CurrencyCNY, CurrencyToken, 10)
+	assert.ErrorIs(t, err, ErrWalletFrozen)
+	assert.Empty(t, repo.ledger)
+}
+
+func TestExchangeCreditRoundsDownToCents(t *testing.T) {
+	assert.Equal(t, 0.33, exchangeCredit(1, 1.0/3))
+	assert.Equal(t, 7.0, exchangeCredit(0.07, 100), "no float drift below the exact value")
+	assert.Equal(t, 12.34, exchangeCredit(1234, 0.01))
+}
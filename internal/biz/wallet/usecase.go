@@ -3,14 +3,25 @@ package wallet
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/b7777777v/fish_server/internal/pkg/logger"
 )
 
+// gameTxTypePrefix 遊戲內扣款（如子彈費用）的交易類型前綴，不視為提款
+const gameTxTypePrefix = "game_"
+
+// WithdrawGuard 提款前的檢查（如玩家處罰），返回錯誤時拒絕提款
+type WithdrawGuard interface {
+	CheckWithdraw(ctx context.Context, userID int64) error
+}
+
 // WalletUsecase 是錢包業務邏輯的用例
 type WalletUsecase struct {
-	repo   WalletRepo
-	logger logger.Logger
+	repo          WalletRepo
+	withdrawGuard WithdrawGuard
+	logger        logger.Logger
 }
 
 // NewWalletUsecase 創建一個新的 WalletUsecase 實例
@@ -21,6 +32,11 @@ func NewWalletUsecase(repo WalletRepo, logger logger.Logger) *WalletUsecase {
 	}
 }
 
+// SetWithdrawGuard 設置提款檢查，遊戲內扣款不受影響
+func (uc *WalletUsecase) SetWithdrawGuard(guard WithdrawGuard) {
+	uc.withdrawGuard = guard
+}
+
 // GetWallet 獲取錢包信息
 func (uc *WalletUsecase) GetWallet(ctx context.Context, id uint) (*Wallet, error) {
 	return uc.repo.FindByID(ctx, id)
@@ -37,7 +53,13 @@ func (uc *WalletUsecase) GetWalletsByUserID(ctx context.Context, userID uint) ([
 }
 
 // CreateWallet 創建錢包
+// 幣種必須在幣種目錄中
 func (uc *WalletUsecase) CreateWallet(ctx context.Context, userID uint, currency string) (*Wallet, error) {
+	currency = NormalizeCurrency(currency)
+	if _, ok := LookupCurrency(currency); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+
 	// 檢查用戶是否已有該幣種的錢包
 	existingWallet, err := uc.repo.FindByUserID(ctx, userID, currency)
 	if err == nil && existingWallet != nil {
@@ -71,6 +93,15 @@ func (uc *WalletUsecase) Deposit(ctx context.Context, walletID uint, amount floa
 
 // Withdraw 提款
 func (uc *WalletUsecase) Withdraw(ctx context.Context, walletID uint, amount float64, txType, referenceID, description string, metadata map[string]interface{}) error {
+	if uc.withdrawGuard != nil && !strings.HasPrefix(txType, gameTxTypePrefix) {
+		wallet, err := uc.repo.FindByID(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		if err := uc.withdrawGuard.CheckWithdraw(ctx, int64(wallet.UserID)); err != nil {
+			return err
+		}
+	}
 	return uc.repo.Withdraw(ctx, walletID, amount, txType, referenceID, description, metadata)
 }
 
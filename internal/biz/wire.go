@@ -3,12 +3,18 @@ package biz
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/biz/admin"
 	"github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/biz/lobby"
 	"github.com/b7777777v/fish_server/internal/biz/player"
 	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/b7777777v/fish_server/internal/conf"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
 
 	"github.com/google/wire"
 )
@@ -17,17 +23,39 @@ import (
 var ProviderSet = wire.NewSet(
 	game.ProviderSet,
 	player.NewPlayerUsecase,
-	wallet.NewWalletUsecase,
+	ProvideWalletUsecase,
+	wallet.NewExchangeUsecase,
 
 	// Account module providers
 	account.NewAccountUsecase,
-	account.NewOAuthService,
+	account.NewSessionUsecase,
+	account.NewSanctionUsecase,
+	ProvideOAuthService,
+	account.NewLoginSecurityUsecase,
+	ProvideLoginThrottlePolicy,
 	ProvideWalletCreator, // 提供 WalletCreator 給 AccountUsecase
 
+	// Guest progress and guest-to-account upgrade
+	account.NewGuestUsecase,
+	ProvideWalletCreditor,
+	ProvideGuestRecordMigrator,
+	ProvideGuestUpgradePolicy,
+
+	// Admin accounts, RBAC and audit log
+	admin.NewAdminUsecase,
+	admin.NewAuditUsecase,
+
 	// Lobby module providers
 	lobby.NewLobbyUsecase,
 )
 
+// ProvideWalletUsecase 創建 WalletUsecase，被禁止提款的玩家不能提款
+func ProvideWalletUsecase(repo wallet.WalletRepo, sanctions *account.SanctionUsecase, logger logger.Logger) *wallet.WalletUsecase {
+	uc := wallet.NewWalletUsecase(repo, logger)
+	uc.SetWithdrawGuard(sanctions)
+	return uc
+}
+
 // ProvideWalletCreator 將 WalletUsecase 轉換為 WalletCreator 介面
 func ProvideWalletCreator(uc *wallet.WalletUsecase) account.WalletCreator {
 	// 創建一個包裝器，將 CreateWallet 方法適配為 account.WalletCreator 介面
@@ -44,3 +72,140 @@ func (w *walletUsecaseWrapper) CreateWallet(ctx context.Context, userID uint, cu
 	_, err := w.uc.CreateWallet(ctx, userID, currency)
 	return err
 }
+
+// ProvideOAuthService 按配置註冊第三方平台並創建 OAuthService
+func ProvideOAuthService(c *conf.Config, states account.OAuthStateStore, logger logger.Logger) (account.OAuthService, error) {
+	registry := account.NewOAuthProviderRegistry(&http.Client{Timeout: 10 * time.Second})
+	stateTTL := 10 * time.Minute
+	if c.OAuth != nil {
+		if c.OAuth.StateTTL > 0 {
+			stateTTL = time.Duration(c.OAuth.StateTTL) * time.Second
+		}
+		for name, p := range c.OAuth.Providers {
+			if p == nil || p.ClientID == "" {
+				continue
+			}
+			err := registry.Configure(account.OAuthProviderConfig{
+				Name:         name,
+				Type:         p.Type,
+				ClientID:     p.ClientID,
+				ClientSecret: p.ClientSecret,
+				RedirectURL:  p.RedirectURL,
+				AuthURL:      p.AuthURL,
+				TokenURL:     p.TokenURL,
+				UserInfoURL:  p.UserInfoURL,
+				OpenIDURL:    p.OpenIDURL,
+				Scopes:       p.Scopes,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return account.NewOAuthService(registry, states, stateTTL, logger), nil
+}
+
+// ProvideWalletCreditor 將 WalletUsecase 轉換為 WalletCreditor 介面
+func ProvideWalletCreditor(uc *wallet.WalletUsecase) account.WalletCreditor {
+	return &walletCreditor{uc: uc}
+}
+
+// walletCreditor 向玩家指定幣種的錢包存入金額，錢包不存在時先創建
+type walletCreditor struct {
+	uc *wallet.WalletUsecase
+}
+
+func (w *walletCreditor) CreditWallet(ctx context.Context, userID int64, currency string, amount int64, txType, referenceID, description string, metadata map[string]interface{}) error {
+	userWallet, err := w.uc.CreateWallet(ctx, uint(userID), currency)
+	if err != nil {
+		return err
+	}
+	return w.uc.Deposit(ctx, userWallet.ID, float64(amount)/100.0, txType, referenceID, description, metadata)
+}
+
+// ProvideGuestRecordMigrator 把遊客入座記錄寫入 game_records
+func ProvideGuestRecordMigrator(repo game.GameRecordRepo) account.GuestRecordMigrator {
+	return &guestRecordMigrator{repo: repo}
+}
+
+// guestRecordMigrator 每條入座記錄轉換為一條已結束的遊戲記錄，金額從分轉換為元
+type guestRecordMigrator struct {
+	repo game.GameRecordRepo
+}
+
+func (m *guestRecordMigrator) MigrateGuestRounds(ctx context.Context, userID int64, nickname string, rounds []account.GuestRound) (int, error) {
+	migrated := 0
+	for i, round := range rounds {
+		record := game.NewGameRecord(userID, round.RoomID, fmt.Sprintf("guest_%s_%d", nickname, i+1))
+		record.StartTime = round.StartedAt
+		endTime := round.StartedAt
+		if round.EndedAt != nil {
+			endTime = *round.EndedAt
+		}
+		record.EndTime = &endTime
+		record.DurationSeconds = int(endTime.Sub(round.StartedAt) / time.Second)
+		record.Status = game.GameRecordStatusFinished
+		record.TotalBets = float64(round.TotalBets) / 100.0
+		record.TotalWins = float64(round.TotalWins) / 100.0
+		record.NetProfit = record.TotalWins - record.TotalBets
+		record.BulletsFired = round.BulletsFired
+		record.BulletsHit = round.FishCaught
+		record.FishCaught = round.FishCaught
+		if round.BulletsFired > 0 {
+			record.HitRate = float64(round.FishCaught) / float64(round.BulletsFired) * 100
+		}
+		record.Metadata["source"] = "guest_upgrade"
+		record.Metadata["guest_nickname"] = nickname
+
+		if err := m.repo.Create(ctx, record); err != nil {
+			return migrated, fmt.Errorf("failed to migrate guest round %d: %w", i+1, err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// ProvideGuestUpgradePolicy 從配置讀取遊客餘額轉入策略
+func ProvideGuestUpgradePolicy(c *conf.Config) account.GuestUpgradePolicy {
+	if c == nil || c.GuestUpgrade == nil {
+		return account.GuestUpgradePolicy{}
+	}
+	return account.GuestUpgradePolicy{
+		CarryOverRatio: c.GuestUpgrade.CarryOverRatio,
+		MaxCarryOver:   c.GuestUpgrade.MaxCarryOver,
+	}
+}
+
+// ProvideLoginThrottlePolicy 從配置讀取登入失敗限流策略，未配置的欄位使用默認值
+func ProvideLoginThrottlePolicy(c *conf.Config) account.LoginThrottlePolicy {
+	policy := account.DefaultLoginThrottlePolicy()
+	if c == nil || c.LoginSecurity == nil {
+		return policy
+	}
+	ls := c.LoginSecurity
+	if ls.UsernameFreeAttempts > 0 {
+		policy.Username.FreeAttempts = ls.UsernameFreeAttempts
+	}
+	if ls.UsernameLockoutThreshold > 0 {
+		policy.Username.LockoutThreshold = ls.UsernameLockoutThreshold
+	}
+	if ls.IPFreeAttempts > 0 {
+		policy.IP.FreeAttempts = ls.IPFreeAttempts
+	}
+	if ls.IPLockoutThreshold > 0 {
+		policy.IP.LockoutThreshold = ls.IPLockoutThreshold
+	}
+	if ls.Window > 0 {
+		policy.Window = time.Duration(ls.Window) * time.Second
+	}
+	if ls.BaseDelay > 0 {
+		policy.BaseDelay = time.Duration(ls.BaseDelay) * time.Second
+	}
+	if ls.MaxDelay > 0 {
+		policy.MaxDelay = time.Duration(ls.MaxDelay) * time.Second
+	}
+	if ls.LockoutDuration > 0 {
+		policy.LockoutDuration = time.Duration(ls.LockoutDuration) * time.Second
+	}
+	return policy
+}
@@ -18,6 +18,11 @@ type Config struct {
     RateLimit   *RateLimit `mapstructure:"rate_limit"`
     Security    *Security `mapstructure:"security"`
     Game        *Game     `mapstructure:"game"`
+    Cluster     *Cluster  `mapstructure:"cluster"`
+    AdminAuth   *AdminAuth `mapstructure:"admin_auth"`
+    GuestUpgrade *GuestUpgrade `mapstructure:"guest_upgrade"`
+    OAuth       *OAuth    `mapstructure:"oauth"`
+    LoginSecurity *LoginSecurity `mapstructure:"login_security"`
 }
 
 type Server struct {
@@ -87,7 +92,11 @@ type Redis struct {
 type JWT struct {
 	Secret string `mapstructure:"secret"`
 	Issuer string `mapstructure:"issuer"`
-	Expire int64  `mapstructure:"expire"`
+	Expire int64  `mapstructure:"expire"` // 不綁定會話的令牌有效期（秒）
+	// AccessExpire 登入會話的訪問令牌有效期（秒），過期後使用刷新令牌換取新令牌
+	AccessExpire int64 `mapstructure:"access_expire"`
+	// RefreshExpire 刷新令牌的閒置有效期（秒），每次刷新重新計算；超過時間未刷新的會話失效
+	RefreshExpire int64 `mapstructure:"refresh_expire"`
 }
 type Log struct {
 	Level    string `mapstructure:"level"`
@@ -114,6 +123,61 @@ type CORS struct {
 type RateLimit struct {
 	Enable             bool `mapstructure:"enable"`
 	RequestsPerMinute  int  `mapstructure:"requests_per_minute"`
+	WebSocket          *WSRateLimit `mapstructure:"websocket"` // WebSocket 消息限流
+}
+
+// WSRateLimit WebSocket 消息限流配置（每個客戶端、每種消息類型一個令牌桶）
+type WSRateLimit struct {
+	Enable   bool                   `mapstructure:"enable"`
+	Default  TokenBucket            `mapstructure:"default"`  // 未單獨配置的消息類型使用的令牌桶
+	Messages map[string]TokenBucket `mapstructure:"messages"` // key 為消息類型名稱（小寫），如 fire_bullet、join_room
+	// CannonFireRates 砲台類型對應的每秒最大開火次數，key 為砲台類型（字串）
+	// 切換砲台後 fire_bullet 令牌桶的補充速率會跟著調整
+	CannonFireRates map[string]float64 `mapstructure:"cannon_fire_rates"`
+	Penalty         WSPenalty          `mapstructure:"penalty"`
+}
+
+// TokenBucket 令牌桶配置
+type TokenBucket struct {
+	Rate  float64 `mapstructure:"rate"`  // 每秒補充的令牌數
+	Burst int     `mapstructure:"burst"` // 桶容量（允許的瞬時突發量）
+}
+
+// WSPenalty 違規懲罰配置（逐級：丟棄 -> 警告 -> 斷線 -> 臨時封禁）
+type WSPenalty struct {
+	Window          int `mapstructure:"window"`           // 違規計數窗口（秒）
+	WarnAfter       int `mapstructure:"warn_after"`       // 窗口內違規次數達到後開始回覆警告
+	DisconnectAfter int `mapstructure:"disconnect_after"` // 窗口內違規次數達到後斷開連接
+	BanAfter        int `mapstructure:"ban_after"`        // 封禁窗口內被斷線次數達到後臨時封禁
+	BanWindow       int `mapstructure:"ban_window"`       // 斷線次數計數窗口（秒）
+	BanDuration     int `mapstructure:"ban_duration"`     // 封禁時長（秒）
+}
+
+// DefaultWSRateLimit 返回 WebSocket 消息限流的默認配置
+func DefaultWSRateLimit() *WSRateLimit {
+	return &WSRateLimit{
+		Enable:  true,
+		Default: TokenBucket{Rate: 10, Burst: 20},
+		Messages: map[string]TokenBucket{
+			"fire_bullet":     {Rate: 5, Burst: 10},
+			"hit_fish":        {Rate: 20, Burst: 40},
+			"switch_cannon":   {Rate: 2, Burst: 5},
+			"join_room":       {Rate: 0.2, Burst: 3},
+			"leave_room":      {Rate: 0.2, Burst: 3},
+			"heartbeat":       {Rate: 1, Burst: 3},
+			"get_room_list":   {Rate: 1, Burst: 5},
+			"get_player_info": {Rate: 2, Burst: 5},
+		},
+		CannonFireRates: map[string]float64{},
+		Penalty: WSPenalty{
+			Window:          10,
+			WarnAfter:       5,
+			DisconnectAfter: 50,
+			BanAfter:        3,
+			BanWindow:       600,
+			BanDuration:     300,
+		},
+	}
 }
 
 // Security 安全配置
@@ -123,16 +187,146 @@ type Security struct {
     MaxRequestSize     string `mapstructure:"max_request_size"`
 }
 
+// AdminAuth 管理後台帳號配置（管理員帳號保存在 admin_users，與玩家帳號分開）
+type AdminAuth struct {
+	TokenExpire       int64    `mapstructure:"token_expire"`       // 管理員令牌有效期（秒）
+	BootstrapUsername string   `mapstructure:"bootstrap_username"` // admin_users 為空時創建的初始超級管理員
+	BootstrapPassword string   `mapstructure:"bootstrap_password"` // 初始超級管理員密碼，為空時不創建
+	StepUpWindow      int64    `mapstructure:"step_up_window"`     // 兩步驗證後可以執行敏感操作的時間（秒）
+	TrustedProxies    []string `mapstructure:"trusted_proxies"`    // 可信反向代理的 IP 或 CIDR，只採用它們提供的 X-Forwarded-For
+}
+
+// GuestUpgrade 遊客升級為正式帳號時的餘額轉入策略
+type GuestUpgrade struct {
+	CarryOverRatio float64 `mapstructure:"carry_over_ratio"` // 遊客餘額轉入新帳號錢包的比例，0 表示不轉入
+	MaxCarryOver   int64   `mapstructure:"max_carry_over"`   // 最多轉入（分），0 表示不限
+}
+
+// LoginSecurity 玩家密碼登入的失敗限流和臨時鎖定，為 0 的欄位使用默認值
+type LoginSecurity struct {
+	UsernameFreeAttempts     int   `mapstructure:"username_free_attempts"`     // 同一帳號名不需要等待的失敗次數
+	UsernameLockoutThreshold int   `mapstructure:"username_lockout_threshold"` // 同一帳號名達到後臨時鎖定
+	IPFreeAttempts           int   `mapstructure:"ip_free_attempts"`           // 同一 IP 不需要等待的失敗次數
+	IPLockoutThreshold       int   `mapstructure:"ip_lockout_threshold"`       // 同一 IP 達到後臨時鎖定
+	Window                   int64 `mapstructure:"window"`                     // 失敗計數統計窗口（秒）
+	BaseDelay                int64 `mapstructure:"base_delay"`                 // 第一次退避等待時間（秒），之後每次翻倍
+	MaxDelay                 int64 `mapstructure:"max_delay"`                  // 單次退避最長等待時間（秒）
+	LockoutDuration          int64 `mapstructure:"lockout_duration"`           // 臨時鎖定時長（秒）
+}
+
+// OAuth 第三方登入配置
+type OAuth struct {
+	StateTTL  int64                     `mapstructure:"state_ttl"` // 授權 state 有效期（秒）
+	Providers map[string]*OAuthProvider `mapstructure:"providers"` // 按平台名稱配置，client_id 為空的平台不啟用
+}
+
+// OAuthProvider 第三方平台配置，端點為空時使用該類型的默認端點
+type OAuthProvider struct {
+	Type         string   `mapstructure:"type"`          // google、facebook、qq、oidc，為空時與平台名稱相同
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`  // 第三方回調到前端的地址，需要與平台上登記的一致
+	AuthURL      string   `mapstructure:"auth_url"`
+	TokenURL     string   `mapstructure:"token_url"`
+	UserInfoURL  string   `mapstructure:"user_info_url"`
+	OpenIDURL    string   `mapstructure:"open_id_url"`   // 只有 qq 使用
+	Scopes       []string `mapstructure:"scopes"`
+}
+
 // Game 遊戲相關配置
 type Game struct {
     PrebuiltRooms []PrebuiltRoom `mapstructure:"prebuilt_rooms"`
+    WebSocket     *GameWebSocket `mapstructure:"websocket"` // WebSocket 傳輸配置
+    Matchmaking   *Matchmaking   `mapstructure:"matchmaking"` // 快速加入與房間擴縮容配置
+    PrivateRooms  *PrivateRooms  `mapstructure:"private_rooms"` // 玩家創建的私人房間配置
+    Seats         *Seats         `mapstructure:"seats"` // 座位保留、換座和 AFK 配置
+    RoomConfigs   *RoomConfigs   `mapstructure:"room_configs"` // room_configs 表的配置熱更新
+    RoomEvents    *RoomEvents    `mapstructure:"room_events"` // 定時房間活動排程
+}
+
+// Cluster 多節點部署配置（Game Server 在 Redis 中註冊，由大廳路由）
+type Cluster struct {
+	NodeID            string      `mapstructure:"node_id"`            // 節點 ID，為空時由主機名和端口生成
+	PublicURL         string      `mapstructure:"public_url"`         // 對客戶端公開的 WebSocket 地址，為空時使用 ws://localhost:{port}/ws
+	HeartbeatInterval int         `mapstructure:"heartbeat_interval"` // 心跳和房間上報間隔（秒）
+	NodeTTL           int         `mapstructure:"node_ttl"`           // 超過此時間（秒）未收到心跳的節點視為下線並被移除
+	DrainTimeout      int         `mapstructure:"drain_timeout"`      // 下線模式等待玩家離開的最長時間（秒），超時後強制結算並斷開
+	Checkpoint        *Checkpoint `mapstructure:"checkpoint"`
+}
+
+// Checkpoint 房間狀態檢查點配置（節點崩潰後由重啟的節點或備用節點恢復房間）
+type Checkpoint struct {
+	Enable        bool `mapstructure:"enable"`
+	Interval      int  `mapstructure:"interval"`       // 檢查點間隔（秒）
+	TTL           int  `mapstructure:"ttl"`            // 檢查點在 Redis 中的保留時間（秒），超過後不再恢復
+	ResumeTimeout int  `mapstructure:"resume_timeout"` // 恢復後等待玩家重新連接的時間（秒），超時後按離開房間結算
+	Takeover      bool `mapstructure:"takeover"`       // 備用節點：接管已下線節點的檢查點
+}
+
+// GameWebSocket 遊戲 WebSocket 傳輸配置
+type GameWebSocket struct {
+	Batching    WSBatching    `mapstructure:"batching"`
+	Compression WSCompression `mapstructure:"compression"`
+}
+
+// WSBatching 出站消息批量合併配置（客戶端需以 batch=1 協商）
+type WSBatching struct {
+	Enable      bool `mapstructure:"enable"`
+	WindowMs    int  `mapstructure:"window_ms"`    // tick 之外的消息最長合併時間；房間 tick 結束時立即送出本 tick 的消息
+	MaxMessages int  `mapstructure:"max_messages"` // 單批最大消息數，達到立即發送
+	MaxBytes    int  `mapstructure:"max_bytes"`    // 單批最大字節數，達到立即發送
+}
+
+// WSCompression permessage-deflate 壓縮配置
+type WSCompression struct {
+	Enable    bool `mapstructure:"enable"`
+	Threshold int  `mapstructure:"threshold"` // 幀大小達到此字節數才壓縮，小幀壓縮得不償失
+	Level     int  `mapstructure:"level"`     // flate 壓縮等級（1-9），默認 1 以節省 CPU
 }
 
 // PrebuiltRoom 預建房間配置
+// 房間數在 min_count 和 max_count 之間按需擴縮容；未設置 min_count 時使用 count
 type PrebuiltRoom struct {
     Type       string `mapstructure:"type"`
     MaxPlayers int32  `mapstructure:"max_players"`
     Count      int    `mapstructure:"count"`
+    MinCount   int    `mapstructure:"min_count"` // 最少保持的房間數
+    MaxCount   int    `mapstructure:"max_count"` // 最多可創建的房間數，默認為 min_count 的 4 倍
+}
+
+// Matchmaking 快速加入與房間擴縮容配置
+type Matchmaking struct {
+	TargetFill    float64 `mapstructure:"target_fill"`    // 偏好的房間填充率（0-1），所有房間都達到此值時預先擴容
+	StakeShots    int64   `mapstructure:"stake_shots"`    // 自動選擇房間類型時，餘額至少可按最低下注發射的子彈數
+	ScaleInterval int     `mapstructure:"scale_interval"` // 擴縮容檢查間隔（秒）
+	IdleTimeout   int     `mapstructure:"idle_timeout"`   // 空房間超過此時間（秒）且房間數多於 min_count 時關閉
+}
+
+// PrivateRooms 私人房間配置
+type PrivateRooms struct {
+	IdleTimeout int `mapstructure:"idle_timeout"`  // 沒有玩家超過此時間（秒）後關閉
+	MaxPerOwner int `mapstructure:"max_per_owner"` // 每個玩家同時擁有的私人房間數上限
+}
+
+// Seats 座位保留、換座和 AFK 配置
+type Seats struct {
+	ReconnectGrace int `mapstructure:"reconnect_grace"` // 斷線後保留座位的時間（秒），0 表示斷線立即離開房間
+	SwapTimeout    int `mapstructure:"swap_timeout"`    // 換座請求等待對方回應的時間（秒）
+	AFKTimeout     int `mapstructure:"afk_timeout"`     // 超過此時間（秒）沒有開火、切換砲台或換座的玩家轉為觀戰者，0 表示不檢查
+	SweepInterval  int `mapstructure:"sweep_interval"`  // 檢查座位保留和 AFK 的間隔（秒）
+}
+
+// RoomConfigs 房間配置熱更新（管理後台修改 room_configs 後通過 Redis pub/sub 通知遊戲節點）
+type RoomConfigs struct {
+	HotReload      bool `mapstructure:"hot_reload"`      // 是否訂閱配置變更，關閉時只在啟動時加載
+	ResyncInterval int  `mapstructure:"resync_interval"` // 定期從資料庫重新同步的間隔（秒），補上 pub/sub 丟失的消息
+}
+
+// RoomEvents 定時房間活動（排程保存在 room_event_schedules，由管理後台維護）
+type RoomEvents struct {
+	Enabled        bool `mapstructure:"enabled"`         // 是否舉行定時活動
+	ReloadInterval int  `mapstructure:"reload_interval"` // 從資料庫重新加載排程的間隔（秒）
+	LookaheadHours int  `mapstructure:"lookahead_hours"` // 大廳活動看板顯示未來多少小時內開始的活動
 }
 
 // NewConfig 創建並加載配置
@@ -209,12 +403,52 @@ func setDefaultValues(c *Config) {
 	if c.RateLimit == nil {
 		c.RateLimit = &RateLimit{}
 	}
+	setWSRateLimitDefaults(c.RateLimit)
     if c.Security == nil {
         c.Security = &Security{}
     }
     if c.Game == nil {
         c.Game = &Game{}
     }
+	setGameWebSocketDefaults(c.Game)
+	setMatchmakingDefaults(c.Game)
+	setPrivateRoomDefaults(c.Game)
+	setSeatDefaults(c.Game)
+	setRoomConfigDefaults(c.Game)
+	setRoomEventDefaults(c.Game)
+	if c.Cluster == nil {
+		c.Cluster = &Cluster{}
+	}
+	setClusterDefaults(c.Cluster)
+	if c.JWT != nil {
+		if c.JWT.AccessExpire <= 0 {
+			c.JWT.AccessExpire = 15 * 60
+		}
+		if c.JWT.RefreshExpire <= 0 {
+			c.JWT.RefreshExpire = 30 * 24 * 3600
+		}
+	}
+	if c.GuestUpgrade == nil {
+		c.GuestUpgrade = &GuestUpgrade{CarryOverRatio: 0.1, MaxCarryOver: 10000}
+	}
+	if c.OAuth == nil {
+		c.OAuth = &OAuth{}
+	}
+	if c.OAuth.StateTTL <= 0 {
+		c.OAuth.StateTTL = 600
+	}
+	if c.LoginSecurity == nil {
+		c.LoginSecurity = &LoginSecurity{}
+	}
+	if c.AdminAuth == nil {
+		c.AdminAuth = &AdminAuth{}
+	}
+	if c.AdminAuth.TokenExpire <= 0 {
+		c.AdminAuth.TokenExpire = 8 * 3600
+	}
+	if c.AdminAuth.StepUpWindow <= 0 {
+		c.AdminAuth.StepUpWindow = 5 * 60
+	}
 	
 	// 根據環境設置默認值
 	switch c.Environment {
@@ -227,6 +461,196 @@ func setDefaultValues(c *Config) {
 	}
 }
 
+// setWSRateLimitDefaults 補齊 WebSocket 限流配置中未填寫的欄位
+func setWSRateLimitDefaults(r *RateLimit) {
+	def := DefaultWSRateLimit()
+	if r.WebSocket == nil {
+		r.WebSocket = def
+		return
+	}
+	ws := r.WebSocket
+	if ws.Default.Rate <= 0 || ws.Default.Burst <= 0 {
+		ws.Default = def.Default
+	}
+	if ws.Messages == nil {
+		ws.Messages = map[string]TokenBucket{}
+	}
+	for name, bucket := range def.Messages {
+		if _, ok := ws.Messages[name]; !ok {
+			ws.Messages[name] = bucket
+		}
+	}
+	if ws.CannonFireRates == nil {
+		ws.CannonFireRates = map[string]float64{}
+	}
+	if ws.Penalty.Window <= 0 {
+		ws.Penalty.Window = def.Penalty.Window
+	}
+	if ws.Penalty.WarnAfter <= 0 {
+		ws.Penalty.WarnAfter = def.Penalty.WarnAfter
+	}
+	if ws.Penalty.DisconnectAfter <= 0 {
+		ws.Penalty.DisconnectAfter = def.Penalty.DisconnectAfter
+	}
+	if ws.Penalty.BanAfter <= 0 {
+		ws.Penalty.BanAfter = def.Penalty.BanAfter
+	}
+	if ws.Penalty.BanWindow <= 0 {
+		ws.Penalty.BanWindow = def.Penalty.BanWindow
+	}
+	if ws.Penalty.BanDuration <= 0 {
+		ws.Penalty.BanDuration = def.Penalty.BanDuration
+	}
+}
+
+// setMatchmakingDefaults 設置快速加入和房間數範圍默認值
+func setMatchmakingDefaults(g *Game) {
+	if g.Matchmaking == nil {
+		g.Matchmaking = &Matchmaking{}
+	}
+	m := g.Matchmaking
+	if m.TargetFill <= 0 || m.TargetFill > 1 {
+		m.TargetFill = 0.75
+	}
+	if m.StakeShots <= 0 {
+		m.StakeShots = 100
+	}
+	if m.ScaleInterval <= 0 {
+		m.ScaleInterval = 10
+	}
+	if m.IdleTimeout <= 0 {
+		m.IdleTimeout = 120
+	}
+
+	for i := range g.PrebuiltRooms {
+		pr := &g.PrebuiltRooms[i]
+		if pr.MinCount <= 0 {
+			pr.MinCount = pr.Count
+		}
+		if pr.MinCount <= 0 {
+			pr.MinCount = 1
+		}
+		if pr.MaxCount <= 0 {
+			pr.MaxCount = 4 * pr.MinCount
+		}
+		if pr.MaxCount < pr.MinCount {
+			pr.MaxCount = pr.MinCount
+		}
+	}
+}
+
+// setPrivateRoomDefaults 設置私人房間默認值
+func setPrivateRoomDefaults(g *Game) {
+	if g.PrivateRooms == nil {
+		g.PrivateRooms = &PrivateRooms{}
+	}
+	if g.PrivateRooms.IdleTimeout <= 0 {
+		g.PrivateRooms.IdleTimeout = 300
+	}
+	if g.PrivateRooms.MaxPerOwner <= 0 {
+		g.PrivateRooms.MaxPerOwner = 1
+	}
+}
+
+// setSeatDefaults 設置座位默認值，未配置 seats 時啟用斷線保留和 AFK 檢查
+func setSeatDefaults(g *Game) {
+	if g.Seats == nil {
+		g.Seats = &Seats{ReconnectGrace: 30, AFKTimeout: 300}
+	}
+	s := g.Seats
+	if s.ReconnectGrace < 0 {
+		s.ReconnectGrace = 0
+	}
+	if s.AFKTimeout < 0 {
+		s.AFKTimeout = 0
+	}
+	if s.SwapTimeout <= 0 {
+		s.SwapTimeout = 15
+	}
+	if s.SweepInterval <= 0 {
+		s.SweepInterval = 5
+	}
+}
+
+// setRoomConfigDefaults 設置房間配置熱更新默認值，未配置 room_configs 時啟用熱更新
+func setRoomConfigDefaults(g *Game) {
+	if g.RoomConfigs == nil {
+		g.RoomConfigs = &RoomConfigs{HotReload: true}
+	}
+	if g.RoomConfigs.ResyncInterval <= 0 {
+		g.RoomConfigs.ResyncInterval = 60
+	}
+}
+
+// setRoomEventDefaults 設置定時活動默認值，未配置 room_events 時舉行活動
+func setRoomEventDefaults(g *Game) {
+	if g.RoomEvents == nil {
+		g.RoomEvents = &RoomEvents{Enabled: true}
+	}
+	if g.RoomEvents.ReloadInterval <= 0 {
+		g.RoomEvents.ReloadInterval = 30
+	}
+	if g.RoomEvents.LookaheadHours <= 0 {
+		g.RoomEvents.LookaheadHours = 24
+	}
+}
+
+// setClusterDefaults 設置集群心跳和下線默認值
+func setClusterDefaults(c *Cluster) {
+	if c.HeartbeatInterval <= 0 {
+		c.HeartbeatInterval = 5
+	}
+	if c.NodeTTL <= 0 {
+		c.NodeTTL = 15
+	}
+	// 至少容忍兩次心跳丟失
+	if c.NodeTTL < 2*c.HeartbeatInterval {
+		c.NodeTTL = 2 * c.HeartbeatInterval
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = 60
+	}
+
+	if c.Checkpoint == nil {
+		c.Checkpoint = &Checkpoint{Enable: true}
+	}
+	if c.Checkpoint.Interval <= 0 {
+		c.Checkpoint.Interval = 5
+	}
+	if c.Checkpoint.TTL <= 0 {
+		c.Checkpoint.TTL = 600
+	}
+	if c.Checkpoint.ResumeTimeout <= 0 {
+		c.Checkpoint.ResumeTimeout = 120
+	}
+}
+
+// setGameWebSocketDefaults 設置 WebSocket 傳輸默認值
+func setGameWebSocketDefaults(g *Game) {
+	if g.WebSocket == nil {
+		g.WebSocket = &GameWebSocket{
+			Batching:    WSBatching{Enable: true},
+			Compression: WSCompression{Enable: true},
+		}
+	}
+	ws := g.WebSocket
+	if ws.Batching.WindowMs <= 0 {
+		ws.Batching.WindowMs = 100
+	}
+	if ws.Batching.MaxMessages <= 0 {
+		ws.Batching.MaxMessages = 64
+	}
+	if ws.Batching.MaxBytes <= 0 {
+		ws.Batching.MaxBytes = 64 * 1024
+	}
+	if ws.Compression.Threshold <= 0 {
+		ws.Compression.Threshold = 1024
+	}
+	if ws.Compression.Level < 1 || ws.Compression.Level > 9 {
+		ws.Compression.Level = 1
+	}
+}
+
 // setDevDefaults 設置開發環境默認值
 func setDevDefaults(c *Config) {
 	if c.Debug.EnablePprof == false && c.Environment == "dev" {
@@ -3,6 +3,8 @@ package data
 
 import (
 	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/biz/lobby"
 	"github.com/b7777777v/fish_server/internal/conf"
 	"github.com/b7777777v/fish_server/internal/data/postgres"
@@ -57,6 +59,26 @@ func NewAccountRepo(dbManager *postgres.DBManager) account.AccountRepo {
 	return postgres.NewAccountRepo(dbManager)
 }
 
+// NewAdminRepo creates a new AdminRepo
+func NewAdminRepo(dbManager *postgres.DBManager) admin.AdminRepo {
+	return postgres.NewAdminRepo(dbManager)
+}
+
+// NewAuditRepo creates a new AuditRepo
+func NewAuditRepo(dbManager *postgres.DBManager) admin.AuditRepo {
+	return postgres.NewAuditRepo(dbManager)
+}
+
+// NewSanctionRepo creates a new SanctionRepo
+func NewSanctionRepo(dbManager *postgres.DBManager) account.SanctionRepo {
+	return postgres.NewSanctionRepo(dbManager)
+}
+
+// NewLoginHistoryRepo creates a new LoginHistoryRepo
+func NewLoginHistoryRepo(dbManager *postgres.DBManager) account.LoginHistoryRepo {
+	return postgres.NewLoginHistoryRepo(dbManager)
+}
+
 // NewLobbyRepo creates a new LobbyRepo
 func NewLobbyRepo(dbManager *postgres.DBManager) lobby.LobbyRepo {
 	return postgres.NewLobbyRepo(dbManager)
@@ -66,3 +88,58 @@ func NewLobbyRepo(dbManager *postgres.DBManager) lobby.LobbyRepo {
 func NewRoomCache(redisClient *redis.Client) lobby.RoomCache {
 	return redis.NewRoomCache(redisClient.Redis)
 }
+
+// NewNodeRegistry creates a new NodeRegistry
+func NewNodeRegistry(redisClient *redis.Client) lobby.NodeRegistry {
+	return redis.NewNodeRegistry(redisClient.Redis)
+}
+
+// NewRoomCheckpointRepo creates a new RoomCheckpointRepo
+func NewRoomCheckpointRepo(redisClient *redis.Client) game.RoomCheckpointRepo {
+	return redis.NewRoomCheckpointStore(redisClient.Redis)
+}
+
+// NewRoomHistoryRepo creates a new RoomHistoryRepo
+func NewRoomHistoryRepo(redisClient *redis.Client) game.RoomHistoryRepo {
+	return redis.NewRoomHistoryStore(redisClient.Redis)
+}
+
+// NewRoomConfigBus creates a new RoomConfigBus
+func NewRoomConfigBus(redisClient *redis.Client) game.RoomConfigBus {
+	return redis.NewRoomConfigBus(redisClient.Redis)
+}
+
+// NewSessionStore creates a new Redis-backed login session store
+func NewSessionStore(redisClient *redis.Client) *redis.SessionStore {
+	return redis.NewSessionStore(redisClient.Redis)
+}
+
+// NewSessionEvents creates a new SessionEvents
+func NewSessionEvents(redisClient *redis.Client) account.SessionEvents {
+	return redis.NewSessionEvents(redisClient.Redis)
+}
+
+// NewGuestProgressStore creates a new GuestProgressStore
+func NewGuestProgressStore(redisClient *redis.Client) account.GuestProgressStore {
+	return redis.NewGuestProgressStore(redisClient.Redis)
+}
+
+// NewOAuthStateStore creates a new OAuthStateStore
+func NewOAuthStateStore(redisClient *redis.Client) account.OAuthStateStore {
+	return redis.NewOAuthStateStore(redisClient.Redis)
+}
+
+// NewLoginAttemptStore creates a new LoginAttemptStore
+func NewLoginAttemptStore(redisClient *redis.Client) account.LoginAttemptStore {
+	return redis.NewLoginAttemptStore(redisClient.Redis)
+}
+
+// NewRoomEventRepo creates a new RoomEventRepo
+func NewRoomEventRepo(dbManager *postgres.DBManager) game.RoomEventRepo {
+	return postgres.NewRoomEventRepo(dbManager)
+}
+
+// NewRoomEventBoard creates a new RoomEventBoard
+func NewRoomEventBoard(redisClient *redis.Client) lobby.RoomEventBoard {
+	return redis.NewRoomEventBoard(redisClient.Redis)
+}
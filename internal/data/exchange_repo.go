@@ -0,0 +1,211 @@
+// internal/data/exchange_repo.go
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/jackc/pgx/v5"
+)
+
+type exchangeRepo struct {
+	data    *Data
+	wallets *walletRepo
+	logger  logger.Logger
+}
+
+// NewExchangeRepo 創建幣種兌換儲存庫
+func NewExchangeRepo(data *Data, logger logger.Logger) wallet.ExchangeRepo {
+	return &exchangeRepo{
+		data:    data,
+		wallets: &walletRepo{data: data, logger: logger.With("module", "data/wallet_repo")},
+		logger:  logger.With("module", "data/exchange_repo"),
+	}
+}
+
+const exchangeRateColumns = `from_currency, to_currency, rate, enabled, updated_by, updated_at`
+
+func scanExchangeRate(row pgx.Row) (*wallet.ExchangeRate, error) {
+	var rate wallet.ExchangeRate
+	if err := row.Scan(&rate.FromCurrency, &rate.ToCurrency, &rate.Rate, &rate.Enabled, &rate.UpdatedBy, &rate.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// ListExchangeRates 列出所有兌換比例
+func (r *exchangeRepo) ListExchangeRates(ctx context.Context) ([]*wallet.ExchangeRate, error) {
+	query := `SELECT ` + exchangeRateColumns + ` FROM wallet_exchange_rates ORDER BY from_currency, to_currency`
+	rows, err := r.data.DBManager().Read().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*wallet.ExchangeRate
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// GetExchangeRate 獲取一個方向的兌換比例，沒有配置時返回 nil
+func (r *exchangeRepo) GetExchangeRate(ctx context.Context, from, to string) (*wallet.ExchangeRate, error) {
+	query := `SELECT ` + exchangeRateColumns + ` FROM wallet_exchange_rates WHERE from_currency = $1 AND to_currency = $2`
+	// 兌換前讀取比例使用 Write DB，避免剛修改的比例因複製延遲未生效
+	rate, err := scanExchangeRate(r.data.DBManager().Write().QueryRow(ctx, query, from, to))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// SaveExchangeRate 新增或覆蓋一個方向的兌換比例，寫回更新時間
+func (r *exchangeRepo) SaveExchangeRate(ctx context.Context, rate *wallet.ExchangeRate) error {
+	query := `
+		INSERT INTO wallet_exchange_rates (from_currency, to_currency, rate, enabled, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (from_currency, to_currency) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			enabled = EXCLUDED.enabled,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	err := r.data.DBManager().Write().QueryRow(ctx, query,
+		rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.Enabled, rate.UpdatedBy,
+	).Scan(&rate.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+	return nil
+}
+
+// DeleteExchangeRate 刪除一個方向的兌換比例，返回是否存在
+func (r *exchangeRepo) DeleteExchangeRate(ctx context.Context, from, to string) (bool, error) {
+	tag, err := r.data.DBManager().Write().Exec(ctx,
+		`DELETE FROM wallet_exchange_rates WHERE from_currency = $1 AND to_currency = $2`, from, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Exchange 在一個事務中完成兌換：按 ID 順序鎖定兩個錢包，來源錢包記 exchange_out 流水，
+// 目標錢包記 exchange_in 流水，兩筆流水參考 ID 相同
+func (r *exchangeRepo) Exchange(ctx context.Context, ex *wallet.Exchange) error {
+	if ex.Debited <= 0 || ex.Credited <= 0 {
+		return fmt.Errorf("exchange %w", wallet.ErrInvalidAmount)
+	}
+
+	tx, err := r.data.DBManager().Write().Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// 按 ID 順序加鎖，避免與反方向兌換死鎖
+	rows, err := tx.Query(ctx, `
+		SELECT id, user_id, balance, currency, status
+		FROM wallets
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`, []int64{int64(ex.FromWalletID), int64(ex.ToWalletID)})
+	if err != nil {
+		r.logger.Errorf("failed to lock wallets: %v", err)
+		return err
+	}
+	locked := make(map[uint]*WalletPO, 2)
+	for rows.Next() {
+		var po WalletPO
+		if err := rows.Scan(&po.ID, &po.UserID, &po.Balance, &po.Currency, &po.Status); err != nil {
+			rows.Close()
+			return err
+		}
+		locked[po.ID] = &po
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	source, target := locked[ex.FromWalletID], locked[ex.ToWalletID]
+	if source == nil || target == nil || source.UserID != ex.UserID || target.UserID != ex.UserID ||
+		source.Currency != ex.FromCurrency || target.Currency != ex.ToCurrency {
+		return wallet.ErrWalletNotFound
+	}
+	if source.Status != 1 || target.Status != 1 {
+		return wallet.ErrWalletFrozen
+	}
+	if source.Balance < ex.Debited {
+		return wallet.ErrInsufficientBalance
+	}
+
+	metadata, err := json.Marshal(map[string]interface{}{
+		"from_currency": ex.FromCurrency,
+		"to_currency":   ex.ToCurrency,
+		"rate":          ex.Rate,
+		"debited":       ex.Debited,
+		"credited":      ex.Credited,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	legs := []struct {
+		wallet *WalletPO
+		amount float64
+		txType string
+		desc   string
+	}{
+		{source, -ex.Debited, wallet.TxTypeExchangeOut, fmt.Sprintf("兌換為 %s", ex.ToCurrency)},
+		{target, ex.Credited, wallet.TxTypeExchangeIn, fmt.Sprintf("由 %s 兌換", ex.FromCurrency)},
+	}
+	for _, leg := range legs {
+		before := leg.wallet.Balance
+		leg.wallet.Balance += leg.amount
+		if _, err := tx.Exec(ctx, `UPDATE wallets SET balance = $1, updated_at = $2 WHERE id = $3`,
+			leg.wallet.Balance, now, leg.wallet.ID); err != nil {
+			r.logger.Errorf("failed to update wallet balance: %v", err)
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO wallet_transactions (
+				wallet_id, amount, balance_before, balance_after,
+				type, status, reference_id, description, metadata,
+				created_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, 1, $6, $7, $8, $9, $9)
+		`, leg.wallet.ID, leg.amount, before, leg.wallet.Balance,
+			leg.txType, ex.ReferenceID, leg.desc, metadata, now); err != nil {
+			r.logger.Errorf("failed to create transaction record: %v", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Errorf("failed to commit transaction: %v", err)
+		return err
+	}
+
+	for _, w := range []*WalletPO{source, target} {
+		r.wallets.invalidateWalletCache(ctx, w.ID, w.UserID, w.Currency)
+	}
+	ex.FromBalance = source.Balance
+	ex.ToBalance = target.Balance
+	return nil
+}
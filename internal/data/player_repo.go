@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/biz/player"
+	"github.com/b7777777v/fish_server/internal/biz/wallet"
 	"github.com/b7777777v/fish_server/internal/pkg/logger"
 	"github.com/go-redis/redis/v8"
 	"github.com/jackc/pgx/v5"
@@ -28,101 +30,47 @@ func NewPlayerRepo(data *Data, logger logger.Logger) player.PlayerRepo {
 	}
 }
 
-// gamePlayerRepo 實現了 biz/game.PlayerRepo 接口
+// gamePlayerRepo 實現了 biz/game.PlayerRepo 接口：遊戲內玩家由 player.Profile 構建，
+// 與大廳和管理後台看到的是同一個帳號和同一個主錢包
 type gamePlayerRepo struct {
-	data   *Data
-	logger logger.Logger
+	players player.PlayerRepo
+	data    *Data
+	logger  logger.Logger
 }
 
 // NewGamePlayerRepo 創建一個用於遊戲業務的 PlayerRepo
-func NewGamePlayerRepo(data *Data, logger logger.Logger) game.PlayerRepo {
+func NewGamePlayerRepo(players player.PlayerRepo, data *Data, logger logger.Logger) game.PlayerRepo {
 	return &gamePlayerRepo{
-		data:   data,
-		logger: logger.With("component", "game_player_repo"),
+		players: players,
+		data:    data,
+		logger:  logger.With("component", "game_player_repo"),
 	}
 }
 
-// GetPlayer 獲取玩家信息
+// GetPlayer 獲取玩家信息，playerID 即帳號 ID
 func (r *gamePlayerRepo) GetPlayer(ctx context.Context, playerID int64) (*game.Player, error) {
-	// 1. 從 Redis 讀取快取
-	cacheKey := fmt.Sprintf("player:%d", playerID)
-	playerJSON, err := r.data.redis.Get(ctx, cacheKey)
-	if err == nil {
-		var player game.Player
-		if json.Unmarshal([]byte(playerJSON), &player) == nil {
-			r.logger.Debugf("Cache hit for player: %d", playerID)
-			return &player, nil
-		}
-	}
-
-	// 2. 快取未命中，從資料庫讀取
-	r.logger.Debugf("Cache miss for player: %d. Fetching from DB.", playerID)
-	query := `
-		SELECT u.id, u.nickname, u.status, w.balance
-		FROM users u
-		LEFT JOIN wallets w ON u.id = w.user_id AND w.currency = 'CNY'
-		WHERE u.id = $1
-	`
-	var po struct {
-		ID       int64
-		Nickname string
-		Status   int
-		Balance  *float64 // Use pointer to handle NULL from LEFT JOIN
-	}
-	// 讀操作使用 Read DB
-	err = r.data.DBManager().Read().QueryRow(ctx, query, playerID).Scan(&po.ID, &po.Nickname, &po.Status, &po.Balance)
+	profile, err := r.players.GetProfile(ctx, playerID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("player with id %d not found", playerID)
-		}
 		r.logger.Errorf("failed to get player from db: %v", err)
 		return nil, err
 	}
-
-	balance := int64(0)
-	if po.Balance != nil {
-		balance = int64(*po.Balance * 100) // Assuming balance is stored as decimal, convert to cents
-	}
-
-	player := &game.Player{
-		ID:       po.ID,
-		Nickname: po.Nickname,
-		Balance:  balance,
-		Status:   game.PlayerStatusIdle, // Default status
-	}
-	if po.Status == 0 {
-		player.Status = game.PlayerStatusOffline
+	if profile == nil || !profile.Active {
+		return nil, fmt.Errorf("%w: id %d", player.ErrPlayerNotFound, playerID)
 	}
-
-	// 3. 將數據寫入快取
-	playerBytes, err := json.Marshal(player)
-	if err == nil {
-		r.data.redis.Set(ctx, cacheKey, playerBytes, 10*time.Minute)
-	}
-
-	return player, nil
+	return gamePlayerFromProfile(profile), nil
 }
 
-// UpdatePlayerBalance 更新玩家餘額
-func (r *gamePlayerRepo) UpdatePlayerBalance(ctx context.Context, playerID int64, balance int64) error {
-	r.logger.Debugf("Updating player %d balance to %d", playerID, balance)
-	query := `UPDATE wallets SET balance = $1 WHERE user_id = $2 AND currency = 'CNY'`
-	// Convert balance from cents to decimal for DB
-	balanceDecimal := float64(balance) / 100.0
-	// 寫操作使用 Write DB
-	_, err := r.data.DBManager().Write().Exec(ctx, query, balanceDecimal, playerID)
-	if err != nil {
-		r.logger.Errorf("failed to update player balance: %v", err)
-		return err
-	}
-
-	// 更新成功後，使快取失效
-	cacheKey := fmt.Sprintf("player:%d", playerID)
-	if err := r.data.redis.Del(ctx, cacheKey); err != nil {
-		r.logger.Warnf("Failed to delete player cache on balance update: %v", err)
+// gamePlayerFromProfile 由玩家資料構建遊戲內玩家，餘額和錢包 ID 來自主錢包
+func gamePlayerFromProfile(profile *player.Profile) *game.Player {
+	return &game.Player{
+		ID:       profile.UserID,
+		UserID:   profile.UserID,
+		Nickname: profile.Nickname,
+		Balance:  profile.Balance,
+		WalletID: profile.WalletID,
+		SeatID:   -1,
+		Status:   game.PlayerStatusIdle,
 	}
-
-	return nil
 }
 
 // UpdatePlayerStatus 更新玩家狀態
@@ -146,12 +94,6 @@ func (r *gamePlayerRepo) UpdatePlayerStatus(ctx context.Context, playerID int64,
 		return err
 	}
 
-	// 更新成功後，使快取失效
-	cacheKey := fmt.Sprintf("player:%d", playerID)
-	if err := r.data.redis.Del(ctx, cacheKey); err != nil {
-		r.logger.Warnf("Failed to delete player cache on status update: %v", err)
-	}
-
 	return nil
 }
 
@@ -214,27 +156,40 @@ func (r *playerRepo) FindByUsername(ctx context.Context, username string) (*play
 	return p, nil // 找不到用戶時 p 為 nil
 }
 
-// Create 創建一個新玩家
-func (r *playerRepo) Create(ctx context.Context, p *player.Player) (*player.Player, error) {
-	// 在真實應用中，可能需要更複雜的預設值邏輯
-	// 這裡我們只插入必要的欄位，並讓資料庫使用預設值
-	query := `INSERT INTO users (username, nickname, password_hash, status) VALUES ($1, $2, $3, $4) RETURNING id`
-
-	// 為新用戶設置一個空的密碼雜湊和預設狀態
-	// 密碼為空，因此無法透過一般登入流程登入
-	emptyPasswordHash := ""
-	defaultStatus := 1 // 假設 1 為活躍狀態
-
-	var newID uint
-	// 寫操作使用 Write DB
-	err := r.data.DBManager().Write().QueryRow(ctx, query, p.Username, p.Username, emptyPasswordHash, defaultStatus).Scan(&newID)
+// GetProfile 按帳號 ID 讀取玩家資料和主錢包
+func (r *playerRepo) GetProfile(ctx context.Context, userID int64) (*player.Profile, error) {
+	query := `
+		SELECT u.id, COALESCE(u.username, ''), u.nickname, COALESCE(u.avatar_url, ''), u.is_guest,
+			u.level, u.exp, u.is_active, COALESCE(w.id, 0), COALESCE(w.balance, 0)
+		FROM users u
+		LEFT JOIN wallets w ON w.user_id = u.id AND w.currency = $2
+		WHERE u.id = $1
+	`
+	profile := &player.Profile{Currency: wallet.DefaultCurrency}
+	var walletID int64
+	var balance float64
+	// 使用 Write DB，剛寫入的錢包流水立即反映在餘額中
+	err := r.data.DBManager().Write().QueryRow(ctx, query, userID, wallet.DefaultCurrency).Scan(
+		&profile.UserID,
+		&profile.Username,
+		&profile.Nickname,
+		&profile.AvatarURL,
+		&profile.IsGuest,
+		&profile.Level,
+		&profile.EXP,
+		&profile.Active,
+		&walletID,
+		&balance,
+	)
 	if err != nil {
-		r.logger.Errorf("failed to create player: %v", err)
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.logger.Errorf("failed to get player profile: %v", err)
 		return nil, err
 	}
 
-	p.ID = newID
-	r.logger.Infof("Created new player %s with ID %d", p.Username, p.ID)
-
-	return p, nil
+	profile.WalletID = uint(walletID)
+	profile.Balance = int64(math.Round(balance * 100)) // 錢包以元保存，轉換為分
+	return profile, nil
 }
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/b7777777v/fish_server/internal/biz/player"
+	"github.com/b7777777v/fish_server/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProfileRepo 只實現 GetProfile 的玩家存儲
+type stubProfileRepo struct {
+	player.PlayerRepo
+	profiles map[int64]*player.Profile
+}
+
+func (r *stubProfileRepo) GetProfile(ctx context.Context, userID int64) (*player.Profile, error) {
+	return r.profiles[userID], nil
+}
+
+// 遊戲內玩家、大廳狀態和大廳金幣都來自同一個玩家資料和主錢包
+func TestPlayerProfileAdapters(t *testing.T) {
+	ctx := context.Background()
+	log := logger.New(io.Discard, "info", "console")
+	profiles := &stubProfileRepo{profiles: map[int64]*player.Profile{
+		7: {UserID: 7, Username: "alice", Nickname: "Alice", Level: 3, EXP: 120, Active: true, WalletID: 12, Currency: "CNY", Balance: 150050},
+		8: {UserID: 8, Username: "bob", Nickname: "Bob", Active: false, WalletID: 13, Balance: 100},
+	}}
+
+	gamePlayers := NewGamePlayerRepo(profiles, nil, log)
+	p, err := gamePlayers.GetPlayer(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), p.ID)
+	assert.Equal(t, int64(7), p.UserID)
+	assert.Equal(t, "Alice", p.Nickname)
+	assert.Equal(t, uint(12), p.WalletID)
+	assert.Equal(t, int64(150050), p.Balance)
+	assert.Equal(t, -1, p.SeatID)
+
+	_, err = gamePlayers.GetPlayer(ctx, 8)
+	assert.ErrorIs(t, err, player.ErrPlayerNotFound)
+	_, err = gamePlayers.GetPlayer(ctx, 9)
+	assert.ErrorIs(t, err, player.ErrPlayerNotFound)
+
+	lobbyPlayers := NewLobbyPlayerRepo(profiles, log)
+	status, err := lobbyPlayers.GetPlayerInfo(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", status.Nickname)
+	assert.Equal(t, 3, status.Level)
+	assert.Equal(t, int64(150050), status.Coins)
+	status, err = lobbyPlayers.GetPlayerInfo(ctx, 8)
+	require.NoError(t, err)
+	assert.Nil(t, status)
+
+	lobbyWallets := NewLobbyWalletRepo(profiles, log)
+	coins, err := lobbyWallets.GetBalance(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, p.Balance, coins)
+	coins, err = lobbyWallets.GetBalance(ctx, 9)
+	require.NoError(t, err)
+	assert.Zero(t, coins)
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/b7777777v/fish_server/internal/biz/account"
 	"github.com/jackc/pgx/v5"
@@ -23,7 +24,7 @@ func NewAccountRepo(dbManager *DBManager) account.AccountRepo {
 	}
 }
 
-// CreateUser 建立新使用者
+// CreateUser 建立新使用者，第三方帳號在同一事務中綁定第三方身份
 func (r *accountRepo) CreateUser(ctx context.Context, user *account.User, passwordHash string) (*account.User, error) {
 	query := `
 		INSERT INTO users (username, password_hash, nickname, avatar_url, is_guest, third_party_provider, third_party_id)
@@ -34,7 +35,13 @@ func (r *accountRepo) CreateUser(ctx context.Context, user *account.User, passwo
 	var id int64
 
 	// 寫操作使用 Write DB
-	err := r.dbManager.Write().QueryRow(
+	tx, err := r.dbManager.Write().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(
 		ctx,
 		query,
 		sql.NullString{String: user.Username, Valid: user.Username != ""},
@@ -50,7 +57,22 @@ func (r *accountRepo) CreateUser(ctx context.Context, user *account.User, passwo
 		return nil, err
 	}
 
+	if user.ThirdPartyProvider != "" && user.ThirdPartyID != "" {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO user_oauth_identities (user_id, provider, third_party_id)
+			VALUES ($1, $2, $3)
+		`, id, user.ThirdPartyProvider, user.ThirdPartyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to link oauth identity: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	user.ID = id
+	user.HasPassword = passwordHash != ""
 	return user, nil
 }
 
@@ -91,6 +113,7 @@ func (r *accountRepo) GetUserByUsername(ctx context.Context, username string) (*
 	user.AvatarURL = avatarURL.String
 	user.ThirdPartyProvider = thirdPartyProvider.String
 	user.ThirdPartyID = thirdPartyID.String
+	user.HasPassword = passwordHash.Valid
 
 	return &user, passwordHash.String, nil
 }
@@ -99,7 +122,7 @@ func (r *accountRepo) GetUserByUsername(ctx context.Context, username string) (*
 func (r *accountRepo) GetUserByID(ctx context.Context, userID int64) (*account.User, error) {
 	query := `
 		SELECT id, username, nickname, avatar_url, is_guest,
-		       third_party_provider, third_party_id
+		       third_party_provider, third_party_id, password_hash IS NOT NULL
 		FROM users
 		WHERE id = $1 AND is_active = true
 	`
@@ -116,6 +139,7 @@ func (r *accountRepo) GetUserByID(ctx context.Context, userID int64) (*account.U
 		&user.IsGuest,
 		&thirdPartyProvider,
 		&thirdPartyID,
+		&user.HasPassword,
 	)
 
 	if err != nil {
@@ -134,13 +158,14 @@ func (r *accountRepo) GetUserByID(ctx context.Context, userID int64) (*account.U
 	return &user, nil
 }
 
-// GetUserByThirdParty 根據第三方平台 ID 獲取使用者
+// GetUserByThirdParty 根據綁定的第三方身份獲取使用者
 func (r *accountRepo) GetUserByThirdParty(ctx context.Context, provider, thirdPartyID string) (*account.User, error) {
 	query := `
-		SELECT id, username, nickname, avatar_url, is_guest,
-		       third_party_provider, third_party_id
-		FROM users
-		WHERE third_party_provider = $1 AND third_party_id = $2 AND is_active = true
+		SELECT u.id, u.username, u.nickname, u.avatar_url, u.is_guest,
+		       u.third_party_provider, u.third_party_id
+		FROM user_oauth_identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.provider = $1 AND i.third_party_id = $2 AND u.is_active = true
 	`
 
 	var user account.User
@@ -192,3 +217,85 @@ func (r *accountRepo) UpdateUser(ctx context.Context, user *account.User) error
 
 	return err
 }
+
+// DisableUser 停用帳號
+func (r *accountRepo) DisableUser(ctx context.Context, userID int64) (bool, error) {
+	tag, err := r.dbManager.Write().Exec(ctx,
+		`UPDATE users SET is_active = false, updated_at = NOW() WHERE id = $1 AND is_active = true`,
+		userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListOAuthIdentities 獲取帳號綁定的第三方身份
+func (r *accountRepo) ListOAuthIdentities(ctx context.Context, userID int64) ([]*account.OAuthIdentity, error) {
+	query := `
+		SELECT user_id, provider, third_party_id, created_at
+		FROM user_oauth_identities
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.dbManager.Read().Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := make([]*account.OAuthIdentity, 0)
+	for rows.Next() {
+		var identity account.OAuthIdentity
+		if err := rows.Scan(&identity.UserID, &identity.Provider, &identity.ThirdPartyID, &identity.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, &identity)
+	}
+	return identities, rows.Err()
+}
+
+// CreateOAuthIdentity 綁定第三方身份，違反唯一約束時按衝突的身份返回對應錯誤
+func (r *accountRepo) CreateOAuthIdentity(ctx context.Context, identity *account.OAuthIdentity) error {
+	query := `
+		INSERT INTO user_oauth_identities (user_id, provider, third_party_id, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := r.dbManager.Write().Exec(ctx, query, identity.UserID, identity.Provider, identity.ThirdPartyID, identity.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var ownerID int64
+	err = r.dbManager.Write().QueryRow(ctx,
+		`SELECT user_id FROM user_oauth_identities WHERE provider = $1 AND third_party_id = $2`,
+		identity.Provider, identity.ThirdPartyID,
+	).Scan(&ownerID)
+	switch {
+	case err == pgx.ErrNoRows:
+		return account.ErrOAuthAlreadyLinked
+	case err != nil:
+		return err
+	case ownerID != identity.UserID:
+		return account.ErrOAuthIdentityTaken
+	}
+	return nil
+}
+
+// DeleteOAuthIdentity 解綁第三方身份
+func (r *accountRepo) DeleteOAuthIdentity(ctx context.Context, userID int64, provider string) (bool, error) {
+	tag, err := r.dbManager.Write().Exec(ctx,
+		`DELETE FROM user_oauth_identities WHERE user_id = $1 AND provider = $2`,
+		userID, provider,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,469 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/jackc/pgx/v5"
+)
+
+// AdminRepo 實現 admin.AdminRepo，管理員帳號和角色保存在 admin_users、admin_roles（見 000014 遷移）
+// 權限修改需要立即生效，所有查詢都使用寫庫避免讀到複製延遲的舊數據
+type AdminRepo struct {
+	dbManager *DBManager
+}
+
+// NewAdminRepo 建立新的 AdminRepo 實例
+func NewAdminRepo(dbManager *DBManager) *AdminRepo {
+	return &AdminRepo{
+		dbManager: dbManager,
+	}
+}
+
+// adminUserColumns admin_users 查詢的列，順序與 scanAdminUser 一致
+const adminUserColumns = `
+		id, username, role, is_active, otp_enabled, last_login_at, created_at, updated_at`
+
+// scanAdminUser 掃描一行 admin_users，extra 為 adminUserColumns 之後的額外列
+func scanAdminUser(row pgx.Row, extra ...interface{}) (*admin.AdminUser, error) {
+	var a admin.AdminUser
+	dest := append([]interface{}{
+		&a.ID,
+		&a.Username,
+		&a.Role,
+		&a.Active,
+		&a.OTPEnabled,
+		&a.LastLoginAt,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetAdmin 根據 ID 獲取管理員
+func (r *AdminRepo) GetAdmin(ctx context.Context, id int64) (*admin.AdminUser, error) {
+	query := `SELECT` + adminUserColumns + `
+		FROM admin_users
+		WHERE id = $1
+	`
+
+	a, err := scanAdminUser(r.dbManager.Write().QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", admin.ErrAdminNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin %d: %w", id, err)
+	}
+	return a, nil
+}
+
+// GetAdminByUsername 根據帳號名獲取管理員和密碼雜湊
+func (r *AdminRepo) GetAdminByUsername(ctx context.Context, username string) (*admin.AdminUser, string, error) {
+	query := `SELECT` + adminUserColumns + `, password_hash
+		FROM admin_users
+		WHERE username = $1
+	`
+
+	var passwordHash string
+	a, err := scanAdminUser(r.dbManager.Write().QueryRow(ctx, query, username), &passwordHash)
+	if err == pgx.ErrNoRows {
+		return nil, "", fmt.Errorf("%w: %s", admin.ErrAdminNotFound, username)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get admin %s: %w", username, err)
+	}
+	return a, passwordHash, nil
+}
+
+// ListAdmins 獲取所有管理員
+func (r *AdminRepo) ListAdmins(ctx context.Context) ([]*admin.AdminUser, error) {
+	query := `SELECT` + adminUserColumns + `
+		FROM admin_users
+		ORDER BY id
+	`
+
+	rows, err := r.dbManager.Write().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admins: %w", err)
+	}
+	defer rows.Close()
+
+	var admins []*admin.AdminUser
+	for rows.Next() {
+		a, err := scanAdminUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		admins = append(admins, a)
+	}
+	return admins, rows.Err()
+}
+
+// CreateAdmin 創建管理員並寫回 ID 和時間戳
+func (r *AdminRepo) CreateAdmin(ctx context.Context, a *admin.AdminUser, passwordHash string) error {
+	query := `
+		INSERT INTO admin_users (username, password_hash, role, is_active)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (username) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+	err := r.dbManager.Write().QueryRow(ctx, query, a.Username, passwordHash, a.Role, a.Active).
+		Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("%w: %s", admin.ErrAdminExists, a.Username)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create admin %s: %w", a.Username, err)
+	}
+	return nil
+}
+
+// UpdateAdmin 更新管理員的角色和啟用狀態
+func (r *AdminRepo) UpdateAdmin(ctx context.Context, a *admin.AdminUser) error {
+	query := `
+		UPDATE admin_users SET role = $1, is_active = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at
+	`
+	err := r.dbManager.Write().QueryRow(ctx, query, a.Role, a.Active, a.ID).Scan(&a.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("%w: %d", admin.ErrAdminNotFound, a.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update admin %d: %w", a.ID, err)
+	}
+	return nil
+}
+
+// UpdateAdminPassword 更新管理員密碼雜湊
+func (r *AdminRepo) UpdateAdminPassword(ctx context.Context, id int64, passwordHash string) error {
+	tag, err := r.dbManager.Write().Exec(ctx,
+		`UPDATE admin_users SET password_hash = $1, updated_at = NOW() WHERE id = $2`, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update password of admin %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %d", admin.ErrAdminNotFound, id)
+	}
+	return nil
+}
+
+// RecordAdminLogin 記錄最後登入時間
+func (r *AdminRepo) RecordAdminLogin(ctx context.Context, id int64) error {
+	if _, err := r.dbManager.Write().Exec(ctx, `UPDATE admin_users SET last_login_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to record login of admin %d: %w", id, err)
+	}
+	return nil
+}
+
+// CountActiveAdmins 擁有角色的啟用管理員數量
+func (r *AdminRepo) CountActiveAdmins(ctx context.Context, role string) (int, error) {
+	var count int
+	err := r.dbManager.Write().QueryRow(ctx,
+		`SELECT COUNT(*) FROM admin_users WHERE role = $1 AND is_active = true`, role).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active admins with role %s: %w", role, err)
+	}
+	return count, nil
+}
+
+// CountAdmins 所有管理員數量
+func (r *AdminRepo) CountAdmins(ctx context.Context) (int, error) {
+	var count int
+	if err := r.dbManager.Write().QueryRow(ctx, `SELECT COUNT(*) FROM admin_users`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count admins: %w", err)
+	}
+	return count, nil
+}
+
+// GetAdminOTP 讀取管理員的兩步驗證密鑰和狀態
+func (r *AdminRepo) GetAdminOTP(ctx context.Context, id int64) (*admin.OTPState, error) {
+	var state admin.OTPState
+	err := r.dbManager.Write().QueryRow(ctx,
+		`SELECT otp_secret, otp_enabled, otp_last_step, otp_failed_attempts, otp_locked_until
+		FROM admin_users WHERE id = $1`, id).
+		Scan(&state.Secret, &state.Enabled, &state.LastStep, &state.FailedAttempts, &state.LockedUntil)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", admin.ErrAdminNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get otp of admin %d: %w", id, err)
+	}
+	return &state, nil
+}
+
+// SetAdminOTP 保存兩步驗證密鑰和啟用狀態，清除密鑰時同時刪除恢復碼並解除失敗鎖定
+func (r *AdminRepo) SetAdminOTP(ctx context.Context, id int64, secret string, enabled bool) error {
+	tx, err := r.dbManager.Write().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE admin_users SET otp_secret = $1, otp_enabled = $2, updated_at = NOW()
+		WHERE id = $3
+	`, secret, enabled, id)
+	if err != nil {
+		return fmt.Errorf("failed to update otp of admin %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %d", admin.ErrAdminNotFound, id)
+	}
+	if secret == "" {
+		if _, err := tx.Exec(ctx, `DELETE FROM admin_recovery_codes WHERE admin_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete recovery codes of admin %d: %w", id, err)
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE admin_users SET otp_failed_attempts = 0, otp_locked_until = NULL WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to clear otp lockout of admin %d: %w", id, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// UseOTPStep 原子地推進已使用的驗證碼步長，並發的相同驗證碼只有一個成功
+func (r *AdminRepo) UseOTPStep(ctx context.Context, id int64, step int64) (bool, error) {
+	tag, err := r.dbManager.Write().Exec(ctx,
+		`UPDATE admin_users SET otp_last_step = $1 WHERE id = $2 AND otp_last_step < $1`, step, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to record otp step of admin %d: %w", id, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RecordOTPFailure 原子地累加驗證失敗次數，達到上限時鎖定並清零計數，返回鎖定截止時間
+func (r *AdminRepo) RecordOTPFailure(ctx context.Context, id int64, limit int, lockout time.Duration) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.dbManager.Write().QueryRow(ctx, `
+		UPDATE admin_users SET
+			otp_locked_until = CASE WHEN otp_failed_attempts + 1 >= $2
+				THEN NOW() + $3 * INTERVAL '1 second' ELSE otp_locked_until END,
+			otp_failed_attempts = CASE WHEN otp_failed_attempts + 1 >= $2
+				THEN 0 ELSE otp_failed_attempts + 1 END
+		WHERE id = $1
+		RETURNING otp_locked_until
+	`, id, limit, lockout.Seconds()).Scan(&lockedUntil)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", admin.ErrAdminNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record otp failure of admin %d: %w", id, err)
+	}
+	return lockedUntil, nil
+}
+
+// ResetOTPFailures 清零驗證失敗次數
+func (r *AdminRepo) ResetOTPFailures(ctx context.Context, id int64) error {
+	if _, err := r.dbManager.Write().Exec(ctx,
+		`UPDATE admin_users SET otp_failed_attempts = 0 WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to reset otp failures of admin %d: %w", id, err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes 刪除管理員所有的恢復碼並寫入新的一組
+func (r *AdminRepo) ReplaceRecoveryCodes(ctx context.Context, id int64, hashes []string) error {
+	tx, err := r.dbManager.Write().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM admin_recovery_codes WHERE admin_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete recovery codes of admin %d: %w", id, err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO admin_recovery_codes (admin_id, code_hash)
+		SELECT $1, UNNEST($2::TEXT[])
+	`, id, hashes); err != nil {
+		return fmt.Errorf("failed to insert recovery codes of admin %d: %w", id, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// UseRecoveryCode 把未使用的恢復碼標記為已使用
+func (r *AdminRepo) UseRecoveryCode(ctx context.Context, id int64, hash string) (bool, error) {
+	tag, err := r.dbManager.Write().Exec(ctx, `
+		UPDATE admin_recovery_codes SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM admin_recovery_codes
+			WHERE admin_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+	`, id, hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code of admin %d: %w", id, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CountRecoveryCodes 管理員剩餘未使用的恢復碼數量
+func (r *AdminRepo) CountRecoveryCodes(ctx context.Context, id int64) (int, error) {
+	var count int
+	err := r.dbManager.Write().QueryRow(ctx,
+		`SELECT COUNT(*) FROM admin_recovery_codes WHERE admin_id = $1 AND used_at IS NULL`, id).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes of admin %d: %w", id, err)
+	}
+	return count, nil
+}
+
+// adminRoleColumns admin_roles 查詢的列，順序與 scanAdminRole 一致
+const adminRoleColumns = `
+		name, description, permissions, allowed_ips, built_in, created_at, updated_at`
+
+// scanAdminRole 掃描一行 admin_roles
+func scanAdminRole(row pgx.Row) (*admin.Role, error) {
+	var (
+		role        admin.Role
+		permissions []string
+	)
+	err := row.Scan(
+		&role.Name,
+		&role.Description,
+		&permissions,
+		&role.AllowedIPs,
+		&role.BuiltIn,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = make([]admin.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		role.Permissions = append(role.Permissions, admin.Permission(p))
+	}
+	return &role, nil
+}
+
+// permissionStrings 轉換為寫入 TEXT[] 的參數
+func permissionStrings(permissions []admin.Permission) []string {
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		result = append(result, string(p))
+	}
+	return result
+}
+
+// GetRole 根據名稱獲取角色
+func (r *AdminRepo) GetRole(ctx context.Context, name string) (*admin.Role, error) {
+	query := `SELECT` + adminRoleColumns + `
+		FROM admin_roles
+		WHERE name = $1
+	`
+
+	role, err := scanAdminRole(r.dbManager.Write().QueryRow(ctx, query, name))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", admin.ErrRoleNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role %s: %w", name, err)
+	}
+	return role, nil
+}
+
+// ListRoles 獲取所有角色，內置角色在前
+func (r *AdminRepo) ListRoles(ctx context.Context) ([]*admin.Role, error) {
+	query := `SELECT` + adminRoleColumns + `
+		FROM admin_roles
+		ORDER BY built_in DESC, created_at, name
+	`
+
+	rows, err := r.dbManager.Write().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*admin.Role
+	for rows.Next() {
+		role, err := scanAdminRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// CreateRole 創建角色並寫回時間戳
+func (r *AdminRepo) CreateRole(ctx context.Context, role *admin.Role) error {
+	query := `
+		INSERT INTO admin_roles (name, description, permissions, allowed_ips, built_in)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING created_at, updated_at
+	`
+	err := r.dbManager.Write().QueryRow(ctx, query, role.Name, role.Description, permissionStrings(role.Permissions), allowedIPs(role.AllowedIPs), role.BuiltIn).
+		Scan(&role.CreatedAt, &role.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("%w: %s", admin.ErrRoleExists, role.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create role %s: %w", role.Name, err)
+	}
+	return nil
+}
+
+// UpdateRole 更新角色的描述和權限
+func (r *AdminRepo) UpdateRole(ctx context.Context, role *admin.Role) error {
+	query := `
+		UPDATE admin_roles SET description = $1, permissions = $2, updated_at = NOW()
+		WHERE name = $3
+		RETURNING created_at, updated_at
+	`
+	err := r.dbManager.Write().QueryRow(ctx, query, role.Description, permissionStrings(role.Permissions), role.Name).
+		Scan(&role.CreatedAt, &role.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("%w: %s", admin.ErrRoleNotFound, role.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update role %s: %w", role.Name, err)
+	}
+	return nil
+}
+
+// UpdateRoleAllowedIPs 更新角色的 IP 白名單
+func (r *AdminRepo) UpdateRoleAllowedIPs(ctx context.Context, role *admin.Role) error {
+	query := `
+		UPDATE admin_roles SET allowed_ips = $1, updated_at = NOW()
+		WHERE name = $2
+		RETURNING updated_at
+	`
+	err := r.dbManager.Write().QueryRow(ctx, query, allowedIPs(role.AllowedIPs), role.Name).Scan(&role.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("%w: %s", admin.ErrRoleNotFound, role.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update allowed ips of role %s: %w", role.Name, err)
+	}
+	return nil
+}
+
+// allowedIPs 轉換為寫入 TEXT[] NOT NULL 的參數，nil 寫入空數組
+func allowedIPs(entries []string) []string {
+	if entries == nil {
+		return []string{}
+	}
+	return entries
+}
+
+// DeleteRole 刪除角色
+func (r *AdminRepo) DeleteRole(ctx context.Context, name string) error {
+	tag, err := r.dbManager.Write().Exec(ctx, `DELETE FROM admin_roles WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete role %s: %w", name, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", admin.ErrRoleNotFound, name)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/b7777777v/fish_server/internal/biz/admin"
+	"github.com/jackc/pgx/v5"
+)
+
+// auditChainLockKey 追加審計記錄時使用的 advisory lock，保證序號和雜湊鏈按順序生成
+const auditChainLockKey int64 = 0x61756469745f6c67 // "audit_lg"
+
+// AuditRepo 實現 admin.AuditRepo，審計記錄保存在 admin_audit_log（見 000015 遷移），表上的觸發器禁止修改和刪除
+type AuditRepo struct {
+	dbManager *DBManager
+}
+
+// NewAuditRepo 建立新的 AuditRepo 實例
+func NewAuditRepo(dbManager *DBManager) *AuditRepo {
+	return &AuditRepo{
+		dbManager: dbManager,
+	}
+}
+
+// auditColumns admin_audit_log 查詢的列，順序與 scanAuditEntry 一致
+const auditColumns = `
+		seq, actor_id, actor_name, role, method, route, path, target, status,
+		before_data, after_data, diff, ip, request_id, created_at, prev_hash, hash`
+
+// scanAuditEntry 掃描一行 admin_audit_log
+func scanAuditEntry(row pgx.Row) (*admin.AuditEntry, error) {
+	var e admin.AuditEntry
+	var before, after, diff []byte
+	err := row.Scan(
+		&e.Seq,
+		&e.ActorID,
+		&e.ActorName,
+		&e.Role,
+		&e.Method,
+		&e.Route,
+		&e.Path,
+		&e.Target,
+		&e.Status,
+		&before,
+		&after,
+		&diff,
+		&e.IP,
+		&e.RequestID,
+		&e.CreatedAt,
+		&e.PrevHash,
+		&e.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	e.Before, e.After, e.Diff = before, after, diff
+	return &e, nil
+}
+
+// nullableJSON 空的 JSON 保存為 NULL
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+// AppendAudit 在事務中持有 advisory lock，讀取鏈尾後把記錄接在其後寫入
+func (r *AuditRepo) AppendAudit(ctx context.Context, entry *admin.AuditEntry) error {
+	tx, err := r.dbManager.Write().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockKey); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var prev *admin.AuditEntry
+	var tail admin.AuditEntry
+	err = tx.QueryRow(ctx, `SELECT seq, hash FROM admin_audit_log ORDER BY seq DESC LIMIT 1`).Scan(&tail.Seq, &tail.Hash)
+	switch {
+	case err == nil:
+		prev = &tail
+	case err != pgx.ErrNoRows:
+		return fmt.Errorf("failed to read audit chain tail: %w", err)
+	}
+
+	if err := entry.Chain(prev); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO admin_audit_log (` + auditColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+	_, err = tx.Exec(ctx, query,
+		entry.Seq, entry.ActorID, entry.ActorName, entry.Role, entry.Method, entry.Route, entry.Path,
+		entry.Target, entry.Status, nullableJSON(entry.Before), nullableJSON(entry.After), nullableJSON(entry.Diff),
+		entry.IP, entry.RequestID, entry.CreatedAt, entry.PrevHash, entry.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry %d: %w", entry.Seq, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListAudit 按條件查詢審計記錄，序號倒序
+func (r *AuditRepo) ListAudit(ctx context.Context, filter admin.AuditFilter) ([]*admin.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.ActorID != 0 {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.Method != "" {
+		add("method = $%d", filter.Method)
+	}
+	if filter.Route != "" {
+		add("route = $%d", filter.Route)
+	}
+	if filter.Target != "" {
+		add("target = $%d", filter.Target)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+	if filter.BeforeSeq != 0 {
+		add("seq < $%d", filter.BeforeSeq)
+	}
+
+	query := `SELECT` + auditColumns + `
+		FROM admin_audit_log`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf("\n\t\tORDER BY seq DESC\n\t\tLIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.dbManager.Read().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*admin.AuditEntry
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ScanAudit 讀取序號大於 afterSeq 的記錄，序號正序；校驗需要看到最新數據，使用寫庫
+func (r *AuditRepo) ScanAudit(ctx context.Context, afterSeq int64, limit int) ([]*admin.AuditEntry, error) {
+	query := `SELECT` + auditColumns + `
+		FROM admin_audit_log
+		WHERE seq > $1
+		ORDER BY seq ASC
+		LIMIT $2
+	`
+
+	rows, err := r.dbManager.Write().Query(ctx, query, afterSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan audit entries after %d: %w", afterSeq, err)
+	}
+	defer rows.Close()
+
+	var entries []*admin.AuditEntry
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/jackc/pgx/v5"
+)
+
+// LoginHistoryRepo 實現 account.LoginHistoryRepo，登入記錄和已知設備保存在 user_login_history、user_devices（見 000020 遷移）
+type LoginHistoryRepo struct {
+	dbManager *DBManager
+}
+
+// NewLoginHistoryRepo 建立新的 LoginHistoryRepo 實例
+func NewLoginHistoryRepo(dbManager *DBManager) *LoginHistoryRepo {
+	return &LoginHistoryRepo{
+		dbManager: dbManager,
+	}
+}
+
+// AppendLogin 追加登入記錄並寫回 ID 和時間
+func (r *LoginHistoryRepo) AppendLogin(ctx context.Context, record *account.LoginRecord) error {
+	query := `
+		INSERT INTO user_login_history (user_id, method, success, failure_reason, ip, user_agent, device_id, fingerprint, new_device)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+	err := r.dbManager.Write().QueryRow(ctx, query,
+		record.UserID,
+		record.Method,
+		record.Success,
+		record.FailureReason,
+		record.IP,
+		record.UserAgent,
+		record.DeviceID,
+		record.Fingerprint,
+		record.NewDevice,
+	).Scan(&record.ID, &record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to append login record of user %d: %w", record.UserID, err)
+	}
+	return nil
+}
+
+// ListLogins 帳號最近的登入記錄，最新的在前
+func (r *LoginHistoryRepo) ListLogins(ctx context.Context, userID int64, limit int) ([]*account.LoginRecord, error) {
+	query := `
+		SELECT id, user_id, method, success, failure_reason, ip, user_agent, device_id, fingerprint, new_device, created_at
+		FROM user_login_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+	rows, err := r.dbManager.Read().Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login history of user %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	records := []*account.LoginRecord{}
+	for rows.Next() {
+		var rec account.LoginRecord
+		if err := rows.Scan(
+			&rec.ID,
+			&rec.UserID,
+			&rec.Method,
+			&rec.Success,
+			&rec.FailureReason,
+			&rec.IP,
+			&rec.UserAgent,
+			&rec.DeviceID,
+			&rec.Fingerprint,
+			&rec.NewDevice,
+			&rec.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		records = append(records, &rec)
+	}
+	return records, rows.Err()
+}
+
+// TouchDevice 新增或更新設備的最近登入信息，返回設備是否首次出現
+func (r *LoginHistoryRepo) TouchDevice(ctx context.Context, device *account.Device) (bool, error) {
+	query := `
+		INSERT INTO user_devices (user_id, fingerprint, device_id, user_agent, last_ip)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, fingerprint) DO UPDATE
+		SET device_id = EXCLUDED.device_id, user_agent = EXCLUDED.user_agent,
+			last_ip = EXCLUDED.last_ip, last_seen_at = NOW()
+		RETURNING first_seen_at, last_seen_at, (xmax = 0) AS inserted
+	`
+	var inserted bool
+	err := r.dbManager.Write().QueryRow(ctx, query,
+		device.UserID, device.Fingerprint, device.DeviceID, device.UserAgent, device.LastIP,
+	).Scan(&device.FirstSeenAt, &device.LastSeenAt, &inserted)
+	if err != nil {
+		return false, fmt.Errorf("failed to record device of user %d: %w", device.UserID, err)
+	}
+	return inserted, nil
+}
+
+// CountDevices 帳號已知的設備數量
+func (r *LoginHistoryRepo) CountDevices(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.dbManager.Write().QueryRow(ctx, `SELECT COUNT(*) FROM user_devices WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count devices of user %d: %w", userID, err)
+	}
+	return count, nil
+}
+
+// ListDevices 帳號已知的設備，最近使用的在前
+func (r *LoginHistoryRepo) ListDevices(ctx context.Context, userID int64) ([]*account.Device, error) {
+	query := `
+		SELECT user_id, fingerprint, device_id, user_agent, last_ip, first_seen_at, last_seen_at
+		FROM user_devices
+		WHERE user_id = $1
+		ORDER BY last_seen_at DESC
+	`
+	rows, err := r.dbManager.Read().Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices of user %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	devices := []*account.Device{}
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+// scanDevice 掃描一行 user_devices
+func scanDevice(row pgx.Row) (*account.Device, error) {
+	var d account.Device
+	if err := row.Scan(&d.UserID, &d.Fingerprint, &d.DeviceID, &d.UserAgent, &d.LastIP, &d.FirstSeenAt, &d.LastSeenAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -2,11 +2,15 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/jackc/pgx/v5"
 )
 
 // RoomConfigRepo 实现房间配置的数据访问
+// room_configs 每种房间类型一行当前配置，room_config_versions 保存所有版本（见 000012 迁移）
 type RoomConfigRepo struct {
 	dbManager *DBManager
 }
@@ -24,6 +28,7 @@ type RoomConfigPO struct {
 	RoomType             string
 	RoomName             string
 	MaxPlayers           int
+	MaxSpectators        int
 	MinBet               int64
 	MaxBet               int64
 	EntryFee             int64
@@ -34,27 +39,29 @@ type RoomConfigPO struct {
 	RoomWidth            float64
 	RoomHeight           float64
 	TargetRTP            float64
+	Currency             string
 	IsActive             bool
-	Description          string
+	Description          *string
+	Version              int64
+	UpdatedBy            *string
 }
 
-// GetRoomConfig 根据房间类型获取配置
-func (r *RoomConfigRepo) GetRoomConfig(ctx context.Context, roomType string) (*game.RoomConfig, error) {
-	query := `
-		SELECT id, room_type, room_name, max_players, min_bet, max_bet, entry_fee,
-		       bullet_cost_multiplier, fish_spawn_rate, min_fish_count, max_fish_count,
-		       room_width, room_height, target_rtp, is_active, description
-		FROM room_configs
-		WHERE room_type = $1 AND is_active = true
-	`
+// roomConfigColumns room_configs 查询的列，顺序与 scanRoomConfig 一致
+const roomConfigColumns = `
+		id, room_type, room_name, max_players, max_spectators, min_bet, max_bet, entry_fee,
+		bullet_cost_multiplier, fish_spawn_rate, min_fish_count, max_fish_count,
+		room_width, room_height, target_rtp, currency, is_active, description, version, updated_by, updated_at`
 
+// scanRoomConfig 扫描一行 room_configs 为配置版本
+func scanRoomConfig(row pgx.Row) (*game.RoomConfigVersion, error) {
 	var po RoomConfigPO
-	// 讀操作使用 Read DB
-	err := r.dbManager.Read().QueryRow(ctx, query, roomType).Scan(
+	v := &game.RoomConfigVersion{}
+	err := row.Scan(
 		&po.ID,
 		&po.RoomType,
 		&po.RoomName,
 		&po.MaxPlayers,
+		&po.MaxSpectators,
 		&po.MinBet,
 		&po.MaxBet,
 		&po.EntryFee,
@@ -65,17 +72,31 @@ func (r *RoomConfigRepo) GetRoomConfig(ctx context.Context, roomType string) (*g
 		&po.RoomWidth,
 		&po.RoomHeight,
 		&po.TargetRTP,
+		&po.Currency,
 		&po.IsActive,
 		&po.Description,
+		&po.Version,
+		&po.UpdatedBy,
+		&v.CreatedAt,
 	)
-
 	if err != nil {
 		return nil, err
 	}
 
+	v.RoomType = game.RoomType(po.RoomType)
+	v.Version = po.Version
+	v.Name = po.RoomName
+	v.Active = po.IsActive
+	if po.Description != nil {
+		v.Description = *po.Description
+	}
+	if po.UpdatedBy != nil {
+		v.UpdatedBy = *po.UpdatedBy
+	}
 	// 转换为业务实体
-	config := &game.RoomConfig{
+	v.Config = game.RoomConfig{
 		MaxPlayers:           int32(po.MaxPlayers),
+		MaxSpectators:        int32(po.MaxSpectators),
 		MinBet:               po.MinBet,
 		MaxBet:               po.MaxBet,
 		BulletCostMultiplier: po.BulletCostMultiplier,
@@ -85,67 +106,201 @@ func (r *RoomConfigRepo) GetRoomConfig(ctx context.Context, roomType string) (*g
 		RoomWidth:            po.RoomWidth,
 		RoomHeight:           po.RoomHeight,
 		TargetRTP:            po.TargetRTP,
+		Currency:             po.Currency,
+		Version:              po.Version,
 	}
+	return v, nil
+}
+
+// GetRoomConfig 根据房间类型获取配置
+func (r *RoomConfigRepo) GetRoomConfig(ctx context.Context, roomType string) (*game.RoomConfig, error) {
+	query := `SELECT` + roomConfigColumns + `
+		FROM room_configs
+		WHERE room_type = $1 AND is_active = true
+	`
 
-	return config, nil
+	// 讀操作使用 Read DB
+	v, err := scanRoomConfig(r.dbManager.Read().QueryRow(ctx, query, roomType))
+	if err != nil {
+		return nil, err
+	}
+	return &v.Config, nil
 }
 
 // GetAllRoomConfigs 获取所有活跃的房间配置
 func (r *RoomConfigRepo) GetAllRoomConfigs(ctx context.Context) (map[string]*game.RoomConfig, error) {
-	query := `
-		SELECT id, room_type, room_name, max_players, min_bet, max_bet, entry_fee,
-		       bullet_cost_multiplier, fish_spawn_rate, min_fish_count, max_fish_count,
-		       room_width, room_height, target_rtp, is_active, description
+	versions, err := r.ListRoomConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]*game.RoomConfig)
+	for _, v := range versions {
+		if v.Active {
+			configs[string(v.RoomType)] = &v.Config
+		}
+	}
+	return configs, nil
+}
+
+// CurrentRoomConfig 获取房间类型的当前配置版本（包括已删除的），没有配置时返回 nil
+// 配置修改后马上会被读取，使用写库避免读到复制延迟的旧版本
+func (r *RoomConfigRepo) CurrentRoomConfig(ctx context.Context, roomType game.RoomType) (*game.RoomConfigVersion, error) {
+	query := `SELECT` + roomConfigColumns + `
+		FROM room_configs
+		WHERE room_type = $1
+	`
+
+	v, err := scanRoomConfig(r.dbManager.Write().QueryRow(ctx, query, string(roomType)))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room config %s: %w", roomType, err)
+	}
+	return v, nil
+}
+
+// ListRoomConfigs 获取所有房间类型的当前配置版本（包括已删除的）
+func (r *RoomConfigRepo) ListRoomConfigs(ctx context.Context) ([]*game.RoomConfigVersion, error) {
+	query := `SELECT` + roomConfigColumns + `
 		FROM room_configs
-		WHERE is_active = true
 		ORDER BY room_type
 	`
 
 	// 讀操作使用 Read DB
 	rows, err := r.dbManager.Read().Query(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list room configs: %w", err)
 	}
 	defer rows.Close()
 
-	configs := make(map[string]*game.RoomConfig)
+	var versions []*game.RoomConfigVersion
 	for rows.Next() {
-		var po RoomConfigPO
-		err := rows.Scan(
-			&po.ID,
-			&po.RoomType,
-			&po.RoomName,
-			&po.MaxPlayers,
-			&po.MinBet,
-			&po.MaxBet,
-			&po.EntryFee,
-			&po.BulletCostMultiplier,
-			&po.FishSpawnRate,
-			&po.MinFishCount,
-			&po.MaxFishCount,
-			&po.RoomWidth,
-			&po.RoomHeight,
-			&po.TargetRTP,
-			&po.IsActive,
-			&po.Description,
-		)
+		v, err := scanRoomConfig(rows)
 		if err != nil {
 			return nil, err
 		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
 
-		configs[po.RoomType] = &game.RoomConfig{
-			MaxPlayers:           int32(po.MaxPlayers),
-			MinBet:               po.MinBet,
-			MaxBet:               po.MaxBet,
-			BulletCostMultiplier: po.BulletCostMultiplier,
-			FishSpawnRate:        po.FishSpawnRate,
-			MinFishCount:         int32(po.MinFishCount),
-			MaxFishCount:         int32(po.MaxFishCount),
-			RoomWidth:            po.RoomWidth,
-			RoomHeight:           po.RoomHeight,
-			TargetRTP:            po.TargetRTP,
-		}
+// SaveRoomConfig 在事务中锁定当前配置行，检查版本后写入新版本并记录历史
+func (r *RoomConfigRepo) SaveRoomConfig(ctx context.Context, v *game.RoomConfigVersion, expectedVersion int64) error {
+	tx, err := r.dbManager.Write().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current int64
+	err = tx.QueryRow(ctx, `SELECT version FROM room_configs WHERE room_type = $1 FOR UPDATE`, string(v.RoomType)).Scan(&current)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to lock room config %s: %w", v.RoomType, err)
+	}
+	if current != expectedVersion {
+		return fmt.Errorf("%w: %s is at version %d, not %d", game.ErrRoomConfigConflict, v.RoomType, current, expectedVersion)
+	}
+
+	version := expectedVersion + 1
+	config := v.Config
+	config.Version = version
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal room config %s: %w", v.RoomType, err)
+	}
+
+	upsert := `
+		INSERT INTO room_configs (
+			room_type, room_name, max_players, max_spectators, min_bet, max_bet,
+			bullet_cost_multiplier, fish_spawn_rate, min_fish_count, max_fish_count,
+			room_width, room_height, target_rtp, currency, is_active, description, version, updated_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (room_type) DO UPDATE SET
+			room_name = EXCLUDED.room_name,
+			max_players = EXCLUDED.max_players,
+			max_spectators = EXCLUDED.max_spectators,
+			min_bet = EXCLUDED.min_bet,
+			max_bet = EXCLUDED.max_bet,
+			bullet_cost_multiplier = EXCLUDED.bullet_cost_multiplier,
+			fish_spawn_rate = EXCLUDED.fish_spawn_rate,
+			min_fish_count = EXCLUDED.min_fish_count,
+			max_fish_count = EXCLUDED.max_fish_count,
+			room_width = EXCLUDED.room_width,
+			room_height = EXCLUDED.room_height,
+			target_rtp = EXCLUDED.target_rtp,
+			currency = EXCLUDED.currency,
+			is_active = EXCLUDED.is_active,
+			description = EXCLUDED.description,
+			version = EXCLUDED.version,
+			updated_by = EXCLUDED.updated_by
+		RETURNING updated_at
+	`
+	err = tx.QueryRow(ctx, upsert,
+		string(v.RoomType), v.Name, config.MaxPlayers, config.MaxSpectators, config.MinBet, config.MaxBet,
+		config.BulletCostMultiplier, config.FishSpawnRate, config.MinFishCount, config.MaxFishCount,
+		config.RoomWidth, config.RoomHeight, config.TargetRTP, config.PlayCurrency(), v.Active, v.Description, version, v.UpdatedBy,
+	).Scan(&v.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save room config %s: %w", v.RoomType, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO room_config_versions (room_type, version, room_name, description, is_active, config, updated_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, string(v.RoomType), version, v.Name, v.Description, v.Active, data, v.UpdatedBy, v.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record room config %s version %d: %w", v.RoomType, version, err)
 	}
 
-	return configs, rows.Err()
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit room config %s: %w", v.RoomType, err)
+	}
+	v.Version = version
+	v.Config.Version = version
+	return nil
+}
+
+// RoomConfigHistory 获取房间类型最近的配置版本，按版本从新到旧排列
+func (r *RoomConfigRepo) RoomConfigHistory(ctx context.Context, roomType game.RoomType, limit int) ([]*game.RoomConfigVersion, error) {
+	query := `
+		SELECT room_type, version, room_name, description, is_active, config, updated_by, created_at
+		FROM room_config_versions
+		WHERE room_type = $1
+		ORDER BY version DESC
+		LIMIT $2
+	`
+
+	// 讀操作使用 Read DB
+	rows, err := r.dbManager.Read().Query(ctx, query, string(roomType), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history of room config %s: %w", roomType, err)
+	}
+	defer rows.Close()
+
+	var versions []*game.RoomConfigVersion
+	for rows.Next() {
+		var (
+			v                      game.RoomConfigVersion
+			rt                     string
+			description, updatedBy *string
+			data                   []byte
+		)
+		if err := rows.Scan(&rt, &v.Version, &v.Name, &description, &v.Active, &data, &updatedBy, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &v.Config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal room config %s version %d: %w", rt, v.Version, err)
+		}
+		v.RoomType = game.RoomType(rt)
+		if description != nil {
+			v.Description = *description
+		}
+		if updatedBy != nil {
+			v.UpdatedBy = *updatedBy
+		}
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
 }
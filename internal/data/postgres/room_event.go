@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/jackc/pgx/v5"
+)
+
+// RoomEventRepo 实现 game.RoomEventRepo，活动排程保存在 room_event_schedules（见 000013 迁移）
+type RoomEventRepo struct {
+	dbManager *DBManager
+}
+
+// NewRoomEventRepo 创建新的 RoomEventRepo 实例
+func NewRoomEventRepo(dbManager *DBManager) *RoomEventRepo {
+	return &RoomEventRepo{
+		dbManager: dbManager,
+	}
+}
+
+// roomEventColumns room_event_schedules 查询的列，顺序与 scanRoomEventSchedule 一致
+const roomEventColumns = `
+		id, name, event_type, room_types, start_at, duration_minutes, timezone, recurrence,
+		reward_multiplier, tide_fish_type_id, tide_wave_size, tide_wave_seconds,
+		announcement, enabled, updated_by, created_at, updated_at`
+
+// scanRoomEventSchedule 扫描一行 room_event_schedules
+func scanRoomEventSchedule(row pgx.Row) (*game.RoomEventSchedule, error) {
+	var (
+		s                       game.RoomEventSchedule
+		eventType               string
+		roomTypes               []string
+		recurrence              []byte
+		announcement, updatedBy *string
+	)
+	err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&eventType,
+		&roomTypes,
+		&s.StartAt,
+		&s.DurationMinutes,
+		&s.Timezone,
+		&recurrence,
+		&s.RewardMultiplier,
+		&s.TideFishTypeID,
+		&s.TideWaveSize,
+		&s.TideWaveSeconds,
+		&announcement,
+		&s.Enabled,
+		&updatedBy,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(recurrence, &s.Recurrence); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recurrence of room event schedule %d: %w", s.ID, err)
+	}
+	s.Type = game.RoomEventType(eventType)
+	for _, rt := range roomTypes {
+		s.RoomTypes = append(s.RoomTypes, game.RoomType(rt))
+	}
+	if announcement != nil {
+		s.Announcement = *announcement
+	}
+	if updatedBy != nil {
+		s.UpdatedBy = *updatedBy
+	}
+	return &s, nil
+}
+
+// roomEventParams 写入 room_event_schedules 的参数，顺序与 INSERT/UPDATE 语句一致
+func roomEventParams(s *game.RoomEventSchedule) ([]interface{}, error) {
+	recurrence, err := json.Marshal(s.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal recurrence: %w", err)
+	}
+	roomTypes := make([]string, 0, len(s.RoomTypes))
+	for _, rt := range s.RoomTypes {
+		roomTypes = append(roomTypes, string(rt))
+	}
+	return []interface{}{
+		s.Name, string(s.Type), roomTypes, s.StartAt, s.DurationMinutes, s.Timezone, recurrence,
+		s.RewardMultiplier, s.TideFishTypeID, s.TideWaveSize, s.TideWaveSeconds,
+		s.Announcement, s.Enabled, s.UpdatedBy,
+	}, nil
+}
+
+// ListRoomEventSchedules 获取所有活动排程
+// 排程修改后游戏节点需要尽快看到，使用写库避免读到复制延迟的旧数据
+func (r *RoomEventRepo) ListRoomEventSchedules(ctx context.Context) ([]*game.RoomEventSchedule, error) {
+	query := `SELECT` + roomEventColumns + `
+		FROM room_event_schedules
+		ORDER BY id
+	`
+
+	rows, err := r.dbManager.Write().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list room event schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*game.RoomEventSchedule
+	for rows.Next() {
+		s, err := scanRoomEventSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// GetRoomEventSchedule 根据 ID 获取活动排程
+func (r *RoomEventRepo) GetRoomEventSchedule(ctx context.Context, id int64) (*game.RoomEventSchedule, error) {
+	query := `SELECT` + roomEventColumns + `
+		FROM room_event_schedules
+		WHERE id = $1
+	`
+
+	s, err := scanRoomEventSchedule(r.dbManager.Write().QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", game.ErrRoomEventNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room event schedule %d: %w", id, err)
+	}
+	return s, nil
+}
+
+// CreateRoomEventSchedule 创建活动排程并写回 ID 和时间戳
+func (r *RoomEventRepo) CreateRoomEventSchedule(ctx context.Context, s *game.RoomEventSchedule) error {
+	params, err := roomEventParams(s)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO room_event_schedules (
+			name, event_type, room_types, start_at, duration_minutes, timezone, recurrence,
+			reward_multiplier, tide_fish_type_id, tide_wave_size, tide_wave_seconds,
+			announcement, enabled, updated_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at, updated_at
+	`
+	if err := r.dbManager.Write().QueryRow(ctx, query, params...).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create room event schedule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRoomEventSchedule 更新活动排程
+func (r *RoomEventRepo) UpdateRoomEventSchedule(ctx context.Context, s *game.RoomEventSchedule) error {
+	params, err := roomEventParams(s)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE room_event_schedules SET
+			name = $1, event_type = $2, room_types = $3, start_at = $4, duration_minutes = $5,
+			timezone = $6, recurrence = $7, reward_multiplier = $8, tide_fish_type_id = $9,
+			tide_wave_size = $10, tide_wave_seconds = $11, announcement = $12, enabled = $13,
+			updated_by = $14, updated_at = NOW()
+		WHERE id = $15
+		RETURNING created_at, updated_at
+	`
+	err = r.dbManager.Write().QueryRow(ctx, query, append(params, s.ID)...).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("%w: %d", game.ErrRoomEventNotFound, s.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update room event schedule %d: %w", s.ID, err)
+	}
+	return nil
+}
+
+// DeleteRoomEventSchedule 删除活动排程
+func (r *RoomEventRepo) DeleteRoomEventSchedule(ctx context.Context, id int64) error {
+	tag, err := r.dbManager.Write().Exec(ctx, `DELETE FROM room_event_schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete room event schedule %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %d", game.ErrRoomEventNotFound, id)
+	}
+	return nil
+}
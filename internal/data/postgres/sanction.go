@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/jackc/pgx/v5"
+)
+
+// SanctionRepo 實現 account.SanctionRepo，處罰記錄保存在 player_sanctions（見 000017 遷移）
+type SanctionRepo struct {
+	dbManager *DBManager
+}
+
+// NewSanctionRepo 建立新的 SanctionRepo 實例
+func NewSanctionRepo(dbManager *DBManager) *SanctionRepo {
+	return &SanctionRepo{
+		dbManager: dbManager,
+	}
+}
+
+// sanctionColumns player_sanctions 查詢的列，順序與 scanSanction 一致
+const sanctionColumns = `
+		id, user_id, type, reason, room_types, issued_by, created_at,
+		expires_at, lifted_at, COALESCE(lifted_by, 0), lift_reason`
+
+// scanSanction 掃描一行 player_sanctions
+func scanSanction(row pgx.Row) (*account.Sanction, error) {
+	var s account.Sanction
+	var sanctionType string
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&sanctionType,
+		&s.Reason,
+		&s.RoomTypes,
+		&s.IssuedBy,
+		&s.CreatedAt,
+		&s.ExpiresAt,
+		&s.LiftedAt,
+		&s.LiftedBy,
+		&s.LiftReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.Type = account.SanctionType(sanctionType)
+	if len(s.RoomTypes) == 0 {
+		s.RoomTypes = nil
+	}
+	return &s, nil
+}
+
+// querySanctions 執行查詢並掃描所有處罰
+func querySanctions(ctx context.Context, db *Client, query string, args ...interface{}) ([]*account.Sanction, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sanctions := make([]*account.Sanction, 0)
+	for rows.Next() {
+		s, err := scanSanction(rows)
+		if err != nil {
+			return nil, err
+		}
+		sanctions = append(sanctions, s)
+	}
+	return sanctions, rows.Err()
+}
+
+// CreateSanction 保存處罰並設置 ID
+func (r *SanctionRepo) CreateSanction(ctx context.Context, sanction *account.Sanction) error {
+	roomTypes := sanction.RoomTypes
+	if roomTypes == nil {
+		roomTypes = []string{}
+	}
+	query := `
+		INSERT INTO player_sanctions (user_id, type, reason, room_types, issued_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	return r.dbManager.Write().QueryRow(ctx, query,
+		sanction.UserID,
+		string(sanction.Type),
+		sanction.Reason,
+		roomTypes,
+		sanction.IssuedBy,
+		sanction.CreatedAt,
+		sanction.ExpiresAt,
+	).Scan(&sanction.ID)
+}
+
+// ListSanctions 玩家的所有處罰記錄，最新的在前
+func (r *SanctionRepo) ListSanctions(ctx context.Context, userID int64) ([]*account.Sanction, error) {
+	return querySanctions(ctx, r.dbManager.Read(), `SELECT `+sanctionColumns+`
+		FROM player_sanctions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`, userID)
+}
+
+// ListActiveSanctions 玩家在 now 時生效的處罰；使用 Write DB，剛發出的處罰立即生效
+func (r *SanctionRepo) ListActiveSanctions(ctx context.Context, userID int64, now time.Time) ([]*account.Sanction, error) {
+	return querySanctions(ctx, r.dbManager.Write(), `SELECT `+sanctionColumns+`
+		FROM player_sanctions
+		WHERE user_id = $1 AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY created_at DESC, id DESC`, userID, now)
+}
+
+// LiftSanction 解除一條生效中的處罰
+func (r *SanctionRepo) LiftSanction(ctx context.Context, userID, sanctionID, liftedBy int64, reason string, at time.Time) (bool, error) {
+	tag, err := r.dbManager.Write().Exec(ctx, `
+		UPDATE player_sanctions
+		SET lifted_at = $3, lifted_by = $4, lift_reason = $5
+		WHERE id = $1 AND user_id = $2 AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > $3)
+	`, sanctionID, userID, at, liftedBy, reason)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// LiftSanctionsByType 解除玩家某一類型的所有生效中的處罰
+func (r *SanctionRepo) LiftSanctionsByType(ctx context.Context, userID int64, sanctionType account.SanctionType, liftedBy int64, reason string, at time.Time) (int, error) {
+	tag, err := r.dbManager.Write().Exec(ctx, `
+		UPDATE player_sanctions
+		SET lifted_at = $3, lifted_by = $4, lift_reason = $5
+		WHERE user_id = $1 AND type = $2 AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > $3)
+	`, userID, string(sanctionType), at, liftedBy, reason)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
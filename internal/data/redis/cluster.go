@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/go-redis/redis/v8"
+)
+
+// ClusterRedisRegistry implements game server node registration
+// 此檔案實現 NodeRegistry 介面：
+// - cluster:nodes 有序集合記錄節點 ID，分數為心跳過期時間（Unix 秒）
+// - cluster:node:{id} 存儲節點資訊 JSON，過期時間與心跳 TTL 一致
+// - 移除節點時一併刪除其房間上報 room:server:{id}
+// - cluster:drain:{id} 為管理後台發出的下線請求，節點在心跳時讀取
+
+const (
+	clusterNodesKey       = "cluster:nodes"
+	clusterNodeKeyPrefix  = "cluster:node:"
+	clusterDrainKeyPrefix = "cluster:drain:"
+	roomServerKeyPrefix   = "room:server:"
+
+	// drainRequestTTL 下線請求的有效期，節點未在此期間內讀取則請求失效
+	drainRequestTTL = 10 * time.Minute
+)
+
+// nodeRegistry 實現 lobby.NodeRegistry 介面
+type nodeRegistry struct {
+	client *redis.Client
+}
+
+// NewNodeRegistry 建立新的 NodeRegistry 實例
+func NewNodeRegistry(client *redis.Client) lobby.NodeRegistry {
+	return &nodeRegistry{
+		client: client,
+	}
+}
+
+// RegisterNode 註冊或刷新節點
+func (r *nodeRegistry) RegisterNode(ctx context.Context, node *lobby.GameNode, ttl time.Duration) error {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	expireAt := node.LastHeartbeat.Add(ttl).Unix()
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, clusterNodeKeyPrefix+node.NodeID, data, ttl)
+	pipe.ZAdd(ctx, clusterNodesKey, &redis.Z{Score: float64(expireAt), Member: node.NodeID})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeregisterNode 移除節點及其房間上報
+func (r *nodeRegistry) DeregisterNode(ctx context.Context, nodeID string) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZRem(ctx, clusterNodesKey, nodeID)
+	pipe.Del(ctx, clusterNodeKeyPrefix+nodeID, roomServerKeyPrefix+nodeID, clusterDrainKeyPrefix+nodeID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RequestDrain 請求節點進入下線模式
+func (r *nodeRegistry) RequestDrain(ctx context.Context, nodeID string) error {
+	return r.client.Set(ctx, clusterDrainKeyPrefix+nodeID, time.Now().Unix(), drainRequestTTL).Err()
+}
+
+// DrainRequested 檢查節點是否有待處理的下線請求
+func (r *nodeRegistry) DrainRequested(ctx context.Context, nodeID string) (bool, error) {
+	n, err := r.client.Exists(ctx, clusterDrainKeyPrefix+nodeID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ListNodes 獲取所有在線節點
+func (r *nodeRegistry) ListNodes(ctx context.Context) ([]*lobby.GameNode, error) {
+	ids, err := r.client.ZRange(ctx, clusterNodesKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = clusterNodeKeyPrefix + id
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*lobby.GameNode, 0, len(values))
+	for _, v := range values {
+		// 節點資訊已過期但尚未從有序集合中移除，等待下次清理
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		var node lobby.GameNode
+		if err := json.Unmarshal([]byte(s), &node); err != nil {
+			// 跳過無效的資料
+			continue
+		}
+		nodes = append(nodes, &node)
+	}
+
+	return nodes, nil
+}
+
+// EvictExpiredNodes 移除心跳已過期的節點
+func (r *nodeRegistry) EvictExpiredNodes(ctx context.Context, now time.Time) ([]string, error) {
+	ids, err := r.client.ZRangeByScore(ctx, clusterNodesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if err := r.DeregisterNode(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to evict node %s: %w", id, err)
+		}
+	}
+
+	return ids, nil
+}
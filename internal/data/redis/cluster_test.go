@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeRegistry(t *testing.T) {
+	setupTestRedis(t)
+
+	ctx := context.Background()
+	registry := NewNodeRegistry(testClient.Redis)
+	cache := NewRoomCache(testClient.Redis)
+
+	live := &lobby.GameNode{NodeID: "test-node-live", URL: "ws://live/ws", Connections: 3}
+	dead := &lobby.GameNode{NodeID: "test-node-dead", URL: "ws://dead/ws"}
+	defer func() {
+		_ = registry.DeregisterNode(ctx, live.NodeID)
+		_ = registry.DeregisterNode(ctx, dead.NodeID)
+	}()
+
+	require.NoError(t, registry.RegisterNode(ctx, live, time.Minute))
+	require.NoError(t, registry.RegisterNode(ctx, dead, time.Second))
+	require.NoError(t, cache.UpdateRoomInfo(ctx, dead.NodeID, []*lobby.RoomInfo{{RoomID: "room_dead", GameServerID: dead.NodeID}}))
+
+	nodes, err := registry.ListNodes(ctx)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.NodeID)
+	}
+	assert.Contains(t, ids, live.NodeID)
+	assert.Contains(t, ids, dead.NodeID)
+
+	// 模擬時間流逝：dead 節點的過期時間已過
+	evicted, err := registry.EvictExpiredNodes(ctx, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Contains(t, evicted, dead.NodeID)
+	assert.NotContains(t, evicted, live.NodeID)
+
+	exists, err := testClient.Redis.Exists(ctx, roomServerKeyPrefix+dead.NodeID).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+
+	nodes, err = registry.ListNodes(ctx)
+	require.NoError(t, err)
+	for _, node := range nodes {
+		assert.NotEqual(t, dead.NodeID, node.NodeID)
+	}
+}
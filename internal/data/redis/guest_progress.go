@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/go-redis/redis/v8"
+)
+
+// guestProgressKeyPrefix 遊客進度 hash，guest_progress:{nickname}
+// 字段：data（進度 JSON）、room_id（當前房間）、upgraded（升級標記）
+const guestProgressKeyPrefix = "guest_progress:"
+
+// saveGuestProgressScript 未升級時保存進度並重新設置過期時間
+var saveGuestProgressScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], "upgraded") == 1 then
+	return 0
+end
+redis.call("HSET", KEYS[1], "data", ARGV[1], "room_id", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return 1
+`)
+
+// claimGuestProgressScript 未升級且不在房間中時設置升級標記並返回進度
+var claimGuestProgressScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], "upgraded") == 1 then
+	return {"upgraded", ""}
+end
+local room = redis.call("HGET", KEYS[1], "room_id")
+if room and room ~= "" then
+	return {"in_room", ""}
+end
+redis.call("HSET", KEYS[1], "upgraded", "1")
+redis.call("PEXPIRE", KEYS[1], ARGV[1])
+return {"ok", redis.call("HGET", KEYS[1], "data") or ""}
+`)
+
+// GuestProgressStore 基於 Redis 的遊客進度存儲，實現 account.GuestProgressStore
+type GuestProgressStore struct {
+	client *redis.Client
+}
+
+// NewGuestProgressStore 創建新的 GuestProgressStore 實例
+func NewGuestProgressStore(client *redis.Client) *GuestProgressStore {
+	return &GuestProgressStore{
+		client: client,
+	}
+}
+
+func guestProgressKey(nickname string) string {
+	return guestProgressKeyPrefix + nickname
+}
+
+// decodeGuestProgress 解析進度 JSON，空字符串表示沒有保存過
+func decodeGuestProgress(data string) (*account.GuestProgress, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var progress account.GuestProgress
+	if err := json.Unmarshal([]byte(data), &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal guest progress: %w", err)
+	}
+	return &progress, nil
+}
+
+// LoadGuestProgress 獲取遊客進度
+func (s *GuestProgressStore) LoadGuestProgress(ctx context.Context, nickname string) (*account.GuestProgress, error) {
+	fields, err := s.client.HGetAll(ctx, guestProgressKey(nickname)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := fields["upgraded"]; ok {
+		return nil, account.ErrGuestAlreadyUpgraded
+	}
+	return decodeGuestProgress(fields["data"])
+}
+
+// SaveGuestProgress 保存遊客進度
+func (s *GuestProgressStore) SaveGuestProgress(ctx context.Context, progress *account.GuestProgress, ttl time.Duration) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal guest progress %s: %w", progress.Nickname, err)
+	}
+	saved, err := saveGuestProgressScript.Run(ctx, s.client, []string{guestProgressKey(progress.Nickname)},
+		data, progress.RoomID(), ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if saved == 0 {
+		return account.ErrGuestAlreadyUpgraded
+	}
+	return nil
+}
+
+// ClaimGuestProgress 把遊客標記為升級中並返回進度
+func (s *GuestProgressStore) ClaimGuestProgress(ctx context.Context, nickname string, ttl time.Duration) (*account.GuestProgress, error) {
+	result, err := claimGuestProgressScript.Run(ctx, s.client, []string{guestProgressKey(nickname)}, ttl.Milliseconds()).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 2 {
+		return nil, fmt.Errorf("unexpected claim result for guest %s: %v", nickname, result)
+	}
+
+	switch result[0] {
+	case "upgraded":
+		return nil, account.ErrGuestAlreadyUpgraded
+	case "in_room":
+		return nil, account.ErrGuestInRoom
+	}
+	return decodeGuestProgress(result[1])
+}
+
+// ReleaseGuestProgress 撤銷升級標記
+func (s *GuestProgressStore) ReleaseGuestProgress(ctx context.Context, nickname string) error {
+	return s.client.HDel(ctx, guestProgressKey(nickname), "upgraded").Err()
+}
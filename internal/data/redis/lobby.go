@@ -27,7 +27,7 @@ func NewRoomCache(client *redis.Client) lobby.RoomCache {
 // GetAllRooms 獲取所有房間資訊
 func (c *roomCache) GetAllRooms(ctx context.Context) ([]*lobby.RoomInfo, error) {
 	// 使用 SCAN 命令獲取所有 "room:server:*" 鍵（比 KEYS 更安全）
-	pattern := "room:server:*"
+	pattern := roomServerKeyPrefix + "*"
 	var allRooms []*lobby.RoomInfo
 
 	// 使用 SCAN 遍歷所有匹配的鍵
@@ -71,7 +71,7 @@ func (c *roomCache) UpdateRoomInfo(ctx context.Context, gameServerID string, roo
 		return err
 	}
 
-	key := "room:server:" + gameServerID
+	key := roomServerKeyPrefix + gameServerID
 
 	// 使用 SET 命令存儲資料，並設定過期時間為 15 秒
 	err = c.client.Set(ctx, key, data, 15*time.Second).Err()
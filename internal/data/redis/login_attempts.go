@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// loginFailuresKeyPrefix 登入失敗次數，login_failures:{scope}:{值}，最近一次失敗後統計窗口過期
+	loginFailuresKeyPrefix = "login_failures:"
+	// loginLockKeyPrefix 登入退避或鎖定，login_lock:{scope}:{值}，值為到期時間（Unix 毫秒）
+	loginLockKeyPrefix = "login_lock:"
+)
+
+// recordFailureScript 增加失敗次數並重設過期時間，返回累計值
+var recordFailureScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[1])
+return count
+`)
+
+// LoginAttemptStore 基於 Redis 的登入失敗計數和鎖定，實現 account.LoginAttemptStore
+type LoginAttemptStore struct {
+	client *redis.Client
+}
+
+// NewLoginAttemptStore 創建新的 LoginAttemptStore 實例
+func NewLoginAttemptStore(client *redis.Client) *LoginAttemptStore {
+	return &LoginAttemptStore{
+		client: client,
+	}
+}
+
+// RecordFailure 增加失敗次數並返回累計值
+func (s *LoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	count, err := recordFailureScript.Run(ctx, s.client, []string{loginFailuresKeyPrefix + key}, window.Milliseconds()).Int()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Lock 在 until 之前拒絕登入，已有更晚的鎖定時保持不變
+func (s *LoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	current, err := s.LockedUntil(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !current.Before(until) {
+		return nil
+	}
+	return s.client.Set(ctx, loginLockKeyPrefix+key, until.UnixMilli(), time.Until(until)).Err()
+}
+
+// LockedUntil 鎖定到期時間，未鎖定時返回零值
+func (s *LoginAttemptStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	value, err := s.client.Get(ctx, loginLockKeyPrefix+key).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(millis), nil
+}
+
+// Reset 清除失敗次數和鎖定
+func (s *LoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, loginFailuresKeyPrefix+key, loginLockKeyPrefix+key).Err()
+}
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/go-redis/redis/v8"
+)
+
+// oauthStateKeyPrefix OAuth 授權狀態，oauth_state:{state}，回調時取出並刪除
+const oauthStateKeyPrefix = "oauth_state:"
+
+// OAuthStateStore 基於 Redis 的 OAuth 授權狀態存儲，實現 account.OAuthStateStore
+type OAuthStateStore struct {
+	client *redis.Client
+}
+
+// NewOAuthStateStore 創建新的 OAuthStateStore 實例
+func NewOAuthStateStore(client *redis.Client) *OAuthStateStore {
+	return &OAuthStateStore{
+		client: client,
+	}
+}
+
+// SaveOAuthState 保存授權狀態
+func (s *OAuthStateStore) SaveOAuthState(ctx context.Context, state string, data *account.OAuthState, ttl time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal oauth state: %w", err)
+	}
+	return s.client.Set(ctx, oauthStateKeyPrefix+state, payload, ttl).Err()
+}
+
+// TakeOAuthState 取出並刪除授權狀態，保證每個 state 只能使用一次
+func (s *OAuthStateStore) TakeOAuthState(ctx context.Context, state string) (*account.OAuthState, error) {
+	payload, err := s.client.GetDel(ctx, oauthStateKeyPrefix+state).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data account.OAuthState
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal oauth state: %w", err)
+	}
+	return &data, nil
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/go-redis/redis/v8"
+)
+
+// roomCheckpointStore implements game.RoomCheckpointRepo
+// 此檔案實現房間檢查點的存儲：
+// - game:checkpoints:{node_id} 雜湊表，欄位為房間 ID，值為檢查點 JSON，過期時間為檢查點 TTL
+// - game:checkpoint_nodes 有序集合記錄有檢查點的節點，分數為最後保存時間（Unix 秒）
+// - game:checkpoint_claim:{node_id} 記錄正在恢復該節點檢查點的節點，防止多個節點重複恢復
+
+const (
+	roomCheckpointKeyPrefix   = "game:checkpoints:"
+	roomCheckpointNodesKey    = "game:checkpoint_nodes"
+	roomCheckpointClaimPrefix = "game:checkpoint_claim:"
+)
+
+// roomCheckpointStore 實現 game.RoomCheckpointRepo 介面
+type roomCheckpointStore struct {
+	client *redis.Client
+}
+
+// NewRoomCheckpointStore 建立新的 RoomCheckpointRepo 實例
+func NewRoomCheckpointStore(client *redis.Client) game.RoomCheckpointRepo {
+	return &roomCheckpointStore{
+		client: client,
+	}
+}
+
+// SaveCheckpoints 以本次檢查點替換節點的全部檢查點
+func (s *roomCheckpointStore) SaveCheckpoints(ctx context.Context, nodeID string, checkpoints []*game.RoomCheckpoint, ttl time.Duration) error {
+	values := make([]interface{}, 0, 2*len(checkpoints))
+	for _, cp := range checkpoints {
+		data, err := json.Marshal(cp)
+		if err != nil {
+			return fmt.Errorf("failed to marshal checkpoint of room %s: %w", cp.Room.ID, err)
+		}
+		values = append(values, cp.Room.ID, data)
+	}
+
+	key := roomCheckpointKeyPrefix + nodeID
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, key)
+	if len(values) > 0 {
+		pipe.HSet(ctx, key, values...)
+		pipe.Expire(ctx, key, ttl)
+		pipe.ZAdd(ctx, roomCheckpointNodesKey, &redis.Z{Score: float64(time.Now().Unix()), Member: nodeID})
+	} else {
+		pipe.ZRem(ctx, roomCheckpointNodesKey, nodeID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// LoadCheckpoints 讀取節點的全部檢查點，跳過無法解析的資料
+func (s *roomCheckpointStore) LoadCheckpoints(ctx context.Context, nodeID string) ([]*game.RoomCheckpoint, error) {
+	values, err := s.client.HGetAll(ctx, roomCheckpointKeyPrefix+nodeID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := make([]*game.RoomCheckpoint, 0, len(values))
+	for _, v := range values {
+		var cp game.RoomCheckpoint
+		if err := json.Unmarshal([]byte(v), &cp); err != nil || cp.Room == nil {
+			continue
+		}
+		checkpoints = append(checkpoints, &cp)
+	}
+	return checkpoints, nil
+}
+
+// DeleteCheckpoints 刪除節點的全部檢查點及恢復聲明
+func (s *roomCheckpointStore) DeleteCheckpoints(ctx context.Context, nodeID string) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, roomCheckpointKeyPrefix+nodeID, roomCheckpointClaimPrefix+nodeID)
+	pipe.ZRem(ctx, roomCheckpointNodesKey, nodeID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// CheckpointNodes 有檢查點的節點 ID
+func (s *roomCheckpointStore) CheckpointNodes(ctx context.Context) ([]string, error) {
+	return s.client.ZRange(ctx, roomCheckpointNodesKey, 0, -1).Result()
+}
+
+// ClaimCheckpoints 聲明恢復節點的檢查點，已由 claimer 聲明時同樣返回 true
+func (s *roomCheckpointStore) ClaimCheckpoints(ctx context.Context, nodeID, claimer string, ttl time.Duration) (bool, error) {
+	key := roomCheckpointClaimPrefix + nodeID
+	ok, err := s.client.SetNX(ctx, key, claimer, ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+
+	owner, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return owner == claimer, nil
+}
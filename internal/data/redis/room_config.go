@@ -66,3 +66,56 @@ func (c *RoomConfigCache) GetAllRoomConfigs(ctx context.Context) (map[string]*ga
 	// 这个方法可以根据需要实现，但通常我们会逐个房间类型获取缓存
 	return nil, fmt.Errorf("not implemented: use GetRoomConfig for each room type")
 }
+
+// roomConfigChannel 房间配置变更频道，消息为 game.RoomConfigVersion JSON
+const roomConfigChannel = "game:room_configs:updates"
+
+// roomConfigBus 实现 game.RoomConfigBus 接口
+type roomConfigBus struct {
+	client *redis.Client
+}
+
+// NewRoomConfigBus 创建基于 Redis pub/sub 的房间配置变更通知
+func NewRoomConfigBus(client *redis.Client) game.RoomConfigBus {
+	return &roomConfigBus{
+		client: client,
+	}
+}
+
+// PublishRoomConfig 发布房间配置的新版本
+func (b *roomConfigBus) PublishRoomConfig(ctx context.Context, version *game.RoomConfigVersion) error {
+	data, err := json.Marshal(version)
+	if err != nil {
+		return fmt.Errorf("failed to marshal room config %s version %d: %w", version.RoomType, version.Version, err)
+	}
+	return b.client.Publish(ctx, roomConfigChannel, data).Err()
+}
+
+// SubscribeRoomConfigs 订阅房间配置变更直到 ctx 取消，跳过无法解析的消息
+// 订阅期间断线重连时错过的消息不会补发，调用方需定期从数据库重新同步
+func (b *roomConfigBus) SubscribeRoomConfigs(ctx context.Context, handler func(version *game.RoomConfigVersion)) error {
+	sub := b.client.Subscribe(ctx, roomConfigChannel)
+	defer sub.Close()
+
+	// 等待订阅确认，连接失败时立即返回
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", roomConfigChannel, err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("subscription to %s closed", roomConfigChannel)
+			}
+			var version game.RoomConfigVersion
+			if err := json.Unmarshal([]byte(msg.Payload), &version); err != nil {
+				continue
+			}
+			handler(&version)
+		}
+	}
+}
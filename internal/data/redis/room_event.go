@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/lobby"
+	"github.com/go-redis/redis/v8"
+)
+
+// roomEventBoardKey 定時活動看板，值為 []*lobby.RoomEvent JSON
+const roomEventBoardKey = "lobby:room_events"
+
+// roomEventBoard 實現 lobby.RoomEventBoard 介面
+type roomEventBoard struct {
+	client *redis.Client
+}
+
+// NewRoomEventBoard 建立新的 RoomEventBoard 實例
+func NewRoomEventBoard(client *redis.Client) lobby.RoomEventBoard {
+	return &roomEventBoard{
+		client: client,
+	}
+}
+
+// PublishRoomEvents 替換活動看板，所有 Game Server 寫入相同的排程計算結果
+func (b *roomEventBoard) PublishRoomEvents(ctx context.Context, events []*lobby.RoomEvent, ttl time.Duration) error {
+	if events == nil {
+		events = []*lobby.RoomEvent{}
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal room events: %w", err)
+	}
+	return b.client.Set(ctx, roomEventBoardKey, data, ttl).Err()
+}
+
+// GetRoomEvents 獲取活動看板上的活動，看板不存在時返回空列表
+func (b *roomEventBoard) GetRoomEvents(ctx context.Context) ([]*lobby.RoomEvent, error) {
+	data, err := b.client.Get(ctx, roomEventBoardKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*lobby.RoomEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal room events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/go-redis/redis/v8"
+)
+
+// roomHistoryStore implements game.RoomHistoryRepo
+// 此檔案實現房間狀態轉換歷史的存儲：
+// - game:room_history:{room_id} 列表，按時間順序保存轉換記錄 JSON，只保留最近 roomHistoryLimit 條
+// - 每次追加時刷新過期時間，房間關閉 roomHistoryTTL 後自動清除
+
+const (
+	roomHistoryKeyPrefix = "game:room_history:"
+	roomHistoryLimit     = 100
+	roomHistoryTTL       = 24 * time.Hour
+)
+
+// roomHistoryStore 實現 game.RoomHistoryRepo 介面
+type roomHistoryStore struct {
+	client *redis.Client
+}
+
+// NewRoomHistoryStore 建立新的 RoomHistoryRepo 實例
+func NewRoomHistoryStore(client *redis.Client) game.RoomHistoryRepo {
+	return &roomHistoryStore{
+		client: client,
+	}
+}
+
+// AppendRoomTransition 追加一條轉換記錄
+func (s *roomHistoryStore) AppendRoomTransition(ctx context.Context, transition game.RoomTransition) error {
+	data, err := json.Marshal(transition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transition of room %s: %w", transition.RoomID, err)
+	}
+
+	key := roomHistoryKeyPrefix + transition.RoomID
+	pipe := s.client.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.LTrim(ctx, key, -roomHistoryLimit, -1)
+	pipe.Expire(ctx, key, roomHistoryTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RoomHistory 按時間順序獲取房間的轉換記錄，跳過無法解析的資料
+func (s *roomHistoryStore) RoomHistory(ctx context.Context, roomID string) ([]game.RoomTransition, error) {
+	values, err := s.client.LRange(ctx, roomHistoryKeyPrefix+roomID, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]game.RoomTransition, 0, len(values))
+	for _, v := range values {
+		var transition game.RoomTransition
+		if err := json.Unmarshal([]byte(v), &transition); err != nil {
+			continue
+		}
+		history = append(history, transition)
+	}
+	return history, nil
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomHistoryStore(t *testing.T) {
+	setupTestRedis(t)
+
+	ctx := context.Background()
+	store := NewRoomHistoryStore(testClient.Redis)
+	roomID := "test_room_history"
+	defer testClient.Redis.Del(ctx, roomHistoryKeyPrefix+roomID)
+
+	history, err := store.RoomHistory(ctx, roomID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	for i := 0; i < roomHistoryLimit+5; i++ {
+		require.NoError(t, store.AppendRoomTransition(ctx, game.RoomTransition{
+			RoomID: roomID,
+			From:   game.RoomStatusWaiting,
+			To:     game.RoomStatusPlaying,
+			Reason: fmt.Sprintf("transition %d", i),
+			At:     time.Now(),
+		}))
+	}
+
+	// 只保留最近的記錄，按時間順序返回
+	history, err = store.RoomHistory(ctx, roomID)
+	require.NoError(t, err)
+	require.Len(t, history, roomHistoryLimit)
+	assert.Equal(t, "transition 5", history[0].Reason)
+	assert.Equal(t, fmt.Sprintf("transition %d", roomHistoryLimit+4), history[len(history)-1].Reason)
+
+	ttl, err := testClient.Redis.TTL(ctx, roomHistoryKeyPrefix+roomID).Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, time.Hour)
+}
@@ -0,0 +1,272 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// sessionKeyPrefix 會話 hash，session:{session_id}，在刷新令牌過期時自動刪除
+	sessionKeyPrefix = "session:"
+	// userSessionsKeyPrefix 玩家會話索引 set，user_sessions:{user_id}，遊客會話不建立索引
+	userSessionsKeyPrefix = "user_sessions:"
+	// sessionRotatedKeyPrefix 會話已輪換的刷新令牌雜湊 set，session_rotated:{session_id}，與會話同時過期
+	sessionRotatedKeyPrefix = "session_rotated:"
+	// sessionRevokedChannel 會話撤銷頻道，消息為 account.SessionRevocation JSON
+	sessionRevokedChannel = "account:sessions:revoked"
+)
+
+// rotateRefreshScript 當前刷新令牌雜湊等於 ARGV[1] 時替換為 ARGV[2]，更新最近使用時間並延長過期時間
+// 被替換的雜湊加入 KEYS[2]，用於識別舊刷新令牌的重複使用
+var rotateRefreshScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "refresh_hash") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "refresh_hash", ARGV[2], "last_used_at", ARGV[3], "expires_at", ARGV[4])
+redis.call("PEXPIREAT", KEYS[1], ARGV[4])
+redis.call("SADD", KEYS[2], ARGV[1])
+redis.call("PEXPIREAT", KEYS[2], ARGV[4])
+return 1
+`)
+
+// SessionStore 基於 Redis 的登入會話存儲，實現 account.SessionStore 和 token.SessionValidator
+type SessionStore struct {
+	client *redis.Client
+}
+
+// NewSessionStore 創建新的 SessionStore 實例
+func NewSessionStore(client *redis.Client) *SessionStore {
+	return &SessionStore{
+		client: client,
+	}
+}
+
+func sessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func sessionRotatedKey(sessionID string) string {
+	return sessionRotatedKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID int64) string {
+	return userSessionsKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+// CreateSession 保存新會話和刷新令牌雜湊
+func (s *SessionStore) CreateSession(ctx context.Context, session *account.Session, refreshHash string) error {
+	key := sessionKey(session.ID)
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"user_id", session.UserID,
+		"is_guest", session.IsGuest,
+		"nickname", session.Nickname,
+		"user_agent", session.UserAgent,
+		"ip", session.IP,
+		"created_at", session.CreatedAt.UnixMilli(),
+		"last_used_at", session.LastUsedAt.UnixMilli(),
+		"expires_at", session.ExpiresAt.UnixMilli(),
+		"refresh_hash", refreshHash,
+	)
+	pipe.PExpireAt(ctx, key, session.ExpiresAt)
+	if session.UserID > 0 {
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+		// 索引至少保留到最晚過期的會話，過期的會話 ID 在列表時清理
+		pipe.Expire(ctx, userSessionsKey(session.UserID), time.Until(session.ExpiresAt))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// parseSession 從會話 hash 解析會話
+func parseSession(sessionID string, fields map[string]string) *account.Session {
+	userID, _ := strconv.ParseInt(fields["user_id"], 10, 64)
+	isGuest, _ := strconv.ParseBool(fields["is_guest"])
+	millis := func(name string) time.Time {
+		v, _ := strconv.ParseInt(fields[name], 10, 64)
+		return time.UnixMilli(v).UTC()
+	}
+	return &account.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		IsGuest:    isGuest,
+		Nickname:   fields["nickname"],
+		UserAgent:  fields["user_agent"],
+		IP:         fields["ip"],
+		CreatedAt:  millis("created_at"),
+		LastUsedAt: millis("last_used_at"),
+		ExpiresAt:  millis("expires_at"),
+	}
+}
+
+// GetSession 獲取會話和當前刷新令牌雜湊
+func (s *SessionStore) GetSession(ctx context.Context, sessionID string) (*account.Session, string, error) {
+	fields, err := s.client.HGetAll(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(fields) == 0 {
+		return nil, "", account.ErrSessionNotFound
+	}
+	return parseSession(sessionID, fields), fields["refresh_hash"], nil
+}
+
+// RotateRefreshToken 原子地比較並替換刷新令牌雜湊
+func (s *SessionStore) RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string, lastUsedAt, expiresAt time.Time) (bool, error) {
+	n, err := rotateRefreshScript.Run(ctx, s.client, []string{sessionKey(sessionID), sessionRotatedKey(sessionID)},
+		oldHash, newHash, lastUsedAt.UnixMilli(), expiresAt.UnixMilli()).Int()
+	if err != nil {
+		return false, err
+	}
+	if n == 1 {
+		if session, _, err := s.GetSession(ctx, sessionID); err == nil && session.UserID > 0 {
+			s.client.Expire(ctx, userSessionsKey(session.UserID), time.Until(expiresAt))
+		}
+	}
+	return n == 1, nil
+}
+
+// RefreshTokenRotated 雜湊是否為該會話已輪換掉的刷新令牌
+func (s *SessionStore) RefreshTokenRotated(ctx context.Context, sessionID, refreshHash string) (bool, error) {
+	return s.client.SIsMember(ctx, sessionRotatedKey(sessionID), refreshHash).Result()
+}
+
+// ListUserSessions 玩家所有有效會話，順便清理索引中已過期的會話 ID
+func (s *SessionStore) ListUserSessions(ctx context.Context, userID int64) ([]*account.Session, error) {
+	ids, err := s.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, sessionKey(id))
+	}
+	if len(ids) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	sessions := make([]*account.Session, 0, len(ids))
+	var expired []interface{}
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			expired = append(expired, ids[i])
+			continue
+		}
+		sessions = append(sessions, parseSession(ids[i], fields))
+	}
+	if len(expired) > 0 {
+		s.client.SRem(ctx, userSessionsKey(userID), expired...)
+	}
+	return sessions, nil
+}
+
+// DeleteSession 刪除會話及其索引
+func (s *SessionStore) DeleteSession(ctx context.Context, session *account.Session) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, sessionKey(session.ID), sessionRotatedKey(session.ID))
+	if session.UserID > 0 {
+		pipe.SRem(ctx, userSessionsKey(session.UserID), session.ID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// DeleteUserSessions 刪除玩家所有會話，返回仍然有效的被刪除會話 ID
+func (s *SessionStore) DeleteUserSessions(ctx context.Context, userID int64) ([]string, error) {
+	ids, err := s.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	pipe := s.client.TxPipeline()
+	cmds := make([]*redis.IntCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.Del(ctx, sessionKey(id))
+		pipe.Del(ctx, sessionRotatedKey(id))
+	}
+	pipe.Del(ctx, userSessionsKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	deleted := make([]string, 0, len(ids))
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			deleted = append(deleted, ids[i])
+		}
+	}
+	return deleted, nil
+}
+
+// SessionActive 會話是否存在
+func (s *SessionStore) SessionActive(ctx context.Context, sessionID string) (bool, error) {
+	n, err := s.client.Exists(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// sessionEvents 實現 account.SessionEvents 接口
+type sessionEvents struct {
+	client *redis.Client
+}
+
+// NewSessionEvents 創建基於 Redis pub/sub 的會話撤銷通知
+func NewSessionEvents(client *redis.Client) account.SessionEvents {
+	return &sessionEvents{
+		client: client,
+	}
+}
+
+// PublishSessionRevoked 發布會話撤銷
+func (e *sessionEvents) PublishSessionRevoked(ctx context.Context, revocation *account.SessionRevocation) error {
+	data, err := json.Marshal(revocation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session revocation for user %d: %w", revocation.UserID, err)
+	}
+	return e.client.Publish(ctx, sessionRevokedChannel, data).Err()
+}
+
+// SubscribeSessionRevoked 訂閱會話撤銷直到 ctx 取消，跳過無法解析的消息
+// 斷線期間錯過的消息不會補發，調用方需定期檢查會話是否仍然有效
+func (e *sessionEvents) SubscribeSessionRevoked(ctx context.Context, handler func(revocation *account.SessionRevocation)) error {
+	sub := e.client.Subscribe(ctx, sessionRevokedChannel)
+	defer sub.Close()
+
+	// 等待訂閱確認，連接失敗時立即返回
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", sessionRevokedChannel, err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("subscription to %s closed", sessionRevokedChannel)
+			}
+			var revocation account.SessionRevocation
+			if err := json.Unmarshal([]byte(msg.Payload), &revocation); err != nil {
+				continue
+			}
+			handler(&revocation)
+		}
+	}
+}
@@ -74,3 +74,30 @@ func (r *roomConfigRepo) GetAllRoomConfigs(ctx context.Context) (map[string]*gam
 
 	return configs, nil
 }
+
+// CurrentRoomConfig 获取房间类型的当前配置版本（直接读 DB，管理后台修改前需要最新版本）
+func (r *roomConfigRepo) CurrentRoomConfig(ctx context.Context, roomType game.RoomType) (*game.RoomConfigVersion, error) {
+	return r.pgRepo.CurrentRoomConfig(ctx, roomType)
+}
+
+// ListRoomConfigs 获取所有房间类型的当前配置版本
+func (r *roomConfigRepo) ListRoomConfigs(ctx context.Context) ([]*game.RoomConfigVersion, error) {
+	return r.pgRepo.ListRoomConfigs(ctx)
+}
+
+// SaveRoomConfig 保存新版本到 DB 并清除缓存
+func (r *roomConfigRepo) SaveRoomConfig(ctx context.Context, v *game.RoomConfigVersion, expectedVersion int64) error {
+	if err := r.pgRepo.SaveRoomConfig(ctx, v, expectedVersion); err != nil {
+		return err
+	}
+
+	if err := r.cache.DeleteRoomConfig(ctx, string(v.RoomType)); err != nil {
+		r.logger.Warnf("Failed to invalidate cached room config %s: %v", v.RoomType, err)
+	}
+	return nil
+}
+
+// RoomConfigHistory 获取房间类型最近的配置版本
+func (r *roomConfigRepo) RoomConfigHistory(ctx context.Context, roomType game.RoomType, limit int) ([]*game.RoomConfigVersion, error) {
+	return r.pgRepo.RoomConfigHistory(ctx, roomType, limit)
+}
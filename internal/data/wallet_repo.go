@@ -4,7 +4,6 @@ package data
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
@@ -153,7 +152,7 @@ func (r *walletRepo) FindByID(ctx context.Context, id uint) (*wallet.Wallet, err
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, errors.New("wallet not found")
+			return nil, wallet.ErrWalletNotFound
 		}
 		r.logger.Errorf("failed to find wallet by id: %v", err)
 		return nil, err
@@ -202,7 +201,7 @@ func (r *walletRepo) FindByUserID(ctx context.Context, userID uint, currency str
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, errors.New("wallet not found")
+			return nil, wallet.ErrWalletNotFound
 		}
 		r.logger.Errorf("failed to find wallet by user_id and currency: %v", err)
 		return nil, err
@@ -308,7 +307,7 @@ func (r *walletRepo) Update(ctx context.Context, w *wallet.Wallet) error {
 
 	// 檢查是否有記錄被更新
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("wallet with id %d not found", w.ID)
+		return fmt.Errorf("%w: id %d", wallet.ErrWalletNotFound, w.ID)
 	}
 
 	// 更新對象的 UpdatedAt 字段
@@ -499,7 +498,7 @@ func (r *walletRepo) Deposit(ctx context.Context, walletID uint, amount float64,
 
 	// 檢查金額
 	if amount <= 0 {
-		return errors.New("deposit amount must be positive")
+		return fmt.Errorf("deposit %w", wallet.ErrInvalidAmount)
 	}
 
 	// 查詢錢包並鎖定（需要獲取 user_id 和 currency 用於緩存失效）
@@ -510,7 +509,7 @@ func (r *walletRepo) Deposit(ctx context.Context, walletID uint, amount float64,
 	err = tx.QueryRow(ctx, query, walletID).Scan(&currentBalance, &userID, &currency)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return fmt.Errorf("wallet not found")
+			return wallet.ErrWalletNotFound
 		}
 		r.logger.Errorf("failed to find wallet: %v", err)
 		return err
@@ -593,19 +592,19 @@ func (r *walletRepo) Withdraw(ctx context.Context, walletID uint, amount float64
 	// 查詢錢包並鎖定
 	var w WalletPO
 	query := `
-		SELECT id, user_id, balance, status, created_at, updated_at 
+		SELECT id, user_id, balance, currency, status, created_at, updated_at 
 		FROM wallets 
 		WHERE id = $1 
 		FOR UPDATE
 	`
 
 	err = tx.QueryRow(ctx, query, walletID).Scan(
-		&w.ID, &w.UserID, &w.Balance, &w.Status, &w.CreatedAt, &w.UpdatedAt,
+		&w.ID, &w.UserID, &w.Balance, &w.Currency, &w.Status, &w.CreatedAt, &w.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return fmt.Errorf("wallet not found")
+			return wallet.ErrWalletNotFound
 		}
 		r.logger.Errorf("failed to find wallet: %v", err)
 		return err
@@ -613,17 +612,17 @@ func (r *walletRepo) Withdraw(ctx context.Context, walletID uint, amount float64
 
 	// 檢查錢包狀態
 	if w.Status != 1 {
-		return errors.New("wallet is frozen")
+		return wallet.ErrWalletFrozen
 	}
 
 	// 檢查金額
 	if amount <= 0 {
-		return errors.New("withdraw amount must be positive")
+		return fmt.Errorf("withdraw %w", wallet.ErrInvalidAmount)
 	}
 
 	// 檢查餘額
 	if w.Balance < amount {
-		return errors.New("insufficient balance")
+		return wallet.ErrInsufficientBalance
 	}
 
 	// 更新餘額
@@ -697,3 +696,14 @@ func (r *walletRepo) Withdraw(ctx context.Context, walletID uint, amount float64
 
 	return nil
 }
+
+// invalidateWalletCache 清除錢包（按 ID 和按用戶幣種）及其交易歷史的快取
+func (r *walletRepo) invalidateWalletCache(ctx context.Context, walletID, userID uint, currency string) {
+	if err := r.data.redis.Del(ctx, fmt.Sprintf("wallet:%d", walletID)); err != nil {
+		r.logger.Warnf("Failed to delete wallet cache by id: %v", err)
+	}
+	if err := r.data.redis.Del(ctx, fmt.Sprintf("wallet:user_id:%d:currency:%s", userID, currency)); err != nil {
+		r.logger.Warnf("Failed to delete wallet cache by user id: %v", err)
+	}
+	r.invalidateTransactionCache(ctx, walletID)
+}
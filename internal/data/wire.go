@@ -2,6 +2,7 @@
 package data
 
 import (
+	"github.com/b7777777v/fish_server/internal/biz/account"
 	"github.com/b7777777v/fish_server/internal/biz/game"
 	"github.com/b7777777v/fish_server/internal/data/postgres" // Import postgres client
 	"github.com/b7777777v/fish_server/internal/data/redis"    // Import redis client
@@ -16,6 +17,7 @@ var ProviderSet = wire.NewSet(
 	NewGamePlayerRepo,
 	NewPlayerRepo,
 	NewWalletRepo,
+	NewExchangeRepo,
 	NewGameRecordRepo,
 
 	// Add the new inventory repo provider
@@ -28,11 +30,23 @@ var ProviderSet = wire.NewSet(
 
 	// Add RoomConfigRepo provider
 	NewRoomConfigRepo,
+	NewRoomConfigBus,
+
+	// Scheduled room events
+	NewRoomEventRepo,
+	NewRoomEventBoard,
 
 	// Account and Lobby repo providers
 	NewAccountRepo,
+	NewSanctionRepo,
+	NewLoginHistoryRepo,
+	NewAdminRepo,
+	NewAuditRepo,
 	NewLobbyRepo,
 	NewRoomCache,
+	NewNodeRegistry,
+	NewRoomCheckpointRepo,
+	NewRoomHistoryRepo,
 	NewLobbyPlayerRepo,
 	NewLobbyWalletRepo,
 
@@ -43,6 +57,15 @@ var ProviderSet = wire.NewSet(
 	// Token cache provider
 	redis.NewTokenCache,
 	wire.Bind(new(token.TokenCache), new(*redis.TokenCache)),
+
+	// Login sessions and refresh tokens
+	NewSessionStore,
+	wire.Bind(new(account.SessionStore), new(*redis.SessionStore)),
+	wire.Bind(new(token.SessionValidator), new(*redis.SessionStore)),
+	NewSessionEvents,
+	NewGuestProgressStore,
+	NewOAuthStateStore,
+	NewLoginAttemptStore,
 )
 
 // ProvideDBManager extracts *postgres.DBManager from *Data
@@ -3,6 +3,8 @@ package token
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/b7777777v/fish_server/internal/conf"
@@ -12,12 +14,31 @@ import (
 
 // CustomClaims 定義了我們想要在 JWT 中攜帶的自訂資料
 type CustomClaims struct {
-	UserID   int64  `json:"user_id"`
-	IsGuest  bool   `json:"is_guest,omitempty"`  // 是否為遊客
-	Nickname string `json:"nickname,omitempty"`  // 遊客昵稱（僅遊客使用）
+	UserID       int64  `json:"user_id"`
+	IsGuest      bool   `json:"is_guest,omitempty"`      // 是否為遊客
+	Nickname     string `json:"nickname,omitempty"`      // 遊客昵稱（僅遊客使用）
+	SpectateRoom string `json:"spectate_room,omitempty"` // 客服觀戰令牌限定的房間（僅管理後台簽發）
+	Admin        bool   `json:"admin,omitempty"`         // 管理員令牌，UserID 為 admin_users.id
+	SessionID    string `json:"sid,omitempty"`           // 登入會話 ID，會話被登出或撤銷後令牌立即失效
+	StepUpAt     int64  `json:"step_up_at,omitempty"`    // 管理員令牌最近一次通過兩步驗證的時間（Unix 秒）
 	jwt.RegisteredClaims
 }
 
+// ErrSessionRevoked 令牌所屬的登入會話已登出、撤銷或過期
+var ErrSessionRevoked = errors.New("session revoked")
+
+const (
+	// defaultAccessExpire 未配置時會話訪問令牌的有效期
+	defaultAccessExpire = 15 * time.Minute
+	// defaultRefreshExpire 未配置時刷新令牌的閒置有效期
+	defaultRefreshExpire = 30 * 24 * time.Hour
+)
+
+// SessionValidator 檢查登入會話是否仍然有效
+type SessionValidator interface {
+	SessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
 // TokenCache 定義 token 快取的介面
 type TokenCache interface {
 	StoreToken(ctx context.Context, token string, userID int64) error
@@ -28,30 +49,40 @@ type TokenCache interface {
 
 // TokenHelper 是一個輔助工具，用於生成和解析 JWT
 type TokenHelper struct {
-	secret     []byte
-	issuer     string
-	expire     int64
-	tokenCache TokenCache // Redis token cache（可選）
+	secret        []byte
+	issuer        string
+	expire        int64
+	accessExpire  time.Duration
+	refreshExpire time.Duration
+	tokenCache    TokenCache       // Redis token cache（可選）
+	sessions      SessionValidator // 登入會話檢查（可選）
 }
 
 // NewTokenHelper 創建一個新的 TokenHelper
 func NewTokenHelper(c *conf.JWT) *TokenHelper {
 	return &TokenHelper{
-		secret:     []byte(c.Secret),
-		issuer:     c.Issuer,
-		expire:     c.Expire,
-		tokenCache: nil, // 預設不啟用 cache
+		secret:        []byte(c.Secret),
+		issuer:        c.Issuer,
+		expire:        c.Expire,
+		accessExpire:  secondsOr(c.AccessExpire, defaultAccessExpire),
+		refreshExpire: secondsOr(c.RefreshExpire, defaultRefreshExpire),
+		tokenCache:    nil, // 預設不啟用 cache
 	}
 }
 
 // NewTokenHelperWithCache 創建一個帶 Redis cache 的 TokenHelper
 func NewTokenHelperWithCache(c *conf.JWT, cache TokenCache) *TokenHelper {
-	return &TokenHelper{
-		secret:     []byte(c.Secret),
-		issuer:     c.Issuer,
-		expire:     c.Expire,
-		tokenCache: cache,
+	h := NewTokenHelper(c)
+	h.tokenCache = cache
+	return h
+}
+
+// secondsOr 把秒數轉換為 time.Duration，未配置時使用默認值
+func secondsOr(seconds int64, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
 	}
+	return time.Duration(seconds) * time.Second
 }
 
 // SetTokenCache 設置 token cache（用於依賴注入後設置）
@@ -59,6 +90,21 @@ func (h *TokenHelper) SetTokenCache(cache TokenCache) {
 	h.tokenCache = cache
 }
 
+// SetSessionValidator 設置登入會話檢查，設置後帶會話 ID 的令牌在會話撤銷後立即失效
+func (h *TokenHelper) SetSessionValidator(sessions SessionValidator) {
+	h.sessions = sessions
+}
+
+// AccessTokenTTL 會話訪問令牌有效期
+func (h *TokenHelper) AccessTokenTTL() time.Duration {
+	return h.accessExpire
+}
+
+// RefreshTokenTTL 刷新令牌閒置有效期
+func (h *TokenHelper) RefreshTokenTTL() time.Duration {
+	return h.refreshExpire
+}
+
 // GenerateToken 生成一個新的 JWT
 // 已過時：請使用 GenerateTokenWithClaims
 func (h *TokenHelper) GenerateToken(userID uint) (string, error) {
@@ -133,24 +179,109 @@ func (h *TokenHelper) GenerateGuestToken(nickname string) (string, error) {
 	return tokenString, nil
 }
 
-// ParseToken 解析並驗證一個 JWT
-// 如果啟用了 Redis cache，會額外檢查 token 是否在 Redis 中存在（是否已被撤銷）
-func (h *TokenHelper) ParseToken(tokenString string) (*CustomClaims, error) {
-	// 1. 如果啟用了 token cache，先檢查 Redis
+// GenerateSessionToken 生成綁定登入會話的短期訪問令牌
+// 有效性由會話決定，不寫入 token cache；遊客的 userID 為 0，nickname 為遊客昵稱
+func (h *TokenHelper) GenerateSessionToken(userID int64, isGuest bool, nickname, sessionID string) (string, error) {
+	claims := CustomClaims{
+		UserID:    userID,
+		IsGuest:   isGuest,
+		Nickname:  nickname,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    h.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(h.accessExpire)),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(h.secret)
+}
+
+// SpectateSubject 客服觀戰令牌的主體，不會與 users.id 衝突
+func SpectateSubject(adminID int64) string {
+	return "admin:" + strconv.FormatInt(adminID, 10)
+}
+
+// GenerateSpectateToken 生成客服觀戰專用的短期 JWT，只能用於觀戰指定房間
+// UserID 為 0，簽發的管理員記在主體（sub）中，令牌不代表任何玩家帳號
+func (h *TokenHelper) GenerateSpectateToken(adminID int64, roomID string, ttl time.Duration) (string, error) {
+	claims := CustomClaims{
+		SpectateRoom: roomID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   SpectateSubject(adminID),
+			Issuer:    h.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(h.secret)
+	if err != nil {
+		return "", err
+	}
+
 	if h.tokenCache != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		valid, err := h.tokenCache.ValidateToken(ctx, tokenString)
-		if err != nil {
-			// Redis 查詢失敗，繼續使用 JWT 驗證（容錯處理）
-		} else if !valid {
-			// Token 不在 Redis 中或已過期（已被撤銷或超過 10 分鐘）
-			return nil, jwt.ErrTokenExpired
+		if err := h.tokenCache.StoreToken(ctx, tokenString, 0); err != nil {
+			// Redis 存儲失敗不影響 token 生成
 		}
 	}
 
-	// 2. 解析並驗證 JWT
+	return tokenString, nil
+}
+
+// GenerateAdminToken 生成管理員令牌，只能用於管理後台接口
+func (h *TokenHelper) GenerateAdminToken(adminID int64, ttl time.Duration) (string, error) {
+	return h.generateAdminToken(adminID, ttl, 0)
+}
+
+// GenerateAdminStepUpToken 生成記錄了兩步驗證時間的管理員令牌，敏感操作據此判斷是否需要重新驗證
+func (h *TokenHelper) GenerateAdminStepUpToken(adminID int64, ttl time.Duration, stepUpAt time.Time) (string, error) {
+	return h.generateAdminToken(adminID, ttl, stepUpAt.Unix())
+}
+
+// generateAdminToken 生成管理員令牌，stepUpAt 為 0 表示未通過兩步驗證
+func (h *TokenHelper) generateAdminToken(adminID int64, ttl time.Duration, stepUpAt int64) (string, error) {
+	claims := CustomClaims{
+		UserID:   adminID,
+		Admin:    true,
+		StepUpAt: stepUpAt,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    h.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(h.secret)
+	if err != nil {
+		return "", err
+	}
+
+	if h.tokenCache != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := h.tokenCache.StoreToken(ctx, tokenString, adminID); err != nil {
+			// Redis 存儲失敗不影響 token 生成
+		}
+	}
+
+	return tokenString, nil
+}
+
+// ParseToken 解析並驗證一個 JWT
+// 帶會話 ID 的令牌檢查會話是否仍然有效；其他令牌在啟用了 Redis cache 時檢查 token 是否在 Redis 中存在（是否已被撤銷）
+func (h *TokenHelper) ParseToken(tokenString string) (*CustomClaims, error) {
+	// 1. 解析並驗證 JWT
 	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return h.secret, nil
 	})
@@ -159,11 +290,40 @@ func (h *TokenHelper) ParseToken(tokenString string) (*CustomClaims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
+	claims, ok := token.Claims.(*CustomClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrInvalidKey
+	}
+
+	// 2. 會話令牌：檢查會話是否已登出或撤銷，Redis 查詢失敗時繼續使用 JWT 驗證（容錯處理）
+	if claims.SessionID != "" {
+		if h.sessions != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			active, err := h.sessions.SessionActive(ctx, claims.SessionID)
+			if err == nil && !active {
+				return nil, ErrSessionRevoked
+			}
+		}
 		return claims, nil
 	}
 
-	return nil, jwt.ErrInvalidKey
+	// 3. 如果啟用了 token cache，檢查 Redis
+	if h.tokenCache != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		valid, err := h.tokenCache.ValidateToken(ctx, tokenString)
+		if err != nil {
+			// Redis 查詢失敗，繼續使用 JWT 驗證（容錯處理）
+		} else if !valid {
+			// Token 不在 Redis 中或已過期（已被撤銷或超過 10 分鐘）
+			return nil, jwt.ErrTokenExpired
+		}
+	}
+
+	return claims, nil
 }
 
 // RevokeToken 撤銷 token（從 Redis 中刪除）
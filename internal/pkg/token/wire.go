@@ -3,6 +3,7 @@ package token
 
 import (
 	"github.com/b7777777v/fish_server/internal/biz/account"
+	"github.com/b7777777v/fish_server/internal/biz/admin"
 	"github.com/b7777777v/fish_server/internal/conf"
 	"github.com/google/wire"
 )
@@ -11,10 +12,13 @@ import (
 var ProviderSet = wire.NewSet(
 	ProvideTokenHelper,
 	wire.Bind(new(account.TokenService), new(*TokenHelper)),
+	wire.Bind(new(admin.TokenIssuer), new(*TokenHelper)),
 )
 
-// ProvideTokenHelper 提供一個帶 Redis cache 的 TokenHelper
+// ProvideTokenHelper 提供一個帶 Redis cache 和登入會話檢查的 TokenHelper
 // 如果 TokenCache 可用，會自動注入
-func ProvideTokenHelper(c *conf.JWT, cache TokenCache) *TokenHelper {
-	return NewTokenHelperWithCache(c, cache)
+func ProvideTokenHelper(c *conf.JWT, cache TokenCache, sessions SessionValidator) *TokenHelper {
+	helper := NewTokenHelperWithCache(c, cache)
+	helper.SetSessionValidator(sessions)
+	return helper
 }
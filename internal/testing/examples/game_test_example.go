@@ -63,17 +63,16 @@ func ExampleMockExpectationVerification(t *testing.T) {
 	env := testhelper.NewGameTestEnv(t, nil)
 
 	playerID := int64(1)
-	newBalance := int64(90000)
 
-	// Setup expectation: UpdatePlayerBalance should be called exactly once
-	env.PlayerRepo.On("UpdatePlayerBalance", env.Ctx, playerID, newBalance).
+	// Setup expectation: UpdatePlayerStatus should be called exactly once
+	env.PlayerRepo.On("UpdatePlayerStatus", env.Ctx, playerID, game.PlayerStatusPlaying).
 		Return(nil).Once()
 
 	// Act
-	err := env.PlayerRepo.UpdatePlayerBalance(env.Ctx, playerID, newBalance)
+	err := env.PlayerRepo.UpdatePlayerStatus(env.Ctx, playerID, game.PlayerStatusPlaying)
 	assert.NoError(t, err)
 
-	// Verify: This will fail if UpdatePlayerBalance wasn't called exactly once
+	// Verify: This will fail if UpdatePlayerStatus wasn't called exactly once
 	env.AssertExpectations(t)
 }
 
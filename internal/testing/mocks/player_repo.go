@@ -21,12 +21,6 @@ func (m *PlayerRepo) GetPlayer(ctx context.Context, playerID int64) (*game.Playe
 	return args.Get(0).(*game.Player), args.Error(1)
 }
 
-// UpdatePlayerBalance mocks the UpdatePlayerBalance method
-func (m *PlayerRepo) UpdatePlayerBalance(ctx context.Context, playerID int64, balance int64) error {
-	args := m.Called(ctx, playerID, balance)
-	return args.Error(0)
-}
-
 // UpdatePlayerStatus mocks the UpdatePlayerStatus method
 func (m *PlayerRepo) UpdatePlayerStatus(ctx context.Context, playerID int64, status game.PlayerStatus) error {
 	args := m.Called(ctx, playerID, status)
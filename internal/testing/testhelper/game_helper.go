@@ -186,7 +186,7 @@ func setupDefaultMocks(gameRepo *mocks.GameRepo, playerRepo *mocks.PlayerRepo, w
 
 	// PlayerRepo defaults
 	// Note: PlayerRepo methods are NOT mocked by default
-	// Tests should explicitly set expectations for GetPlayer, UpdatePlayerBalance, UpdatePlayerStatus
+	// Tests should explicitly set expectations for GetPlayer, UpdatePlayerStatus
 	// This avoids conflicts with explicit mock expectations and function return issues
 
 	// WalletRepo defaults
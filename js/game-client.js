@@ -229,26 +229,9 @@ document.addEventListener('DOMContentLoaded', () => {
         setupWebSocketHandlers();
     }
 
-    // --- 傳統連接方式（使用 player_id）---
+    // --- 傳統連接方式（使用 player_id）已移除，伺服器只接受令牌連接 ---
     function connect() {
-        if (socket && socket.readyState === WebSocket.OPEN) {
-            log('已經連接。', 'system');
-            return;
-        }
-
-        const playerId = playerIdInput.value;
-        if (!playerId) {
-            log('請輸入玩家ID。', 'error');
-            return;
-        }
-
-        const url = `${WEBSOCKET_URL}?player_id=${encodeURIComponent(playerId)}`;
-        log(`正在連接到 ${url}`, 'system');
-
-        socket = new WebSocket(url);
-        socket.binaryType = "arraybuffer";
-
-        setupWebSocketHandlers();
+        log('不再支持使用玩家ID直接連接，請先登入或使用遊客登入。', 'error');
     }
 
     // --- 設置 WebSocket 事件處理器 ---
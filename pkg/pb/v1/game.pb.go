@@ -61,6 +61,28 @@ const (
 	MessageType_ROOM_STATE_UPDATE MessageType = 28
 	MessageType_FORMATION_SPAWNED MessageType = 29
 	MessageType_FORMATION_UPDATED MessageType = 30
+	MessageType_SERVER_DRAINING   MessageType = 31 // 節點即將下線，客戶端應重連到其他節點
+	// 房間匹配 (40-49)
+	MessageType_QUICK_JOIN          MessageType = 40 // 由伺服器選擇或創建房間並加入，以 JOIN_ROOM_RESPONSE 回覆
+	MessageType_WATCH_ROOM          MessageType = 41 // 以觀戰者身份進入房間，只接收房間狀態，不佔座位
+	MessageType_WATCH_ROOM_RESPONSE MessageType = 42
+	MessageType_CREATE_PRIVATE_ROOM MessageType = 43 // 創建私人房間並以房主身份加入，以 JOIN_ROOM_RESPONSE 回覆
+	MessageType_KICK_PLAYER         MessageType = 44 // 房主踢出玩家
+	MessageType_LOCK_ROOM           MessageType = 45 // 房主鎖定或解鎖房間
+	MessageType_PRIVATE_ROOM_UPDATE MessageType = 46 // 私人房間狀態變更（鎖定、踢人），廣播給房間內所有人
+	// 座位 (50-59)
+	MessageType_SWAP_SEAT         MessageType = 50 // 請求與其他玩家交換座位，對方以 RESPOND_SEAT_SWAP 接受或拒絕
+	MessageType_RESPOND_SEAT_SWAP MessageType = 51 // 回應換座請求
+	MessageType_SEAT_EVENT        MessageType = 52 // 座位變化（入座、換座、保留、轉為觀戰等），廣播給房間內所有人
+	// 房間活動 (60-69)
+	MessageType_ROOM_EVENT MessageType = 60 // 定時活動（雙倍獎勵、Boss 狂潮、魚潮風暴）開始或結束，廣播給受影響的房間
+	// 聊天 (70-79)
+	MessageType_CHAT         MessageType = 70 // 房間內發言，被禁言時返回 CHAT_MUTED
+	MessageType_CHAT_MESSAGE MessageType = 71 // 房間聊天消息，廣播給房間內所有人
+	// 握手 (80-89)
+	MessageType_HELLO MessageType = 80 // 客戶端聲明協議版本和能力，伺服器以 WELCOME 回覆
+	// 傳輸層 (90-98)
+	MessageType_MESSAGE_BATCH MessageType = 90 // 同一個房間 tick 內的多條消息合併為一幀
 	// 錯誤消息 (99)
 	MessageType_ERROR MessageType = 99
 )
@@ -98,6 +120,22 @@ var (
 		28: "ROOM_STATE_UPDATE",
 		29: "FORMATION_SPAWNED",
 		30: "FORMATION_UPDATED",
+		31: "SERVER_DRAINING",
+		40: "QUICK_JOIN",
+		41: "WATCH_ROOM",
+		42: "WATCH_ROOM_RESPONSE",
+		43: "CREATE_PRIVATE_ROOM",
+		44: "KICK_PLAYER",
+		45: "LOCK_ROOM",
+		46: "PRIVATE_ROOM_UPDATE",
+		50: "SWAP_SEAT",
+		51: "RESPOND_SEAT_SWAP",
+		52: "SEAT_EVENT",
+		60: "ROOM_EVENT",
+		70: "CHAT",
+		71: "CHAT_MESSAGE",
+		80: "HELLO",
+		90: "MESSAGE_BATCH",
 		99: "ERROR",
 	}
 	MessageType_value = map[string]int32{
@@ -131,6 +169,22 @@ var (
 		"ROOM_STATE_UPDATE":      28,
 		"FORMATION_SPAWNED":      29,
 		"FORMATION_UPDATED":      30,
+		"SERVER_DRAINING":        31,
+		"QUICK_JOIN":             40,
+		"WATCH_ROOM":             41,
+		"WATCH_ROOM_RESPONSE":    42,
+		"CREATE_PRIVATE_ROOM":    43,
+		"KICK_PLAYER":            44,
+		"LOCK_ROOM":              45,
+		"PRIVATE_ROOM_UPDATE":    46,
+		"SWAP_SEAT":              50,
+		"RESPOND_SEAT_SWAP":      51,
+		"SEAT_EVENT":             52,
+		"ROOM_EVENT":             60,
+		"CHAT":                   70,
+		"CHAT_MESSAGE":           71,
+		"HELLO":                  80,
+		"MESSAGE_BATCH":          90,
 		"ERROR":                  99,
 	}
 )
@@ -162,6 +216,176 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return file_proto_v1_game_proto_rawDescGZIP(), []int{0}
 }
 
+// 錯誤碼
+// 枚舉名即 ErrorMessage.code 字符串，舊版客戶端可繼續按 code 判斷
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED ErrorCode = 0
+	// 通用 (1-99)
+	ErrorCode_GENERAL_ERROR       ErrorCode = 1
+	ErrorCode_INTERNAL_ERROR      ErrorCode = 2
+	ErrorCode_INVALID_MESSAGE     ErrorCode = 3 // 消息格式錯誤、空消息或超長
+	ErrorCode_INVALID_ARGUMENT    ErrorCode = 4 // 消息字段不合法
+	ErrorCode_UNSUPPORTED_MESSAGE ErrorCode = 5 // 不支持的消息類型或幀類型
+	ErrorCode_REQUEST_TIMEOUT     ErrorCode = 6
+	// 連接 (100-199)
+	ErrorCode_PROTOCOL_VERSION_UNSUPPORTED ErrorCode = 100
+	ErrorCode_RATE_LIMITED                 ErrorCode = 101
+	ErrorCode_TEMPORARILY_BANNED           ErrorCode = 102
+	ErrorCode_NODE_DRAINING                ErrorCode = 103 // 節點正在下線，應連接其他節點
+	ErrorCode_ACCOUNT_BANNED               ErrorCode = 104 // 帳號已被管理員封禁
+	// 房間與座位 (200-299)
+	ErrorCode_ROOM_NOT_FOUND         ErrorCode = 200
+	ErrorCode_ROOM_FULL              ErrorCode = 201
+	ErrorCode_SEAT_TAKEN             ErrorCode = 202
+	ErrorCode_INVALID_SEAT           ErrorCode = 203
+	ErrorCode_NOT_IN_ROOM            ErrorCode = 204
+	ErrorCode_ALREADY_IN_ROOM        ErrorCode = 205
+	ErrorCode_SEAT_REQUIRED          ErrorCode = 206 // 需要先選擇座位
+	ErrorCode_NO_ROOM_AVAILABLE      ErrorCode = 207 // 快速加入沒有可用房間且已達房間數上限
+	ErrorCode_SPECTATORS_FULL        ErrorCode = 208 // 房間觀戰人數已達上限
+	ErrorCode_ROOM_LOCKED            ErrorCode = 209 // 私人房間已被房主鎖定
+	ErrorCode_INVALID_INVITE_CODE    ErrorCode = 210 // 邀請碼無效，或未提供邀請碼加入私人房間
+	ErrorCode_WRONG_ROOM_PASSWORD    ErrorCode = 211
+	ErrorCode_KICKED_FROM_ROOM       ErrorCode = 212 // 已被房主踢出，不能再次加入
+	ErrorCode_NOT_ROOM_OWNER         ErrorCode = 213 // 只有房主可以執行此操作
+	ErrorCode_PRIVATE_ROOM_LIMIT     ErrorCode = 214 // 擁有的私人房間數已達上限
+	ErrorCode_SEAT_RESERVED          ErrorCode = 215 // 座位保留給斷線重連的玩家
+	ErrorCode_SWAP_REQUEST_NOT_FOUND ErrorCode = 216 // 換座請求不存在或已過期
+	ErrorCode_ROOM_TYPE_RESTRICTED   ErrorCode = 217 // 玩家被限制進入該類型的房間
+	// 遊戲操作 (300-399)
+	ErrorCode_INVALID_CANNON             ErrorCode = 300
+	ErrorCode_INVALID_BULLET_POWER       ErrorCode = 301
+	ErrorCode_BULLET_NOT_FOUND           ErrorCode = 302
+	ErrorCode_FISH_NOT_FOUND             ErrorCode = 303
+	ErrorCode_PLAYER_NOT_FOUND           ErrorCode = 304
+	ErrorCode_SPECTATOR_ACTION_FORBIDDEN ErrorCode = 305 // 觀戰者不能開火、切換砲台或入座
+	ErrorCode_CHAT_MUTED                 ErrorCode = 306 // 玩家已被禁言
+	// 錢包 (400-499)
+	ErrorCode_INSUFFICIENT_BALANCE ErrorCode = 400
+	ErrorCode_WALLET_UNAVAILABLE   ErrorCode = 401 // 錢包服務暫時不可用，可重試
+	ErrorCode_WALLET_FROZEN        ErrorCode = 402
+	ErrorCode_WALLET_NOT_FOUND     ErrorCode = 403
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0:   "ERROR_CODE_UNSPECIFIED",
+		1:   "GENERAL_ERROR",
+		2:   "INTERNAL_ERROR",
+		3:   "INVALID_MESSAGE",
+		4:   "INVALID_ARGUMENT",
+		5:   "UNSUPPORTED_MESSAGE",
+		6:   "REQUEST_TIMEOUT",
+		100: "PROTOCOL_VERSION_UNSUPPORTED",
+		101: "RATE_LIMITED",
+		102: "TEMPORARILY_BANNED",
+		103: "NODE_DRAINING",
+		104: "ACCOUNT_BANNED",
+		200: "ROOM_NOT_FOUND",
+		201: "ROOM_FULL",
+		202: "SEAT_TAKEN",
+		203: "INVALID_SEAT",
+		204: "NOT_IN_ROOM",
+		205: "ALREADY_IN_ROOM",
+		206: "SEAT_REQUIRED",
+		207: "NO_ROOM_AVAILABLE",
+		208: "SPECTATORS_FULL",
+		209: "ROOM_LOCKED",
+		210: "INVALID_INVITE_CODE",
+		211: "WRONG_ROOM_PASSWORD",
+		212: "KICKED_FROM_ROOM",
+		213: "NOT_ROOM_OWNER",
+		214: "PRIVATE_ROOM_LIMIT",
+		215: "SEAT_RESERVED",
+		216: "SWAP_REQUEST_NOT_FOUND",
+		217: "ROOM_TYPE_RESTRICTED",
+		300: "INVALID_CANNON",
+		301: "INVALID_BULLET_POWER",
+		302: "BULLET_NOT_FOUND",
+		303: "FISH_NOT_FOUND",
+		304: "PLAYER_NOT_FOUND",
+		305: "SPECTATOR_ACTION_FORBIDDEN",
+		306: "CHAT_MUTED",
+		400: "INSUFFICIENT_BALANCE",
+		401: "WALLET_UNAVAILABLE",
+		402: "WALLET_FROZEN",
+		403: "WALLET_NOT_FOUND",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED":       0,
+		"GENERAL_ERROR":                1,
+		"INTERNAL_ERROR":               2,
+		"INVALID_MESSAGE":              3,
+		"INVALID_ARGUMENT":             4,
+		"UNSUPPORTED_MESSAGE":          5,
+		"REQUEST_TIMEOUT":              6,
+		"PROTOCOL_VERSION_UNSUPPORTED": 100,
+		"RATE_LIMITED":                 101,
+		"TEMPORARILY_BANNED":           102,
+		"NODE_DRAINING":                103,
+		"ACCOUNT_BANNED":               104,
+		"ROOM_NOT_FOUND":               200,
+		"ROOM_FULL":                    201,
+		"SEAT_TAKEN":                   202,
+		"INVALID_SEAT":                 203,
+		"NOT_IN_ROOM":                  204,
+		"ALREADY_IN_ROOM":              205,
+		"SEAT_REQUIRED":                206,
+		"NO_ROOM_AVAILABLE":            207,
+		"SPECTATORS_FULL":              208,
+		"ROOM_LOCKED":                  209,
+		"INVALID_INVITE_CODE":          210,
+		"WRONG_ROOM_PASSWORD":          211,
+		"KICKED_FROM_ROOM":             212,
+		"NOT_ROOM_OWNER":               213,
+		"PRIVATE_ROOM_LIMIT":           214,
+		"SEAT_RESERVED":                215,
+		"SWAP_REQUEST_NOT_FOUND":       216,
+		"ROOM_TYPE_RESTRICTED":         217,
+		"INVALID_CANNON":               300,
+		"INVALID_BULLET_POWER":         301,
+		"BULLET_NOT_FOUND":             302,
+		"FISH_NOT_FOUND":               303,
+		"PLAYER_NOT_FOUND":             304,
+		"SPECTATOR_ACTION_FORBIDDEN":   305,
+		"CHAT_MUTED":                   306,
+		"INSUFFICIENT_BALANCE":         400,
+		"WALLET_UNAVAILABLE":           401,
+		"WALLET_FROZEN":                402,
+		"WALLET_NOT_FOUND":             403,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v1_game_proto_enumTypes[1].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_proto_v1_game_proto_enumTypes[1]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{1}
+}
+
 // 位置信息
 type Position struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -250,6 +474,22 @@ type GameMessage struct {
 	//	*GameMessage_RoomStateUpdate
 	//	*GameMessage_FormationSpawned
 	//	*GameMessage_FormationUpdated
+	//	*GameMessage_ServerDraining
+	//	*GameMessage_QuickJoin
+	//	*GameMessage_WatchRoom
+	//	*GameMessage_WatchRoomResponse
+	//	*GameMessage_CreatePrivateRoom
+	//	*GameMessage_KickPlayer
+	//	*GameMessage_LockRoom
+	//	*GameMessage_PrivateRoomUpdate
+	//	*GameMessage_SwapSeat
+	//	*GameMessage_RespondSeatSwap
+	//	*GameMessage_SeatEvent
+	//	*GameMessage_RoomEvent
+	//	*GameMessage_Chat
+	//	*GameMessage_ChatMessage
+	//	*GameMessage_Hello
+	//	*GameMessage_Batch
 	//	*GameMessage_Error
 	Data          isGameMessage_Data `protobuf_oneof:"data"`
 	unknownFields protoimpl.UnknownFields
@@ -561,6 +801,150 @@ func (x *GameMessage) GetFormationUpdated() *FormationUpdatedEvent {
 	return nil
 }
 
+func (x *GameMessage) GetServerDraining() *ServerDrainingEvent {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_ServerDraining); ok {
+			return x.ServerDraining
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetQuickJoin() *QuickJoinRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_QuickJoin); ok {
+			return x.QuickJoin
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetWatchRoom() *WatchRoomRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_WatchRoom); ok {
+			return x.WatchRoom
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetWatchRoomResponse() *WatchRoomResponse {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_WatchRoomResponse); ok {
+			return x.WatchRoomResponse
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetCreatePrivateRoom() *CreatePrivateRoomRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_CreatePrivateRoom); ok {
+			return x.CreatePrivateRoom
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetKickPlayer() *KickPlayerRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_KickPlayer); ok {
+			return x.KickPlayer
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetLockRoom() *LockRoomRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_LockRoom); ok {
+			return x.LockRoom
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetPrivateRoomUpdate() *PrivateRoomUpdate {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_PrivateRoomUpdate); ok {
+			return x.PrivateRoomUpdate
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetSwapSeat() *SwapSeatRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_SwapSeat); ok {
+			return x.SwapSeat
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetRespondSeatSwap() *RespondSeatSwapRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_RespondSeatSwap); ok {
+			return x.RespondSeatSwap
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetSeatEvent() *SeatEvent {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_SeatEvent); ok {
+			return x.SeatEvent
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetRoomEvent() *RoomEventNotification {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_RoomEvent); ok {
+			return x.RoomEvent
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetChat() *ChatRequest {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_Chat); ok {
+			return x.Chat
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetChatMessage() *ChatMessage {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_ChatMessage); ok {
+			return x.ChatMessage
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetHello() *HelloMessage {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_Hello); ok {
+			return x.Hello
+		}
+	}
+	return nil
+}
+
+func (x *GameMessage) GetBatch() *MessageBatch {
+	if x != nil {
+		if x, ok := x.Data.(*GameMessage_Batch); ok {
+			return x.Batch
+		}
+	}
+	return nil
+}
+
 func (x *GameMessage) GetError() *ErrorMessage {
 	if x != nil {
 		if x, ok := x.Data.(*GameMessage_Error); ok {
@@ -693,6 +1077,75 @@ type GameMessage_FormationUpdated struct {
 	FormationUpdated *FormationUpdatedEvent `protobuf:"bytes,32,opt,name=formation_updated,json=formationUpdated,proto3,oneof"`
 }
 
+type GameMessage_ServerDraining struct {
+	ServerDraining *ServerDrainingEvent `protobuf:"bytes,33,opt,name=server_draining,json=serverDraining,proto3,oneof"`
+}
+
+type GameMessage_QuickJoin struct {
+	// 房間匹配
+	QuickJoin *QuickJoinRequest `protobuf:"bytes,40,opt,name=quick_join,json=quickJoin,proto3,oneof"`
+}
+
+type GameMessage_WatchRoom struct {
+	WatchRoom *WatchRoomRequest `protobuf:"bytes,41,opt,name=watch_room,json=watchRoom,proto3,oneof"`
+}
+
+type GameMessage_WatchRoomResponse struct {
+	WatchRoomResponse *WatchRoomResponse `protobuf:"bytes,42,opt,name=watch_room_response,json=watchRoomResponse,proto3,oneof"`
+}
+
+type GameMessage_CreatePrivateRoom struct {
+	CreatePrivateRoom *CreatePrivateRoomRequest `protobuf:"bytes,43,opt,name=create_private_room,json=createPrivateRoom,proto3,oneof"`
+}
+
+type GameMessage_KickPlayer struct {
+	KickPlayer *KickPlayerRequest `protobuf:"bytes,44,opt,name=kick_player,json=kickPlayer,proto3,oneof"`
+}
+
+type GameMessage_LockRoom struct {
+	LockRoom *LockRoomRequest `protobuf:"bytes,45,opt,name=lock_room,json=lockRoom,proto3,oneof"`
+}
+
+type GameMessage_PrivateRoomUpdate struct {
+	PrivateRoomUpdate *PrivateRoomUpdate `protobuf:"bytes,46,opt,name=private_room_update,json=privateRoomUpdate,proto3,oneof"`
+}
+
+type GameMessage_SwapSeat struct {
+	// 座位
+	SwapSeat *SwapSeatRequest `protobuf:"bytes,50,opt,name=swap_seat,json=swapSeat,proto3,oneof"`
+}
+
+type GameMessage_RespondSeatSwap struct {
+	RespondSeatSwap *RespondSeatSwapRequest `protobuf:"bytes,51,opt,name=respond_seat_swap,json=respondSeatSwap,proto3,oneof"`
+}
+
+type GameMessage_SeatEvent struct {
+	SeatEvent *SeatEvent `protobuf:"bytes,52,opt,name=seat_event,json=seatEvent,proto3,oneof"`
+}
+
+type GameMessage_RoomEvent struct {
+	// 房間活動
+	RoomEvent *RoomEventNotification `protobuf:"bytes,60,opt,name=room_event,json=roomEvent,proto3,oneof"`
+}
+
+type GameMessage_Chat struct {
+	Chat *ChatRequest `protobuf:"bytes,70,opt,name=chat,proto3,oneof"`
+}
+
+type GameMessage_ChatMessage struct {
+	ChatMessage *ChatMessage `protobuf:"bytes,71,opt,name=chat_message,json=chatMessage,proto3,oneof"`
+}
+
+type GameMessage_Hello struct {
+	// 握手
+	Hello *HelloMessage `protobuf:"bytes,80,opt,name=hello,proto3,oneof"`
+}
+
+type GameMessage_Batch struct {
+	// 傳輸層
+	Batch *MessageBatch `protobuf:"bytes,90,opt,name=batch,proto3,oneof"`
+}
+
 type GameMessage_Error struct {
 	// 錯誤消息
 	Error *ErrorMessage `protobuf:"bytes,99,opt,name=error,proto3,oneof"`
@@ -756,6 +1209,38 @@ func (*GameMessage_FormationSpawned) isGameMessage_Data() {}
 
 func (*GameMessage_FormationUpdated) isGameMessage_Data() {}
 
+func (*GameMessage_ServerDraining) isGameMessage_Data() {}
+
+func (*GameMessage_QuickJoin) isGameMessage_Data() {}
+
+func (*GameMessage_WatchRoom) isGameMessage_Data() {}
+
+func (*GameMessage_WatchRoomResponse) isGameMessage_Data() {}
+
+func (*GameMessage_CreatePrivateRoom) isGameMessage_Data() {}
+
+func (*GameMessage_KickPlayer) isGameMessage_Data() {}
+
+func (*GameMessage_LockRoom) isGameMessage_Data() {}
+
+func (*GameMessage_PrivateRoomUpdate) isGameMessage_Data() {}
+
+func (*GameMessage_SwapSeat) isGameMessage_Data() {}
+
+func (*GameMessage_RespondSeatSwap) isGameMessage_Data() {}
+
+func (*GameMessage_SeatEvent) isGameMessage_Data() {}
+
+func (*GameMessage_RoomEvent) isGameMessage_Data() {}
+
+func (*GameMessage_Chat) isGameMessage_Data() {}
+
+func (*GameMessage_ChatMessage) isGameMessage_Data() {}
+
+func (*GameMessage_Hello) isGameMessage_Data() {}
+
+func (*GameMessage_Batch) isGameMessage_Data() {}
+
 func (*GameMessage_Error) isGameMessage_Data() {}
 
 // 開火請求
@@ -884,6 +1369,8 @@ func (x *SwitchCannonRequest) GetLevel() int32 {
 type JoinRoomRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RoomId        string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	InviteCode    string                 `protobuf:"bytes,2,opt,name=invite_code,json=inviteCode,proto3" json:"invite_code,omitempty"` // 加入私人房間的邀請碼，提供時可以不填 room_id
+	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`                       // 私人房間密碼
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -925,27 +1412,44 @@ func (x *JoinRoomRequest) GetRoomId() string {
 	return ""
 }
 
-// 離開房間請求
-type LeaveRoomRequest struct {
+func (x *JoinRoomRequest) GetInviteCode() string {
+	if x != nil {
+		return x.InviteCode
+	}
+	return ""
+}
+
+func (x *JoinRoomRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// 快速加入請求：由伺服器按房間類型、餘額、填充率和好友選擇房間
+type QuickJoinRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomType      string                 `protobuf:"bytes,1,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"`                  // 房間類型，為空時按餘額選擇可負擔的最高級房間
+	FriendIds     []int64                `protobuf:"varint,2,rep,packed,name=friend_ids,json=friendIds,proto3" json:"friend_ids,omitempty"`       // 優先加入有這些玩家的房間
+	PreferredFill float64                `protobuf:"fixed64,3,opt,name=preferred_fill,json=preferredFill,proto3" json:"preferred_fill,omitempty"` // 偏好的房間填充率（0-1），0 表示使用伺服器默認值
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LeaveRoomRequest) Reset() {
-	*x = LeaveRoomRequest{}
+func (x *QuickJoinRequest) Reset() {
+	*x = QuickJoinRequest{}
 	mi := &file_proto_v1_game_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LeaveRoomRequest) String() string {
+func (x *QuickJoinRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LeaveRoomRequest) ProtoMessage() {}
+func (*QuickJoinRequest) ProtoMessage() {}
 
-func (x *LeaveRoomRequest) ProtoReflect() protoreflect.Message {
+func (x *QuickJoinRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_game_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -957,33 +1461,54 @@ func (x *LeaveRoomRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LeaveRoomRequest.ProtoReflect.Descriptor instead.
-func (*LeaveRoomRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use QuickJoinRequest.ProtoReflect.Descriptor instead.
+func (*QuickJoinRequest) Descriptor() ([]byte, []int) {
 	return file_proto_v1_game_proto_rawDescGZIP(), []int{5}
 }
 
-// 心跳消息
-type HeartbeatMessage struct {
+func (x *QuickJoinRequest) GetRoomType() string {
+	if x != nil {
+		return x.RoomType
+	}
+	return ""
+}
+
+func (x *QuickJoinRequest) GetFriendIds() []int64 {
+	if x != nil {
+		return x.FriendIds
+	}
+	return nil
+}
+
+func (x *QuickJoinRequest) GetPreferredFill() float64 {
+	if x != nil {
+		return x.PreferredFill
+	}
+	return 0
+}
+
+// 觀戰請求，離開觀戰使用 LEAVE_ROOM
+type WatchRoomRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	RoomId        string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatMessage) Reset() {
-	*x = HeartbeatMessage{}
+func (x *WatchRoomRequest) Reset() {
+	*x = WatchRoomRequest{}
 	mi := &file_proto_v1_game_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatMessage) String() string {
+func (x *WatchRoomRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatMessage) ProtoMessage() {}
+func (*WatchRoomRequest) ProtoMessage() {}
 
-func (x *HeartbeatMessage) ProtoReflect() protoreflect.Message {
+func (x *WatchRoomRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_game_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -995,40 +1520,41 @@ func (x *HeartbeatMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatMessage.ProtoReflect.Descriptor instead.
-func (*HeartbeatMessage) Descriptor() ([]byte, []int) {
+// Deprecated: Use WatchRoomRequest.ProtoReflect.Descriptor instead.
+func (*WatchRoomRequest) Descriptor() ([]byte, []int) {
 	return file_proto_v1_game_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *HeartbeatMessage) GetTimestamp() int64 {
+func (x *WatchRoomRequest) GetRoomId() string {
 	if x != nil {
-		return x.Timestamp
+		return x.RoomId
 	}
-	return 0
+	return ""
 }
 
-// 獲取房間列表請求
-type GetRoomListRequest struct {
+// 創建私人房間請求
+type CreatePrivateRoomRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RoomType      string                 `protobuf:"bytes,1,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"` // 可選的房間類型過濾
+	RoomType      string                 `protobuf:"bytes,1,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"` // 房間類型
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`                 // 可選的房間密碼
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetRoomListRequest) Reset() {
-	*x = GetRoomListRequest{}
+func (x *CreatePrivateRoomRequest) Reset() {
+	*x = CreatePrivateRoomRequest{}
 	mi := &file_proto_v1_game_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetRoomListRequest) String() string {
+func (x *CreatePrivateRoomRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetRoomListRequest) ProtoMessage() {}
+func (*CreatePrivateRoomRequest) ProtoMessage() {}
 
-func (x *GetRoomListRequest) ProtoReflect() protoreflect.Message {
+func (x *CreatePrivateRoomRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_game_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1040,39 +1566,47 @@ func (x *GetRoomListRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetRoomListRequest.ProtoReflect.Descriptor instead.
-func (*GetRoomListRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CreatePrivateRoomRequest.ProtoReflect.Descriptor instead.
+func (*CreatePrivateRoomRequest) Descriptor() ([]byte, []int) {
 	return file_proto_v1_game_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *GetRoomListRequest) GetRoomType() string {
+func (x *CreatePrivateRoomRequest) GetRoomType() string {
 	if x != nil {
 		return x.RoomType
 	}
 	return ""
 }
 
-// 獲取玩家信息請求
-type GetPlayerInfoRequest struct {
+func (x *CreatePrivateRoomRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// 踢出玩家請求（僅房主）
+type KickPlayerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      int64                  `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetPlayerInfoRequest) Reset() {
-	*x = GetPlayerInfoRequest{}
+func (x *KickPlayerRequest) Reset() {
+	*x = KickPlayerRequest{}
 	mi := &file_proto_v1_game_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetPlayerInfoRequest) String() string {
+func (x *KickPlayerRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetPlayerInfoRequest) ProtoMessage() {}
+func (*KickPlayerRequest) ProtoMessage() {}
 
-func (x *GetPlayerInfoRequest) ProtoReflect() protoreflect.Message {
+func (x *KickPlayerRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_game_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1084,33 +1618,40 @@ func (x *GetPlayerInfoRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetPlayerInfoRequest.ProtoReflect.Descriptor instead.
-func (*GetPlayerInfoRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use KickPlayerRequest.ProtoReflect.Descriptor instead.
+func (*KickPlayerRequest) Descriptor() ([]byte, []int) {
 	return file_proto_v1_game_proto_rawDescGZIP(), []int{8}
 }
 
-// 選擇座位請求
-type SelectSeatRequest struct {
+func (x *KickPlayerRequest) GetPlayerId() int64 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+// 鎖定房間請求（僅房主），鎖定後不再接受新玩家
+type LockRoomRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SeatId        int32                  `protobuf:"varint,1,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"` // 座位ID (0-3)
+	Locked        bool                   `protobuf:"varint,1,opt,name=locked,proto3" json:"locked,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SelectSeatRequest) Reset() {
-	*x = SelectSeatRequest{}
+func (x *LockRoomRequest) Reset() {
+	*x = LockRoomRequest{}
 	mi := &file_proto_v1_game_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SelectSeatRequest) String() string {
+func (x *LockRoomRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SelectSeatRequest) ProtoMessage() {}
+func (*LockRoomRequest) ProtoMessage() {}
 
-func (x *SelectSeatRequest) ProtoReflect() protoreflect.Message {
+func (x *LockRoomRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_game_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1122,41 +1663,39 @@ func (x *SelectSeatRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SelectSeatRequest.ProtoReflect.Descriptor instead.
-func (*SelectSeatRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LockRoomRequest.ProtoReflect.Descriptor instead.
+func (*LockRoomRequest) Descriptor() ([]byte, []int) {
 	return file_proto_v1_game_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *SelectSeatRequest) GetSeatId() int32 {
+func (x *LockRoomRequest) GetLocked() bool {
 	if x != nil {
-		return x.SeatId
+		return x.Locked
 	}
-	return 0
+	return false
 }
 
-// 擊中魚類請求
-type HitFishRequest struct {
+// 離開房間請求
+type LeaveRoomRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BulletId      int64                  `protobuf:"varint,1,opt,name=bullet_id,json=bulletId,proto3" json:"bullet_id,omitempty"` // 子彈ID
-	FishId        int64                  `protobuf:"varint,2,opt,name=fish_id,json=fishId,proto3" json:"fish_id,omitempty"`       // 魚ID
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HitFishRequest) Reset() {
-	*x = HitFishRequest{}
+func (x *LeaveRoomRequest) Reset() {
+	*x = LeaveRoomRequest{}
 	mi := &file_proto_v1_game_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HitFishRequest) String() string {
+func (x *LeaveRoomRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HitFishRequest) ProtoMessage() {}
+func (*LeaveRoomRequest) ProtoMessage() {}
 
-func (x *HitFishRequest) ProtoReflect() protoreflect.Message {
+func (x *LeaveRoomRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_game_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1168,52 +1707,206 @@ func (x *HitFishRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HitFishRequest.ProtoReflect.Descriptor instead.
-func (*HitFishRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LeaveRoomRequest.ProtoReflect.Descriptor instead.
+func (*LeaveRoomRequest) Descriptor() ([]byte, []int) {
 	return file_proto_v1_game_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *HitFishRequest) GetBulletId() int64 {
+// 心跳消息
+type HeartbeatMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatMessage) Reset() {
+	*x = HeartbeatMessage{}
+	mi := &file_proto_v1_game_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatMessage) ProtoMessage() {}
+
+func (x *HeartbeatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[11]
 	if x != nil {
-		return x.BulletId
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatMessage.ProtoReflect.Descriptor instead.
+func (*HeartbeatMessage) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HeartbeatMessage) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
 	}
 	return 0
 }
 
-func (x *HitFishRequest) GetFishId() int64 {
+// 獲取房間列表請求
+type GetRoomListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomType      string                 `protobuf:"bytes,1,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"` // 可選的房間類型過濾
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRoomListRequest) Reset() {
+	*x = GetRoomListRequest{}
+	mi := &file_proto_v1_game_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRoomListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoomListRequest) ProtoMessage() {}
+
+func (x *GetRoomListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[12]
 	if x != nil {
-		return x.FishId
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoomListRequest.ProtoReflect.Descriptor instead.
+func (*GetRoomListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetRoomListRequest) GetRoomType() string {
+	if x != nil {
+		return x.RoomType
+	}
+	return ""
+}
+
+// 獲取玩家信息請求
+type GetPlayerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPlayerInfoRequest) Reset() {
+	*x = GetPlayerInfoRequest{}
+	mi := &file_proto_v1_game_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPlayerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPlayerInfoRequest) ProtoMessage() {}
+
+func (x *GetPlayerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPlayerInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetPlayerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{13}
+}
+
+// 選擇座位請求
+type SelectSeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SeatId        int32                  `protobuf:"varint,1,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"` // 座位ID (0-3)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelectSeatRequest) Reset() {
+	*x = SelectSeatRequest{}
+	mi := &file_proto_v1_game_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelectSeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectSeatRequest) ProtoMessage() {}
+
+func (x *SelectSeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelectSeatRequest.ProtoReflect.Descriptor instead.
+func (*SelectSeatRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SelectSeatRequest) GetSeatId() int32 {
+	if x != nil {
+		return x.SeatId
 	}
 	return 0
 }
 
-// 開火響應
-type FireBulletResponse struct {
+// 換座請求
+type SwapSeatRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	BulletId      int64                  `protobuf:"varint,2,opt,name=bullet_id,json=bulletId,proto3" json:"bullet_id,omitempty"`
-	Cost          int64                  `protobuf:"varint,3,opt,name=cost,proto3" json:"cost,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	TargetFishId  int64                  `protobuf:"varint,5,opt,name=target_fish_id,json=targetFishId,proto3" json:"target_fish_id,omitempty"` // 鎖定的目標魚ID
+	SeatId        int32                  `protobuf:"varint,1,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"` // 想要交換的座位，必須有其他在線玩家
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FireBulletResponse) Reset() {
-	*x = FireBulletResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[11]
+func (x *SwapSeatRequest) Reset() {
+	*x = SwapSeatRequest{}
+	mi := &file_proto_v1_game_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FireBulletResponse) String() string {
+func (x *SwapSeatRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FireBulletResponse) ProtoMessage() {}
+func (*SwapSeatRequest) ProtoMessage() {}
 
-func (x *FireBulletResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[11]
+func (x *SwapSeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1224,73 +1917,753 @@ func (x *FireBulletResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FireBulletResponse.ProtoReflect.Descriptor instead.
-func (*FireBulletResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use SwapSeatRequest.ProtoReflect.Descriptor instead.
+func (*SwapSeatRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SwapSeatRequest) GetSeatId() int32 {
+	if x != nil {
+		return x.SeatId
+	}
+	return 0
+}
+
+// 回應換座請求
+type RespondSeatSwapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequesterId   int64                  `protobuf:"varint,1,opt,name=requester_id,json=requesterId,proto3" json:"requester_id,omitempty"` // 發起換座的玩家
+	Accept        bool                   `protobuf:"varint,2,opt,name=accept,proto3" json:"accept,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RespondSeatSwapRequest) Reset() {
+	*x = RespondSeatSwapRequest{}
+	mi := &file_proto_v1_game_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RespondSeatSwapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RespondSeatSwapRequest) ProtoMessage() {}
+
+func (x *RespondSeatSwapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RespondSeatSwapRequest.ProtoReflect.Descriptor instead.
+func (*RespondSeatSwapRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RespondSeatSwapRequest) GetRequesterId() int64 {
+	if x != nil {
+		return x.RequesterId
+	}
+	return 0
+}
+
+func (x *RespondSeatSwapRequest) GetAccept() bool {
+	if x != nil {
+		return x.Accept
+	}
+	return false
+}
+
+// 擊中魚類請求
+type HitFishRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BulletId      int64                  `protobuf:"varint,1,opt,name=bullet_id,json=bulletId,proto3" json:"bullet_id,omitempty"` // 子彈ID
+	FishId        int64                  `protobuf:"varint,2,opt,name=fish_id,json=fishId,proto3" json:"fish_id,omitempty"`       // 魚ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HitFishRequest) Reset() {
+	*x = HitFishRequest{}
+	mi := &file_proto_v1_game_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HitFishRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HitFishRequest) ProtoMessage() {}
+
+func (x *HitFishRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HitFishRequest.ProtoReflect.Descriptor instead.
+func (*HitFishRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *HitFishRequest) GetBulletId() int64 {
+	if x != nil {
+		return x.BulletId
+	}
+	return 0
+}
+
+func (x *HitFishRequest) GetFishId() int64 {
+	if x != nil {
+		return x.FishId
+	}
+	return 0
+}
+
+// 聊天請求
+type ChatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"` // 發言內容，去除首尾空白後不能為空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatRequest) Reset() {
+	*x = ChatRequest{}
+	mi := &file_proto_v1_game_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatRequest) ProtoMessage() {}
+
+func (x *ChatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatRequest.ProtoReflect.Descriptor instead.
+func (*ChatRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ChatRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// 開火響應
+type FireBulletResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	BulletId      int64                  `protobuf:"varint,2,opt,name=bullet_id,json=bulletId,proto3" json:"bullet_id,omitempty"`
+	Cost          int64                  `protobuf:"varint,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	TargetFishId  int64                  `protobuf:"varint,5,opt,name=target_fish_id,json=targetFishId,proto3" json:"target_fish_id,omitempty"` // 鎖定的目標魚ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FireBulletResponse) Reset() {
+	*x = FireBulletResponse{}
+	mi := &file_proto_v1_game_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FireBulletResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FireBulletResponse) ProtoMessage() {}
+
+func (x *FireBulletResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FireBulletResponse.ProtoReflect.Descriptor instead.
+func (*FireBulletResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FireBulletResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *FireBulletResponse) GetBulletId() int64 {
+	if x != nil {
+		return x.BulletId
+	}
+	return 0
+}
+
+func (x *FireBulletResponse) GetCost() int64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *FireBulletResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *FireBulletResponse) GetTargetFishId() int64 {
+	if x != nil {
+		return x.TargetFishId
+	}
+	return 0
+}
+
+// 切換砲台響應
+type SwitchCannonResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	CannonType    int32                  `protobuf:"varint,2,opt,name=cannon_type,json=cannonType,proto3" json:"cannon_type,omitempty"`
+	Level         int32                  `protobuf:"varint,3,opt,name=level,proto3" json:"level,omitempty"`
+	Power         int32                  `protobuf:"varint,4,opt,name=power,proto3" json:"power,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SwitchCannonResponse) Reset() {
+	*x = SwitchCannonResponse{}
+	mi := &file_proto_v1_game_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwitchCannonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwitchCannonResponse) ProtoMessage() {}
+
+func (x *SwitchCannonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwitchCannonResponse.ProtoReflect.Descriptor instead.
+func (*SwitchCannonResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SwitchCannonResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SwitchCannonResponse) GetCannonType() int32 {
+	if x != nil {
+		return x.CannonType
+	}
+	return 0
+}
+
+func (x *SwitchCannonResponse) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *SwitchCannonResponse) GetPower() int32 {
+	if x != nil {
+		return x.Power
+	}
+	return 0
+}
+
+func (x *SwitchCannonResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// 加入房間響應
+type JoinRoomResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	RoomId        string                 `protobuf:"bytes,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	PlayerCount   int32                  `protobuf:"varint,4,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"` // 當前房間人數
+	SeatId        int32                  `protobuf:"varint,5,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"`                // 分配的座位ID（0-based）
+	RoomType      string                 `protobuf:"bytes,6,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"`           // 房間類型（快速加入時返回匹配到的類型）
+	Private       bool                   `protobuf:"varint,7,opt,name=private,proto3" json:"private,omitempty"`                            // 是否為私人房間
+	InviteCode    string                 `protobuf:"bytes,8,opt,name=invite_code,json=inviteCode,proto3" json:"invite_code,omitempty"`     // 私人房間邀請碼
+	OwnerId       int64                  `protobuf:"varint,9,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`             // 私人房間房主
+	Locked        bool                   `protobuf:"varint,10,opt,name=locked,proto3" json:"locked,omitempty"`                             // 私人房間是否已鎖定
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinRoomResponse) Reset() {
+	*x = JoinRoomResponse{}
+	mi := &file_proto_v1_game_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinRoomResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinRoomResponse) ProtoMessage() {}
+
+func (x *JoinRoomResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinRoomResponse.ProtoReflect.Descriptor instead.
+func (*JoinRoomResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *JoinRoomResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *JoinRoomResponse) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *JoinRoomResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *JoinRoomResponse) GetPlayerCount() int32 {
+	if x != nil {
+		return x.PlayerCount
+	}
+	return 0
+}
+
+func (x *JoinRoomResponse) GetSeatId() int32 {
+	if x != nil {
+		return x.SeatId
+	}
+	return 0
+}
+
+func (x *JoinRoomResponse) GetRoomType() string {
+	if x != nil {
+		return x.RoomType
+	}
+	return ""
+}
+
+func (x *JoinRoomResponse) GetPrivate() bool {
+	if x != nil {
+		return x.Private
+	}
+	return false
+}
+
+func (x *JoinRoomResponse) GetInviteCode() string {
+	if x != nil {
+		return x.InviteCode
+	}
+	return ""
+}
+
+func (x *JoinRoomResponse) GetOwnerId() int64 {
+	if x != nil {
+		return x.OwnerId
+	}
+	return 0
+}
+
+func (x *JoinRoomResponse) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+// 私人房間狀態變更
+type PrivateRoomUpdate struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	RoomId         string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	OwnerId        int64                  `protobuf:"varint,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	Locked         bool                   `protobuf:"varint,3,opt,name=locked,proto3" json:"locked,omitempty"`
+	KickedPlayerId int64                  `protobuf:"varint,4,opt,name=kicked_player_id,json=kickedPlayerId,proto3" json:"kicked_player_id,omitempty"` // 被踢出的玩家，0 表示沒有
+	Timestamp      int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PrivateRoomUpdate) Reset() {
+	*x = PrivateRoomUpdate{}
+	mi := &file_proto_v1_game_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrivateRoomUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrivateRoomUpdate) ProtoMessage() {}
+
+func (x *PrivateRoomUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrivateRoomUpdate.ProtoReflect.Descriptor instead.
+func (*PrivateRoomUpdate) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PrivateRoomUpdate) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *PrivateRoomUpdate) GetOwnerId() int64 {
+	if x != nil {
+		return x.OwnerId
+	}
+	return 0
+}
+
+func (x *PrivateRoomUpdate) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+func (x *PrivateRoomUpdate) GetKickedPlayerId() int64 {
+	if x != nil {
+		return x.KickedPlayerId
+	}
+	return 0
+}
+
+func (x *PrivateRoomUpdate) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// 座位事件
+type SeatEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // taken, changed, released, reserved, resumed, swap_requested, swapped, swap_declined, afk
+	PlayerId      int64                  `protobuf:"varint,3,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	SeatId        int32                  `protobuf:"varint,4,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"`
+	OtherPlayerId int64                  `protobuf:"varint,5,opt,name=other_player_id,json=otherPlayerId,proto3" json:"other_player_id,omitempty"` // 換座的另一方，0 表示沒有
+	OtherSeatId   int32                  `protobuf:"varint,6,opt,name=other_seat_id,json=otherSeatId,proto3" json:"other_seat_id,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // 座位保留或換座請求的過期時間（Unix 毫秒），0 表示沒有
+	Reason        string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SeatEvent) Reset() {
+	*x = SeatEvent{}
+	mi := &file_proto_v1_game_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeatEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeatEvent) ProtoMessage() {}
+
+func (x *SeatEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeatEvent.ProtoReflect.Descriptor instead.
+func (*SeatEvent) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SeatEvent) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *SeatEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SeatEvent) GetPlayerId() int64 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *SeatEvent) GetSeatId() int32 {
+	if x != nil {
+		return x.SeatId
+	}
+	return 0
+}
+
+func (x *SeatEvent) GetOtherPlayerId() int64 {
+	if x != nil {
+		return x.OtherPlayerId
+	}
+	return 0
+}
+
+func (x *SeatEvent) GetOtherSeatId() int32 {
+	if x != nil {
+		return x.OtherSeatId
+	}
+	return 0
+}
+
+func (x *SeatEvent) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *SeatEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SeatEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// 房間活動
+type RoomEventInfo struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	EventId          string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"` // 活動場次 ID（排程 ID 和開始時間）
+	ScheduleId       int64                  `protobuf:"varint,2,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	Name             string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Type             string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`                          // double_reward, boss_rush, tide_storm
+	StartsAt         int64                  `protobuf:"varint,5,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"` // Unix 秒
+	EndsAt           int64                  `protobuf:"varint,6,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	RewardMultiplier float64                `protobuf:"fixed64,7,opt,name=reward_multiplier,json=rewardMultiplier,proto3" json:"reward_multiplier,omitempty"` // 雙倍獎勵活動的獎勵倍數
+	Announcement     string                 `protobuf:"bytes,8,opt,name=announcement,proto3" json:"announcement,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RoomEventInfo) Reset() {
+	*x = RoomEventInfo{}
+	mi := &file_proto_v1_game_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomEventInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomEventInfo) ProtoMessage() {}
+
+func (x *RoomEventInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomEventInfo.ProtoReflect.Descriptor instead.
+func (*RoomEventInfo) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RoomEventInfo) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *RoomEventInfo) GetScheduleId() int64 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *RoomEventInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-func (x *FireBulletResponse) GetSuccess() bool {
+func (x *RoomEventInfo) GetType() string {
 	if x != nil {
-		return x.Success
+		return x.Type
 	}
-	return false
+	return ""
 }
 
-func (x *FireBulletResponse) GetBulletId() int64 {
+func (x *RoomEventInfo) GetStartsAt() int64 {
 	if x != nil {
-		return x.BulletId
+		return x.StartsAt
 	}
 	return 0
 }
 
-func (x *FireBulletResponse) GetCost() int64 {
+func (x *RoomEventInfo) GetEndsAt() int64 {
 	if x != nil {
-		return x.Cost
+		return x.EndsAt
 	}
 	return 0
 }
 
-func (x *FireBulletResponse) GetTimestamp() int64 {
+func (x *RoomEventInfo) GetRewardMultiplier() float64 {
 	if x != nil {
-		return x.Timestamp
+		return x.RewardMultiplier
 	}
 	return 0
 }
 
-func (x *FireBulletResponse) GetTargetFishId() int64 {
+func (x *RoomEventInfo) GetAnnouncement() string {
 	if x != nil {
-		return x.TargetFishId
+		return x.Announcement
 	}
-	return 0
+	return ""
 }
 
-// 切換砲台響應
-type SwitchCannonResponse struct {
+// 房間活動開始或結束
+type RoomEventNotification struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	CannonType    int32                  `protobuf:"varint,2,opt,name=cannon_type,json=cannonType,proto3" json:"cannon_type,omitempty"`
-	Level         int32                  `protobuf:"varint,3,opt,name=level,proto3" json:"level,omitempty"`
-	Power         int32                  `protobuf:"varint,4,opt,name=power,proto3" json:"power,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	RoomId        string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Phase         string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"` // started, ended
+	Event         *RoomEventInfo         `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SwitchCannonResponse) Reset() {
-	*x = SwitchCannonResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[12]
+func (x *RoomEventNotification) Reset() {
+	*x = RoomEventNotification{}
+	mi := &file_proto_v1_game_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SwitchCannonResponse) String() string {
+func (x *RoomEventNotification) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SwitchCannonResponse) ProtoMessage() {}
+func (*RoomEventNotification) ProtoMessage() {}
 
-func (x *SwitchCannonResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[12]
+func (x *RoomEventNotification) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1301,73 +2674,66 @@ func (x *SwitchCannonResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SwitchCannonResponse.ProtoReflect.Descriptor instead.
-func (*SwitchCannonResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{12}
-}
-
-func (x *SwitchCannonResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
+// Deprecated: Use RoomEventNotification.ProtoReflect.Descriptor instead.
+func (*RoomEventNotification) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *SwitchCannonResponse) GetCannonType() int32 {
+func (x *RoomEventNotification) GetRoomId() string {
 	if x != nil {
-		return x.CannonType
+		return x.RoomId
 	}
-	return 0
+	return ""
 }
 
-func (x *SwitchCannonResponse) GetLevel() int32 {
+func (x *RoomEventNotification) GetPhase() string {
 	if x != nil {
-		return x.Level
+		return x.Phase
 	}
-	return 0
+	return ""
 }
 
-func (x *SwitchCannonResponse) GetPower() int32 {
+func (x *RoomEventNotification) GetEvent() *RoomEventInfo {
 	if x != nil {
-		return x.Power
+		return x.Event
 	}
-	return 0
+	return nil
 }
 
-func (x *SwitchCannonResponse) GetTimestamp() int64 {
+func (x *RoomEventNotification) GetTimestamp() int64 {
 	if x != nil {
 		return x.Timestamp
 	}
 	return 0
 }
 
-// 加入房間響應
-type JoinRoomResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	RoomId        string                 `protobuf:"bytes,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	PlayerCount   int32                  `protobuf:"varint,4,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"` // 當前房間人數
-	SeatId        int32                  `protobuf:"varint,5,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"`                // 分配的座位ID（0-based）
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// 觀戰響應
+type WatchRoomResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	RoomId         string                 `protobuf:"bytes,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	PlayerCount    int32                  `protobuf:"varint,3,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"`
+	SpectatorCount int32                  `protobuf:"varint,4,opt,name=spectator_count,json=spectatorCount,proto3" json:"spectator_count,omitempty"`
+	Timestamp      int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *JoinRoomResponse) Reset() {
-	*x = JoinRoomResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[13]
+func (x *WatchRoomResponse) Reset() {
+	*x = WatchRoomResponse{}
+	mi := &file_proto_v1_game_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JoinRoomResponse) String() string {
+func (x *WatchRoomResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JoinRoomResponse) ProtoMessage() {}
+func (*WatchRoomResponse) ProtoMessage() {}
 
-func (x *JoinRoomResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[13]
+func (x *WatchRoomResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1378,42 +2744,42 @@ func (x *JoinRoomResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JoinRoomResponse.ProtoReflect.Descriptor instead.
-func (*JoinRoomResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use WatchRoomResponse.ProtoReflect.Descriptor instead.
+func (*WatchRoomResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *JoinRoomResponse) GetSuccess() bool {
+func (x *WatchRoomResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *JoinRoomResponse) GetRoomId() string {
+func (x *WatchRoomResponse) GetRoomId() string {
 	if x != nil {
 		return x.RoomId
 	}
 	return ""
 }
 
-func (x *JoinRoomResponse) GetTimestamp() int64 {
+func (x *WatchRoomResponse) GetPlayerCount() int32 {
 	if x != nil {
-		return x.Timestamp
+		return x.PlayerCount
 	}
 	return 0
 }
 
-func (x *JoinRoomResponse) GetPlayerCount() int32 {
+func (x *WatchRoomResponse) GetSpectatorCount() int32 {
 	if x != nil {
-		return x.PlayerCount
+		return x.SpectatorCount
 	}
 	return 0
 }
 
-func (x *JoinRoomResponse) GetSeatId() int32 {
+func (x *WatchRoomResponse) GetTimestamp() int64 {
 	if x != nil {
-		return x.SeatId
+		return x.Timestamp
 	}
 	return 0
 }
@@ -1430,7 +2796,7 @@ type LeaveRoomResponse struct {
 
 func (x *LeaveRoomResponse) Reset() {
 	*x = LeaveRoomResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[14]
+	mi := &file_proto_v1_game_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1442,7 +2808,7 @@ func (x *LeaveRoomResponse) String() string {
 func (*LeaveRoomResponse) ProtoMessage() {}
 
 func (x *LeaveRoomResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[14]
+	mi := &file_proto_v1_game_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1455,7 +2821,7 @@ func (x *LeaveRoomResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LeaveRoomResponse.ProtoReflect.Descriptor instead.
 func (*LeaveRoomResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{14}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *LeaveRoomResponse) GetSuccess() bool {
@@ -1490,7 +2856,7 @@ type HeartbeatResponse struct {
 
 func (x *HeartbeatResponse) Reset() {
 	*x = HeartbeatResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[15]
+	mi := &file_proto_v1_game_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1502,7 +2868,7 @@ func (x *HeartbeatResponse) String() string {
 func (*HeartbeatResponse) ProtoMessage() {}
 
 func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[15]
+	mi := &file_proto_v1_game_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1515,7 +2881,7 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
 func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{15}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *HeartbeatResponse) GetServerTime() int64 {
@@ -1543,7 +2909,7 @@ type RoomListResponse struct {
 
 func (x *RoomListResponse) Reset() {
 	*x = RoomListResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[16]
+	mi := &file_proto_v1_game_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1555,7 +2921,7 @@ func (x *RoomListResponse) String() string {
 func (*RoomListResponse) ProtoMessage() {}
 
 func (x *RoomListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[16]
+	mi := &file_proto_v1_game_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1568,7 +2934,7 @@ func (x *RoomListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoomListResponse.ProtoReflect.Descriptor instead.
 func (*RoomListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{16}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *RoomListResponse) GetRooms() []*RoomInfo {
@@ -1602,7 +2968,7 @@ type PlayerInfoResponse struct {
 
 func (x *PlayerInfoResponse) Reset() {
 	*x = PlayerInfoResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[17]
+	mi := &file_proto_v1_game_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1614,7 +2980,7 @@ func (x *PlayerInfoResponse) String() string {
 func (*PlayerInfoResponse) ProtoMessage() {}
 
 func (x *PlayerInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[17]
+	mi := &file_proto_v1_game_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1627,7 +2993,7 @@ func (x *PlayerInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerInfoResponse.ProtoReflect.Descriptor instead.
 func (*PlayerInfoResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{17}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *PlayerInfoResponse) GetPlayerId() int64 {
@@ -1699,7 +3065,7 @@ type SelectSeatResponse struct {
 
 func (x *SelectSeatResponse) Reset() {
 	*x = SelectSeatResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[18]
+	mi := &file_proto_v1_game_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1711,7 +3077,7 @@ func (x *SelectSeatResponse) String() string {
 func (*SelectSeatResponse) ProtoMessage() {}
 
 func (x *SelectSeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[18]
+	mi := &file_proto_v1_game_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1724,7 +3090,7 @@ func (x *SelectSeatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SelectSeatResponse.ProtoReflect.Descriptor instead.
 func (*SelectSeatResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{18}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *SelectSeatResponse) GetSuccess() bool {
@@ -1767,13 +3133,15 @@ type HitFishResponse struct {
 	IsCritical    bool                   `protobuf:"varint,7,opt,name=is_critical,json=isCritical,proto3" json:"is_critical,omitempty"` // 是否暴擊
 	Multiplier    float64                `protobuf:"fixed64,8,opt,name=multiplier,proto3" json:"multiplier,omitempty"`                  // 獎勵倍數
 	Timestamp     int64                  `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	EventId       string                 `protobuf:"bytes,10,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`           // 獎勵受活動加成時的活動場次 ID
+	EventBonus    int64                  `protobuf:"varint,11,opt,name=event_bonus,json=eventBonus,proto3" json:"event_bonus,omitempty"` // 活動加成的獎勵部分（已包含在 reward 中）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HitFishResponse) Reset() {
 	*x = HitFishResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[19]
+	mi := &file_proto_v1_game_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1785,7 +3153,7 @@ func (x *HitFishResponse) String() string {
 func (*HitFishResponse) ProtoMessage() {}
 
 func (x *HitFishResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[19]
+	mi := &file_proto_v1_game_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1798,7 +3166,7 @@ func (x *HitFishResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HitFishResponse.ProtoReflect.Descriptor instead.
 func (*HitFishResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{19}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *HitFishResponse) GetSuccess() bool {
@@ -1864,6 +3232,20 @@ func (x *HitFishResponse) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *HitFishResponse) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *HitFishResponse) GetEventBonus() int64 {
+	if x != nil {
+		return x.EventBonus
+	}
+	return 0
+}
+
 // 子彈發射事件
 type BulletFiredEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1880,7 +3262,7 @@ type BulletFiredEvent struct {
 
 func (x *BulletFiredEvent) Reset() {
 	*x = BulletFiredEvent{}
-	mi := &file_proto_v1_game_proto_msgTypes[20]
+	mi := &file_proto_v1_game_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1892,7 +3274,7 @@ func (x *BulletFiredEvent) String() string {
 func (*BulletFiredEvent) ProtoMessage() {}
 
 func (x *BulletFiredEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[20]
+	mi := &file_proto_v1_game_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1905,7 +3287,7 @@ func (x *BulletFiredEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulletFiredEvent.ProtoReflect.Descriptor instead.
 func (*BulletFiredEvent) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{20}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *BulletFiredEvent) GetPlayerId() int64 {
@@ -1971,7 +3353,7 @@ type CannonSwitchedEvent struct {
 
 func (x *CannonSwitchedEvent) Reset() {
 	*x = CannonSwitchedEvent{}
-	mi := &file_proto_v1_game_proto_msgTypes[21]
+	mi := &file_proto_v1_game_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1983,7 +3365,7 @@ func (x *CannonSwitchedEvent) String() string {
 func (*CannonSwitchedEvent) ProtoMessage() {}
 
 func (x *CannonSwitchedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[21]
+	mi := &file_proto_v1_game_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1996,7 +3378,7 @@ func (x *CannonSwitchedEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CannonSwitchedEvent.ProtoReflect.Descriptor instead.
 func (*CannonSwitchedEvent) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{21}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *CannonSwitchedEvent) GetPlayerId() int64 {
@@ -2047,7 +3429,7 @@ type FishSpawnedEvent struct {
 
 func (x *FishSpawnedEvent) Reset() {
 	*x = FishSpawnedEvent{}
-	mi := &file_proto_v1_game_proto_msgTypes[22]
+	mi := &file_proto_v1_game_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2059,7 +3441,7 @@ func (x *FishSpawnedEvent) String() string {
 func (*FishSpawnedEvent) ProtoMessage() {}
 
 func (x *FishSpawnedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[22]
+	mi := &file_proto_v1_game_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2072,7 +3454,7 @@ func (x *FishSpawnedEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FishSpawnedEvent.ProtoReflect.Descriptor instead.
 func (*FishSpawnedEvent) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{22}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *FishSpawnedEvent) GetFishId() int64 {
@@ -2116,7 +3498,7 @@ type FishDiedEvent struct {
 
 func (x *FishDiedEvent) Reset() {
 	*x = FishDiedEvent{}
-	mi := &file_proto_v1_game_proto_msgTypes[23]
+	mi := &file_proto_v1_game_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2128,7 +3510,7 @@ func (x *FishDiedEvent) String() string {
 func (*FishDiedEvent) ProtoMessage() {}
 
 func (x *FishDiedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[23]
+	mi := &file_proto_v1_game_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2141,7 +3523,7 @@ func (x *FishDiedEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FishDiedEvent.ProtoReflect.Descriptor instead.
 func (*FishDiedEvent) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{23}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *FishDiedEvent) GetFishId() int64 {
@@ -2184,7 +3566,7 @@ type PlayerRewardEvent struct {
 
 func (x *PlayerRewardEvent) Reset() {
 	*x = PlayerRewardEvent{}
-	mi := &file_proto_v1_game_proto_msgTypes[24]
+	mi := &file_proto_v1_game_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2196,7 +3578,7 @@ func (x *PlayerRewardEvent) String() string {
 func (*PlayerRewardEvent) ProtoMessage() {}
 
 func (x *PlayerRewardEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[24]
+	mi := &file_proto_v1_game_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2209,7 +3591,7 @@ func (x *PlayerRewardEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerRewardEvent.ProtoReflect.Descriptor instead.
 func (*PlayerRewardEvent) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{24}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *PlayerRewardEvent) GetPlayerId() int64 {
@@ -2233,18 +3615,93 @@ func (x *PlayerRewardEvent) GetTimestamp() int64 {
 	return 0
 }
 
+// 握手請求：客戶端聲明協議版本和能力
+type HelloMessage struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolVersion int32                  `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // 客戶端實現的協議版本
+	Capabilities    []string               `protobuf:"bytes,2,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                               // 客戶端能力，如 batch
+	ClientVersion   string                 `protobuf:"bytes,3,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"`        // 客戶端版本號（僅用於記錄）
+	Platform        string                 `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`                                       // 客戶端平台（僅用於記錄）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HelloMessage) Reset() {
+	*x = HelloMessage{}
+	mi := &file_proto_v1_game_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HelloMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HelloMessage) ProtoMessage() {}
+
+func (x *HelloMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HelloMessage.ProtoReflect.Descriptor instead.
+func (*HelloMessage) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *HelloMessage) GetProtocolVersion() int32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *HelloMessage) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *HelloMessage) GetClientVersion() string {
+	if x != nil {
+		return x.ClientVersion
+	}
+	return ""
+}
+
+func (x *HelloMessage) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
 // 新增：歡迎消息
 type WelcomeMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
-	ServerTime    int64                  `protobuf:"varint,2,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ClientId           string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ServerTime         int64                  `protobuf:"varint,2,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`
+	ProtocolVersion    int32                  `protobuf:"varint,3,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`            // 本連接協商使用的協議版本
+	MinProtocolVersion int32                  `protobuf:"varint,4,opt,name=min_protocol_version,json=minProtocolVersion,proto3" json:"min_protocol_version,omitempty"` // 伺服器支持的最低協議版本
+	MaxProtocolVersion int32                  `protobuf:"varint,5,opt,name=max_protocol_version,json=maxProtocolVersion,proto3" json:"max_protocol_version,omitempty"` // 伺服器支持的最高協議版本
+	Features           []string               `protobuf:"bytes,6,rep,name=features,proto3" json:"features,omitempty"`                                                  // 伺服器啟用的功能開關
+	Capabilities       []string               `protobuf:"bytes,7,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                                          // 伺服器接受的客戶端能力
+	Codec              string                 `protobuf:"bytes,8,opt,name=codec,proto3" json:"codec,omitempty"`                                                        // 本連接使用的編碼
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *WelcomeMessage) Reset() {
 	*x = WelcomeMessage{}
-	mi := &file_proto_v1_game_proto_msgTypes[25]
+	mi := &file_proto_v1_game_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2256,7 +3713,7 @@ func (x *WelcomeMessage) String() string {
 func (*WelcomeMessage) ProtoMessage() {}
 
 func (x *WelcomeMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[25]
+	mi := &file_proto_v1_game_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2267,23 +3724,65 @@ func (x *WelcomeMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WelcomeMessage.ProtoReflect.Descriptor instead.
-func (*WelcomeMessage) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use WelcomeMessage.ProtoReflect.Descriptor instead.
+func (*WelcomeMessage) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *WelcomeMessage) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *WelcomeMessage) GetServerTime() int64 {
+	if x != nil {
+		return x.ServerTime
+	}
+	return 0
+}
+
+func (x *WelcomeMessage) GetProtocolVersion() int32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *WelcomeMessage) GetMinProtocolVersion() int32 {
+	if x != nil {
+		return x.MinProtocolVersion
+	}
+	return 0
+}
+
+func (x *WelcomeMessage) GetMaxProtocolVersion() int32 {
+	if x != nil {
+		return x.MaxProtocolVersion
+	}
+	return 0
+}
+
+func (x *WelcomeMessage) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
 }
 
-func (x *WelcomeMessage) GetClientId() string {
+func (x *WelcomeMessage) GetCapabilities() []string {
 	if x != nil {
-		return x.ClientId
+		return x.Capabilities
 	}
-	return ""
+	return nil
 }
 
-func (x *WelcomeMessage) GetServerTime() int64 {
+func (x *WelcomeMessage) GetCodec() string {
 	if x != nil {
-		return x.ServerTime
+		return x.Codec
 	}
-	return 0
+	return ""
 }
 
 // 新增：玩家加入房間事件
@@ -2299,7 +3798,7 @@ type PlayerJoinedMessage struct {
 
 func (x *PlayerJoinedMessage) Reset() {
 	*x = PlayerJoinedMessage{}
-	mi := &file_proto_v1_game_proto_msgTypes[26]
+	mi := &file_proto_v1_game_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2311,7 +3810,7 @@ func (x *PlayerJoinedMessage) String() string {
 func (*PlayerJoinedMessage) ProtoMessage() {}
 
 func (x *PlayerJoinedMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[26]
+	mi := &file_proto_v1_game_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2324,7 +3823,7 @@ func (x *PlayerJoinedMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerJoinedMessage.ProtoReflect.Descriptor instead.
 func (*PlayerJoinedMessage) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{26}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *PlayerJoinedMessage) GetPlayerId() string {
@@ -2367,7 +3866,7 @@ type PlayerLeftMessage struct {
 
 func (x *PlayerLeftMessage) Reset() {
 	*x = PlayerLeftMessage{}
-	mi := &file_proto_v1_game_proto_msgTypes[27]
+	mi := &file_proto_v1_game_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2379,7 +3878,7 @@ func (x *PlayerLeftMessage) String() string {
 func (*PlayerLeftMessage) ProtoMessage() {}
 
 func (x *PlayerLeftMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[27]
+	mi := &file_proto_v1_game_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2392,7 +3891,7 @@ func (x *PlayerLeftMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlayerLeftMessage.ProtoReflect.Descriptor instead.
 func (*PlayerLeftMessage) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{27}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *PlayerLeftMessage) GetPlayerId() string {
@@ -2437,7 +3936,7 @@ type FishInfo struct {
 
 func (x *FishInfo) Reset() {
 	*x = FishInfo{}
-	mi := &file_proto_v1_game_proto_msgTypes[28]
+	mi := &file_proto_v1_game_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2449,7 +3948,7 @@ func (x *FishInfo) String() string {
 func (*FishInfo) ProtoMessage() {}
 
 func (x *FishInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[28]
+	mi := &file_proto_v1_game_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2462,7 +3961,7 @@ func (x *FishInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FishInfo.ProtoReflect.Descriptor instead.
 func (*FishInfo) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{28}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *FishInfo) GetFishId() int64 {
@@ -2568,7 +4067,7 @@ type BulletInfo struct {
 
 func (x *BulletInfo) Reset() {
 	*x = BulletInfo{}
-	mi := &file_proto_v1_game_proto_msgTypes[29]
+	mi := &file_proto_v1_game_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2580,7 +4079,7 @@ func (x *BulletInfo) String() string {
 func (*BulletInfo) ProtoMessage() {}
 
 func (x *BulletInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[29]
+	mi := &file_proto_v1_game_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2593,7 +4092,7 @@ func (x *BulletInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BulletInfo.ProtoReflect.Descriptor instead.
 func (*BulletInfo) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{29}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *BulletInfo) GetBulletId() int64 {
@@ -2688,7 +4187,7 @@ type FormationInfo struct {
 
 func (x *FormationInfo) Reset() {
 	*x = FormationInfo{}
-	mi := &file_proto_v1_game_proto_msgTypes[30]
+	mi := &file_proto_v1_game_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2700,7 +4199,7 @@ func (x *FormationInfo) String() string {
 func (*FormationInfo) ProtoMessage() {}
 
 func (x *FormationInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[30]
+	mi := &file_proto_v1_game_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2713,7 +4212,7 @@ func (x *FormationInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FormationInfo.ProtoReflect.Descriptor instead.
 func (*FormationInfo) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{30}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *FormationInfo) GetFormationId() string {
@@ -2819,7 +4318,7 @@ type FormationSize struct {
 
 func (x *FormationSize) Reset() {
 	*x = FormationSize{}
-	mi := &file_proto_v1_game_proto_msgTypes[31]
+	mi := &file_proto_v1_game_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2831,7 +4330,7 @@ func (x *FormationSize) String() string {
 func (*FormationSize) ProtoMessage() {}
 
 func (x *FormationSize) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[31]
+	mi := &file_proto_v1_game_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2844,7 +4343,7 @@ func (x *FormationSize) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FormationSize.ProtoReflect.Descriptor instead.
 func (*FormationSize) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{31}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *FormationSize) GetWidth() float64 {
@@ -2884,7 +4383,7 @@ type RouteInfo struct {
 
 func (x *RouteInfo) Reset() {
 	*x = RouteInfo{}
-	mi := &file_proto_v1_game_proto_msgTypes[32]
+	mi := &file_proto_v1_game_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2896,7 +4395,7 @@ func (x *RouteInfo) String() string {
 func (*RouteInfo) ProtoMessage() {}
 
 func (x *RouteInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[32]
+	mi := &file_proto_v1_game_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2909,7 +4408,7 @@ func (x *RouteInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RouteInfo.ProtoReflect.Descriptor instead.
 func (*RouteInfo) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{32}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *RouteInfo) GetRouteId() string {
@@ -2973,7 +4472,7 @@ type SeatInfo struct {
 
 func (x *SeatInfo) Reset() {
 	*x = SeatInfo{}
-	mi := &file_proto_v1_game_proto_msgTypes[33]
+	mi := &file_proto_v1_game_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2985,7 +4484,7 @@ func (x *SeatInfo) String() string {
 func (*SeatInfo) ProtoMessage() {}
 
 func (x *SeatInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[33]
+	mi := &file_proto_v1_game_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2998,7 +4497,7 @@ func (x *SeatInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SeatInfo.ProtoReflect.Descriptor instead.
 func (*SeatInfo) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{33}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *SeatInfo) GetSeatId() int32 {
@@ -3024,22 +4523,25 @@ func (x *SeatInfo) GetNickname() string {
 
 // 房間狀態更新
 type RoomStateUpdate struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RoomId        string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
-	Fishes        []*FishInfo            `protobuf:"bytes,2,rep,name=fishes,proto3" json:"fishes,omitempty"`
-	Bullets       []*BulletInfo          `protobuf:"bytes,3,rep,name=bullets,proto3" json:"bullets,omitempty"`
-	Formations    []*FormationInfo       `protobuf:"bytes,4,rep,name=formations,proto3" json:"formations,omitempty"`
-	PlayerCount   int32                  `protobuf:"varint,5,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	RoomStatus    string                 `protobuf:"bytes,7,opt,name=room_status,json=roomStatus,proto3" json:"room_status,omitempty"`
-	Seats         []*SeatInfo            `protobuf:"bytes,8,rep,name=seats,proto3" json:"seats,omitempty"` // 座位信息
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	RoomId         string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Fishes         []*FishInfo            `protobuf:"bytes,2,rep,name=fishes,proto3" json:"fishes,omitempty"`
+	Bullets        []*BulletInfo          `protobuf:"bytes,3,rep,name=bullets,proto3" json:"bullets,omitempty"`
+	Formations     []*FormationInfo       `protobuf:"bytes,4,rep,name=formations,proto3" json:"formations,omitempty"`
+	PlayerCount    int32                  `protobuf:"varint,5,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"`
+	Timestamp      int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	RoomStatus     string                 `protobuf:"bytes,7,opt,name=room_status,json=roomStatus,proto3" json:"room_status,omitempty"`
+	Seats          []*SeatInfo            `protobuf:"bytes,8,rep,name=seats,proto3" json:"seats,omitempty"`                                          // 座位信息
+	SpectatorCount int32                  `protobuf:"varint,9,opt,name=spectator_count,json=spectatorCount,proto3" json:"spectator_count,omitempty"` // 觀戰人數
+	ConfigVersion  int64                  `protobuf:"varint,10,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"`   // 房間當前套用的配置版本（0 表示內建默認配置）
+	Events         []*RoomEventInfo       `protobuf:"bytes,11,rep,name=events,proto3" json:"events,omitempty"`                                       // 房間正在進行的活動
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *RoomStateUpdate) Reset() {
 	*x = RoomStateUpdate{}
-	mi := &file_proto_v1_game_proto_msgTypes[34]
+	mi := &file_proto_v1_game_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3051,7 +4553,7 @@ func (x *RoomStateUpdate) String() string {
 func (*RoomStateUpdate) ProtoMessage() {}
 
 func (x *RoomStateUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[34]
+	mi := &file_proto_v1_game_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3064,7 +4566,7 @@ func (x *RoomStateUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoomStateUpdate.ProtoReflect.Descriptor instead.
 func (*RoomStateUpdate) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{34}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{48}
 }
 
 func (x *RoomStateUpdate) GetRoomId() string {
@@ -3123,6 +4625,27 @@ func (x *RoomStateUpdate) GetSeats() []*SeatInfo {
 	return nil
 }
 
+func (x *RoomStateUpdate) GetSpectatorCount() int32 {
+	if x != nil {
+		return x.SpectatorCount
+	}
+	return 0
+}
+
+func (x *RoomStateUpdate) GetConfigVersion() int64 {
+	if x != nil {
+		return x.ConfigVersion
+	}
+	return 0
+}
+
+func (x *RoomStateUpdate) GetEvents() []*RoomEventInfo {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 // 魚群陣型生成事件
 type FormationSpawnedEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -3136,7 +4659,7 @@ type FormationSpawnedEvent struct {
 
 func (x *FormationSpawnedEvent) Reset() {
 	*x = FormationSpawnedEvent{}
-	mi := &file_proto_v1_game_proto_msgTypes[35]
+	mi := &file_proto_v1_game_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3148,7 +4671,7 @@ func (x *FormationSpawnedEvent) String() string {
 func (*FormationSpawnedEvent) ProtoMessage() {}
 
 func (x *FormationSpawnedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[35]
+	mi := &file_proto_v1_game_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3161,7 +4684,7 @@ func (x *FormationSpawnedEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FormationSpawnedEvent.ProtoReflect.Descriptor instead.
 func (*FormationSpawnedEvent) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{35}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{49}
 }
 
 func (x *FormationSpawnedEvent) GetRoomId() string {
@@ -3208,7 +4731,7 @@ type FormationUpdatedEvent struct {
 
 func (x *FormationUpdatedEvent) Reset() {
 	*x = FormationUpdatedEvent{}
-	mi := &file_proto_v1_game_proto_msgTypes[36]
+	mi := &file_proto_v1_game_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3220,7 +4743,7 @@ func (x *FormationUpdatedEvent) String() string {
 func (*FormationUpdatedEvent) ProtoMessage() {}
 
 func (x *FormationUpdatedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[36]
+	mi := &file_proto_v1_game_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3233,7 +4756,7 @@ func (x *FormationUpdatedEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FormationUpdatedEvent.ProtoReflect.Descriptor instead.
 func (*FormationUpdatedEvent) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{36}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{50}
 }
 
 func (x *FormationUpdatedEvent) GetRoomId() string {
@@ -3285,6 +4808,144 @@ func (x *FormationUpdatedEvent) GetTimestamp() int64 {
 	return 0
 }
 
+// 節點下線通知：伺服器不再接受加入房間，截止時間後關閉連接並結算
+type ServerDrainingEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	ReconnectUrl  string                 `protobuf:"bytes,2,opt,name=reconnect_url,json=reconnectUrl,proto3" json:"reconnect_url,omitempty"` // 建議重連的節點地址，為空時應通過大廳重新路由
+	Deadline      int64                  `protobuf:"varint,3,opt,name=deadline,proto3" json:"deadline,omitempty"`                            // 連接被關閉的時間（Unix 毫秒）
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerDrainingEvent) Reset() {
+	*x = ServerDrainingEvent{}
+	mi := &file_proto_v1_game_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerDrainingEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerDrainingEvent) ProtoMessage() {}
+
+func (x *ServerDrainingEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerDrainingEvent.ProtoReflect.Descriptor instead.
+func (*ServerDrainingEvent) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ServerDrainingEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ServerDrainingEvent) GetReconnectUrl() string {
+	if x != nil {
+		return x.ReconnectUrl
+	}
+	return ""
+}
+
+func (x *ServerDrainingEvent) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *ServerDrainingEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// 房間聊天消息
+type ChatMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      int64                  `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Nickname      string                 `protobuf:"bytes,2,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // 伺服器收到發言的時間（毫秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	mi := &file_proto_v1_game_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ChatMessage) GetPlayerId() int64 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *ChatMessage) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
 // 房間信息
 type RoomInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -3294,14 +4955,15 @@ type RoomInfo struct {
 	PlayerCount   int32                  `protobuf:"varint,4,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"`
 	MaxPlayers    int32                  `protobuf:"varint,5,opt,name=max_players,json=maxPlayers,proto3" json:"max_players,omitempty"` // 最大玩家數（座位數）
 	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
-	Seats         []*SeatInfo            `protobuf:"bytes,7,rep,name=seats,proto3" json:"seats,omitempty"` // 座位信息
+	Seats         []*SeatInfo            `protobuf:"bytes,7,rep,name=seats,proto3" json:"seats,omitempty"`                                       // 座位信息
+	ConfigVersion int64                  `protobuf:"varint,8,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"` // 房間當前套用的配置版本
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RoomInfo) Reset() {
 	*x = RoomInfo{}
-	mi := &file_proto_v1_game_proto_msgTypes[37]
+	mi := &file_proto_v1_game_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3313,7 +4975,7 @@ func (x *RoomInfo) String() string {
 func (*RoomInfo) ProtoMessage() {}
 
 func (x *RoomInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[37]
+	mi := &file_proto_v1_game_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3326,7 +4988,7 @@ func (x *RoomInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoomInfo.ProtoReflect.Descriptor instead.
 func (*RoomInfo) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{37}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *RoomInfo) GetRoomId() string {
@@ -3378,19 +5040,82 @@ func (x *RoomInfo) GetSeats() []*SeatInfo {
 	return nil
 }
 
+func (x *RoomInfo) GetConfigVersion() int64 {
+	if x != nil {
+		return x.ConfigVersion
+	}
+	return 0
+}
+
+// 批量消息（客戶端以 batch=1 協商後啟用）
+type MessageBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*GameMessage         `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"` // 按發送順序排列
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageBatch) Reset() {
+	*x = MessageBatch{}
+	mi := &file_proto_v1_game_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageBatch) ProtoMessage() {}
+
+func (x *MessageBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_game_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageBatch.ProtoReflect.Descriptor instead.
+func (*MessageBatch) Descriptor() ([]byte, []int) {
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *MessageBatch) GetMessages() []*GameMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *MessageBatch) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
 // 錯誤消息
 type ErrorMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"` // 錯誤碼名稱，與 error_code 對應
 	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ErrorCode     ErrorCode              `protobuf:"varint,4,opt,name=error_code,json=errorCode,proto3,enum=v1.ErrorCode" json:"error_code,omitempty"`
+	Retryable     bool                   `protobuf:"varint,5,opt,name=retryable,proto3" json:"retryable,omitempty"`                             // 相同請求稍後重試是否可能成功
+	RetryAfterMs  int64                  `protobuf:"varint,6,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // 建議的重試等待時間，0 表示由客戶端決定
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ErrorMessage) Reset() {
 	*x = ErrorMessage{}
-	mi := &file_proto_v1_game_proto_msgTypes[38]
+	mi := &file_proto_v1_game_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3402,7 +5127,7 @@ func (x *ErrorMessage) String() string {
 func (*ErrorMessage) ProtoMessage() {}
 
 func (x *ErrorMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[38]
+	mi := &file_proto_v1_game_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3415,7 +5140,7 @@ func (x *ErrorMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ErrorMessage.ProtoReflect.Descriptor instead.
 func (*ErrorMessage) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{38}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{55}
 }
 
 func (x *ErrorMessage) GetMessage() string {
@@ -3439,6 +5164,27 @@ func (x *ErrorMessage) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *ErrorMessage) GetErrorCode() ErrorCode {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+func (x *ErrorMessage) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *ErrorMessage) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
 // 登入請求
 type LoginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -3450,7 +5196,7 @@ type LoginRequest struct {
 
 func (x *LoginRequest) Reset() {
 	*x = LoginRequest{}
-	mi := &file_proto_v1_game_proto_msgTypes[39]
+	mi := &file_proto_v1_game_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3462,7 +5208,7 @@ func (x *LoginRequest) String() string {
 func (*LoginRequest) ProtoMessage() {}
 
 func (x *LoginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[39]
+	mi := &file_proto_v1_game_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3475,7 +5221,7 @@ func (x *LoginRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
 func (*LoginRequest) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{39}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *LoginRequest) GetUsername() string {
@@ -3502,7 +5248,7 @@ type LoginResponse struct {
 
 func (x *LoginResponse) Reset() {
 	*x = LoginResponse{}
-	mi := &file_proto_v1_game_proto_msgTypes[40]
+	mi := &file_proto_v1_game_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3514,7 +5260,7 @@ func (x *LoginResponse) String() string {
 func (*LoginResponse) ProtoMessage() {}
 
 func (x *LoginResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_game_proto_msgTypes[40]
+	mi := &file_proto_v1_game_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3527,7 +5273,7 @@ func (x *LoginResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
 func (*LoginResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_game_proto_rawDescGZIP(), []int{40}
+	return file_proto_v1_game_proto_rawDescGZIP(), []int{57}
 }
 
 func (x *LoginResponse) GetToken() string {
@@ -3544,7 +5290,7 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\x13proto/v1/game.proto\x12\x02v1\"&\n" +
 	"\bPosition\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
-	"\x01y\x18\x02 \x01(\x01R\x01y\"\xad\x0f\n" +
+	"\x01y\x18\x02 \x01(\x01R\x01y\"\xca\x16\n" +
 	"\vGameMessage\x12#\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x0f.v1.MessageTypeR\x04type\x128\n" +
 	"\vfire_bullet\x18\x02 \x01(\v2\x15.v1.FireBulletRequestH\x00R\n" +
@@ -3580,7 +5326,28 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"playerLeft\x12A\n" +
 	"\x11room_state_update\x18\x1e \x01(\v2\x13.v1.RoomStateUpdateH\x00R\x0froomStateUpdate\x12H\n" +
 	"\x11formation_spawned\x18\x1f \x01(\v2\x19.v1.FormationSpawnedEventH\x00R\x10formationSpawned\x12H\n" +
-	"\x11formation_updated\x18  \x01(\v2\x19.v1.FormationUpdatedEventH\x00R\x10formationUpdated\x12(\n" +
+	"\x11formation_updated\x18  \x01(\v2\x19.v1.FormationUpdatedEventH\x00R\x10formationUpdated\x12B\n" +
+	"\x0fserver_draining\x18! \x01(\v2\x17.v1.ServerDrainingEventH\x00R\x0eserverDraining\x125\n" +
+	"\n" +
+	"quick_join\x18( \x01(\v2\x14.v1.QuickJoinRequestH\x00R\tquickJoin\x125\n" +
+	"\n" +
+	"watch_room\x18) \x01(\v2\x14.v1.WatchRoomRequestH\x00R\twatchRoom\x12G\n" +
+	"\x13watch_room_response\x18* \x01(\v2\x15.v1.WatchRoomResponseH\x00R\x11watchRoomResponse\x12N\n" +
+	"\x13create_private_room\x18+ \x01(\v2\x1c.v1.CreatePrivateRoomRequestH\x00R\x11createPrivateRoom\x128\n" +
+	"\vkick_player\x18, \x01(\v2\x15.v1.KickPlayerRequestH\x00R\n" +
+	"kickPlayer\x122\n" +
+	"\tlock_room\x18- \x01(\v2\x13.v1.LockRoomRequestH\x00R\blockRoom\x12G\n" +
+	"\x13private_room_update\x18. \x01(\v2\x15.v1.PrivateRoomUpdateH\x00R\x11privateRoomUpdate\x122\n" +
+	"\tswap_seat\x182 \x01(\v2\x13.v1.SwapSeatRequestH\x00R\bswapSeat\x12H\n" +
+	"\x11respond_seat_swap\x183 \x01(\v2\x1a.v1.RespondSeatSwapRequestH\x00R\x0frespondSeatSwap\x12.\n" +
+	"\n" +
+	"seat_event\x184 \x01(\v2\r.v1.SeatEventH\x00R\tseatEvent\x12:\n" +
+	"\n" +
+	"room_event\x18< \x01(\v2\x19.v1.RoomEventNotificationH\x00R\troomEvent\x12%\n" +
+	"\x04chat\x18F \x01(\v2\x0f.v1.ChatRequestH\x00R\x04chat\x124\n" +
+	"\fchat_message\x18G \x01(\v2\x0f.v1.ChatMessageH\x00R\vchatMessage\x12(\n" +
+	"\x05hello\x18P \x01(\v2\x10.v1.HelloMessageH\x00R\x05hello\x12(\n" +
+	"\x05batch\x18Z \x01(\v2\x10.v1.MessageBatchH\x00R\x05batch\x12(\n" +
 	"\x05error\x18c \x01(\v2\x10.v1.ErrorMessageH\x00R\x05errorB\x06\n" +
 	"\x04data\"\x97\x01\n" +
 	"\x11FireBulletRequest\x12\x1c\n" +
@@ -3591,9 +5358,26 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\x13SwitchCannonRequest\x12\x1f\n" +
 	"\vcannon_type\x18\x01 \x01(\x05R\n" +
 	"cannonType\x12\x14\n" +
-	"\x05level\x18\x02 \x01(\x05R\x05level\"*\n" +
+	"\x05level\x18\x02 \x01(\x05R\x05level\"g\n" +
 	"\x0fJoinRoomRequest\x12\x17\n" +
-	"\aroom_id\x18\x01 \x01(\tR\x06roomId\"\x12\n" +
+	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12\x1f\n" +
+	"\vinvite_code\x18\x02 \x01(\tR\n" +
+	"inviteCode\x12\x1a\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\"u\n" +
+	"\x10QuickJoinRequest\x12\x1b\n" +
+	"\troom_type\x18\x01 \x01(\tR\broomType\x12\x1d\n" +
+	"\n" +
+	"friend_ids\x18\x02 \x03(\x03R\tfriendIds\x12%\n" +
+	"\x0epreferred_fill\x18\x03 \x01(\x01R\rpreferredFill\"+\n" +
+	"\x10WatchRoomRequest\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\tR\x06roomId\"S\n" +
+	"\x18CreatePrivateRoomRequest\x12\x1b\n" +
+	"\troom_type\x18\x01 \x01(\tR\broomType\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"0\n" +
+	"\x11KickPlayerRequest\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\x03R\bplayerId\")\n" +
+	"\x0fLockRoomRequest\x12\x16\n" +
+	"\x06locked\x18\x01 \x01(\bR\x06locked\"\x12\n" +
 	"\x10LeaveRoomRequest\"0\n" +
 	"\x10HeartbeatMessage\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"1\n" +
@@ -3601,10 +5385,17 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\troom_type\x18\x01 \x01(\tR\broomType\"\x16\n" +
 	"\x14GetPlayerInfoRequest\",\n" +
 	"\x11SelectSeatRequest\x12\x17\n" +
-	"\aseat_id\x18\x01 \x01(\x05R\x06seatId\"F\n" +
+	"\aseat_id\x18\x01 \x01(\x05R\x06seatId\"*\n" +
+	"\x0fSwapSeatRequest\x12\x17\n" +
+	"\aseat_id\x18\x01 \x01(\x05R\x06seatId\"S\n" +
+	"\x16RespondSeatSwapRequest\x12!\n" +
+	"\frequester_id\x18\x01 \x01(\x03R\vrequesterId\x12\x16\n" +
+	"\x06accept\x18\x02 \x01(\bR\x06accept\"F\n" +
 	"\x0eHitFishRequest\x12\x1b\n" +
 	"\tbullet_id\x18\x01 \x01(\x03R\bbulletId\x12\x17\n" +
-	"\afish_id\x18\x02 \x01(\x03R\x06fishId\"\xa3\x01\n" +
+	"\afish_id\x18\x02 \x01(\x03R\x06fishId\"!\n" +
+	"\vChatRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"\xa3\x01\n" +
 	"\x12FireBulletResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
 	"\tbullet_id\x18\x02 \x01(\x03R\bbulletId\x12\x12\n" +
@@ -3617,13 +5408,58 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"cannonType\x12\x14\n" +
 	"\x05level\x18\x03 \x01(\x05R\x05level\x12\x14\n" +
 	"\x05power\x18\x04 \x01(\x05R\x05power\x12\x1c\n" +
-	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"\x9f\x01\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"\xaa\x02\n" +
 	"\x10JoinRoomResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x17\n" +
 	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12\x1c\n" +
 	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12!\n" +
 	"\fplayer_count\x18\x04 \x01(\x05R\vplayerCount\x12\x17\n" +
-	"\aseat_id\x18\x05 \x01(\x05R\x06seatId\"d\n" +
+	"\aseat_id\x18\x05 \x01(\x05R\x06seatId\x12\x1b\n" +
+	"\troom_type\x18\x06 \x01(\tR\broomType\x12\x18\n" +
+	"\aprivate\x18\a \x01(\bR\aprivate\x12\x1f\n" +
+	"\vinvite_code\x18\b \x01(\tR\n" +
+	"inviteCode\x12\x19\n" +
+	"\bowner_id\x18\t \x01(\x03R\aownerId\x12\x16\n" +
+	"\x06locked\x18\n" +
+	" \x01(\bR\x06locked\"\xa7\x01\n" +
+	"\x11PrivateRoomUpdate\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12\x19\n" +
+	"\bowner_id\x18\x02 \x01(\x03R\aownerId\x12\x16\n" +
+	"\x06locked\x18\x03 \x01(\bR\x06locked\x12(\n" +
+	"\x10kicked_player_id\x18\x04 \x01(\x03R\x0ekickedPlayerId\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"\x8f\x02\n" +
+	"\tSeatEvent\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1b\n" +
+	"\tplayer_id\x18\x03 \x01(\x03R\bplayerId\x12\x17\n" +
+	"\aseat_id\x18\x04 \x01(\x05R\x06seatId\x12&\n" +
+	"\x0fother_player_id\x18\x05 \x01(\x03R\rotherPlayerId\x12\"\n" +
+	"\rother_seat_id\x18\x06 \x01(\x05R\votherSeatId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\x03R\texpiresAt\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x12\x1c\n" +
+	"\ttimestamp\x18\t \x01(\x03R\ttimestamp\"\xfa\x01\n" +
+	"\rRoomEventInfo\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1f\n" +
+	"\vschedule_id\x18\x02 \x01(\x03R\n" +
+	"scheduleId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x1b\n" +
+	"\tstarts_at\x18\x05 \x01(\x03R\bstartsAt\x12\x17\n" +
+	"\aends_at\x18\x06 \x01(\x03R\x06endsAt\x12+\n" +
+	"\x11reward_multiplier\x18\a \x01(\x01R\x10rewardMultiplier\x12\"\n" +
+	"\fannouncement\x18\b \x01(\tR\fannouncement\"\x8d\x01\n" +
+	"\x15RoomEventNotification\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12\x14\n" +
+	"\x05phase\x18\x02 \x01(\tR\x05phase\x12'\n" +
+	"\x05event\x18\x03 \x01(\v2\x11.v1.RoomEventInfoR\x05event\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"\xb0\x01\n" +
+	"\x11WatchRoomResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x17\n" +
+	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12!\n" +
+	"\fplayer_count\x18\x03 \x01(\x05R\vplayerCount\x12'\n" +
+	"\x0fspectator_count\x18\x04 \x01(\x05R\x0espectatorCount\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"d\n" +
 	"\x11LeaveRoomResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x17\n" +
 	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12\x1c\n" +
@@ -3648,7 +5484,7 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x17\n" +
 	"\aseat_id\x18\x02 \x01(\x05R\x06seatId\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"\x8d\x02\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"\xc9\x02\n" +
 	"\x0fHitFishResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
 	"\tbullet_id\x18\x02 \x01(\x03R\bbulletId\x12\x17\n" +
@@ -3661,7 +5497,11 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\n" +
 	"multiplier\x18\b \x01(\x01R\n" +
 	"multiplier\x12\x1c\n" +
-	"\ttimestamp\x18\t \x01(\x03R\ttimestamp\"\xee\x01\n" +
+	"\ttimestamp\x18\t \x01(\x03R\ttimestamp\x12\x19\n" +
+	"\bevent_id\x18\n" +
+	" \x01(\tR\aeventId\x12\x1f\n" +
+	"\vevent_bonus\x18\v \x01(\x03R\n" +
+	"eventBonus\"\xee\x01\n" +
 	"\x10BulletFiredEvent\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\x03R\bplayerId\x12\x1b\n" +
 	"\tbullet_id\x18\x02 \x01(\x03R\bbulletId\x12\x1c\n" +
@@ -3690,11 +5530,22 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\x11PlayerRewardEvent\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\x03R\bplayerId\x12\x16\n" +
 	"\x06reward\x18\x02 \x01(\x03R\x06reward\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"N\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"\xa0\x01\n" +
+	"\fHelloMessage\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\x05R\x0fprotocolVersion\x12\"\n" +
+	"\fcapabilities\x18\x02 \x03(\tR\fcapabilities\x12%\n" +
+	"\x0eclient_version\x18\x03 \x01(\tR\rclientVersion\x12\x1a\n" +
+	"\bplatform\x18\x04 \x01(\tR\bplatform\"\xb3\x02\n" +
 	"\x0eWelcomeMessage\x12\x1b\n" +
 	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x1f\n" +
 	"\vserver_time\x18\x02 \x01(\x03R\n" +
-	"serverTime\"\x80\x01\n" +
+	"serverTime\x12)\n" +
+	"\x10protocol_version\x18\x03 \x01(\x05R\x0fprotocolVersion\x120\n" +
+	"\x14min_protocol_version\x18\x04 \x01(\x05R\x12minProtocolVersion\x120\n" +
+	"\x14max_protocol_version\x18\x05 \x01(\x05R\x12maxProtocolVersion\x12\x1a\n" +
+	"\bfeatures\x18\x06 \x03(\tR\bfeatures\x12\"\n" +
+	"\fcapabilities\x18\a \x03(\tR\fcapabilities\x12\x14\n" +
+	"\x05codec\x18\b \x01(\tR\x05codec\"\x80\x01\n" +
 	"\x13PlayerJoinedMessage\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x17\n" +
 	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12\x17\n" +
@@ -3770,7 +5621,7 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\bSeatInfo\x12\x17\n" +
 	"\aseat_id\x18\x01 \x01(\x05R\x06seatId\x12\x1b\n" +
 	"\tplayer_id\x18\x02 \x01(\x03R\bplayerId\x12\x1a\n" +
-	"\bnickname\x18\x03 \x01(\tR\bnickname\"\xb3\x02\n" +
+	"\bnickname\x18\x03 \x01(\tR\bnickname\"\xae\x03\n" +
 	"\x0fRoomStateUpdate\x12\x17\n" +
 	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12$\n" +
 	"\x06fishes\x18\x02 \x03(\v2\f.v1.FishInfoR\x06fishes\x12(\n" +
@@ -3782,7 +5633,11 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12\x1f\n" +
 	"\vroom_status\x18\a \x01(\tR\n" +
 	"roomStatus\x12\"\n" +
-	"\x05seats\x18\b \x03(\v2\f.v1.SeatInfoR\x05seats\"\xa5\x01\n" +
+	"\x05seats\x18\b \x03(\v2\f.v1.SeatInfoR\x05seats\x12'\n" +
+	"\x0fspectator_count\x18\t \x01(\x05R\x0espectatorCount\x12%\n" +
+	"\x0econfig_version\x18\n" +
+	" \x01(\x03R\rconfigVersion\x12)\n" +
+	"\x06events\x18\v \x03(\v2\x11.v1.RoomEventInfoR\x06events\"\xa5\x01\n" +
 	"\x15FormationSpawnedEvent\x12\x17\n" +
 	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12/\n" +
 	"\tformation\x18\x02 \x01(\v2\x11.v1.FormationInfoR\tformation\x12$\n" +
@@ -3795,7 +5650,17 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\tdirection\x18\x04 \x01(\x01R\tdirection\x12\x1a\n" +
 	"\bprogress\x18\x05 \x01(\x01R\bprogress\x12$\n" +
 	"\x06fishes\x18\x06 \x03(\v2\f.v1.FishInfoR\x06fishes\x12\x1c\n" +
-	"\ttimestamp\x18\a \x01(\x03R\ttimestamp\"\xcb\x01\n" +
+	"\ttimestamp\x18\a \x01(\x03R\ttimestamp\"\x8c\x01\n" +
+	"\x13ServerDrainingEvent\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12#\n" +
+	"\rreconnect_url\x18\x02 \x01(\tR\freconnectUrl\x12\x1a\n" +
+	"\bdeadline\x18\x03 \x01(\x03R\bdeadline\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"x\n" +
+	"\vChatMessage\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\x03R\bplayerId\x12\x1a\n" +
+	"\bnickname\x18\x02 \x01(\tR\bnickname\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"\xf2\x01\n" +
 	"\bRoomInfo\x12\x17\n" +
 	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
@@ -3804,16 +5669,24 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\vmax_players\x18\x05 \x01(\x05R\n" +
 	"maxPlayers\x12\x16\n" +
 	"\x06status\x18\x06 \x01(\tR\x06status\x12\"\n" +
-	"\x05seats\x18\a \x03(\v2\f.v1.SeatInfoR\x05seats\"Z\n" +
+	"\x05seats\x18\a \x03(\v2\f.v1.SeatInfoR\x05seats\x12%\n" +
+	"\x0econfig_version\x18\b \x01(\x03R\rconfigVersion\"Y\n" +
+	"\fMessageBatch\x12+\n" +
+	"\bmessages\x18\x01 \x03(\v2\x0f.v1.GameMessageR\bmessages\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"\xcc\x01\n" +
 	"\fErrorMessage\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x12\x12\n" +
 	"\x04code\x18\x02 \x01(\tR\x04code\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"F\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12,\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\x0e2\r.v1.ErrorCodeR\terrorCode\x12\x1c\n" +
+	"\tretryable\x18\x05 \x01(\bR\tretryable\x12$\n" +
+	"\x0eretry_after_ms\x18\x06 \x01(\x03R\fretryAfterMs\"F\n" +
 	"\fLoginRequest\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\"%\n" +
 	"\rLoginResponse\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token*\xf1\x04\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token*\x91\a\n" +
 	"\vMessageType\x12\v\n" +
 	"\aINVALID\x10\x00\x12\x0f\n" +
 	"\vFIRE_BULLET\x10\x01\x12\x11\n" +
@@ -3846,8 +5719,72 @@ const file_proto_v1_game_proto_rawDesc = "" +
 	"\vPLAYER_LEFT\x10\x1b\x12\x15\n" +
 	"\x11ROOM_STATE_UPDATE\x10\x1c\x12\x15\n" +
 	"\x11FORMATION_SPAWNED\x10\x1d\x12\x15\n" +
-	"\x11FORMATION_UPDATED\x10\x1e\x12\t\n" +
-	"\x05ERROR\x10c24\n" +
+	"\x11FORMATION_UPDATED\x10\x1e\x12\x13\n" +
+	"\x0fSERVER_DRAINING\x10\x1f\x12\x0e\n" +
+	"\n" +
+	"QUICK_JOIN\x10(\x12\x0e\n" +
+	"\n" +
+	"WATCH_ROOM\x10)\x12\x17\n" +
+	"\x13WATCH_ROOM_RESPONSE\x10*\x12\x17\n" +
+	"\x13CREATE_PRIVATE_ROOM\x10+\x12\x0f\n" +
+	"\vKICK_PLAYER\x10,\x12\r\n" +
+	"\tLOCK_ROOM\x10-\x12\x17\n" +
+	"\x13PRIVATE_ROOM_UPDATE\x10.\x12\r\n" +
+	"\tSWAP_SEAT\x102\x12\x15\n" +
+	"\x11RESPOND_SEAT_SWAP\x103\x12\x0e\n" +
+	"\n" +
+	"SEAT_EVENT\x104\x12\x0e\n" +
+	"\n" +
+	"ROOM_EVENT\x10<\x12\b\n" +
+	"\x04CHAT\x10F\x12\x10\n" +
+	"\fCHAT_MESSAGE\x10G\x12\t\n" +
+	"\x05HELLO\x10P\x12\x11\n" +
+	"\rMESSAGE_BATCH\x10Z\x12\t\n" +
+	"\x05ERROR\x10c*\xa8\a\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rGENERAL_ERROR\x10\x01\x12\x12\n" +
+	"\x0eINTERNAL_ERROR\x10\x02\x12\x13\n" +
+	"\x0fINVALID_MESSAGE\x10\x03\x12\x14\n" +
+	"\x10INVALID_ARGUMENT\x10\x04\x12\x17\n" +
+	"\x13UNSUPPORTED_MESSAGE\x10\x05\x12\x13\n" +
+	"\x0fREQUEST_TIMEOUT\x10\x06\x12 \n" +
+	"\x1cPROTOCOL_VERSION_UNSUPPORTED\x10d\x12\x10\n" +
+	"\fRATE_LIMITED\x10e\x12\x16\n" +
+	"\x12TEMPORARILY_BANNED\x10f\x12\x11\n" +
+	"\rNODE_DRAINING\x10g\x12\x12\n" +
+	"\x0eACCOUNT_BANNED\x10h\x12\x13\n" +
+	"\x0eROOM_NOT_FOUND\x10\xc8\x01\x12\x0e\n" +
+	"\tROOM_FULL\x10\xc9\x01\x12\x0f\n" +
+	"\n" +
+	"SEAT_TAKEN\x10\xca\x01\x12\x11\n" +
+	"\fINVALID_SEAT\x10\xcb\x01\x12\x10\n" +
+	"\vNOT_IN_ROOM\x10\xcc\x01\x12\x14\n" +
+	"\x0fALREADY_IN_ROOM\x10\xcd\x01\x12\x12\n" +
+	"\rSEAT_REQUIRED\x10\xce\x01\x12\x16\n" +
+	"\x11NO_ROOM_AVAILABLE\x10\xcf\x01\x12\x14\n" +
+	"\x0fSPECTATORS_FULL\x10\xd0\x01\x12\x10\n" +
+	"\vROOM_LOCKED\x10\xd1\x01\x12\x18\n" +
+	"\x13INVALID_INVITE_CODE\x10\xd2\x01\x12\x18\n" +
+	"\x13WRONG_ROOM_PASSWORD\x10\xd3\x01\x12\x15\n" +
+	"\x10KICKED_FROM_ROOM\x10\xd4\x01\x12\x13\n" +
+	"\x0eNOT_ROOM_OWNER\x10\xd5\x01\x12\x17\n" +
+	"\x12PRIVATE_ROOM_LIMIT\x10\xd6\x01\x12\x12\n" +
+	"\rSEAT_RESERVED\x10\xd7\x01\x12\x1b\n" +
+	"\x16SWAP_REQUEST_NOT_FOUND\x10\xd8\x01\x12\x19\n" +
+	"\x14ROOM_TYPE_RESTRICTED\x10\xd9\x01\x12\x13\n" +
+	"\x0eINVALID_CANNON\x10\xac\x02\x12\x19\n" +
+	"\x14INVALID_BULLET_POWER\x10\xad\x02\x12\x15\n" +
+	"\x10BULLET_NOT_FOUND\x10\xae\x02\x12\x13\n" +
+	"\x0eFISH_NOT_FOUND\x10\xaf\x02\x12\x15\n" +
+	"\x10PLAYER_NOT_FOUND\x10\xb0\x02\x12\x1f\n" +
+	"\x1aSPECTATOR_ACTION_FORBIDDEN\x10\xb1\x02\x12\x0f\n" +
+	"\n" +
+	"CHAT_MUTED\x10\xb2\x02\x12\x19\n" +
+	"\x14INSUFFICIENT_BALANCE\x10\x90\x03\x12\x17\n" +
+	"\x12WALLET_UNAVAILABLE\x10\x91\x03\x12\x12\n" +
+	"\rWALLET_FROZEN\x10\x92\x03\x12\x15\n" +
+	"\x10WALLET_NOT_FOUND\x10\x93\x0324\n" +
 	"\x04Game\x12,\n" +
 	"\x05Login\x12\x10.v1.LoginRequest\x1a\x11.v1.LoginResponseB\x0eZ\fpkg/pb/v1;v1b\x06proto3"
 
@@ -3863,110 +5800,148 @@ func file_proto_v1_game_proto_rawDescGZIP() []byte {
 	return file_proto_v1_game_proto_rawDescData
 }
 
-var file_proto_v1_game_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_v1_game_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
+var file_proto_v1_game_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_v1_game_proto_msgTypes = make([]protoimpl.MessageInfo, 58)
 var file_proto_v1_game_proto_goTypes = []any{
-	(MessageType)(0),              // 0: v1.MessageType
-	(*Position)(nil),              // 1: v1.Position
-	(*GameMessage)(nil),           // 2: v1.GameMessage
-	(*FireBulletRequest)(nil),     // 3: v1.FireBulletRequest
-	(*SwitchCannonRequest)(nil),   // 4: v1.SwitchCannonRequest
-	(*JoinRoomRequest)(nil),       // 5: v1.JoinRoomRequest
-	(*LeaveRoomRequest)(nil),      // 6: v1.LeaveRoomRequest
-	(*HeartbeatMessage)(nil),      // 7: v1.HeartbeatMessage
-	(*GetRoomListRequest)(nil),    // 8: v1.GetRoomListRequest
-	(*GetPlayerInfoRequest)(nil),  // 9: v1.GetPlayerInfoRequest
-	(*SelectSeatRequest)(nil),     // 10: v1.SelectSeatRequest
-	(*HitFishRequest)(nil),        // 11: v1.HitFishRequest
-	(*FireBulletResponse)(nil),    // 12: v1.FireBulletResponse
-	(*SwitchCannonResponse)(nil),  // 13: v1.SwitchCannonResponse
-	(*JoinRoomResponse)(nil),      // 14: v1.JoinRoomResponse
-	(*LeaveRoomResponse)(nil),     // 15: v1.LeaveRoomResponse
-	(*HeartbeatResponse)(nil),     // 16: v1.HeartbeatResponse
-	(*RoomListResponse)(nil),      // 17: v1.RoomListResponse
-	(*PlayerInfoResponse)(nil),    // 18: v1.PlayerInfoResponse
-	(*SelectSeatResponse)(nil),    // 19: v1.SelectSeatResponse
-	(*HitFishResponse)(nil),       // 20: v1.HitFishResponse
-	(*BulletFiredEvent)(nil),      // 21: v1.BulletFiredEvent
-	(*CannonSwitchedEvent)(nil),   // 22: v1.CannonSwitchedEvent
-	(*FishSpawnedEvent)(nil),      // 23: v1.FishSpawnedEvent
-	(*FishDiedEvent)(nil),         // 24: v1.FishDiedEvent
-	(*PlayerRewardEvent)(nil),     // 25: v1.PlayerRewardEvent
-	(*WelcomeMessage)(nil),        // 26: v1.WelcomeMessage
-	(*PlayerJoinedMessage)(nil),   // 27: v1.PlayerJoinedMessage
-	(*PlayerLeftMessage)(nil),     // 28: v1.PlayerLeftMessage
-	(*FishInfo)(nil),              // 29: v1.FishInfo
-	(*BulletInfo)(nil),            // 30: v1.BulletInfo
-	(*FormationInfo)(nil),         // 31: v1.FormationInfo
-	(*FormationSize)(nil),         // 32: v1.FormationSize
-	(*RouteInfo)(nil),             // 33: v1.RouteInfo
-	(*SeatInfo)(nil),              // 34: v1.SeatInfo
-	(*RoomStateUpdate)(nil),       // 35: v1.RoomStateUpdate
-	(*FormationSpawnedEvent)(nil), // 36: v1.FormationSpawnedEvent
-	(*FormationUpdatedEvent)(nil), // 37: v1.FormationUpdatedEvent
-	(*RoomInfo)(nil),              // 38: v1.RoomInfo
-	(*ErrorMessage)(nil),          // 39: v1.ErrorMessage
-	(*LoginRequest)(nil),          // 40: v1.LoginRequest
-	(*LoginResponse)(nil),         // 41: v1.LoginResponse
+	(MessageType)(0),                 // 0: v1.MessageType
+	(ErrorCode)(0),                   // 1: v1.ErrorCode
+	(*Position)(nil),                 // 2: v1.Position
+	(*GameMessage)(nil),              // 3: v1.GameMessage
+	(*FireBulletRequest)(nil),        // 4: v1.FireBulletRequest
+	(*SwitchCannonRequest)(nil),      // 5: v1.SwitchCannonRequest
+	(*JoinRoomRequest)(nil),          // 6: v1.JoinRoomRequest
+	(*QuickJoinRequest)(nil),         // 7: v1.QuickJoinRequest
+	(*WatchRoomRequest)(nil),         // 8: v1.WatchRoomRequest
+	(*CreatePrivateRoomRequest)(nil), // 9: v1.CreatePrivateRoomRequest
+	(*KickPlayerRequest)(nil),        // 10: v1.KickPlayerRequest
+	(*LockRoomRequest)(nil),          // 11: v1.LockRoomRequest
+	(*LeaveRoomRequest)(nil),         // 12: v1.LeaveRoomRequest
+	(*HeartbeatMessage)(nil),         // 13: v1.HeartbeatMessage
+	(*GetRoomListRequest)(nil),       // 14: v1.GetRoomListRequest
+	(*GetPlayerInfoRequest)(nil),     // 15: v1.GetPlayerInfoRequest
+	(*SelectSeatRequest)(nil),        // 16: v1.SelectSeatRequest
+	(*SwapSeatRequest)(nil),          // 17: v1.SwapSeatRequest
+	(*RespondSeatSwapRequest)(nil),   // 18: v1.RespondSeatSwapRequest
+	(*HitFishRequest)(nil),           // 19: v1.HitFishRequest
+	(*ChatRequest)(nil),              // 20: v1.ChatRequest
+	(*FireBulletResponse)(nil),       // 21: v1.FireBulletResponse
+	(*SwitchCannonResponse)(nil),     // 22: v1.SwitchCannonResponse
+	(*JoinRoomResponse)(nil),         // 23: v1.JoinRoomResponse
+	(*PrivateRoomUpdate)(nil),        // 24: v1.PrivateRoomUpdate
+	(*SeatEvent)(nil),                // 25: v1.SeatEvent
+	(*RoomEventInfo)(nil),            // 26: v1.RoomEventInfo
+	(*RoomEventNotification)(nil),    // 27: v1.RoomEventNotification
+	(*WatchRoomResponse)(nil),        // 28: v1.WatchRoomResponse
+	(*LeaveRoomResponse)(nil),        // 29: v1.LeaveRoomResponse
+	(*HeartbeatResponse)(nil),        // 30: v1.HeartbeatResponse
+	(*RoomListResponse)(nil),         // 31: v1.RoomListResponse
+	(*PlayerInfoResponse)(nil),       // 32: v1.PlayerInfoResponse
+	(*SelectSeatResponse)(nil),       // 33: v1.SelectSeatResponse
+	(*HitFishResponse)(nil),          // 34: v1.HitFishResponse
+	(*BulletFiredEvent)(nil),         // 35: v1.BulletFiredEvent
+	(*CannonSwitchedEvent)(nil),      // 36: v1.CannonSwitchedEvent
+	(*FishSpawnedEvent)(nil),         // 37: v1.FishSpawnedEvent
+	(*FishDiedEvent)(nil),            // 38: v1.FishDiedEvent
+	(*PlayerRewardEvent)(nil),        // 39: v1.PlayerRewardEvent
+	(*HelloMessage)(nil),             // 40: v1.HelloMessage
+	(*WelcomeMessage)(nil),           // 41: v1.WelcomeMessage
+	(*PlayerJoinedMessage)(nil),      // 42: v1.PlayerJoinedMessage
+	(*PlayerLeftMessage)(nil),        // 43: v1.PlayerLeftMessage
+	(*FishInfo)(nil),                 // 44: v1.FishInfo
+	(*BulletInfo)(nil),               // 45: v1.BulletInfo
+	(*FormationInfo)(nil),            // 46: v1.FormationInfo
+	(*FormationSize)(nil),            // 47: v1.FormationSize
+	(*RouteInfo)(nil),                // 48: v1.RouteInfo
+	(*SeatInfo)(nil),                 // 49: v1.SeatInfo
+	(*RoomStateUpdate)(nil),          // 50: v1.RoomStateUpdate
+	(*FormationSpawnedEvent)(nil),    // 51: v1.FormationSpawnedEvent
+	(*FormationUpdatedEvent)(nil),    // 52: v1.FormationUpdatedEvent
+	(*ServerDrainingEvent)(nil),      // 53: v1.ServerDrainingEvent
+	(*ChatMessage)(nil),              // 54: v1.ChatMessage
+	(*RoomInfo)(nil),                 // 55: v1.RoomInfo
+	(*MessageBatch)(nil),             // 56: v1.MessageBatch
+	(*ErrorMessage)(nil),             // 57: v1.ErrorMessage
+	(*LoginRequest)(nil),             // 58: v1.LoginRequest
+	(*LoginResponse)(nil),            // 59: v1.LoginResponse
 }
 var file_proto_v1_game_proto_depIdxs = []int32{
 	0,  // 0: v1.GameMessage.type:type_name -> v1.MessageType
-	3,  // 1: v1.GameMessage.fire_bullet:type_name -> v1.FireBulletRequest
-	4,  // 2: v1.GameMessage.switch_cannon:type_name -> v1.SwitchCannonRequest
-	5,  // 3: v1.GameMessage.join_room:type_name -> v1.JoinRoomRequest
-	6,  // 4: v1.GameMessage.leave_room:type_name -> v1.LeaveRoomRequest
-	7,  // 5: v1.GameMessage.heartbeat:type_name -> v1.HeartbeatMessage
-	8,  // 6: v1.GameMessage.get_room_list:type_name -> v1.GetRoomListRequest
-	9,  // 7: v1.GameMessage.get_player_info:type_name -> v1.GetPlayerInfoRequest
-	10, // 8: v1.GameMessage.select_seat:type_name -> v1.SelectSeatRequest
-	11, // 9: v1.GameMessage.hit_fish:type_name -> v1.HitFishRequest
-	12, // 10: v1.GameMessage.fire_bullet_response:type_name -> v1.FireBulletResponse
-	13, // 11: v1.GameMessage.switch_cannon_response:type_name -> v1.SwitchCannonResponse
-	14, // 12: v1.GameMessage.join_room_response:type_name -> v1.JoinRoomResponse
-	15, // 13: v1.GameMessage.leave_room_response:type_name -> v1.LeaveRoomResponse
-	16, // 14: v1.GameMessage.heartbeat_response:type_name -> v1.HeartbeatResponse
-	17, // 15: v1.GameMessage.room_list_response:type_name -> v1.RoomListResponse
-	18, // 16: v1.GameMessage.player_info_response:type_name -> v1.PlayerInfoResponse
-	19, // 17: v1.GameMessage.select_seat_response:type_name -> v1.SelectSeatResponse
-	20, // 18: v1.GameMessage.hit_fish_response:type_name -> v1.HitFishResponse
-	21, // 19: v1.GameMessage.bullet_fired:type_name -> v1.BulletFiredEvent
-	22, // 20: v1.GameMessage.cannon_switched:type_name -> v1.CannonSwitchedEvent
-	23, // 21: v1.GameMessage.fish_spawned:type_name -> v1.FishSpawnedEvent
-	24, // 22: v1.GameMessage.fish_died:type_name -> v1.FishDiedEvent
-	25, // 23: v1.GameMessage.player_reward:type_name -> v1.PlayerRewardEvent
-	26, // 24: v1.GameMessage.welcome:type_name -> v1.WelcomeMessage
-	27, // 25: v1.GameMessage.player_joined:type_name -> v1.PlayerJoinedMessage
-	28, // 26: v1.GameMessage.player_left:type_name -> v1.PlayerLeftMessage
-	35, // 27: v1.GameMessage.room_state_update:type_name -> v1.RoomStateUpdate
-	36, // 28: v1.GameMessage.formation_spawned:type_name -> v1.FormationSpawnedEvent
-	37, // 29: v1.GameMessage.formation_updated:type_name -> v1.FormationUpdatedEvent
-	39, // 30: v1.GameMessage.error:type_name -> v1.ErrorMessage
-	1,  // 31: v1.FireBulletRequest.position:type_name -> v1.Position
-	38, // 32: v1.RoomListResponse.rooms:type_name -> v1.RoomInfo
-	1,  // 33: v1.BulletFiredEvent.position:type_name -> v1.Position
-	1,  // 34: v1.FishSpawnedEvent.position:type_name -> v1.Position
-	1,  // 35: v1.FishInfo.position:type_name -> v1.Position
-	1,  // 36: v1.BulletInfo.position:type_name -> v1.Position
-	1,  // 37: v1.FormationInfo.center_position:type_name -> v1.Position
-	32, // 38: v1.FormationInfo.size:type_name -> v1.FormationSize
-	33, // 39: v1.FormationInfo.route:type_name -> v1.RouteInfo
-	1,  // 40: v1.RouteInfo.points:type_name -> v1.Position
-	29, // 41: v1.RoomStateUpdate.fishes:type_name -> v1.FishInfo
-	30, // 42: v1.RoomStateUpdate.bullets:type_name -> v1.BulletInfo
-	31, // 43: v1.RoomStateUpdate.formations:type_name -> v1.FormationInfo
-	34, // 44: v1.RoomStateUpdate.seats:type_name -> v1.SeatInfo
-	31, // 45: v1.FormationSpawnedEvent.formation:type_name -> v1.FormationInfo
-	29, // 46: v1.FormationSpawnedEvent.fishes:type_name -> v1.FishInfo
-	1,  // 47: v1.FormationUpdatedEvent.center_position:type_name -> v1.Position
-	29, // 48: v1.FormationUpdatedEvent.fishes:type_name -> v1.FishInfo
-	34, // 49: v1.RoomInfo.seats:type_name -> v1.SeatInfo
-	40, // 50: v1.Game.Login:input_type -> v1.LoginRequest
-	41, // 51: v1.Game.Login:output_type -> v1.LoginResponse
-	51, // [51:52] is the sub-list for method output_type
-	50, // [50:51] is the sub-list for method input_type
-	50, // [50:50] is the sub-list for extension type_name
-	50, // [50:50] is the sub-list for extension extendee
-	0,  // [0:50] is the sub-list for field type_name
+	4,  // 1: v1.GameMessage.fire_bullet:type_name -> v1.FireBulletRequest
+	5,  // 2: v1.GameMessage.switch_cannon:type_name -> v1.SwitchCannonRequest
+	6,  // 3: v1.GameMessage.join_room:type_name -> v1.JoinRoomRequest
+	12, // 4: v1.GameMessage.leave_room:type_name -> v1.LeaveRoomRequest
+	13, // 5: v1.GameMessage.heartbeat:type_name -> v1.HeartbeatMessage
+	14, // 6: v1.GameMessage.get_room_list:type_name -> v1.GetRoomListRequest
+	15, // 7: v1.GameMessage.get_player_info:type_name -> v1.GetPlayerInfoRequest
+	16, // 8: v1.GameMessage.select_seat:type_name -> v1.SelectSeatRequest
+	19, // 9: v1.GameMessage.hit_fish:type_name -> v1.HitFishRequest
+	21, // 10: v1.GameMessage.fire_bullet_response:type_name -> v1.FireBulletResponse
+	22, // 11: v1.GameMessage.switch_cannon_response:type_name -> v1.SwitchCannonResponse
+	23, // 12: v1.GameMessage.join_room_response:type_name -> v1.JoinRoomResponse
+	29, // 13: v1.GameMessage.leave_room_response:type_name -> v1.LeaveRoomResponse
+	30, // 14: v1.GameMessage.heartbeat_response:type_name -> v1.HeartbeatResponse
+	31, // 15: v1.GameMessage.room_list_response:type_name -> v1.RoomListResponse
+	32, // 16: v1.GameMessage.player_info_response:type_name -> v1.PlayerInfoResponse
+	33, // 17: v1.GameMessage.select_seat_response:type_name -> v1.SelectSeatResponse
+	34, // 18: v1.GameMessage.hit_fish_response:type_name -> v1.HitFishResponse
+	35, // 19: v1.GameMessage.bullet_fired:type_name -> v1.BulletFiredEvent
+	36, // 20: v1.GameMessage.cannon_switched:type_name -> v1.CannonSwitchedEvent
+	37, // 21: v1.GameMessage.fish_spawned:type_name -> v1.FishSpawnedEvent
+	38, // 22: v1.GameMessage.fish_died:type_name -> v1.FishDiedEvent
+	39, // 23: v1.GameMessage.player_reward:type_name -> v1.PlayerRewardEvent
+	41, // 24: v1.GameMessage.welcome:type_name -> v1.WelcomeMessage
+	42, // 25: v1.GameMessage.player_joined:type_name -> v1.PlayerJoinedMessage
+	43, // 26: v1.GameMessage.player_left:type_name -> v1.PlayerLeftMessage
+	50, // 27: v1.GameMessage.room_state_update:type_name -> v1.RoomStateUpdate
+	51, // 28: v1.GameMessage.formation_spawned:type_name -> v1.FormationSpawnedEvent
+	52, // 29: v1.GameMessage.formation_updated:type_name -> v1.FormationUpdatedEvent
+	53, // 30: v1.GameMessage.server_draining:type_name -> v1.ServerDrainingEvent
+	7,  // 31: v1.GameMessage.quick_join:type_name -> v1.QuickJoinRequest
+	8,  // 32: v1.GameMessage.watch_room:type_name -> v1.WatchRoomRequest
+	28, // 33: v1.GameMessage.watch_room_response:type_name -> v1.WatchRoomResponse
+	9,  // 34: v1.GameMessage.create_private_room:type_name -> v1.CreatePrivateRoomRequest
+	10, // 35: v1.GameMessage.kick_player:type_name -> v1.KickPlayerRequest
+	11, // 36: v1.GameMessage.lock_room:type_name -> v1.LockRoomRequest
+	24, // 37: v1.GameMessage.private_room_update:type_name -> v1.PrivateRoomUpdate
+	17, // 38: v1.GameMessage.swap_seat:type_name -> v1.SwapSeatRequest
+	18, // 39: v1.GameMessage.respond_seat_swap:type_name -> v1.RespondSeatSwapRequest
+	25, // 40: v1.GameMessage.seat_event:type_name -> v1.SeatEvent
+	27, // 41: v1.GameMessage.room_event:type_name -> v1.RoomEventNotification
+	20, // 42: v1.GameMessage.chat:type_name -> v1.ChatRequest
+	54, // 43: v1.GameMessage.chat_message:type_name -> v1.ChatMessage
+	40, // 44: v1.GameMessage.hello:type_name -> v1.HelloMessage
+	56, // 45: v1.GameMessage.batch:type_name -> v1.MessageBatch
+	57, // 46: v1.GameMessage.error:type_name -> v1.ErrorMessage
+	2,  // 47: v1.FireBulletRequest.position:type_name -> v1.Position
+	26, // 48: v1.RoomEventNotification.event:type_name -> v1.RoomEventInfo
+	55, // 49: v1.RoomListResponse.rooms:type_name -> v1.RoomInfo
+	2,  // 50: v1.BulletFiredEvent.position:type_name -> v1.Position
+	2,  // 51: v1.FishSpawnedEvent.position:type_name -> v1.Position
+	2,  // 52: v1.FishInfo.position:type_name -> v1.Position
+	2,  // 53: v1.BulletInfo.position:type_name -> v1.Position
+	2,  // 54: v1.FormationInfo.center_position:type_name -> v1.Position
+	47, // 55: v1.FormationInfo.size:type_name -> v1.FormationSize
+	48, // 56: v1.FormationInfo.route:type_name -> v1.RouteInfo
+	2,  // 57: v1.RouteInfo.points:type_name -> v1.Position
+	44, // 58: v1.RoomStateUpdate.fishes:type_name -> v1.FishInfo
+	45, // 59: v1.RoomStateUpdate.bullets:type_name -> v1.BulletInfo
+	46, // 60: v1.RoomStateUpdate.formations:type_name -> v1.FormationInfo
+	49, // 61: v1.RoomStateUpdate.seats:type_name -> v1.SeatInfo
+	26, // 62: v1.RoomStateUpdate.events:type_name -> v1.RoomEventInfo
+	46, // 63: v1.FormationSpawnedEvent.formation:type_name -> v1.FormationInfo
+	44, // 64: v1.FormationSpawnedEvent.fishes:type_name -> v1.FishInfo
+	2,  // 65: v1.FormationUpdatedEvent.center_position:type_name -> v1.Position
+	44, // 66: v1.FormationUpdatedEvent.fishes:type_name -> v1.FishInfo
+	49, // 67: v1.RoomInfo.seats:type_name -> v1.SeatInfo
+	3,  // 68: v1.MessageBatch.messages:type_name -> v1.GameMessage
+	1,  // 69: v1.ErrorMessage.error_code:type_name -> v1.ErrorCode
+	58, // 70: v1.Game.Login:input_type -> v1.LoginRequest
+	59, // 71: v1.Game.Login:output_type -> v1.LoginResponse
+	71, // [71:72] is the sub-list for method output_type
+	70, // [70:71] is the sub-list for method input_type
+	70, // [70:70] is the sub-list for extension type_name
+	70, // [70:70] is the sub-list for extension extendee
+	0,  // [0:70] is the sub-list for field type_name
 }
 
 func init() { file_proto_v1_game_proto_init() }
@@ -4004,6 +5979,22 @@ func file_proto_v1_game_proto_init() {
 		(*GameMessage_RoomStateUpdate)(nil),
 		(*GameMessage_FormationSpawned)(nil),
 		(*GameMessage_FormationUpdated)(nil),
+		(*GameMessage_ServerDraining)(nil),
+		(*GameMessage_QuickJoin)(nil),
+		(*GameMessage_WatchRoom)(nil),
+		(*GameMessage_WatchRoomResponse)(nil),
+		(*GameMessage_CreatePrivateRoom)(nil),
+		(*GameMessage_KickPlayer)(nil),
+		(*GameMessage_LockRoom)(nil),
+		(*GameMessage_PrivateRoomUpdate)(nil),
+		(*GameMessage_SwapSeat)(nil),
+		(*GameMessage_RespondSeatSwap)(nil),
+		(*GameMessage_SeatEvent)(nil),
+		(*GameMessage_RoomEvent)(nil),
+		(*GameMessage_Chat)(nil),
+		(*GameMessage_ChatMessage)(nil),
+		(*GameMessage_Hello)(nil),
+		(*GameMessage_Batch)(nil),
 		(*GameMessage_Error)(nil),
 	}
 	type x struct{}
@@ -4011,8 +6002,8 @@ func file_proto_v1_game_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_v1_game_proto_rawDesc), len(file_proto_v1_game_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   41,
+			NumEnums:      2,
+			NumMessages:   58,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,8 @@
+-- 回滾：刪除配置版本歷史和版本欄位
+
+DROP TABLE IF EXISTS room_config_versions;
+
+ALTER TABLE room_configs
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS max_spectators,
+    DROP COLUMN IF EXISTS updated_by;
@@ -0,0 +1,61 @@
+-- 房間配置版本化
+-- room_configs 保存每種房間類型的當前配置，每次修改版本號加一；room_config_versions 保存所有版本
+-- 遊戲節點按 version 判斷配置新舊，刪除配置時 is_active 設為 false 並同樣遞增版本
+
+ALTER TABLE room_configs
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS max_spectators INT NOT NULL DEFAULT 20,
+    ADD COLUMN IF NOT EXISTS updated_by VARCHAR(100);
+
+COMMENT ON COLUMN room_configs.version IS '配置版本，每次修改加一';
+COMMENT ON COLUMN room_configs.max_spectators IS '最大觀戰人數';
+COMMENT ON COLUMN room_configs.updated_by IS '最後修改配置的管理員';
+
+-- 此前遊戲節點只使用代碼中的默認配置，表中的種子數據從未生效
+-- 把未修改過的種子數據對齊到實際運行的配置，避免啟用後房間的下注範圍和魚數量突然變化
+UPDATE room_configs SET min_bet = 10, max_bet = 100, bullet_cost_multiplier = 1.0, fish_spawn_rate = 0.3,
+    min_fish_count = 10, max_fish_count = 20, room_width = 1200, room_height = 800, target_rtp = 0.97, max_spectators = 20
+WHERE room_type = 'novice' AND version = 1;
+UPDATE room_configs SET min_bet = 100, max_bet = 1000, bullet_cost_multiplier = 2.0, fish_spawn_rate = 0.4,
+    min_fish_count = 12, max_fish_count = 25, room_width = 1200, room_height = 800, target_rtp = 0.96, max_spectators = 20
+WHERE room_type = 'intermediate' AND version = 1;
+UPDATE room_configs SET min_bet = 1000, max_bet = 10000, bullet_cost_multiplier = 5.0, fish_spawn_rate = 0.5,
+    min_fish_count = 15, max_fish_count = 30, room_width = 1200, room_height = 800, target_rtp = 0.95, max_spectators = 10
+WHERE room_type = 'advanced' AND version = 1;
+UPDATE room_configs SET min_bet = 10000, max_bet = 100000, bullet_cost_multiplier = 10.0, fish_spawn_rate = 0.6,
+    min_fish_count = 18, max_fish_count = 35, room_width = 1200, room_height = 800, target_rtp = 0.94, max_spectators = 5
+WHERE room_type = 'vip' AND version = 1;
+
+-- 配置版本歷史，config 為遊戲使用的完整配置 JSON
+CREATE TABLE IF NOT EXISTS room_config_versions (
+    id BIGSERIAL PRIMARY KEY,
+    room_type VARCHAR(50) NOT NULL,
+    version BIGINT NOT NULL,
+    room_name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_active BOOLEAN NOT NULL,
+    config JSONB NOT NULL,
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (room_type, version)
+);
+
+-- 現有配置記錄為版本 1
+INSERT INTO room_config_versions (room_type, version, room_name, description, is_active, config)
+SELECT room_type, version, room_name, description, is_active,
+       jsonb_build_object(
+           'max_players', max_players,
+           'max_spectators', max_spectators,
+           'min_bet', min_bet,
+           'max_bet', max_bet,
+           'bullet_cost_multiplier', bullet_cost_multiplier,
+           'fish_spawn_rate', fish_spawn_rate,
+           'min_fish_count', min_fish_count,
+           'max_fish_count', max_fish_count,
+           'room_width', room_width,
+           'room_height', room_height,
+           'target_rtp', target_rtp,
+           'version', version
+       )
+FROM room_configs
+ON CONFLICT (room_type, version) DO NOTHING;
@@ -0,0 +1,3 @@
+-- 回滾：刪除定時房間活動排程
+
+DROP TABLE IF EXISTS room_event_schedules;
@@ -0,0 +1,32 @@
+-- 定時房間活動排程
+-- 遊戲節點定期加載啟用的排程，按 timezone 的日曆和 recurrence 計算每一場活動的開始時間
+-- recurrence 例：{"frequency": "weekly", "interval": 1, "weekdays": [5, 6], "count": 0}
+
+CREATE TABLE IF NOT EXISTS room_event_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    event_type VARCHAR(30) NOT NULL,
+    room_types TEXT[] NOT NULL DEFAULT '{}',
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_minutes INT NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    recurrence JSONB NOT NULL DEFAULT '{"frequency": "none"}',
+    reward_multiplier DOUBLE PRECISION NOT NULL DEFAULT 0,
+    tide_fish_type_id INT NOT NULL DEFAULT 0,
+    tide_wave_size INT NOT NULL DEFAULT 0,
+    tide_wave_seconds INT NOT NULL DEFAULT 0,
+    announcement TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE room_event_schedules IS '定時房間活動排程';
+COMMENT ON COLUMN room_event_schedules.event_type IS '活動類型：double_reward, boss_rush, tide_storm';
+COMMENT ON COLUMN room_event_schedules.room_types IS '生效的房間類型，空數組表示所有類型';
+COMMENT ON COLUMN room_event_schedules.start_at IS '首場開始時間';
+COMMENT ON COLUMN room_event_schedules.timezone IS '計算重複規則使用的 IANA 時區';
+COMMENT ON COLUMN room_event_schedules.recurrence IS '重複規則 JSON';
+
+CREATE INDEX IF NOT EXISTS idx_room_event_schedules_enabled ON room_event_schedules(enabled);
@@ -0,0 +1,4 @@
+-- 回滾：刪除管理後台帳號和角色
+
+DROP TABLE IF EXISTS admin_users;
+DROP TABLE IF EXISTS admin_roles;
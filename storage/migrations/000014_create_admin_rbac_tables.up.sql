@@ -0,0 +1,69 @@
+-- 管理後台帳號和角色權限
+-- 管理員帳號與玩家帳號（users、players）分開保存，每個管理員屬於一個角色
+-- 權限格式為 "資源:操作"，與 internal/biz/admin/rbac.go 中的定義一致
+
+CREATE TABLE IF NOT EXISTS admin_roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    built_in BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE admin_roles IS '管理後台角色';
+COMMENT ON COLUMN admin_roles.permissions IS '角色擁有的權限列表';
+COMMENT ON COLUMN admin_roles.built_in IS '內置角色不能刪除，super_admin 也不能修改';
+
+CREATE TABLE IF NOT EXISTS admin_users (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(50) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL REFERENCES admin_roles(name),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE admin_users IS '管理後台帳號，與玩家帳號相互獨立';
+COMMENT ON COLUMN admin_users.role IS '所屬角色，權限每次請求時重新讀取';
+
+CREATE INDEX IF NOT EXISTS idx_admin_users_role ON admin_users(role);
+
+-- 內置角色
+INSERT INTO admin_roles (name, description, permissions, built_in) VALUES
+    ('super_admin', '所有權限，包括管理員帳號和角色管理', ARRAY[
+        'system:read', 'cluster:manage',
+        'player:read', 'player:write', 'player:ban',
+        'wallet:read', 'wallet:freeze', 'wallet:adjust',
+        'room:read', 'room:spectate', 'room:configure',
+        'formation:read', 'formation:write',
+        'fish_tide:read', 'fish_tide:write',
+        'announcement:write',
+        'admin:manage'
+    ], true),
+    ('operator', '遊戲運營：房間、陣型、魚潮、公告和集群節點', ARRAY[
+        'system:read', 'cluster:manage',
+        'player:read', 'wallet:read',
+        'room:read', 'room:spectate', 'room:configure',
+        'formation:read', 'formation:write',
+        'fish_tide:read', 'fish_tide:write',
+        'announcement:write'
+    ], true),
+    ('finance', '財務：錢包充值、扣款和凍結', ARRAY[
+        'player:read',
+        'wallet:read', 'wallet:freeze', 'wallet:adjust'
+    ], true),
+    ('support', '客服：查詢玩家、封禁玩家和觀戰', ARRAY[
+        'player:read', 'player:ban',
+        'wallet:read',
+        'room:read', 'room:spectate'
+    ], true),
+    ('read_only', '只讀：查看所有數據，不能做任何修改', ARRAY[
+        'system:read',
+        'player:read', 'wallet:read',
+        'room:read',
+        'formation:read', 'fish_tide:read'
+    ], true)
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,7 @@
+-- 回滾：刪除管理操作審計日誌
+
+UPDATE admin_roles
+SET permissions = array_remove(permissions, 'audit:read'), updated_at = NOW();
+
+DROP TABLE IF EXISTS admin_audit_log;
+DROP FUNCTION IF EXISTS reject_admin_audit_log_change();
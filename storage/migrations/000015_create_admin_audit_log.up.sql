@@ -0,0 +1,58 @@
+-- 管理操作審計日誌
+-- 只能追加：seq 由應用在 advisory lock 內按鏈尾加一分配，hash = sha256(上一條 hash + 本條內容)
+-- 計算方式見 internal/biz/admin/audit.go；before、after、diff 使用 JSON 而不是 JSONB 以保留原始文本
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    seq BIGINT PRIMARY KEY,
+    actor_id BIGINT NOT NULL,
+    actor_name VARCHAR(50) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path VARCHAR(1024) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    status INT NOT NULL,
+    before_data JSON,
+    after_data JSON,
+    diff JSON,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+COMMENT ON TABLE admin_audit_log IS '管理操作審計日誌，只能追加';
+COMMENT ON COLUMN admin_audit_log.seq IS '連續遞增的序號，出現缺口說明記錄被刪除';
+COMMENT ON COLUMN admin_audit_log.route IS '路由模板，例如 /admin/wallets/:id/deposit';
+COMMENT ON COLUMN admin_audit_log.target IS '操作對象，例如 wallet:12';
+COMMENT ON COLUMN admin_audit_log.prev_hash IS '上一條記錄的 hash，第一條為 64 個 0';
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_actor ON admin_audit_log(actor_id, seq DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target ON admin_audit_log(target, seq DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log(created_at);
+
+-- 禁止修改和刪除審計記錄
+CREATE OR REPLACE FUNCTION reject_admin_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_reject_admin_audit_log_change ON admin_audit_log;
+CREATE TRIGGER trigger_reject_admin_audit_log_change
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_admin_audit_log_change();
+
+DROP TRIGGER IF EXISTS trigger_reject_admin_audit_log_truncate ON admin_audit_log;
+CREATE TRIGGER trigger_reject_admin_audit_log_truncate
+    BEFORE TRUNCATE ON admin_audit_log
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION reject_admin_audit_log_change();
+
+-- 超級管理員獲得審計日誌查詢權限
+UPDATE admin_roles
+SET permissions = array_append(permissions, 'audit:read'), updated_at = NOW()
+WHERE name = 'super_admin' AND NOT ('audit:read' = ANY(permissions));
@@ -0,0 +1,3 @@
+-- 回滾：刪除第三方身份綁定（註冊時的身份仍保存在 users 表）
+
+DROP TABLE IF EXISTS user_oauth_identities;
@@ -0,0 +1,25 @@
+-- 玩家綁定的第三方身份
+-- 一個帳號每個平台最多綁定一個身份，一個第三方身份只能屬於一個帳號
+-- users.third_party_provider / third_party_id 保留為註冊時使用的平台，登入按本表查找
+
+CREATE TABLE IF NOT EXISTS user_oauth_identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    third_party_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, third_party_id),
+    UNIQUE (user_id, provider)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_oauth_identities_user_id ON user_oauth_identities(user_id);
+
+-- 已有的第三方帳號綁定註冊時使用的身份
+INSERT INTO user_oauth_identities (user_id, provider, third_party_id, created_at)
+SELECT id, third_party_provider, third_party_id, created_at
+FROM users
+WHERE third_party_provider IS NOT NULL AND third_party_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE user_oauth_identities IS '玩家綁定的第三方身份';
+COMMENT ON COLUMN user_oauth_identities.third_party_id IS '第三方平台的使用者 ID（google sub、facebook id、qq openid）';
@@ -0,0 +1,3 @@
+-- 回滾：刪除玩家處罰記錄
+
+DROP TABLE IF EXISTS player_sanctions;
@@ -0,0 +1,25 @@
+-- 玩家處罰：封禁、禁言、房間類型限制、提款限制
+-- 解除處罰時只設置 lifted_* 欄位，保留記錄；expires_at 為 NULL 表示永久
+
+CREATE TABLE IF NOT EXISTS player_sanctions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL CHECK (type IN ('ban', 'mute', 'room_restriction', 'withdraw_block')),
+    reason TEXT NOT NULL,
+    room_types TEXT[] NOT NULL DEFAULT '{}',
+    issued_by BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lifted_by BIGINT,
+    lift_reason TEXT NOT NULL DEFAULT ''
+);
+
+-- 登入、連接和加入房間時按玩家查詢未解除的處罰
+CREATE INDEX IF NOT EXISTS idx_player_sanctions_active ON player_sanctions(user_id, type) WHERE lifted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_player_sanctions_user_id ON player_sanctions(user_id, created_at DESC);
+
+COMMENT ON TABLE player_sanctions IS '管理員對玩家發出的處罰';
+COMMENT ON COLUMN player_sanctions.room_types IS '房間類型限制禁止進入的房間類型（novice、intermediate、advanced、vip）';
+COMMENT ON COLUMN player_sanctions.issued_by IS '發出處罰的管理員 ID（admin_users.id）';
+COMMENT ON COLUMN player_sanctions.expires_at IS '到期時間，NULL 表示永久';
@@ -0,0 +1,37 @@
+-- 回滾：按 migration_credit 流水恢復 users.coins，並從 COIN 錢包扣回這筆入帳
+-- 已刪除的影子帳號和已創建的主錢包不恢復；只因本遷移創建、扣回後沒有其他流水的 COIN 錢包會刪除
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS coins BIGINT NOT NULL DEFAULT 1000;
+
+UPDATE users u
+SET coins = COALESCE((
+    SELECT SUM(t.amount)::BIGINT
+    FROM wallet_transactions t
+    JOIN wallets w ON w.id = t.wallet_id
+    WHERE w.user_id = u.id
+      AND w.currency = 'COIN'
+      AND t.type = 'migration_credit'
+      AND t.reference_id = 'migration:000018'
+), 0)
+WHERE u.is_guest = FALSE;
+
+UPDATE wallets w
+SET balance = w.balance - t.amount,
+    updated_at = NOW()
+FROM wallet_transactions t
+WHERE t.wallet_id = w.id
+  AND w.currency = 'COIN'
+  AND t.type = 'migration_credit'
+  AND t.reference_id = 'migration:000018';
+
+DELETE FROM wallet_transactions
+WHERE type = 'migration_credit'
+  AND reference_id = 'migration:000018';
+
+DELETE FROM wallets w
+WHERE w.currency = 'COIN'
+  AND w.balance = 0
+  AND NOT EXISTS (SELECT 1 FROM wallet_transactions t WHERE t.wallet_id = w.id);
+
+COMMENT ON TABLE wallets IS NULL;
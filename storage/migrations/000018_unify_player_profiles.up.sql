@@ -0,0 +1,52 @@
+-- 統一玩家資料：帳號（users.id）是唯一的玩家標識，遊戲內餘額只保存在 CNY 主錢包
+-- 1. 刪除舊版 Game Server 按暱稱創建的影子帳號
+-- 2. 為沒有主錢包的帳號創建空的主錢包
+-- 3. users.coins 是贈送的大廳金幣，不是真實貨幣，不能計入 CNY 主錢包：
+--    按原數量轉入 COIN 免費金幣錢包（幣種見 internal/biz/wallet/currency.go）並寫入 migration_credit 流水，回滾時據此恢復
+-- 4. 移除 users.coins，大廳金幣改為讀取主錢包
+
+-- 影子帳號：使用者名稱等於另一個帳號的暱稱、密碼為空、沒有第三方身份，且沒有任何錢包、遊戲和處罰數據
+DELETE FROM users u
+WHERE u.is_guest = FALSE
+  AND u.password_hash = ''
+  AND u.third_party_provider IS NULL
+  AND EXISTS (SELECT 1 FROM users o WHERE o.id <> u.id AND o.nickname = u.username)
+  AND NOT EXISTS (SELECT 1 FROM wallets w WHERE w.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM game_records r WHERE r.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM game_statistics s WHERE s.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM game_events e WHERE e.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM user_oauth_identities i WHERE i.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM player_sanctions p WHERE p.user_id = u.id);
+
+INSERT INTO wallets (user_id, balance, currency)
+SELECT u.id, 0, 'CNY'
+FROM users u
+WHERE u.is_guest = FALSE
+  AND NOT EXISTS (SELECT 1 FROM wallets w WHERE w.user_id = u.id AND w.currency = 'CNY');
+
+-- 大廳金幣轉入 COIN 錢包，已有 COIN 錢包的帳號累加
+INSERT INTO wallets (user_id, balance, currency)
+SELECT u.id, 0, 'COIN'
+FROM users u
+WHERE u.is_guest = FALSE
+  AND u.coins > 0
+ON CONFLICT (user_id, currency) DO NOTHING;
+
+WITH credited AS (
+    UPDATE wallets w
+    SET balance = w.balance + u.coins,
+        updated_at = NOW()
+    FROM users u
+    WHERE w.user_id = u.id
+      AND w.currency = 'COIN'
+      AND u.is_guest = FALSE
+      AND u.coins > 0
+    RETURNING w.id, u.coins AS amount, w.balance
+)
+INSERT INTO wallet_transactions (wallet_id, amount, balance_before, balance_after, type, status, reference_id, description)
+SELECT id, amount, balance - amount, balance, 'migration_credit', 1, 'migration:000018', '合併 users.coins 大廳金幣到 COIN 免費金幣錢包'
+FROM credited;
+
+ALTER TABLE users DROP COLUMN IF EXISTS coins;
+
+COMMENT ON TABLE wallets IS '玩家錢包；每個正式帳號都有一個 CNY 主錢包，遊戲內餘額和大廳金幣都是主錢包餘額，原 users.coins 贈送金幣在 COIN 錢包';
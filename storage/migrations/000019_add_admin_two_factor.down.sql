@@ -0,0 +1,10 @@
+-- 回滾：刪除管理員兩步驗證和角色 IP 白名單
+
+ALTER TABLE admin_roles DROP COLUMN IF EXISTS allowed_ips;
+
+DROP TABLE IF EXISTS admin_recovery_codes;
+
+ALTER TABLE admin_users
+    DROP COLUMN IF EXISTS otp_last_step,
+    DROP COLUMN IF EXISTS otp_enabled,
+    DROP COLUMN IF EXISTS otp_secret;
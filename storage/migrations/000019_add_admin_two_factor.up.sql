@@ -0,0 +1,29 @@
+-- 管理員兩步驗證（TOTP）、恢復碼和角色 IP 白名單
+-- otp_secret 在申請後、確認前保存，otp_enabled 為 true 後登入和敏感操作才要求驗證碼
+
+ALTER TABLE admin_users
+    ADD COLUMN IF NOT EXISTS otp_secret VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS otp_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS otp_last_step BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN admin_users.otp_secret IS 'TOTP 密鑰（base32），為空表示未申請';
+COMMENT ON COLUMN admin_users.otp_enabled IS '是否已確認並啟用兩步驗證';
+COMMENT ON COLUMN admin_users.otp_last_step IS '最近一次使用的驗證碼步長，同一驗證碼不能使用兩次';
+
+CREATE TABLE IF NOT EXISTS admin_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    admin_id BIGINT NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_recovery_codes_admin_id ON admin_recovery_codes(admin_id) WHERE used_at IS NULL;
+
+COMMENT ON TABLE admin_recovery_codes IS '管理員兩步驗證的一次性恢復碼';
+COMMENT ON COLUMN admin_recovery_codes.code_hash IS '恢復碼的 SHA-256 雜湊，明文只在生成時返回一次';
+
+ALTER TABLE admin_roles
+    ADD COLUMN IF NOT EXISTS allowed_ips TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN admin_roles.allowed_ips IS '允許登入和訪問管理後台的 IP 或 CIDR，為空表示不限制';
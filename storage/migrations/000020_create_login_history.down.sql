@@ -0,0 +1,4 @@
+-- 回滾：刪除玩家登入記錄和已知設備
+
+DROP TABLE IF EXISTS user_devices;
+DROP TABLE IF EXISTS user_login_history;
@@ -0,0 +1,37 @@
+-- 玩家登入記錄和已知設備
+-- 登入失敗計數和鎖定保存在 Redis（login_failures:*、login_lock:*），這裡只保存可以查詢的歷史
+-- fingerprint 為客戶端設備 ID（沒有時為 User-Agent）的 SHA-256 前 16 字節
+
+CREATE TABLE IF NOT EXISTS user_login_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(50) NOT NULL,
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(32) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    device_id VARCHAR(128) NOT NULL DEFAULT '',
+    fingerprint CHAR(32) NOT NULL,
+    new_device BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_login_history_user_id ON user_login_history(user_id, created_at DESC);
+
+COMMENT ON TABLE user_login_history IS '玩家登入記錄，包括密碼錯誤和被封禁拒絕的嘗試';
+COMMENT ON COLUMN user_login_history.method IS '登入方式：password 或 oauth:<平台>';
+COMMENT ON COLUMN user_login_history.failure_reason IS '失敗原因：bad_credentials、banned';
+COMMENT ON COLUMN user_login_history.new_device IS '是否為帳號首次使用的設備';
+
+CREATE TABLE IF NOT EXISTS user_devices (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint CHAR(32) NOT NULL,
+    device_id VARCHAR(128) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    last_ip VARCHAR(64) NOT NULL DEFAULT '',
+    first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, fingerprint)
+);
+
+COMMENT ON TABLE user_devices IS '玩家登入成功過的設備，用於新設備檢測';
@@ -0,0 +1,6 @@
+-- 回滾：移除兌換比例表和房間遊戲幣種
+
+DROP TABLE IF EXISTS wallet_exchange_rates;
+
+ALTER TABLE room_configs
+    DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,21 @@
+-- 多幣種錢包：房間類型聲明遊戲幣種，幣種之間按管理後台配置的比例兌換
+-- 幣種代碼見 internal/biz/wallet/currency.go（CNY 真實貨幣、COIN 免費金幣、TOKEN 活動代幣）
+
+ALTER TABLE room_configs
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(10) NOT NULL DEFAULT 'CNY';
+
+COMMENT ON COLUMN room_configs.currency IS '房間遊戲幣種，開火、捕獲和退款只變動玩家該幣種的錢包';
+
+CREATE TABLE IF NOT EXISTS wallet_exchange_rates (
+    from_currency VARCHAR(10) NOT NULL,
+    to_currency VARCHAR(10) NOT NULL,
+    rate DOUBLE PRECISION NOT NULL CHECK (rate > 0),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    updated_by VARCHAR(100) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (from_currency, to_currency),
+    CHECK (from_currency <> to_currency)
+);
+
+COMMENT ON TABLE wallet_exchange_rates IS '允許的幣種兌換方向和比例，沒有記錄的方向不能兌換';
+COMMENT ON COLUMN wallet_exchange_rates.rate IS '1 單位來源幣種兌換的目標幣種數量，入帳向下取整到分';
@@ -0,0 +1,5 @@
+-- 回滾：刪除管理員兩步驗證失敗鎖定
+
+ALTER TABLE admin_users
+    DROP COLUMN IF EXISTS otp_locked_until,
+    DROP COLUMN IF EXISTS otp_failed_attempts;
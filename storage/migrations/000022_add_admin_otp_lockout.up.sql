@@ -0,0 +1,8 @@
+-- 管理員兩步驗證失敗鎖定：驗證碼和恢復碼連續錯誤達到上限後暫時拒絕驗證
+
+ALTER TABLE admin_users
+    ADD COLUMN IF NOT EXISTS otp_failed_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS otp_locked_until TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN admin_users.otp_failed_attempts IS '連續兩步驗證失敗次數，成功或鎖定後清零';
+COMMENT ON COLUMN admin_users.otp_locked_until IS '兩步驗證鎖定的截止時間，之前的驗證一律拒絕';